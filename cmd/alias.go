@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/generated"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/datakaicr/pk/pkg/shell"
+	"github.com/spf13/cobra"
+)
+
+var aliasDirenvStyle bool
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Export project commands as project-scoped shortcuts",
+	Long: `Make the named commands of a project's kind (see 'pk run') available as
+plain commands, scoped to the project instead of global aliases.`,
+}
+
+var aliasExportCmd = &cobra.Command{
+	Use:   "export [project] --direnv-style",
+	Short: "Write per-project command wrappers for direnv",
+	Long: `Write one executable wrapper per named command of each project's kind into
+~/.local/share/pk/bin/<project>/, so 'test', 'deploy' or 'logs' run that
+project's command from its root, with any arguments appended.
+
+The directories aren't on PATH by themselves: add
+
+  PATH_add ~/.local/share/pk/bin/<project>
+
+to the project's .envrc and direnv puts the wrappers on PATH only while
+you're inside it. pk lists the projects whose .envrc lacks the line.
+
+Without a project, every project that isn't archived is exported, and
+wrapper directories of projects that no longer have commands are removed.
+The directories are tracked with 'pk generated' (kind bin-dir).
+
+Example:
+  pk alias export --direnv-style           # All projects
+  pk alias export acme-etl --direnv-style  # Just one`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runAliasExport,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasExportCmd)
+	aliasExportCmd.Flags().BoolVar(&aliasDirenvStyle, "direnv-style", false, "Write wrappers into a per-project bin directory for direnv")
+	aliasExportCmd.MarkFlagRequired("direnv-style")
+}
+
+func runAliasExport(cmd *cobra.Command, args []string) {
+	if len(args) == 1 {
+		project := currentOrNamedProject(args[0])
+		if !exportBinDir(loadSettings(), project) {
+			fmt.Fprintf(os.Stderr, "Error: '%s' has no named commands (set [project] kind; see 'pk kind list')\n", project.ProjectInfo.ID)
+			os.Exit(1)
+		}
+		return
+	}
+
+	projects, err := findProjects(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+	exportBinDirs(projects)
+}
+
+// exportBinDirs writes wrapper directories for every project that isn't
+// archived and removes those left over from projects without commands
+func exportBinDirs(projects []*config.Project) {
+	s := loadSettings()
+	archives := projectPaths().Archives()
+	written := make(map[string]bool)
+	for _, p := range projects {
+		if p.ProjectInfo.Status == "archived" || insideAny(p.Path, archives) {
+			continue
+		}
+		if exportBinDir(s, p) {
+			dir, _ := shell.BinDir(p.ProjectInfo.ID)
+			written[dir] = true
+		}
+	}
+
+	entries, _ := generated.List(generated.KindBinDir)
+	for _, e := range entries {
+		if written[e.Path] {
+			continue
+		}
+		if err := generated.Clean(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to remove %s: %v\n", e.Path, err)
+			continue
+		}
+		fmt.Printf("\033[32m✓\033[0m Removed %s\n", e.Path)
+	}
+
+	if len(written) == 0 {
+		fmt.Println("No projects with named commands (see 'pk kind list')")
+	}
+}
+
+// exportBinDir writes a project's wrapper directory, reporting whether its
+// kind has any commands to write
+func exportBinDir(s *settings.Settings, p *config.Project) bool {
+	kind, exists := s.KindFor(p)
+	if !exists || len(kind.Commands) == 0 {
+		return false
+	}
+
+	dir, names, err := shell.WriteBinDir(p, kind.Commands)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\033[31m✗\033[0m %s: %v\n", p.ProjectInfo.ID, err)
+		return false
+	}
+	generated.Record(dir, generated.KindBinDir, generated.ModeDir, "pk alias export --direnv-style")
+	fmt.Printf("\033[32m✓\033[0m %-20s %s\n", p.ProjectInfo.ID, strings.Join(names, ", "))
+
+	envrc := filepath.Join(p.Path, ".envrc")
+	if data, err := os.ReadFile(envrc); err != nil || !strings.Contains(string(data), filepath.Join("pk", "bin", p.ProjectInfo.ID)) {
+		fmt.Printf("    add to %s: PATH_add %s\n", envrc, dir)
+	}
+	return true
+}
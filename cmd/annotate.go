@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/journal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	annotateProject string
+	annotateLimit   int
+	annotateLatest  bool
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate [note...]",
+	Short: "Add a timestamped note to the current project's journal",
+	Long: `Capture a one-line note about where a project stands, for whoever picks it
+up next (usually you, after three meetings).
+
+The note goes into the current directory's project journal with the time,
+unless --project names another project. The latest note shows in 'pk show'
+and in the preview of the 'pk session' picker.
+
+Without a note, lists the project's recent notes, newest first.
+
+Example:
+  pk annotate "blocked on client VPN"
+  pk annotate waiting for review on PR 42
+  pk annotate -p dojo "demo moved to Friday"
+  pk annotate                 # Recent notes for this project
+  pk annotate -n 50           # More of them`,
+	Args: cobra.ArbitraryArgs,
+	Run:  runAnnotate,
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+	annotateCmd.Flags().StringVarP(&annotateProject, "project", "p", "", "Project to annotate (default: current directory's)")
+	annotateCmd.Flags().IntVarP(&annotateLimit, "limit", "n", 10, "Number of notes to list")
+	annotateCmd.Flags().BoolVar(&annotateLatest, "latest", false, "Print only the latest note (for pickers and prompts)")
+	annotateCmd.RegisterFlagCompletionFunc("project", validProjectNames)
+}
+
+func runAnnotate(cmd *cobra.Command, args []string) {
+	// Previews call this once per highlighted line: take the ID as given
+	// instead of resolving the project
+	if annotateLatest && annotateProject != "" {
+		printLatestNote(annotateProject)
+		return
+	}
+
+	project := currentOrNamedProject(annotateProject)
+	projectID := project.ProjectInfo.ID
+
+	if annotateLatest {
+		printLatestNote(projectID)
+		return
+	}
+
+	note := strings.TrimSpace(strings.Join(args, " "))
+	if note == "" {
+		listNotes(projectID)
+		return
+	}
+
+	entry, err := journal.Annotate(projectID, note)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to save note: %v\n", err)
+		os.Exit(1)
+	}
+	events.Emit(events.ProjectAnnotated, projectID, project.Path, map[string]string{"note": entry.Text})
+
+	fmt.Printf("\033[32m✓\033[0m %s: %s\n", projectID, entry.Text)
+}
+
+func printLatestNote(projectID string) {
+	if entry, err := journal.Latest(projectID); err == nil && entry != nil {
+		fmt.Printf("Note (%s): %s\n", formatAccessTime(entry.Time), entry.Text)
+	}
+}
+
+func listNotes(projectID string) {
+	entries, err := journal.Entries(projectID, annotateLimit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read journal: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No notes for %s yet\n", projectID)
+		fmt.Println("\nTip: pk annotate \"what you're waiting on\"")
+		return
+	}
+
+	for _, e := range entries {
+		fmt.Printf("\033[2m%-16s\033[0m %s\n", e.Time.Format("2006-01-02 15:04"), e.Text)
+	}
+}
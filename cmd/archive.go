@@ -7,8 +7,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/archive"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/session"
 	"github.com/spf13/cobra"
 )
 
@@ -18,35 +22,77 @@ var archiveCmd = &cobra.Command{
 	Long: `Move a project to the archive directory and update its status.
 
 This will:
-  1. Move the project from ~/projects to ~/archive
+  1. Move the project from wherever it lives to ~/archive, or to the first
+     matching [[paths.archive_rules]] directory
   2. Update status to "archived" in .project.toml
   3. Set completion date to today
-  4. Auto-sync shell aliases (if enabled)
+  4. Kill its tmux session, if one is running
+  5. Auto-sync shell aliases (if enabled)
+
+--compress then packs the working tree into <name>.tar.zst (with a .sha256
+checksum next to it) and removes the unpacked files, keeping .project.toml
+so the project is still listed. Requires tar and zstd.
+
+'pk archive verify' checks what's in the archive (see its --help).
 
 Example:
   pk archive old-project
-  pk archive keplr-data-model`,
-	Args:              cobra.ExactArgs(1),
+  pk archive keplr-data-model --compress
+  pk archive verify`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if !archiveCompress {
+			return nil
+		}
+		return requireTools(cmd, deps.Require("'pk archive --compress'", "tar", "zstd"))
+	},
 	Run:               runArchive,
 	ValidArgsFunction: validProjectNames,
 }
 
-var archiveAutoSync bool
+var archiveVerifyCmd = &cobra.Command{
+	Use:   "verify [name]",
+	Short: "Check the integrity of archived projects",
+	Long: `Check every archived project (or one), so cold storage can be trusted
+when a client asks for an old deliverable:
+
+  - Its metadata is there and records it as archived, with a completion date
+  - A compressed project's tarball matches its .sha256 checksum and reads
+    to the end
+  - No directory in an archive lacks metadata
+
+Each check is recorded with its time; the output shows when every project
+was last verified before this run. Exits non-zero if anything fails.
+
+Example:
+  pk archive verify
+  pk archive verify old-project`,
+	Args: cobra.MaximumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return requireTools(cmd, deps.Require("'pk archive verify'", "tar", "zstd"))
+	},
+	Run:               runArchiveVerify,
+	ValidArgsFunction: validArchivedNames,
+}
+
+var (
+	archiveAutoSync bool
+	archiveCompress bool
+)
 
 func init() {
 	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveVerifyCmd)
 	archiveCmd.Flags().BoolVar(&archiveAutoSync, "sync", true, "Auto-sync aliases after archiving")
+	archiveCmd.Flags().BoolVar(&archiveCompress, "compress", false, "Pack the working tree into a .tar.zst after moving it")
 }
 
 func runArchive(cmd *cobra.Command, args []string) {
 	projectName := strings.ToLower(args[0])
 
-	homeDir, _ := os.UserHomeDir()
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
+	resolver := projectPaths()
 
-	// Find project in projects directory
-	projects, err := config.FindProjects(projectsDir)
+	projects, err := config.FindProjects(cacheRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
 		os.Exit(1)
@@ -62,69 +108,207 @@ func runArchive(cmd *cobra.Command, args []string) {
 	}
 
 	if found == nil {
-		fmt.Fprintf(os.Stderr, "Project '%s' not found in ~/projects\n", projectName)
+		fmt.Fprintf(os.Stderr, "Project '%s' not found\n", projectName)
 		fmt.Fprintf(os.Stderr, "Hint: Use 'pk list active' to see available projects\n")
 		os.Exit(1)
 	}
-
-	// Check if already exists in archive
-	destPath := filepath.Join(archiveDir, filepath.Base(found.Path))
-	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Project already exists in archive: %s\n", destPath)
+	if insideAny(found.Path, resolver.Archives()) {
+		fmt.Fprintf(os.Stderr, "Project '%s' is already archived: %s\n", projectName, found.Path)
 		os.Exit(1)
 	}
-
-	// Ensure archive directory exists
-	if err := os.MkdirAll(archiveDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create archive directory: %v\n", err)
+	archiveDir, err := archiveDirFor(resolver, found)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	sessionName := session.SanitizeSessionName(found.ProjectInfo.ID)
+	if session.SessionExists(sessionName) {
+		if err := session.KillSession(sessionName); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to kill tmux session: %v\n", err)
+		} else {
+			fmt.Printf("\033[32m✓\033[0m Tmux session killed\n")
+		}
+	}
+
 	// Move project
 	fmt.Printf("Moving project: %s\n", found.ProjectInfo.Name)
 	fmt.Printf("  From: %s\n", found.Path)
-	fmt.Printf("  To:   %s\n", destPath)
+	fmt.Printf("  To:   %s\n", filepath.Join(archiveDir, filepath.Base(found.Path)))
 
-	if err := os.Rename(found.Path, destPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to move project: %v\n", err)
+	destPath, err := archiveProject(found, archiveDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Printf("\n\033[32m✓\033[0m Archived successfully\n")
+	fmt.Printf("  Status: \033[33marchived\033[0m\n")
+	fmt.Printf("  Location: %s\n", destPath)
 
-	// Update .project.toml
-	tomlPath := filepath.Join(destPath, ".project.toml")
-	if err := updateProjectToml(tomlPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to update .project.toml: %v\n", err)
-	} else {
-		fmt.Printf("\n\033[32m✓\033[0m Archived successfully\n")
-		fmt.Printf("  Status: \033[33marchived\033[0m\n")
-		fmt.Printf("  Location: %s\n", destPath)
+	if archiveCompress {
+		var keep []string
+		if !found.Sidecar() {
+			keep = append(keep, filepath.Base(found.FileIn(destPath)))
+		}
+		tarball, err := archive.Compress(destPath, keep...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to compress: %v\n", err)
+			os.Exit(1)
+		}
+		size := ""
+		if info, err := os.Stat(tarball); err == nil {
+			size = fmt.Sprintf(" (%.1f MB)", float64(info.Size())/(1<<20))
+		}
+		fmt.Printf("  Compressed: %s%s\n", filepath.Base(tarball), size)
 	}
 
 	// Auto-sync aliases
 	if archiveAutoSync {
-		fmt.Printf("\nSyncing aliases...\n")
-		runSync(cmd, []string{})
+		fmt.Println()
+		syncScopes(syncAliases)
+	}
+}
+
+// archiveProject moves a project into archiveDir, marks it archived and
+// returns its new path. Failing to update .project.toml after the move is
+// only a warning.
+func archiveProject(found *config.Project, archiveDir string) (string, error) {
+	destPath := filepath.Join(archiveDir, filepath.Base(found.Path))
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		return "", fmt.Errorf("project already exists in archive: %s", destPath)
+	}
+
+	// An unmounted volume leaves no parent to create the archive in
+	if _, err := os.Stat(filepath.Dir(archiveDir)); err != nil {
+		return "", fmt.Errorf("archive directory %s is unavailable (volume not mounted?)", archiveDir)
+	}
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	if err := paths.Move(found.Path, destPath); err != nil {
+		return "", fmt.Errorf("failed to move project: %w", err)
+	}
+
+	tomlPath := found.FileIn(destPath)
+	if err := updateProjectToml(tomlPath, destPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to update .project.toml: %v\n", err)
 	}
+	events.Emit(events.ProjectArchived, found.ProjectInfo.ID, destPath, nil)
+	return destPath, nil
 }
 
-func updateProjectToml(path string) error {
+// archiveDirFor returns where a project is archived: the directory of the
+// first archive rule it matches, or the archive directory
+func archiveDirFor(resolver *paths.Resolver, p *config.Project) (string, error) {
+	for _, rule := range resolver.ArchiveRules() {
+		filter, err := config.ParseFilter(rule.Match)
+		if err != nil {
+			return "", fmt.Errorf("archive rule for %s: %w", rule.Path, err)
+		}
+		if filter.Matches(p) {
+			return rule.Path, nil
+		}
+	}
+	return resolver.Archive(), nil
+}
+
+func updateProjectToml(path, dir string) error {
 	// Read current TOML
-	var project config.Project
-	if _, err := toml.DecodeFile(path, &project); err != nil {
+	project, err := config.LoadProject(path)
+	if err != nil {
 		return err
 	}
+	project.Path = dir // A sidecar still names the old directory
 
 	// Update status and completion date
 	project.ProjectInfo.Status = "archived"
 	project.Dates.Completed = time.Now().Format("2006-01-02")
 
-	// Write back to file
-	f, err := os.Create(path)
+	return project.SaveAs(path)
+}
+
+func runArchiveVerify(cmd *cobra.Command, args []string) {
+	archives := projectPaths().Archives()
+	projects, err := config.FindProjects(archives...)
 	if err != nil {
-		return err
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) > 0 {
+		var named []*config.Project
+		for _, p := range projects {
+			if strings.EqualFold(p.ProjectInfo.ID, args[0]) || strings.EqualFold(p.ProjectInfo.Name, args[0]) {
+				named = append(named, p)
+			}
+		}
+		if len(named) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: '%s' is not in the archive\n", args[0])
+			os.Exit(1)
+		}
+		projects = named
+	}
+
+	previous, err := archive.LoadVerifications()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to read past verifications: %v\n", err)
+	}
+
+	now := time.Now()
+	var results []archive.Verification
+	failed := 0
+	for _, p := range projects {
+		v := archive.Verify(p, now)
+		results = append(results, v)
+
+		last := "never verified"
+		if prev, ok := previous[v.ID]; ok {
+			last = "last verified " + prev.VerifiedAt.Format("2006-01-02")
+		}
+		kind := "directory"
+		if v.Compressed {
+			kind = "compressed"
+		}
+		if v.OK() {
+			fmt.Printf("\033[32m✓\033[0m %-24s %-10s  %s\n", v.ID, kind, last)
+			continue
+		}
+		failed++
+		fmt.Printf("\033[31m✗\033[0m %-24s %-10s  %s\n", v.ID, kind, last)
+		for _, problem := range v.Problems {
+			fmt.Printf("    %s\n", problem)
+		}
 	}
-	defer f.Close()
 
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(&project)
+	// Directories nothing was found in have lost their metadata
+	orphans := 0
+	if len(args) == 0 {
+		known := make(map[string]bool)
+		for _, p := range projects {
+			known[p.Path] = true
+		}
+		for _, root := range archives {
+			entries, _ := os.ReadDir(root)
+			for _, e := range entries {
+				dir := filepath.Join(root, e.Name())
+				if e.IsDir() && !strings.HasPrefix(e.Name(), ".") && !known[dir] {
+					fmt.Printf("\033[31m✗\033[0m %s: no project metadata\n", dir)
+					orphans++
+				}
+			}
+		}
+	}
+
+	if err := archive.RecordVerifications(results); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to record verifications: %v\n", err)
+	}
+
+	fmt.Printf("\nVerified %d archived project(s): %d failed", len(results), failed)
+	if orphans > 0 {
+		fmt.Printf(", %d directories without metadata", orphans)
+	}
+	fmt.Println()
+	if failed > 0 || orphans > 0 {
+		os.Exit(1)
+	}
 }
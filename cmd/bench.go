@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/datakaicr/pk/pkg/bench"
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/daemon"
+	"github.com/spf13/cobra"
+)
+
+var benchRuns int
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure pk performance on this machine",
+	Long: `Measure how long pk's hot paths take with your current roots and settings,
+then suggest tuning.
+
+Measures:
+  discovery   Full filesystem scan of the projects, archive and scriptorium roots
+  cache load  Reading ~/.cache/pk/projects.json
+  picker      Everything 'pk session' does before the picker appears
+              (cached project load, scratch scan, tmux session list)
+
+Also counts directories the scan walks, and how many sit inside
+dependency/build trees like node_modules or .venv.
+
+Example:
+  pk bench
+  pk bench --runs 10`,
+	Args: cobra.NoArgs,
+	Run:  runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().IntVarP(&benchRuns, "runs", "n", 3, "Number of runs per measurement")
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	resolver := projectPaths()
+	roots := resolver.AllRoots()
+
+	var report bench.Report
+	report.CacheValid = cache.IsCacheValid()
+	if serviceFile, err := daemon.ServiceFile(); err == nil {
+		if _, err := os.Stat(serviceFile); err == nil {
+			report.DaemonInstalled = true
+		}
+	}
+
+	fmt.Printf("Benchmarking pk (%d runs each)...\n\n", benchRuns)
+
+	// Discovery
+	var projects []*config.Project
+	var err error
+	report.Discovery, err = bench.Measure(benchRuns, func() error {
+		projects, err = config.FindProjects(roots...)
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Discovery failed: %v\n", err)
+		os.Exit(1)
+	}
+	report.Projects = len(projects)
+	report.Walk = bench.Walk(roots...)
+
+	// Cache load needs a cache; build one from the scan we just did
+	if _, err := cache.LoadFromCache(); err != nil {
+		if err := cache.SaveToCache(projects); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to write cache: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	report.CacheLoad, err = bench.Measure(benchRuns, func() error {
+		_, err := cache.LoadFromCache()
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cache load failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Picker provisioning, as 'pk session' does it
+	report.Picker, err = bench.Measure(benchRuns, func() error {
+		cached, err := cache.FindProjectsCached(roots...)
+		if err != nil {
+			return err
+		}
+		scratch, _ := findScratchProjects(resolver.Scratch())
+		projectPickerInput(append(cached, scratch...))
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Picker setup failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("  %-12s %s\n", "discovery", report.Discovery)
+	fmt.Printf("  %-12s %s\n", "cache load", report.CacheLoad)
+	fmt.Printf("  %-12s %s\n", "picker", report.Picker)
+	fmt.Println()
+
+	fmt.Printf("  Projects:      %d\n", report.Projects)
+	fmt.Printf("  Dirs walked:   %d (%d inside dependency/build trees)\n", report.Walk.Dirs, report.Walk.HeavyDirs)
+	if len(report.Walk.Heavy) > 0 {
+		var names []string
+		for name := range report.Walk.Heavy {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("                 %s × %d\n", name, report.Walk.Heavy[name])
+		}
+	}
+	fmt.Printf("  Cache valid:   %v\n", report.CacheValid)
+	fmt.Printf("  Daemon:        %v\n", report.DaemonInstalled)
+	fmt.Println()
+
+	suggestions := report.Suggestions()
+	if len(suggestions) == 0 {
+		fmt.Println("\033[32m✓\033[0m No tuning needed")
+		return
+	}
+
+	fmt.Println("Suggestions:")
+	for _, s := range suggestions {
+		fmt.Printf("  • %s\n", s)
+	}
+}
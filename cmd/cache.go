@@ -4,8 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/importer"
+	"github.com/datakaicr/pk/pkg/paths"
 	"github.com/spf13/cobra"
 )
 
@@ -18,8 +25,9 @@ The cache is automatically maintained, but these commands allow manual control.
 
 Subcommands:
   pk cache status    Show cache information
-  pk cache refresh   Rebuild cache now
-  pk cache clear     Remove cache file`,
+  pk cache refresh   Rebuild cache now (--root for one root)
+  pk cache clear     Remove cache file (and cached cloud context switches)
+  pk cache reconcile Fix pins and access history for moved or renamed projects`,
 }
 
 var cacheStatusCmd = &cobra.Command{
@@ -31,23 +39,94 @@ var cacheStatusCmd = &cobra.Command{
 var cacheRefreshCmd = &cobra.Command{
 	Use:   "refresh",
 	Short: "Rebuild cache now",
-	Run:   runCacheRefresh,
+	Long: `Rebuild the project cache.
+
+Without --root, the whole cache is discarded and rebuilt in the background.
+With --root, only that root is rescanned, right away, and the cached
+projects of the other roots are kept.
+
+Reads of an expired cache already rescan only the roots where something
+changed: a directory searched, a .project.toml, or a .project-defaults.toml.
+Changes outside the root, like a .project-defaults.toml above it, need an
+explicit refresh.
+
+--depth limits how far below each root this rebuild searches, overriding
+[scan] max_depth (0 is unlimited). Later rescans go back to the configured
+depth.
+
+Example:
+  pk cache refresh
+  pk cache refresh --depth 3
+  pk cache refresh --root ~/projects
+  pk cache refresh --root ~/archive --root ~/scriptorium`,
+	Args: cobra.NoArgs,
+	Run:  runCacheRefresh,
 }
 
+var (
+	cacheRefreshRoots []string
+	cacheRefreshDepth int
+)
+
 var cacheClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Remove cache file",
 	Run:   runCacheClear,
 }
 
+var cacheReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Fix pins and access history for moved or renamed projects",
+	Long: `Compare what pk remembers (cache, pins, access history) against the
+projects on disk, and carry state over to projects that moved or changed ID.
+
+  moved     Same ID at a new path: updated automatically
+  renamed   Same path, new ID in .project.toml: asks before remapping
+  missing   Neither found: pick the project it became, forget it, or skip
+
+'pk doctor' runs the same comparison and reports what it finds.
+
+Example:
+  pk cache reconcile
+  pk cache reconcile --dry-run   # Show conflicts only
+  pk cache reconcile --yes       # Accept moves and renames, skip missing`,
+	Args: cobra.NoArgs,
+	Run:  runCacheReconcile,
+}
+
+var (
+	reconcileDryRun bool
+	reconcileYes    bool
+)
+
 func init() {
 	rootCmd.AddCommand(cacheCmd)
 	cacheCmd.AddCommand(cacheStatusCmd)
 	cacheCmd.AddCommand(cacheRefreshCmd)
 	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheReconcileCmd)
+
+	cacheRefreshCmd.Flags().StringSliceVar(&cacheRefreshRoots, "root", nil, "Rescan only this root (repeatable)")
+	cacheRefreshCmd.Flags().IntVar(&cacheRefreshDepth, "depth", 0, "Directory levels below each root to search (default [scan] max_depth; 0 for unlimited)")
+	cacheRefreshCmd.RegisterFlagCompletionFunc("root", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cacheRoots(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	cacheReconcileCmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "Show conflicts without changing anything")
+	cacheReconcileCmd.Flags().BoolVarP(&reconcileYes, "yes", "y", false, "Accept moves and renames without prompting")
 }
 
 func runCacheStatus(cmd *cobra.Command, args []string) {
+	if format := structuredFormat(cmd); format != "" {
+		info, err := cache.Inspect()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printStructured(format, info)
+		return
+	}
+
 	status, err := cache.Status()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -58,15 +137,18 @@ func runCacheStatus(cmd *cobra.Command, args []string) {
 }
 
 func runCacheRefresh(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
+	if cmd.Flags().Changed("depth") {
+		if cacheRefreshDepth < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --depth must be 0 (unlimited) or more\n")
+			os.Exit(1)
+		}
+		config.Scan.MaxDepth = cacheRefreshDepth
 	}
 
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scriptoriumDir := filepath.Join(homeDir, "scriptorium")
+	if len(cacheRefreshRoots) > 0 {
+		refreshRoots(cacheRefreshRoots)
+		return
+	}
 
 	fmt.Println("Refreshing cache...")
 
@@ -76,18 +158,203 @@ func runCacheRefresh(cmd *cobra.Command, args []string) {
 	}
 
 	// Rebuild cache
-	cache.RebuildCacheAsync(projectsDir, archiveDir, scriptoriumDir)
+	cache.RebuildCacheAsync(cacheRoots()...)
 
 	fmt.Println("\033[32m✓\033[0m Cache refresh triggered (rebuilding in background)")
 	fmt.Println("\nRun 'pk cache status' to check progress")
 }
 
+// refreshRoots rescans the named roots now, keeping the rest of the cache
+func refreshRoots(names []string) {
+	var roots []string
+	for _, name := range names {
+		root, err := filepath.Abs(importer.ExpandHome(name))
+		if err != nil || !slices.Contains(cacheRoots(), root) {
+			fmt.Fprintf(os.Stderr, "Error: '%s' is not a project root (use one of: %s)\n", name, strings.Join(cacheRoots(), ", "))
+			os.Exit(1)
+		}
+		roots = append(roots, root)
+	}
+
+	for _, root := range roots {
+		projects, err := cache.RefreshRoots(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\033[31m✗\033[0m %s: %v\n", root, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\033[32m✓\033[0m Rescanned %s: %d project(s)\n", root, len(projects))
+	}
+}
+
+// projectPaths resolves the project roots, honoring [paths] in config.toml
+func projectPaths() *paths.Resolver {
+	resolver, err := paths.NewResolver()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
+		os.Exit(1)
+	}
+	return resolver
+}
+
+// listedRoots are the roots of projects with aliases and in 'pk list':
+// projects and every archive directory
+func listedRoots(resolver *paths.Resolver) []string {
+	return append([]string{resolver.Projects()}, resolver.Archives()...)
+}
+
+// cacheRoots are the roots the project cache holds
+func cacheRoots() []string {
+	return projectPaths().AllRoots()
+}
+
 func runCacheClear(cmd *cobra.Command, args []string) {
 	if err := cache.InvalidateCache(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Also forget cached cloud context switches
+	if err := context.InvalidateSwitchCache(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not clear context cache: %v\n", err)
+	}
+
 	fmt.Println("\033[32m✓\033[0m Cache cleared")
 	fmt.Println("\nCache will be rebuilt on next 'pk session' or 'pk cache refresh'")
 }
+
+func runCacheReconcile(cmd *cobra.Command, args []string) {
+	current, err := findProjects(reconcileRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	conflicts := cache.Reconcile(cache.KnownProjects(), current)
+	if len(conflicts) == 0 {
+		fmt.Println("\033[32m✓\033[0m Cache, pins, and access history match disk")
+		return
+	}
+
+	remapped, forgotten, skipped := 0, 0, 0
+	for _, c := range conflicts {
+		fmt.Println(describeConflict(c))
+		if reconcileDryRun {
+			continue
+		}
+
+		switch c.Kind {
+		case cache.ConflictMoved:
+			if err := cache.RemapProject(c.OldID, c.NewID, c.NewPath); err != nil {
+				fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+				continue
+			}
+			remapped++
+
+		case cache.ConflictRenamed:
+			if !reconcileYes && !confirmDefaultYes(fmt.Sprintf("  Map '%s' → '%s'? (Y/n): ", c.OldID, c.NewID)) {
+				skipped++
+				continue
+			}
+			if err := cache.RemapProject(c.OldID, c.NewID, c.NewPath); err != nil {
+				fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+				continue
+			}
+			remapped++
+
+		case cache.ConflictMissing:
+			if reconcileYes {
+				skipped++
+				continue
+			}
+			target, forget := chooseCandidate(c)
+			switch {
+			case forget:
+				if err := cache.ForgetProject(c.OldID); err != nil {
+					fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+					continue
+				}
+				forgotten++
+			case target != nil:
+				if err := cache.RemapProject(c.OldID, target.ProjectInfo.ID, target.Path); err != nil {
+					fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+					continue
+				}
+				remapped++
+			default:
+				skipped++
+			}
+		}
+	}
+
+	if reconcileDryRun {
+		fmt.Printf("\n%d conflict(s); run without --dry-run to resolve\n", len(conflicts))
+		return
+	}
+
+	// The cache now reflects disk
+	if err := cache.SaveToCache(current); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not rebuild cache: %v\n", err)
+	}
+
+	fmt.Printf("\n\033[32m✓\033[0m Remapped %d, forgot %d, skipped %d\n", remapped, forgotten, skipped)
+}
+
+// reconcileRoots are all directories pk keeps state for projects in
+func reconcileRoots() []string {
+	resolver := projectPaths()
+	return append(resolver.AllRoots(), resolver.Scratch())
+}
+
+// describeConflict renders a conflict as a single status line
+func describeConflict(c cache.Conflict) string {
+	switch c.Kind {
+	case cache.ConflictMoved:
+		return fmt.Sprintf("\033[33m~\033[0m %s moved: %s → %s", c.OldID, c.OldPath, c.NewPath)
+	case cache.ConflictRenamed:
+		return fmt.Sprintf("\033[33m?\033[0m %s is now '%s' (%s)", c.OldID, c.NewID, c.NewPath)
+	}
+	return fmt.Sprintf("\033[31m?\033[0m %s not found (was %s)", c.OldID, c.OldPath)
+}
+
+// chooseCandidate asks which project a missing one became. Returns the chosen
+// project, or forget=true to drop its state; neither means skip.
+func chooseCandidate(c cache.Conflict) (target *config.Project, forget bool) {
+	const maxCandidates = 5
+	candidates := c.Candidates
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+
+	if last, ok := cache.LastAccessed(c.OldID); ok {
+		fmt.Printf("  Last opened %s\n", last.Format("2006-01-02"))
+	}
+	for i, p := range candidates {
+		fmt.Printf("  %d) %-24s %s\n", i+1, p.ProjectInfo.ID, p.Path)
+	}
+
+	if len(candidates) > 0 {
+		fmt.Printf("  Map to [1-%d], f to forget, Enter to skip: ", len(candidates))
+	} else {
+		fmt.Print("  f to forget its history and pins, Enter to skip: ")
+	}
+
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response == "f" {
+		return nil, true
+	}
+	if n, err := strconv.Atoi(response); err == nil && n >= 1 && n <= len(candidates) {
+		return candidates[n-1], false
+	}
+	return nil, false
+}
+
+// confirmDefaultYes prompts and treats anything but n/no as yes
+func confirmDefaultYes(prompt string) bool {
+	fmt.Print(prompt)
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response != "n" && response != "no"
+}
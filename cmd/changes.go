@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var changesSince string
+
+var changesCmd = &cobra.Command{
+	Use:   "changes [project]",
+	Short: "Review teammates' metadata changes from the team registry",
+	Long: `List the changes teammates made to shared project metadata, as pulled
+by 'pk sync remote': who archived what, who moved a project to production.
+
+--since last-pull (default) shows what the most recent pull brought in;
+a duration (7d, 36h) or date (2006-01-02) shows everything pulled since.
+A project name narrows it to that project.
+
+Example:
+  pk sync remote
+  pk changes                  # What the last pull brought in
+  pk changes --since 7d
+  pk changes conduit --since 2026-10-01`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runChanges,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(changesCmd)
+	changesCmd.Flags().StringVar(&changesSince, "since", "last-pull",
+		"last-pull, a duration (7d, 36h) or a date (2006-01-02)")
+}
+
+func runChanges(cmd *cobra.Command, args []string) {
+	lastPull, err := registry.LastPull()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read registry state: %v\n", err)
+		os.Exit(1)
+	}
+	if lastPull.IsZero() {
+		fmt.Println("Nothing pulled yet (run 'pk sync remote')")
+		return
+	}
+
+	since := lastPull
+	if changesSince != "last-pull" {
+		if since, err = parseSince(changesSince, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	changes, err := registry.Changes(since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read registry state: %v\n", err)
+		os.Exit(1)
+	}
+
+	shown := 0
+	for _, c := range changes {
+		if len(args) > 0 && !strings.EqualFold(c.ProjectID, args[0]) {
+			continue
+		}
+		line := c.String()
+		if c.Key != "" && c.From != "" {
+			line += fmt.Sprintf(" \033[90m(was %s)\033[0m", c.From)
+		}
+		fmt.Printf("%s  %s\n", c.At.Local().Format("2006-01-02 15:04"), line)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Printf("No changes pulled since %s\n", since.Local().Format("2006-01-02 15:04"))
+		return
+	}
+	fmt.Printf("\nLast pull: %s\n", lastPull.Local().Format("2006-01-02 15:04"))
+}
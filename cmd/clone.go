@@ -3,21 +3,43 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/detect"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/forge"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/datakaicr/pk/pkg/runner"
 	"github.com/spf13/cobra"
 )
 
-var cloneOpenSession bool
+var (
+	cloneOpenSession bool
+	cloneBranch      string
+	cloneSidecar     bool
+	cloneOrg         string
+	cloneUser        string
+	cloneLanguages   []string
+	cloneTopics      []string
+	cloneForks       bool
+	cloneArchived    bool
+	cloneJobs        int
+	cloneDryRun      bool
+)
 
 var cloneCmd = &cobra.Command{
-	Use:   "clone <git-url> [name]",
+	Use:   "clone <git-url> [name] | --org <org> | --user <user>",
 	Short: "Clone a git repository and create .project.toml",
-	Long: `Clone a git repository into ~/projects and automatically create a .project.toml file.
+	Long: `Clone a git repository into the projects root (~/projects unless [paths]
+projects is set in config.toml) and automatically create a .project.toml
+file.
 
 If the repository already contains a .project.toml, it will be preserved.
 Otherwise, a basic configuration will be created.
@@ -25,21 +47,62 @@ Otherwise, a basic configuration will be created.
 The project name is extracted from the repository URL by default, but can
 be overridden with the optional [name] argument.
 
+With --org or --user, every repository of a GitHub organization or user is
+cloned instead, several at a time (--jobs), each with a .project.toml whose
+links.repository, description, stack and domain come from GitHub. Forks and
+archived repositories are skipped unless asked for, as are repositories
+already in the projects root; --language and --topic narrow the list further.
+GITHUB_TOKEN, GH_TOKEN or the gh CLI's login is used when available, so
+private repositories are included.
+
 Examples:
   pk clone https://github.com/user/repo
   pk clone git@github.com:user/repo.git
   pk clone https://github.com/user/repo my-project
-  pk clone https://github.com/user/repo --session  # Open in tmux after cloning`,
-	Args: cobra.MinimumNArgs(1),
+  pk clone https://github.com/user/repo --session  # Open in tmux after cloning
+  pk clone https://github.com/user/repo --branch <TAB>  # Complete remote branches
+  pk clone git@github.com:client/repo.git --sidecar      # No pk files in the repository
+  pk clone --org acme --dry-run                          # What would be cloned
+  pk clone --org acme --language python --topic dbt
+  pk clone --user jdoe --forks`,
+	Args: cobra.RangeArgs(0, 2),
 	Run:  runClone,
 }
 
 func init() {
 	rootCmd.AddCommand(cloneCmd)
 	cloneCmd.Flags().BoolVarP(&cloneOpenSession, "session", "s", false, "Open in tmux session after cloning")
+	cloneCmd.Flags().StringVarP(&cloneBranch, "branch", "b", "", "Check out this branch instead of the remote's default")
+	cloneCmd.Flags().BoolVar(&cloneSidecar, "sidecar", false, "Keep metadata in ~/.local/share/pk/meta instead of the repository")
+	cloneCmd.Flags().StringVar(&cloneOrg, "org", "", "Clone every repository of a GitHub organization")
+	cloneCmd.Flags().StringVar(&cloneUser, "user", "", "Clone every repository of a GitHub user")
+	cloneCmd.Flags().StringSliceVar(&cloneLanguages, "language", nil, "With --org/--user: only repositories in these primary languages")
+	cloneCmd.Flags().StringSliceVar(&cloneTopics, "topic", nil, "With --org/--user: only repositories with one of these topics")
+	cloneCmd.Flags().BoolVar(&cloneForks, "forks", false, "With --org/--user: include forks")
+	cloneCmd.Flags().BoolVar(&cloneArchived, "archived", false, "With --org/--user: include archived repositories (status archived)")
+	cloneCmd.Flags().IntVarP(&cloneJobs, "jobs", "j", 4, "With --org/--user: repositories to clone at once")
+	cloneCmd.Flags().BoolVar(&cloneDryRun, "dry-run", false, "With --org/--user: list the repositories without cloning")
+	cloneCmd.MarkFlagsMutuallyExclusive("org", "user")
+	cloneCmd.RegisterFlagCompletionFunc("branch", validCloneBranches)
 }
 
 func runClone(cmd *cobra.Command, args []string) {
+	if cloneOrg != "" || cloneUser != "" {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --org and --user take no repository URL\n")
+			os.Exit(1)
+		}
+		if cloneBranch != "" || cloneOpenSession {
+			fmt.Fprintf(os.Stderr, "Error: --branch and --session apply to a single repository\n")
+			os.Exit(1)
+		}
+		runCloneOwner()
+		return
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: Give a repository URL, or --org or --user\n")
+		os.Exit(1)
+	}
 	gitURL := args[0]
 
 	// Extract project name from URL
@@ -50,13 +113,7 @@ func runClone(cmd *cobra.Command, args []string) {
 		projectName = args[1]
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	projectsDir := filepath.Join(homeDir, "projects")
+	projectsDir := projectPaths().Projects()
 	targetPath := filepath.Join(projectsDir, projectName)
 
 	// Check if project already exists
@@ -68,11 +125,17 @@ func runClone(cmd *cobra.Command, args []string) {
 	// Clone the repository
 	fmt.Printf("Cloning %s into %s...\n", gitURL, targetPath)
 
-	cloneCmd := exec.Command("git", "clone", gitURL, targetPath)
+	cloneArgs := []string{"clone"}
+	if cloneBranch != "" {
+		cloneArgs = append(cloneArgs, "--branch", cloneBranch)
+	}
+	cloneArgs = append(cloneArgs, gitURL, targetPath)
+
+	cloneCmd := runner.Command("git", cloneArgs...)
 	cloneCmd.Stdout = os.Stdout
 	cloneCmd.Stderr = os.Stderr
 
-	if err := cloneCmd.Run(); err != nil {
+	if err := runner.Run(cloneCmd); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to clone repository: %v\n", err)
 		os.Exit(1)
 	}
@@ -87,11 +150,14 @@ func runClone(cmd *cobra.Command, args []string) {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to create .project.toml: %v\n", err)
 		} else {
 			fmt.Println("✓ Created .project.toml")
+			hideMetadata(projectTomlPath, cloneSidecar)
 		}
 	} else {
 		fmt.Println("✓ Using existing .project.toml")
 	}
 
+	events.Emit(events.ProjectCreated, projectName, targetPath, map[string]string{"source": "clone", "url": gitURL})
+
 	// Invalidate cache to pick up new project
 	cache.InvalidateCache()
 
@@ -143,36 +209,167 @@ func extractProjectName(gitURL string) string {
 	return name
 }
 
-// createBasicProjectToml creates a minimal .project.toml file
+// createBasicProjectToml creates a minimal .project.toml file, filling in
+// what can be detected from the checkout
 func createBasicProjectToml(path, projectName, repoURL string) error {
-	content := fmt.Sprintf(`# Project Metadata
+	project := basicProject(path, projectName, repoURL)
+	return project.SaveAs(path)
+}
 
-[project]
-name = "%s"
-id = "%s"
-status = "active"
-type = "product"
+// basicProject is the metadata createBasicProjectToml writes to path
+func basicProject(path, projectName, repoURL string) *config.Project {
+	var project config.Project
+	project.ProjectInfo.Name = projectName
+	project.ProjectInfo.ID = projectName
+	project.ProjectInfo.Status = "active"
+	project.ProjectInfo.Type = "product"
+	project.Tech.Stack = []string{}
+	project.Tech.Domain = []string{}
+	project.Dates.Started = getCurrentDate()
+	project.Links.Repository = repoURL
+	detect.Apply(&project, filepath.Dir(path))
+	return &project
+}
 
-[ownership]
-primary = ""
+// runCloneOwner clones the repositories of a GitHub organization or user
+// that pass the filters and aren't in the projects root yet
+func runCloneOwner() {
+	owner, user := cloneOrg, false
+	if cloneUser != "" {
+		owner, user = cloneUser, true
+	}
+	if cloneJobs < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --jobs must be at least 1\n")
+		os.Exit(1)
+	}
 
-[tech]
-stack = []
-domain = []
+	projectsDir := projectPaths().Projects()
 
-[dates]
-started = "%s"
-completed = ""
+	spinner := progress.Spinner("Listing repositories of " + owner)
+	repos, err := forge.ListGitHubRepos(owner, user)
+	spinner.Stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list repositories of %s: %v\n", owner, err)
+		os.Exit(1)
+	}
 
-[links]
-repository = "%s"
-documentation = ""
+	var selected []forge.OwnedRepo
+	present := 0
+	for _, repo := range repos {
+		if !cloneSelects(repo) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(projectsDir, extractProjectName(repo.Name))); err == nil {
+			present++
+			continue
+		}
+		selected = append(selected, repo)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
 
-[notes]
-description = ""
-`, projectName, projectName, getCurrentDate(), repoURL)
+	if len(selected) == 0 {
+		fmt.Printf("No repositories to clone from %s (%d listed, %d already in %s)\n", owner, len(repos), present, projectsDir)
+		return
+	}
+	if cloneDryRun {
+		fmt.Printf("Would clone %d of %d repositories from %s:\n", len(selected), len(repos), owner)
+		for _, repo := range selected {
+			fmt.Printf("  %-30s %s\n", extractProjectName(repo.Name), truncate(repo.Description, 60))
+		}
+		return
+	}
+
+	if err := os.MkdirAll(projectsDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create %s: %v\n", projectsDir, err)
+		os.Exit(1)
+	}
+
+	dirs := make([]string, len(selected))
+	byDir := make(map[string]forge.OwnedRepo, len(selected))
+	idWidth := 0
+	for i, repo := range selected {
+		dirs[i] = filepath.Join(projectsDir, extractProjectName(repo.Name))
+		byDir[dirs[i]] = repo
+		idWidth = max(idWidth, min(len(filepath.Base(dirs[i])), 30))
+	}
 
-	return os.WriteFile(path, []byte(content), 0644)
+	task := progress.Counter("Cloning", len(dirs))
+	errs := git.ForEach(dirs, cloneJobs, func(dir string) error {
+		repo := byDir[dir]
+		return git.Clone(githubRemoteURL(repo.CloneURL, repo.SSHURL), dir)
+	}, func(dir string, err error) {
+		id := filepath.Base(dir)
+		task.Next(id)
+		id = padANSI(truncate(id, idWidth), idWidth)
+		if err != nil {
+			task.Printf("\033[31m✗\033[0m %s  %v\n", id, err)
+		} else {
+			task.Printf("\033[32m✓\033[0m %s  cloned\n", id)
+		}
+	})
+	task.Stop()
+
+	// Metadata is written afterwards, one project at a time, so its output
+	// doesn't interleave
+	cloned, failed := 0, 0
+	for i, dir := range dirs {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		cloned++
+		repo := byDir[dir]
+		id := filepath.Base(dir)
+		tomlPath := filepath.Join(dir, ".project.toml")
+		if _, err := os.Stat(tomlPath); os.IsNotExist(err) {
+			if err := ownedRepoProject(tomlPath, id, repo).SaveAs(tomlPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: Failed to create .project.toml: %v\n", id, err)
+			} else {
+				hideMetadata(tomlPath, cloneSidecar)
+			}
+		}
+		events.Emit(events.ProjectCreated, id, dir, map[string]string{"source": "clone", "url": repo.HTMLURL})
+	}
+	cache.InvalidateCache()
+
+	fmt.Printf("\nCloned %d of %d repositories from %s into %s", cloned, len(repos), owner, projectsDir)
+	if present > 0 {
+		fmt.Printf(", %d already there", present)
+	}
+	fmt.Printf(", %d failed\n", failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// cloneSelects reports whether a listed repository passes --forks,
+// --archived, --language and --topic
+func cloneSelects(repo forge.OwnedRepo) bool {
+	if (repo.Fork && !cloneForks) || (repo.Archived && !cloneArchived) {
+		return false
+	}
+	if len(cloneLanguages) > 0 && !slices.ContainsFunc(cloneLanguages, func(language string) bool {
+		return strings.EqualFold(language, repo.Language)
+	}) {
+		return false
+	}
+	if len(cloneTopics) > 0 && !slices.ContainsFunc(cloneTopics, func(topic string) bool {
+		return slices.ContainsFunc(repo.Topics, func(t string) bool { return strings.EqualFold(t, topic) })
+	}) {
+		return false
+	}
+	return true
+}
+
+// ownedRepoProject is the metadata for a repository cloned with --org or
+// --user: the basic metadata, plus what GitHub reports about it
+func ownedRepoProject(path, id string, repo forge.OwnedRepo) *config.Project {
+	project := basicProject(path, id, repo.HTMLURL)
+	if repo.Archived {
+		project.ProjectInfo.Status = "archived"
+	}
+	forge.Apply(project, &repo.Repo, forge.Changes(project, &repo.Repo, false))
+	return project
 }
 
 // getCurrentDate returns the current date in YYYY-MM-DD format
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+var compareAll bool
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <project> <project>",
+	Short: "Compare two projects side by side",
+	Long: `Show two projects' metadata, stack, activity and size side by side, to
+decide which of two prototypes or forks of a deliverable is the real one.
+
+Rows that differ are marked with ≠. Activity comes from pk's access history
+and git; size counts files outside VCS, dependency and build directories
+(the ones discovery skips). Rows empty for both projects are left out
+unless --all is given.
+
+Example:
+  pk compare acme-etl acme-etl-v2
+  pk compare dojo dojo-old --all`,
+	Args:              cobra.ExactArgs(2),
+	Run:               runCompare,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().BoolVar(&compareAll, "all", false, "Show rows that are empty for both projects")
+}
+
+// compareRow is one line of the comparison, or a section heading
+type compareRow struct {
+	label   string
+	values  [2]string
+	heading bool
+}
+
+func runCompare(cmd *cobra.Command, args []string) {
+	a := currentOrNamedProject(args[0])
+	b := currentOrNamedProject(args[1])
+	if a.Path == b.Path {
+		fmt.Fprintf(os.Stderr, "Error: '%s' and '%s' are the same project\n", args[0], args[1])
+		os.Exit(1)
+	}
+	projects := [2]*config.Project{a, b}
+
+	records, _ := cache.LoadAccessRecords()
+	field := func(label string, get func(p *config.Project) string) compareRow {
+		return compareRow{label: label, values: [2]string{get(a), get(b)}}
+	}
+
+	rows := []compareRow{
+		{label: "Project", heading: true},
+		field("Name", func(p *config.Project) string { return p.ProjectInfo.Name }),
+		field("Path", func(p *config.Project) string { return p.Path }),
+		field("Status", func(p *config.Project) string { return p.ProjectInfo.Status }),
+		field("Type", func(p *config.Project) string { return p.ProjectInfo.Type }),
+		field("Kind", func(p *config.Project) string { return p.ProjectInfo.Kind }),
+		field("Description", func(p *config.Project) string { return p.Notes.Description }),
+
+		{label: "Ownership", heading: true},
+		field("Owner", func(p *config.Project) string { return p.GetOwner() }),
+		field("Client", func(p *config.Project) string { return p.GetClientName() }),
+		field("Via", func(p *config.Project) string { return p.GetPartner() }),
+		field("Role", func(p *config.Project) string { return p.GetMyRole() }),
+		field("Visibility", func(p *config.Project) string { return p.DataKai.Visibility }),
+		field("Billable", func(p *config.Project) string {
+			if p.GetOwner() == "" && !p.Consultant.Billable {
+				return ""
+			}
+			return strconv.FormatBool(p.Consultant.Billable)
+		}),
+
+		{label: "Stack", heading: true},
+		field("Stack", func(p *config.Project) string { return strings.Join(p.Tech.Stack, ", ") }),
+		{label: "  shared", values: stackOverlap(a.Tech.Stack, b.Tech.Stack, true)},
+		{label: "  only here", values: stackOverlap(a.Tech.Stack, b.Tech.Stack, false)},
+		field("Domain", func(p *config.Project) string { return strings.Join(p.Tech.Domain, ", ") }),
+		field("Repository", func(p *config.Project) string { return p.Links.Repository }),
+
+		{label: "Activity", heading: true},
+		field("Started", func(p *config.Project) string { return p.Dates.Started }),
+		field("Completed", func(p *config.Project) string { return p.Dates.Completed }),
+		field("Last opened", func(p *config.Project) string {
+			record, ok := records[p.ProjectInfo.ID]
+			if !ok {
+				return ""
+			}
+			if count := openCount(record); count != "" {
+				return fmt.Sprintf("%s, %s", formatAccessTime(record.LastAccessed), count)
+			}
+			return formatAccessTime(record.LastAccessed)
+		}),
+		field("Last commit", lastCommit),
+		field("Commits", func(p *config.Project) string {
+			return gitOutput(p.Path, "rev-list", "--count", "HEAD")
+		}),
+	}
+
+	rows = append(rows, compareRow{label: "Size", heading: true})
+	var sizes [2]string
+	var files [2]string
+	for i, p := range projects {
+		count, bytes := projectSize(p.Path)
+		files[i] = strconv.Itoa(count)
+		sizes[i] = formatBytes(bytes)
+	}
+	rows = append(rows, compareRow{label: "Files", values: files}, compareRow{label: "Size", values: sizes})
+
+	printComparison(a.ProjectInfo.ID, b.ProjectInfo.ID, rows)
+}
+
+// printComparison renders rows as two columns, marking values that differ
+func printComparison(left, right string, rows []compareRow) {
+	const labelWidth, valueWidth = 14, 36
+
+	fmt.Printf("\n  %-*s \033[1;34m%-*s\033[0m \033[1;34m%s\033[0m\n", labelWidth, "", valueWidth, truncate(left, valueWidth), right)
+	for i, row := range rows {
+		if row.heading {
+			// Only if a row follows before the next heading
+			if hasRows(rows[i+1:], compareAll) {
+				fmt.Printf("\n\033[1m%s\033[0m\n", row.label)
+			}
+			continue
+		}
+		if row.values == [2]string{} && !compareAll {
+			continue
+		}
+
+		marker := " "
+		if row.values[0] != row.values[1] {
+			marker = "\033[33m≠\033[0m"
+		}
+		fmt.Printf("%s %-*s %-*s %s\n", marker, labelWidth, row.label,
+			valueWidth, truncate(orDash(row.values[0]), valueWidth), truncate(orDash(row.values[1]), valueWidth))
+	}
+	fmt.Println()
+}
+
+// hasRows reports whether rows up to the next heading would be printed
+func hasRows(rows []compareRow, all bool) bool {
+	for _, row := range rows {
+		if row.heading {
+			return false
+		}
+		if all || row.values != [2]string{} {
+			return true
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// stackOverlap lists the technologies both stacks share, or for each side
+// the ones only it has. Matching ignores case.
+func stackOverlap(a, b []string, shared bool) [2]string {
+	has := func(stack []string, tech string) bool {
+		return slices.ContainsFunc(stack, func(s string) bool { return strings.EqualFold(s, tech) })
+	}
+	pick := func(from, other []string) string {
+		var out []string
+		for _, tech := range from {
+			if has(other, tech) == shared {
+				out = append(out, tech)
+			}
+		}
+		return strings.Join(out, ", ")
+	}
+	if shared {
+		both := pick(a, b)
+		return [2]string{both, both}
+	}
+	return [2]string{pick(a, b), pick(b, a)}
+}
+
+// lastCommit is the date of HEAD's commit and how long ago it was
+func lastCommit(p *config.Project) string {
+	date := gitOutput(p.Path, "log", "-1", "--format=%cI")
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", t.Format("2006-01-02"), formatAccessTime(t))
+}
+
+// gitOutput runs a git command in dir, returning "" if it fails (not a
+// repository, no commits, no git)
+func gitOutput(dir string, args ...string) string {
+	output, err := runner.Output(runner.Command("git", append([]string{"-C", dir}, args...)...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// projectSize counts the files under dir and their total size, skipping
+// the directories discovery ignores
+func projectSize(dir string) (files int, bytes int64) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if rel, _ := filepath.Rel(dir, path); path != dir && config.Ignored(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			files++
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return files, bytes
+}
+
+// formatBytes renders a size in binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -2,49 +2,61 @@ package cmd
 
 import (
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/paths"
 	"github.com/spf13/cobra"
 )
 
 // validProjectNames returns list of project names/IDs for completion
 func validProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	homeDir, err := os.UserHomeDir()
+	return completeProjectIDs(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjectIDs returns "id<TAB>description" for projects matching
+// toComplete. The flat completion file written by sync, the daemon and every
+// cache rebuild is read directly; only without one does completion scan.
+func completeProjectIDs(toComplete string) []string {
+	completions, err := cache.LoadCompletions()
 	if err != nil {
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
+		resolver, err := paths.NewResolver()
+		if err != nil {
+			return nil
+		}
 
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scriptoriumDir := filepath.Join(homeDir, "scriptorium")
+		projects, err := cache.FindProjectsCached(resolver.AllRoots()...)
+		if err != nil {
+			return nil
+		}
 
-	// Use cached projects if available
-	projects, err := cache.FindProjectsCached(projectsDir, archiveDir, scriptoriumDir)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveNoFileComp
+		// The cache is saved in the background, which a completion process
+		// exits before; write the completion file now so the next TAB is fast
+		cache.SaveCompletions(projects)
+		if completions, err = cache.LoadCompletions(); err != nil {
+			return nil
+		}
 	}
 
 	var names []string
-	for _, p := range projects {
-		// Add project ID
-		if strings.HasPrefix(p.ProjectInfo.ID, toComplete) {
-			names = append(names, p.ProjectInfo.ID)
+	for _, c := range completions {
+		if !strings.HasPrefix(c.ID, toComplete) {
+			continue
+		}
+		if c.Description != "" {
+			names = append(names, c.ID+"\t"+c.Description)
+		} else {
+			names = append(names, c.ID)
 		}
 	}
-
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return names
 }
 
 // validScratchNames returns list of scratch project names for completion
 func validScratchNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
-
-	scratchDir := filepath.Join(homeDir, "scratch")
+	scratchDir := projectPaths().Scratch()
 
 	// Check if scratch directory exists
 	if _, err := os.Stat(scratchDir); os.IsNotExist(err) {
@@ -69,28 +81,10 @@ func validScratchNames(cmd *cobra.Command, args []string, toComplete string) ([]
 
 // validAllProjectNames returns both regular projects and scratch projects
 func validAllProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
-
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scriptoriumDir := filepath.Join(homeDir, "scriptorium")
-	scratchDir := filepath.Join(homeDir, "scratch")
+	scratchDir := projectPaths().Scratch()
 
 	// Get regular projects
-	projects, err := cache.FindProjectsCached(projectsDir, archiveDir, scriptoriumDir)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
-
-	var names []string
-	for _, p := range projects {
-		if strings.HasPrefix(p.ProjectInfo.ID, toComplete) {
-			names = append(names, p.ProjectInfo.ID)
-		}
-	}
+	names := completeProjectIDs(toComplete)
 
 	// Get scratch projects
 	if _, err := os.Stat(scratchDir); err == nil {
@@ -109,12 +103,45 @@ func validAllProjectNames(cmd *cobra.Command, args []string, toComplete string)
 
 // validListFilters returns valid filter options for pk list
 func validListFilters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	filters := []string{"active", "archived", "datakai", "westmonroe", "product", "client"}
 	var matches []string
-	for _, f := range filters {
+	for _, f := range listFilters {
 		if strings.HasPrefix(f, toComplete) {
 			matches = append(matches, f)
 		}
 	}
 	return matches, cobra.ShellCompDirectiveNoFileComp
 }
+
+// validFilterKeys completes the key of a --filter key=value term
+func validFilterKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var keys []string
+	for _, key := range config.FilterKeys() {
+		keys = append(keys, key+"=")
+	}
+	return filterPrefix(keys, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// validCloneBranches completes 'pk clone <url> --branch' from the remote's branches
+func validCloneBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	branches, err := git.CachedRemoteBranches("", args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return filterPrefix(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterPrefix returns the values starting with prefix
+func filterPrefix(values []string, prefix string) []string {
+	var matches []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			matches = append(matches, v)
+		}
+	}
+	return matches
+}
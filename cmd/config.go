@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and write settings and project metadata",
+	Long: `Read and write global settings (~/.config/pk/config.toml) and project
+metadata (.project.toml) without hand-editing TOML.
+
+With a project argument the command works on that project's .project.toml
+('.' for the project containing the current directory); without one it works
+on the global config. Keys are dotted TOML paths.
+
+Subcommands:
+  pk config get [project] <key>          Print one value
+  pk config set [project] <key> <value>  Validate and write one value
+  pk config list [project]               Print every value that is set
+
+Lists are comma-separated. Values are checked against the schema (enums,
+dates, types) before anything is written. Global settings are edited in
+place, keeping comments; .project.toml is rewritten in canonical form.
+
+Example:
+  pk config set dojo tech.stack go,postgres
+  pk config get dojo project.status
+  pk config set . consultant.billable true
+  pk config set shell.cd_hook false
+  pk config set formats.ids '{{.ProjectInfo.ID}}'
+  pk config list dojo
+  pk config list --keys          # Global keys pk config set accepts`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [project] <key>",
+	Short:             "Print one setting or metadata value",
+	Args:              cobra.RangeArgs(1, 2),
+	Run:               runConfigGet,
+	ValidArgsFunction: validConfigArgs,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set [project] <key> <value>",
+	Short:             "Validate and write one setting or metadata value",
+	Args:              cobra.RangeArgs(2, 3),
+	Run:               runConfigSet,
+	ValidArgsFunction: validConfigArgs,
+}
+
+var configListCmd = &cobra.Command{
+	Use:               "list [project]",
+	Short:             "Print every setting or metadata value that is set",
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runConfigList,
+	ValidArgsFunction: validConfigListArgs,
+}
+
+var configListKeys bool
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configListCmd.Flags().BoolVar(&configListKeys, "keys", false, "List the keys that can be set instead of values")
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	if len(args) == 2 {
+		project := configProject(args[0])
+		key := args[1]
+		if !isFieldKey(key) {
+			fmt.Fprintf(os.Stderr, "Error: Unknown key '%s' (see 'pk config list %s --keys')\n", key, args[0])
+			os.Exit(1)
+		}
+		fmt.Println(config.FieldValue(project, key))
+		return
+	}
+
+	value, set, err := settings.Get(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read config: %v\n", err)
+		os.Exit(1)
+	}
+	if !set {
+		// Like 'git config': unset is exit 1 with no output
+		os.Exit(1)
+	}
+	fmt.Println(value)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	if len(args) == 3 {
+		setProjectField(args[0], args[1], args[2])
+		return
+	}
+
+	key, value := args[0], args[1]
+	if err := settings.Set(key, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	configPath, _ := settings.ConfigFile()
+	fmt.Printf("\033[32m✓\033[0m %s = %s (%s)\n", key, value, configPath)
+}
+
+// setProjectField validates and writes one field of a project's .project.toml
+func setProjectField(name, key, value string) {
+	project := configProject(name)
+
+	if key == "project.id" {
+		fmt.Fprintf(os.Stderr, "Error: Use 'pk rename %s <new-id>' to change the ID; it also moves the directory and aliases\n",
+			project.ProjectInfo.ID)
+		os.Exit(1)
+	}
+
+	previousStatus := project.ProjectInfo.Status
+	if err := project.SetField(key, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Same checks as 'pk validate', limited to what this change touched
+	data, err := project.Encode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, d := range config.Validate(data) {
+		if d.Key == key {
+			fmt.Fprintf(os.Stderr, "Error: %s: %s\n", d.Key, d.Message)
+			os.Exit(1)
+		}
+	}
+
+	file := project.File()
+	if hasComments(file) {
+		fmt.Printf("\033[33mNote:\033[0m comments in %s are not preserved\n", file)
+	}
+	if err := project.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write %s: %v\n", file, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\033[32m✓\033[0m %s: %s = %s\n", project.ProjectInfo.ID, key, config.FieldValue(project, key))
+	cache.InvalidateCache()
+
+	// Archived projects are aliased in their own section
+	if project.ProjectInfo.Status != previousStatus {
+		syncScopes(syncAliases)
+	}
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	if len(args) == 1 {
+		if configListKeys {
+			for _, key := range config.FieldKeys() {
+				fmt.Println(key)
+			}
+			return
+		}
+		for _, f := range configProject(args[0]).Fields() {
+			fmt.Printf("%s = %s\n", f.Key, f.Value)
+		}
+		return
+	}
+
+	if configListKeys {
+		for _, key := range settings.Keys() {
+			fmt.Println(key)
+		}
+		return
+	}
+
+	fields, err := settings.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read config: %v\n", err)
+		os.Exit(1)
+	}
+	for _, f := range fields {
+		fmt.Printf("%s = %s\n", f.Key, f.Value)
+	}
+}
+
+// configProject loads a project by ID/name, or the current one for "."
+func configProject(name string) *config.Project {
+	if name == "." {
+		name = ""
+	}
+	return currentOrNamedProject(name)
+}
+
+func isFieldKey(key string) bool {
+	for _, k := range config.FieldKeys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// validConfigArgs completes project names, then project keys; a first
+// argument containing a dot is taken as a global key
+func validConfigArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		names, _ := validProjectNames(cmd, args, toComplete)
+		return append(names, filterPrefix(settings.Keys(), toComplete)...), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		if args[0] == "." || !strings.Contains(args[0], ".") {
+			return filterPrefix(config.FieldKeys(), toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func validConfigListArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return validProjectNames(cmd, args, toComplete)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var confirmCmd = &cobra.Command{
+	Use:   "confirm [project] [field...]",
+	Short: "Accept auto-detected metadata as correct",
+	Long: `Mark auto-detected metadata as user-set.
+
+clone, promote, and import fill in stack, domain, repository, and kind from
+what they find on disk, and record each guess in the [detected] section of
+.project.toml. 'pk show <name> --audit' lists them by confidence; once they
+look right (edit them first if not), confirm them so they are treated like
+any other value you wrote.
+
+With no fields, every detected field is confirmed. Without a project, the
+project containing the current directory is used.
+
+Example:
+  pk show acme --audit           # Review detected fields
+  pk confirm acme                # Accept all of them
+  pk confirm acme tech.domain    # Accept one`,
+	Run:               runConfirm,
+	ValidArgsFunction: validConfirmArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(confirmCmd)
+}
+
+func runConfirm(cmd *cobra.Command, args []string) {
+	name := ""
+	var fields []string
+	if len(args) > 0 {
+		name, fields = args[0], args[1:]
+	}
+
+	project := currentOrNamedProject(name)
+	if len(project.Detected) == 0 {
+		fmt.Printf("Nothing to confirm: %s has no auto-detected fields\n", project.ProjectInfo.ID)
+		return
+	}
+
+	for _, field := range fields {
+		if _, exists := project.Detected[field]; !exists {
+			fmt.Fprintf(os.Stderr, "Error: '%s' is not an auto-detected field (detected: %s)\n",
+				field, strings.Join(detectedKeys(project), ", "))
+			os.Exit(1)
+		}
+	}
+
+	tomlPath := project.File()
+	confirmed, err := confirmProjectToml(tomlPath, fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to update %s: %v\n", tomlPath, err)
+		os.Exit(1)
+	}
+
+	for _, key := range confirmed {
+		fmt.Printf("  %-18s %s\n", key, config.FieldValue(project, key))
+	}
+	fmt.Printf("\n\033[32m✓\033[0m Confirmed %d field(s) for '%s'\n", len(confirmed), project.ProjectInfo.ID)
+}
+
+// confirmProjectToml clears provenance for fields in the file on disk,
+// leaving everything else as written
+func confirmProjectToml(path string, fields []string) ([]string, error) {
+	project, err := config.LoadProject(path)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed := project.Confirm(fields...)
+	return confirmed, project.SaveAs(path)
+}
+
+func detectedKeys(p *config.Project) []string {
+	keys := make([]string, 0, len(p.Detected))
+	for key := range p.Detected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validConfirmArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return validProjectNames(cmd, args, toComplete)
+	}
+
+	projects, _ := cache.FindProjectsCached(cacheRoots()...)
+	for _, p := range projects {
+		if strings.EqualFold(p.ProjectInfo.ID, args[0]) || strings.EqualFold(p.ProjectInfo.Name, args[0]) {
+			return filterPrefix(detectedKeys(p), toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
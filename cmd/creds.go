@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+var credsForce bool
+
+var credsCmd = &cobra.Command{
+	Use:   "creds [project]",
+	Short: "Show which cloud logins are valid and when they expire",
+	Long: `Check every cloud login your projects' [context] sections depend on: AWS
+profiles (including SSO sessions), Azure subscriptions, gcloud Application
+Default Credentials, and kube contexts.
+
+Each login is checked once however many projects share it, using the same
+CLI calls the tools themselves make, so a valid row means commands will
+work. Expiry is shown where the provider reports it.
+
+Without a project, checks every project in ~/projects and ~/scriptorium.
+Exits non-zero when any login needs renewing.
+
+Example:
+  pk creds                 # Dashboard for all projects
+  pk creds acme-etl        # One project
+  pk creds refresh acme-etl`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runCreds,
+	ValidArgsFunction: validProjectNames,
+}
+
+var credsRefreshCmd = &cobra.Command{
+	Use:   "refresh [project]",
+	Short: "Log in to everything a project needs",
+	Long: `Re-authenticate every cloud login a project needs, one after another:
+aws sso login, az login, gcloud auth application-default login, and a kubectl
+call that lets exec credential plugins log in.
+
+Logins that are still valid are skipped unless --force is given. Without a
+project, uses the project in the current directory.
+
+Example:
+  pk creds refresh acme-etl
+  pk creds refresh --force   # Current project, even if still valid`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runCredsRefresh,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(credsCmd)
+	credsCmd.AddCommand(credsRefreshCmd)
+	credsRefreshCmd.Flags().BoolVar(&credsForce, "force", false, "Log in again even if still valid")
+}
+
+func runCreds(cmd *cobra.Command, args []string) {
+	var projects []*config.Project
+	if len(args) > 0 {
+		projects = []*config.Project{currentOrNamedProject(args[0])}
+	} else {
+		all, err := cache.FindProjectsCached(cacheRoots()...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+			os.Exit(1)
+		}
+		// Archived projects' logins don't need refreshing
+		archives := projectPaths().Archives()
+		for _, p := range all {
+			if !insideAny(p.Path, archives) {
+				projects = append(projects, p)
+			}
+		}
+	}
+
+	// One row per login, listing the projects that use it
+	var creds []pkcontext.Credential
+	users := make(map[string][]string)
+	for _, p := range projects {
+		for _, c := range pkcontext.Credentials(p) {
+			if _, seen := users[c.Key()]; !seen {
+				creds = append(creds, c)
+			}
+			users[c.Key()] = append(users[c.Key()], p.ProjectInfo.ID)
+		}
+	}
+
+	if len(creds) == 0 {
+		fmt.Println("No cloud logins configured (set them in a project's [context] section)")
+		return
+	}
+
+	invalid := 0
+	for _, c := range pkcontext.CheckCredentials(creds) {
+		printCredential(c, strings.Join(users[c.Key()], ", "))
+		if c.State == pkcontext.CredInvalid {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		fmt.Printf("\n%d login(s) need renewing; run 'pk creds refresh <project>'\n", invalid)
+		os.Exit(1)
+	}
+}
+
+func runCredsRefresh(cmd *cobra.Command, args []string) {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+	project := currentOrNamedProject(name)
+
+	creds := pkcontext.CheckCredentials(pkcontext.Credentials(project))
+	if len(creds) == 0 {
+		fmt.Printf("%s has no cloud logins in [context]\n", project.ProjectInfo.ID)
+		return
+	}
+
+	failed := 0
+	for _, c := range creds {
+		if c.State == pkcontext.CredValid && !credsForce {
+			fmt.Printf("\033[32m✓\033[0m %s %s still valid\n", c.Provider, c.Profile)
+			continue
+		}
+		fmt.Printf("\033[2m→ %s\033[0m\n", pkcontext.RefreshCommand(c))
+		if err := pkcontext.Refresh(c); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s %s login failed: %v\n", c.Provider, c.Profile, err)
+			failed++
+		}
+	}
+
+	fmt.Println()
+	invalid := 0
+	for _, c := range pkcontext.CheckCredentials(creds) {
+		printCredential(c, "")
+		if c.State != pkcontext.CredValid {
+			invalid++
+		}
+	}
+	if invalid > 0 || failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// printCredential prints one dashboard row
+func printCredential(c pkcontext.Credential, projects string) {
+	var mark, status string
+	switch c.State {
+	case pkcontext.CredValid:
+		mark, status = "\033[32m✓\033[0m", "valid"
+		if !c.Expires.IsZero() {
+			status += fmt.Sprintf(", expires in %s", time.Until(c.Expires).Round(time.Minute))
+		}
+	case pkcontext.CredInvalid:
+		mark, status = "\033[31m✗\033[0m", "needs login: "+truncate(c.Detail, 48)
+	default:
+		mark, status = "\033[33m?\033[0m", truncate(c.Detail, 48)
+	}
+	fmt.Printf("  %s %-7s %-28s %-40s %s\n", mark, c.Provider, c.Profile, status, projects)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/datakaicr/pk/pkg/daemon"
+	"github.com/datakaicr/pk/pkg/generated"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonNoStart    bool
+	daemonWarmPinned bool
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Background daemon that keeps the project cache warm",
+	Long: `Run pk in the background to keep the project cache warm, so the first
+'pk session' of the day is never slow.
+
+The daemon watches the project roots and updates the cache as soon as a
+.project.toml is added, changed, or removed, and rescans on a timer as a
+backstop. Mirror projects (mirror = true) are fetched every [mirror]
+fetch_interval (default 6h).
+
+With --warm-pinned the daemon also creates background tmux sessions for
+every pinned project when it starts (see 'pk warm').
+
+Subcommands:
+  pk daemon run         Run the daemon in the foreground
+  pk daemon install     Start the daemon at login (systemd user unit / launchd agent)
+  pk daemon uninstall   Stop the daemon and remove the login service`,
+}
+
+var daemonRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the daemon in the foreground",
+	Run:   runDaemonRun,
+}
+
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Start the daemon automatically at login",
+	Long: `Generate a login service that runs 'pk daemon run':
+
+  Linux: ~/.config/systemd/user/pk-daemon.service (enabled with systemctl --user)
+  macOS: ~/Library/LaunchAgents/com.datakai.pk.daemon.plist (loaded with launchctl)
+
+Example:
+  pk daemon install
+  pk daemon install --warm-pinned  # Also pre-create sessions for pins at login
+  pk daemon install --no-start     # Only write the service file`,
+	Run: runDaemonInstall,
+}
+
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop the daemon and remove the login service",
+	Run:   runDaemonUninstall,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonRunCmd)
+	daemonCmd.AddCommand(daemonInstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
+
+	daemonInstallCmd.Flags().BoolVar(&daemonNoStart, "no-start", false,
+		"Write the service file without enabling or starting it")
+	for _, c := range []*cobra.Command{daemonRunCmd, daemonInstallCmd} {
+		c.Flags().BoolVar(&daemonWarmPinned, "warm-pinned", false,
+			"Create background tmux sessions for pinned projects on start")
+	}
+}
+
+func runDaemonRun(cmd *cobra.Command, args []string) {
+	roots := cacheRoots()
+
+	// Stop cleanly on SIGINT/SIGTERM (systemd and launchd send SIGTERM)
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	if daemonWarmPinned {
+		warmDaemonSessions()
+	}
+
+	interval, err := loadSettings().MirrorFetchInterval()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	daemon.MirrorFetchInterval = interval
+
+	if err := daemon.Run(roots, stop); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// warmDaemonSessions is 'pk warm --pinned' for the daemon: failures are
+// logged, never fatal
+func warmDaemonSessions() {
+	if err := session.Check(); err != nil {
+		log.Printf("not warming sessions: %v", err)
+		return
+	}
+
+	projects, missing := warmTargets(true, nil)
+	for _, r := range session.OpenAll(projects, nil) {
+		switch {
+		case r.Err != nil:
+			log.Printf("warm %s: %v", r.Project.ProjectInfo.ID, r.Err)
+		case r.Created:
+			log.Printf("warmed session %s", r.Session)
+		}
+	}
+	for _, id := range missing {
+		log.Printf("warm %s: pinned project not found", id)
+	}
+}
+
+func runDaemonInstall(cmd *cobra.Command, args []string) {
+	serviceFile, err := daemon.ServiceFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not determine binary location: %v\n", err)
+		os.Exit(1)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+
+	content, err := daemon.RenderService(binary, daemon.ServiceOptions{WarmPinned: daemonWarmPinned})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(serviceFile), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create %s: %v\n", filepath.Dir(serviceFile), err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(serviceFile, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write service file: %v\n", err)
+		os.Exit(1)
+	}
+	generated.Record(serviceFile, generated.KindService, generated.ModeFile, "pk daemon install")
+
+	fmt.Printf("\033[32m✓\033[0m Service file written: %s\n", serviceFile)
+
+	enable := daemon.EnableCommand(serviceFile)
+	if daemonNoStart {
+		fmt.Printf("\nEnable it with:\n  %s\n", strings.Join(enable, " "))
+		return
+	}
+
+	if _, err := runner.LookPath(enable[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s not found, enable the service manually:\n  %s\n", enable[0], strings.Join(enable, " "))
+		return
+	}
+
+	// systemd needs to pick up the new unit before enabling it
+	if enable[0] == "systemctl" {
+		runner.Run(runner.Command("systemctl", "--user", "daemon-reload"))
+	}
+
+	enableCmd := runner.Command(enable[0], enable[1:]...)
+	enableCmd.Stdout = os.Stdout
+	enableCmd.Stderr = os.Stderr
+	if err := runner.Run(enableCmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to start service: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Start it manually with:\n  %s\n", strings.Join(enable, " "))
+		return
+	}
+
+	fmt.Printf("\033[32m✓\033[0m Daemon started (runs automatically at login)\n")
+}
+
+func runDaemonUninstall(cmd *cobra.Command, args []string) {
+	serviceFile, err := daemon.ServiceFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := os.Stat(serviceFile); os.IsNotExist(err) {
+		fmt.Println("Daemon service is not installed")
+		return
+	}
+
+	disable := daemon.DisableCommand(serviceFile)
+	if _, err := runner.LookPath(disable[0]); err == nil {
+		runner.Run(runner.Command(disable[0], disable[1:]...))
+	}
+
+	if err := os.Remove(serviceFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to remove service file: %v\n", err)
+		os.Exit(1)
+	}
+	generated.Forget(serviceFile)
+
+	fmt.Printf("\033[32m✓\033[0m Daemon stopped and service removed\n")
+}
@@ -3,38 +3,56 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/runner"
 	"github.com/datakaicr/pk/pkg/session"
+	"github.com/datakaicr/pk/pkg/trash"
 	"github.com/spf13/cobra"
 )
 
 var (
-	deleteKeepGit bool
-	deleteForce   bool
+	deleteKeepGit   bool
+	deleteForce     bool
+	deletePermanent bool
+	deleteKeepCode  bool
 )
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete <name>",
-	Short: "Delete a project",
-	Long: `Remove a project directory and all its contents.
+	Short: "Move a project to the trash",
+	Long: `Move a project directory and all its contents to the trash,
+~/.local/share/pk/trash, from where 'pk restore' brings it back.
 
 This will:
   1. Validate project exists
-  2. Check for active tmux session and optionally kill it
-  3. Optionally archive git history (--keep-git)
-  4. Remove entire project directory
-  5. Auto-sync shell aliases
+  2. Check for uncommitted or unpushed git changes, an active tmux
+     session, and pins, and list what it finds before asking
+  3. Kill the tmux session, if you agree
+  4. Optionally archive git history (--keep-git)
+  5. Move the project directory (and its sidecar metadata) to the trash
+  6. Auto-sync shell aliases
 
-WARNING: This operation is permanent. Data will be deleted.
+Pins and access history are kept, so a restored project picks up where it
+left off. --permanent removes the directory outright instead; that can't be
+undone. Empty the trash with 'pk restore --purge'.
+
+--keep-code removes the project from pk instead, for repositories that
+should stay on disk but out of pk's views: its metadata, aliases, cache
+entry, pins, access history, triage snoozes and journal notes go, and the
+directory is left exactly as it is. 'pk here' in the directory tracks it
+again.
 
 Example:
   pk delete old-project
   pk delete legacy-project --force         # Skip confirmation, auto-kill session
-  pk delete archived-proj --keep-git       # Save git history first`,
+  pk delete archived-proj --keep-git       # Save git history first
+  pk delete scratch-copy --permanent       # Skip the trash
+  pk delete vendored-fork --keep-code      # Stop tracking, keep the files`,
 	Args:              cobra.ExactArgs(1),
 	Run:               runDelete,
 	ValidArgsFunction: validProjectNames,
@@ -46,22 +64,19 @@ func init() {
 		"Archive git history before deletion")
 	deleteCmd.Flags().BoolVar(&deleteForce, "force", false,
 		"Skip confirmation prompt")
+	deleteCmd.Flags().BoolVar(&deletePermanent, "permanent", false,
+		"Delete outright instead of moving to the trash")
+	deleteCmd.Flags().BoolVar(&deleteKeepCode, "keep-code", false,
+		"Remove the project from pk but leave its directory untouched")
+	deleteCmd.MarkFlagsMutuallyExclusive("keep-code", "permanent")
+	deleteCmd.MarkFlagsMutuallyExclusive("keep-code", "keep-git")
 }
 
 func runDelete(cmd *cobra.Command, args []string) {
 	projectName := strings.ToLower(args[0])
 
 	// Find project
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-
-	projects, err := config.FindProjects(projectsDir, archiveDir)
+	projects, err := config.FindProjects(cacheRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
 		os.Exit(1)
@@ -82,19 +97,31 @@ func runDelete(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if deleteKeepCode {
+		forgetProject(found)
+		return
+	}
+
 	// Check for active tmux session
 	sessionName := session.SanitizeSessionName(found.ProjectInfo.ID)
 	hasSession := session.SessionExists(sessionName)
 
 	// Show confirmation prompt
 	if !deleteForce {
-		fmt.Printf("\033[33mWARNING: This will permanently delete the project.\033[0m\n\n")
+		if deletePermanent {
+			fmt.Printf("\033[33mWARNING: This will permanently delete the project.\033[0m\n\n")
+		} else {
+			fmt.Printf("This will move the project to the trash ('pk restore %s' brings it back).\n\n", found.ProjectInfo.ID)
+		}
 		fmt.Printf("Project:  %s\n", found.ProjectInfo.Name)
 		fmt.Printf("Location: %s\n", found.Path)
 		fmt.Printf("Status:   %s\n", found.ProjectInfo.Status)
 		if hasSession {
 			fmt.Printf("Tmux:     \033[33m● Active session found\033[0m\n")
 		}
+		for _, warning := range deleteWarnings(found) {
+			fmt.Printf("\033[33m⚠\033[0m %s\n", warning)
+		}
 		fmt.Println()
 
 		fmt.Print("Continue? (y/N): ")
@@ -142,8 +169,8 @@ func runDelete(cmd *cobra.Command, args []string) {
 
 			fmt.Printf("Archiving git history to: %s\n", archivePath)
 
-			tarCmd := exec.Command("tar", "czf", archivePath, "-C", found.Path, ".git")
-			if err := tarCmd.Run(); err != nil {
+			tarCmd := runner.Command("tar", "czf", archivePath, "-C", found.Path, ".git")
+			if err := runner.Run(tarCmd); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: Failed to archive git history: %v\n", err)
 				fmt.Print("Continue with deletion? (y/N): ")
 
@@ -162,17 +189,118 @@ func runDelete(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// Delete project directory
-	if err := os.RemoveAll(found.Path); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to delete project: %v\n", err)
+	if deletePermanent {
+		// Delete project directory
+		if err := os.RemoveAll(found.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to delete project: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\033[32m✓\033[0m Deleted: %s\n", found.Path)
+		if found.Sidecar() {
+			if err := os.Remove(found.File()); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to remove sidecar %s: %v\n", found.File(), err)
+			}
+		}
+		events.Emit(events.ProjectDeleted, found.ProjectInfo.ID, found.Path, nil)
+	} else {
+		entry, err := trash.Put(found)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Use --permanent to delete it without the trash.\n")
+			os.Exit(1)
+		}
+
+		fmt.Printf("\033[32m✓\033[0m Moved to trash: %s\n", entry.Dir)
+		events.Emit(events.ProjectDeleted, found.ProjectInfo.ID, found.Path, map[string]string{"trash": entry.Dir})
+	}
+
+	// Sync aliases
+	syncScopes(syncAliases)
+	cache.InvalidateCache()
+
+	fmt.Printf("\n\033[32m✓\033[0m Project '%s' deleted successfully\n", found.ProjectInfo.Name)
+	if !deletePermanent {
+		fmt.Printf("Restore it with: pk restore %s\n", found.ProjectInfo.ID)
+	}
+}
+
+// forgetProject removes a project from pk without touching its directory:
+// the metadata file goes, then pk's state for it, aliases and the cache
+func forgetProject(found *config.Project) {
+	id := found.ProjectInfo.ID
+	tomlPath := found.File()
+
+	if !deleteForce {
+		fmt.Printf("This will remove the project from pk. Its directory is left untouched.\n\n")
+		fmt.Printf("Project:  %s\n", found.ProjectInfo.Name)
+		fmt.Printf("Location: %s\n", found.Path)
+		fmt.Printf("Metadata: \033[33m%s will be deleted\033[0m\n", tomlPath)
+		if slot := cache.IsPinned(id); slot >= 0 {
+			fmt.Printf("\033[33m⚠\033[0m Pinned to slot %d\n", slot)
+		}
+		fmt.Println()
+
+		fmt.Print("Continue? (y/N): ")
+
+		var response string
+		fmt.Scanln(&response)
+
+		if strings.ToLower(response) != "y" {
+			fmt.Println("Cancelled")
+			return
+		}
+	}
+
+	if err := os.Remove(tomlPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to remove %s: %v\n", tomlPath, err)
 		os.Exit(1)
 	}
+	fmt.Printf("\033[32m✓\033[0m Removed metadata: %s\n", tomlPath)
 
-	fmt.Printf("\033[32m✓\033[0m Deleted: %s\n", found.Path)
+	if err := cache.DropState(id); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to remove pins and history: %v\n", err)
+	} else {
+		fmt.Printf("\033[32m✓\033[0m Pins, access history and journal removed\n")
+	}
+	events.Emit(events.ProjectDeleted, id, found.Path, map[string]string{"keep_code": "true"})
 
 	// Sync aliases
-	fmt.Println("Syncing aliases...")
-	runSync(cmd, []string{})
+	syncScopes(syncAliases)
+	cache.InvalidateCache()
 
-	fmt.Printf("\n\033[32m✓\033[0m Project '%s' deleted successfully\n", found.ProjectInfo.Name)
+	fmt.Printf("\n\033[32m✓\033[0m Project '%s' removed from pk; files kept at %s\n", found.ProjectInfo.Name, found.Path)
+	if session.SessionExists(session.SanitizeSessionName(id)) {
+		fmt.Printf("Its session is still running.\n")
+	}
+	fmt.Printf("Track it again with: cd %s && pk here\n", found.Path)
+}
+
+// deleteWarnings lists what deleting a project would lose or leave behind:
+// uncommitted and unpushed git changes, and pins
+func deleteWarnings(p *config.Project) []string {
+	var warnings []string
+	if gitOutput(p.Path, "rev-parse", "--git-dir") != "" {
+		if status := gitOutput(p.Path, "status", "--porcelain"); status != "" {
+			warnings = append(warnings, fmt.Sprintf("%d uncommitted change(s)", len(strings.Split(status, "\n"))))
+		}
+		if gitOutput(p.Path, "remote") == "" {
+			if gitOutput(p.Path, "rev-parse", "--verify", "-q", "HEAD") != "" {
+				warnings = append(warnings, "No git remote: the history exists only here")
+			}
+		} else if unpushed := gitOutput(p.Path, "log", "--branches", "--not", "--remotes", "--oneline"); unpushed != "" {
+			warnings = append(warnings, fmt.Sprintf("%d commit(s) not pushed to any remote", len(strings.Split(unpushed, "\n"))))
+		}
+		if stashes := gitOutput(p.Path, "stash", "list"); stashes != "" {
+			warnings = append(warnings, fmt.Sprintf("%d stash(es)", len(strings.Split(stashes, "\n"))))
+		}
+	}
+
+	pins, _ := cache.ListPins()
+	for _, pin := range pins {
+		if pin.ProjectID == p.ProjectInfo.ID {
+			warnings = append(warnings, fmt.Sprintf("Pinned to slot %d", pin.Slot))
+		}
+	}
+	return warnings
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/hooks"
+	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/spf13/cobra"
+)
+
+var (
+	demoteUnmanaged      bool
+	demoteRemoveMetadata bool
+	demoteForce          bool
+)
+
+var demoteCmd = &cobra.Command{
+	Use:   "demote <project>",
+	Short: "Convert a project back to scratch (reverse of promote)",
+	Long: `Convert a tracked project back into a scratch project, for experiments
+that were promoted prematurely.
+
+This will:
+  1. Move the directory to ~/scratch/<name> (skip with --unmanaged)
+  2. Park .project.toml as .project.toml.parked (or remove it with --remove-metadata)
+  3. Remove pins and access history for the project
+  4. Rebuild the cache and re-sync shell aliases
+
+With --unmanaged the directory stays where it is and simply stops being
+tracked by pk. A parked .project.toml can be restored by renaming it back.
+
+Example:
+  pk demote prototype                     # Back to ~/scratch/prototype
+  pk demote legacy-tool --unmanaged       # Leave in place, stop tracking
+  pk demote spike --remove-metadata       # Delete metadata instead of parking it`,
+	Args:              cobra.ExactArgs(1),
+	Run:               runDemote,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(demoteCmd)
+	demoteCmd.Flags().BoolVar(&demoteUnmanaged, "unmanaged", false,
+		"Leave directory in place instead of moving it to ~/scratch")
+	demoteCmd.Flags().BoolVar(&demoteRemoveMetadata, "remove-metadata", false,
+		"Delete .project.toml instead of parking it")
+	demoteCmd.Flags().BoolVar(&demoteForce, "force", false,
+		"Skip confirmation prompt")
+}
+
+func runDemote(cmd *cobra.Command, args []string) {
+	projectName := strings.ToLower(args[0])
+
+	projects, err := config.FindProjects(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var found *config.Project
+	for _, p := range projects {
+		if strings.ToLower(p.ProjectInfo.ID) == projectName ||
+			strings.ToLower(p.ProjectInfo.Name) == projectName {
+			found = p
+			break
+		}
+	}
+
+	if found == nil {
+		fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", args[0])
+		fmt.Fprintf(os.Stderr, "\nUse 'pk list' to see all projects.\n")
+		os.Exit(1)
+	}
+
+	destPath := found.Path
+	if !demoteUnmanaged {
+		destPath = filepath.Join(projectPaths().Scratch(), filepath.Base(found.Path))
+		if _, err := os.Stat(destPath); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: Scratch project already exists at %s\n", destPath)
+			fmt.Fprintf(os.Stderr, "Use --unmanaged to demote in place.\n")
+			os.Exit(1)
+		}
+	}
+
+	// Show confirmation prompt
+	if !demoteForce {
+		fmt.Printf("Project:  %s\n", found.ProjectInfo.Name)
+		fmt.Printf("Location: %s\n", found.Path)
+		if destPath != found.Path {
+			fmt.Printf("Move to:  %s\n", destPath)
+		}
+		if demoteRemoveMetadata {
+			fmt.Printf("Metadata: \033[33mwill be deleted\033[0m\n")
+		} else {
+			fmt.Printf("Metadata: parked as .project.toml.parked\n")
+		}
+		fmt.Print("\nDemote project? (y/N): ")
+
+		var response string
+		fmt.Scanln(&response)
+
+		if strings.ToLower(response) != "y" {
+			fmt.Println("Cancelled")
+			return
+		}
+	}
+
+	// Move to scratch first, so a failed move leaves the project as it was
+	tomlPath := found.File()
+	if destPath != found.Path {
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to create scratch directory: %v\n", err)
+			os.Exit(1)
+		}
+		if err := paths.Move(found.Path, destPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to move directory: %v\n", err)
+			os.Exit(1)
+		}
+		if rel, err := filepath.Rel(found.Path, tomlPath); err == nil && !strings.HasPrefix(rel, "..") {
+			tomlPath = filepath.Join(destPath, rel)
+		}
+		fmt.Printf("\033[32m✓\033[0m Moved to: %s\n", destPath)
+	}
+
+	// Park or remove metadata
+	if demoteRemoveMetadata {
+		err = os.Remove(tomlPath)
+	} else {
+		err = os.Rename(tomlPath, tomlPath+".parked")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to unregister .project.toml: %v\n", err)
+		if destPath != found.Path {
+			if err := paths.Move(destPath, found.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Failed to move %s back: %v\n", destPath, err)
+			}
+		}
+		os.Exit(1)
+	}
+	if demoteRemoveMetadata {
+		fmt.Printf("\033[32m✓\033[0m Removed metadata\n")
+	} else {
+		fmt.Printf("\033[32m✓\033[0m Parked metadata: %s.parked\n", tomlPath)
+	}
+
+	// Clean pins and access history
+	if cache.IsPinned(found.ProjectInfo.ID) != -1 {
+		if err := cache.RemovePinByProject(found.ProjectInfo.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to remove pin: %v\n", err)
+		} else {
+			fmt.Printf("\033[32m✓\033[0m Removed pin\n")
+		}
+	}
+	if err := cache.RemoveAccessRecord(found.ProjectInfo.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to clean access history: %v\n", err)
+	}
+
+	// Sync aliases
+	syncScopes(syncAliases)
+
+	// Invalidate cache for pk session
+	hooks.InvalidateCache()
+
+	fmt.Printf("\n\033[32m✓\033[0m Project '%s' demoted\n", found.ProjectInfo.ID)
+	if !demoteUnmanaged {
+		fmt.Printf("\nPromote again with:\n")
+		fmt.Printf("  pk promote %s\n", filepath.Base(destPath))
+	}
+}
@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/hooks"
 	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/shell"
 	"github.com/spf13/cobra"
 )
 
@@ -17,20 +23,35 @@ var doctorCmd = &cobra.Command{
 	Long: `Check pk installation, dependencies, and configuration for common issues.
 
 This command performs health checks on:
-  - Directory structure (~/projects, ~/archive, ~/scratch)
+  - Directory structure (projects, archive and archive_rules, scratch)
   - Dependencies (tmux, fzf)
   - Tmux configuration
   - Cache file integrity
-  - Stale path detection
   - Config file validity
+  - Stale and dangling pin/access records
+  - Moved or renamed projects (see 'pk cache reconcile')
+  - Known projects outside every project root
+  - Duplicate project IDs
+  - Aliases pointing at directories that no longer exist
+  - Metadata still on the legacy schema (see 'pk migrate')
+
+--fix repairs what it safely can: it creates missing directories, rebuilds
+a corrupted cache, drops pins and access records for projects that can't
+be found, regenerates stale aliases and migrates legacy metadata without
+comments. Duplicate IDs and projects outside the roots need a decision and
+are only reported.
 
 Example:
-  pk doctor`,
+  pk doctor
+  pk doctor --fix`,
 	Run: runDoctor,
 }
 
+var doctorFix bool
+
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the issues that can be fixed safely")
 }
 
 func runDoctor(cmd *cobra.Command, args []string) {
@@ -48,6 +69,9 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	} else {
 		checkDirectory(resolver.Projects(), "Projects directory", &issues)
 		checkDirectory(resolver.Archive(), "Archive directory", &issues)
+		for _, rule := range resolver.ArchiveRules() {
+			checkDirectory(rule.Path, "Archive for "+rule.Match, &issues)
+		}
 		checkDirectory(resolver.Scratch(), "Scratch directory", &issues)
 		checkDirectory(resolver.Scriptorium(), "Scriptorium directory", &issues)
 	}
@@ -56,7 +80,12 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	// Check 2: Dependencies
 	fmt.Println("🔧 Checking dependencies...")
 	checkCommand("tmux", "Required for 'pk session' and tmux keybindings", &issues)
-	checkCommand("fzf", "Required for interactive project selection", &issues)
+	if deps.Available("fzf") {
+		fmt.Printf("   ✓ fzf installed\n")
+	} else {
+		fmt.Printf("   ⚠ fzf not found - pickers use the built-in selector, without previews\n")
+		fmt.Printf("      %s\n", deps.Lookup("fzf").InstallHint())
+	}
 	fmt.Println()
 
 	// Check 3: Tmux configuration
@@ -79,20 +108,62 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	checkStalePaths(&issues)
 	fmt.Println()
 
+	// Check 7: Cache vs disk
+	fmt.Println("🔀 Checking for moved or renamed projects...")
+	checkReconcile(&issues)
+	checkOutsideRoots(&issues)
+	fmt.Println()
+
+	// Check 8: Project IDs
+	fmt.Println("🪪 Checking for duplicate project IDs...")
+	checkDuplicateIDs(&issues)
+	fmt.Println()
+
+	// Check 9: Aliases
+	fmt.Println("🔗 Checking shell aliases...")
+	checkOrphanedAliases(&issues)
+	fmt.Println()
+
+	// Check 10: Legacy schema
+	fmt.Println("📜 Checking for legacy metadata...")
+	checkLegacySchema(&issues)
+	fmt.Println()
+
 	// Summary
 	fmt.Println("════════════════════════════════════════")
-	if issues == 0 {
+	switch {
+	case issues == 0:
 		fmt.Println("✅ All checks passed! PK is healthy.")
-	} else {
+	case doctorFix:
+		fmt.Printf("⚠️  %d issue(s) could not be fixed automatically.\n", issues)
+	default:
 		fmt.Printf("⚠️  Found %d issue(s) that need attention.\n", issues)
+		fmt.Println("   Run: pk doctor --fix to repair what can be fixed safely")
 	}
 	fmt.Println("════════════════════════════════════════")
 }
 
+// fixed reports a repair made by --fix, or the error that stopped it.
+// It returns whether the issue is gone.
+func fixed(what string, err error) bool {
+	if err != nil {
+		fmt.Printf("      ❌ Could not fix: %v\n", err)
+		return false
+	}
+	fmt.Printf("      ✓ Fixed: %s\n", what)
+	return true
+}
+
 func checkDirectory(path, name string, issues *int) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		fmt.Printf("   ⚠️  %s does not exist: %s\n", name, path)
-		fmt.Printf("      Run: mkdir -p %s\n", path)
+		if doctorFix {
+			if fixed("created "+path, os.MkdirAll(path, 0755)) {
+				return
+			}
+		} else {
+			fmt.Printf("      Run: mkdir -p %s\n", path)
+		}
 		*issues++
 	} else {
 		fmt.Printf("   ✓ %s: %s\n", name, path)
@@ -100,11 +171,11 @@ func checkDirectory(path, name string, issues *int) {
 }
 
 func checkCommand(name, description string, issues *int) {
-	if _, err := exec.LookPath(name); err == nil {
+	if deps.Available(name) {
 		fmt.Printf("   ✓ %s installed\n", name)
 	} else {
 		fmt.Printf("   ❌ %s not found - %s\n", name, description)
-		fmt.Printf("      Install: apt install %s (Debian/Ubuntu) or brew install %s (macOS)\n", name, name)
+		fmt.Printf("      %s\n", deps.Lookup(name).InstallHint())
 		*issues++
 	}
 }
@@ -171,15 +242,25 @@ func checkCacheIntegrity(issues *int) {
 		return
 	}
 
-	if _, err := os.Stat(cacheFile); os.IsNotExist(err) {
+	if _, err := cache.BuiltAt(); errors.Is(err, cache.ErrStaleCache) {
+		fmt.Printf("   ℹ️  Cache written by another pk version (will be rebuilt on first use)\n")
+	} else if err != nil {
 		fmt.Printf("   ℹ️  Cache not yet built (will be created on first use)\n")
 	} else {
 		fmt.Printf("   ✓ Cache file exists: %s\n", cacheFile)
 
 		// Try to load cache
-		if _, err := cache.LoadFromCache(); err != nil {
+		if _, err := cache.LoadFromCache(); errors.Is(err, cache.ErrStaleCache) {
+			fmt.Printf("   ℹ️  Cache written by another pk version (will be rebuilt on first use)\n")
+		} else if err != nil {
 			fmt.Printf("   ❌ Cache file corrupted: %v\n", err)
-			fmt.Printf("      Run: pk cache clear && pk cache refresh\n")
+			if doctorFix {
+				if fixed("rebuilt the cache", rebuildCache()) {
+					return
+				}
+			} else {
+				fmt.Printf("      Run: pk cache clear && pk cache refresh\n")
+			}
 			*issues++
 		}
 	}
@@ -219,7 +300,7 @@ func checkStalePaths(issues *int) {
 		return
 	}
 
-	staleCount := 0
+	staleCount, dangling := 0, 0
 
 	// Check pins
 	pins, err := cache.LoadPins()
@@ -227,10 +308,15 @@ func checkStalePaths(issues *int) {
 		for _, pin := range pins {
 			if _, err := os.Stat(pin.ProjectPath); os.IsNotExist(err) {
 				// Try to find it
-				if _, err := resolver.FindProject(pin.ProjectID); err != nil {
-					fmt.Printf("   ⚠️  Pin [%d] %s: path not found\n", pin.Slot, pin.ProjectID)
+				if _, err := resolver.FindProject(pin.ProjectID); err == nil {
 					staleCount++
+					continue
+				}
+				fmt.Printf("   ⚠️  Pin [%d] %s: project not found\n", pin.Slot, pin.ProjectID)
+				if doctorFix && fixed(fmt.Sprintf("unpinned slot %d", pin.Slot), cache.RemovePin(pin.Slot)) {
+					continue
 				}
+				dangling++
 			}
 		}
 	}
@@ -241,18 +327,211 @@ func checkStalePaths(issues *int) {
 		for _, record := range records {
 			if _, err := os.Stat(record.ProjectPath); os.IsNotExist(err) {
 				// Try to find it
-				if _, err := resolver.FindProject(record.ProjectID); err != nil {
+				if _, err := resolver.FindProject(record.ProjectID); err == nil {
 					staleCount++
+					continue
 				}
+				fmt.Printf("   ⚠️  Access record %s: project not found\n", record.ProjectID)
+				if doctorFix && fixed("forgot "+record.ProjectID, cache.RemoveAccessRecord(record.ProjectID)) {
+					continue
+				}
+				dangling++
 			}
 		}
 	}
 
-	if staleCount == 0 {
+	if staleCount == 0 && dangling == 0 {
 		fmt.Printf("   ✓ All cached paths are valid\n")
-	} else {
+	}
+	if staleCount > 0 {
 		fmt.Printf("   ℹ️  Found %d stale path(s) - they will be auto-healed on next use\n", staleCount)
 	}
+	if dangling > 0 {
+		fmt.Printf("      Run: pk cache reconcile, or pk doctor --fix to drop them\n")
+		*issues += dangling
+	}
+}
+
+func checkReconcile(issues *int) {
+	current, err := findProjects(reconcileRoots()...)
+	if err != nil {
+		fmt.Printf("   ❌ Cannot scan projects: %v\n", err)
+		*issues++
+		return
+	}
+
+	conflicts := cache.Reconcile(cache.KnownProjects(), current)
+	if len(conflicts) == 0 {
+		fmt.Printf("   ✓ Cache, pins, and access history match disk\n")
+		return
+	}
+
+	for _, c := range conflicts {
+		fmt.Printf("   %s\n", describeConflict(c))
+	}
+	fmt.Printf("      Run: pk cache reconcile\n")
+	*issues += len(conflicts)
+}
+
+// checkOutsideRoots reports pinned or recently used projects that still
+// exist but live outside every project root, so scans never find them
+func checkOutsideRoots(issues *int) {
+	roots := reconcileRoots()
+	var outside []string
+	for id, path := range cache.KnownProjects() {
+		if path == "" || insideAny(path, roots) {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			outside = append(outside, fmt.Sprintf("%s (%s)", id, path))
+		}
+	}
+	if len(outside) == 0 {
+		return
+	}
+
+	sort.Strings(outside)
+	for _, o := range outside {
+		fmt.Printf("   ⚠️  %s is outside every project root\n", o)
+	}
+	fmt.Printf("      Move it under %s, or add its directory as a root in config.toml\n", projectPaths().Projects())
+	*issues += len(outside)
+}
+
+func checkDuplicateIDs(issues *int) {
+	projects, err := findProjects(reconcileRoots()...)
+	if err != nil {
+		fmt.Printf("   ❌ Cannot scan projects: %v\n", err)
+		*issues++
+		return
+	}
+
+	byID := make(map[string][]string)
+	for _, p := range projects {
+		id := strings.ToLower(p.ProjectInfo.ID)
+		byID[id] = append(byID[id], p.Path)
+	}
+	var duplicates []string
+	for id, dirs := range byID {
+		if len(dirs) > 1 {
+			duplicates = append(duplicates, id)
+		}
+	}
+	if len(duplicates) == 0 {
+		fmt.Printf("   ✓ %d project(s), every ID unique\n", len(projects))
+		return
+	}
+
+	sort.Strings(duplicates)
+	for _, id := range duplicates {
+		fmt.Printf("   ❌ %s is used by %d projects:\n", id, len(byID[id]))
+		for _, dir := range byID[id] {
+			fmt.Printf("      %s\n", dir)
+		}
+	}
+	fmt.Printf("      Run: pk rename <old> <new> on all but one of them\n")
+	*issues += len(duplicates)
+}
+
+func checkOrphanedAliases(issues *int) {
+	aliasFile := shell.ConfigPath(shell.Detect())
+	lines := readAliasLines(aliasFile)
+	if lines == nil {
+		fmt.Printf("   ℹ️  No alias file yet: %s\n", aliasFile)
+		return
+	}
+
+	orphaned := 0
+	for _, line := range lines {
+		target := aliasTarget(line)
+		if target == "" {
+			continue
+		}
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			fmt.Printf("   ⚠️  %s\n", strings.TrimSpace(strings.SplitN(line, "#", 2)[0]))
+			orphaned++
+		}
+	}
+	if orphaned == 0 {
+		fmt.Printf("   ✓ %d alias(es) point at existing directories\n", len(lines))
+		return
+	}
+
+	fmt.Printf("      %d alias(es) point at directories that no longer exist\n", orphaned)
+	if doctorFix {
+		if fixed("regenerated "+aliasFile, syncAliasesQuietly()) {
+			return
+		}
+	} else {
+		fmt.Printf("      Run: pk sync aliases\n")
+	}
+	*issues += orphaned
+}
+
+// aliasTarget returns the directory an alias or abbreviation cds into
+func aliasTarget(line string) string {
+	_, rest, ok := strings.Cut(line, "cd ")
+	if !ok {
+		return ""
+	}
+	if end := strings.IndexAny(rest, "\"' &;"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest
+}
+
+// syncAliasesQuietly regenerates the alias file without printing the diff
+func syncAliasesQuietly() error {
+	_, err := syncAliasScope(cacheRoots()...)
+	return err
+}
+
+func checkLegacySchema(issues *int) {
+	files, err := config.FindProjectFiles(reconcileRoots()...)
+	if err != nil {
+		fmt.Printf("   ❌ Cannot scan projects: %v\n", err)
+		*issues++
+		return
+	}
+
+	legacy, migrated := 0, 0
+	for _, file := range files {
+		project, err := config.LoadProject(file)
+		if err != nil || !project.Migrated() {
+			continue
+		}
+		fmt.Printf("   ⚠️  %s uses the legacy schema: %s\n", project.ProjectInfo.ID, file)
+		if doctorFix {
+			if hasComments(file) {
+				fmt.Printf("      Skipped: it has comments; run pk migrate %s to rewrite it anyway\n", project.ProjectInfo.ID)
+			} else if fixed("migrated "+project.ProjectInfo.ID, project.SaveAs(file)) {
+				migrated++
+				continue
+			}
+		}
+		legacy++
+	}
+
+	if migrated > 0 {
+		hooks.InvalidateCache()
+	}
+	if legacy == 0 && migrated == 0 {
+		fmt.Printf("   ✓ %d project file(s) on the current schema\n", len(files))
+		return
+	}
+	if legacy > 0 && !doctorFix {
+		fmt.Printf("      Run: pk migrate --dry-run\n")
+	}
+	*issues += legacy
+}
+
+// rebuildCache clears the cache and rescans every root
+func rebuildCache() error {
+	if err := cache.InvalidateCache(); err != nil {
+		return err
+	}
+	_, err := cache.RefreshRoots(cacheRoots()...)
+	return err
 }
 
 func containsString(haystack, needle string) bool {
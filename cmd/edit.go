@@ -1,33 +1,50 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
 
-	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/editor"
+	"github.com/datakaicr/pk/pkg/picker"
 	"github.com/spf13/cobra"
 )
 
+var editFile bool
+
 var editCmd = &cobra.Command{
 	Use:   "edit <name>",
 	Short: "Edit project metadata",
-	Long: `Open the project's .project.toml file in your default editor.
-
-The editor is determined by (in order):
+	Long: `Edit a project's metadata in a form: every field of .project.toml with
+its current value, and a fixed list to choose from for status, visibility,
+ownership and yes/no fields.
+
+  ↑/↓ or Tab   Move between fields
+  Enter        Edit a text field (Enter again to keep, Esc to undo)
+  ←/→          Choose a value for a listed field
+  Ctrl-S       Save
+  Esc          Cancel
+
+Each value is checked as it's entered, with the same rules as 'pk config
+set' and 'pk validate', plus any [[naming]] rules for the name. Lists are
+comma-separated; an empty value unsets an optional field. The project ID
+is shown but changed with 'pk rename'.
+
+With --file, open .project.toml in your editor instead, determined by (in
+order):
   1. $EDITOR environment variable
   2. vim
   3. nano
 
-After editing, the TOML is validated. If the project ID changed,
-aliases will be regenerated automatically.
+Use "." for the project you're in.
 
 Example:
   pk edit dojo
-  pk edit my-project`,
+  pk edit .
+  pk edit my-project --file`,
 	Args:              cobra.ExactArgs(1),
 	Run:               runEdit,
 	ValidArgsFunction: validProjectNames,
@@ -35,61 +52,138 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(editCmd)
+	editCmd.Flags().BoolVarP(&editFile, "file", "f", false, "Open .project.toml in $EDITOR instead of the form")
 }
 
 func runEdit(cmd *cobra.Command, args []string) {
-	projectName := strings.ToLower(args[0])
+	found := configProject(args[0])
+	if editFile {
+		editProjectFile(found)
+		return
+	}
+	editProjectForm(found)
+}
+
+// editProjectForm edits a project's fields in a form and saves the changes
+func editProjectForm(project *config.Project) {
+	var fields []picker.FormField
+	for _, key := range config.FieldKeys() {
+		field := picker.FormField{
+			Key:     key,
+			Value:   config.FieldValue(project, key),
+			Choices: config.FieldChoices(key),
+			Help:    config.FieldDescription(key),
+		}
+		switch key {
+		case "root":
+			continue // Set by pk for sidecar files
+		case "project.id":
+			field.ReadOnly = true
+			field.Help = "Use 'pk rename' to change the ID; it also moves the directory and aliases"
+		}
+		fields = append(fields, field)
+	}
 
-	// Find project
-	homeDir, err := os.UserHomeDir()
+	// Changes are tried on a copy so each is checked along with the earlier ones
+	draft, err := config.LoadProject(project.File())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: Failed to load %s: %v\n", project.File(), err)
 		os.Exit(1)
 	}
+	validate := func(key, value string) (err error) {
+		previous := config.FieldValue(draft, key)
+		if err := draft.SetField(key, value); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				draft.SetField(key, previous)
+			}
+		}()
+		data, err := draft.Encode()
+		if err != nil {
+			return err
+		}
+		for _, d := range config.Validate(data) {
+			if d.Key == key {
+				return fmt.Errorf("%s: %s", d.Key, d.Message)
+			}
+		}
+		violations, err := loadSettings().CheckNaming(draft)
+		if err != nil {
+			return err
+		}
+		for _, v := range violations {
+			if "project."+v.Field == key {
+				return v
+			}
+		}
+		return nil
+	}
 
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-
-	projects, err := config.FindProjects(projectsDir, archiveDir)
+	edited, err := picker.EditForm(fields, picker.FormOptions{
+		Title:    fmt.Sprintf("Edit %s (%s)", project.ProjectInfo.ID, project.File()),
+		Validate: validate,
+	})
+	if errors.Is(err, picker.ErrCancelled) {
+		fmt.Println("No changes saved")
+		return
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v (use --file to edit in $EDITOR)\n", err)
 		os.Exit(1)
 	}
 
-	var found *config.Project
-	for _, p := range projects {
-		if strings.ToLower(p.ProjectInfo.ID) == projectName ||
-			strings.ToLower(p.ProjectInfo.Name) == projectName {
-			found = p
-			break
+	previousStatus := project.ProjectInfo.Status
+	var changed []string
+	for i, field := range edited {
+		if field.Value == fields[i].Value {
+			continue
+		}
+		if err := project.SetField(field.Key, field.Value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		changed = append(changed, field.Key)
+	}
+	if len(changed) == 0 {
+		fmt.Println("No changes")
+		return
 	}
 
-	if found == nil {
-		fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", args[0])
-		fmt.Fprintf(os.Stderr, "\nUse 'pk list' to see all projects.\n")
+	file := project.File()
+	if hasComments(file) {
+		fmt.Printf("\033[33mNote:\033[0m comments in %s are not preserved\n", file)
+	}
+	if err := project.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write %s: %v\n", file, err)
 		os.Exit(1)
 	}
+	cache.InvalidateCache()
 
-	tomlPath := filepath.Join(found.Path, ".project.toml")
+	for _, key := range changed {
+		fmt.Printf("\033[32m✓\033[0m %s: %s = %s\n", project.ProjectInfo.ID, key, config.FieldValue(project, key))
+	}
+
+	// Archived projects are aliased in their own section
+	if project.ProjectInfo.Status != previousStatus {
+		syncScopes(syncAliases)
+	}
+}
+
+// editProjectFile opens a project's .project.toml in the user's editor and
+// checks it afterwards
+func editProjectFile(found *config.Project) {
+	tomlPath := found.File()
 
 	// Store original ID to detect changes
 	originalID := found.ProjectInfo.ID
 
-	// Determine editor
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vim"
-		// Check if vim exists, fallback to nano
-		if _, err := exec.LookPath("vim"); err != nil {
-			editor = "nano"
-		}
-	}
-
-	fmt.Printf("Opening %s in %s...\n", tomlPath, editor)
+	program := editor.Terminal()
+	fmt.Printf("Opening %s in %s...\n", tomlPath, program)
 
 	// Open editor
-	editorCmd := exec.Command(editor, tomlPath)
+	editorCmd := exec.Command(program, tomlPath)
 	editorCmd.Stdin = os.Stdin
 	editorCmd.Stdout = os.Stdout
 	editorCmd.Stderr = os.Stderr
@@ -100,20 +194,29 @@ func runEdit(cmd *cobra.Command, args []string) {
 	}
 
 	// Validate TOML
-	var project config.Project
-	if _, err := toml.DecodeFile(tomlPath, &project); err != nil {
+	project, err := config.LoadProject(tomlPath)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "\n\033[33mWarning: Invalid TOML syntax:\033[0m %v\n", err)
 		fmt.Fprintf(os.Stderr, "Please fix the file and run 'pk sync' when ready.\n")
 		os.Exit(1)
 	}
 
 	fmt.Printf("\n\033[32m✓\033[0m Metadata updated successfully\n")
+	cache.InvalidateCache()
+
+	// Rewrite legacy sections now rather than leaving them for every load to migrate
+	if project.Migrated() {
+		if err := project.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to migrate legacy sections: %v\n", err)
+		} else {
+			fmt.Printf("Migrated legacy [ownership]/[client] sections to the current schema\n")
+		}
+	}
 
 	// Check if ID changed
 	if project.ProjectInfo.ID != originalID {
 		fmt.Printf("\nProject ID changed: %s → %s\n", originalID, project.ProjectInfo.ID)
-		fmt.Println("Syncing aliases...")
-		runSync(cmd, []string{})
+		syncScopes(syncAliases)
 	}
 
 	fmt.Printf("\nProject: %s\n", project.ProjectInfo.Name)
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/editor"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	codeProfile     string
+	codeNoIsolation bool
+)
+
+var codeCmd = &cobra.Command{
+	Use:   "code [project]",
+	Short: "Open project in VS Code",
+	Long: `Open a project in VS Code instead of a tmux session.
+
+Records access and switches cloud/git context the same way 'pk session' does.
+If no project is specified, displays an interactive selector.
+
+The VS Code profile is chosen in this order:
+  1. --profile flag
+  2. [editor] vscode_profile in .project.toml
+  3. The client's profile in ~/.config/pk/config.toml:
+
+     [editor.vscode_profiles]
+     "Acme Corp" = "acme"
+
+Projects with visibility "client-confidential" open in an isolated,
+per-client VS Code (separate user data and extensions under
+~/.local/share/pk/vscode/<client>) so client work never mixes with personal
+extensions. pk keeps its settings.json in sync on every launch (telemetry
+off plus [editor.client_profile] settings) and installs any missing
+extensions from [editor.client_profile] / [editor.clients."<name>"].
+
+Example:
+  pk code                   # Interactive selector
+  pk code dojo              # Open dojo in VS Code
+  pk code dojo --profile x  # Open with a specific profile
+  pk code acme-etl --no-isolation  # Skip the isolated client profile`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return requireTools(cmd, editor.CheckVSCode())
+	},
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runCode,
+	ValidArgsFunction: validAllProjectNames,
+}
+
+var nvimCmd = &cobra.Command{
+	Use:   "nvim [project]",
+	Short: "Open project in a persistent Neovim server",
+	Long: `Open a project in a persistent Neovim instance instead of a tmux session.
+
+Each project gets a headless Neovim server listening on
+~/.cache/pk/nvim/<project>.sock. The first call starts the server in the
+project directory; later calls reattach to it, so buffers and state survive
+closing the terminal.
+
+Records access and switches cloud/git context the same way 'pk session' does.
+
+Example:
+  pk nvim              # Interactive selector
+  pk nvim dojo         # Start or reattach to dojo's Neovim server`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return requireTools(cmd, editor.CheckNvim())
+	},
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runNvim,
+	ValidArgsFunction: validAllProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(codeCmd)
+	rootCmd.AddCommand(nvimCmd)
+	codeCmd.Flags().StringVar(&codeProfile, "profile", "", "VS Code profile to open with")
+	codeCmd.Flags().BoolVar(&codeNoIsolation, "no-isolation", false,
+		"Open client-confidential projects in your normal VS Code")
+}
+
+func runCode(cmd *cobra.Command, args []string) {
+	project := resolveOpenTarget(args)
+	if project == nil {
+		return
+	}
+
+	cache.RecordAccess(project.ProjectInfo.ID, project.Path)
+	context.Switch(project)
+
+	s, _ := settings.Load()
+
+	profile := codeProfile
+	if profile == "" {
+		profile = editor.VSCodeProfile(project, s)
+	}
+
+	var isolated *editor.IsolatedProfile
+	if editor.NeedsIsolation(project) && !codeNoIsolation {
+		isolated = ensureClientProfile(project.GetClientName(), s)
+	}
+
+	if err := editor.OpenVSCode(project.Path, profile, isolated); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to open VS Code: %v\n", err)
+		os.Exit(1)
+	}
+
+	if isolated != nil {
+		fmt.Printf("\033[32m✓\033[0m Opened %s in isolated VS Code for %s\n", project.ProjectInfo.ID, isolated.Client)
+	} else if profile != "" {
+		fmt.Printf("\033[32m✓\033[0m Opened %s in VS Code (profile: %s)\n", project.ProjectInfo.ID, profile)
+	} else {
+		fmt.Printf("\033[32m✓\033[0m Opened %s in VS Code\n", project.ProjectInfo.ID)
+	}
+}
+
+// ensureClientProfile prepares the isolated client profile, installing missing extensions
+func ensureClientProfile(client string, s *settings.Settings) *editor.IsolatedProfile {
+	isolated, err := editor.EnsureClientProfile(client, s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to prepare client profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	missing, err := isolated.MissingExtensions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not list extensions for %s: %v\n", client, err)
+		return isolated
+	}
+
+	for _, ext := range missing {
+		fmt.Printf("Installing %s for %s...\n", ext, client)
+		if err := isolated.InstallExtension(ext); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to install %s: %v\n", ext, err)
+		}
+	}
+
+	return isolated
+}
+
+func runNvim(cmd *cobra.Command, args []string) {
+	project := resolveOpenTarget(args)
+	if project == nil {
+		return
+	}
+
+	cache.RecordAccess(project.ProjectInfo.ID, project.Path)
+	context.Switch(project)
+
+	socket, err := editor.NvimSocket(project.ProjectInfo.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not create socket directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !editor.NvimServerRunning(socket) {
+		if err := editor.StartNvimServer(project.Path, socket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to start Neovim server: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Wait for the server to start listening before attaching
+		if !editor.WaitForNvimServer(socket, 5*time.Second) {
+			fmt.Fprintf(os.Stderr, "Error: Neovim server did not start on %s\n", socket)
+			os.Exit(1)
+		}
+	}
+
+	if err := editor.AttachNvim(socket); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to attach to Neovim: %v\n", err)
+		os.Exit(1)
+	}
+}
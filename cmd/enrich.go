@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/forge"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	enrichAll       bool
+	enrichYes       bool
+	enrichOverwrite bool
+)
+
+var enrichCmd = &cobra.Command{
+	Use:   "enrich [project]",
+	Short: "Fill in metadata from the project's GitHub or GitLab repository",
+	Long: `Read a project's repository (links.repository) from the GitHub or GitLab
+API and offer to merge what it reports into .project.toml:
+
+  description       -> notes.description (when empty)
+  topics            -> tech.domain (added)
+  primary language  -> tech.stack (added)
+  license           -> consultant.license_model = open-source (when empty)
+
+The changes are shown as a diff and written only once you confirm. Merged
+fields are recorded as detected, so 'pk show --audit' lists them until you
+'pk confirm' them. Repositories archived upstream are pointed out.
+
+Set GITHUB_TOKEN (or GH_TOKEN) or GITLAB_TOKEN for private repositories
+and higher rate limits. Without a project, the project containing the
+current directory is used.
+
+Example:
+  pk enrich acme-etl
+  pk enrich --all              # Every project with a repository
+  pk enrich --all --yes        # Without prompting
+  pk enrich dojo --overwrite   # Replace description and license_model too`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runEnrich,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(enrichCmd)
+	enrichCmd.Flags().BoolVar(&enrichAll, "all", false, "Enrich every project that has a repository")
+	enrichCmd.Flags().BoolVarP(&enrichYes, "yes", "y", false, "Apply changes without prompting")
+	enrichCmd.Flags().BoolVar(&enrichOverwrite, "overwrite", false, "Replace a description or license_model that is already set")
+}
+
+func runEnrich(cmd *cobra.Command, args []string) {
+	if enrichAll && len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: Give a project or --all, not both\n")
+		os.Exit(1)
+	}
+
+	var projects []*config.Project
+	if enrichAll {
+		all, err := cache.FindProjectsCached(cacheRoots()...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+			os.Exit(1)
+		}
+		for _, p := range all {
+			if p.Links.Repository != "" {
+				projects = append(projects, p)
+			}
+		}
+		if len(projects) == 0 {
+			fmt.Println("No projects have links.repository set (fill it from git with 'pk sync links')")
+			return
+		}
+	} else {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		project := currentOrNamedProject(name)
+		if project.Links.Repository == "" {
+			fmt.Fprintf(os.Stderr, "Error: '%s' has no links.repository (fill it from git with 'pk sync links')\n",
+				project.ProjectInfo.ID)
+			os.Exit(1)
+		}
+		projects = []*config.Project{project}
+	}
+
+	updated, failed := 0, 0
+	for _, p := range projects {
+		switch enrichProject(p) {
+		case enrichUpdated:
+			updated++
+		case enrichFailed:
+			failed++
+		}
+	}
+
+	if updated > 0 {
+		cache.InvalidateCache()
+	}
+	if enrichAll {
+		fmt.Printf("\nEnriched %d of %d project(s)", updated, len(projects))
+		if failed > 0 {
+			fmt.Printf(", %d failed", failed)
+		}
+		fmt.Println()
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// Outcomes of enriching one project
+const (
+	enrichUnchanged = iota
+	enrichUpdated
+	enrichFailed
+)
+
+// enrichProject fetches a project's repository, shows the proposed
+// changes and writes them if confirmed
+func enrichProject(p *config.Project) int {
+	id := p.ProjectInfo.ID
+	task := progress.Spinner("Fetching " + id)
+	repo, err := forge.Fetch(p.Links.Repository)
+	task.Stop()
+	if err != nil {
+		fmt.Printf("\033[31m✗\033[0m %s: %v\n", id, err)
+		return enrichFailed
+	}
+
+	// Merge into the file as written, not the cached copy
+	file := p.File()
+	project, err := config.LoadProject(file)
+	if err != nil {
+		fmt.Printf("\033[31m✗\033[0m %s: Failed to load %s: %v\n", id, file, err)
+		return enrichFailed
+	}
+
+	changes := forge.Changes(project, repo, enrichOverwrite)
+	if repo.Archived && project.ProjectInfo.Status != "archived" {
+		fmt.Printf("\033[33mNote:\033[0m %s is archived on %s (pk archive %s?)\n", repo.Path, repo.Provider, id)
+	}
+	if len(changes) == 0 {
+		fmt.Printf("\033[32m✓\033[0m %s: up to date with %s\n", id, repo.Path)
+		return enrichUnchanged
+	}
+
+	fmt.Printf("\n%s \033[2m(%s)\033[0m\n", id, repo.Path)
+	for _, c := range changes {
+		if c.Old == "" {
+			fmt.Printf("  \033[32m+\033[0m %-26s %s\n", c.Key, c.New)
+		} else {
+			fmt.Printf("  \033[33m~\033[0m %-26s %s -> %s\n", c.Key, c.Old, c.New)
+		}
+	}
+
+	if !enrichYes {
+		fmt.Printf("Apply %d change(s) to %s? (y/N): ", len(changes), id)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			return enrichUnchanged
+		}
+	}
+
+	if err := forge.Apply(project, repo, changes); err != nil {
+		fmt.Printf("\033[31m✗\033[0m %s: %v\n", id, err)
+		return enrichFailed
+	}
+	if err := project.SaveAs(file); err != nil {
+		fmt.Printf("\033[31m✗\033[0m %s: Failed to write %s: %v\n", id, file, err)
+		return enrichFailed
+	}
+	fmt.Printf("\033[32m✓\033[0m Updated %d field(s) in %s\n", len(changes), id)
+	return enrichUpdated
+}
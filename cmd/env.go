@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var envApply bool
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Inspect project environment in tmux sessions",
+	Long: `Inspect the environment pk sets for project sessions.
+
+pk sets cloud context variables (AWS_PROFILE, CLOUDSDK_CORE_PROJECT,
+AZURE_SUBSCRIPTION_ID, DATABRICKS_CONFIG_PROFILE, SNOWFLAKE_ACCOUNT) from the
+[context] section, plus any env from the project's kind and the variables in
+[tmux.env], when it creates a tmux session. It also sets PK_PROJECT_ID, PK_CLIENT, PK_VISIBILITY and
+PK_BILLABLE ("true" or "false") so prompts and scripts can tell which
+project they're in.`,
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff [project]",
+	Short: "Show drift between a live session and current metadata",
+	Long: `Compare the environment of a running tmux session against what the
+project's current .project.toml would set, e.g. after editing [context].
+
+Reports each variable as:
+  missing   metadata sets it, session doesn't have it
+  changed   session has a different value
+  stale     session has it, metadata no longer sets it
+
+When drift is found, offers to re-inject the updated values into the session.
+New windows and panes pick them up; already-running shells keep their old
+environment until restarted.
+
+Without a project, uses the current tmux session.
+
+Example:
+  pk env diff              # Check the current session
+  pk env diff acme-etl     # Check another project's session
+  pk env diff --apply      # Re-inject without prompting`,
+	Args: cobra.MaximumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return requireTools(cmd, session.RequireTmux("'pk env diff'"))
+	},
+	Run:               runEnvDiff,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envDiffCmd)
+	envDiffCmd.Flags().BoolVar(&envApply, "apply", false, "Re-inject updated values without prompting")
+}
+
+func runEnvDiff(cmd *cobra.Command, args []string) {
+	var sessionName string
+	var err error
+	if len(args) == 0 {
+		sessionName, err = session.CurrentSession()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: No project given and %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var project *config.Project
+	for _, p := range projects {
+		if len(args) > 0 {
+			name := strings.ToLower(args[0])
+			if strings.ToLower(p.ProjectInfo.ID) == name || strings.ToLower(p.ProjectInfo.Name) == name {
+				project = p
+				break
+			}
+		} else if session.SanitizeSessionName(p.ProjectInfo.ID) == sessionName {
+			project = p
+			break
+		}
+	}
+
+	if project == nil {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", args[0])
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: Session '%s' is not a pk project\n", sessionName)
+		}
+		os.Exit(1)
+	}
+
+	sessionName = session.SanitizeSessionName(project.ProjectInfo.ID)
+	if !session.SessionExists(sessionName) {
+		fmt.Fprintf(os.Stderr, "Error: No active session for '%s'\n", project.ProjectInfo.ID)
+		os.Exit(1)
+	}
+
+	// Reload from disk: the cache may predate the metadata edit we're checking
+	current, err := config.LoadProject(project.File())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load .project.toml: %v\n", err)
+		os.Exit(1)
+	}
+
+	live, err := session.SessionEnvironment(sessionName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	changes := session.DiffEnv(live, pkcontext.Env(current), pkcontext.ManagedVars(current))
+	if len(changes) == 0 {
+		fmt.Printf("\033[32m✓\033[0m Session '%s' matches metadata\n", sessionName)
+		return
+	}
+
+	fmt.Printf("Environment drift in session '%s':\n\n", sessionName)
+	for _, c := range changes {
+		switch c.Kind() {
+		case "missing":
+			fmt.Printf("  \033[32m+\033[0m %-28s %s\n", c.Key, c.Expected)
+		case "stale":
+			fmt.Printf("  \033[31m-\033[0m %-28s %s\n", c.Key, c.Current)
+		default:
+			fmt.Printf("  \033[33m~\033[0m %-28s %s -> %s\n", c.Key, c.Current, c.Expected)
+		}
+	}
+	fmt.Println()
+
+	if !envApply {
+		fmt.Print("Re-inject updated values into the session? (y/N): ")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			return
+		}
+	}
+
+	if err := session.ApplyEnvChanges(sessionName, changes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\033[32m✓\033[0m Updated %d variable(s) in '%s'\n", len(changes), sessionName)
+	fmt.Println("  New windows and panes use the new values; restart existing shells to pick them up")
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventsFollow bool
+	eventsType   string
+	eventsLimit  int
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show pk's event stream as JSON lines",
+	Long: `Print pk's internal events as JSON lines, one per event, for building
+your own automation (time trackers, OBS scene switching, notifications).
+
+Events are appended to ~/.cache/pk/events.jsonl by pk commands and by the
+background daemon ('pk daemon run'), which also reports projects that
+appear or disappear on disk outside pk.
+
+Event types:
+  project.created    pk new, pk clone, pk promote
+  project.archived   pk archive
+  project.unarchived pk unarchive
+  project.deleted    pk delete
+  project.detected   new project found on disk (daemon)
+  project.gone       project vanished from disk (daemon)
+  project.annotated  pk annotate
+  session.opened     pk session / jump / sessions
+  session.attached   tmux client attached or switched to a session (tmux hooks)
+  session.detached   tmux client detached (tmux hooks)
+  access.recorded    project access (including the shell cd hook)
+  cache.rebuilt      project cache written
+
+Example:
+  pk events                          # Last 20 events
+  pk events --follow                 # Stream new events as they happen
+  pk events -f --type session        # Only session.* events
+  pk events -f | jq -r .project_id   # Feed into other tools`,
+	Args: cobra.NoArgs,
+	Run:  runEvents,
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.Flags().BoolVarP(&eventsFollow, "follow", "f", false, "Stream new events as they are emitted")
+	eventsCmd.Flags().StringVarP(&eventsType, "type", "t", "", "Only events of this type or type prefix (e.g. session)")
+	eventsCmd.Flags().IntVarP(&eventsLimit, "limit", "n", 20, "Number of past events to print (0 for all)")
+}
+
+func runEvents(cmd *cobra.Command, args []string) {
+	// When following, print history only if explicitly requested
+	if !eventsFollow || cmd.Flags().Changed("limit") {
+		past, err := events.Read(eventsLimit, eventsType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to read events: %v\n", err)
+			os.Exit(1)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		for _, e := range past {
+			encoder.Encode(e)
+		}
+	}
+
+	if !eventsFollow {
+		return
+	}
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	if err := events.Follow(os.Stdout, eventsType, 250*time.Millisecond, stop); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/graph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportSince       string
+	exportFormat      string
+	exportGraphFormat string
+	exportGraphAll    bool
+	exportGraphFilter []string
+)
+
+// activityLookback is how far before --since pk looks for the attach that
+// began an interval still running at --since
+const activityLookback = 24 * time.Hour
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export pk data for other tools",
+	Long: `Export data pk records in formats other tools understand.
+
+Subcommands:
+  pk export activity   # Time attached to each project's session
+  pk export graph      # Clients, partners, projects and dependencies as a graph`,
+}
+
+var exportActivityCmd = &cobra.Command{
+	Use:   "activity",
+	Short: "Export time spent in project sessions as ActivityWatch or Timing JSON",
+	Long: `Export the intervals tmux clients spent attached to each project's session,
+so a desktop time tracker can file the time under pk's projects.
+
+Intervals come from session.attached / session.detached events, recorded by
+the tmux hooks that 'pk install --tmux-bindings' adds. An interval runs from
+attaching (or switching) to a session until that client detaches or
+switches away; one still running ends now. Sessions that aren't pk projects
+and mirrors (mirror = true) are left out.
+
+Formats:
+  aw       ActivityWatch bucket export (aw-server import, or POST /api/0/import)
+  timing   Timing time entries: startDate, endDate, duration, project, title;
+           project is "Client ▸ project-id" for client projects
+
+--since takes a duration (90m, 36h, 7d) or a date (2006-01-02). History is
+limited to what the event log still holds (about two megabytes).
+
+Example:
+  pk export activity --since 7d > pk-week.json
+  pk export activity --since 2026-10-01 --format timing`,
+	Args: cobra.NoArgs,
+	Run:  runExportActivity,
+}
+
+var exportGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export clients, partners, projects and dependencies as DOT or GraphML",
+	Long: `Export the portfolio as a directed graph for Graphviz or Conduit:
+
+  project → client    consultant.client_name
+  project → partner   consultant.partner
+  partner → client    a partner serves a client through some project
+  project → project   relations.depends_on; IDs that aren't projects are
+                      drawn as external dependencies (dashed)
+
+Node IDs are prefixed with their kind (client:Acme Corp, project:etl).
+Archived projects are included with --all; --filter narrows the projects
+(see 'pk session --filter').
+
+Formats:
+  dot       Graphviz, e.g. pk export graph | dot -Tsvg > portfolio.svg
+  graphml   GraphML with label, kind and relation attributes
+
+Example:
+  pk export graph | dot -Tsvg > portfolio.svg
+  pk export graph --format graphml > portfolio.graphml
+  pk export graph --filter client=Acme`,
+	Args: cobra.NoArgs,
+	Run:  runExportGraph,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportActivityCmd, exportGraphCmd)
+	exportActivityCmd.Flags().StringVar(&exportSince, "since", "24h", "Start of the export: duration (7d, 36h) or date (2006-01-02)")
+	exportActivityCmd.Flags().StringVar(&exportFormat, "format", "aw", "Output format: aw or timing")
+	exportActivityCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"aw", "timing"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	exportGraphCmd.Flags().StringVar(&exportGraphFormat, "format", "dot", "Output format: dot or graphml")
+	exportGraphCmd.Flags().BoolVarP(&exportGraphAll, "all", "a", false, "Include archived projects")
+	exportGraphCmd.Flags().StringArrayVar(&exportGraphFilter, "filter", nil, "Only projects matching key=value terms (e.g. client=Acme)")
+	exportGraphCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"dot", "graphml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	exportGraphCmd.RegisterFlagCompletionFunc("filter", validFilterKeys)
+}
+
+func runExportGraph(cmd *cobra.Command, args []string) {
+	var write func(io.Writer, *graph.Graph) error
+	switch exportGraphFormat {
+	case "dot":
+		write = graph.WriteDOT
+	case "graphml":
+		write = graph.WriteGraphML
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown format '%s' (use dot or graphml)\n", exportGraphFormat)
+		os.Exit(1)
+	}
+
+	filter, err := config.ParseFilter(exportGraphFilter...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var included []*config.Project
+	for _, p := range projects {
+		if (exportGraphAll || p.ProjectInfo.Status != "archived") && filter.Matches(p) {
+			included = append(included, p)
+		}
+	}
+
+	if err := write(os.Stdout, graph.Build(included)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runExportActivity(cmd *cobra.Command, args []string) {
+	now := time.Now()
+	since, err := parseSince(exportSince, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	log, err := events.ReadSince(since.Add(-activityLookback), "session")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read events: %v\n", err)
+		os.Exit(1)
+	}
+	byID := activityProjects()
+	intervals := withoutMirrors(events.Intervals(log, since, now), byID)
+
+	var out interface{}
+	switch exportFormat {
+	case "aw":
+		hostname, _ := os.Hostname()
+		out = events.ActivityWatch(intervals, hostname, activityTitle(byID, false))
+	case "timing":
+		out = events.Timing(intervals, activityTitle(byID, true))
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown format '%s' (use aw or timing)\n", exportFormat)
+		os.Exit(1)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+
+	if len(intervals) == 0 {
+		fmt.Fprintf(os.Stderr, "No session activity since %s (are the tmux hooks installed? 'pk install --tmux-bindings')\n",
+			since.Format("2006-01-02 15:04"))
+	}
+}
+
+// activityProjects indexes the projects activity is filed under by ID
+func activityProjects() map[string]*config.Project {
+	projects, _ := cache.FindProjectsCached(cacheRoots()...)
+	byID := make(map[string]*config.Project)
+	for _, p := range projects {
+		byID[p.ProjectInfo.ID] = p
+	}
+	return byID
+}
+
+// withoutMirrors drops time spent in mirror projects, which is reading
+// reference code rather than billable work
+func withoutMirrors(intervals []events.Interval, byID map[string]*config.Project) []events.Interval {
+	var kept []events.Interval
+	for _, i := range intervals {
+		if p, ok := byID[i.ProjectID]; ok && p.ProjectInfo.Mirror {
+			continue
+		}
+		kept = append(kept, i)
+	}
+	return kept
+}
+
+// activityTitle names an interval's project by its name, or for Timing's
+// project hierarchy as "Client ▸ id"
+func activityTitle(byID map[string]*config.Project, hierarchy bool) func(events.Interval) string {
+	return func(i events.Interval) string {
+		p, ok := byID[i.ProjectID]
+		switch {
+		case !ok:
+			return i.ProjectID
+		case hierarchy && p.GetClientName() != "":
+			return p.GetClientName() + " ▸ " + p.ProjectInfo.ID
+		case hierarchy:
+			return p.ProjectInfo.ID
+		case p.ProjectInfo.Name != "":
+			return p.ProjectInfo.Name
+		}
+		return p.ProjectInfo.ID
+	}
+}
+
+// parseSince reads a duration back from now (Go durations plus "d" for
+// days) or a local date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s' (use a duration like 7d or 36h, or a date like 2006-01-02)", value)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/output"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFormat string
+	outputJSON   bool
+)
+
+// checkOutputFormat rejects a global --format other than table, json or
+// yaml. Commands with their own --format flag check it themselves.
+func checkOutputFormat(cmd *cobra.Command) error {
+	if cmd.Flags().Lookup("format") != rootCmd.PersistentFlags().Lookup("format") {
+		return nil
+	}
+	if !slices.Contains(output.Formats, outputFormat) {
+		return fmt.Errorf("unknown --format '%s' (use %s)", outputFormat, strings.Join(output.Formats, ", "))
+	}
+	return nil
+}
+
+// structuredFormat returns json or yaml when a command's output should be
+// machine-readable (--json, or --format json|yaml), and "" for its table
+func structuredFormat(cmd *cobra.Command) string {
+	if outputJSON {
+		return output.FormatJSON
+	}
+	if format := cmd.Flags().Lookup("format"); format != nil && output.Structured(format.Value.String()) {
+		return format.Value.String()
+	}
+	return ""
+}
+
+// printStructured writes v as JSON or YAML to stdout
+func printStructured(format string, v any) {
+	if err := output.Encode(os.Stdout, format, v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// templateFormat reports whether a command's own --format value is a
+// template or saved format name rather than table, json or yaml
+func templateFormat(format string) bool {
+	return format != "" && format != output.FormatTable && !output.Structured(format)
+}
+
+// projectDocument is a project as JSON and YAML output show it: its fields
+// keyed like .project.toml, plus its path
+func projectDocument(p *config.Project) map[string]any {
+	doc := p.Document()
+	doc["path"] = p.Path
+	return doc
+}
+
+// projectDocuments maps projectDocument over projects, never returning nil
+// so an empty result encodes as an empty list
+func projectDocuments(projects []*config.Project) []map[string]any {
+	docs := make([]map[string]any, 0, len(projects))
+	for _, p := range projects {
+		docs = append(docs, projectDocument(p))
+	}
+	return docs
+}
+
+// renderFormat prints projects with a --format template or saved format name
+func renderFormat(format string, projects []*config.Project) {
+	s, err := settings.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load saved formats: %v\n", err)
+	}
+
+	tmpl, err := output.ParseTemplate(output.ResolveFormat(format, s.Formats))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := output.RenderEach(os.Stdout, tmpl, projects); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/generated"
+	"github.com/spf13/cobra"
+)
+
+var generatedForce bool
+
+var generatedCmd = &cobra.Command{
+	Use:   "generated",
+	Short: "Manage files pk has written outside project directories",
+	Long: `Show and manage everything pk has generated outside project directories,
+such as shell alias files, shell completions, and tmux keybinding blocks,
+plus the badge blocks 'pk sync badges' fills in project READMEs.
+
+Files are tracked in ~/.local/share/pk/generated.json. Files pk fully owns
+are deleted on clean; for user files (like ~/.tmux.conf) only pk's managed
+block is removed, and README badge blocks are emptied. pk never writes
+.envrc files ('pk alias export' only prints the line to add), so they are
+not tracked.
+
+Subcommands:
+  pk generated list [kind]         Show generated files
+  pk generated clean [kind]        Remove generated files
+  pk generated regenerate [kind]   Rewrite generated files
+
+Kinds: aliases, completion, tmux-bindings, service, bin-dir, badges`,
+}
+
+var generatedListCmd = &cobra.Command{
+	Use:               "list [kind]",
+	Short:             "Show generated files",
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runGeneratedList,
+	ValidArgsFunction: validGeneratedKinds,
+}
+
+var generatedCleanCmd = &cobra.Command{
+	Use:   "clean [kind]",
+	Short: "Remove generated files",
+	Long: `Remove files generated by pk, optionally limited to one kind.
+
+Example:
+  pk generated clean                 # Remove everything pk generated
+  pk generated clean tmux-bindings   # Only strip the tmux managed block`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runGeneratedClean,
+	ValidArgsFunction: validGeneratedKinds,
+}
+
+var generatedRegenerateCmd = &cobra.Command{
+	Use:               "regenerate [kind]",
+	Short:             "Rewrite generated files",
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runGeneratedRegenerate,
+	ValidArgsFunction: validGeneratedKinds,
+}
+
+func init() {
+	rootCmd.AddCommand(generatedCmd)
+	generatedCmd.AddCommand(generatedListCmd)
+	generatedCmd.AddCommand(generatedCleanCmd)
+	generatedCmd.AddCommand(generatedRegenerateCmd)
+
+	generatedCleanCmd.Flags().BoolVar(&generatedForce, "force", false,
+		"Skip confirmation prompt")
+}
+
+func runGeneratedList(cmd *cobra.Command, args []string) {
+	entries := loadGeneratedEntries(args)
+
+	if len(entries) == 0 {
+		fmt.Println("No generated files tracked")
+		fmt.Println("\nFiles are tracked when running 'pk sync' or 'pk install'")
+		return
+	}
+
+	fmt.Println("Generated files:")
+	fmt.Println()
+
+	for _, e := range entries {
+		state := ""
+		if _, err := os.Stat(e.Path); os.IsNotExist(err) {
+			state = "  \033[33m(missing)\033[0m"
+		}
+		fmt.Printf("  %-14s %-6s %s%s\n", e.Kind, e.Mode, e.Path, state)
+		fmt.Printf("  %-14s %-6s updated %s by '%s'\n", "", "", e.UpdatedAt.Format("2006-01-02 15:04"), e.Generator)
+	}
+
+	fmt.Printf("\nTotal: %d files\n", len(entries))
+}
+
+func runGeneratedClean(cmd *cobra.Command, args []string) {
+	entries := loadGeneratedEntries(args)
+
+	if len(entries) == 0 {
+		fmt.Println("No generated files to clean")
+		return
+	}
+
+	if !generatedForce {
+		fmt.Println("This will remove:")
+		for _, e := range entries {
+			if e.Mode == generated.ModeBlock {
+				fmt.Printf("  %s (managed block only)\n", e.Path)
+			} else {
+				fmt.Printf("  %s\n", e.Path)
+			}
+		}
+		fmt.Print("\nContinue? (y/N): ")
+
+		var response string
+		fmt.Scanln(&response)
+
+		if strings.ToLower(response) != "y" {
+			fmt.Println("Cancelled")
+			return
+		}
+	}
+
+	for _, e := range entries {
+		if err := generated.Clean(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to clean %s: %v\n", e.Path, err)
+			continue
+		}
+		fmt.Printf("\033[32m✓\033[0m Removed %s\n", e.Path)
+	}
+}
+
+func runGeneratedRegenerate(cmd *cobra.Command, args []string) {
+	kinds := []string{generated.KindAliases, generated.KindCompletion, generated.KindTmuxBindings, generated.KindService, generated.KindBinDir, generated.KindBadges}
+	if len(args) > 0 {
+		kinds = []string{args[0]}
+	}
+
+	for _, kind := range kinds {
+		switch kind {
+		case generated.KindAliases:
+			syncScopes(syncAliases)
+		case generated.KindCompletion:
+			if path, ok := installCompletion(); ok {
+				generated.Record(path, generated.KindCompletion, generated.ModeFile, "pk install")
+				fmt.Printf("\033[32m✓\033[0m Completion written to %s\n", path)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: Shell completion not installed (unsupported shell)\n")
+			}
+		case generated.KindTmuxBindings:
+			// Only rewrite bindings the user opted into
+			if entries, _ := generated.List(generated.KindTmuxBindings); len(entries) > 0 {
+				runInstallTmuxBindings()
+			}
+		case generated.KindService:
+			// Only rewrite the login service if it was installed
+			if entries, _ := generated.List(generated.KindService); len(entries) > 0 {
+				daemonNoStart = true
+				runDaemonInstall(cmd, []string{})
+			}
+		case generated.KindBinDir:
+			// Only rewrite wrappers for users who exported them
+			if entries, _ := generated.List(generated.KindBinDir); len(entries) > 0 {
+				projects, err := findProjects(cacheRoots()...)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+					os.Exit(1)
+				}
+				exportBinDirs(projects)
+			}
+		case generated.KindBadges:
+			// Only refill READMEs that were badged before
+			if entries, _ := generated.List(generated.KindBadges); len(entries) > 0 {
+				syncScopes(syncBadges)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "Error: Unknown kind '%s'\n", kind)
+			os.Exit(1)
+		}
+	}
+}
+
+// loadGeneratedEntries loads manifest entries filtered by the optional kind argument
+func loadGeneratedEntries(args []string) []generated.Entry {
+	kind := ""
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	entries, err := generated.List(kind)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load generated files manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	return entries
+}
+
+// validGeneratedKinds returns kinds of generated files for completion
+func validGeneratedKinds(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	kinds := []string{generated.KindAliases, generated.KindCompletion, generated.KindTmuxBindings, generated.KindService, generated.KindBinDir, generated.KindBadges}
+	var matches []string
+	for _, k := range kinds {
+		if strings.HasPrefix(k, toComplete) {
+			matches = append(matches, k)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
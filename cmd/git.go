@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gitAll    bool
+	gitFilter []string
+	gitClean  bool
+	gitJobs   int
+	gitRebase bool
+)
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Run git across many projects at once",
+	Long: `Run git operations across every project (or those matching --filter).
+
+Mirror projects (mirror = true) are skipped; the daemon keeps them fetched.
+
+Subcommands:
+  pk git status   # Uncommitted, untracked and unpushed work in one view
+  pk git fetch    # Fetch every repository
+  pk git pull     # Fast-forward (or --rebase) every repository`,
+}
+
+var gitStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Find uncommitted, untracked and unpushed work across projects",
+	Long: `Read git status in every project repository concurrently and list the ones
+with work that exists only on this machine:
+
+  CHANGES   +staged ~modified ?untracked !conflicted
+  UNPUSHED  ↑commits on the current branch not on its upstream
+
+Only those projects are listed unless --clean is given. Archived projects
+are included with --all; --filter narrows the list (see 'pk session
+--filter'). With --format json or yaml every repository is listed.
+
+Example:
+  pk git status
+  pk git status --filter client=Acme
+  pk git status --all --clean`,
+	Args: cobra.NoArgs,
+	Run:  runGitStatus,
+}
+
+var gitFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Fetch all remotes of every project repository",
+	Long: `Fetch all remotes of every project repository (pruning deleted branches),
+a few at a time, and report each one's result. Working trees aren't touched.
+
+Git is never allowed to prompt for credentials; a repository that needs them
+fails instead. Exits 1 if any fetch failed.
+
+Example:
+  pk git fetch
+  pk git fetch --filter client=Acme --jobs 16`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGitBatch("Fetching", "fetched", func(dir string) (bool, error) {
+			return true, git.Fetch(dir)
+		})
+	},
+}
+
+var gitPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Pull the current branch of every project repository",
+	Long: `Pull the current branch of every project repository from its upstream, a
+few at a time, and report which were updated, already up to date or failed.
+
+Pulls only fast-forward unless --rebase is given, so a diverged branch fails
+rather than getting a merge commit; so do uncommitted changes with --rebase,
+and git's reason is shown. A branch without an upstream, or a detached HEAD,
+is skipped and shown as "no upstream". Exits 1 if any pull failed.
+
+Example:
+  pk git pull
+  pk git pull --rebase --filter client=Acme`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGitBatch("Pulling", "updated", func(dir string) (bool, error) {
+			return git.Pull(dir, gitRebase)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(gitStatusCmd, gitFetchCmd, gitPullCmd)
+	gitCmd.PersistentFlags().BoolVarP(&gitAll, "all", "a", false, "Include archived projects")
+	gitCmd.PersistentFlags().StringArrayVar(&gitFilter, "filter", nil, "Only projects matching key=value terms (e.g. client=Acme)")
+	gitCmd.RegisterFlagCompletionFunc("filter", validFilterKeys)
+	gitStatusCmd.Flags().BoolVar(&gitClean, "clean", false, "Also list repositories with nothing to commit or push")
+	for _, c := range []*cobra.Command{gitFetchCmd, gitPullCmd} {
+		c.Flags().IntVarP(&gitJobs, "jobs", "j", 8, "Repositories to update at once")
+	}
+	gitPullCmd.Flags().BoolVar(&gitRebase, "rebase", false, "Rebase onto the upstream instead of fast-forwarding only")
+}
+
+// gitProjects returns the repositories 'pk git' subcommands act on, sorted
+// by ID: non-archived (all with --all), matching --filter, and not mirrors
+func gitProjects() []*config.Project {
+	filter, err := config.ParseFilter(gitFilter...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var repos []*config.Project
+	for _, p := range projects {
+		if p.ProjectInfo.Mirror || (!gitAll && p.ProjectInfo.Status == "archived") || !filter.Matches(p) {
+			continue
+		}
+		if git.IsRepo(p.Path) {
+			repos = append(repos, p)
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].ProjectInfo.ID < repos[j].ProjectInfo.ID
+	})
+	return repos
+}
+
+func runGitStatus(cmd *cobra.Command, args []string) {
+	repos := gitProjects()
+	dirs := make([]string, len(repos))
+	for i, p := range repos {
+		dirs[i] = p.Path
+	}
+	statuses := git.ReadStatuses(dirs)
+
+	if format := structuredFormat(cmd); format != "" {
+		type repoStatus struct {
+			ID         string `json:"id"`
+			Path       string `json:"path"`
+			Branch     string `json:"branch"`
+			Upstream   string `json:"upstream"`
+			Staged     int    `json:"staged"`
+			Modified   int    `json:"modified"`
+			Untracked  int    `json:"untracked"`
+			Conflicted int    `json:"conflicted"`
+			Unpushed   int    `json:"unpushed"`
+			Error      string `json:"error,omitempty"`
+		}
+		result := []repoStatus{}
+		for i, p := range repos {
+			s := statuses[i]
+			r := repoStatus{
+				ID: p.ProjectInfo.ID, Path: p.Path, Branch: s.Branch, Upstream: s.Upstream,
+				Staged: s.Staged, Modified: s.Modified, Untracked: s.Untracked,
+				Conflicted: s.Conflicted, Unpushed: s.Ahead,
+			}
+			if s.Err != nil {
+				r.Error = s.Err.Error()
+			}
+			result = append(result, r)
+		}
+		printStructured(format, result)
+		return
+	}
+
+	if len(repos) == 0 {
+		fmt.Println("No project repositories")
+		return
+	}
+
+	var shown []int
+	idWidth, branchWidth := len("PROJECT"), len("BRANCH")
+	pending := 0
+	for i, p := range repos {
+		s := statuses[i]
+		needsAttention := s.Err != nil || s.Dirty() || s.Ahead > 0
+		if needsAttention {
+			pending++
+		}
+		if !needsAttention && !gitClean {
+			continue
+		}
+		shown = append(shown, i)
+		idWidth = max(idWidth, len(p.ProjectInfo.ID))
+		branchWidth = max(branchWidth, len(s.Branch))
+	}
+	idWidth, branchWidth = min(idWidth, 30), min(branchWidth, 24)
+
+	if len(shown) > 0 {
+		fmt.Printf("%-*s  %-*s  %-14s %s\n", idWidth, "PROJECT", branchWidth, "BRANCH", "CHANGES", "UNPUSHED")
+	}
+	for _, i := range shown {
+		p, s := repos[i], statuses[i]
+		branch, changes, unpushed := s.Branch, describeChanges(s.Status), ""
+		if branch == "" {
+			branch = "detached"
+		}
+		branch = truncate(branch, branchWidth)
+		switch {
+		case s.Err != nil:
+			changes = "\033[31merror\033[0m"
+			unpushed = fmt.Sprintf("\033[90m%v\033[0m", s.Err)
+		case s.Ahead > 0:
+			unpushed = fmt.Sprintf("\033[33m↑%d\033[0m", s.Ahead)
+		case s.Upstream == "":
+			unpushed = "\033[90mno upstream\033[0m"
+		}
+
+		fmt.Printf("\033[34m%s\033[0m  %s  %s %s\n",
+			padANSI(truncate(p.ProjectInfo.ID, idWidth), idWidth), padANSI(branch, branchWidth),
+			padANSI(changes, 14), unpushed)
+	}
+
+	if len(shown) > 0 {
+		fmt.Println()
+	}
+	if pending == 0 {
+		fmt.Printf("\033[32m✓\033[0m All %d repositories are clean and pushed\n", len(repos))
+		return
+	}
+	fmt.Printf("%d of %d repositories have uncommitted or unpushed work\n", pending, len(repos))
+}
+
+// runGitBatch runs update in every repository from gitProjects, gitJobs at
+// a time, printing a line per repository as it finishes and a summary.
+// update reports whether the repository changed; past is how a changed one
+// is described ("fetched", "updated"). Repositories without an upstream are
+// skipped rather than failed.
+func runGitBatch(label, past string, update func(dir string) (bool, error)) {
+	repos := gitProjects()
+	if len(repos) == 0 {
+		fmt.Println("No project repositories")
+		return
+	}
+	if gitJobs < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --jobs must be at least 1\n")
+		os.Exit(1)
+	}
+
+	ids := make(map[string]string, len(repos))
+	dirs := make([]string, len(repos))
+	idWidth := 0
+	for i, p := range repos {
+		dirs[i] = p.Path
+		ids[p.Path] = p.ProjectInfo.ID
+		idWidth = max(idWidth, min(len(p.ProjectInfo.ID), 30))
+	}
+
+	var mu sync.Mutex
+	changed := make(map[string]bool, len(repos))
+	task := progress.Counter(label, len(repos))
+	errs := git.ForEach(dirs, gitJobs, func(dir string) error {
+		updated, err := update(dir)
+		mu.Lock()
+		changed[dir] = err == nil && updated
+		mu.Unlock()
+		return err
+	}, func(dir string, err error) {
+		mu.Lock()
+		updated := changed[dir]
+		mu.Unlock()
+
+		task.Next(ids[dir])
+		id := padANSI(truncate(ids[dir], idWidth), idWidth)
+		switch {
+		case errors.Is(err, git.ErrNoUpstream):
+			task.Printf("\033[90m·\033[0m %s  \033[90mno upstream\033[0m\n", id)
+		case err != nil:
+			task.Printf("\033[31m✗\033[0m %s  %v\n", id, err)
+		case updated:
+			task.Printf("\033[32m✓\033[0m %s  %s\n", id, past)
+		default:
+			task.Printf("\033[90m·\033[0m %s  \033[90mup to date\033[0m\n", id)
+		}
+	})
+	task.Stop()
+
+	updated, failed, skipped := 0, 0, 0
+	for i, err := range errs {
+		switch {
+		case errors.Is(err, git.ErrNoUpstream):
+			skipped++
+		case err != nil:
+			failed++
+		case changed[dirs[i]]:
+			updated++
+		}
+	}
+	summary := fmt.Sprintf("%d %s", updated, past)
+	if current := len(repos) - updated - failed - skipped; current > 0 {
+		summary += fmt.Sprintf(", %d up to date", current)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d without upstream", skipped)
+	}
+	fmt.Printf("\n%s, %d failed\n", summary, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+var guardStrict bool
+
+var guardCmd = &cobra.Command{
+	Use:   "guard <command> [args...]",
+	Short: "Run a command only if the active cloud context matches the project",
+	Long: `Check the current directory's project against the cloud context this shell
+is actually using, then run the command. A seatbelt against running
+'terraform apply' or 'kubectl delete' against another client's account.
+
+Compares each value set in the project's [context] with what is active:
+AWS_PROFILE, the az CLI's current subscription, the gcloud project
+(CLOUDSDK_CORE_PROJECT or gcloud config), the current kube context,
+DATABRICKS_CONFIG_PROFILE and SNOWFLAKE_ACCOUNT. On a mismatch pk lists the
+differences and asks before running; with --strict it refuses outright.
+Outside a project, or when a CLI's state can't be read, the command runs.
+
+The command runs with this shell's environment, unchanged, and pk exits with
+its exit code. Guard the tools you care about with shell aliases:
+
+  alias terraform='pk guard terraform'
+
+Example:
+  pk guard terraform apply
+  pk guard --strict kubectl delete deployment api`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runGuard,
+}
+
+func init() {
+	rootCmd.AddCommand(guardCmd)
+	// Everything after the command name belongs to the command
+	guardCmd.Flags().SetInterspersed(false)
+	guardCmd.Flags().BoolVar(&guardStrict, "strict", false, "Refuse to run on a mismatch instead of asking")
+}
+
+func runGuard(cmd *cobra.Command, args []string) {
+	cwd, _ := os.Getwd()
+	if file := config.FindProjectFile(cwd); file != "" {
+		project, err := config.LoadProject(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to load %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		guardProject(project)
+	}
+
+	guarded := runner.Command(args[0], args[1:]...)
+	guarded.Stdin = os.Stdin
+	guarded.Stdout = os.Stdout
+	guarded.Stderr = os.Stderr
+
+	if err := runner.Run(guarded); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// guardProject exits unless the active context matches the project's, or
+// the user chooses to go ahead anyway
+func guardProject(project *config.Project) {
+	mismatches := pkcontext.CheckActive(project)
+	if len(mismatches) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\033[31m✗\033[0m Active context doesn't match %s:\n", project.ProjectInfo.ID)
+	for _, m := range mismatches {
+		active := m.Active
+		if active == "" {
+			active = "(none)"
+		}
+		fmt.Fprintf(os.Stderr, "  %-10s project: %-28s active: %s\n", m.Provider, m.Want, active)
+	}
+	fmt.Fprintf(os.Stderr, "  Fix with: pk session %s, or pk env diff\n", project.ProjectInfo.ID)
+
+	if guardStrict || !pkcontext.Confirm("Run anyway? (y/N): ") {
+		os.Exit(1)
+	}
+}
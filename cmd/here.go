@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/detect"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/hooks"
+	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/slug"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hereID      string
+	hereOwner   string
+	hereType    string
+	hereSidecar bool
+	hereSession bool
+	hereMove    bool
+)
+
+var hereCmd = &cobra.Command{
+	Use:   "here [name]",
+	Short: "Register the current directory as a project",
+	Long: `Register the current directory as a project in one step, where it is.
+
+The name comes from package.json, Cargo.toml, pyproject.toml or go.mod
+(else the directory name), and the stack, domain, repository (git remote
+origin), kind and description are detected, as 'pk list --untracked'
+shows them. The ID is derived from the directory name per [ids].
+
+Unlike 'pk promote', nothing is moved and no git repository is created.
+With --sidecar, the metadata goes in ~/.local/share/pk/meta instead of the
+directory.
+
+pk finds projects under its roots (~/projects, ~/archive, ~/scriptorium,
+or [paths] and its archive_rules in config.toml), so a directory elsewhere
+is refused: --move moves it into the projects root first.
+
+Example:
+  pk here
+  pk here "Acme ETL" --id acme-etl
+  pk here --sidecar --session
+  pk here --move            # From outside the roots`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runHere,
+}
+
+func init() {
+	rootCmd.AddCommand(hereCmd)
+	hereCmd.Flags().StringVar(&hereID, "id", "", "Project ID (default: derived from the directory name)")
+	hereCmd.Flags().StringVar(&hereOwner, "owner", "datakai", "Project owner")
+	hereCmd.Flags().StringVar(&hereType, "type", "product", "Project type")
+	hereCmd.Flags().BoolVar(&hereSidecar, "sidecar", false,
+		"Keep metadata in ~/.local/share/pk/meta instead of the directory")
+	hereCmd.Flags().BoolVarP(&hereSession, "session", "s", false, "Open in a tmux session afterwards")
+	hereCmd.Flags().BoolVar(&hereMove, "move", false,
+		"Move a directory outside the project roots into the projects root")
+}
+
+func runHere(cmd *cobra.Command, args []string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get current directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	file := config.MetadataFile(dir)
+	if _, err := os.Stat(file); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: Already a project (found %s)\n", file)
+		os.Exit(1)
+	}
+
+	project := hereProjectMetadata(dir)
+	if len(args) > 0 {
+		project.ProjectInfo.Name = args[0]
+		project.Confirm("project.name")
+	}
+	project.ProjectInfo.ID = hereProjectID(dir)
+	enforceNaming(project, "Use --id to choose a different ID")
+
+	moved := false
+	if !insideAny(dir, cacheRoots()) {
+		projectsDir := projectPaths().Projects()
+		if !hereMove {
+			fmt.Fprintf(os.Stderr, "Error: %s is outside your project roots, so pk wouldn't find it\n", dir)
+			fmt.Fprintf(os.Stderr, "Use --move to move it to %s, or add a root in config.toml\n",
+				filepath.Join(projectsDir, project.ProjectInfo.ID))
+			os.Exit(1)
+		}
+		target := filepath.Join(projectsDir, project.ProjectInfo.ID)
+		if _, err := os.Stat(target); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists\n", target)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(projectsDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := paths.Move(dir, target); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to move %s to %s: %v\n", dir, target, err)
+			os.Exit(1)
+		}
+		dir, moved = target, true
+		project.Path = dir
+	}
+
+	tomlPath := filepath.Join(dir, ".project.toml")
+	if err := project.SaveAs(tomlPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create .project.toml: %v\n", err)
+		os.Exit(1)
+	}
+	hideMetadata(tomlPath, hereSidecar)
+	events.Emit(events.ProjectCreated, project.ProjectInfo.ID, dir, map[string]string{"source": "here"})
+
+	fmt.Printf("\033[32m✓\033[0m Registered '%s' (%s)\n", project.ProjectInfo.ID, dir)
+	fmt.Printf("  Name:  %s\n", project.ProjectInfo.Name)
+	if len(project.Tech.Stack) > 0 {
+		fmt.Printf("  Stack: %s\n", strings.Join(project.Tech.Stack, ", "))
+	}
+	if project.Links.Repository != "" {
+		fmt.Printf("  Repo:  %s\n", project.Links.Repository)
+	}
+
+	syncScopes(syncAliases)
+	cache.InvalidateCache()
+	hooks.InvalidateCache()
+
+	fmt.Printf("\nNext steps:\n")
+	if moved {
+		fmt.Printf("  cd %s\n", dir)
+	}
+	fmt.Printf("  pk edit %s          # Review detected metadata\n", project.ProjectInfo.ID)
+	fmt.Printf("  pk session %s       # Open in tmux\n", project.ProjectInfo.ID)
+
+	if hereSession {
+		fmt.Println("\nOpening in tmux session...")
+		runSession(cmd, []string{project.ProjectInfo.ID})
+	}
+}
+
+// hereProjectMetadata builds metadata for a directory registered in place,
+// starting from what can be detected
+func hereProjectMetadata(dir string) *config.Project {
+	project := detect.Provisional(dir)
+	project.ProjectInfo.Status = "active"
+	project.ProjectInfo.Type = hereType
+	if project.Tech.Stack == nil {
+		project.Tech.Stack = []string{}
+	}
+	if project.Tech.Domain == nil {
+		project.Tech.Domain = []string{}
+	}
+	project.Dates.Started = time.Now().Format("2006-01-02")
+
+	if hereOwner != "" {
+		project.Consultant.Ownership = hereOwner
+		project.Consultant.MyRole = "owner"
+	}
+	if hereOwner == "datakai" {
+		project.DataKai.Visibility = "private" // As for pk new
+	}
+	return project
+}
+
+// hereProjectID returns --id, or an ID derived from the directory name per
+// [ids]; either must not belong to a known project
+func hereProjectID(dir string) string {
+	rules := loadSettings().IDs
+
+	id := hereID
+	if id == "" {
+		id = slug.Make(filepath.Base(dir), "", rules)
+	} else if !slug.Valid(id, rules) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid ID '%s' (want e.g. '%s')\n", id, slug.Make(id, "", rules))
+		os.Exit(1)
+	}
+	if id == "" {
+		fmt.Fprintf(os.Stderr, "Error: Can't derive an ID from '%s'; set one with --id\n", filepath.Base(dir))
+		os.Exit(1)
+	}
+
+	if projects, err := cache.FindProjectsCached(cacheRoots()...); err == nil {
+		for _, p := range projects {
+			if p.ProjectInfo.ID == id {
+				fmt.Fprintf(os.Stderr, "Error: Project ID '%s' is already in use (%s); set another with --id\n", id, p.Path)
+				os.Exit(1)
+			}
+		}
+	}
+	return id
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+// hookAccessInterval debounces repeated cd's within the same project
+const hookAccessInterval = time.Minute
+
+var hookCmd = &cobra.Command{
+	Use:    "__hook",
+	Short:  "Internal shell integration hooks",
+	Hidden: true,
+}
+
+var hookChpwdCmd = &cobra.Command{
+	Use:   "chpwd [dir]",
+	Short: "Record access when the shell enters a project directory",
+	Long: `Called by the cd hook in the generated alias file (see 'pk sync aliases').
+Records access for the project containing dir (default: current directory)
+so 'pk recent' reflects manual navigation, not just session opens.
+
+Silent and always exits 0 so it never disturbs the shell.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runHookChpwd,
+}
+
+var hookTmuxCmd = &cobra.Command{
+	Use:   "tmux <attached|detached> <client> [session] [session-path]",
+	Short: "Record a tmux client attaching to or leaving a session",
+	Long: `Called by the tmux hooks in pk's managed tmux.conf block (see 'pk install
+--tmux-bindings') on client-attached, client-session-changed and
+client-detached. Emits session.attached / session.detached events, which
+'pk export activity' turns into time spent per project.
+
+The project is the one containing the session's start directory. Silent
+and always exits 0.`,
+	Args: cobra.RangeArgs(2, 4),
+	Run:  runHookTmux,
+}
+
+var hookSessionCmd = &cobra.Command{
+	Use:   "session on_detach <project-path> [client]",
+	Short: "Run a project's session lifecycle hook",
+	Long: `Called by the client-detached hook pk sets on the tmux sessions of projects
+with on_detach commands (see [tmux] on_detach). Runs them, and records the
+session.detached event for client, since a session's own hook replaces the
+global ones from 'pk install --tmux-bindings'.
+Always exits 0.`,
+	Args: cobra.RangeArgs(2, 3),
+	Run:  runHookSession,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookChpwdCmd)
+	hookCmd.AddCommand(hookTmuxCmd)
+	hookCmd.AddCommand(hookSessionCmd)
+}
+
+func runHookSession(cmd *cobra.Command, args []string) {
+	if args[0] != session.OnDetach {
+		return
+	}
+	if len(args) > 2 && args[2] != "" {
+		events.Emit(events.SessionDetached, "", "", map[string]string{"client": args[2]})
+	}
+
+	project, err := config.LoadProject(config.MetadataFile(args[1]))
+	if err != nil {
+		return
+	}
+	session.RunHook(project, session.OnDetach)
+}
+
+func runHookTmux(cmd *cobra.Command, args []string) {
+	data := map[string]string{"client": args[1]}
+
+	if args[0] == "detached" {
+		events.Emit(events.SessionDetached, "", "", data)
+		return
+	}
+	if args[0] != "attached" || len(args) < 3 {
+		return
+	}
+
+	data["session"] = args[2]
+	projectID, path := "", ""
+	if len(args) > 3 && args[3] != "" {
+		// Sessions pk created start in the project root
+		if file := config.FindProjectFile(args[3]); file != "" {
+			if project, err := config.LoadProject(file); err == nil {
+				projectID, path = project.ProjectInfo.ID, project.Path
+			}
+		}
+	}
+	events.Emit(events.SessionAttached, projectID, path, data)
+}
+
+func runHookChpwd(cmd *cobra.Command, args []string) {
+	dir := ""
+	if len(args) > 0 {
+		dir = args[0]
+	} else {
+		dir, _ = os.Getwd()
+	}
+	if dir == "" {
+		return
+	}
+
+	projectFile := config.FindProjectFile(dir)
+	if projectFile == "" {
+		return
+	}
+
+	project, err := config.LoadProject(projectFile)
+	if err != nil || project.ProjectInfo.ID == "" {
+		return
+	}
+
+	cache.TouchAccess(project.ProjectInfo.ID, project.Path, hookAccessInterval)
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/detect"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/hooks"
+	"github.com/datakaicr/pk/pkg/importer"
+	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importDryRun bool
+	importMove   bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <source> [file]",
+	Short: "Import projects from other project managers",
+	Long: `Import projects registered in another tool, creating .project.toml files
+so they show up in pk.
+
+Sources:
+  projectile   ~/.emacs.d/projectile-bookmarks.eld
+  sesh         ~/.config/sesh/sesh.toml (startup_command becomes a tmux window)
+  tmuxifier    ~/.tmuxifier/layouts/*.session.sh (windows and run_cmd become [tmux])
+  tmuxinator   ~/.config/tmuxinator/*.yml (windows, panes and layouts become [tmux])
+
+The file argument overrides the default location.
+
+Directories already inside a pk root (projects, archive, archive_rules
+paths or scriptorium) get metadata in place. Directories elsewhere are
+skipped unless --move is given, which moves them into the projects root.
+Directories that already have a .project.toml are left alone, except that
+windows from the source fill in [tmux] if the project has no windows yet.
+A directory whose ID (derived from its name) is already used by a project,
+or by another directory in the same import, is skipped.
+
+Example:
+  pk import projectile --dry-run
+  pk import projectile ~/.emacs.d/projectile-bookmarks.eld
+  pk import sesh --move
+  pk import tmuxifier ~/dotfiles/tmuxifier/layouts
+  pk import tmuxinator ~/.tmuxinator/dojo.yml`,
+	Args:              cobra.RangeArgs(1, 2),
+	Run:               runImport,
+	ValidArgsFunction: validImportSources,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be imported without changing anything")
+	importCmd.Flags().BoolVar(&importMove, "move", false, "Move projects outside pk roots into ~/projects")
+}
+
+func runImport(cmd *cobra.Command, args []string) {
+	source, err := importer.FindSource(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	file := ""
+	if len(args) > 1 {
+		file = args[1]
+	}
+
+	candidates, err := source.Load(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read %s registry: %v\n", source.Name, err)
+		os.Exit(1)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Printf("No projects found in %s registry\n", source.Name)
+		return
+	}
+
+	projectsDir := projectPaths().Projects()
+	roots := cacheRoots()
+
+	// Settle IDs for the whole batch before anything is moved
+	known := make(map[string]string)
+	if projects, err := cache.FindProjectsCached(roots...); err == nil {
+		for _, p := range projects {
+			known[p.ProjectInfo.ID] = p.Path
+		}
+	}
+	conflicts := importer.Conflicts(importable(candidates), known)
+
+	task := progress.Counter("Importing", len(candidates))
+	imported, layouts := 0, 0
+	for _, c := range candidates {
+		task.Next(c.Name)
+		id := importer.Slug(c.Name)
+		label := fmt.Sprintf("%-24s %s", id, c.Path)
+
+		if info, err := os.Stat(c.Path); err != nil || !info.IsDir() {
+			task.Printf("  \033[90mskip\033[0m    %s (missing)\n", label)
+			continue
+		}
+
+		file := config.MetadataFile(c.Path)
+		if _, err := os.Stat(file); err == nil {
+			switch added, err := importLayout(c, file); {
+			case err != nil:
+				task.Printf("  \033[31mfail\033[0m    %s (%v)\n", label, err)
+			case added:
+				task.Printf("  \033[32mtmux\033[0m    %s (%d window(s) added)\n", label, len(c.Windows))
+				layouts++
+			default:
+				task.Printf("  \033[90mskip\033[0m    %s (already tracked)\n", label)
+			}
+			continue
+		}
+
+		if reason, ok := conflicts[c.Path]; ok {
+			task.Printf("  \033[31mskip\033[0m    %s (%s)\n", label, reason)
+			continue
+		}
+
+		target := c.Path
+		if !insideAny(c.Path, roots) {
+			if !importMove {
+				task.Printf("  \033[33mskip\033[0m    %s (outside pk roots, use --move)\n", label)
+				continue
+			}
+
+			target = filepath.Join(projectsDir, id)
+			if _, err := os.Stat(target); err == nil {
+				task.Printf("  \033[31mskip\033[0m    %s (%s already exists)\n", label, target)
+				continue
+			}
+		}
+
+		if importDryRun {
+			if target != c.Path {
+				task.Printf("  \033[32mmove\033[0m    %s -> %s\n", label, target)
+			} else {
+				task.Printf("  \033[32mimport\033[0m  %s\n", label)
+			}
+			imported++
+			continue
+		}
+
+		if target != c.Path {
+			if err := os.MkdirAll(projectsDir, 0755); err != nil {
+				task.Stop()
+				fmt.Fprintf(os.Stderr, "Error: Failed to create %s: %v\n", projectsDir, err)
+				os.Exit(1)
+			}
+			if err := paths.Move(c.Path, target); err != nil {
+				task.Printf("  \033[31mfail\033[0m    %s (%v)\n", label, err)
+				continue
+			}
+		}
+
+		if err := writeImportedProjectToml(c, target); err != nil {
+			task.Printf("  \033[31mfail\033[0m    %s (%v)\n", label, err)
+			continue
+		}
+
+		events.Emit(events.ProjectCreated, id, target, map[string]string{"source": "import:" + source.Name})
+		task.Printf("  \033[32m✓\033[0m       %s\n", label)
+		imported++
+	}
+
+	task.Stop()
+
+	fmt.Println()
+	if importDryRun {
+		fmt.Printf("Would import %d of %d project(s) from %s", imported, len(candidates), source.Name)
+		if layouts > 0 {
+			fmt.Printf(" and add [tmux] to %d", layouts)
+		}
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("\033[32m✓\033[0m Imported %d of %d project(s) from %s", imported, len(candidates), source.Name)
+	if layouts > 0 {
+		fmt.Printf(" and added [tmux] to %d", layouts)
+	}
+	fmt.Println()
+	if layouts > 0 {
+		cache.InvalidateCache()
+	}
+	if imported > 0 {
+		syncScopes(syncAliases)
+		hooks.InvalidateCache()
+	}
+}
+
+// importable returns the candidates that would become new projects: existing
+// directories without metadata of their own
+func importable(candidates []importer.Candidate) []importer.Candidate {
+	var result []importer.Candidate
+	for _, c := range candidates {
+		if info, err := os.Stat(c.Path); err != nil || !info.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(config.MetadataFile(c.Path)); err == nil {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// insideAny reports whether path is inside one of the root directories
+func insideAny(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// importLayout gives a tracked project the candidate's windows if it has
+// none of its own, and reports whether it did (or would, with --dry-run)
+func importLayout(c importer.Candidate, file string) (bool, error) {
+	if len(c.Windows) == 0 {
+		return false, nil
+	}
+	project, err := config.LoadProject(file)
+	if err != nil {
+		return false, err
+	}
+	if len(project.Tmux.Windows) > 0 {
+		return false, nil
+	}
+	if importDryRun {
+		return true, nil
+	}
+
+	project.Tmux.Windows = c.Windows
+	return true, project.Save()
+}
+
+// writeImportedProjectToml writes metadata for an imported project
+func writeImportedProjectToml(c importer.Candidate, path string) error {
+	project := c.Project(path)
+	detect.Apply(project, path)
+
+	return project.Save()
+}
+
+func validImportSources(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		var names []string
+		for _, s := range importer.Sources {
+			names = append(names, s.Name+"\t"+s.Description)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/generated"
 	"github.com/datakaicr/pk/pkg/shell"
 	"github.com/spf13/cobra"
 )
@@ -17,7 +19,7 @@ var installCmd = &cobra.Command{
 	Long: `Install pk binary, man page, and shell completions system-wide.
 
 This command will:
-  1. Create pk directories (~/projects, ~/scratch, ~/archive)
+  1. Create pk directories (projects, scratch and archive roots)
   2. Copy pk binary to /usr/local/bin/pk
   3. Install man page to system man directory
   4. Install shell completions for your shell
@@ -27,19 +29,50 @@ Requires sudo permissions for binary and man page installation.
 
 Core commands work without dependencies.
 Optional features:
-  - tmux session management: requires tmux and fzf
+  - tmux session management: requires tmux (fzf optional, for previews)
   - Context switching: requires cloud CLIs (aws, az, gcloud, etc.)
 
+Use --tmux-bindings to only append pk's keybindings to your tmux config.
+The bindings are written inside a managed block, so re-running the command
+updates them in place instead of duplicating them. The block also sets
+hooks (at index 90, leaving your own hooks alone) that record when you
+attach to and leave sessions, for 'pk export activity'.
+
 Example:
-  pk install`,
+  pk install
+  pk install --tmux-bindings`,
 	Run: runInstall,
 }
 
+var installTmuxBindings bool
+
+// tmuxBindings is the managed keybinding snippet written by --tmux-bindings
+const tmuxBindings = `# Generated by 'pk install --tmux-bindings' - changes inside this block are overwritten
+bind-key f run-shell "tmux display-popup -E -w 90% -h 80% 'pk session --popup'"
+bind-key F run-shell "tmux display-popup -E -w 90% -h 80% 'pk sessions --popup'"
+bind-key W run-shell "tmux display-popup -E -w 90% -h 80% 'pk sessions --windows --popup'"
+bind-key g switch-client -T jump
+bind-key -T jump 1 run-shell "pk jump 1 --popup"
+bind-key -T jump 2 run-shell "pk jump 2 --popup"
+bind-key -T jump 3 run-shell "pk jump 3 --popup"
+bind-key -T jump 4 run-shell "pk jump 4 --popup"
+bind-key -T jump 5 run-shell "pk jump 5 --popup"
+set-hook -g client-attached[90] "run-shell -b 'pk __hook tmux attached \"#{client_name}\" \"#{session_name}\" \"#{session_path}\"'"
+set-hook -g client-session-changed[90] "run-shell -b 'pk __hook tmux attached \"#{client_name}\" \"#{session_name}\" \"#{session_path}\"'"
+set-hook -g client-detached[90] "run-shell -b 'pk __hook tmux detached \"#{client_name}\"'"`
+
 func init() {
 	rootCmd.AddCommand(installCmd)
+	installCmd.Flags().BoolVar(&installTmuxBindings, "tmux-bindings", false,
+		"Only append pk keybindings to your tmux config (managed block)")
 }
 
 func runInstall(cmd *cobra.Command, args []string) {
+	if installTmuxBindings {
+		runInstallTmuxBindings()
+		return
+	}
+
 	fmt.Println("Installing PK (Project Kit)...")
 	fmt.Println()
 
@@ -66,20 +99,11 @@ func runInstall(cmd *cobra.Command, args []string) {
 		manPagePath = ""
 	}
 
-	// Get home directory for creating pk directories
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
 	// 1. Create pk directories
 	fmt.Println("1. Creating pk directories...")
-	projectsDir := filepath.Join(homeDir, "projects")
-	scratchDir := filepath.Join(homeDir, "scratch")
-	archiveDir := filepath.Join(homeDir, "archive")
+	resolver := projectPaths()
 
-	for _, dir := range []string{projectsDir, scratchDir, archiveDir} {
+	for _, dir := range []string{resolver.Projects(), resolver.Scratch(), resolver.Archive()} {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			fmt.Fprintf(os.Stderr, "   Warning: Failed to create %s: %v\n", dir, err)
 		} else {
@@ -133,8 +157,9 @@ func runInstall(cmd *cobra.Command, args []string) {
 
 	// 4. Install shell completion
 	fmt.Println("4. Installing shell completion...")
-	installedCompletion := installCompletion()
+	completionPath, installedCompletion := installCompletion()
 	if installedCompletion {
+		generated.Record(completionPath, generated.KindCompletion, generated.ModeFile, "pk install")
 		fmt.Println("   ✓ Shell completion installed")
 	} else {
 		fmt.Println("   ⚠ Shell completion not installed (unsupported shell)")
@@ -144,7 +169,7 @@ func runInstall(cmd *cobra.Command, args []string) {
 	// 5. Check optional dependencies
 	fmt.Println("5. Checking optional dependencies...")
 	checkDependency("tmux", "Required for 'pk session'")
-	checkDependency("fzf", "Required for interactive 'pk session'")
+	checkDependency("fzf", "Optional: pickers with previews (built-in picker otherwise)")
 	fmt.Println()
 
 	// Success message
@@ -170,6 +195,9 @@ func runInstall(cmd *cobra.Command, args []string) {
 		}
 		fmt.Println()
 	}
+	fmt.Println("Check that everything works (in a new shell):")
+	fmt.Println("  pk verify-install")
+	fmt.Println()
 	fmt.Println("View documentation:")
 	fmt.Println("  pk --help")
 	if manPagePath != "" {
@@ -177,7 +205,8 @@ func runInstall(cmd *cobra.Command, args []string) {
 	}
 }
 
-func installCompletion() bool {
+// installCompletion installs completion for the current shell and returns its path
+func installCompletion() (string, bool) {
 	currentShell := shell.Detect()
 
 	switch currentShell {
@@ -188,11 +217,11 @@ func installCompletion() bool {
 	case shell.Fish:
 		return installFishCompletion()
 	default:
-		return false
+		return "", false
 	}
 }
 
-func installZshCompletion() bool {
+func installZshCompletion() (string, bool) {
 	// Try Homebrew location first
 	completionPath := ""
 	if runtime.GOOS == "darwin" {
@@ -213,7 +242,7 @@ func installZshCompletion() bool {
 	compCmd := exec.Command("pk", "completion", "zsh")
 	output, err := compCmd.Output()
 	if err != nil {
-		return false
+		return "", false
 	}
 
 	// Write to file (use sudo if system directory)
@@ -222,14 +251,14 @@ func installZshCompletion() bool {
 		tmpFile := "/tmp/pk_completion.zsh"
 		os.WriteFile(tmpFile, output, 0644)
 		cpCmd := exec.Command("sudo", "cp", tmpFile, completionPath)
-		return cpCmd.Run() == nil
+		return completionPath, cpCmd.Run() == nil
 	} else {
 		// User directory
-		return os.WriteFile(completionPath, output, 0644) == nil
+		return completionPath, os.WriteFile(completionPath, output, 0644) == nil
 	}
 }
 
-func installBashCompletion() bool {
+func installBashCompletion() (string, bool) {
 	homeDir, _ := os.UserHomeDir()
 	completionDir := filepath.Join(homeDir, ".bash_completion.d")
 	os.MkdirAll(completionDir, 0755)
@@ -239,13 +268,13 @@ func installBashCompletion() bool {
 	compCmd := exec.Command("pk", "completion", "bash")
 	output, err := compCmd.Output()
 	if err != nil {
-		return false
+		return "", false
 	}
 
-	return os.WriteFile(completionPath, output, 0644) == nil
+	return completionPath, os.WriteFile(completionPath, output, 0644) == nil
 }
 
-func installFishCompletion() bool {
+func installFishCompletion() (string, bool) {
 	homeDir, _ := os.UserHomeDir()
 	completionDir := filepath.Join(homeDir, ".config", "fish", "completions")
 	os.MkdirAll(completionDir, 0755)
@@ -255,16 +284,48 @@ func installFishCompletion() bool {
 	compCmd := exec.Command("pk", "completion", "fish")
 	output, err := compCmd.Output()
 	if err != nil {
-		return false
+		return "", false
 	}
 
-	return os.WriteFile(completionPath, output, 0644) == nil
+	return completionPath, os.WriteFile(completionPath, output, 0644) == nil
 }
 
 func checkDependency(name, description string) {
-	if _, err := exec.LookPath(name); err == nil {
+	if deps.Available(name) {
 		fmt.Printf("   ✓ %s installed\n", name)
 	} else {
-		fmt.Printf("   ⚠ %s not found - %s\n", name, description)
+		fmt.Printf("   ⚠ %s not found - %s (%s)\n", name, description, deps.Lookup(name).InstallHint())
 	}
 }
+
+func runInstallTmuxBindings() {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Prefer XDG location if it exists, same order as 'pk doctor'
+	confPath := filepath.Join(homeDir, ".tmux.conf")
+	xdgPath := filepath.Join(homeDir, ".config", "tmux", "tmux.conf")
+	if _, err := os.Stat(xdgPath); err == nil {
+		confPath = xdgPath
+	}
+
+	data, err := os.ReadFile(confPath)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read %s: %v\n", confPath, err)
+		os.Exit(1)
+	}
+
+	content := generated.ReplaceBlock(string(data), tmuxBindings)
+	if err := os.WriteFile(confPath, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write %s: %v\n", confPath, err)
+		os.Exit(1)
+	}
+	generated.Record(confPath, generated.KindTmuxBindings, generated.ModeBlock, "pk install --tmux-bindings")
+
+	fmt.Printf("\033[32m✓\033[0m PK keybindings written to %s\n", confPath)
+	fmt.Printf("\nReload tmux:\n")
+	fmt.Printf("  tmux source-file %s\n", confPath)
+}
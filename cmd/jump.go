@@ -35,15 +35,19 @@ Examples:
 	Run:               runJump,
 	ValidArgsFunction: validJumpArgs,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		return session.CheckTmux()
+		return requireTools(cmd, session.Check())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(jumpCmd)
+	jumpCmd.Flags().BoolVar(&popupMode, "popup", false,
+		"Always switch-client (for use from tmux popups and run-shell)")
 }
 
 func runJump(cmd *cobra.Command, args []string) {
+	applyPopupMode()
+
 	slotStr := args[0]
 
 	// Parse slot number
@@ -86,6 +90,7 @@ func runJump(cmd *cobra.Command, args []string) {
 		fmt.Fprintf(os.Stderr, "Error: Failed to create/switch session: %v\n", err)
 		os.Exit(1)
 	}
+	reportDetached(project)
 }
 
 // validJumpArgs provides shell completion for jump command
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var kindCmd = &cobra.Command{
+	Use:   "kind",
+	Short: "List and inspect project kinds",
+	Long: `Project kinds bundle defaults for a family of projects: a tmux layout,
+session environment, named commands for 'pk run', detection rules, and
+extra fields for 'pk show'. A project opts in with one field:
+
+  [project]
+  kind = "dbt"
+
+clone, promote, and import set the kind automatically when a kind's
+detection rules match. Built-in kinds can be extended, and new ones added,
+under [kinds.<name>] in ~/.config/pk/config.toml.
+
+Subcommands:
+  pk kind list           List available kinds
+  pk kind show <name>    Show everything a kind provides
+  pk kind detect [dir]   Show which kind a directory would get`,
+}
+
+var kindListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available kinds",
+	Args:  cobra.NoArgs,
+	Run:   runKindList,
+}
+
+var kindShowCmd = &cobra.Command{
+	Use:               "show <name>",
+	Short:             "Show everything a kind provides",
+	Args:              cobra.ExactArgs(1),
+	Run:               runKindShow,
+	ValidArgsFunction: validKindNames,
+}
+
+var kindDetectCmd = &cobra.Command{
+	Use:   "detect [dir]",
+	Short: "Show which kind a directory would get",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runKindDetect,
+}
+
+func init() {
+	rootCmd.AddCommand(kindCmd)
+	kindCmd.AddCommand(kindListCmd)
+	kindCmd.AddCommand(kindShowCmd)
+	kindCmd.AddCommand(kindDetectCmd)
+}
+
+func loadSettings() *settings.Settings {
+	s, err := settings.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
+	}
+	return s
+}
+
+func runKindList(cmd *cobra.Command, args []string) {
+	s := loadSettings()
+	kinds := s.AllKinds()
+
+	names := s.KindNames()
+	sort.Strings(names)
+
+	for _, name := range names {
+		k := kinds[name]
+		source := "built-in"
+		if _, custom := s.Kinds[name]; custom {
+			source = "config"
+			if settings.IsBuiltinKind(name) {
+				source = "built-in + config"
+			}
+		}
+		fmt.Printf("\033[34m%-18s\033[0m %-36s %s\n", name, k.Description, source)
+	}
+}
+
+func runKindShow(cmd *cobra.Command, args []string) {
+	s := loadSettings()
+	k, exists := s.AllKinds()[args[0]]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Error: Unknown kind '%s' (see 'pk kind list')\n", args[0])
+		os.Exit(1)
+	}
+
+	fmt.Printf("\033[1;34m%s\033[0m", args[0])
+	if k.Description != "" {
+		fmt.Printf(" - %s", k.Description)
+	}
+	fmt.Println()
+
+	if len(k.Detect) > 0 {
+		fmt.Printf("\n\033[1mDetect\033[0m (all must match)\n  %s\n", strings.Join(k.Detect, ", "))
+	}
+
+	if layout := k.LayoutIn(s); layout != nil {
+		fmt.Printf("\n\033[1mLayout\033[0m")
+		if k.Layout != "" {
+			fmt.Printf(" (%s)", k.Layout)
+		}
+		fmt.Println()
+		if layout.Layout != "" {
+			fmt.Printf("  tmux layout: %s\n", layout.Layout)
+		}
+		for _, w := range layout.Windows {
+			fmt.Printf("  %-12s %s\n", w.Name, w.Command)
+		}
+	}
+
+	printKindMap("Env", k.Env)
+	printKindMap("Commands", k.Commands)
+	printKindMap("Report", k.Report)
+}
+
+func printKindMap(title string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	fmt.Printf("\n\033[1m%s\033[0m\n", title)
+	for _, key := range settings.SortedKeys(m) {
+		fmt.Printf("  %-12s %s\n", key, m[key])
+	}
+}
+
+func runKindDetect(cmd *cobra.Command, args []string) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	kind := loadSettings().DetectKind(dir)
+	if kind == "" {
+		fmt.Println("No kind detected")
+		return
+	}
+	fmt.Println(kind)
+}
+
+func validKindNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeKindNames(cmd, args, toComplete)
+}
+
+// completeKindNames completes --kind flags, whatever positional args are set
+func completeKindNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	s, _ := settings.Load()
+	return filterPrefix(s.KindNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
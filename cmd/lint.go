@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var lintNaming bool
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check existing projects against your conventions",
+	Long: `Check every project against the conventions in config.toml and list the
+ones that break them. Exits non-zero if any do, so it can run in CI.
+
+Checks:
+  --naming   IDs and names against the [[naming]] rules
+
+Without a check flag, all checks run.
+
+Naming rules apply to projects matching their type, owner and/or client
+(client = "*" for any client). Each pattern is a regular expression the
+whole ID (or name, with field = "name") must match, rendered first as a Go
+template over the project, with lower, slug and quote available:
+
+  [[naming]]
+  type = "client-project"
+  pattern = "{{slug .Consultant.ClientName}}-[a-z0-9-]+"
+  message = "client projects start with the client"
+
+pk new, rename and promote refuse names that break a rule.
+
+Example:
+  pk lint
+  pk lint --naming`,
+	Args: cobra.NoArgs,
+	Run:  runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().BoolVar(&lintNaming, "naming", false, "Check IDs and names against [[naming]] rules")
+}
+
+func runLint(cmd *cobra.Command, args []string) {
+	all := !lintNaming
+
+	projects, err := findProjects(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	problems := 0
+	if all || lintNaming {
+		problems += lintProjectNames(projects)
+	}
+
+	if problems > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d problem(s) in %d project(s)\n", problems, len(projects))
+		os.Exit(1)
+	}
+}
+
+// lintProjectNames prints each naming rule violation and returns how many
+// there were
+func lintProjectNames(projects []*config.Project) int {
+	s := loadSettings()
+	if len(s.Naming) == 0 {
+		fmt.Println("Naming: no rules configured ([[naming]] in config.toml)")
+		return 0
+	}
+
+	problems := 0
+	for _, p := range projects {
+		violations, err := s.CheckNaming(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, v := range violations {
+			fmt.Printf("\033[31m✗\033[0m %s: %s\n", p.ProjectInfo.ID, v.Error())
+			problems++
+		}
+	}
+
+	if problems == 0 {
+		fmt.Printf("\033[32m✓\033[0m Naming: %d project(s) follow %d rule(s)\n", len(projects), len(s.Naming))
+	}
+	return problems
+}
+
+// enforceNaming exits with the rules a project being created or renamed
+// breaks, and hint on how to pick another name
+func enforceNaming(p *config.Project, hint string) {
+	violations, err := loadSettings().CheckNaming(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(violations) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: '%s' breaks the naming conventions in config.toml:\n", p.ProjectInfo.Name)
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "  %s\n", v.Error())
+	}
+	fmt.Fprintf(os.Stderr, "%s.\n", hint)
+	os.Exit(1)
+}
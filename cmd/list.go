@@ -3,10 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/detect"
+	"github.com/datakaicr/pk/pkg/progress"
 	"github.com/spf13/cobra"
 )
 
@@ -23,16 +25,40 @@ Filters:
   product     - Product projects
   client      - Client projects
 
+Custom output with --format: a Go template executed per project, or the
+name of a saved format from [formats] in ~/.config/pk/config.toml.
+Helpers: join, lower, upper, default. "\t" and "\n" are expanded.
+--format json or yaml (or --json) prints every project's fields and path.
+
+--untracked lists what pk doesn't manage yet instead: directories under the
+roots with a .git or a language manifest (go.mod, package.json, Cargo.toml,
+pyproject.toml) but no .project.toml. Each is shown as the project
+'pk promote' would create, named and described from its manifest.
+
 Examples:
   pk list              # All projects
   pk list active       # Active projects only
-  pk list datakai      # DataKai projects only`,
+  pk list datakai      # DataKai projects only
+  pk list --format '{{.ProjectInfo.ID}},{{.GetClientName}}'
+  pk list active --format csv   # Saved format
+  pk list active --json         # For scripts
+  pk list --untracked           # Repos without a .project.toml`,
 	Run:               runList,
 	ValidArgsFunction: validListFilters,
 }
 
+var (
+	listFormat    string
+	listUntracked bool
+)
+
+// listFilters are the filter arguments 'pk list' accepts
+var listFilters = []string{"active", "archived", "datakai", "westmonroe", "product", "client"}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listFormat, "format", "", "Go template or saved format name for each project, or table, json or yaml")
+	listCmd.Flags().BoolVar(&listUntracked, "untracked", false, "List repositories under the roots that have no .project.toml")
 }
 
 func runList(cmd *cobra.Command, args []string) {
@@ -43,23 +69,33 @@ func runList(cmd *cobra.Command, args []string) {
 	}
 
 	// Find projects in standard locations
-	homeDir, _ := os.UserHomeDir()
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
+	resolver := projectPaths()
+	roots := listedRoots(resolver)
 
-	projects, err := config.FindProjects(projectsDir, archiveDir)
+	if listUntracked {
+		listUntrackedProjects(cmd, roots...)
+		return
+	}
+
+	filtered, err := listProjects(filter, roots...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(projects) == 0 {
+	if format := structuredFormat(cmd); format != "" {
+		printStructured(format, projectDocuments(filtered))
+		return
+	}
+	if filtered == nil {
 		fmt.Println("No projects found")
 		return
 	}
 
-	// Apply filter
-	filtered := filterProjects(projects, filter)
+	if templateFormat(listFormat) {
+		renderFormat(listFormat, filtered)
+		return
+	}
 
 	// Print header
 	fmt.Printf("\n=== Projects (%s) ===\n\n", getFilterLabel(filter))
@@ -72,6 +108,101 @@ func runList(cmd *cobra.Command, args []string) {
 	fmt.Printf("\nTotal: %d projects\n", len(filtered))
 }
 
+// listUntrackedProjects shows the directories under rootDirs that look
+// like projects but aren't, with how to adopt them
+func listUntrackedProjects(cmd *cobra.Command, rootDirs ...string) {
+	dirs, err := config.FindUntracked(rootDirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding untracked repositories: %v\n", err)
+		os.Exit(1)
+	}
+
+	var projects []*config.Project
+	for _, dir := range dirs {
+		projects = append(projects, detect.Provisional(dir))
+	}
+
+	if format := structuredFormat(cmd); format != "" {
+		printStructured(format, projectDocuments(projects))
+		return
+	}
+	if templateFormat(listFormat) {
+		renderFormat(listFormat, projects)
+		return
+	}
+	if len(projects) == 0 {
+		fmt.Println("No untracked repositories: every repository under your roots has a .project.toml")
+		return
+	}
+
+	fmt.Printf("\n=== Untracked (%d) ===\n\n", len(projects))
+	for _, p := range projects {
+		fmt.Printf("\033[33m%s\033[0m\n", p.ProjectInfo.ID)
+		if p.ProjectInfo.Name != p.ProjectInfo.ID {
+			fmt.Printf("  Name: %s\n", p.ProjectInfo.Name)
+		}
+		if p.Notes.Description != "" {
+			fmt.Printf("  Description: %s\n", p.Notes.Description)
+		}
+		if len(p.Tech.Stack) > 0 {
+			fmt.Printf("  Stack: %s\n", strings.Join(p.Tech.Stack, ", "))
+		}
+		fmt.Printf("  Path: %s\n", p.Path)
+		fmt.Printf("  \033[2mAdopt: pk promote %s\033[0m\n\n", p.Path)
+	}
+	fmt.Printf("Total: %d untracked\n", len(projects))
+}
+
+// listProjects returns the projects a filter selects, or nil if there are
+// none at all. A fresh SQLite index answers the filter directly; otherwise
+// the roots are scanned so the list always reflects what is on disk.
+func listProjects(filter string, rootDirs ...string) ([]*config.Project, error) {
+	if q, ok := listQuery(filter); ok && cache.IndexEnabled() && cache.IsCacheValid() {
+		q.Roots = rootDirs
+		if projects, err := cache.QueryProjects(q); err == nil {
+			if len(projects) == 0 {
+				return []*config.Project{}, nil
+			}
+			return projects, nil
+		}
+	}
+
+	projects, err := findProjects(rootDirs...)
+	if err != nil || len(projects) == 0 {
+		return nil, err
+	}
+	filtered := filterProjects(projects, filter)
+	if filtered == nil {
+		filtered = []*config.Project{}
+	}
+	return filtered, nil
+}
+
+// findProjects scans rootDirs for projects, showing a spinner if that
+// takes a while
+func findProjects(rootDirs ...string) ([]*config.Project, error) {
+	task := progress.Spinner("Scanning for projects")
+	defer task.Stop()
+	return config.FindProjects(rootDirs...)
+}
+
+// listQuery translates a filter into an index query
+func listQuery(filter string) (cache.Query, bool) {
+	switch filter {
+	case "":
+		return cache.Query{}, true
+	case "active", "archived":
+		return cache.Query{Status: filter}, true
+	case "datakai", "westmonroe":
+		return cache.Query{Owner: filter}, true
+	case "product":
+		return cache.Query{Type: "product"}, true
+	case "client", "client-project":
+		return cache.Query{Type: "client-project"}, true
+	}
+	return cache.Query{}, false
+}
+
 func filterProjects(projects []*config.Project, filter string) []*config.Project {
 	if filter == "" {
 		return projects
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lookupURL         string
+	lookupScriptorium string
+	lookupConduit     string
+	lookupPath        bool
+)
+
+var lookupCmd = &cobra.Command{
+	Use:   "lookup --url <url> | --scriptorium <id> | --conduit <id>",
+	Short: "Find the local project for a repository URL or DataKai ID",
+	Long: `Find which local project a URL or ID from a browser tab or ticket refers to.
+
+--url matches links.repository and links.documentation. https, ssh and
+git@host:path forms of a repository are the same, and a page inside one (a
+pull request, a file) finds it too; the most specific link is listed first.
+--scriptorium and --conduit match datakai.scriptorium_project and
+datakai.conduit_graph.
+
+Projects without links.repository can't be found by URL; 'pk sync links'
+fills it from their git remotes.
+
+With --path, prints only the best match's directory, for cd.
+
+Example:
+  pk lookup --url https://github.com/acme/etl/pull/42
+  pk lookup --url git@github.com:acme/etl.git
+  pk lookup --scriptorium SCR-42
+  cd "$(pk lookup --url https://github.com/acme/etl --path)"`,
+	Args: cobra.NoArgs,
+	Run:  runLookup,
+}
+
+func init() {
+	rootCmd.AddCommand(lookupCmd)
+	lookupCmd.Flags().StringVar(&lookupURL, "url", "", "Repository or documentation URL, or a page inside one")
+	lookupCmd.Flags().StringVar(&lookupScriptorium, "scriptorium", "", "Scriptorium project ID")
+	lookupCmd.Flags().StringVar(&lookupConduit, "conduit", "", "Conduit graph ID")
+	lookupCmd.Flags().BoolVar(&lookupPath, "path", false, "Print only the best match's directory")
+	lookupCmd.MarkFlagsOneRequired("url", "scriptorium", "conduit")
+	lookupCmd.MarkFlagsMutuallyExclusive("url", "scriptorium", "conduit")
+}
+
+func runLookup(cmd *cobra.Command, args []string) {
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var results []config.LookupResult
+	query := lookupURL
+	switch {
+	case lookupURL != "":
+		results = config.LookupURL(projects, lookupURL)
+	case lookupScriptorium != "":
+		query = lookupScriptorium
+		results = config.LookupField(projects, "datakai.scriptorium_project", lookupScriptorium)
+	default:
+		query = lookupConduit
+		results = config.LookupField(projects, "datakai.conduit_graph", lookupConduit)
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(os.Stderr, "No project links to %s\n", query)
+		if lookupURL != "" {
+			fmt.Fprintf(os.Stderr, "Hint: 'pk sync links' fills links.repository from git remotes\n")
+		}
+		os.Exit(1)
+	}
+
+	if lookupPath {
+		fmt.Println(results[0].Project.Path)
+		return
+	}
+
+	if format := structuredFormat(cmd); format != "" {
+		type match struct {
+			ID   string `json:"id"`
+			Path string `json:"path"`
+			Key  string `json:"key"`
+			Link string `json:"link"`
+		}
+		matches := []match{}
+		for _, r := range results {
+			matches = append(matches, match{r.Project.ProjectInfo.ID, r.Project.Path, r.Key, r.Link})
+		}
+		printStructured(format, matches)
+		return
+	}
+
+	for _, r := range results {
+		fmt.Printf("\033[34m%s\033[0m  %s\n", r.Project.ProjectInfo.ID, r.Project.Path)
+		fmt.Printf("  \033[2m%s = %s\033[0m\n", r.Key, r.Link)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/hooks"
+	"github.com/spf13/cobra"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [name|path]",
+	Short: "Rewrite legacy .project.toml files in the current schema",
+	Long: `Upgrade .project.toml files that still use the legacy schema.
+
+pk migrates [ownership], [client], and the legacy [links] keys in memory
+every time it loads a project, but leaves the file alone. This command
+rewrites those files in the current format, printing what moved where.
+Files already on the current schema are not touched.
+
+Rewritten files are re-encoded, so comments in them are not kept; use
+--dry-run first to see which files would change.
+
+Example:
+  pk migrate --dry-run     # Show what would change
+  pk migrate               # Rewrite every legacy project
+  pk migrate dojo          # Just one project`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runMigrate,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show changes without writing files")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) {
+	files := allProjectFiles()
+	if len(args) == 1 {
+		files = []string{resolveProjectFile(args[0])}
+	}
+
+	migrated, failed := 0, 0
+	for _, file := range files {
+		project, err := config.LoadProject(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\033[31m!\033[0m %s: %v\n", file, err)
+			failed++
+			continue
+		}
+
+		changes := project.MigrationChanges()
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Printf("\033[33m~\033[0m %s (%s)\n", project.ProjectInfo.ID, file)
+		for _, change := range changes {
+			fmt.Printf("    %s\n", change)
+		}
+		if hasComments(file) {
+			fmt.Printf("    \033[2m(comments in this file will not be kept)\033[0m\n")
+		}
+
+		if migrateDryRun {
+			migrated++
+			continue
+		}
+		if err := project.SaveAs(file); err != nil {
+			fmt.Fprintf(os.Stderr, "    \033[31mError:\033[0m %v\n", err)
+			failed++
+			continue
+		}
+		migrated++
+	}
+
+	fmt.Println()
+	switch {
+	case migrated == 0 && failed == 0:
+		fmt.Printf("\033[32m✓\033[0m All %d project(s) already use the current schema\n", len(files))
+	case migrateDryRun:
+		fmt.Printf("Would migrate %d of %d project(s)\n", migrated, len(files))
+	default:
+		fmt.Printf("\033[32m✓\033[0m Migrated %d of %d project(s)\n", migrated, len(files))
+		hooks.InvalidateCache()
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d project(s) could not be migrated\n", failed)
+		os.Exit(1)
+	}
+}
+
+// hasComments reports whether a file has comments besides pk's own header
+func hasComments(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	data = bytes.TrimPrefix(data, []byte(config.FileHeader))
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), "#") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,16 +10,30 @@ import (
 	"strings"
 	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/forge"
+	"github.com/datakaicr/pk/pkg/git"
 	"github.com/datakaicr/pk/pkg/hooks"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/slug"
+	"github.com/datakaicr/pk/pkg/templates"
 	"github.com/spf13/cobra"
 )
 
 var (
-	newOwner string
-	newType  string
-	newNoGit bool
+	newOwner    string
+	newType     string
+	newKind     string
+	newTemplate string
+	newNoGit    bool
+	newID       string
+	newClient   string
+	newSidecar  bool
+	newGitHub   bool
+	newPrivate  bool
 )
 
 var newCmd = &cobra.Command{
@@ -25,16 +41,41 @@ var newCmd = &cobra.Command{
 	Short: "Create a new project",
 	Long: `Create a new project with metadata template and optional git initialization.
 
+The name is kept as given ([project] name); the ID used for the directory,
+alias and session is derived from it per [ids] in config.toml: lowercase
+words joined by hyphens, optionally prefixed with the client and capped in
+length. An ID already in use gets -2, -3, ...; --id sets one explicitly.
+
 This will:
-  1. Create directory in ~/projects/<name>
+  1. Create directory in the projects root ([paths] projects, ~/projects)
   2. Initialize git repository (optional: --no-git)
-  3. Create .project.toml with template metadata
-  4. Auto-sync shell aliases
+  3. Scaffold files from a template (optional: --template)
+  4. Create .project.toml with template metadata
+  5. Auto-sync shell aliases
+
+With --github the repository is also created on GitHub (public unless
+--private), added as the origin remote and pushed with an initial commit,
+and its URL is written to links.repository. The token comes from
+GITHUB_TOKEN or GH_TOKEN, else from the gh CLI's login. If GitHub refuses,
+the local project is still created.
+
+Templates live in ~/.config/pk/templates/<name>/. template.toml holds a
+[template] section (description, dirs) plus .project.toml defaults such as
+[project], [tech], and [tmux]; files/ is copied into the project, rendering
+*.tmpl files as Go templates with {{.Name}}, {{.ID}}, {{.Owner}}, {{.Type}},
+{{.Kind}}, {{.Date}}, and {{.Year}}. Flags override template defaults.
 
 Example:
   pk new my-awesome-project
+  pk new "Acme Data Platform POC"              # ID acme-data-platform-poc
+  pk new "Data Platform POC" --client "Acme Corp" --owner westmonroe
+  pk new "Acme Data Platform POC" --id acme-poc
   pk new my-project --owner westmonroe --type client-project
-  pk new prototype --no-git`,
+  pk new prototype --no-git
+  pk new warehouse --kind dbt   # Kind layout, env, and commands
+  pk new acme-api --template client-api
+  pk new acme-etl --sidecar     # No pk files in the repository
+  pk new acme-etl --github --private`,
 	Args: cobra.ExactArgs(1),
 	Run:  runNew,
 }
@@ -45,33 +86,82 @@ func init() {
 		"Project owner (datakai, westmonroe, etc.)")
 	newCmd.Flags().StringVar(&newType, "type", "product",
 		"Project type (product, client-project, internal)")
+	newCmd.Flags().StringVar(&newKind, "kind", "",
+		"Project kind (see 'pk kind list')")
+	newCmd.Flags().StringVarP(&newTemplate, "template", "t", "",
+		"Scaffold from ~/.config/pk/templates/<name>")
 	newCmd.Flags().BoolVar(&newNoGit, "no-git", false,
 		"Skip git initialization")
+	newCmd.Flags().StringVar(&newID, "id", "",
+		"Project ID (default: derived from the name)")
+	newCmd.Flags().StringVar(&newClient, "client", "",
+		"Client name (consultant.client_name)")
+	newCmd.Flags().BoolVar(&newSidecar, "sidecar", false,
+		"Keep metadata in ~/.local/share/pk/meta instead of the project")
+	newCmd.Flags().BoolVar(&newGitHub, "github", false,
+		"Create the repository on GitHub and push to it")
+	newCmd.Flags().BoolVar(&newPrivate, "private", false,
+		"Make the GitHub repository private (with --github)")
+	newCmd.MarkFlagsMutuallyExclusive("github", "no-git")
+	newCmd.RegisterFlagCompletionFunc("kind", completeKindNames)
+	newCmd.RegisterFlagCompletionFunc("template", completeTemplateNames)
 }
 
 func runNew(cmd *cobra.Command, args []string) {
-	projectName := args[0]
-
-	// Validate project name
-	if strings.ContainsAny(projectName, "/\\:*?\"<>|") {
-		fmt.Fprintf(os.Stderr, "Error: Invalid project name. Avoid special characters.\n")
+	projectName := strings.TrimSpace(args[0])
+	if projectName == "" {
+		fmt.Fprintf(os.Stderr, "Error: Project name is empty\n")
 		os.Exit(1)
 	}
 
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
+	// Template defaults apply unless the flag was given explicitly
+	var tpl *templates.Template
+	var err error
+	if newTemplate != "" {
+		tpl, err = templates.Load(newTemplate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if names, _ := templates.Names(); len(names) > 0 {
+				fmt.Fprintf(os.Stderr, "Available templates: %s\n", strings.Join(names, ", "))
+			}
+			os.Exit(1)
+		}
+		if !cmd.Flags().Changed("type") && tpl.ProjectInfo.Type != "" {
+			newType = tpl.ProjectInfo.Type
+		}
+		if !cmd.Flags().Changed("kind") && tpl.ProjectInfo.Kind != "" {
+			newKind = tpl.ProjectInfo.Kind
+		}
+		if !cmd.Flags().Changed("owner") && tpl.Consultant.Ownership != "" {
+			newOwner = tpl.Consultant.Ownership
+		}
+	}
+
+	if newPrivate && !newGitHub {
+		fmt.Fprintf(os.Stderr, "Error: --private only applies with --github\n")
 		os.Exit(1)
 	}
 
-	projectPath := filepath.Join(homeDir, "projects", projectName)
+	if newKind != "" {
+		if _, exists := loadSettings().AllKinds()[newKind]; !exists {
+			fmt.Fprintf(os.Stderr, "Error: Unknown kind '%s' (see 'pk kind list')\n", newKind)
+			os.Exit(1)
+		}
+	}
+
+	projectsDir := projectPaths().Projects()
+	projectID := newProjectID(projectName, projectsDir)
+	projectPath := filepath.Join(projectsDir, projectID)
+	if projectID != projectName {
+		fmt.Printf("Project ID: %s\n", projectID)
+	}
 
-	// Check if project already exists
-	if _, err := os.Stat(projectPath); err == nil {
-		fmt.Fprintf(os.Stderr, "Error: Project '%s' already exists at %s\n", projectName, projectPath)
-		os.Exit(1)
+	var base config.Project
+	if tpl != nil {
+		base = tpl.Project
 	}
+	project := newProjectMetadata(projectName, projectID, projectPath, base)
+	enforceNaming(project, "Use --id to choose a different ID")
 
 	// Create project directory
 	if err := os.MkdirAll(projectPath, 0755); err != nil {
@@ -82,20 +172,55 @@ func runNew(cmd *cobra.Command, args []string) {
 	fmt.Printf("Created project directory: %s\n", projectPath)
 
 	// Initialize git repository
+	gitInitialized := false
 	if !newNoGit {
-		gitCmd := exec.Command("git", "init")
+		gitCmd := runner.Command("git", "init")
 		gitCmd.Dir = projectPath
-		if err := gitCmd.Run(); err != nil {
+		if err := runner.Run(gitCmd); err != nil {
 			fmt.Printf("Warning: git init failed: %v\n", err)
 			fmt.Printf("Continuing without git...\n")
 		} else {
 			fmt.Println("Initialized git repository")
+			gitInitialized = true
+		}
+	}
+
+	// Scaffold template files
+	if tpl != nil {
+		now := time.Now()
+		data := templates.Data{
+			Name:  projectName,
+			ID:    projectID,
+			Owner: newOwner,
+			Type:  newType,
+			Kind:  newKind,
+			Date:  now.Format("2006-01-02"),
+			Year:  now.Format("2006"),
+		}
+		if err := tpl.Scaffold(projectPath, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to apply template '%s': %v\n", tpl.Name, err)
+			os.RemoveAll(projectPath)
+			os.Exit(1)
+		}
+		fmt.Printf("Applied template: %s\n", tpl.Name)
+	}
+
+	// Create the GitHub repository first, so its URL is in the metadata
+	var remote *forge.CreatedRepo
+	if newGitHub && gitInitialized {
+		remote, err = forge.CreateGitHubRepo(projectID, strings.TrimSpace(project.Notes.Description), newPrivate)
+		if err != nil {
+			fmt.Printf("Warning: Failed to create the GitHub repository: %v\n", err)
+			fmt.Printf("Continuing with a local repository...\n")
+		} else {
+			fmt.Printf("Created GitHub repository: %s\n", remote.HTMLURL)
+			project.Links.Repository = remote.HTMLURL
 		}
 	}
 
 	// Create .project.toml
 	tomlPath := filepath.Join(projectPath, ".project.toml")
-	if err := createProjectToml(tomlPath, projectName, projectPath); err != nil {
+	if err := project.SaveAs(tomlPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to create .project.toml: %v\n", err)
 		// Clean up
 		os.RemoveAll(projectPath)
@@ -103,61 +228,189 @@ func runNew(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Printf("Created metadata: %s\n", tomlPath)
+	hideMetadata(tomlPath, newSidecar)
+	if remote != nil {
+		publishToGitHub(projectPath, remote)
+	}
+	events.Emit(events.ProjectCreated, projectID, projectPath, map[string]string{"source": "new"})
 
 	// Sync aliases
-	fmt.Println("Syncing aliases...")
-	runSync(cmd, []string{})
+	syncScopes(syncAliases)
 
-	// Invalidate cache for pk session
+	// Invalidate cache for pk session. Choosing the ID read the cache, which
+	// may now hold a copy from before the project existed.
+	cache.InvalidateCache()
 	hooks.InvalidateCache()
 
 	fmt.Printf("\n\033[32m✓\033[0m Project '%s' created successfully!\n", projectName)
 	fmt.Printf("\nNext steps:\n")
-	fmt.Printf("  cd ~/projects/%s\n", projectName)
-	fmt.Printf("  %s      # Jump to project (after reloading shell)\n", projectName)
+	fmt.Printf("  cd %s\n", projectPath)
+	fmt.Printf("  %s      # Jump to project (after reloading shell)\n", projectID)
+}
+
+// newProjectID returns the ID for a new project: --id as given, or one
+// derived from the name per [ids] and made unique among known projects and
+// directories in the projects root
+func newProjectID(name, projectsDir string) string {
+	rules := loadSettings().IDs
+
+	taken := func(id string) bool {
+		_, err := os.Stat(filepath.Join(projectsDir, id))
+		return err == nil
+	}
+	if projects, err := cache.FindProjectsCached(cacheRoots()...); err == nil {
+		ids := make(map[string]bool, len(projects))
+		for _, p := range projects {
+			ids[p.ProjectInfo.ID] = true
+		}
+		exists := taken
+		taken = func(id string) bool { return ids[id] || exists(id) }
+	}
+
+	if newID != "" {
+		if !slug.Valid(newID, rules) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid ID '%s' (want e.g. '%s')\n", newID, slug.Make(newID, "", rules))
+			os.Exit(1)
+		}
+		if taken(newID) {
+			fmt.Fprintf(os.Stderr, "Error: Project ID '%s' is already in use\n", newID)
+			os.Exit(1)
+		}
+		return newID
+	}
+
+	id := slug.Make(name, newClient, rules)
+	if id == "" {
+		fmt.Fprintf(os.Stderr, "Error: Can't derive an ID from '%s'; set one with --id\n", name)
+		os.Exit(1)
+	}
+	if unique := slug.Unique(id, rules, taken); unique != id {
+		fmt.Printf("Project ID '%s' is already in use, using '%s'\n", id, unique)
+		id = unique
+	}
+	return id
 }
 
-func createProjectToml(path, name, projectPath string) error {
-	// Create template project with NEW schema
-	var project config.Project
+// newProjectMetadata builds a new project's metadata from the flags
+func newProjectMetadata(name, id, projectPath string, base config.Project) *config.Project {
+	// Create template project with NEW schema, starting from template defaults
+	project := base
 	project.Path = projectPath
 
 	// Core fields
 	project.ProjectInfo.Name = name
-	project.ProjectInfo.ID = name
-	project.ProjectInfo.Status = "active"
+	project.ProjectInfo.ID = id
+	if project.ProjectInfo.Status == "" {
+		project.ProjectInfo.Status = "active"
+	}
 	project.ProjectInfo.Type = newType
-	project.Tech.Stack = []string{}
-	project.Tech.Domain = []string{}
+	project.ProjectInfo.Kind = newKind
+	if project.Tech.Stack == nil {
+		project.Tech.Stack = []string{}
+	}
+	if project.Tech.Domain == nil {
+		project.Tech.Domain = []string{}
+	}
 	project.Dates.Started = time.Now().Format("2006-01-02")
 	project.Dates.Completed = ""
-	project.Links.Repository = ""
-	project.Links.Documentation = ""
-	project.Notes.Description = ""
 
 	// Consultant extension (only if owner is specified)
 	if newOwner != "" {
 		project.Consultant.Ownership = newOwner
-		project.Consultant.MyRole = "owner"
+		if project.Consultant.MyRole == "" {
+			project.Consultant.MyRole = "owner"
+		}
+	}
+	if newClient != "" {
+		project.Consultant.ClientName = newClient
 	}
 
 	// DataKai extension (only for DataKai projects)
 	if newOwner == "datakai" {
-		project.DataKai.Visibility = "private" // Default for new DataKai projects
-		project.Dev.Roadmap = ".dev/ROADMAP.md" // Standard roadmap location for DataKai
+		if project.DataKai.Visibility == "" {
+			project.DataKai.Visibility = "private" // Default for new DataKai projects
+		}
+		if project.Dev.Roadmap == "" {
+			project.Dev.Roadmap = ".dev/ROADMAP.md" // Standard roadmap location for DataKai
+		}
 	}
 
-	// Write TOML file
-	f, err := os.Create(path)
+	return &project
+}
+
+// hideMetadata keeps a new project's pk files out of its repository as
+// [metadata] in config.toml says for the project's visibility, or in a
+// sidecar file when sidecar is set. Other modes leave projects without a
+// git repository alone.
+func hideMetadata(tomlPath string, sidecar bool) {
+	project, err := config.LoadProject(tomlPath)
 	if err != nil {
-		return err
+		return
 	}
-	defer f.Close()
+	mode := loadSettings().MetadataMode(project.DataKai.Visibility)
+	if sidecar || mode == git.MetadataSidecar {
+		file, err := project.MoveToSidecar(true)
+		if err != nil {
+			fmt.Printf("Warning: Failed to move metadata to a sidecar file: %v\n", err)
+			return
+		}
+		fmt.Printf("Moved pk metadata out of the repository to %s\n", file)
+		return
+	}
+	if gitOutput(project.Path, "rev-parse", "--git-dir") == "" {
+		return
+	}
+	changed, err := git.HideMetadata(project.Path, mode)
+	if err != nil {
+		fmt.Printf("Warning: Failed to keep metadata out of the repository: %v\n", err)
+		return
+	}
+	if changed != "" {
+		fmt.Printf("Kept pk metadata out of the repository (%s in %s)\n", mode, changed)
+	}
+}
+
+// publishToGitHub adds a new GitHub repository as origin, commits the
+// scaffolded project and pushes it. Failures are warnings: the project
+// exists either way, and the remaining steps can be done by hand.
+func publishToGitHub(projectPath string, remote *forge.CreatedRepo) {
+	url := githubRemoteURL(remote.CloneURL, remote.SSHURL)
+	steps := [][]string{
+		{"remote", "add", "origin", url},
+		{"add", "-A"},
+		{"commit", "--quiet", "-m", "Initial commit"},
+		{"push", "--quiet", "-u", "origin", "HEAD"},
+	}
+	for _, args := range steps {
+		gitCmd := runner.Command("git", args...)
+		gitCmd.Dir = projectPath
+		if _, err := runner.Output(gitCmd); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+				err = errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			fmt.Printf("Warning: git %s failed: %v\n", args[0], err)
+			return
+		}
+	}
+	fmt.Printf("Pushed the initial commit to %s\n", url)
+}
 
-	// Write header comment
-	fmt.Fprintln(f, "# Project Metadata")
-	fmt.Fprintln(f, "")
+// githubRemoteURL picks a GitHub repository's https or ssh URL, following
+// gh's git_protocol when gh is installed, since its credential helper
+// serves that one
+func githubRemoteURL(httpsURL, sshURL string) string {
+	if deps.Available("gh") && sshURL != "" {
+		if output, err := runner.Output(runner.Command("gh", "config", "get", "git_protocol")); err == nil &&
+			strings.TrimSpace(string(output)) == "ssh" {
+			return sshURL
+		}
+	}
+	return httpsURL
+}
 
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(&project)
+// completeTemplateNames completes --template with names from the templates directory
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, _ := templates.Names()
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
 }
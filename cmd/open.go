@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/editor"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	openEditor bool
+	openRepo   bool
+	openDocs   bool
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open [project] [--editor|--repo|--docs]",
+	Short: "Open a project in $EDITOR, or its repository or docs in the browser",
+	Long: `Act on the links 'pk show' displays:
+
+  --editor  Open the project directory in $EDITOR (the default)
+  --repo    Open links.repository in the browser; ssh remotes open as
+            their https page
+  --docs    Open links.documentation; a path is resolved against the
+            project directory
+
+The browser is $BROWSER when set, else open (macOS) or xdg-open.
+If no project is specified, displays an interactive selector.
+
+Example:
+  pk open dojo             # Edit dojo in $EDITOR
+  pk open dojo --repo      # Its repository page
+  pk open dojo --docs      # Its documentation`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runOpen,
+	ValidArgsFunction: validAllProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().BoolVarP(&openEditor, "editor", "e", false, "Open the project directory in $EDITOR")
+	openCmd.Flags().BoolVarP(&openRepo, "repo", "r", false, "Open links.repository in the browser")
+	openCmd.Flags().BoolVarP(&openDocs, "docs", "d", false, "Open links.documentation")
+	openCmd.MarkFlagsMutuallyExclusive("editor", "repo", "docs")
+}
+
+func runOpen(cmd *cobra.Command, args []string) {
+	project := resolveOpenTarget(args)
+	if project == nil {
+		return
+	}
+
+	var link, key string
+	switch {
+	case openRepo:
+		link, key = project.Links.Repository, "links.repository"
+	case openDocs:
+		link, key = project.Links.Documentation, "links.documentation"
+	default:
+		cache.RecordAccess(project.ProjectInfo.ID, project.Path)
+
+		fields := strings.Fields(editor.Terminal())
+		editorCmd := runner.Command(fields[0], append(fields[1:], ".")...)
+		editorCmd.Dir = project.Path
+		editorCmd.Stdin = os.Stdin
+		editorCmd.Stdout = os.Stdout
+		editorCmd.Stderr = os.Stderr
+		if err := runner.Run(editorCmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Editor failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if link == "" {
+		fmt.Fprintf(os.Stderr, "Error: '%s' has no %s\n", project.ProjectInfo.ID, key)
+		if openRepo {
+			fmt.Fprintf(os.Stderr, "Hint: 'pk sync links' fills it from the git remote\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "Hint: Set it with 'pk edit %s'\n", project.ProjectInfo.ID)
+		}
+		os.Exit(1)
+	}
+
+	target := editor.WebURL(link, project.Path)
+	if filepath.IsAbs(target) {
+		if _, err := os.Stat(target); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s %s does not exist\n", key, target)
+			os.Exit(1)
+		}
+	}
+	if err := editor.OpenInBrowser(target); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to open %s: %v\n", target, err)
+		os.Exit(1)
+	}
+	fmt.Printf("\033[32m✓\033[0m Opened %s\n", target)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/picker"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+// popupMode tunes pickers for running inside 'tmux display-popup'
+var popupMode bool
+
+// applyPopupMode propagates --popup to the session package
+func applyPopupMode() {
+	session.PopupMode = popupMode
+}
+
+// requireTools is used in PreRunE dependency checks: a missing tool is not
+// a usage error, so don't print the usage text after the install hint
+func requireTools(cmd *cobra.Command, err error) error {
+	if err != nil {
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+// pick runs the interactive picker (fzf, or the built-in one without it)
+// and returns the chosen line, or false if the user cancelled. If there's
+// no terminal to pick on, it exits with the error and an alternative.
+func pick(lines []string, opts picker.Options, alternative string) (string, bool) {
+	selection, err := picker.Pick(lines, withPickerSettings(opts))
+	if errors.Is(err, picker.ErrCancelled) {
+		return "", false
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "\nAlternatively, %s\n", alternative)
+		os.Exit(1)
+	}
+	return selection, true
+}
+
+// fzfLayoutArgs returns fzf sizing flags for the current picker mode.
+// Inside a popup, tmux already draws the frame and sizes the window,
+// so fzf fills it without its own border.
+func fzfLayoutArgs() []string {
+	if popupMode {
+		return []string{"--height", "100%", "--reverse", "--info", "inline"}
+	}
+	return []string{"--height", "60%", "--reverse", "--border"}
+}
+
+// withPickerSettings applies [picker] from the global config: backend,
+// extra flags and bindings after pk's own, and the initial query
+func withPickerSettings(opts picker.Options) picker.Options {
+	s := loadSettings().Picker
+	opts.Backend = s.Backend
+	opts.FzfArgs = append(opts.FzfArgs, s.Args...)
+	opts.Bind = append(opts.Bind, s.Bind...)
+	if opts.Query == "" {
+		opts.Query = s.Query
+	}
+	return opts
+}
+
+// projectPreviewArgs returns the preview flags for pickers whose first
+// column is a project ID: [picker] preview if set, else 'pk __preview'
+func projectPreviewArgs() []string {
+	preview := loadSettings().Picker.Preview
+	switch preview {
+	case "none":
+		return nil
+	case "":
+		preview = "pk __preview {1} 2>/dev/null"
+	}
+	return []string{"--preview", preview, "--preview-window", "right:40%:wrap"}
+}
+
+// branchMarkers returns the picker's branch column for each project by
+// path: the branch, with * when there are uncommitted changes, or "-"
+// outside a repository. Statuses are cached briefly (see
+// git.CachedSummaries), so opening a picker again doesn't reread them.
+func branchMarkers(projects []*config.Project) map[string]string {
+	dirs := make([]string, len(projects))
+	for i, p := range projects {
+		dirs[i] = p.Path
+	}
+	summaries := git.CachedSummaries(dirs)
+
+	markers := make(map[string]string, len(projects))
+	for _, p := range projects {
+		s, ok := summaries[p.Path]
+		switch {
+		case !ok:
+			markers[p.Path] = "-"
+			continue
+		case s.Branch == "":
+			markers[p.Path] = "(detached)"
+		default:
+			markers[p.Path] = s.Branch
+		}
+		if s.Dirty {
+			markers[p.Path] += "*"
+		}
+	}
+	return markers
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -102,19 +101,15 @@ func runPinAdd(cmd *cobra.Command, args []string) {
 	}
 
 	// Find the project
-	homeDir, _ := os.UserHomeDir()
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scratchDir := filepath.Join(homeDir, "scratch")
-
-	projects, err := cache.FindProjectsCached(projectsDir, archiveDir)
+	resolver := projectPaths()
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Check scratch projects too
-	scratchProjects, _ := findScratchProjects(scratchDir)
+	scratchProjects, _ := findScratchProjects(resolver.Scratch())
 	projects = append(projects, scratchProjects...)
 
 	// Find matching project
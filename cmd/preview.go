@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var previewCmd = &cobra.Command{
+	Use:    "__preview <project>",
+	Short:  "Describe a project for picker previews",
+	Hidden: true,
+	Long: `Called by the fzf preview of the project pickers ('pk session', 'pk
+sessions'): name, path, status, client, stack, the repository's branch,
+changes and upstream, whether its session is running, last access and the
+latest note. A leading pin slot ("[2]dojo") is ignored.
+
+Reads git live for the one project shown. Always exits 0.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPreview,
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+}
+
+func runPreview(cmd *cobra.Command, args []string) {
+	id := args[0]
+	if strings.HasPrefix(id, "[") {
+		if _, rest, ok := strings.Cut(id, "]"); ok {
+			id = rest
+		}
+	}
+
+	project := previewProject(id)
+	if project == nil {
+		fmt.Printf("\033[1m%s\033[0m\n", id)
+		return
+	}
+
+	fmt.Printf("\033[1m%s\033[0m", project.ProjectInfo.ID)
+	if name := project.ProjectInfo.Name; name != "" && name != project.ProjectInfo.ID {
+		fmt.Printf(" · %s", name)
+	}
+	fmt.Println()
+	homeDir, _ := os.UserHomeDir()
+	path := project.Path
+	if rel, err := filepath.Rel(homeDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = filepath.Join("~", rel)
+	}
+	fmt.Printf("\033[2m%s\033[0m\n\n", path)
+
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Printf("%-9s %s\n", label+":", value)
+		}
+	}
+	status := project.ProjectInfo.Status
+	if project.ProjectInfo.Mirror {
+		status += " (mirror)"
+	}
+	if status != "" {
+		field("Status", getStatusColor(project.ProjectInfo.Status)+status+"\033[0m")
+	}
+	field("Client", project.GetClientName())
+	field("Owner", project.GetOwner())
+	field("Stack", strings.Join(project.Tech.Stack, ", "))
+
+	if git.IsRepo(project.Path) {
+		if s, err := git.ReadStatus(project.Path); err == nil {
+			branch := s.Branch
+			if branch == "" {
+				branch = "detached"
+			}
+			field("Branch", fmt.Sprintf("%s  %s", branch, describeSync(s)))
+			field("Changes", describeChanges(s))
+		}
+	}
+
+	if session.SessionExists(session.SanitizeSessionName(project.ProjectInfo.ID)) {
+		field("Session", "\033[32m● running\033[0m")
+	} else {
+		field("Session", "\033[90mnot running\033[0m")
+	}
+	records, _ := cache.LoadAccessRecords()
+	if r, ok := records[project.ProjectInfo.ID]; ok && !r.LastAccessed.IsZero() {
+		field("Accessed", fmt.Sprintf("%s (%d opens)", formatAccessTime(r.LastAccessed), r.AccessCount))
+	}
+
+	if description := strings.TrimSpace(project.Notes.Description); description != "" {
+		fmt.Printf("\n%s\n", description)
+	}
+	fmt.Println()
+	printLatestNote(project.ProjectInfo.ID)
+}
+
+// previewProject finds a project or scratch directory by ID, or nil
+func previewProject(id string) *config.Project {
+	projects, _ := cache.FindProjectsCached(cacheRoots()...)
+	scratch, _ := findScratchProjects(projectPaths().Scratch())
+	for _, p := range append(projects, scratch...) {
+		if p.ProjectInfo.ID == id {
+			return p
+		}
+	}
+	return nil
+}
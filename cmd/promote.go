@@ -3,21 +3,23 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/detect"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/runner"
 	"github.com/spf13/cobra"
 )
 
 var (
-	promoteMove   bool
-	promoteNoGit  bool
-	promoteOwner  string
-	promoteType   string
+	promoteMove    bool
+	promoteNoGit   bool
+	promoteOwner   string
+	promoteType    string
+	promoteSidecar bool
 )
 
 var promoteCmd = &cobra.Command{
@@ -34,7 +36,8 @@ Scratch projects in ~/scratch are automatically moved to ~/projects.
 Example:
   pk promote api-test                            # Auto-detects scratch project
   pk promote /path/to/existing-work --move
-  pk promote . --no-git                          # Promote current directory`,
+  pk promote . --no-git                          # Promote current directory
+  pk promote ~/work/client-repo --sidecar        # No pk files in the repository`,
 	Args: cobra.ExactArgs(1),
 	Run:  runPromote,
 }
@@ -49,18 +52,17 @@ func init() {
 		"Project owner")
 	promoteCmd.Flags().StringVar(&promoteType, "type", "product",
 		"Project type")
+	promoteCmd.Flags().BoolVar(&promoteSidecar, "sidecar", false,
+		"Keep metadata in ~/.local/share/pk/meta instead of the directory")
 }
 
 func runPromote(cmd *cobra.Command, args []string) {
-	// Get home directory first for scratch detection
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
+	// Resolve roots first for scratch detection
+	resolver := projectPaths()
 
 	// Resolve path - check if it's a scratch project name
 	var dirPath string
+	var err error
 	if args[0] == "." {
 		dirPath, err = os.Getwd()
 		if err != nil {
@@ -70,7 +72,7 @@ func runPromote(cmd *cobra.Command, args []string) {
 	} else {
 		// Check if it's a simple name (no path separators) - might be scratch project
 		if !strings.Contains(args[0], string(filepath.Separator)) && !filepath.IsAbs(args[0]) {
-			scratchPath := filepath.Join(homeDir, "scratch", args[0])
+			scratchPath := filepath.Join(resolver.Scratch(), args[0])
 			if _, err := os.Stat(scratchPath); err == nil {
 				dirPath = scratchPath
 				promoteMove = true // Auto-enable move for scratch projects
@@ -106,15 +108,18 @@ func runPromote(cmd *cobra.Command, args []string) {
 	projectName := filepath.Base(dirPath)
 
 	// Check if already a project
-	tomlPath := filepath.Join(dirPath, ".project.toml")
+	tomlPath := config.MetadataFile(dirPath)
 	if _, err := os.Stat(tomlPath); err == nil {
 		fmt.Fprintf(os.Stderr, "Error: Already a project (found %s)\n", tomlPath)
 		os.Exit(1)
 	}
 
+	project := promoteProjectMetadata(projectName, dirPath)
+	enforceNaming(project, "Rename the directory to change the project ID")
+
 	// Move to ~/projects if --move
 	if promoteMove {
-		newPath := filepath.Join(homeDir, "projects", projectName)
+		newPath := filepath.Join(resolver.Projects(), projectName)
 
 		// Check if destination exists
 		if _, err := os.Stat(newPath); err == nil {
@@ -136,15 +141,16 @@ func runPromote(cmd *cobra.Command, args []string) {
 
 		fmt.Printf("Moved to: %s\n", newPath)
 		dirPath = newPath
+		project.Path = newPath
 	}
 
 	// Initialize git if needed
 	gitDir := filepath.Join(dirPath, ".git")
 	if _, err := os.Stat(gitDir); err != nil {
 		if !promoteNoGit {
-			gitCmd := exec.Command("git", "init")
+			gitCmd := runner.Command("git", "init")
 			gitCmd.Dir = dirPath
-			if err := gitCmd.Run(); err != nil {
+			if err := runner.Run(gitCmd); err != nil {
 				fmt.Printf("Warning: git init failed: %v\n", err)
 			} else {
 				fmt.Println("Initialized git repository")
@@ -156,16 +162,17 @@ func runPromote(cmd *cobra.Command, args []string) {
 
 	// Create .project.toml
 	tomlPath = filepath.Join(dirPath, ".project.toml")
-	if err := createPromoteProjectToml(tomlPath, projectName, dirPath); err != nil {
+	if err := project.SaveAs(tomlPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to create .project.toml: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Printf("Created metadata: %s\n", tomlPath)
+	hideMetadata(tomlPath, promoteSidecar)
+	events.Emit(events.ProjectCreated, projectName, dirPath, map[string]string{"source": "promote"})
 
 	// Sync aliases
-	fmt.Println("Syncing aliases...")
-	runSync(cmd, []string{})
+	syncScopes(syncAliases)
 
 	fmt.Printf("\n\033[32m✓\033[0m Project '%s' promoted successfully!\n", projectName)
 	fmt.Printf("\nNext steps:\n")
@@ -173,7 +180,8 @@ func runPromote(cmd *cobra.Command, args []string) {
 	fmt.Printf("  pk show %s\n", projectName)
 }
 
-func createPromoteProjectToml(path, name, projectPath string) error {
+// promoteProjectMetadata builds metadata for a directory being promoted
+func promoteProjectMetadata(name, projectPath string) *config.Project {
 	// Create template project
 	var project config.Project
 	project.Path = projectPath
@@ -191,6 +199,7 @@ func createPromoteProjectToml(path, name, projectPath string) error {
 	project.Links.Documentation = ""
 	project.Links.ConduitGraph = ""
 	project.Notes.Description = ""
+	detect.Apply(&project, projectPath)
 
 	// DataKai extension (only for DataKai projects)
 	if promoteOwner == "datakai" {
@@ -198,17 +207,5 @@ func createPromoteProjectToml(path, name, projectPath string) error {
 		project.Dev.Roadmap = ".dev/ROADMAP.md"
 	}
 
-	// Write TOML file
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	// Write header comment
-	fmt.Fprintln(f, "# Project Metadata")
-	fmt.Fprintln(f, "")
-
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(&project)
+	return &project
 }
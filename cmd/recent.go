@@ -13,15 +13,17 @@ var recentLimit int
 
 var recentCmd = &cobra.Command{
 	Use:   "recent",
-	Short: "List recently accessed projects",
-	Long: `List projects sorted by most recent access time.
+	Short: "List projects you use most, by frequency and recency",
+	Long: `List projects by frecency: how often and how recently you've opened them.
 
-Shows projects you've opened with 'pk session' recently. Projects never
-accessed are not shown.
+A project opened many times this week ranks above one opened once a minute
+ago, and one used heavily months ago fades below both. The same ranking
+orders the 'pk session' and 'pk sessions' pickers. Projects never accessed
+are not shown.
 
 Examples:
-  pk recent           # Show 10 most recent projects
-  pk recent --limit 5 # Show 5 most recent projects`,
+  pk recent           # Show the top 10
+  pk recent --limit 5 # Show the top 5`,
 	Run: runRecent,
 }
 
@@ -44,13 +46,43 @@ func runRecent(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if format := structuredFormat(cmd); format != "" {
+		type recentProject struct {
+			ID           string    `json:"id"`
+			Name         string    `json:"name"`
+			Owner        string    `json:"owner"`
+			Status       string    `json:"status"`
+			Path         string    `json:"path"`
+			LastAccessed time.Time `json:"last_accessed"`
+			AccessCount  int       `json:"access_count"`
+		}
+		recent := []recentProject{}
+		for _, p := range projects {
+			record, ok := accessRecords[p.ProjectInfo.ID]
+			if !ok {
+				continue
+			}
+			recent = append(recent, recentProject{
+				ID:           p.ProjectInfo.ID,
+				Name:         p.ProjectInfo.Name,
+				Owner:        p.GetOwner(),
+				Status:       p.ProjectInfo.Status,
+				Path:         p.Path,
+				LastAccessed: record.LastAccessed,
+				AccessCount:  record.AccessCount,
+			})
+		}
+		printStructured(format, recent)
+		return
+	}
+
 	if len(projects) == 0 {
 		fmt.Println("No recently accessed projects")
 		fmt.Println("\nTip: Projects are tracked when you open them with 'pk session'")
 		return
 	}
 
-	fmt.Printf("Recently accessed projects (showing %d):\n\n", len(projects))
+	fmt.Printf("Recent projects by frecency (showing %d):\n\n", len(projects))
 
 	for _, p := range projects {
 		record, ok := accessRecords[p.ProjectInfo.ID]
@@ -58,31 +90,7 @@ func runRecent(cmd *cobra.Command, args []string) {
 			continue
 		}
 
-		// Format access time
-		accessTime := record.LastAccessed
-		var timeStr string
-
-		now := time.Now()
-		diff := now.Sub(accessTime)
-
-		if diff < time.Minute {
-			timeStr = "just now"
-		} else if diff < time.Hour {
-			minutes := int(diff.Minutes())
-			timeStr = fmt.Sprintf("%dm ago", minutes)
-		} else if diff < 24*time.Hour {
-			hours := int(diff.Hours())
-			timeStr = fmt.Sprintf("%dh ago", hours)
-		} else if diff < 7*24*time.Hour {
-			days := int(diff.Hours() / 24)
-			if days == 1 {
-				timeStr = "1 day ago"
-			} else {
-				timeStr = fmt.Sprintf("%d days ago", days)
-			}
-		} else {
-			timeStr = accessTime.Format("Jan 2, 2006")
-		}
+		timeStr := formatAccessTime(record.LastAccessed)
 
 		owner := p.GetOwner()
 		if owner == "" {
@@ -94,12 +102,46 @@ func runRecent(cmd *cobra.Command, args []string) {
 			status = "unknown"
 		}
 
-		fmt.Printf("%-25s [%s] %-12s  %s\n",
+		fmt.Printf("%-25s [%s] %-12s  %-14s %s\n",
 			p.ProjectInfo.ID,
 			owner,
 			status,
-			timeStr)
+			timeStr,
+			openCount(record))
 	}
 
 	fmt.Printf("\nUse 'pk session <name>' to open a project\n")
 }
+
+// openCount describes how many times a project has been opened
+func openCount(record cache.AccessRecord) string {
+	switch record.AccessCount {
+	case 0:
+		return "" // Recorded before counts were kept
+	case 1:
+		return "opened once"
+	}
+	return fmt.Sprintf("opened %d times", record.AccessCount)
+}
+
+// formatAccessTime describes when something was accessed, relative to now
+// for the last week
+func formatAccessTime(accessTime time.Time) string {
+	diff := time.Since(accessTime)
+
+	switch {
+	case diff < time.Minute:
+		return "just now"
+	case diff < time.Hour:
+		return fmt.Sprintf("%dm ago", int(diff.Minutes()))
+	case diff < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(diff.Hours()))
+	case diff < 7*24*time.Hour:
+		days := int(diff.Hours() / 24)
+		if days == 1 {
+			return "1 day ago"
+		}
+		return fmt.Sprintf("%d days ago", days)
+	}
+	return accessTime.Format("Jan 2, 2006")
+}
@@ -6,54 +6,58 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/datakaicr/pk/pkg/slug"
 	"github.com/spf13/cobra"
 )
 
+var renameID string
+
 var renameCmd = &cobra.Command{
 	Use:   "rename <old-name> <new-name>",
 	Short: "Rename a project",
-	Long: `Rename a project directory and update all metadata.
+	Long: `Rename a project and carry everything pk knows about it along.
 
 This will:
   1. Validate both old and new names
-  2. Rename the project directory
+  2. Rename the project directory to the new ID
   3. Update .project.toml (name and ID)
-  4. Auto-sync shell aliases
+  4. Rename the project's running session, if any
+  5. Move pins, access history, triage snoozes and journal notes to the new ID
+  6. Refresh the project cache and shell aliases
+
+The ID is the new name if it is a valid ID, otherwise derived from it per
+[ids] in config.toml (see 'pk new'); --id sets it explicitly.
 
 Example:
   pk rename old-name new-name
-  pk rename prototype awesome-product`,
-	Args: cobra.ExactArgs(2),
-	Run:  runRename,
+  pk rename prototype awesome-product
+  pk rename acme-poc "Acme Data Platform" --id acme-platform`,
+	Args:              cobra.ExactArgs(2),
+	Run:               runRename,
+	ValidArgsFunction: validProjectNames,
 }
 
 func init() {
 	rootCmd.AddCommand(renameCmd)
+	renameCmd.Flags().StringVar(&renameID, "id", "",
+		"New project ID (default: derived from the new name)")
 }
 
 func runRename(cmd *cobra.Command, args []string) {
 	oldName := strings.ToLower(args[0])
-	newName := args[1]
+	newName := strings.TrimSpace(args[1])
 
 	// Validate new name
-	if strings.ContainsAny(newName, "/\\:*?\"<>|") {
+	if newName == "" || strings.ContainsAny(newName, "/\\:*?\"<>|") {
 		fmt.Fprintf(os.Stderr, "Error: Invalid project name. Avoid special characters.\n")
 		os.Exit(1)
 	}
 
-	// Find project
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-
-	projects, err := config.FindProjects(projectsDir, archiveDir)
+	projects, err := findProjects(cacheRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
 		os.Exit(1)
@@ -74,69 +78,125 @@ func runRename(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Determine new path
-	parentDir := filepath.Dir(found.Path)
-	newPath := filepath.Join(parentDir, newName)
+	oldID, oldPath := found.ProjectInfo.ID, found.Path
+	newID := renameProjectID(newName, found, projects)
 
-	// Check if new name already exists
-	if _, err := os.Stat(newPath); err == nil {
-		fmt.Fprintf(os.Stderr, "Error: A project with name '%s' already exists at %s\n", newName, newPath)
-		os.Exit(1)
+	renamed := *found
+	renamed.ProjectInfo.Name, renamed.ProjectInfo.ID = newName, newID
+	enforceNaming(&renamed, "Use --id to choose a different ID")
+
+	// Determine new path
+	newPath := filepath.Join(filepath.Dir(oldPath), newID)
+	if newPath != oldPath {
+		if _, err := os.Stat(newPath); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists\n", newPath)
+			os.Exit(1)
+		}
 	}
 
 	fmt.Printf("Renaming project: %s → %s\n", found.ProjectInfo.Name, newName)
-	fmt.Printf("Location: %s → %s\n", found.Path, newPath)
-
-	// Rename directory
-	if err := os.Rename(found.Path, newPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to rename directory: %v\n", err)
-		os.Exit(1)
+	if newID != oldID {
+		fmt.Printf("ID: %s → %s\n", oldID, newID)
 	}
+	if newPath != oldPath {
+		fmt.Printf("Location: %s → %s\n", oldPath, newPath)
 
-	fmt.Printf("\033[32m✓\033[0m Directory renamed\n")
+		if err := os.Rename(oldPath, newPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to rename directory: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\033[32m✓\033[0m Directory renamed\n")
+	}
 
 	// Update .project.toml
-	tomlPath := filepath.Join(newPath, ".project.toml")
-	if err := updateProjectTomlRename(tomlPath, newName, newPath); err != nil {
+	if err := updateProjectTomlRename(found.FileIn(newPath), newName, newID, newPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to update .project.toml: %v\n", err)
 		fmt.Fprintf(os.Stderr, "Directory was renamed but metadata update failed.\n")
 		os.Exit(1)
 	}
-
 	fmt.Printf("\033[32m✓\033[0m Metadata updated\n")
 
-	// Sync aliases
-	fmt.Println("Syncing aliases...")
-	runSync(cmd, []string{})
+	if newID != oldID {
+		renameProjectSession(oldID, newID)
+	}
+
+	if err := cache.MoveState(oldID, newID, newPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to move pins and history: %v\n", err)
+	} else {
+		fmt.Printf("\033[32m✓\033[0m Pins, access history and journal moved\n")
+	}
+	events.Emit(events.ProjectRenamed, newID, newPath, map[string]string{"from": oldID, "from_path": oldPath})
+
+	cache.InvalidateCache()
+	syncScopes(syncAliases)
 
 	fmt.Printf("\n\033[32m✓\033[0m Project renamed successfully!\n")
 	fmt.Printf("\nNew alias:\n")
-	fmt.Printf("  %s    # Jump to project (after reloading shell)\n", newName)
+	fmt.Printf("  %s    # Jump to project (after reloading shell)\n", newID)
 }
 
-func updateProjectTomlRename(path, newName, newPath string) error {
-	// Read current TOML
-	var project config.Project
-	if _, err := toml.DecodeFile(path, &project); err != nil {
-		return err
+// renameProjectID returns the ID for a project being renamed to newName:
+// --id, the name itself if it is a valid ID, or one derived from it. It
+// exits if another project already has that ID.
+func renameProjectID(newName string, found *config.Project, projects []*config.Project) string {
+	rules := loadSettings().IDs
+
+	id := renameID
+	switch {
+	case id != "":
+		if !slug.Valid(id, rules) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid ID '%s' (want e.g. '%s')\n", id, slug.Make(id, "", rules))
+			os.Exit(1)
+		}
+	case slug.Valid(newName, rules):
+		id = newName
+	default:
+		id = slug.Make(newName, "", rules)
+		if id == "" {
+			fmt.Fprintf(os.Stderr, "Error: Can't derive an ID from '%s'; set one with --id\n", newName)
+			os.Exit(1)
+		}
 	}
 
-	// Update fields
-	project.Path = newPath
-	project.ProjectInfo.Name = newName
-	project.ProjectInfo.ID = newName
+	for _, p := range projects {
+		if p != found && p.ProjectInfo.ID == id {
+			fmt.Fprintf(os.Stderr, "Error: Project ID '%s' is already in use (%s)\n", id, p.Path)
+			os.Exit(1)
+		}
+	}
+	return id
+}
+
+// renameProjectSession renames the project's running session to match its
+// new ID. Failing to is only a warning.
+func renameProjectSession(oldID, newID string) {
+	oldSession := session.SanitizeSessionName(oldID)
+	newSession := session.SanitizeSessionName(newID)
+	if !session.SessionExists(oldSession) {
+		return
+	}
+	if session.SessionExists(newSession) {
+		fmt.Fprintf(os.Stderr, "Warning: Session '%s' already exists; left '%s' as it is\n", newSession, oldSession)
+		return
+	}
+	if err := session.RenameSession(oldSession, newSession); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to rename session '%s': %v\n", oldSession, err)
+		return
+	}
+	fmt.Printf("\033[32m✓\033[0m Session renamed: %s → %s\n", oldSession, newSession)
+}
 
-	// Write back
-	f, err := os.Create(path)
+func updateProjectTomlRename(path, newName, newID, newPath string) error {
+	// Read current TOML
+	project, err := config.LoadProject(path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	// Write header comment
-	fmt.Fprintln(f, "# Project Metadata")
-	fmt.Fprintln(f, "")
+	// Update fields
+	project.Path = newPath
+	project.ProjectInfo.Name = newName
+	project.ProjectInfo.ID = newID
 
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(&project)
+	return project.Save()
 }
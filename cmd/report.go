@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportClient        string
+	reportWeekly        bool
+	reportSince         string
+	reportTemplate      string
+	reportPrintTemplate bool
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Status reports built from project activity",
+	Long: `Build status reports from what pk records about your projects.
+
+Subcommands:
+  pk report markdown   # Per-client status in Markdown, e.g. for a weekly email`,
+}
+
+var reportMarkdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Per-client status report in Markdown",
+	Long: `Write a Markdown status report for every project of a client: roadmap
+milestones ticked off, commits, time tracked and journal notes over the
+period.
+
+  Milestones  Task list items checked in the project's roadmap (dev.roadmap)
+              in commits during the period
+  Commits     Non-merge commit subjects in the period
+  Time        Time attached to the project's session, from the tmux hooks
+              'pk install --tmux-bindings' adds (see 'pk export activity')
+  Notes       'pk annotate' entries from the period
+
+--weekly covers this week so far, from Monday; otherwise --since (default
+7d) takes a duration or a date. Archived projects appear only if something
+happened in them; mirrors (mirror = true) never do.
+
+The layout is a Go template. Put your own in ~/.config/pk/reports/weekly.md
+(or pass --template) to match what you already send; --print-template shows
+the built-in one to start from. The template sees .Client, .From, .To, .Time
+and .Projects, each with .Project, .Milestones, .Commits, .Time, .Notes and
+.Active; date, hours, first, sub and join are available.
+
+Example:
+  pk report markdown --client Acme --weekly
+  pk report markdown --client Acme --since 2026-10-01 > acme.md
+  pk report markdown --print-template > ~/.config/pk/reports/weekly.md`,
+	Args: cobra.NoArgs,
+	Run:  runReportMarkdown,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportMarkdownCmd)
+	reportMarkdownCmd.Flags().StringVar(&reportClient, "client", "", "Client whose projects to report on (consultant.client_name)")
+	reportMarkdownCmd.Flags().BoolVar(&reportWeekly, "weekly", false, "Cover this week so far, from Monday")
+	reportMarkdownCmd.Flags().StringVar(&reportSince, "since", "7d", "Start of the period: duration (7d, 36h) or date (2006-01-02)")
+	reportMarkdownCmd.Flags().StringVar(&reportTemplate, "template", "", "Template file (default: ~/.config/pk/reports/weekly.md if it exists)")
+	reportMarkdownCmd.Flags().BoolVar(&reportPrintTemplate, "print-template", false, "Print the built-in template and exit")
+	reportMarkdownCmd.RegisterFlagCompletionFunc("client", validClientNames)
+}
+
+func runReportMarkdown(cmd *cobra.Command, args []string) {
+	if reportPrintTemplate {
+		fmt.Println(report.DefaultTemplate)
+		return
+	}
+	if reportClient == "" {
+		fmt.Fprintf(os.Stderr, "Error: --client is required\n")
+		os.Exit(1)
+	}
+	if reportWeekly && cmd.Flags().Changed("since") {
+		fmt.Fprintf(os.Stderr, "Error: Use either --weekly or --since\n")
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	from := startOfWeek(now)
+	if !reportWeekly {
+		var err error
+		if from, err = parseSince(reportSince, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	layout := loadReportTemplate()
+
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+	var clientProjects []*config.Project
+	client := reportClient
+	for _, p := range projects {
+		// Mirrors are reference code, not work billed to the client
+		if strings.EqualFold(p.GetClientName(), reportClient) && !p.ProjectInfo.Mirror {
+			clientProjects = append(clientProjects, p)
+			client = p.GetClientName() // As the projects spell it
+		}
+	}
+	if len(clientProjects) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: No projects for client '%s'\n", reportClient)
+		if names := clientNames(projects); len(names) > 0 {
+			fmt.Fprintf(os.Stderr, "Clients: %s\n", strings.Join(names, ", "))
+		}
+		os.Exit(1)
+	}
+
+	log, err := events.ReadSince(from.Add(-activityLookback), "session")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to read events, time won't be reported: %v\n", err)
+	}
+	status := report.Collect(client, clientProjects, events.Intervals(log, from, now), from, now)
+
+	if err := report.Render(os.Stdout, layout, status); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// startOfWeek returns midnight on the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// loadReportTemplate reads --template, the user's weekly.md, or falls back
+// to the built-in layout
+func loadReportTemplate() string {
+	path := reportTemplate
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return report.DefaultTemplate
+		}
+		path = filepath.Join(homeDir, ".config", "pk", "reports", "weekly.md")
+		if _, err := os.Stat(path); err != nil {
+			return report.DefaultTemplate
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read template: %v\n", err)
+		os.Exit(1)
+	}
+	return string(data)
+}
+
+// clientNames returns the distinct client names among projects, sorted
+func clientNames(projects []*config.Project) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range projects {
+		if name := p.GetClientName(); name != "" && !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validClientNames completes --client from the clients of known projects
+func validClientNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	projects, _ := cache.FindProjectsCached(cacheRoots()...)
+	return filterPrefix(clientNames(projects), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/hooks"
+	"github.com/datakaicr/pk/pkg/trash"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restorePurge     bool
+	restoreOlderThan time.Duration
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [name]",
+	Short: "Bring back a project from the trash",
+	Long: `Move a project deleted with 'pk delete' back where it was, with its
+sidecar metadata if it had one. If the same project was deleted more than
+once, the most recent copy comes back. Without a name, list the trash.
+
+--purge deletes from the trash for good: the named project, or everything
+(deleted more than --older-than ago, if given).
+
+Example:
+  pk restore                       # What's in the trash
+  pk restore old-project
+  pk restore old-project --purge
+  pk restore --purge --older-than 720h`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runRestore,
+	ValidArgsFunction: validTrashNames,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().BoolVar(&restorePurge, "purge", false,
+		"Delete from the trash permanently instead of restoring")
+	restoreCmd.Flags().DurationVar(&restoreOlderThan, "older-than", 0,
+		"With --purge and no name, only entries deleted longer ago than this")
+}
+
+func runRestore(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		if restorePurge {
+			purgeTrash()
+		} else {
+			listTrash()
+		}
+		return
+	}
+
+	entry, ok, err := trash.Find(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read the trash: %v\n", err)
+		os.Exit(1)
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: '%s' is not in the trash\n", args[0])
+		fmt.Fprintf(os.Stderr, "\nUse 'pk restore' to see what is.\n")
+		os.Exit(1)
+	}
+
+	if restorePurge {
+		if err := trash.Purge(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\033[32m✓\033[0m Permanently deleted %s (was %s)\n", entry.ID, entry.Path)
+		return
+	}
+
+	if err := trash.Restore(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Can't restore '%s': %v\n", entry.ID, err)
+		os.Exit(1)
+	}
+	fmt.Printf("\033[32m✓\033[0m Restored %s to %s\n", entry.ID, entry.Path)
+	events.Emit(events.ProjectRestored, entry.ID, entry.Path, nil)
+
+	syncScopes(syncAliases)
+	cache.InvalidateCache()
+	hooks.InvalidateCache()
+}
+
+// listTrash prints the projects in the trash, most recently deleted first
+func listTrash() {
+	entries, err := trash.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read the trash: %v\n", err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Println("The trash is empty")
+		return
+	}
+
+	for _, e := range entries {
+		fmt.Printf("\033[34m%-24s\033[0m %s  %s\n", e.ID, e.DeletedAt.Format("2006-01-02 15:04"), e.Path)
+	}
+	fmt.Printf("\nTotal: %d in the trash (restore with 'pk restore <name>')\n", len(entries))
+}
+
+// purgeTrash permanently deletes the trash, or the entries older than
+// --older-than
+func purgeTrash() {
+	entries, err := trash.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read the trash: %v\n", err)
+		os.Exit(1)
+	}
+
+	purged := 0
+	for _, e := range entries {
+		if restoreOlderThan > 0 && time.Since(e.DeletedAt) < restoreOlderThan {
+			continue
+		}
+		if err := trash.Purge(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to purge %s: %v\n", e.Dir, err)
+			continue
+		}
+		purged++
+	}
+	fmt.Printf("\033[32m✓\033[0m Permanently deleted %d of %d project(s) in the trash\n", purged, len(entries))
+}
+
+// validTrashNames completes the IDs of projects in the trash
+func validTrashNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries, _ := trash.List()
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.ID)
+	}
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -3,7 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/output"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/datakaicr/pk/pkg/settings"
 	"github.com/spf13/cobra"
 )
 
@@ -50,16 +55,48 @@ Examples:
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	if len(os.Args) > 1 && strings.HasPrefix(os.Args[1], "__complete") {
+		// Completion output is read by the shell
+		progress.Quiet = true
+	}
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 func init() {
+	// Execute prints errors itself; cobra would print them a second time
+	rootCmd.SilenceErrors = true
+
+	cobra.OnInitialize(applyScanSettings)
+
 	// Global flags (available to all commands)
+	rootCmd.PersistentFlags().BoolVarP(&progress.Quiet, "quiet", "q", false, "Don't show progress for long operations")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", output.FormatTable, "Output format for list, show, recent, sessions, stats, cache status, lookup, git status and version: table, json or yaml")
+	rootCmd.PersistentFlags().BoolVar(&outputJSON, "json", false, "Shorthand for --format json")
+	rootCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(output.Formats, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return checkOutputFormat(cmd)
+	}
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pk.yaml)")
 
 	// Local flags (only for this command)
 	// rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// applyScanSettings passes [scan] settings to project discovery
+func applyScanSettings() {
+	s, err := settings.Load()
+	if err != nil {
+		return
+	}
+	config.Scan = config.ScanOptions{
+		Parallelism: s.Scan.Parallelism,
+		Ignore:      s.Scan.Ignore,
+		Nested:      s.Scan.Nested,
+		MaxDepth:    s.Scan.MaxDepth,
+	}
+}
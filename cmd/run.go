@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var runProject string
+
+var runCmd = &cobra.Command{
+	Use:   "run [command]",
+	Short: "Run a named command from the project's kind",
+	Long: `Run one of the commands the project's kind defines (see 'pk kind show'),
+from the project root and with the project's session environment.
+Metadata references in the command, such as {{.Context.AWSProfile}} or
+{{.Consultant.ClientName}}, are filled in from the project first, quoted for
+the shell where needed; other tools' templates are left alone.
+
+Without a command, lists the commands available.
+
+Example:
+  pk run                   # List commands for the current project
+  pk run test              # e.g. 'dbt test' in a dbt project
+  pk run plan -p infra     # Run in another project`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runRun,
+	ValidArgsFunction: validRunCommands,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringVarP(&runProject, "project", "p", "", "Project to run in (default: current directory)")
+	runCmd.RegisterFlagCompletionFunc("project", validProjectNames)
+}
+
+func runRun(cmd *cobra.Command, args []string) {
+	project := currentOrNamedProject(runProject)
+
+	s := loadSettings()
+	kind, exists := s.KindFor(project)
+	if project.ProjectInfo.Kind == "" {
+		fmt.Fprintf(os.Stderr, "Error: '%s' has no kind; set [project] kind (see 'pk kind list')\n", project.ProjectInfo.ID)
+		os.Exit(1)
+	}
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Error: Unknown kind '%s' (see 'pk kind list')\n", project.ProjectInfo.Kind)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 {
+		fmt.Printf("Commands for %s (%s):\n", project.ProjectInfo.ID, project.ProjectInfo.Kind)
+		for _, name := range settings.SortedKeys(kind.Commands) {
+			fmt.Printf("  %-12s %s\n", name, kind.Commands[name])
+		}
+		return
+	}
+
+	command, exists := kind.Commands[args[0]]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Error: Kind '%s' has no command '%s' (available: %s)\n",
+			project.ProjectInfo.Kind, args[0], strings.Join(settings.SortedKeys(kind.Commands), ", "))
+		os.Exit(1)
+	}
+
+	command, err := project.Expand(command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Command '%s': %v\n", args[0], err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\033[2m$ %s\033[0m\n", command)
+
+	shellCmd := runner.Command("sh", "-c", command)
+	shellCmd.Dir = project.Path
+	shellCmd.Stdin = os.Stdin
+	shellCmd.Stdout = os.Stdout
+	shellCmd.Stderr = os.Stderr
+	shellCmd.Env = os.Environ()
+	for key, value := range pkcontext.Env(project) {
+		shellCmd.Env = append(shellCmd.Env, key+"="+value)
+	}
+
+	if err := runner.Run(shellCmd); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// currentOrNamedProject finds a project by ID/name, or the project containing
+// the current directory when name is empty
+func currentOrNamedProject(name string) *config.Project {
+	if name == "" {
+		cwd, _ := os.Getwd()
+		file := config.FindProjectFile(cwd)
+		if file == "" {
+			fmt.Fprintf(os.Stderr, "Error: Not inside a project (no .project.toml found); use --project\n")
+			os.Exit(1)
+		}
+		project, err := config.LoadProject(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to load %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		return project
+	}
+
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	lower := strings.ToLower(name)
+	for _, p := range projects {
+		if strings.ToLower(p.ProjectInfo.ID) == lower || strings.ToLower(p.ProjectInfo.Name) == lower {
+			// Reload so edits since the last cache refresh count
+			if fresh, err := config.LoadProject(p.File()); err == nil {
+				return fresh
+			}
+			return p
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", name)
+	os.Exit(1)
+	return nil
+}
+
+func validRunCommands(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cwd, _ := os.Getwd()
+	file := config.FindProjectFile(cwd)
+	if file == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	project, err := config.LoadProject(file)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	s, _ := settings.Load()
+	kind, _ := s.KindFor(project)
+	return filterPrefix(settings.SortedKeys(kind.Commands), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
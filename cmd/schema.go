@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var schemaOutput string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema for .project.toml",
+	Long: `Print a JSON Schema describing .project.toml (core fields plus the
+consultant and datakai extensions), generated from pk's own structs.
+
+Point taplo or the Even Better TOML extension at it for completion and
+validation while editing. Either add a directive to the top of a file:
+
+  #:schema /path/to/project.schema.json
+
+or map it for every project in .taplo.toml:
+
+  [[rule]]
+  include = ["**/.project.toml"]
+  schema.path = "/path/to/project.schema.json"
+
+Example:
+  pk schema                                        # Print to stdout
+  pk schema -o ~/.config/pk/project.schema.json    # Write to a file`,
+	Args: cobra.NoArgs,
+	Run:  runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "Write the schema to a file instead of stdout")
+}
+
+func runSchema(cmd *cobra.Command, args []string) {
+	data, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to generate schema: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if schemaOutput == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := os.WriteFile(schemaOutput, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\033[32m✓\033[0m Wrote schema to %s\n", schemaOutput)
+}
@@ -3,10 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/datakaicr/pk/pkg/runner"
 	"github.com/datakaicr/pk/pkg/session"
 	"github.com/spf13/cobra"
 )
@@ -97,14 +97,7 @@ func runScratchNew(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	scratchPath := filepath.Join(homeDir, "scratch", projectName)
+	scratchPath := filepath.Join(projectPaths().Scratch(), projectName)
 
 	// Check if already exists
 	if _, err := os.Stat(scratchPath); err == nil {
@@ -122,9 +115,9 @@ func runScratchNew(cmd *cobra.Command, args []string) {
 
 	// Initialize git repository
 	if !scratchNoGit {
-		gitCmd := exec.Command("git", "init")
+		gitCmd := runner.Command("git", "init")
 		gitCmd.Dir = scratchPath
-		if err := gitCmd.Run(); err != nil {
+		if err := runner.Run(gitCmd); err != nil {
 			fmt.Printf("Warning: git init failed: %v\n", err)
 		} else {
 			fmt.Println("Initialized git repository")
@@ -150,13 +143,7 @@ func runScratchNew(cmd *cobra.Command, args []string) {
 func runScratchDelete(cmd *cobra.Command, args []string) {
 	projectName := args[0]
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	scratchPath := filepath.Join(homeDir, "scratch", projectName)
+	scratchPath := filepath.Join(projectPaths().Scratch(), projectName)
 
 	// Check if exists
 	if _, err := os.Stat(scratchPath); os.IsNotExist(err) {
@@ -223,13 +210,7 @@ func runScratchDelete(cmd *cobra.Command, args []string) {
 }
 
 func runScratchList(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	scratchDir := filepath.Join(homeDir, "scratch")
+	scratchDir := projectPaths().Scratch()
 
 	// Check if scratch directory exists
 	if _, err := os.Stat(scratchDir); os.IsNotExist(err) {
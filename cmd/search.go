@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchLimit    int
+	searchArchived bool
+)
+
+// searchHighlight marks matched text in search results
+const searchHighlight = "\033[1;33m"
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search project metadata",
+	Long: `Search the name, ID, client, tech stack, domain, kind, type, partner and
+description of every project, from the project cache so it's instant.
+
+Every word of the query must match somewhere, case-insensitively. Results
+are ranked: a match in the name or ID counts most, then client, stack and
+domain, then the description; a whole-value match beats the start of a
+word, which beats the middle of one. Matches are highlighted.
+
+Archived projects are left out unless --archived is given.
+
+Example:
+  pk search duckdb
+  pk search acme etl
+  pk search lineage --archived`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Number of results to show (0 for all)")
+	searchCmd.Flags().BoolVarP(&searchArchived, "archived", "a", false, "Include archived projects")
+}
+
+func runSearch(cmd *cobra.Command, args []string) {
+	query := strings.Join(args, " ")
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !searchArchived {
+		var live []*config.Project
+		for _, p := range projects {
+			if p.ProjectInfo.Status != "archived" {
+				live = append(live, p)
+			}
+		}
+		projects = live
+	}
+
+	results := config.Search(projects, query)
+	if len(results) == 0 {
+		fmt.Printf("No projects match '%s'\n", query)
+		if !searchArchived {
+			fmt.Println("\nTip: --archived searches archived projects too")
+		}
+		return
+	}
+
+	terms := config.SearchTerms(query)
+	shown := results
+	if searchLimit > 0 && len(shown) > searchLimit {
+		shown = shown[:searchLimit]
+	}
+	for _, r := range shown {
+		printSearchResult(r, terms)
+	}
+
+	if len(shown) < len(results) {
+		fmt.Printf("Showing %d of %d matches (--limit 0 for all)\n", len(shown), len(results))
+	} else {
+		fmt.Printf("%d match(es)\n", len(results))
+	}
+}
+
+// printSearchResult prints a project with its matching fields highlighted
+func printSearchResult(r config.SearchResult, terms []string) {
+	p := r.Project
+	fmt.Printf("\033[34m%s\033[0m  %s  %s%s\033[0m\n",
+		config.Highlight(p.ProjectInfo.ID, terms, searchHighlight, "\033[0m\033[34m"),
+		config.Highlight(p.ProjectInfo.Name, terms, searchHighlight, "\033[0m"),
+		getStatusColor(p.ProjectInfo.Status), p.ProjectInfo.Status)
+	for _, m := range r.Matches {
+		if m.Key == "project.id" || m.Key == "project.name" {
+			continue
+		}
+		fmt.Printf("  %s: %s\n", m.Key, config.Highlight(m.Value, terms, searchHighlight, "\033[0m"))
+	}
+	fmt.Println()
+}
@@ -3,28 +3,57 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
 	"github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/picker"
+	"github.com/datakaicr/pk/pkg/progress"
 	"github.com/datakaicr/pk/pkg/session"
 	"github.com/spf13/cobra"
 )
 
+var (
+	sessionDetached bool
+	sessionFilter   []string
+	sessionAll      bool
+)
+
 var sessionCmd = &cobra.Command{
-	Use:   "session [project]",
+	Use:   "session [project...]",
 	Short: "Open project in tmux session (requires tmux)",
 	Long: `Open a project in a tmux session with optional custom layouts.
 
-If no project is specified, displays an interactive fzf selector.
+If no project is specified, displays an interactive selector: fzf when it's
+installed, otherwise pk's built-in fuzzy picker.
 If a project name is provided, opens that project directly.
 
+Several projects can be opened at once. Their sessions are created one at a
+time in the background; a project that fails is reported in the summary at
+the end rather than stopping the rest. pk then switches to the first session
+that opened.
+
+--filter narrows the selector to projects matching key=value terms (client,
+owner, status, type, kind, stack, domain, visibility, or any key from 'pk
+config list --keys'); values are case-insensitive globs. With --all, every
+match is opened instead, most frecently used first, and pk switches to the
+first: clocking in to an engagement in one command. Archived projects are
+left out unless the filter names a status.
+
+Projects with 'attach = false' in their [tmux] section get their session
+created (or left running) in the background, without attaching.
+
+--detached does the same for any project: the session is created with its
+layout and session environment, and on_create hooks run, but pk neither
+attaches nor switches to it, nor switches cloud or git context, nor counts it
+as a visit. Use it from scripts and startup hooks to pre-warm sessions.
+
 Requires:
   - tmux: brew install tmux (macOS) or apt install tmux (Linux)
-  - fzf: brew install fzf (macOS) or apt install fzf (Linux)
+  - fzf (optional, adds previews): brew install fzf (macOS) or apt install fzf (Linux)
 
 Custom layouts can be configured in .project.toml:
 
@@ -36,11 +65,19 @@ windows = [
     {name = "server", command = "npm run dev"}
 ]
 
+Use --popup when running inside 'tmux display-popup': the picker fills the popup
+and pk always switches the current client instead of attaching.
+
 Example:
   pk session              # Interactive selector
-  pk session dojo         # Open dojo project directly`,
+  pk session dojo         # Open dojo project directly
+  pk session dojo conduit # Open both, then switch to dojo
+  pk session --popup      # Selector tuned for tmux display-popup
+  pk session dojo conduit --detached  # Pre-warm both in the background
+  pk session --filter client=Acme --all   # Open every Acme project
+  pk session --filter 'stack=dbt,status=active' --all --detached`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		return session.CheckTmux()
+		return requireTools(cmd, session.Check())
 	},
 	Run:               runSession,
 	ValidArgsFunction: validAllProjectNames,
@@ -48,22 +85,117 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.Flags().BoolVar(&popupMode, "popup", false,
+		"Tune picker for tmux display-popup (fill popup, always switch-client)")
+	sessionCmd.Flags().BoolVarP(&sessionDetached, "detached", "d", false,
+		"Create the session in the background without attaching or switching")
+	sessionCmd.Flags().StringArrayVar(&sessionFilter, "filter", nil,
+		"Only projects matching key=value terms, e.g. client=Acme (repeatable)")
+	sessionCmd.Flags().BoolVar(&sessionAll, "all", false,
+		"Open every project matching --filter instead of choosing one")
+	sessionCmd.RegisterFlagCompletionFunc("filter", validFilterKeys)
+}
+
+// detach makes opening the project leave its session in the background,
+// as [tmux] attach = false does
+func detach(project *config.Project) {
+	attach := false
+	project.Tmux.Attach = &attach
 }
 
 func runSession(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
+	applyPopupMode()
+
+	var selectedProject *config.Project
+	switch {
+	case len(sessionFilter) > 0 || sessionAll:
+		projects := filteredOpenCandidates(args)
+		if sessionAll {
+			openSessionBatch(projects, nil)
+			return
+		}
+		selectedProject = selectProject(projects)
+	case len(args) > 1:
+		runSessionBatch(args)
+		return
+	default:
+		selectedProject = resolveOpenTarget(args)
+	}
+	if selectedProject == nil {
+		// User cancelled
+		return
+	}
+
+	if sessionDetached {
+		detach(selectedProject)
+	} else {
+		// Record project access
+		cache.RecordAccess(selectedProject.ProjectInfo.ID, selectedProject.Path)
+
+		// Switch context if configured
+		context.Switch(selectedProject)
+	}
+
+	// Create or switch to session
+	if err := session.CreateSession(selectedProject); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create session: %v\n", err)
 		os.Exit(1)
 	}
+	reportDetached(selectedProject)
+}
 
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scriptoriumDir := filepath.Join(homeDir, "scriptorium")
-	scratchDir := filepath.Join(homeDir, "scratch")
+// reportDetached tells the user where a session opened without attaching went
+func reportDetached(project *config.Project) {
+	if project.TmuxAttach() {
+		return
+	}
+	name := session.SanitizeSessionName(project.ProjectInfo.ID)
+	if sessionDetached {
+		fmt.Printf("\033[32m✓\033[0m Session '%s' is running in the background\n", name)
+	} else {
+		fmt.Printf("\033[32m✓\033[0m Session '%s' is running in the background ([tmux] attach = false)\n", name)
+	}
+	fmt.Printf("  Attach with: tmux attach -t %s\n", name)
+}
+
+// resolveOpenTarget finds the project named in args (including scratch),
+// or shows the picker when no name is given. Returns nil on cancel.
+func resolveOpenTarget(args []string) *config.Project {
+	allProjects := openCandidates()
+
+	// Interactive selection
+	if len(args) == 0 {
+		return selectProject(allProjects)
+	}
+
+	if p := findOpenTarget(allProjects, args[0]); p != nil {
+		return p
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", args[0])
+	os.Exit(1)
+	return nil
+}
+
+// findOpenTarget matches a project by ID or name, case-insensitively
+func findOpenTarget(projects []*config.Project, name string) *config.Project {
+	projectName := strings.ToLower(name)
+	for _, p := range projects {
+		if strings.ToLower(p.ProjectInfo.ID) == projectName ||
+			strings.ToLower(p.ProjectInfo.Name) == projectName {
+			return p
+		}
+	}
+	return nil
+}
+
+// openCandidates returns every project that can be opened, including scratch
+func openCandidates() []*config.Project {
+	resolver := projectPaths()
+	scratchDir := resolver.Scratch()
 
 	// Find all projects (uses cache if available)
-	projects, err := cache.FindProjectsCached(projectsDir, archiveDir, scriptoriumDir)
+	projects, err := cache.FindProjectsCached(resolver.AllRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
 		os.Exit(1)
@@ -77,47 +209,133 @@ func runSession(cmd *cobra.Command, args []string) {
 	}
 
 	// Combine projects and scratch
-	allProjects := append(projects, scratchProjects...)
-
-	var selectedProject *config.Project
+	return append(projects, scratchProjects...)
+}
 
-	// If project name provided, find it directly
+// filteredOpenCandidates returns the projects --filter selects, most
+// frecently used first. Archived projects count only if the filter names a
+// status.
+func filteredOpenCandidates(args []string) []*config.Project {
 	if len(args) > 0 {
-		projectName := strings.ToLower(args[0])
-		for _, p := range allProjects {
-			if strings.ToLower(p.ProjectInfo.ID) == projectName ||
-				strings.ToLower(p.ProjectInfo.Name) == projectName {
-				selectedProject = p
-				break
-			}
+		fmt.Fprintf(os.Stderr, "Error: Give either project names or --filter, not both\n")
+		os.Exit(1)
+	}
+	if len(sessionFilter) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --all needs --filter, e.g. --filter client=Acme\n")
+		os.Exit(1)
+	}
+	filter, err := config.ParseFilter(sessionFilter...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var projects []*config.Project
+	for _, p := range openCandidates() {
+		if p.ProjectInfo.Status == "archived" && !filter.Has("status") {
+			continue
 		}
+		if filter.Matches(p) {
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: No projects match %s\n", strings.Join(sessionFilter, ", "))
+		os.Exit(1)
+	}
 
-		if selectedProject == nil {
-			fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", args[0])
-			os.Exit(1)
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ProjectInfo.ID < projects[j].ProjectInfo.ID })
+	records, _ := cache.LoadAccessRecords()
+	cache.SortByFrecency(projects, records)
+	return projects
+}
+
+// runSessionBatch opens a session for each named project
+func runSessionBatch(names []string) {
+	allProjects := openCandidates()
+
+	var projects []*config.Project
+	var missing []string
+	for _, name := range names {
+		if p := findOpenTarget(allProjects, name); p != nil {
+			projects = append(projects, p)
+		} else {
+			missing = append(missing, name)
 		}
-	} else {
-		// Interactive selection with fzf
-		selectedProject = selectProjectWithFzf(allProjects)
-		if selectedProject == nil {
-			// User cancelled
-			return
+	}
+	openSessionBatch(projects, missing)
+}
+
+// openSessionBatch opens a session for each project in order and switches
+// to the first, reporting per-project failures (and names that weren't
+// found) at the end instead of stopping at the first one
+func openSessionBatch(projects []*config.Project, missing []string) {
+	if sessionDetached {
+		for _, p := range projects {
+			detach(p)
 		}
 	}
 
-	// Record project access
-	cache.RecordAccess(selectedProject.ProjectInfo.ID, selectedProject.Path)
+	task := progress.Counter("Opening", len(projects)).Logged()
+	results := session.OpenAll(projects, func(done, total int, p *config.Project) {
+		task.Next(p.ProjectInfo.ID)
+	})
+	task.Stop()
 
-	// Switch context if configured
-	context.Switch(selectedProject)
+	failed := printSessionResults(results, missing, "Opened")
 
-	// Create or switch to session
-	if err := session.CreateSession(selectedProject); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create session: %v\n", err)
+	var first *config.Project
+	for _, r := range results {
+		if r.Err != nil || sessionDetached {
+			continue
+		}
+		cache.RecordAccess(r.Project.ProjectInfo.ID, r.Project.Path)
+		if first == nil && r.Project.TmuxAttach() {
+			first = r.Project
+		}
+	}
+
+	if first != nil {
+		context.Switch(first)
+		if err := session.SwitchSession(session.SanitizeSessionName(first.ProjectInfo.ID)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to switch to %s: %v\n", first.ProjectInfo.ID, err)
+			os.Exit(1)
+		}
+	}
+
+	if failed > 0 {
 		os.Exit(1)
 	}
 }
 
+// printSessionResults prints one line per project opened in a batch and a
+// total, returning how many failed (including names that weren't found)
+func printSessionResults(results []session.Result, missing []string, verb string) int {
+	fmt.Println()
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			fmt.Printf("  \033[31m✗\033[0m %-24s %v\n", r.Project.ProjectInfo.ID, r.Err)
+		case r.Created:
+			fmt.Printf("  \033[32m✓\033[0m %-24s created\n", r.Project.ProjectInfo.ID)
+		default:
+			fmt.Printf("  \033[32m✓\033[0m %-24s already running\n", r.Project.ProjectInfo.ID)
+		}
+	}
+	for _, name := range missing {
+		fmt.Printf("  \033[31m✗\033[0m %-24s not found\n", name)
+	}
+
+	total := len(results) + len(missing)
+	failed := len(session.Failed(results)) + len(missing)
+	fmt.Printf("\n%s %d of %d session(s)", verb, total-failed, total)
+	if failed > 0 {
+		fmt.Printf(", \033[31m%d failed\033[0m", failed)
+	}
+	fmt.Println()
+	return failed
+}
+
 // findScratchProjects finds directories in scratch (no .project.toml required)
 func findScratchProjects(scratchDir string) ([]*config.Project, error) {
 	var projects []*config.Project
@@ -154,15 +372,33 @@ func findScratchProjects(scratchDir string) ([]*config.Project, error) {
 	return projects, nil
 }
 
-func selectProjectWithFzf(projects []*config.Project) *config.Project {
-	// Check if fzf is installed
-	if _, err := exec.LookPath("fzf"); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: fzf is required for interactive selection\n")
-		fmt.Fprintf(os.Stderr, "Install: brew install fzf (macOS) or apt install fzf (Linux)\n")
-		fmt.Fprintf(os.Stderr, "\nAlternatively, specify a project: pk session <name>\n")
-		os.Exit(1)
+func selectProject(projects []*config.Project) *config.Project {
+	lines, projectMap := projectPickerInput(projects)
+
+	selection, ok := pick(lines, picker.Options{
+		Prompt: "⚡ Project: ",
+		Header: "● = Active Session | * = Uncommitted changes",
+		FzfArgs: append(append(fzfLayoutArgs(),
+			"--ansi",
+			"--tabstop=40",
+		), projectPreviewArgs()...),
+	}, "specify a project: pk session <name>")
+	if !ok {
+		return nil
 	}
 
+	// Get first column (project ID)
+	projectID := strings.Fields(selection)[0]
+	return projectMap[projectID]
+}
+
+// projectPickerInput builds the picker lines for the project picker, most
+// frecently used projects first
+func projectPickerInput(projects []*config.Project) ([]string, map[string]*config.Project) {
+	records, _ := cache.LoadAccessRecords()
+	projects = append([]*config.Project(nil), projects...)
+	cache.SortByFrecency(projects, records)
+
 	// Get list of existing sessions
 	existingSessions, _ := session.ListSessions()
 	sessionSet := make(map[string]bool)
@@ -170,12 +406,14 @@ func selectProjectWithFzf(projects []*config.Project) *config.Project {
 		sessionSet[s] = true
 	}
 
-	// Build fzf input
-	var builder strings.Builder
+	branches := branchMarkers(projects)
+
+	// Build picker lines
+	var lines []string
 	projectMap := make(map[string]*config.Project)
 
 	for _, p := range projects {
-		// Format: "project-id    [owner]    status    [session-indicator]"
+		// Format: "project-id    [owner]    status    branch    [session-indicator]"
 		owner := p.GetOwner()
 		if owner == "" {
 			owner = "none"
@@ -191,40 +429,9 @@ func selectProjectWithFzf(projects []*config.Project) *config.Project {
 			sessionIndicator = "●" // Indicates active session
 		}
 
-		line := fmt.Sprintf("%s\t[%s]\t%s\t%s\n", p.ProjectInfo.ID, owner, status, sessionIndicator)
-		builder.WriteString(line)
+		lines = append(lines, fmt.Sprintf("%s\t[%s]\t%s\t%s\t%s", p.ProjectInfo.ID, owner, status, branches[p.Path], sessionIndicator))
 		projectMap[p.ProjectInfo.ID] = p
 	}
 
-	// Run fzf
-	fzfCmd := exec.Command("fzf",
-		"--height", "60%",
-		"--reverse",
-		"--border",
-		"--ansi",
-		"--tabstop=40",
-		"--prompt", "⚡ Project: ",
-		"--preview", "echo 'Name: {1}\\nOwner: {2}\\nStatus: {3}\\nSession: {4}'",
-		"--preview-window", "right:30%:wrap",
-		"--header", "● = Active Session",
-	)
-
-	fzfCmd.Stdin = strings.NewReader(builder.String())
-	fzfCmd.Stderr = os.Stderr
-
-	output, err := fzfCmd.Output()
-	if err != nil {
-		// User cancelled or error
-		return nil
-	}
-
-	// Extract project ID from selection
-	selection := strings.TrimSpace(string(output))
-	if selection == "" {
-		return nil
-	}
-
-	// Get first column (project ID)
-	projectID := strings.Fields(selection)[0]
-	return projectMap[projectID]
+	return lines, projectMap
 }
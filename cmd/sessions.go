@@ -3,14 +3,17 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
 	"github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/importer"
+	"github.com/datakaicr/pk/pkg/picker"
 	"github.com/datakaicr/pk/pkg/session"
+	"github.com/datakaicr/pk/pkg/statefile"
 	"github.com/spf13/cobra"
 )
 
@@ -25,35 +28,99 @@ Unlike 'pk session' which shows ALL projects, 'pk sessions' only shows:
   - Perfect for quick switching between active work
 
 If a project name is provided, switches directly to that session.
-If no name is provided, shows an interactive fzf selector with active sessions only.
+If no name is provided, shows an interactive selector with active sessions only
+(fzf when it's installed, otherwise pk's built-in fuzzy picker).
 
 Bind this to Ctrl+b F (Shift+f) for fast access:
-  bind-key F run-shell "tmux display-popup -E -w 90% -h 80% 'pk sessions'"
+  bind-key F run-shell "tmux display-popup -E -w 90% -h 80% 'pk sessions --popup'"
+
+Or install the ready-made bindings with 'pk install --tmux-bindings'.
+
+With --windows, the picker lists every window inside the active sessions
+(session:index entries with the window's name), so you can jump straight to
+a project's "server" window instead of landing on whatever window was last
+active. Windows sharing a name stay apart.
 
 Examples:
-  pk sessions           # Interactive picker (active sessions only)
-  pk sessions pk        # Switch directly to 'pk' session`,
+  pk sessions                    # Interactive picker (active sessions only)
+  pk sessions pk                 # Switch directly to 'pk' session
+  pk sessions --windows          # Interactive picker of every window
+  pk sessions --windows pk:server  # Switch directly to window 'server' in 'pk'
+  pk sessions --json             # List active sessions for scripts
+  pk sessions save               # Snapshot running project sessions
+  pk sessions restore            # Recreate them after a reboot`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		return session.CheckTmux()
+		if sessionsWindows {
+			return requireTools(cmd, session.RequireTmux("'pk sessions --windows'"))
+		}
+		return requireTools(cmd, session.Check())
 	},
 	Run: runSessions,
 }
 
+var sessionsSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Snapshot the running project sessions",
+	Long: `Record which project sessions are running, with each window's name, pane
+layout and the working directory of every pane, so 'pk sessions restore'
+can bring them back after a reboot or a tmux server restart.
+
+Only sessions belonging to a project are saved. The snapshot goes to
+~/.cache/pk/sessions.json unless --file is given; saving replaces it.
+
+Example:
+  pk sessions save
+  pk sessions save --file ~/sessions-friday.json`,
+	Args: cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return requireTools(cmd, session.RequireTmux("'pk sessions save'"))
+	},
+	Run: runSessionsSave,
+}
+
+var sessionsRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Recreate the sessions from the last snapshot",
+	Long: `Recreate the project sessions recorded by 'pk sessions save', in the
+background: windows, panes, their working directories and layout, with the
+project's session environment. Programs that were running aren't restarted;
+panes open a shell in the saved directory (or the project directory if it
+is gone).
+
+Sessions that are already running are left alone, as are projects that no
+longer exist.
+
+Example:
+  pk sessions restore
+  pk sessions restore --file ~/sessions-friday.json`,
+	Args: cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return requireTools(cmd, session.RequireTmux("'pk sessions restore'"))
+	},
+	Run: runSessionsRestore,
+}
+
+var (
+	sessionsWindows bool
+	sessionsFile    string
+)
+
 func init() {
 	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsSaveCmd, sessionsRestoreCmd)
+	for _, c := range []*cobra.Command{sessionsSaveCmd, sessionsRestoreCmd} {
+		c.Flags().StringVar(&sessionsFile, "file", "", "Snapshot file (default ~/.cache/pk/sessions.json)")
+	}
+	sessionsCmd.Flags().BoolVarP(&sessionsWindows, "windows", "w", false,
+		"List windows within active sessions (session:window)")
+	sessionsCmd.Flags().BoolVar(&popupMode, "popup", false,
+		"Tune picker for tmux display-popup (fill popup, always switch-client)")
 }
 
 func runSessions(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not determine home directory: %v\n", err)
-		os.Exit(1)
-	}
+	applyPopupMode()
 
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scriptoriumDir := filepath.Join(homeDir, "scriptorium")
-	scratchDir := filepath.Join(homeDir, "scratch")
+	resolver := projectPaths()
 
 	// Get active tmux sessions
 	activeSessions, err := session.ListSessions()
@@ -62,7 +129,8 @@ func runSessions(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if len(activeSessions) == 0 {
+	format := structuredFormat(cmd)
+	if len(activeSessions) == 0 && format == "" {
 		fmt.Println("No active tmux sessions")
 		fmt.Println("\nStart a session with:")
 		fmt.Println("  pk session <project>")
@@ -70,14 +138,14 @@ func runSessions(cmd *cobra.Command, args []string) {
 	}
 
 	// Load all projects (from cache) to get metadata
-	allProjects, err := cache.FindProjectsCached(projectsDir, archiveDir, scriptoriumDir)
+	allProjects, err := cache.FindProjectsCached(resolver.AllRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to load project metadata: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Also load scratch projects
-	scratchProjects, _ := findScratchProjects(scratchDir)
+	scratchProjects, _ := findScratchProjects(resolver.Scratch())
 	allProjects = append(allProjects, scratchProjects...)
 
 	// Build map of active sessions to projects
@@ -103,6 +171,17 @@ func runSessions(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if format != "" {
+		printStructured(format, sessionsDocument(sessionProjects))
+		return
+	}
+
+	// Window-level picker
+	if sessionsWindows {
+		runSessionsWindows(sessionProjects, args)
+		return
+	}
+
 	// If project name provided, switch directly
 	if len(args) > 0 {
 		targetName := strings.ToLower(args[0])
@@ -140,8 +219,8 @@ func runSessions(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// Interactive selection with fzf
-	selectedProject := selectActiveSessionWithFzf(sessionProjects)
+	// Interactive selection
+	selectedProject := selectActiveSession(sessionProjects)
 	if selectedProject == nil {
 		// User cancelled
 		return
@@ -161,15 +240,46 @@ func runSessions(cmd *cobra.Command, args []string) {
 	}
 }
 
-func selectActiveSessionWithFzf(sessionProjects map[string]*config.Project) *config.Project {
-	// Check if fzf is installed
-	if _, err := exec.LookPath("fzf"); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: fzf is required for interactive selection\n")
-		fmt.Fprintf(os.Stderr, "Install: brew install fzf (macOS) or apt install fzf (Linux)\n")
-		fmt.Fprintf(os.Stderr, "\nAlternatively, specify a session: pk sessions <name>\n")
-		os.Exit(1)
+// sessionsDocument lists the active sessions as JSON and YAML output show
+// them, most frecently used first. Sessions without a project have no path.
+func sessionsDocument(sessionProjects map[string]*config.Project) any {
+	type activeSession struct {
+		Session string `json:"session"`
+		Project string `json:"project"`
+		Path    string `json:"path,omitempty"`
+		Status  string `json:"status"`
+		Pin     int    `json:"pin,omitempty"`
+	}
+
+	pins := make(map[string]int)
+	if list, err := cache.ListPins(); err == nil {
+		for _, pin := range list {
+			pins[pin.ProjectID] = pin.Slot
+		}
+	}
+
+	var ordered []*config.Project
+	for _, p := range sessionProjects {
+		ordered = append(ordered, p)
 	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ProjectInfo.ID < ordered[j].ProjectInfo.ID })
+	records, _ := cache.LoadAccessRecords()
+	cache.SortByFrecency(ordered, records)
+
+	sessions := []activeSession{}
+	for _, p := range ordered {
+		sessions = append(sessions, activeSession{
+			Session: session.SanitizeSessionName(p.ProjectInfo.ID),
+			Project: p.ProjectInfo.ID,
+			Path:    p.Path,
+			Status:  p.ProjectInfo.Status,
+			Pin:     pins[p.ProjectInfo.ID],
+		})
+	}
+	return sessions
+}
 
+func selectActiveSession(sessionProjects map[string]*config.Project) *config.Project {
 	// Load pins to show which projects are pinned
 	pins, _ := cache.ListPins()
 	pinMap := make(map[string]int)
@@ -177,11 +287,22 @@ func selectActiveSessionWithFzf(sessionProjects map[string]*config.Project) *con
 		pinMap[pin.ProjectID] = pin.Slot
 	}
 
-	// Build fzf input
-	var builder strings.Builder
+	// Most frecently used sessions first
+	var ordered []*config.Project
+	for _, p := range sessionProjects {
+		ordered = append(ordered, p)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ProjectInfo.ID < ordered[j].ProjectInfo.ID })
+	records, _ := cache.LoadAccessRecords()
+	cache.SortByFrecency(ordered, records)
+
+	branches := branchMarkers(ordered)
+
+	// Build picker lines
+	var lines []string
 	projectMap := make(map[string]*config.Project)
 
-	for _, p := range sessionProjects {
+	for _, p := range ordered {
 		owner := p.GetOwner()
 		if owner == "" {
 			owner = "none"
@@ -198,40 +319,24 @@ func selectActiveSessionWithFzf(sessionProjects map[string]*config.Project) *con
 			pinIndicator = fmt.Sprintf("[%d]", slot)
 		}
 
-		line := fmt.Sprintf("%s%s\t[%s]\t%s\t●\n",
+		lines = append(lines, fmt.Sprintf("%s%s\t[%s]\t%s\t%s\t●",
 			pinIndicator,
 			p.ProjectInfo.ID,
 			owner,
-			status)
-		builder.WriteString(line)
+			status,
+			branches[p.Path]))
 		projectMap[p.ProjectInfo.ID] = p
 	}
 
-	// Run fzf
-	fzfCmd := exec.Command("fzf",
-		"--height", "60%",
-		"--reverse",
-		"--border",
-		"--ansi",
-		"--tabstop=40",
-		"--prompt", "⚡ Active Session: ",
-		"--preview", "echo 'Name: {1}\\nOwner: {2}\\nStatus: {3}\\nSession: {4}'",
-		"--preview-window", "right:30%:wrap",
-		"--header", "Active tmux sessions only | [N] = Pinned slot",
-	)
-
-	fzfCmd.Stdin = strings.NewReader(builder.String())
-	fzfCmd.Stderr = os.Stderr
-
-	output, err := fzfCmd.Output()
-	if err != nil {
-		// User cancelled or error
-		return nil
-	}
-
-	// Extract project ID from selection
-	selection := strings.TrimSpace(string(output))
-	if selection == "" {
+	selection, ok := pick(lines, picker.Options{
+		Prompt: "⚡ Active Session: ",
+		Header: "Active tmux sessions only | [N] = Pinned slot | * = Uncommitted changes",
+		FzfArgs: append(append(fzfLayoutArgs(),
+			"--ansi",
+			"--tabstop=40",
+		), projectPreviewArgs()...),
+	}, "specify a session: pk sessions <name>")
+	if !ok {
 		return nil
 	}
 
@@ -250,3 +355,214 @@ func selectActiveSessionWithFzf(sessionProjects map[string]*config.Project) *con
 
 	return projectMap[projectID]
 }
+
+// runSessionsWindows switches to a specific window inside an active session
+func runSessionsWindows(sessionProjects map[string]*config.Project, args []string) {
+	windows, err := session.ListWindows()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list tmux windows: %v\n", err)
+		os.Exit(1)
+	}
+
+	var selected *session.Window
+	if len(args) > 0 {
+		selected = findWindow(windows, args[0])
+		if selected == nil {
+			fmt.Fprintf(os.Stderr, "Error: Window '%s' not found in active sessions\n", args[0])
+			fmt.Fprintf(os.Stderr, "\nActive windows:\n")
+			for _, w := range windows {
+				fmt.Fprintf(os.Stderr, "  - %s:%s\n", w.Session, w.Name)
+			}
+			os.Exit(1)
+		}
+	} else {
+		selected = selectActiveWindow(windows, sessionProjects)
+		if selected == nil {
+			// User cancelled
+			return
+		}
+	}
+
+	project := sessionProjects[selected.Session]
+	if project != nil {
+		// Record access
+		cache.RecordAccess(project.ProjectInfo.ID, project.Path)
+
+		// Switch context if configured
+		context.Switch(project)
+	}
+
+	// Switch to window (switch-client/attach accept session:window targets)
+	if err := session.SwitchSession(selected.Target()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to switch window: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// findWindow resolves "session:window" (window name or index) to a window.
+// A bare session name resolves to that session's active window.
+func findWindow(windows []session.Window, target string) *session.Window {
+	sessionName, windowName, hasWindow := strings.Cut(target, ":")
+	sessionName = session.SanitizeSessionName(strings.ToLower(sessionName))
+
+	for i, w := range windows {
+		if w.Session != sessionName {
+			continue
+		}
+
+		if !hasWindow {
+			if w.Active {
+				return &windows[i]
+			}
+			continue
+		}
+
+		if w.Name == windowName || strconv.Itoa(w.Index) == windowName {
+			return &windows[i]
+		}
+	}
+
+	return nil
+}
+
+func selectActiveWindow(windows []session.Window, sessionProjects map[string]*config.Project) *session.Window {
+	// Build picker lines
+	var lines []string
+	windowMap := make(map[string]*session.Window)
+
+	for i, w := range windows {
+		owner := "none"
+		if p, ok := sessionProjects[w.Session]; ok && p.GetOwner() != "" {
+			owner = p.GetOwner()
+		}
+
+		activeIndicator := ""
+		if w.Active {
+			activeIndicator = "●"
+		}
+
+		// Names may repeat within a session, so key on the session:index target
+		key := w.Target()
+		lines = append(lines, fmt.Sprintf("%s\t%s\t[%s]\t%s", key, w.Name, owner, activeIndicator))
+		windowMap[key] = &windows[i]
+	}
+
+	selection, ok := pick(lines, picker.Options{
+		Prompt: "⚡ Window: ",
+		Header: "Windows in active tmux sessions | ● = Active window",
+		FzfArgs: append(fzfLayoutArgs(),
+			"--ansi",
+			"--delimiter", "\t",
+			"--tabstop=40",
+			"--preview", "echo 'Window: {2}\\nTarget: {1}\\nOwner: {3}'",
+			"--preview-window", "right:30%:wrap",
+		),
+	}, "specify a window: pk sessions --windows <session:window>")
+	if !ok {
+		return nil
+	}
+
+	key := strings.SplitN(selection, "\t", 2)[0]
+	return windowMap[key]
+}
+
+// snapshotPath is --file, or the snapshot in the pk cache directory
+func snapshotPath() string {
+	if sessionsFile != "" {
+		return importer.ExpandHome(sessionsFile)
+	}
+	path, err := statefile.Path(session.SnapshotFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return path
+}
+
+// projectsBySession maps tmux session names to the projects they belong to
+func projectsBySession() map[string]*config.Project {
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+	bySession := make(map[string]*config.Project)
+	for _, p := range projects {
+		bySession[session.SanitizeSessionName(p.ProjectInfo.ID)] = p
+	}
+	return bySession
+}
+
+func runSessionsSave(cmd *cobra.Command, args []string) {
+	running, err := session.Capture()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projects := projectsBySession()
+	var saved []session.SavedSession
+	for _, s := range running {
+		p, ok := projects[s.Name]
+		if !ok {
+			continue
+		}
+		s.ProjectID, s.Path = p.ProjectInfo.ID, p.Path
+		saved = append(saved, s)
+	}
+	if len(saved) == 0 {
+		fmt.Println("No project sessions running; nothing saved")
+		return
+	}
+
+	path := snapshotPath()
+	if err := session.SaveSnapshot(path, saved); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	for _, s := range saved {
+		fmt.Printf("  %-25s %d window(s)\n", s.Name, len(s.Windows))
+	}
+	fmt.Printf("\n\033[32m✓\033[0m Saved %d session(s) to %s\n", len(saved), path)
+}
+
+func runSessionsRestore(cmd *cobra.Command, args []string) {
+	path := snapshotPath()
+	snapshot, err := session.LoadSnapshot(path)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: No snapshot at %s (save one with 'pk sessions save')\n", path)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projects := projectsBySession()
+	restored, failed := 0, 0
+	for _, s := range snapshot.Sessions {
+		switch p, ok := projects[s.Name]; {
+		case session.SessionExists(s.Name):
+			fmt.Printf("  \033[2m%-25s already running\033[0m\n", s.Name)
+		case !ok:
+			fmt.Printf("  \033[2m%-25s skipped: project no longer exists\033[0m\n", s.Name)
+		default:
+			s.ProjectID, s.Path = p.ProjectInfo.ID, p.Path
+			if err := session.Restore(s, context.Env(p)); err != nil {
+				fmt.Printf("  \033[31m✗\033[0m %-23s %v\n", s.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("  \033[32m✓\033[0m %-23s %d window(s)\n", s.Name, len(s.Windows))
+			restored++
+		}
+	}
+
+	fmt.Printf("\nRestored %d session(s) saved %s\n", restored, formatAccessTime(snapshot.SavedAt))
+	if restored > 0 {
+		fmt.Println("Switch to one with 'pk sessions'")
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -3,10 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/journal"
+	"github.com/datakaicr/pk/pkg/output"
+	"github.com/datakaicr/pk/pkg/settings"
 	"github.com/spf13/cobra"
 )
 
@@ -17,28 +19,40 @@ var showCmd = &cobra.Command{
 
 The project can be specified by its ID or name.
 
+Use --format for custom output (same templates as 'pk list --format'), or
+--format json|yaml (--json) for the project's fields and path.
+
+Use --audit to review metadata that clone, promote, or import detected
+automatically, least confident first; accept it with 'pk confirm <name>'.
+
 Example:
   pk show dojo
   pk show conduit
-  pk show boardgamefinder`,
+  pk show boardgamefinder
+  pk show dojo --format '{{.Path}}'
+  pk show dojo --json
+  pk show dojo --audit`,
 	Args:              cobra.ExactArgs(1),
 	Run:               runShow,
 	ValidArgsFunction: validProjectNames,
 }
 
+var (
+	showFormat string
+	showAudit  bool
+)
+
 func init() {
 	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().StringVar(&showFormat, "format", "", "Go template or saved format name, or table, json or yaml")
+	showCmd.Flags().BoolVar(&showAudit, "audit", false, "Show auto-detected fields and their confidence")
 }
 
 func runShow(cmd *cobra.Command, args []string) {
 	projectName := strings.ToLower(args[0])
 
 	// Find projects
-	homeDir, _ := os.UserHomeDir()
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-
-	projects, err := config.FindProjects(projectsDir, archiveDir)
+	projects, err := config.FindProjects(cacheRoots()...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
 		os.Exit(1)
@@ -59,6 +73,20 @@ func runShow(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if format := structuredFormat(cmd); format != "" {
+		printStructured(format, projectDocument(found))
+		return
+	}
+	if templateFormat(showFormat) {
+		renderFormat(showFormat, []*config.Project{found})
+		return
+	}
+
+	if showAudit {
+		printAudit(found)
+		return
+	}
+
 	// Print detailed info
 	printDetailedProject(found)
 }
@@ -76,9 +104,14 @@ func printDetailedProject(p *config.Project) {
 	statusColor := getStatusColor(p.ProjectInfo.Status)
 	fmt.Printf("  Status:      %s%s\033[0m\n", statusColor, p.ProjectInfo.Status)
 	fmt.Printf("  Type:        %s\n", p.ProjectInfo.Type)
+	if p.ProjectInfo.Kind != "" {
+		fmt.Printf("  Kind:        %s\n", p.ProjectInfo.Kind)
+	}
 	fmt.Printf("  Path:        %s\n", p.Path)
 	fmt.Printf("\n")
 
+	printKindReport(p)
+
 	// Ownership
 	fmt.Printf("\033[1mOwnership\033[0m\n")
 	fmt.Printf("  Owner:       %s\n", p.GetOwner())
@@ -153,5 +186,83 @@ func printDetailedProject(p *config.Project) {
 		fmt.Printf("\n")
 	}
 
+	if entry, err := journal.Latest(p.ProjectInfo.ID); err == nil && entry != nil {
+		fmt.Printf("\033[1mLatest Note\033[0m\n")
+		fmt.Printf("  %s \033[2m(%s)\033[0m\n", entry.Text, formatAccessTime(entry.Time))
+		fmt.Printf("\n")
+	}
+
+	if unconfirmed := len(p.Detected); unconfirmed > 0 {
+		fmt.Printf("\033[33m%d auto-detected field(s) unconfirmed\033[0m (pk show %s --audit)\n\n",
+			unconfirmed, p.ProjectInfo.ID)
+	}
+
 	fmt.Printf("═══════════════════════════════════════════════════════════════\n\n")
 }
+
+// printKindReport shows the extra fields the project's kind defines
+func printKindReport(p *config.Project) {
+	s, err := settings.Load()
+	if err != nil {
+		return
+	}
+	kind, exists := s.KindFor(p)
+	if !exists || len(kind.Report) == 0 {
+		return
+	}
+
+	fmt.Printf("\033[1m%s\033[0m\n", kind.Description)
+	for _, label := range settings.SortedKeys(kind.Report) {
+		value := ""
+		tmpl, err := output.ParseTemplate(kind.Report[label])
+		if err == nil {
+			var buf strings.Builder
+			if err = tmpl.Execute(&buf, p); err == nil {
+				value = strings.TrimSuffix(buf.String(), "\n")
+			}
+		}
+		if err != nil {
+			value = fmt.Sprintf("\033[31m%v\033[0m", err)
+		}
+		fmt.Printf("  %-12s %s\n", label+":", value)
+	}
+	fmt.Printf("\n")
+}
+
+// printAudit lists auto-detected fields, least confident first
+func printAudit(p *config.Project) {
+	entries := p.Audit()
+	if len(entries) == 0 {
+		fmt.Printf("\033[32m✓\033[0m %s: all metadata is user-set or confirmed\n", p.ProjectInfo.ID)
+		return
+	}
+
+	fmt.Printf("\033[1mDetected metadata: %s\033[0m\n\n", p.ProjectInfo.ID)
+	fmt.Printf("  %-18s %-10s %-30s %s\n", "FIELD", "CONFIDENCE", "VALUE", "SOURCE")
+	for _, e := range entries {
+		value := e.Current
+		if value == "" {
+			value = "-"
+		}
+		if e.Edited {
+			value += " (edited)"
+		}
+		fmt.Printf("  %-18s %s%-10s\033[0m %-30s %s\n",
+			e.Key, confidenceColor(e.Confidence, e.Edited), e.Confidence, value, e.Source)
+	}
+
+	fmt.Printf("\nEdit anything wrong with 'pk edit %s', then 'pk confirm %s'\n", p.ProjectInfo.ID, p.ProjectInfo.ID)
+}
+
+func confidenceColor(confidence string, edited bool) string {
+	if edited {
+		return "\033[2m" // Dimmed: the user has already touched it
+	}
+	switch confidence {
+	case config.ConfidenceHigh:
+		return "\033[32m"
+	case config.ConfidenceMedium:
+		return "\033[33m"
+	}
+	return "\033[31m"
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+var sidecarRestore bool
+
+var sidecarCmd = &cobra.Command{
+	Use:   "sidecar [project]",
+	Short: "Keep a project's metadata outside its repository",
+	Long: `Move a project's .project.toml out of the project into a sidecar file,
+~/.local/share/pk/meta/<id>.toml, for repositories where pk's files aren't
+allowed (client repositories, for instance).
+
+The sidecar holds the same metadata plus root, the project directory.
+Discovery finds the project under whichever root holds that directory, and
+every command reads and writes the sidecar instead. A sidecar takes
+precedence over a .project.toml in the same directory.
+
+--restore moves the metadata back into the project. Without a project, the
+project containing the current directory is used. pk new, clone and promote
+take --sidecar, or "sidecar" in [metadata.visibility] in config.toml, to
+start out this way.
+
+Example:
+  pk sidecar acme-etl              # Metadata to ~/.local/share/pk/meta/acme-etl.toml
+  pk sidecar acme-etl --restore    # Back to acme-etl/.project.toml`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runSidecar,
+	ValidArgsFunction: validAllProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(sidecarCmd)
+	sidecarCmd.Flags().BoolVar(&sidecarRestore, "restore", false,
+		"Move the metadata back into the project")
+}
+
+func runSidecar(cmd *cobra.Command, args []string) {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+	project := currentOrNamedProject(name)
+
+	if project.Sidecar() != sidecarRestore {
+		fmt.Printf("Nothing to do: metadata for '%s' is already in %s\n", project.ProjectInfo.ID, project.File())
+		return
+	}
+
+	file, err := project.MoveToSidecar(!sidecarRestore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cache.InvalidateCache()
+	fmt.Printf("\033[32m✓\033[0m Moved metadata for '%s' to %s\n", project.ProjectInfo.ID, file)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsWeeks int
+	statsLimit int
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Statistics about how you use your projects",
+	Long: `Statistics built from pk's own records.
+
+Subcommands:
+  pk stats access   # Opens, streaks and weekly activity per project`,
+}
+
+var statsAccessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Show open counts, streaks and weekly activity per project",
+	Long: `Show which projects actually get your time, from the access history pk
+keeps whenever you open a project (pk session, pk jump, the shell cd hook).
+
+For each project: all-time opens, last access, the current streak of
+consecutive days with an open (and the longest), and a sparkline of opens
+per week. A histogram of all opens per week follows. Weeks start on Monday.
+
+Per-day history is kept for a year. Opens from before pk kept it count
+toward the totals but not toward streaks or the histogram.
+
+Example:
+  pk stats access              # Last 8 weeks
+  pk stats access --weeks 26   # Last six months
+  pk stats access -n 5         # Top 5 projects`,
+	Args: cobra.NoArgs,
+	Run:  runStatsAccess,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsAccessCmd)
+	statsAccessCmd.Flags().IntVar(&statsWeeks, "weeks", 8, "Number of weeks to chart")
+	statsAccessCmd.Flags().IntVarP(&statsLimit, "limit", "n", 20, "Number of projects to show (0 for all)")
+}
+
+func runStatsAccess(cmd *cobra.Command, args []string) {
+	if statsWeeks < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --weeks must be at least 1\n")
+		os.Exit(1)
+	}
+
+	records, err := cache.LoadAccessRecords()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load access records: %v\n", err)
+		os.Exit(1)
+	}
+
+	format := structuredFormat(cmd)
+	if len(records) == 0 && format == "" {
+		fmt.Println("No access history yet")
+		fmt.Println("\nTip: Projects are tracked when you open them with 'pk session'")
+		return
+	}
+
+	now := time.Now()
+	stats := cache.ComputeAccessStats(records, statsWeeks, now)
+	totals := cache.WeeklyTotals(stats, statsWeeks)
+	if statsLimit > 0 && statsLimit < len(stats) {
+		stats = stats[:statsLimit]
+	}
+
+	first := cache.WeekStart(now).AddDate(0, 0, -7*(statsWeeks-1))
+	if format != "" {
+		printStructured(format, accessStatsDocument(stats, totals, first))
+		return
+	}
+
+	fmt.Printf("%-25s %6s  %-14s %-9s %s\n", "PROJECT", "OPENS", "LAST", "STREAK", fmt.Sprintf("%d WEEKS", statsWeeks))
+	for _, s := range stats {
+		streak := "-"
+		if s.Streak > 0 {
+			streak = fmt.Sprintf("%dd", s.Streak)
+		}
+		if s.LongestStreak > s.Streak {
+			streak += fmt.Sprintf(" (%d)", s.LongestStreak)
+		}
+		fmt.Printf("%-25s %6d  %-14s %-9s %s\n",
+			s.ProjectID, s.Opens, formatAccessTime(s.LastAccessed), streak, sparkline(s.Weekly))
+	}
+
+	fmt.Println("\nOpens per week:")
+	peak := 0
+	for _, n := range totals {
+		peak = max(peak, n)
+	}
+	for i, n := range totals {
+		bar := 0
+		if peak > 0 {
+			bar = (n*40 + peak - 1) / peak
+		}
+		fmt.Printf("  %-7s %s %d\n", first.AddDate(0, 0, 7*i).Format("Jan 2"), strings.Repeat("█", bar), n)
+	}
+}
+
+// accessStatsDocument is 'pk stats access' as JSON and YAML output show it
+func accessStatsDocument(stats []cache.AccessStats, totals []int, first time.Time) any {
+	type project struct {
+		ID            string    `json:"id"`
+		Opens         int       `json:"opens"`
+		LastAccessed  time.Time `json:"last_accessed"`
+		Streak        int       `json:"streak"`
+		LongestStreak int       `json:"longest_streak"`
+		Weekly        []int     `json:"weekly"`
+	}
+	type week struct {
+		Start string `json:"start"`
+		Opens int    `json:"opens"`
+	}
+
+	doc := struct {
+		Projects []project `json:"projects"`
+		Weeks    []week    `json:"weeks"`
+	}{Projects: []project{}, Weeks: []week{}}
+	for _, s := range stats {
+		doc.Projects = append(doc.Projects, project{s.ProjectID, s.Opens, s.LastAccessed, s.Streak, s.LongestStreak, s.Weekly})
+	}
+	for i, n := range totals {
+		doc.Weeks = append(doc.Weeks, week{first.AddDate(0, 0, 7*i).Format("2006-01-02"), n})
+	}
+	return doc
+}
+
+// sparkline draws values as block characters scaled to the largest
+func sparkline(values []int) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case v == 0:
+			b.WriteRune('·')
+		default:
+			b.WriteRune(blocks[(v*len(blocks)-1)/peak])
+		}
+	}
+	return b.String()
+}
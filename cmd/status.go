@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusAll    bool
+	statusFilter []string
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Overview of active projects: git state, sessions, last access",
+	Long: `Show a table of every active project, most recently used first: the
+morning overview of what's in flight.
+
+  BRANCH    Current git branch ("-" outside a repository, "detached")
+  CHANGES   +staged ~modified ?untracked !conflicted, or clean; "mirror"
+            for read-only reference checkouts (mirror = true)
+  SYNC      ↑ahead ↓behind the upstream, = when even
+  SESSION   ● when its session is running
+  ACCESSED  When you last opened or cd'd into it
+
+Repositories are read in parallel. --all adds paused and other non-archived
+projects; --filter narrows the list (see 'pk session --filter').
+
+Example:
+  pk status
+  pk status --all
+  pk status --filter client=Acme`,
+	Args: cobra.NoArgs,
+	Run:  runStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVarP(&statusAll, "all", "a", false, "Include paused and other non-archived projects")
+	statusCmd.Flags().StringArrayVar(&statusFilter, "filter", nil, "Only projects matching key=value terms (e.g. client=Acme)")
+	statusCmd.RegisterFlagCompletionFunc("filter", validFilterKeys)
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	filter, err := config.ParseFilter(statusFilter...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var shown []*config.Project
+	for _, p := range projects {
+		status := p.ProjectInfo.Status
+		if status == "archived" || (!statusAll && status != "active") || !filter.Matches(p) {
+			continue
+		}
+		shown = append(shown, p)
+	}
+	if len(shown) == 0 {
+		fmt.Println("No active projects")
+		return
+	}
+
+	records, _ := cache.LoadAccessRecords()
+	sort.SliceStable(shown, func(i, j int) bool {
+		return records[shown[i].ProjectInfo.ID].LastAccessed.After(records[shown[j].ProjectInfo.ID].LastAccessed)
+	})
+
+	running := make(map[string]bool)
+	if sessions, err := session.ListSessions(); err == nil {
+		for _, name := range sessions {
+			running[name] = true
+		}
+	}
+
+	// Only repositories are asked for their status
+	var repoDirs []string
+	var repoIndex []int
+	for i, p := range shown {
+		if git.IsRepo(p.Path) {
+			repoDirs = append(repoDirs, p.Path)
+			repoIndex = append(repoIndex, i)
+		}
+	}
+	statuses := make([]*git.StatusResult, len(shown))
+	for k, r := range git.ReadStatuses(repoDirs) {
+		statuses[repoIndex[k]] = &r
+	}
+
+	idWidth, branchWidth := len("PROJECT"), len("BRANCH")
+	for i, p := range shown {
+		idWidth = max(idWidth, len(p.ProjectInfo.ID))
+		if s := statuses[i]; s != nil {
+			branchWidth = max(branchWidth, len(s.Branch))
+		}
+	}
+	idWidth, branchWidth = min(idWidth, 30), min(branchWidth, 24)
+
+	fmt.Printf("%-*s  %-*s  %-14s %-9s %-7s %s\n", idWidth, "PROJECT", branchWidth, "BRANCH",
+		"CHANGES", "SYNC", "SESSION", "ACCESSED")
+	dirty := 0
+	for i, p := range shown {
+		branch, changes, sync := "\033[90m-\033[0m", "", ""
+		if s := statuses[i]; s != nil && s.Err == nil {
+			branch = s.Branch
+			if branch == "" {
+				branch = "detached"
+			}
+			branch = truncate(branch, branchWidth)
+			sync = describeSync(s.Status)
+			if p.ProjectInfo.Mirror {
+				// Mirrors are never worked in; local noise isn't news
+				changes = "\033[90mmirror\033[0m"
+			} else {
+				changes = describeChanges(s.Status)
+				if s.Dirty() {
+					dirty++
+				}
+			}
+		} else if s != nil {
+			branch = "\033[31m" + truncate("error", branchWidth) + "\033[0m"
+		}
+		branch = padANSI(branch, branchWidth)
+
+		sess := ""
+		if running[session.SanitizeSessionName(p.ProjectInfo.ID)] {
+			sess = "\033[32m●\033[0m"
+		}
+
+		accessed := "\033[90mnever\033[0m"
+		if r, ok := records[p.ProjectInfo.ID]; ok && !r.LastAccessed.IsZero() {
+			accessed = formatAccessTime(r.LastAccessed)
+		}
+
+		fmt.Printf("\033[34m%s\033[0m  %s  %s %s %s %s\n",
+			padANSI(truncate(p.ProjectInfo.ID, idWidth), idWidth), branch,
+			padANSI(changes, 14), padANSI(sync, 9), padANSI(sess, 7), accessed)
+	}
+
+	fmt.Printf("\n%d project(s), %d with uncommitted changes\n", len(shown), dirty)
+}
+
+// describeChanges summarizes a working tree, e.g. "+1 ~3 ?2"
+func describeChanges(s git.Status) string {
+	if !s.Dirty() {
+		return "\033[32mclean\033[0m"
+	}
+	var parts []string
+	for _, c := range []struct {
+		mark  string
+		count int
+		color string
+	}{
+		{"+", s.Staged, "\033[32m"},
+		{"~", s.Modified, "\033[33m"},
+		{"?", s.Untracked, "\033[90m"},
+		{"!", s.Conflicted, "\033[31m"},
+	} {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%s%s%d\033[0m", c.color, c.mark, c.count))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeSync summarizes a branch against its upstream, e.g. "↑2 ↓1"
+func describeSync(s git.Status) string {
+	if s.Upstream == "" {
+		return "\033[90mlocal\033[0m"
+	}
+	if s.Ahead == 0 && s.Behind == 0 {
+		return "\033[32m=\033[0m"
+	}
+	var parts []string
+	if s.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("\033[33m↑%d\033[0m", s.Ahead))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("\033[36m↓%d\033[0m", s.Behind))
+	}
+	return strings.Join(parts, " ")
+}
+
+// padANSI pads s with spaces to width visible characters, ignoring color
+// escapes
+func padANSI(s string, width int) string {
+	visible := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			if end := strings.IndexByte(s[i:], 'm'); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		visible++
+	}
+	return s + strings.Repeat(" ", max(0, width-visible))
+}
@@ -4,32 +4,68 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/datakaicr/pk/pkg/cache"
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/detect"
+	"github.com/datakaicr/pk/pkg/generated"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/datakaicr/pk/pkg/registry"
 	"github.com/datakaicr/pk/pkg/shell"
 	"github.com/spf13/cobra"
 )
 
+// Sync scopes
+const (
+	syncAliases = "aliases"
+	syncLinks   = "links"
+	syncBadges  = "badges"
+	syncCache   = "cache"
+	syncRemote  = "remote"
+	syncAll     = "all"
+)
+
+var allSyncScopes = []string{syncAliases, syncLinks, syncBadges, syncCache}
+
 var syncCmd = &cobra.Command{
-	Use:   "sync",
-	Short: "Sync shell aliases for all projects",
-	Long: `Generate shell aliases for all projects.
+	Use:   "sync [aliases|links|badges|cache|remote|all]",
+	Short: "Sync generated artifacts (aliases, links, badges, cache)",
+	Long: `Sync artifacts derived from project metadata. Each scope can be run on
+its own and reports what changed.
+
+Scopes:
+  aliases  Regenerate shell aliases for all projects
+  links    Fill empty links.repository from the git 'origin' remote
+  badges   Refresh status badges in READMEs containing a pk badges block
+  cache    Rescan project roots and rebuild the project cache
+  remote   Exchange metadata with the team registry and show what teammates
+           changed since the last pull (needs [team] url; not part of all);
+           only projects with visibility "public" are pushed
+  all      aliases, links, badges and cache (not remote)
 
-Detects your shell (zsh, bash, fish) and generates appropriate
-alias files in the correct location.
+Without a scope only aliases are regenerated; links and all rewrite
+.project.toml files, so they run only when asked for.
 
-For zsh: ~/.config/zsh/project-aliases.zsh
-For bash: ~/.bash_aliases
-For fish: ~/.config/fish/conf.d/project-aliases.fish
+Aliases are written for your detected shell (zsh, bash, fish):
+  zsh:  ~/.config/zsh/project-aliases.zsh
+  bash: ~/.bash_aliases
+  fish: ~/.config/fish/conf.d/project-aliases.fish
 
-After running, reload your shell:
-  source ~/.zshrc    # zsh
-  source ~/.bashrc   # bash
-  source ~/.config/fish/config.fish  # fish
+To opt a project into badges, add this block to its README.md:
+  <!-- pk:badges:start -->
+  <!-- pk:badges:end -->
 
 Example:
-  pk sync`,
-	Run: runSync,
+  pk sync              # Shell aliases
+  pk sync all          # Aliases, links, badges and cache
+  pk sync cache        # Only rebuild the cache
+  pk sync remote       # Push your edits, see your teammates'`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runSync,
+	ValidArgsFunction: validSyncScopes,
 }
 
 func init() {
@@ -37,51 +73,350 @@ func init() {
 }
 
 func runSync(cmd *cobra.Command, args []string) {
-	// Detect shell
+	scope := syncAliases
+	if len(args) > 0 {
+		scope = strings.ToLower(args[0])
+	}
+
+	scopes := []string{scope}
+	if scope == syncAll {
+		scopes = allSyncScopes
+	} else if !isSyncScope(scope) {
+		fmt.Fprintf(os.Stderr, "Error: Unknown sync scope '%s'\n", scope)
+		fmt.Fprintf(os.Stderr, "Valid scopes: %s, %s, all\n", strings.Join(allSyncScopes, ", "), syncRemote)
+		os.Exit(1)
+	}
+
+	syncScopes(scopes...)
+
+	if containsScope(scopes, syncAliases) {
+		fmt.Printf("\nReload your shell:\n")
+		switch shell.Detect() {
+		case shell.Zsh:
+			fmt.Printf("  source ~/.zshrc\n")
+		case shell.Bash:
+			fmt.Printf("  source ~/.bashrc\n")
+		case shell.Fish:
+			fmt.Printf("  source ~/.config/fish/config.fish\n")
+		}
+	}
+}
+
+// syncScopes runs the given sync scopes and prints a diff for each.
+// Commands that change projects call this with only the scopes they affect.
+func syncScopes(scopes ...string) {
+	resolver := projectPaths()
+	listed := listedRoots(resolver)
+
+	for _, scope := range scopes {
+		fmt.Printf("Syncing %s...\n", scope)
+
+		var changes []string
+		var err error
+
+		switch scope {
+		case syncAliases:
+			changes, err = syncAliasScope(listed...)
+		case syncLinks:
+			changes, err = syncLinkScope(listed...)
+		case syncBadges:
+			changes, err = syncBadgeScope(listed...)
+		case syncCache:
+			changes, err = syncCacheScope(resolver.AllRoots()...)
+		case syncRemote:
+			changes, err = syncRemoteScope(listed...)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error syncing %s: %v\n", scope, err)
+			continue
+		}
+
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+		if len(changes) == 0 {
+			fmt.Printf("  \033[32m✓\033[0m %s up to date\n", scope)
+		} else {
+			fmt.Printf("  \033[32m✓\033[0m %s: %d change(s)\n", scope, len(changes))
+		}
+	}
+}
+
+// syncAliasScope regenerates the alias file and diffs alias lines before/after
+func syncAliasScope(roots ...string) ([]string, error) {
 	currentShell := shell.Detect()
-	fmt.Printf("Detected shell: \033[36m%s\033[0m\n", currentShell)
+	aliasFile := shell.ConfigPath(currentShell)
+
+	projects, err := findProjects(roots...)
+	if err != nil {
+		return nil, err
+	}
 
-	// Find all projects
-	homeDir, _ := os.UserHomeDir()
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
+	before := readAliasLines(aliasFile)
+
+	if err := shell.GenerateAliases(currentShell, projects); err != nil {
+		return nil, err
+	}
+	generated.Record(aliasFile, generated.KindAliases, generated.ModeFile, "pk sync aliases")
 
-	fmt.Printf("Scanning projects...\n")
-	projects, err := config.FindProjects(projectsDir, archiveDir)
+	after := readAliasLines(aliasFile)
+	return diffSets(before, after), nil
+}
+
+// readAliasLines returns alias/abbr definitions from an alias file
+func readAliasLines(path string) []string {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
-		os.Exit(1)
+		return nil
 	}
 
-	if len(projects) == 0 {
-		fmt.Println("No projects found")
-		return
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "alias ") || strings.HasPrefix(line, "abbr ") {
+			lines = append(lines, line)
+		}
 	}
+	return lines
+}
 
-	fmt.Printf("Found %d projects\n", len(projects))
+// syncLinkScope fills empty links.repository from the git origin remote
+func syncLinkScope(roots ...string) ([]string, error) {
+	projects, err := findProjects(roots...)
+	if err != nil {
+		return nil, err
+	}
 
-	// Generate aliases
-	fmt.Printf("Generating aliases...\n")
-	if err := shell.GenerateAliases(currentShell, projects); err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating aliases: %v\n", err)
-		os.Exit(1)
+	var candidates []*config.Project
+	for _, p := range projects {
+		if p.Links.Repository != "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(p.Path, ".git")); err == nil {
+			candidates = append(candidates, p)
+		}
 	}
 
-	aliasFile := shell.ConfigPath(currentShell)
-	fmt.Printf("\n\033[32m✓\033[0m Aliases generated successfully!\n")
-	fmt.Printf("  File: %s\n", aliasFile)
-	fmt.Printf("\nReload your shell:\n")
-
-	switch currentShell {
-	case shell.Zsh:
-		fmt.Printf("  source ~/.zshrc\n")
-	case shell.Bash:
-		fmt.Printf("  source ~/.bashrc\n")
-	case shell.Fish:
-		fmt.Printf("  source ~/.config/fish/config.fish\n")
-	}
-
-	fmt.Printf("\nThen test:\n")
-	fmt.Printf("  dojo      # Jump to dojo project\n")
-	fmt.Printf("  conduit   # Jump to conduit project\n")
+	task := progress.Counter("Reading remote of", len(candidates))
+	defer task.Stop()
+
+	var changes []string
+	for _, p := range candidates {
+		task.Next(p.ProjectInfo.ID)
+		remote := detect.Repository(p.Path)
+		if remote == "" {
+			continue
+		}
+
+		tomlPath := p.File()
+		if err := updateProjectTomlRepository(tomlPath, remote); err != nil {
+			changes = append(changes, fmt.Sprintf("\033[31m!\033[0m %s: %v", p.ProjectInfo.ID, err))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("\033[33m~\033[0m %s: links.repository = %s", p.ProjectInfo.ID, remote))
+	}
+
+	return changes, nil
+}
+
+func updateProjectTomlRepository(path, repository string) error {
+	project, err := config.LoadProject(path)
+	if err != nil {
+		return err
+	}
+
+	project.Links.Repository = repository
+
+	return project.SaveAs(path)
+}
+
+// syncBadgeScope refreshes badge blocks in READMEs that opted in
+func syncBadgeScope(roots ...string) ([]string, error) {
+	projects, err := findProjects(roots...)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	for _, p := range projects {
+		readmePath := filepath.Join(p.Path, "README.md")
+		data, err := os.ReadFile(readmePath)
+		if err != nil {
+			continue
+		}
+
+		content := string(data)
+		start := strings.Index(content, generated.BadgesStart)
+		end := strings.Index(content, generated.BadgesEnd)
+		if start < 0 || end < start {
+			continue
+		}
+
+		updated := content[:start] + generated.BadgesStart + "\n" + renderBadges(p) + "\n" + content[end:]
+		if updated == content {
+			generated.Record(readmePath, generated.KindBadges, generated.ModeBlock, "pk sync badges")
+			continue
+		}
+
+		if err := os.WriteFile(readmePath, []byte(updated), 0644); err != nil {
+			changes = append(changes, fmt.Sprintf("\033[31m!\033[0m %s: %v", p.ProjectInfo.ID, err))
+			continue
+		}
+		generated.Record(readmePath, generated.KindBadges, generated.ModeBlock, "pk sync badges")
+		changes = append(changes, fmt.Sprintf("\033[33m~\033[0m %s: README.md badges", p.ProjectInfo.ID))
+	}
+
+	return changes, nil
+}
+
+// renderBadges builds shields.io badges for a project's status, type, and stack
+func renderBadges(p *config.Project) string {
+	var badges []string
+
+	if p.ProjectInfo.Status != "" {
+		color := "lightgrey"
+		switch p.ProjectInfo.Status {
+		case "active":
+			color = "green"
+		case "paused":
+			color = "blue"
+		case "archived":
+			color = "yellow"
+		}
+		badges = append(badges, shieldsBadge("status", p.ProjectInfo.Status, color))
+	}
+	if p.ProjectInfo.Type != "" {
+		badges = append(badges, shieldsBadge("type", p.ProjectInfo.Type, "informational"))
+	}
+	if len(p.Tech.Stack) > 0 {
+		badges = append(badges, shieldsBadge("stack", strings.Join(p.Tech.Stack, ", "), "blueviolet"))
+	}
+
+	return strings.Join(badges, " ")
+}
+
+func shieldsBadge(label, message, color string) string {
+	escape := strings.NewReplacer("-", "--", "_", "__", " ", "%20")
+	return fmt.Sprintf("![%s](https://img.shields.io/badge/%s-%s-%s)",
+		label, escape.Replace(label), escape.Replace(message), color)
+}
+
+// syncCacheScope rescans roots, rewrites the cache, and diffs project IDs
+func syncCacheScope(roots ...string) ([]string, error) {
+	var before []string
+	if cached, err := cache.LoadFromCache(); err == nil {
+		for _, p := range cached {
+			before = append(before, p.ProjectInfo.ID)
+		}
+	}
+
+	projects, err := findProjects(roots...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.SaveToCache(projects); err != nil {
+		return nil, err
+	}
+
+	var after []string
+	for _, p := range projects {
+		after = append(after, p.ProjectInfo.ID)
+	}
+
+	return diffSets(before, after), nil
+}
+
+// syncRemoteScope pulls the team registry, lists teammates' changes since
+// the last pull and pushes the projects edited here
+func syncRemoteScope(roots ...string) ([]string, error) {
+	s := loadSettings()
+	remote, err := registry.Open(s)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := findProjects(roots...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := registry.Sync(remote, projects, registry.User(s), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	for _, c := range result.Changes {
+		changes = append(changes, "\033[36m•\033[0m "+c.String())
+	}
+	for _, id := range result.Pushed {
+		changes = append(changes, "\033[33m↑\033[0m pushed "+id)
+	}
+	for _, id := range result.Withdrawn {
+		changes = append(changes, "\033[33m↓\033[0m withdrew "+id+" (no longer public)")
+	}
+	return changes, nil
+}
+
+// diffSets returns "+ item" / "- item" lines for items added or removed
+func diffSets(before, after []string) []string {
+	beforeSet := make(map[string]bool)
+	for _, item := range before {
+		beforeSet[item] = true
+	}
+	afterSet := make(map[string]bool)
+	for _, item := range after {
+		afterSet[item] = true
+	}
+
+	var added, removed []string
+	for item := range afterSet {
+		if !beforeSet[item] {
+			added = append(added, item)
+		}
+	}
+	for item := range beforeSet {
+		if !afterSet[item] {
+			removed = append(removed, item)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	var changes []string
+	for _, item := range added {
+		changes = append(changes, "\033[32m+\033[0m "+item)
+	}
+	for _, item := range removed {
+		changes = append(changes, "\033[31m-\033[0m "+item)
+	}
+
+	return changes
+}
+
+func isSyncScope(scope string) bool {
+	return containsScope(allSyncScopes, scope) || scope == syncRemote
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// validSyncScopes returns sync scopes for completion
+func validSyncScopes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var matches []string
+	for _, s := range append(allSyncScopes, syncRemote, syncAll) {
+		if strings.HasPrefix(s, toComplete) {
+			matches = append(matches, s)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
 }
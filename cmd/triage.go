@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/datakaicr/pk/pkg/trash"
+	"github.com/spf13/cobra"
+)
+
+// triageSnooze is how long 's' hides a project from the queue
+const triageSnooze = 30 * 24 * time.Hour
+
+var (
+	triageDays int
+	triageAll  bool
+)
+
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Review stale and paused projects one by one",
+	Long: `Walk through projects in ~/projects that look finished: paused or
+completed ones, and any other project with no activity (opened with pk or
+committed to) for --days days. Each shows its metadata, last activity and
+size, and takes a single key:
+
+  a  archive it (as 'pk archive')
+  k  keep it; it comes back once it has been idle for another --days days
+  d  move it to the trash (asks again first; 'pk restore' brings it back)
+  s  snooze it for 30 days
+  q  stop; the rest stay in the queue
+
+Kept and snoozed projects are remembered, so the queue shrinks each time.
+
+Example:
+  pk triage              # Idle for 90 days, paused or completed
+  pk triage --days 30    # Stricter
+  pk triage --all        # Include kept and snoozed projects`,
+	Args: cobra.NoArgs,
+	Run:  runTriage,
+}
+
+func init() {
+	rootCmd.AddCommand(triageCmd)
+	triageCmd.Flags().IntVar(&triageDays, "days", 90, "Days without activity before a project is stale")
+	triageCmd.Flags().BoolVar(&triageAll, "all", false, "Include kept and snoozed projects")
+}
+
+// triageItem is a project in the review queue
+type triageItem struct {
+	project  *config.Project
+	activity time.Time // Last open or commit; zero if neither is known
+	opened   time.Time
+	commit   time.Time
+	record   cache.AccessRecord
+}
+
+func runTriage(cmd *cobra.Command, args []string) {
+	resolver := projectPaths()
+	projectsDir := resolver.Projects()
+
+	projects, err := findProjects(projectsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	queue := triageQueue(projects, time.Now())
+	if len(queue) == 0 {
+		fmt.Printf("\033[32m✓\033[0m Nothing to triage (no project idle for %d days)\n", triageDays)
+		return
+	}
+
+	fmt.Printf("%d project(s) to review\n", len(queue))
+	input := bufio.NewReader(os.Stdin)
+	counts := make(map[string]int)
+	for i, item := range queue {
+		p := item.project
+		fmt.Println()
+		printTriageItem(item, i+1, len(queue))
+
+		action := ""
+		for action == "" {
+			fmt.Print("\n  [a]rchive  [k]eep  [d]elete  [s]nooze 30d  [q]uit: ")
+			switch key := readKey(input); key {
+			case 'a', 'k', 'd', 's', 'q':
+				action = string(key)
+				fmt.Println(action)
+			case 0:
+				action = "q" // End of input
+				fmt.Println()
+			default:
+				fmt.Println()
+			}
+		}
+
+		switch action {
+		case "a":
+			archiveDir, err := archiveDirFor(resolver, p)
+			if err != nil {
+				fmt.Printf("  \033[31m✗\033[0m %v\n", err)
+				continue
+			}
+			destPath, err := archiveProject(p, archiveDir)
+			if err != nil {
+				fmt.Printf("  \033[31m✗\033[0m %v\n", err)
+				continue
+			}
+			fmt.Printf("  \033[32m✓\033[0m Archived to %s\n", destPath)
+			counts["archived"]++
+		case "k":
+			until := time.Now().AddDate(0, 0, triageDays)
+			if err := cache.Snooze(p.ProjectInfo.ID, p.Path, until, "kept"); err != nil {
+				fmt.Printf("  \033[31m✗\033[0m Failed to save: %v\n", err)
+				continue
+			}
+			fmt.Printf("  \033[32m✓\033[0m Kept until %s\n", until.Format("2006-01-02"))
+			counts["kept"]++
+		case "s":
+			until := time.Now().Add(triageSnooze)
+			if err := cache.Snooze(p.ProjectInfo.ID, p.Path, until, "snoozed"); err != nil {
+				fmt.Printf("  \033[31m✗\033[0m Failed to save: %v\n", err)
+				continue
+			}
+			fmt.Printf("  \033[32m✓\033[0m Snoozed until %s\n", until.Format("2006-01-02"))
+			counts["snoozed"]++
+		case "d":
+			fmt.Printf("  \033[33mMove %s to the trash?\033[0m (y/N): ", p.Path)
+			if key := readKey(input); key != 'y' {
+				fmt.Println("\n  Not deleted")
+				continue
+			}
+			fmt.Println("y")
+			if err := removeProject(p); err != nil {
+				fmt.Printf("  \033[31m✗\033[0m %v\n", err)
+				continue
+			}
+			fmt.Printf("  \033[32m✓\033[0m Moved to the trash (pk restore %s)\n", p.ProjectInfo.ID)
+			counts["deleted"]++
+		}
+		if action == "q" {
+			counts["left"] = len(queue) - i
+			break
+		}
+	}
+
+	var summary []string
+	for _, key := range []string{"archived", "deleted", "kept", "snoozed", "left"} {
+		if counts[key] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[key], key))
+		}
+	}
+	if len(summary) > 0 {
+		fmt.Printf("\n%s\n", strings.Join(summary, ", "))
+	}
+	if counts["archived"]+counts["deleted"] > 0 {
+		cache.InvalidateCache()
+		syncScopes(syncAliases)
+	}
+}
+
+// triageQueue returns the projects due for review, least recently active
+// first: paused and completed projects, and others idle for triageDays.
+// Archived projects and, unless --all, kept or snoozed ones are left out.
+func triageQueue(projects []*config.Project, now time.Time) []triageItem {
+	records, _ := cache.LoadAccessRecords()
+	snoozes, err := cache.LoadSnoozes()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load snoozes: %v\n", err)
+	}
+	cutoff := now.AddDate(0, 0, -triageDays)
+
+	task := progress.Counter("Checking", len(projects))
+	defer task.Stop()
+
+	var queue []triageItem
+	for _, p := range projects {
+		task.Next(p.ProjectInfo.ID)
+		status := p.ProjectInfo.Status
+		if status == "archived" || (!triageAll && cache.Snoozed(snoozes, p.Path, now)) {
+			continue
+		}
+
+		item := triageItem{project: p, record: records[p.ProjectInfo.ID]}
+		item.opened = item.record.LastAccessed
+		if date, err := time.Parse(time.RFC3339, gitOutput(p.Path, "log", "-1", "--format=%cI")); err == nil {
+			item.commit = date
+		}
+		item.activity = item.opened
+		if item.commit.After(item.activity) {
+			item.activity = item.commit
+		}
+
+		if status == "paused" || status == "completed" || item.activity.Before(cutoff) {
+			queue = append(queue, item)
+		}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].activity.Before(queue[j].activity) })
+	return queue
+}
+
+func printTriageItem(item triageItem, n, total int) {
+	p := item.project
+	fmt.Printf("\033[1m[%d/%d] %s\033[0m (%s)\n", n, total, p.ProjectInfo.Name, p.ProjectInfo.ID)
+	fmt.Printf("  Status:      %s\n", orDash(p.ProjectInfo.Status))
+	if p.ProjectInfo.Type != "" {
+		fmt.Printf("  Type:        %s\n", p.ProjectInfo.Type)
+	}
+	if client := p.GetClientName(); client != "" {
+		fmt.Printf("  Client:      %s\n", client)
+	}
+	if p.Notes.Description != "" {
+		fmt.Printf("  Description: %s\n", truncate(p.Notes.Description, 70))
+	}
+	fmt.Printf("  Path:        %s\n", p.Path)
+
+	opened := "never"
+	if !item.opened.IsZero() {
+		opened = formatAccessTime(item.opened)
+		if count := openCount(item.record); count != "" {
+			opened += ", " + count
+		}
+	}
+	fmt.Printf("  Last opened: %s\n", opened)
+
+	commit := "-"
+	if !item.commit.IsZero() {
+		commit = fmt.Sprintf("%s (%s)", item.commit.Format("2006-01-02"), formatAccessTime(item.commit))
+	}
+	fmt.Printf("  Last commit: %s\n", commit)
+
+	if !item.activity.IsZero() {
+		fmt.Printf("  Idle:        %d days\n", int(time.Since(item.activity).Hours()/24))
+	}
+
+	task := progress.Spinner("Measuring " + p.ProjectInfo.ID)
+	files, bytes := projectSize(p.Path)
+	task.Stop()
+	fmt.Printf("  Size:        %s in %d files\n", formatBytes(bytes), files)
+}
+
+// removeProject kills a project's session, if any, and moves it to the
+// trash
+func removeProject(p *config.Project) error {
+	if name := session.SanitizeSessionName(p.ProjectInfo.ID); session.SessionExists(name) {
+		if err := session.KillSession(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to kill tmux session: %v\n", err)
+		}
+	}
+	entry, err := trash.Put(p)
+	if err != nil {
+		return err
+	}
+	events.Emit(events.ProjectDeleted, p.ProjectInfo.ID, p.Path, map[string]string{"trash": entry.Dir})
+	return nil
+}
+
+// readKey reads a single keypress, lowercased, without waiting for Enter
+// when stdin is a terminal; otherwise the first character of the next line.
+// Returns 0 at the end of input.
+func readKey(input *bufio.Reader) rune {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		stty := func(args ...string) ([]byte, error) {
+			cmd := runner.Command("stty", args...)
+			cmd.Stdin = os.Stdin
+			return runner.Output(cmd)
+		}
+		if saved, err := stty("-g"); err == nil {
+			if _, err := stty("-icanon", "-echo", "min", "1"); err == nil {
+				defer stty(strings.TrimSpace(string(saved)))
+				r, _, err := input.ReadRune()
+				if err != nil {
+					return 0
+				}
+				return []rune(strings.ToLower(string(r)))[0]
+			}
+		}
+	}
+
+	line, err := input.ReadString('\n')
+	if line = strings.TrimSpace(line); line == "" {
+		if err != nil {
+			return 0
+		}
+		return '\n'
+	}
+	return []rune(strings.ToLower(line))[0]
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/archive"
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unarchiveClearCompleted bool
+	unarchiveAutoSync       bool
+)
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <name>",
+	Short: "Bring an archived project back to active",
+	Long: `Move a project from the archive directory (or an archive rule's
+directory) back to ~/projects.
+
+This will:
+  1. Unpack it if it was archived with --compress (checking its checksum)
+  2. Move it from its archive (~/archive or an archive_rules path) to
+     the projects root
+  3. Update status to "active" in .project.toml
+  4. Clear the completion date, with --clear-completed
+  5. Rebuild the cache and auto-sync shell aliases (if enabled)
+
+Example:
+  pk unarchive old-project
+  pk unarchive keplr-data-model --clear-completed`,
+	Args:              cobra.ExactArgs(1),
+	Run:               runUnarchive,
+	ValidArgsFunction: validArchivedNames,
+}
+
+func init() {
+	rootCmd.AddCommand(unarchiveCmd)
+	unarchiveCmd.Flags().BoolVar(&unarchiveClearCompleted, "clear-completed", false, "Clear dates.completed")
+	unarchiveCmd.Flags().BoolVar(&unarchiveAutoSync, "sync", true, "Auto-sync aliases after unarchiving")
+}
+
+func runUnarchive(cmd *cobra.Command, args []string) {
+	projectName := strings.ToLower(args[0])
+	resolver := projectPaths()
+
+	projects, err := config.FindProjects(resolver.Archives()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	var found *config.Project
+	for _, p := range projects {
+		if strings.ToLower(p.ProjectInfo.ID) == projectName ||
+			strings.ToLower(p.ProjectInfo.Name) == projectName {
+			found = p
+			break
+		}
+	}
+	if found == nil {
+		fmt.Fprintf(os.Stderr, "Project '%s' not found in %s\n", projectName, strings.Join(resolver.Archives(), ", "))
+		fmt.Fprintf(os.Stderr, "Hint: Use 'pk list archived' to see archived projects\n")
+		os.Exit(1)
+	}
+
+	destPath := filepath.Join(resolver.Projects(), filepath.Base(found.Path))
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists\n", destPath)
+		os.Exit(1)
+	}
+
+	if archive.Compressed(found.Path) {
+		if err := deps.Require("Unpacking a compressed project", "tar", "zstd"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Unpacking %s\n", filepath.Base(archive.Tarball(found.Path)))
+		if err := archive.Extract(found.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to unpack: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Moving project: %s\n", found.ProjectInfo.Name)
+	fmt.Printf("  From: %s\n", found.Path)
+	fmt.Printf("  To:   %s\n", destPath)
+
+	if err := os.MkdirAll(resolver.Projects(), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create projects directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err := paths.Move(found.Path, destPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to move project: %v\n", err)
+		os.Exit(1)
+	}
+
+	tomlPath := found.FileIn(destPath)
+	if err := reactivateProjectToml(tomlPath, destPath, unarchiveClearCompleted); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to update .project.toml: %v\n", err)
+	}
+	events.Emit(events.ProjectUnarchived, found.ProjectInfo.ID, destPath, nil)
+
+	fmt.Printf("\n\033[32m✓\033[0m Unarchived successfully\n")
+	fmt.Printf("  Status: \033[32mactive\033[0m\n")
+	fmt.Printf("  Location: %s\n", destPath)
+
+	fmt.Println()
+	cache.InvalidateCache()
+	scopes := []string{syncCache}
+	if unarchiveAutoSync {
+		scopes = append(scopes, syncAliases)
+	}
+	syncScopes(scopes...)
+}
+
+// reactivateProjectToml marks a project active again at dir, optionally
+// clearing its completion date
+func reactivateProjectToml(path, dir string, clearCompleted bool) error {
+	project, err := config.LoadProject(path)
+	if err != nil {
+		return err
+	}
+	project.Path = dir // A sidecar still names the archive directory
+
+	project.ProjectInfo.Status = "active"
+	if clearCompleted {
+		project.Dates.Completed = ""
+	}
+
+	return project.SaveAs(path)
+}
+
+// validArchivedNames completes the IDs of projects in the archive
+// directories
+func validArchivedNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	projects, _ := config.FindProjects(projectPaths().Archives()...)
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.ProjectInfo.ID)
+	}
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var validateAll bool
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [name|path]",
+	Short: "Check .project.toml files against the schema",
+	Long: `Check .project.toml files against the schema.
+
+Reports unknown keys, invalid enum values (status, ownership, visibility),
+malformed dates, missing required fields, and TOML syntax errors, one
+"file:line: key: message" per problem. Exits non-zero if anything is wrong,
+so it can run in CI.
+
+With no argument, validates the project containing the current directory.
+
+Example:
+  pk validate              # Project in current directory
+  pk validate dojo         # By ID, name, or directory name
+  pk validate ./.project.toml
+  pk validate --all        # Every project in the roots, archive_rules included`,
+	Args:              cobra.MaximumNArgs(1),
+	Run:               runValidate,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().BoolVar(&validateAll, "all", false, "Validate every project")
+}
+
+func runValidate(cmd *cobra.Command, args []string) {
+	var files []string
+
+	switch {
+	case validateAll:
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --all does not take a project argument\n")
+			os.Exit(1)
+		}
+		files = allProjectFiles()
+	case len(args) == 1:
+		files = []string{resolveProjectFile(args[0])}
+	default:
+		cwd, _ := os.Getwd()
+		file := config.FindProjectFile(cwd)
+		if file == "" {
+			fmt.Fprintf(os.Stderr, "Error: Not inside a project (no .project.toml found); pass a name or --all\n")
+			os.Exit(1)
+		}
+		files = []string{file}
+	}
+
+	if len(files) == 0 {
+		fmt.Println("No projects found")
+		return
+	}
+
+	bad := 0
+	for _, file := range files {
+		diags, err := config.ValidateFile(file)
+		if err != nil {
+			fmt.Printf("%s: %v\n", file, err)
+			bad++
+			continue
+		}
+		for _, d := range diags {
+			fmt.Println(d.Format(file))
+		}
+		if len(diags) > 0 {
+			bad++
+		}
+	}
+
+	if bad > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d of %d files have problems\n", bad, len(files))
+		os.Exit(1)
+	}
+
+	if len(files) == 1 {
+		fmt.Printf("\033[32m✓\033[0m %s is valid\n", files[0])
+	} else {
+		fmt.Printf("\033[32m✓\033[0m All %d files are valid\n", len(files))
+	}
+}
+
+// allProjectFiles lists .project.toml files in the standard roots, including malformed ones
+func allProjectFiles() []string {
+	files, err := config.FindProjectFiles(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding projects: %v\n", err)
+		os.Exit(1)
+	}
+	return files
+}
+
+// resolveProjectFile maps a path, project ID/name, or directory name to its .project.toml.
+// Directory names matter because a file too broken to parse has no ID.
+func resolveProjectFile(arg string) string {
+	if info, err := os.Stat(arg); err == nil {
+		if info.IsDir() {
+			return config.MetadataFile(arg)
+		}
+		return arg
+	}
+
+	name := strings.ToLower(arg)
+	for _, file := range allProjectFiles() {
+		if strings.ToLower(filepath.Base(filepath.Dir(file))) == name {
+			return file
+		}
+		if p, err := config.LoadProject(file); err == nil {
+			if strings.ToLower(p.ProjectInfo.ID) == name || strings.ToLower(p.ProjectInfo.Name) == name {
+				return file
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", arg)
+	os.Exit(1)
+	return ""
+}
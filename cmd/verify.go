@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/datakaicr/pk/pkg/shell"
+	"github.com/datakaicr/pk/pkg/statefile"
+	"github.com/spf13/cobra"
+)
+
+var verifyInstallCmd = &cobra.Command{
+	Use:   "verify-install",
+	Short: "Check that a fresh install works end to end",
+	Long: `Exercise the whole chain pk relies on after 'pk install' and print it as a
+checklist, so problems show up now rather than halfway through a workflow:
+
+  - Binary: the pk on PATH, its version, and whether it is this one
+  - Completions: 'pk completion <shell>' loads in your shell
+  - Aliases: the alias file exists, parses, and your shell rc sources it
+  - Cache: ~/.cache/pk is writable
+  - tmux: installed and able to start a server
+  - Session: a throwaway tmux session can be created and killed
+
+Unlike 'pk doctor', which reviews configuration and state, this runs
+things. It exits with status 1 if any check fails; warnings don't count.
+
+Example:
+  pk verify-install`,
+	Args: cobra.NoArgs,
+	Run:  runVerifyInstall,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyInstallCmd)
+}
+
+// Outcomes of a verify-install check
+const (
+	checkPass = iota
+	checkWarn
+	checkFail
+)
+
+// installCheck is one line of the verify-install checklist. run returns
+// the outcome, a short detail, and for anything but a pass, how to fix it.
+type installCheck struct {
+	name string
+	run  func() (outcome int, detail, fix string)
+}
+
+func runVerifyInstall(cmd *cobra.Command, args []string) {
+	sh := shell.Detect()
+	checks := []installCheck{
+		{"Binary", verifyBinary},
+		{"Completions", func() (int, string, string) { return verifyCompletions(sh) }},
+		{"Aliases", func() (int, string, string) { return verifyAliases(sh) }},
+		{"Cache", verifyCache},
+		{"tmux", verifyTmux},
+		{"Session", verifySession},
+	}
+
+	fmt.Printf("Verifying pk install (%s)...\n\n", sh)
+	failed, warned := 0, 0
+	for _, check := range checks {
+		outcome, detail, fix := check.run()
+		mark := "\033[32m✓\033[0m"
+		switch outcome {
+		case checkWarn:
+			mark = "\033[33m⚠\033[0m"
+			warned++
+		case checkFail:
+			mark = "\033[31m✗\033[0m"
+			failed++
+		}
+		fmt.Printf("  %s %-12s %s\n", mark, check.name, detail)
+		if fix != "" {
+			fmt.Printf("    %-12s \033[90m%s\033[0m\n", "", fix)
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case failed > 0:
+		fmt.Printf("\033[31m✗\033[0m %d check(s) failed, %d warning(s)\n", failed, warned)
+		os.Exit(1)
+	case warned > 0:
+		fmt.Printf("\033[32m✓\033[0m pk works (%d warning(s))\n", warned)
+	default:
+		fmt.Printf("\033[32m✓\033[0m Everything works\n")
+	}
+}
+
+// installedPK returns the pk that shells run: the one on PATH, or this
+// binary if there is none
+func installedPK() string {
+	if path, err := runner.LookPath("pk"); err == nil {
+		return path
+	}
+	exe, _ := os.Executable()
+	return exe
+}
+
+func verifyBinary() (int, string, string) {
+	exe, err := os.Executable()
+	if err != nil {
+		return checkFail, fmt.Sprintf("cannot locate this binary: %v", err), ""
+	}
+	exe, _ = filepath.EvalSymlinks(exe)
+	detail := fmt.Sprintf("pk %s at %s", buildVersion(), exe)
+
+	onPath, err := runner.LookPath("pk")
+	if err != nil {
+		return checkFail, detail + ", but pk is not on PATH", "Run: pk install (or add its directory to PATH)"
+	}
+	if resolved, err := filepath.EvalSymlinks(onPath); err == nil && resolved != exe {
+		return checkWarn, detail + "; PATH runs " + onPath, "Run: pk install, to replace the older binary"
+	}
+	return checkPass, detail, ""
+}
+
+// completionLoader is a shell script that loads pk's completion from $PK
+// and fails unless the shell then knows how to complete pk
+var completionLoader = map[shell.Shell]string{
+	shell.Zsh:  `autoload -Uz compinit && compinit -u -D && source <("$PK" completion zsh) && (( $+functions[_pk] ))`,
+	shell.Bash: `source <("$PK" completion bash) && complete -p pk >/dev/null`,
+	shell.Fish: `"$PK" completion fish | source; and complete -c pk | string length -q`,
+}
+
+func verifyCompletions(sh shell.Shell) (int, string, string) {
+	if !deps.Available(string(sh)) {
+		return checkWarn, fmt.Sprintf("skipped: %s not found", sh), ""
+	}
+	cmd := runner.Command(string(sh), "-c", completionLoader[sh])
+	cmd.Env = append(os.Environ(), "PK="+installedPK())
+	if output, err := runner.Output(cmd); err != nil {
+		detail := fmt.Sprintf("'pk completion %s' doesn't load in %s", sh, sh)
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			detail += ": " + msg
+		}
+		return checkFail, detail, "Run: pk install, then restart your shell"
+	}
+	return checkPass, fmt.Sprintf("load in %s", sh), ""
+}
+
+// rcFile returns the startup file that should source the alias file, or
+// "" if the shell loads it on its own
+func rcFile(sh shell.Shell) string {
+	homeDir, _ := os.UserHomeDir()
+	switch sh {
+	case shell.Bash:
+		return filepath.Join(homeDir, ".bashrc")
+	case shell.Fish:
+		return "" // conf.d is sourced automatically
+	}
+	if dir := os.Getenv("ZDOTDIR"); dir != "" {
+		return filepath.Join(dir, ".zshrc")
+	}
+	return filepath.Join(homeDir, ".zshrc")
+}
+
+func verifyAliases(sh shell.Shell) (int, string, string) {
+	aliases := shell.ConfigPath(sh)
+	if _, err := os.Stat(aliases); err != nil {
+		return checkFail, aliases + " not found", "Run: pk sync aliases"
+	}
+	if deps.Available(string(sh)) {
+		if err := runner.Run(runner.Command(string(sh), "-n", aliases)); err != nil {
+			return checkFail, aliases + " has syntax errors", "Run: pk sync aliases"
+		}
+	}
+
+	rc := rcFile(sh)
+	if rc == "" {
+		return checkPass, aliases + " (loaded from conf.d)", ""
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil || !strings.Contains(string(data), filepath.Base(aliases)) {
+		home, _ := os.UserHomeDir()
+		return checkFail, fmt.Sprintf("%s is not sourced from %s", aliases, rc),
+			fmt.Sprintf("Add to %s: source %s", rc, strings.Replace(aliases, home, "~", 1))
+	}
+	return checkPass, fmt.Sprintf("%s, sourced from %s", aliases, rc), ""
+}
+
+func verifyCache() (int, string, string) {
+	dir, err := statefile.Dir()
+	if err != nil {
+		return checkFail, fmt.Sprintf("cannot locate the cache directory: %v", err), ""
+	}
+	probe, err := os.CreateTemp(dir, ".verify-*")
+	if err != nil {
+		return checkFail, fmt.Sprintf("%s is not writable: %v", dir, err),
+			"Fix the permissions, or pk keeps its cache in memory for each run"
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return checkPass, dir + " is writable", ""
+}
+
+func verifyTmux() (int, string, string) {
+	if !deps.Available("tmux") {
+		return checkFail, "not found", deps.Lookup("tmux").InstallHint()
+	}
+	output, err := runner.Output(runner.Command("tmux", "-V"))
+	if err != nil {
+		return checkFail, fmt.Sprintf("tmux -V failed: %v", err), ""
+	}
+	return checkPass, strings.TrimSpace(string(output)), ""
+}
+
+func verifySession() (int, string, string) {
+	if !deps.Available("tmux") {
+		return checkWarn, "skipped: tmux not found", ""
+	}
+	homeDir, _ := os.UserHomeDir()
+	name := fmt.Sprintf("pk-verify-%d", os.Getpid())
+
+	create := runner.Command("tmux", "new-session", "-d", "-s", name, "-c", homeDir)
+	if output, err := runner.Output(create); err != nil {
+		return checkFail, fmt.Sprintf("cannot create a session: %s", strings.TrimSpace(string(output)+" "+err.Error())),
+			"Check that tmux can start a server (try: tmux new -d)"
+	}
+	var tmux session.Tmux
+	if !tmux.Exists(name) {
+		return checkFail, "created a session but tmux can't find it", ""
+	}
+	if err := tmux.Kill(name); err != nil {
+		return checkFail, fmt.Sprintf("cannot kill session %s: %v", name, err), "Run: tmux kill-session -t " + name
+	}
+	return checkPass, "created and killed " + name, ""
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/daemon"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/datakaicr/pk/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show pk's version, build and the tools it found",
+	Long: `Show what to include in a bug report: pk's version and the commit and date
+it was built from, the Go version and platform, which cache and state
+backends are configured, whether the daemon is installed, the cache schema
+version, and the versions of the external tools pk uses.
+
+With --format json or yaml (or --json) the same is printed for scripts.
+
+Example:
+  pk version
+  pk version --json`,
+	Args: cobra.NoArgs,
+	Run:  runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+
+// buildInfo is what the Go toolchain recorded about this binary
+type buildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	CommitDate string `json:"commit_date"`
+	Modified   bool   `json:"modified"`
+	GoVersion  string `json:"go_version"`
+	Platform   string `json:"platform"`
+}
+
+// readBuildInfo reads the module version and version control stamps
+// embedded in this binary, reporting whether there were any
+func readBuildInfo() (buildInfo, bool) {
+	b := buildInfo{GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b, false
+	}
+	b.Version = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Commit = s.Value
+		case "vcs.time":
+			b.CommitDate = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	return b, true
+}
+
+// buildVersion describes this binary from its build info: the module
+// version, or the commit it was built from
+func buildVersion() string {
+	b, ok := readBuildInfo()
+	if !ok {
+		return "unknown version"
+	}
+	version := b.Version
+	if (version == "" || version == "(devel)") && b.Commit != "" {
+		version = "commit " + b.Commit[:min(len(b.Commit), 12)]
+		if b.Modified {
+			version += " (modified)"
+		}
+	}
+	if version == "" {
+		version = "(devel)"
+	}
+	return version
+}
+
+// toolVersion is an external tool pk uses, as found on PATH
+type toolVersion struct {
+	Name    string `json:"name"`
+	Found   bool   `json:"found"`
+	Version string `json:"version,omitempty"`
+}
+
+// versionTools are the tools 'pk version' reports on: tmux, git and fzf,
+// plus the multiplexer and picker in use when they're something else
+func versionTools(s *settings.Settings) []toolVersion {
+	names := []string{"tmux", "git", "fzf"}
+	if s.Multiplexer == "zellij" {
+		names = append(names, "zellij")
+	}
+	if s.Picker.Backend == "sk" {
+		names = append(names, "sk")
+	}
+
+	var tools []toolVersion
+	for _, name := range names {
+		tool := toolVersion{Name: name, Found: deps.Available(name)}
+		if tool.Found {
+			if v, err := deps.Version(name); err == nil {
+				tool.Version = v
+			}
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// stateBackend names the [state] store in use, following store.Open
+func stateBackend(s *settings.Settings) string {
+	switch {
+	case s.State.Backend != "":
+		return s.State.Backend
+	case s.Cache.Backend == store.BackendSQLite:
+		return store.BackendSQLite + " (project index)"
+	}
+	return store.BackendJSON
+}
+
+func runVersion(cmd *cobra.Command, args []string) {
+	s := loadSettings()
+	build, _ := readBuildInfo()
+
+	type backends struct {
+		Cache  string `json:"cache"`
+		State  string `json:"state"`
+		Daemon string `json:"daemon"`
+	}
+	report := struct {
+		buildInfo
+		CacheSchema int           `json:"cache_schema"`
+		Backends    backends      `json:"backends"`
+		Tools       []toolVersion `json:"tools"`
+	}{
+		buildInfo:   build,
+		CacheSchema: cache.CacheVersion,
+		Backends:    backends{Cache: cache.BackendJSON, State: stateBackend(s), Daemon: "not installed"},
+		Tools:       versionTools(s),
+	}
+	if s.Cache.Backend == cache.BackendSQLite {
+		report.Backends.Cache = cache.BackendSQLite
+	}
+	if serviceFile, err := daemon.ServiceFile(); err != nil {
+		report.Backends.Daemon = "unsupported"
+	} else if _, err := os.Stat(serviceFile); err == nil {
+		report.Backends.Daemon = "installed"
+	}
+
+	if format := structuredFormat(cmd); format != "" {
+		printStructured(format, report)
+		return
+	}
+
+	// buildVersion names the commit itself for development builds
+	version := buildVersion()
+	var details []string
+	if build.Commit != "" && !strings.HasPrefix(version, "commit ") {
+		details = append(details, "commit "+build.Commit[:min(len(build.Commit), 12)])
+	}
+	if build.CommitDate != "" {
+		details = append(details, build.CommitDate)
+	}
+	fmt.Printf("\033[1mpk %s\033[0m", version)
+	if len(details) > 0 {
+		fmt.Printf(" (%s)", strings.Join(details, ", "))
+	}
+	fmt.Println()
+
+	fmt.Printf("  Go:            %s %s\n", report.GoVersion, report.Platform)
+	fmt.Printf("  Cache:         %s (schema %d)\n", report.Backends.Cache, report.CacheSchema)
+	fmt.Printf("  State:         %s\n", report.Backends.State)
+	fmt.Printf("  Daemon:        %s\n", report.Backends.Daemon)
+
+	fmt.Printf("\n\033[1mTools\033[0m\n")
+	for _, tool := range report.Tools {
+		version := tool.Version
+		switch {
+		case !tool.Found:
+			version = "\033[90mnot found\033[0m"
+		case version == "":
+			version = "\033[90munknown version\033[0m"
+		}
+		fmt.Printf("  %-14s %s\n", tool.Name, version)
+	}
+}
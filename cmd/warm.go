@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/datakaicr/pk/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var warmPinned bool
+
+var warmCmd = &cobra.Command{
+	Use:   "warm [project...]",
+	Short: "Create tmux sessions in the background so switching is instant",
+	Long: `Create tmux sessions for a set of projects without attaching to any of
+them, each with its layout and session environment, so the first switch of
+the morning is instant.
+
+Sessions that are already running are left alone, and a project that fails
+doesn't stop the rest. Nothing is recorded as accessed and no cloud CLI
+context is switched; that happens when you actually open the session.
+
+Run it from the login daemon with 'pk daemon install --warm-pinned'.
+
+Example:
+  pk warm --pinned             # Every pinned project
+  pk warm dojo api docs        # A fixed set
+  pk warm --pinned dojo        # Pins plus extras`,
+	Run:               runWarm,
+	ValidArgsFunction: validAllProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(warmCmd)
+	warmCmd.Flags().BoolVar(&warmPinned, "pinned", false, "Warm every pinned project")
+}
+
+func runWarm(cmd *cobra.Command, args []string) {
+	if !warmPinned && len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: Name projects to warm or use --pinned\n")
+		os.Exit(1)
+	}
+
+	if err := session.Check(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projects, missing := warmTargets(warmPinned, args)
+	if len(projects) == 0 && len(missing) == 0 {
+		fmt.Println("No pinned projects (pin some with 'pk pin add <project> <slot>')")
+		return
+	}
+
+	task := progress.Counter("Warming", len(projects)).Logged()
+	results := session.OpenAll(projects, func(done, total int, p *config.Project) {
+		task.Next(p.ProjectInfo.ID)
+	})
+	task.Stop()
+
+	if printSessionResults(results, missing, "Warmed") > 0 {
+		os.Exit(1)
+	}
+}
+
+// warmTargets resolves pins (in slot order) and named projects, skipping
+// duplicates. Pins whose project is gone and unknown names are returned
+// as missing.
+func warmTargets(pinned bool, names []string) ([]*config.Project, []string) {
+	candidates := openCandidates()
+
+	var projects []*config.Project
+	var missing []string
+	seen := make(map[string]bool)
+	add := func(p *config.Project) {
+		if !seen[p.Path] {
+			seen[p.Path] = true
+			projects = append(projects, p)
+		}
+	}
+
+	if pinned {
+		pins, err := cache.ListPins()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to load pins: %v\n", err)
+		}
+		for _, pin := range pins {
+			if p := findPinnedProject(candidates, pin); p != nil {
+				add(p)
+			} else {
+				missing = append(missing, pin.ProjectID)
+			}
+		}
+	}
+
+	for _, name := range names {
+		if p := findOpenTarget(candidates, name); p != nil {
+			add(p)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return projects, missing
+}
+
+// findPinnedProject matches a pin by path first, since IDs can be reused
+func findPinnedProject(projects []*config.Project, pin cache.PinRecord) *config.Project {
+	for _, p := range projects {
+		if p.Path == pin.ProjectPath {
+			return p
+		}
+	}
+	for _, p := range projects {
+		if strings.EqualFold(p.ProjectInfo.ID, pin.ProjectID) {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/shell"
+	"github.com/spf13/cobra"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <project|path>",
+	Short: "Explain how pk sees a project",
+	Long: `Explain how pk currently sees a project, as a debugging aid for
+"why isn't my project listed?".
+
+Reports:
+  - which root the project was found under, and which commands scan it
+  - whether the project cache has it, and how old the cache is
+  - legacy fields migrated on load, and fields inherited from
+    .project-defaults.toml
+  - which 'pk list' filters include or exclude it
+  - whether 'pk sync aliases' generates an alias for it, and why not
+
+The argument is a project ID or name, a directory name under a pk root, or
+a path to a directory inside a project.
+
+Example:
+  pk why dojo
+  pk why ~/projects/half-set-up
+  pk why .`,
+	Args:              cobra.ExactArgs(1),
+	Run:               runWhy,
+	ValidArgsFunction: validAllProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+}
+
+// whyRoot is a pk root and the commands that scan it
+type whyRoot struct {
+	Dir     string
+	Scanned string
+}
+
+func whyRoots(resolver *paths.Resolver) []whyRoot {
+	var roots []whyRoot
+	for _, dir := range listedRoots(resolver) {
+		roots = append(roots, whyRoot{dir, "list, show, session, aliases"})
+	}
+	return append(roots, whyRoot{resolver.Scriptorium(), "session, run (not list or aliases)"})
+}
+
+func runWhy(cmd *cobra.Command, args []string) {
+	resolver := projectPaths()
+	roots := whyRoots(resolver)
+
+	file := findWhyFile(args[0], roots)
+	if file == "" {
+		explainMissing(args[0], resolver, roots)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n\033[1mWhy: %s\033[0m\n\n", file)
+
+	// Location
+	fmt.Printf("\033[1mLocation\033[0m\n")
+	root := whyRootOf(filepath.Dir(file), roots)
+	if root == nil {
+		fmt.Printf("  Root:        \033[31mnone\033[0m - outside the projects, archive and scriptorium roots, so never scanned\n")
+		fmt.Printf("               Move it with 'pk promote %s --move'\n", filepath.Dir(file))
+	} else {
+		fmt.Printf("  Root:        %s\n", root.Dir)
+		fmt.Printf("  Scanned by:  %s\n", root.Scanned)
+	}
+	fmt.Printf("\n")
+
+	// Load
+	fmt.Printf("\033[1mLoad\033[0m\n")
+	project, err := config.LoadProject(file)
+	if err != nil {
+		fmt.Printf("  Parse:       \033[31mfailed\033[0m - %v\n", err)
+		fmt.Printf("               Malformed files are skipped by every scan; see 'pk validate %s'\n\n", file)
+		os.Exit(1)
+	}
+	fmt.Printf("  Parse:       \033[32mok\033[0m (id %s)\n", project.ProjectInfo.ID)
+	if changes := project.MigrationChanges(); len(changes) > 0 {
+		fmt.Printf("  Migrations:  %d legacy field(s) mapped on load ('pk migrate %s' rewrites them)\n",
+			len(changes), project.ProjectInfo.ID)
+		for _, c := range changes {
+			fmt.Printf("               %s\n", c)
+		}
+	} else {
+		fmt.Printf("  Migrations:  none\n")
+	}
+	if inherited := project.InheritedFields(); len(inherited) > 0 {
+		keys := make([]string, 0, len(inherited))
+		for key := range inherited {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Printf("  Inherited:   %d field(s) from %s\n", len(keys), config.DefaultsFile)
+		for _, key := range keys {
+			fmt.Printf("               %s (%s)\n", key, inherited[key].File)
+		}
+	}
+	fmt.Printf("\n")
+
+	explainCache(project)
+	explainDuplicates(project, roots)
+	explainListing(project, root, resolver)
+	explainAlias(project, root, resolver)
+}
+
+// findWhyFile resolves the argument to a .project.toml path, or "" if
+// there is none
+func findWhyFile(arg string, roots []whyRoot) string {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		if file := config.FindProjectFile(arg); file != "" {
+			return file
+		}
+	}
+
+	var dirs []string
+	for _, r := range roots {
+		dirs = append(dirs, r.Dir)
+	}
+	files, err := config.FindProjectFiles(dirs...)
+	if err != nil {
+		return ""
+	}
+
+	// ID or name first; fall back to the directory name, which also finds
+	// files too broken to parse
+	lower := strings.ToLower(arg)
+	byDir := ""
+	for _, file := range files {
+		if p, err := config.LoadProject(file); err == nil {
+			if strings.ToLower(p.ProjectInfo.ID) == lower || strings.ToLower(p.ProjectInfo.Name) == lower {
+				return file
+			}
+		}
+		if byDir == "" && strings.ToLower(filepath.Base(filepath.Dir(file))) == lower {
+			byDir = file
+		}
+	}
+	return byDir
+}
+
+// explainMissing reports why nothing matched the argument
+func explainMissing(arg string, resolver *paths.Resolver, roots []whyRoot) {
+	fmt.Fprintf(os.Stderr, "No .project.toml found for '%s'\n", arg)
+
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		abs, _ := filepath.Abs(arg)
+		fmt.Fprintf(os.Stderr, "  %s has no .project.toml at or above it\n", abs)
+		if whyRootOf(abs, roots) == nil {
+			fmt.Fprintf(os.Stderr, "  It is also outside the pk roots; 'pk promote %s --move' fixes both\n", abs)
+		} else {
+			fmt.Fprintf(os.Stderr, "  Create one with 'pk promote %s'\n", abs)
+		}
+		return
+	}
+
+	scratch := filepath.Join(resolver.Scratch(), arg)
+	if info, err := os.Stat(scratch); err == nil && info.IsDir() {
+		fmt.Fprintf(os.Stderr, "  %s is a scratch project: it opens with 'pk session' but is not listed\n", scratch)
+		fmt.Fprintf(os.Stderr, "  and gets no alias. 'pk promote %s' makes it a full project\n", arg)
+		return
+	}
+
+	for _, r := range roots {
+		fmt.Fprintf(os.Stderr, "  searched %s\n", r.Dir)
+	}
+}
+
+func whyRootOf(dir string, roots []whyRoot) *whyRoot {
+	for i, r := range roots {
+		if dir == r.Dir || insideAny(dir, []string{r.Dir}) {
+			return &roots[i]
+		}
+	}
+	return nil
+}
+
+// explainCache reports whether commands that read the cache see the project
+func explainCache(p *config.Project) {
+	fmt.Printf("\033[1mCache\033[0m\n")
+	defer fmt.Printf("\n")
+
+	modTime, err := cache.BuiltAt()
+	if err != nil {
+		fmt.Printf("  Status:      not built - session and completion scan the roots directly\n")
+		return
+	}
+
+	age := time.Since(modTime).Round(time.Second)
+	if cache.IsCacheValid() {
+		fmt.Printf("  Status:      fresh (%s old) - session and completion read from it\n", age)
+	} else {
+		fmt.Printf("  Status:      stale (%s old) - the next read rescans the roots\n", age)
+	}
+
+	cached, err := cache.LoadFromCache()
+	if err != nil {
+		fmt.Printf("  Entry:       \033[31munreadable cache\033[0m (%v)\n", err)
+		return
+	}
+	for _, c := range cached {
+		if c.Path != p.Path {
+			continue
+		}
+		if c.ProjectInfo.ID != p.ProjectInfo.ID {
+			fmt.Printf("  Entry:       \033[33mout of date\033[0m - cached as '%s' ('pk sync cache' to refresh)\n", c.ProjectInfo.ID)
+		} else {
+			fmt.Printf("  Entry:       \033[32mpresent\033[0m\n")
+		}
+		return
+	}
+	if cache.IsCacheValid() {
+		fmt.Printf("  Entry:       \033[33mmissing\033[0m - cached before this project existed ('pk sync cache' to refresh)\n")
+	} else {
+		fmt.Printf("  Entry:       missing, but the stale cache will be replaced on the next read\n")
+	}
+}
+
+// explainDuplicates warns when another project shares the ID
+func explainDuplicates(p *config.Project, roots []whyRoot) {
+	var dirs []string
+	for _, r := range roots {
+		dirs = append(dirs, r.Dir)
+	}
+	projects, err := config.FindProjects(dirs...)
+	if err != nil {
+		return
+	}
+
+	var others []string
+	for _, other := range projects {
+		if other.Path != p.Path && strings.EqualFold(other.ProjectInfo.ID, p.ProjectInfo.ID) {
+			others = append(others, other.Path)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	fmt.Printf("\033[1mDuplicates\033[0m\n")
+	fmt.Printf("  \033[33mid '%s' is also used by:\033[0m\n", p.ProjectInfo.ID)
+	for _, path := range others {
+		fmt.Printf("               %s\n", path)
+	}
+	fmt.Printf("  Lookups by name return whichever is found first, and aliases collide\n\n")
+}
+
+// explainListing reports which 'pk list' filters include the project
+func explainListing(p *config.Project, root *whyRoot, resolver *paths.Resolver) {
+	fmt.Printf("\033[1mListing (pk list)\033[0m\n")
+	defer fmt.Printf("\n")
+
+	if root == nil || !listScans(root.Dir, resolver) {
+		fmt.Printf("  \033[31mnot listed\033[0m - 'pk list' only scans the projects and archive roots\n")
+		return
+	}
+
+	fmt.Printf("  %-12s \033[32mincluded\033[0m\n", "(no filter)")
+	for _, filter := range listFilters {
+		if len(filterProjects([]*config.Project{p}, filter)) > 0 {
+			fmt.Printf("  %-12s \033[32mincluded\033[0m\n", filter)
+		} else {
+			fmt.Printf("  %-12s \033[2mexcluded (%s)\033[0m\n", filter, listExclusion(p, filter))
+		}
+	}
+}
+
+func listScans(dir string, resolver *paths.Resolver) bool {
+	return slices.Contains(listedRoots(resolver), dir)
+}
+
+// listExclusion names the field that keeps a project out of a list filter
+func listExclusion(p *config.Project, filter string) string {
+	show := func(v string) string {
+		if v == "" {
+			return "unset"
+		}
+		return "'" + v + "'"
+	}
+	switch filter {
+	case "active", "archived":
+		return "status is " + show(p.ProjectInfo.Status)
+	case "datakai", "westmonroe":
+		return "owner is " + show(p.GetOwner())
+	case "product", "client":
+		return "type is " + show(p.ProjectInfo.Type)
+	}
+	return "no match"
+}
+
+// explainAlias reports whether 'pk sync aliases' writes an alias
+func explainAlias(p *config.Project, root *whyRoot, resolver *paths.Resolver) {
+	fmt.Printf("\033[1mAlias (pk sync aliases)\033[0m\n")
+	defer fmt.Printf("\n")
+
+	switch {
+	case root == nil || !listScans(root.Dir, resolver):
+		fmt.Printf("  \033[31mnone\033[0m - aliases are only generated for the projects and archive roots\n")
+		return
+	case shell.AliasSkipReason(p) != "":
+		fmt.Printf("  \033[31mnone\033[0m - %s\n", shell.AliasSkipReason(p))
+		return
+	}
+
+	current := shell.Detect()
+	name := shell.AliasName(p, loadSettings())
+	fmt.Printf("  Generated:   %s (%s section)\n", name, shell.AliasSection(p))
+	if shell.HasAlias(current, name) {
+		fmt.Printf("  Alias file:  \033[32mpresent\033[0m in %s (%s)\n", shell.ConfigPath(current), current)
+	} else {
+		fmt.Printf("  Alias file:  \033[33mmissing\033[0m from %s ('pk sync aliases' to regenerate)\n",
+			shell.ConfigPath(current))
+	}
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Manage git worktrees for projects",
+	Long: `Create and list git worktrees for projects.
+
+Worktrees live in ~/worktrees/<project>/<branch>, outside the project roots,
+so their copy of .project.toml doesn't show up as a duplicate project.`,
+}
+
+var worktreeAddCmd = &cobra.Command{
+	Use:   "add <project> <branch>",
+	Short: "Check out a branch of a project in a new worktree",
+	Long: `Check out a branch in a new worktree under ~/worktrees/<project>/<branch>.
+
+Existing local or remote branches are checked out (remote branches get a
+tracking branch); unknown names create a new branch from HEAD.
+
+<TAB> after the project completes branch names from 'git ls-remote origin'
+(cached for 10 minutes).
+
+Example:
+  pk worktree add dojo feature/login
+  pk worktree add dojo <TAB>`,
+	Args:              cobra.ExactArgs(2),
+	Run:               runWorktreeAdd,
+	ValidArgsFunction: validWorktreeAddArgs,
+}
+
+var worktreeListCmd = &cobra.Command{
+	Use:               "list <project>",
+	Short:             "List worktrees of a project",
+	Args:              cobra.ExactArgs(1),
+	Run:               runWorktreeList,
+	ValidArgsFunction: validProjectNames,
+}
+
+func init() {
+	rootCmd.AddCommand(worktreeCmd)
+	worktreeCmd.AddCommand(worktreeAddCmd)
+	worktreeCmd.AddCommand(worktreeListCmd)
+}
+
+// findGitProject looks up a project by ID or name and checks it is a git repo
+func findGitProject(name string) *config.Project {
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to find projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	name = strings.ToLower(name)
+	for _, p := range projects {
+		if strings.ToLower(p.ProjectInfo.ID) == name || strings.ToLower(p.ProjectInfo.Name) == name {
+			if _, err := os.Stat(filepath.Join(p.Path, ".git")); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Project '%s' is not a git repository\n", p.ProjectInfo.ID)
+				os.Exit(1)
+			}
+			return p
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: Project '%s' not found\n", name)
+	os.Exit(1)
+	return nil
+}
+
+func runWorktreeAdd(cmd *cobra.Command, args []string) {
+	project := findGitProject(args[0])
+	branch := args[1]
+
+	homeDir, _ := os.UserHomeDir()
+	worktreePath := filepath.Join(homeDir, "worktrees", project.ProjectInfo.ID, strings.ReplaceAll(branch, "/", "-"))
+
+	if _, err := os.Stat(worktreePath); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: Worktree already exists at %s\n", worktreePath)
+		os.Exit(1)
+	}
+
+	gitArgs := []string{"-C", project.Path, "worktree", "add"}
+	if !branchExists(project.Path, branch) {
+		// New branch from HEAD
+		gitArgs = append(gitArgs, "-b", branch, worktreePath)
+	} else {
+		gitArgs = append(gitArgs, worktreePath, branch)
+	}
+
+	gitCmd := runner.Command("git", gitArgs...)
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := runner.Run(gitCmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: git worktree add failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\033[32m✓\033[0m Worktree for %s at %s\n", branch, worktreePath)
+}
+
+// branchExists reports whether branch exists locally or on origin
+func branchExists(repoPath, branch string) bool {
+	if runner.Run(runner.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)) == nil {
+		return true
+	}
+
+	remote, err := git.CachedRemoteBranches(repoPath, "origin")
+	if err != nil {
+		return false
+	}
+	for _, b := range remote {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+func runWorktreeList(cmd *cobra.Command, args []string) {
+	project := findGitProject(args[0])
+
+	gitCmd := runner.Command("git", "-C", project.Path, "worktree", "list")
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := runner.Run(gitCmd); err != nil {
+		os.Exit(1)
+	}
+}
+
+// validWorktreeAddArgs completes the project, then its remote branch names
+func validWorktreeAddArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return validProjectNames(cmd, args, toComplete)
+	}
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	projects, err := cache.FindProjectsCached(cacheRoots()...)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	for _, p := range projects {
+		if strings.EqualFold(p.ProjectInfo.ID, args[0]) {
+			branches, err := git.CachedRemoteBranches(p.Path, "origin")
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return filterPrefix(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
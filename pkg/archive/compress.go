@@ -0,0 +1,153 @@
+// Package archive packs archived projects into a compressed tarball of
+// their working tree, kept in the project directory next to the metadata
+// so the project is still listed, and unpacks them again
+package archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Ext is the extension of a packed project's tarball
+const Ext = ".tar.zst"
+
+// Tarball returns where dir's tarball goes: <dir>/<name>.tar.zst
+func Tarball(dir string) string {
+	return filepath.Join(dir, filepath.Base(dir)+Ext)
+}
+
+// ChecksumFile returns where a tarball's SHA-256 is recorded
+func ChecksumFile(tarball string) string {
+	return tarball + ".sha256"
+}
+
+// Compressed reports whether dir holds a packed project
+func Compressed(dir string) bool {
+	_, err := os.Stat(Tarball(dir))
+	return err == nil
+}
+
+// Compress packs dir's contents, except the files named in keep (e.g. the
+// metadata file), into Tarball(dir) with tar --zstd, records its checksum,
+// then removes what it packed. Nothing is removed if packing fails.
+func Compress(dir string, keep ...string) (string, error) {
+	if Compressed(dir) {
+		return "", fmt.Errorf("%s is already compressed", dir)
+	}
+	tarball := Tarball(dir)
+	keep = append(keep, filepath.Base(tarball), filepath.Base(ChecksumFile(tarball)))
+
+	// Pack next to dir so the tarball doesn't try to include itself
+	tmp := filepath.Join(filepath.Dir(dir), "."+filepath.Base(tarball)+".tmp")
+	args := []string{"--zstd", "-cf", tmp, "-C", dir}
+	for _, name := range keep {
+		args = append(args, "--exclude=./"+name)
+	}
+	args = append(args, ".")
+	tar := runner.Command("tar", args...)
+	var stderr bytes.Buffer
+	tar.Stderr = &stderr
+	if err := runner.Run(tar); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("tar failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	sum, err := Checksum(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, tarball); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.WriteFile(ChecksumFile(tarball), []byte(sum+"  "+filepath.Base(tarball)+"\n"), 0644); err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if slices.Contains(keep, e.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return "", fmt.Errorf("packed into %s, but failed to remove %s: %w", tarball, e.Name(), err)
+		}
+	}
+	return tarball, nil
+}
+
+// Checksum returns a file's SHA-256, hex encoded
+func Checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Extract unpacks a compressed project back into its directory, checking
+// the tarball against its recorded checksum first, then removes the tarball
+func Extract(dir string) error {
+	tarball := Tarball(dir)
+	if !Compressed(dir) {
+		return fmt.Errorf("%s is not compressed", dir)
+	}
+	if err := VerifyChecksum(tarball); err != nil {
+		return err
+	}
+
+	tar := runner.Command("tar", "--zstd", "-xf", tarball, "-C", dir)
+	var stderr bytes.Buffer
+	tar.Stderr = &stderr
+	if err := runner.Run(tar); err != nil {
+		return fmt.Errorf("tar failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := os.Remove(tarball); err != nil {
+		return err
+	}
+	if err := os.Remove(ChecksumFile(tarball)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// VerifyChecksum compares a tarball with its .sha256 file. A tarball
+// without one passes.
+func VerifyChecksum(tarball string) error {
+	data, err := os.ReadFile(ChecksumFile(tarball))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	want, _, _ := strings.Cut(strings.TrimSpace(string(data)), " ")
+	got, err := Checksum(tarball)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("%s doesn't match its checksum (got %s, recorded %s)", filepath.Base(tarball), got, want)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package archive
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeZstd puts a zstd on PATH that is really gzip, for tar --zstd
+func fakeZstd(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("zstd"); err == nil {
+		return
+	}
+	gzip, err := exec.LookPath("gzip")
+	if err != nil {
+		t.Skip("neither zstd nor gzip installed")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\nexec " + gzip + " \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "zstd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestCompress(t *testing.T) {
+	fakeZstd(t)
+	dir := filepath.Join(t.TempDir(), "acme")
+	for name, content := range map[string]string{
+		".project.toml": "[project]\nid = \"acme\"\n",
+		"main.go":       "package main\n",
+		"src/lib.go":    "package src\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tarball, err := Compress(dir, ".project.toml")
+	if err != nil {
+		t.Fatalf("Compress: %v", err)
+	}
+	if tarball != filepath.Join(dir, "acme.tar.zst") || !Compressed(dir) {
+		t.Errorf("tarball = %s, want it in the project directory", tarball)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, " "); got != ".project.toml acme.tar.zst acme.tar.zst.sha256" {
+		t.Errorf("left in the directory: %s", got)
+	}
+
+	sum, err := Checksum(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, _ := os.ReadFile(ChecksumFile(tarball))
+	if string(recorded) != sum+"  acme.tar.zst\n" {
+		t.Errorf("checksum file = %q, want %s", recorded, sum)
+	}
+
+	listing, err := exec.Command("tar", "--zstd", "-tf", tarball).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(listing), "src/lib.go") || strings.Contains(string(listing), ".project.toml") {
+		t.Errorf("tarball holds:\n%s", listing)
+	}
+
+	if _, err := Compress(dir, ".project.toml"); err == nil {
+		t.Error("compressing twice should fail")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	fakeZstd(t)
+	dir := filepath.Join(t.TempDir(), "acme")
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, ".project.toml"), []byte("[project]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "lib.go"), []byte("package src\n"), 0644)
+
+	tarball, err := Compress(dir, ".project.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A corrupted tarball is refused and left alone
+	os.WriteFile(ChecksumFile(tarball), []byte("0000  acme.tar.zst\n"), 0644)
+	if err := Extract(dir); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Extract with a bad checksum: %v", err)
+	}
+	os.Remove(ChecksumFile(tarball))
+
+	if err := Extract(dir); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "src", "lib.go")); err != nil || string(data) != "package src\n" {
+		t.Errorf("src/lib.go = %q, %v", data, err)
+	}
+	if Compressed(dir) {
+		t.Error("the tarball should be removed")
+	}
+	if err := Extract(dir); err == nil {
+		t.Error("extracting an unpacked project should fail")
+	}
+}
@@ -0,0 +1,106 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/store"
+)
+
+// Verification is the outcome of checking one archived project
+type Verification struct {
+	ID         string    `json:"id"`
+	Path       string    `json:"path"`
+	Compressed bool      `json:"compressed"`
+	Checksum   string    `json:"checksum,omitempty"` // Tarball's SHA-256, when compressed
+	VerifiedAt time.Time `json:"verified_at"`
+	Problems   []string  `json:"problems,omitempty"`
+}
+
+// OK reports whether the project passed every check
+func (v Verification) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// Verify checks an archived project: its metadata names it and records it
+// as archived and completed, and a compressed project's tarball matches its
+// checksum and reads to the end
+func Verify(p *config.Project, now time.Time) Verification {
+	v := Verification{ID: p.ProjectInfo.ID, Path: p.Path, VerifiedAt: now}
+	problem := func(format string, args ...any) {
+		v.Problems = append(v.Problems, fmt.Sprintf(format, args...))
+	}
+
+	if _, err := os.Stat(p.File()); err != nil {
+		problem("metadata file missing: %s", p.File())
+	}
+	if p.ProjectInfo.ID == "" || p.ProjectInfo.Name == "" {
+		problem("metadata has no project.id or project.name")
+	}
+	if p.ProjectInfo.Status != "archived" {
+		problem("status is %q, not archived", p.ProjectInfo.Status)
+	}
+	if p.Dates.Completed == "" {
+		problem("no dates.completed")
+	}
+
+	if !Compressed(p.Path) {
+		if _, err := os.Stat(p.Path); err != nil {
+			problem("directory missing: %v", err)
+		}
+		return v
+	}
+
+	v.Compressed = true
+	tarball := Tarball(p.Path)
+	if _, err := os.Stat(ChecksumFile(tarball)); err != nil {
+		problem("no checksum recorded (%s)", ChecksumFile(tarball))
+	} else if err := VerifyChecksum(tarball); err != nil {
+		problem("%v", err)
+	}
+	if sum, err := Checksum(tarball); err == nil {
+		v.Checksum = sum
+	}
+
+	list := runner.Command("tar", "--zstd", "-tf", tarball)
+	var stderr bytes.Buffer
+	list.Stdout = io.Discard
+	list.Stderr = &stderr
+	if err := runner.Run(list); err != nil {
+		problem("tarball unreadable: %v: %s", err, strings.ReplaceAll(strings.TrimSpace(stderr.String()), "\n", "; "))
+	}
+	return v
+}
+
+// verificationsDocument is the state document holding the last
+// verification of each archived project, by project ID
+const verificationsDocument = "archive-verifications"
+
+// LoadVerifications returns the last verification of each archived
+// project, by ID
+func LoadVerifications() (map[string]Verification, error) {
+	verifications := make(map[string]Verification)
+	if err := store.LoadJSON(store.Default(), verificationsDocument, &verifications); err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// RecordVerifications saves verifications over the previous ones for the
+// same projects
+func RecordVerifications(vs []Verification) error {
+	verifications, err := LoadVerifications()
+	if err != nil {
+		return err
+	}
+	for _, v := range vs {
+		verifications[v.ID] = v
+	}
+	return store.SaveJSON(store.Default(), verificationsDocument, verifications)
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func archivedProject(t *testing.T) *config.Project {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "acme")
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "src", "lib.go"), []byte("package src\n"), 0644)
+
+	p := &config.Project{Path: dir}
+	p.ProjectInfo.ID = "acme"
+	p.ProjectInfo.Name = "Acme"
+	p.ProjectInfo.Status = "archived"
+	p.Dates.Completed = "2026-01-31"
+	if err := p.SaveAs(p.File()); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestVerify(t *testing.T) {
+	fakeZstd(t)
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	p := archivedProject(t)
+	if v := Verify(p, now); !v.OK() || v.Compressed {
+		t.Errorf("uncompressed project: %+v", v)
+	}
+
+	tarball, err := Compress(p.Path, filepath.Base(p.File()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := Verify(p, now)
+	if !v.OK() || !v.Compressed || v.Checksum == "" {
+		t.Errorf("compressed project: %+v", v)
+	}
+
+	// Bit rot: the tarball no longer matches its checksum, nor reads
+	data, _ := os.ReadFile(tarball)
+	data[len(data)/2] ^= 0xff
+	os.WriteFile(tarball, data, 0644)
+	p.ProjectInfo.Status = "active"
+	v = Verify(p, now)
+	problems := strings.Join(v.Problems, "; ")
+	for _, want := range []string{"not archived", "checksum", "unreadable"} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("problems %q should mention %q", problems, want)
+		}
+	}
+}
+
+func TestRecordVerifications(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	first := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := RecordVerifications([]Verification{{ID: "acme", VerifiedAt: first}, {ID: "dojo", VerifiedAt: first}}); err != nil {
+		t.Fatal(err)
+	}
+	later := first.AddDate(0, 0, 14)
+	if err := RecordVerifications([]Verification{{ID: "acme", VerifiedAt: later, Problems: []string{"x"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadVerifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got["acme"].VerifiedAt.Equal(later) || got["acme"].OK() || !got["dojo"].VerifiedAt.Equal(first) {
+		t.Errorf("verifications = %+v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package bench
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Thresholds above which a measurement earns a tuning suggestion
+const (
+	SlowDiscovery = 200 * time.Millisecond
+	SlowCacheLoad = 50 * time.Millisecond
+	SlowPicker    = 300 * time.Millisecond
+)
+
+// heavyDirs are directory names that commonly bloat discovery walks
+var heavyDirs = map[string]bool{
+	"node_modules": true,
+	".git":         true,
+	".venv":        true,
+	"venv":         true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"__pycache__":  true,
+}
+
+// Stats summarizes repeated timings of one operation
+type Stats struct {
+	Runs int
+	Min  time.Duration
+	Avg  time.Duration
+	Max  time.Duration
+}
+
+// String formats stats as "min / avg / max"
+func (s Stats) String() string {
+	return fmt.Sprintf("%8s min  %8s avg  %8s max",
+		s.Min.Round(time.Microsecond*100), s.Avg.Round(time.Microsecond*100), s.Max.Round(time.Microsecond*100))
+}
+
+// Measure runs fn the given number of times and returns timing stats.
+// The first error aborts measurement.
+func Measure(runs int, fn func() error) (Stats, error) {
+	if runs < 1 {
+		runs = 1
+	}
+
+	stats := Stats{Runs: runs}
+	var total time.Duration
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		if err := fn(); err != nil {
+			return stats, err
+		}
+		elapsed := time.Since(start)
+
+		total += elapsed
+		if i == 0 || elapsed < stats.Min {
+			stats.Min = elapsed
+		}
+		if elapsed > stats.Max {
+			stats.Max = elapsed
+		}
+	}
+	stats.Avg = total / time.Duration(runs)
+	return stats, nil
+}
+
+// WalkStats counts directories a discovery walk visits, and how many of them
+// sit inside dependency/build directories
+type WalkStats struct {
+	Dirs      int
+	HeavyDirs int            // Directories inside heavy trees
+	Heavy     map[string]int // Heavy directory name -> occurrences
+}
+
+// Walk gathers WalkStats for the given roots
+func Walk(rootDirs ...string) WalkStats {
+	stats := WalkStats{Heavy: make(map[string]int)}
+
+	for _, root := range rootDirs {
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			stats.Dirs++
+
+			if heavyDirs[d.Name()] {
+				stats.Heavy[d.Name()]++
+			}
+			return nil
+		})
+
+		// Second pass counts everything below heavy directories
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if heavyDirs[d.Name()] {
+				stats.HeavyDirs += countDirs(path)
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+
+	return stats
+}
+
+// countDirs counts directories in a tree, including the root
+func countDirs(root string) int {
+	count := 0
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// Report is the full set of measurements for one machine
+type Report struct {
+	Projects        int
+	Discovery       Stats
+	CacheLoad       Stats
+	Picker          Stats
+	Walk            WalkStats
+	CacheValid      bool
+	DaemonInstalled bool
+}
+
+// Suggestions returns tuning advice for the measured setup
+func (r Report) Suggestions() []string {
+	var suggestions []string
+
+	if r.Discovery.Avg > SlowDiscovery {
+		if r.Walk.Dirs > 0 && r.Walk.HeavyDirs*2 > r.Walk.Dirs {
+			suggestions = append(suggestions, fmt.Sprintf(
+				"%d of %d directories walked during discovery are inside dependency/build trees "+
+					"(node_modules, .venv, target...); keeping them out of project roots speeds up every rescan",
+				r.Walk.HeavyDirs, r.Walk.Dirs))
+		}
+		if !r.DaemonInstalled {
+			suggestions = append(suggestions,
+				"Discovery is slow; 'pk daemon install' keeps the cache warm so pickers never wait for a rescan")
+		}
+	}
+
+	if !r.CacheValid && !r.DaemonInstalled {
+		suggestions = append(suggestions,
+			"The cache was stale, so the next picker would have rescanned; run 'pk cache refresh' or install the daemon")
+	}
+
+	if r.CacheLoad.Avg > SlowCacheLoad {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"Loading the cache takes %s for %d projects; archiving inactive projects keeps it small",
+			r.CacheLoad.Avg.Round(time.Millisecond), r.Projects))
+	}
+
+	if r.Picker.Avg > SlowPicker && r.CacheLoad.Avg <= SlowCacheLoad {
+		suggestions = append(suggestions,
+			"Picker setup is slow even though the cache is fast; check 'tmux list-sessions' responsiveness")
+	}
+
+	return suggestions
+}
@@ -0,0 +1,80 @@
+package bench
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMeasure(t *testing.T) {
+	calls := 0
+	stats, err := Measure(3, func() error {
+		calls++
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Measure failed: %v", err)
+	}
+	if calls != 3 || stats.Runs != 3 {
+		t.Errorf("Expected 3 runs, got calls=%d runs=%d", calls, stats.Runs)
+	}
+	if stats.Min > stats.Avg || stats.Avg > stats.Max {
+		t.Errorf("Inconsistent stats: %+v", stats)
+	}
+
+	if _, err := Measure(2, func() error { return errors.New("boom") }); err == nil {
+		t.Error("Expected error to propagate")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"app/src",
+		"app/node_modules/a/lib",
+		"app/node_modules/b",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	stats := Walk(root)
+	// root, app, src, node_modules, a, lib, b
+	if stats.Dirs != 7 {
+		t.Errorf("Expected 7 dirs, got %d", stats.Dirs)
+	}
+	// node_modules, a, lib, b
+	if stats.HeavyDirs != 4 {
+		t.Errorf("Expected 4 heavy dirs, got %d", stats.HeavyDirs)
+	}
+	if stats.Heavy["node_modules"] != 1 {
+		t.Errorf("Expected 1 node_modules, got %v", stats.Heavy)
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	fast := Report{
+		Discovery:  Stats{Avg: 10 * time.Millisecond},
+		CacheLoad:  Stats{Avg: time.Millisecond},
+		Picker:     Stats{Avg: 5 * time.Millisecond},
+		CacheValid: true,
+	}
+	if s := fast.Suggestions(); len(s) != 0 {
+		t.Errorf("Expected no suggestions for fast setup, got %v", s)
+	}
+
+	slow := Report{
+		Discovery: Stats{Avg: time.Second},
+		CacheLoad: Stats{Avg: time.Millisecond},
+		Walk:      WalkStats{Dirs: 1000, HeavyDirs: 900},
+	}
+	joined := strings.Join(slow.Suggestions(), "\n")
+	if !strings.Contains(joined, "node_modules") || !strings.Contains(joined, "pk daemon install") {
+		t.Errorf("Expected heavy-dir and daemon suggestions, got:\n%s", joined)
+	}
+}
@@ -2,57 +2,148 @@ package cache
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
+	"errors"
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
 	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/statefile"
+	"github.com/datakaicr/pk/pkg/store"
 )
 
-// AccessRecord tracks when a project was last accessed
+// AccessRecord tracks when and how often a project is accessed
 type AccessRecord struct {
 	ProjectID    string    `json:"project_id"`
 	ProjectPath  string    `json:"project_path"`
 	LastAccessed time.Time `json:"last_accessed"`
+	AccessCount  int       `json:"access_count,omitempty"` // 0 in records from before counts were kept
+
+	// Opens per local day ("2006-01-02"), kept for AccessHistoryDays
+	Days map[string]int `json:"days,omitempty"`
 }
 
-// GetAccessFile returns the path to the access tracking file
-func GetAccessFile() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+// AccessHistoryDays is how long per-day access counts are kept
+const AccessHistoryDays = 365
+
+// DayKey is the Days key for t's local date
+func DayKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// Frecency scores a record by how often and how recently it was accessed,
+// weighting the count by the age of the last access as zoxide does
+func (r AccessRecord) Frecency(now time.Time) float64 {
+	count := float64(max(r.AccessCount, 1))
+	switch age := now.Sub(r.LastAccessed); {
+	case age < time.Hour:
+		return count * 4
+	case age < 24*time.Hour:
+		return count * 2
+	case age < 7*24*time.Hour:
+		return count / 2
+	default:
+		return count / 4
 	}
+}
 
-	cacheDir := filepath.Join(homeDir, ".cache", "pk")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
+// SortByFrecency orders projects by frecency, highest first. Projects never
+// accessed keep their relative order at the end.
+func SortByFrecency(projects []*config.Project, records map[string]AccessRecord) {
+	now := time.Now()
+	score := func(p *config.Project) float64 {
+		if record, ok := records[p.ProjectInfo.ID]; ok {
+			return record.Frecency(now)
+		}
+		return 0
 	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return score(projects[i]) > score(projects[j])
+	})
+}
 
-	return filepath.Join(cacheDir, "access.json"), nil
+// visit records one access in records
+func visit(records map[string]AccessRecord, projectID, projectPath string) {
+	now := time.Now()
+	count := 1
+	days := make(map[string]int)
+	if existing, ok := records[projectID]; ok {
+		count = max(existing.AccessCount, 1) + 1
+		cutoff := DayKey(now.AddDate(0, 0, -AccessHistoryDays))
+		for day, n := range existing.Days {
+			if day > cutoff {
+				days[day] = n
+			}
+		}
+	}
+	days[DayKey(now)]++
+
+	records[projectID] = AccessRecord{
+		ProjectID:    projectID,
+		ProjectPath:  projectPath,
+		LastAccessed: now,
+		AccessCount:  count,
+		Days:         days,
+	}
 }
 
-// LoadAccessRecords reads the access tracking file and validates paths
-// Automatically heals stale paths by searching for projects
-func LoadAccessRecords() (map[string]AccessRecord, error) {
-	accessFile, err := GetAccessFile()
+// AccessVersion is the layout of the access history. Unlike the project
+// cache it is never discarded: older layouts are migrated when read.
+const AccessVersion = 1
+
+// accessFile is the layout of the access document. Before versioning it was
+// the bare records map, read as version 0.
+type accessFile struct {
+	Version int                     `json:"version"`
+	Records map[string]AccessRecord `json:"records"`
+}
+
+// loadAccessFile reads the access document in any layout up to AccessVersion.
+// History from a newer pk is refused rather than rewritten without the
+// fields this binary doesn't know.
+func loadAccessFile() (map[string]AccessRecord, error) {
+	records := make(map[string]AccessRecord)
+	data, err := store.Default().Get("access")
+	if errors.Is(err, store.ErrNotFound) {
+		return records, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(accessFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return make(map[string]AccessRecord), nil
+	var doc accessFile
+	if err := json.Unmarshal(data, &doc); err == nil && doc.Version > 0 {
+		if doc.Version > AccessVersion {
+			return nil, fmt.Errorf("access history is version %d but this pk reads up to %d; upgrade pk", doc.Version, AccessVersion)
 		}
-		return nil, err
+		if doc.Records != nil {
+			records = doc.Records
+		}
+		return records, nil
 	}
 
-	var records map[string]AccessRecord
 	if err := json.Unmarshal(data, &records); err != nil {
 		return nil, err
 	}
+	return records, nil
+}
+
+// LoadAccessRecords reads the access tracking file and validates paths
+// Automatically heals stale paths by searching for projects
+func LoadAccessRecords() (map[string]AccessRecord, error) {
+	defer statefile.Lock("access")()
+	return loadAccessRecords()
+}
+
+// loadAccessRecords is LoadAccessRecords for callers already holding the
+// access lock
+func loadAccessRecords() (map[string]AccessRecord, error) {
+	records, err := loadAccessFile()
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate and heal paths
 	healed, err := validateAndHealAccessRecords(records)
@@ -97,36 +188,65 @@ func validateAndHealAccessRecords(records map[string]AccessRecord) (bool, error)
 
 // SaveAccessRecords writes the access tracking file
 func SaveAccessRecords(records map[string]AccessRecord) error {
-	accessFile, err := GetAccessFile()
+	return store.SaveJSON(store.Default(), "access", accessFile{Version: AccessVersion, Records: records})
+}
+
+// RecordAccess marks a project as accessed now
+func RecordAccess(projectID, projectPath string) error {
+	defer statefile.Lock("access")()
+
+	records, err := loadAccessRecords()
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(records, "", "  ")
+	visit(records, projectID, projectPath)
+	events.Emit(events.AccessRecorded, projectID, projectPath, nil)
+
+	return SaveAccessRecords(records)
+}
+
+// TouchAccess records access unless the project was already recorded within
+// minInterval, keeping frequent callers like the shell cd hook cheap.
+// Returns true if a new access was recorded.
+func TouchAccess(projectID, projectPath string, minInterval time.Duration) (bool, error) {
+	defer statefile.Lock("access")()
+
+	records, err := loadAccessRecords()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return os.WriteFile(accessFile, data, 0644)
+	if record, exists := records[projectID]; exists &&
+		record.ProjectPath == projectPath &&
+		time.Since(record.LastAccessed) < minInterval {
+		return false, nil
+	}
+
+	visit(records, projectID, projectPath)
+	events.Emit(events.AccessRecorded, projectID, projectPath, map[string]string{"source": "cd"})
+
+	return true, SaveAccessRecords(records)
 }
 
-// RecordAccess marks a project as accessed now
-func RecordAccess(projectID, projectPath string) error {
-	records, err := LoadAccessRecords()
+// RemoveAccessRecord deletes the access history for a project
+func RemoveAccessRecord(projectID string) error {
+	defer statefile.Lock("access")()
+
+	records, err := loadAccessRecords()
 	if err != nil {
 		return err
 	}
 
-	records[projectID] = AccessRecord{
-		ProjectID:    projectID,
-		ProjectPath:  projectPath,
-		LastAccessed: time.Now(),
+	if _, exists := records[projectID]; !exists {
+		return nil
 	}
 
+	delete(records, projectID)
 	return SaveAccessRecords(records)
 }
 
-// GetRecentProjects returns projects sorted by access time (most recent first)
+// GetRecentProjects returns accessed projects by frecency (most used first)
 func GetRecentProjects(limit int) ([]*config.Project, error) {
 	// Load access records
 	records, err := LoadAccessRecords()
@@ -135,37 +255,17 @@ func GetRecentProjects(limit int) ([]*config.Project, error) {
 	}
 
 	// Load all projects
-	homeDir, err := os.UserHomeDir()
+	resolver, err := paths.NewResolver()
 	if err != nil {
 		return nil, err
 	}
 
-	projects, err := FindProjectsCached(
-		filepath.Join(homeDir, "projects"),
-		filepath.Join(homeDir, "scratch"),
-	)
+	projects, err := FindProjectsCached(append(resolver.AllRoots(), resolver.Scratch())...)
 	if err != nil {
 		return nil, err
 	}
 
-	// Sort projects by access time
-	sort.Slice(projects, func(i, j int) bool {
-		accessI, okI := records[projects[i].ProjectInfo.ID]
-		accessJ, okJ := records[projects[j].ProjectInfo.ID]
-
-		// Projects never accessed go to the end
-		if !okI && !okJ {
-			return false
-		}
-		if !okI {
-			return false
-		}
-		if !okJ {
-			return true
-		}
-
-		return accessI.LastAccessed.After(accessJ.LastAccessed)
-	})
+	SortByFrecency(projects, records)
 
 	// Apply limit
 	if limit > 0 && limit < len(projects) {
@@ -3,8 +3,13 @@ package cache
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
 )
 
 func TestRecordAccess(t *testing.T) {
@@ -175,4 +180,246 @@ func TestUpdateExistingAccess(t *testing.T) {
 	if len(records2) != 1 {
 		t.Errorf("Expected 1 record, got %d", len(records2))
 	}
+
+	if count := records2[projectID].AccessCount; count != 2 {
+		t.Errorf("Expected access count 2, got %d", count)
+	}
+}
+
+func TestRemoveAccessRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	testHome := filepath.Join(tmpDir, "home")
+	os.Setenv("HOME", testHome)
+	defer os.Setenv("HOME", originalHome)
+
+	if err := RecordAccess("keep", "/path/to/keep"); err != nil {
+		t.Fatalf("RecordAccess failed: %v", err)
+	}
+	if err := RecordAccess("drop", "/path/to/drop"); err != nil {
+		t.Fatalf("RecordAccess failed: %v", err)
+	}
+
+	if err := RemoveAccessRecord("drop"); err != nil {
+		t.Fatalf("RemoveAccessRecord failed: %v", err)
+	}
+
+	// Removing an unknown project is not an error
+	if err := RemoveAccessRecord("missing"); err != nil {
+		t.Fatalf("RemoveAccessRecord should ignore unknown projects: %v", err)
+	}
+
+	records, err := LoadAccessRecords()
+	if err != nil {
+		t.Fatalf("LoadAccessRecords failed: %v", err)
+	}
+
+	if _, exists := records["drop"]; exists {
+		t.Error("Record for 'drop' should have been removed")
+	}
+	if _, exists := records["keep"]; !exists {
+		t.Error("Record for 'keep' should still exist")
+	}
+}
+
+func TestTouchAccess(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	projectPath := filepath.Join(tmpDir, "projects", "acme")
+	if err := os.MkdirAll(projectPath, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	recorded, err := TouchAccess("acme", projectPath, time.Minute)
+	if err != nil || !recorded {
+		t.Fatalf("Expected first touch to record access, got %v, %v", recorded, err)
+	}
+
+	// Within the interval: no new write
+	recorded, err = TouchAccess("acme", projectPath, time.Minute)
+	if err != nil || recorded {
+		t.Errorf("Expected debounced touch to skip, got %v, %v", recorded, err)
+	}
+
+	// Zero interval always records
+	recorded, err = TouchAccess("acme", projectPath, 0)
+	if err != nil || !recorded {
+		t.Errorf("Expected touch with zero interval to record, got %v, %v", recorded, err)
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Now()
+	daily := AccessRecord{AccessCount: 20, LastAccessed: now.Add(-3 * time.Hour)}
+	justNow := AccessRecord{AccessCount: 1, LastAccessed: now.Add(-time.Minute)}
+	stale := AccessRecord{AccessCount: 50, LastAccessed: now.Add(-90 * 24 * time.Hour)}
+	legacy := AccessRecord{LastAccessed: now.Add(-time.Minute)} // no count yet
+
+	if !(daily.Frecency(now) > justNow.Frecency(now)) {
+		t.Error("a project used all week should outrank one opened once just now")
+	}
+	if !(daily.Frecency(now) > stale.Frecency(now)) {
+		t.Error("heavy use months ago should fade below current use")
+	}
+	if legacy.Frecency(now) != justNow.Frecency(now) {
+		t.Error("a record without a count should score as one access")
+	}
+}
+
+func TestSortByFrecency(t *testing.T) {
+	now := time.Now()
+	records := map[string]AccessRecord{
+		"daily":  {AccessCount: 20, LastAccessed: now.Add(-3 * time.Hour)},
+		"once":   {AccessCount: 1, LastAccessed: now.Add(-time.Minute)},
+		"unused": {AccessCount: 2, LastAccessed: now.Add(-400 * 24 * time.Hour)},
+	}
+	var projects []*config.Project
+	for _, id := range []string{"never-a", "once", "never-b", "unused", "daily"} {
+		p := &config.Project{}
+		p.ProjectInfo.ID = id
+		projects = append(projects, p)
+	}
+
+	SortByFrecency(projects, records)
+
+	var got []string
+	for _, p := range projects {
+		got = append(got, p.ProjectInfo.ID)
+	}
+	want := []string{"daily", "once", "unused", "never-a", "never-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+}
+
+func TestLoadAccessRecordsMigratesLegacy(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	cacheDir := filepath.Join(tmpDir, ".cache", "pk")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	accessFile := filepath.Join(cacheDir, "access.json")
+
+	// Before versioning the file was the bare records map
+	legacy := `{"acme": {"project_id": "acme", "project_path": "/p/acme", "last_accessed": "2026-01-02T03:04:05Z", "access_count": 7}}`
+	if err := os.WriteFile(accessFile, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadAccessRecords()
+	if err != nil {
+		t.Fatalf("LoadAccessRecords: %v", err)
+	}
+	if records["acme"].AccessCount != 7 {
+		t.Fatalf("legacy record not read: %+v", records)
+	}
+
+	// Saving writes the versioned layout, and keeps the history
+	if err := SaveAccessRecords(records); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(accessFile)
+	if !strings.Contains(string(data), `"version": 1`) {
+		t.Errorf("access.json not versioned:\n%s", data)
+	}
+	if records, err = LoadAccessRecords(); err != nil || records["acme"].AccessCount != 7 {
+		t.Errorf("versioned records = %+v, %v", records, err)
+	}
+
+	// History from a newer pk is left alone
+	if err := os.WriteFile(accessFile, []byte(`{"version": 99, "records": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAccessRecords(); err == nil {
+		t.Error("LoadAccessRecords should refuse a newer version")
+	}
+}
+
+func TestRecordAccessConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	// Each call stands in for a separate pk session process
+	const opens = 20
+	var wg sync.WaitGroup
+	for i := 0; i < opens; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := "acme"
+			if i%2 == 1 {
+				id = "dojo"
+			}
+			if err := RecordAccess(id, "/p/"+id); err != nil {
+				t.Errorf("RecordAccess: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	records, err := LoadAccessRecords()
+	if err != nil {
+		t.Fatalf("LoadAccessRecords: %v", err)
+	}
+	if records["acme"].AccessCount != opens/2 || records["dojo"].AccessCount != opens/2 {
+		t.Errorf("Expected %d opens each, got acme=%d dojo=%d",
+			opens/2, records["acme"].AccessCount, records["dojo"].AccessCount)
+	}
+}
+
+func TestGetRecentProjectsConfiguredRoot(t *testing.T) {
+	home := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	// Projects live under [paths] projects, not ~/projects
+	configDir := filepath.Join(home, ".config", "pk")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	conf := "[paths]\nprojects = \"~/work/code\"\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	projectDir := filepath.Join(home, "work", "code", "dojo")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	toml := "[project]\nname = \"Dojo\"\nid = \"dojo\"\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".project.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RecordAccess("dojo", projectDir); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := GetRecentProjects(5)
+	if err != nil {
+		t.Fatalf("GetRecentProjects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Path != projectDir {
+		t.Errorf("GetRecentProjects = %v, want [dojo] at %s", ids(projects), projectDir)
+	}
+
+	// Let the background cache write finish before the temp dir goes
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if cached, err := LoadFromCache(); err == nil && len(cached) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("cache not written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
 }
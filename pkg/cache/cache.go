@@ -2,86 +2,157 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/progress"
+	"github.com/datakaicr/pk/pkg/statefile"
 )
 
 const (
 	// Cache configuration
 	CacheMaxAge = 10 * time.Minute // Refresh every 10 minutes
+
+	// CacheVersion is the layout of cached projects. Bump it when a change
+	// to config.Project would make an older cache decode wrongly; caches
+	// from any other version are discarded and rebuilt on first use.
+	CacheVersion = 1
 )
 
-// GetCacheFile returns the path to the cache file
+// ErrStaleCache is returned for a cache written by a pk with a different
+// CacheVersion
+var ErrStaleCache = errors.New("cache written by a different pk version")
+
+// projectsFile is the layout of projects.json. Caches from before versioning
+// are a bare array and read as stale.
+type projectsFile struct {
+	Version  int               `json:"version"`
+	Projects []*config.Project `json:"projects"`
+
+	// What each root looked like when scanned, for roots scanned by
+	// FindProjectsCached or RefreshRoots. SaveToCache clears it.
+	Roots map[string]config.RootState `json:"roots,omitempty"`
+}
+
+// GetCacheFile returns the path to the cache file (pk.db with the SQLite index)
 func GetCacheFile() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if ix := activeIndex(); ix != nil {
+		return ix.Path(), nil
 	}
+	return statefile.Path("projects.json")
+}
 
-	cacheDir := filepath.Join(homeDir, ".cache", "pk")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
+// BuiltAt returns when the cache was last written
+func BuiltAt() (time.Time, error) {
+	if ix := activeIndex(); ix != nil {
+		return ix.BuiltAt()
 	}
 
-	return filepath.Join(cacheDir, "projects.json"), nil
-}
-
-// IsCacheValid checks if cache exists and is recent
-func IsCacheValid() bool {
 	cacheFile, err := GetCacheFile()
 	if err != nil {
-		return false
+		return time.Time{}, err
 	}
+	return statefile.ModTime(cacheFile)
+}
 
-	info, err := os.Stat(cacheFile)
+// IsCacheValid checks if cache exists and is recent
+func IsCacheValid() bool {
+	builtAt, err := BuiltAt()
 	if err != nil {
 		return false
 	}
 
-	age := time.Since(info.ModTime())
+	age := time.Since(builtAt)
 	return age < CacheMaxAge
 }
 
 // LoadFromCache reads projects from cache
 func LoadFromCache() ([]*config.Project, error) {
+	projects, _, err := loadCache()
+	return projects, err
+}
+
+// loadCache reads the cached projects and root states
+func loadCache() ([]*config.Project, map[string]config.RootState, error) {
+	if ix := activeIndex(); ix != nil {
+		projects, err := ix.Projects(Query{})
+		if err != nil {
+			return nil, nil, err
+		}
+		roots, err := ix.Roots()
+		return projects, roots, err
+	}
+
 	cacheFile, err := GetCacheFile()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	data, err := os.ReadFile(cacheFile)
+	data, err := statefile.ReadFile(cacheFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var projects []*config.Project
-	if err := json.Unmarshal(data, &projects); err != nil {
-		return nil, err
+	var cached projectsFile
+	if err := json.Unmarshal(data, &cached); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, nil, ErrStaleCache
+		}
+		return nil, nil, err
+	}
+	if cached.Version != CacheVersion {
+		return nil, nil, ErrStaleCache
 	}
 
-	return projects, nil
+	return cached.Projects, cached.Roots, nil
 }
 
-// SaveToCache writes projects to cache
+// SaveToCache writes projects to cache. They replace everything cached,
+// so no root is known to be up to date until it is scanned again.
 func SaveToCache(projects []*config.Project) error {
+	return saveToCache(projects, nil)
+}
+
+func saveToCache(projects []*config.Project, roots map[string]config.RootState) error {
+	if err := saveProjects(projects, roots); err != nil {
+		return err
+	}
+	if err := SaveCompletions(projects); err != nil {
+		return err
+	}
+
+	events.Emit(events.CacheRebuilt, "", "", map[string]string{"projects": strconv.Itoa(len(projects))})
+	return nil
+}
+
+func saveProjects(projects []*config.Project, roots map[string]config.RootState) error {
+	if ix := activeIndex(); ix != nil {
+		return ix.replace(projects, roots)
+	}
+
 	cacheFile, err := GetCacheFile()
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(projects, "", "  ")
+	data, err := json.MarshalIndent(projectsFile{Version: CacheVersion, Projects: projects, Roots: roots}, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(cacheFile, data, 0644)
+	return statefile.WriteFile(cacheFile, data, 0644)
 }
 
-// FindProjectsCached returns projects from cache if valid, otherwise scans and caches
+// FindProjectsCached returns projects from cache if valid. Otherwise it
+// rescans the roots that changed since they were cached, reuses the cached
+// projects of the others, and updates the cache.
 func FindProjectsCached(rootDirs ...string) ([]*config.Project, error) {
 	// Try cache first
 	if IsCacheValid() {
@@ -92,32 +163,100 @@ func FindProjectsCached(rootDirs ...string) ([]*config.Project, error) {
 		// Cache read failed, fall through to scan
 	}
 
-	// Scan filesystem
-	projects, err := config.FindProjects(rootDirs...)
+	projects, save, err := scanRoots(rootDirs, false)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update cache in background (non-blocking)
 	go func() {
-		SaveToCache(projects)
+		save()
 	}()
 
 	return projects, nil
 }
 
-// InvalidateCache removes the cache file
-func InvalidateCache() error {
-	cacheFile, err := GetCacheFile()
+// RefreshRoots rescans rootDirs whether or not they changed and replaces
+// their projects in the cache, keeping what it holds for other roots
+func RefreshRoots(rootDirs ...string) ([]*config.Project, error) {
+	projects, save, err := scanRoots(rootDirs, true)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return projects, save()
+}
+
+// scanRoots returns the projects under rootDirs, scanning each root unless
+// the cache holds it and it hasn't changed (or force is set). save writes
+// them back together with the cached projects of other scanned roots.
+func scanRoots(rootDirs []string, force bool) (projects []*config.Project, save func() error, err error) {
+	cached, roots, err := loadCache()
+	if err != nil || roots == nil {
+		cached, roots = nil, make(map[string]config.RootState)
+	}
+
+	for _, root := range rootDirs {
+		if state, ok := roots[root]; ok && !force && !state.Changed() {
+			for _, p := range cached {
+				if underRoot(p.Path, root) {
+					projects = append(projects, p)
+				}
+			}
+			continue
+		}
+
+		task := progress.Spinner("Scanning " + root)
+		found, state, err := config.ScanRoot(root)
+		task.Stop()
+		if err != nil {
+			return nil, nil, err
+		}
+		projects = append(projects, found...)
+		roots[root] = state
 	}
 
-	if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+	// Projects the cache can't place under a scanned root are dropped, as
+	// a full rescan would
+	var tracked []string
+	for root := range roots {
+		tracked = append(tracked, root)
+	}
+	merged := append([]*config.Project(nil), projects...)
+	for _, p := range cached {
+		if !underAny(p.Path, rootDirs) && underAny(p.Path, tracked) {
+			merged = append(merged, p)
+		}
+	}
+
+	return projects, func() error { return saveToCache(merged, roots) }, nil
+}
+
+// underRoot reports whether path is root or inside it
+func underRoot(path, root string) bool {
+	return path == root || strings.HasPrefix(path, strings.TrimRight(root, string(os.PathSeparator))+string(os.PathSeparator))
+}
+
+func underAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if underRoot(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateCache removes the cache file, or empties the SQLite index
+func InvalidateCache() error {
+	if ix := activeIndex(); ix != nil {
+		return ix.Clear()
+	}
+
+	cacheFile, err := GetCacheFile()
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return statefile.Remove(cacheFile)
 }
 
 // RebuildCacheAsync triggers a cache rebuild in the background
@@ -131,28 +270,85 @@ func RebuildCacheAsync(rootDirs ...string) {
 	}()
 }
 
-// Status returns cache information
-func Status() (string, error) {
+// Cache states reported by Inspect
+const (
+	StateBuilt    = "built"
+	StateNotBuilt = "not built"
+	StateStale    = "stale" // Written by another pk version; rebuilt on next use
+)
+
+// StatusInfo describes the cache for 'pk cache status'
+type StatusInfo struct {
+	File    string     `json:"file"`
+	Backend string     `json:"backend"`
+	State   string     `json:"state"`
+	Version int        `json:"version"`
+	BuiltAt *time.Time `json:"built_at,omitempty"`
+	Valid   bool       `json:"valid"`
+	Size    int64      `json:"size,omitempty"` // Bytes
+}
+
+// Inspect reports where the cache is, which backend holds it and whether
+// it's built and still valid
+func Inspect() (StatusInfo, error) {
 	cacheFile, err := GetCacheFile()
 	if err != nil {
-		return "", err
+		return StatusInfo{}, err
 	}
 
-	info, err := os.Stat(cacheFile)
+	info := StatusInfo{File: cacheFile, Backend: BackendJSON, Version: CacheVersion}
+	if IndexEnabled() {
+		info.Backend = BackendSQLite
+	}
+
+	builtAt, err := BuiltAt()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "Cache: not built", nil
+		if os.IsNotExist(err) || errors.Is(err, ErrIndexNotBuilt) {
+			info.State = StateNotBuilt
+			return info, nil
+		}
+		if errors.Is(err, ErrStaleCache) {
+			info.State = StateStale
+			return info, nil
 		}
+		return StatusInfo{}, err
+	}
+	if _, err := LoadFromCache(); errors.Is(err, ErrStaleCache) {
+		info.State = StateStale
+		return info, nil
+	}
+
+	info.State = StateBuilt
+	info.BuiltAt = &builtAt
+	info.Valid = time.Since(builtAt) < CacheMaxAge
+	if stat, err := os.Stat(cacheFile); err == nil {
+		info.Size = stat.Size()
+	}
+	return info, nil
+}
+
+// Status returns cache information
+func Status() (string, error) {
+	info, err := Inspect()
+	if err != nil {
 		return "", err
 	}
 
-	age := time.Since(info.ModTime())
-	valid := age < CacheMaxAge
+	switch info.State {
+	case StateNotBuilt:
+		return "Cache: not built\n", nil
+	case StateStale:
+		return "Cache: from another pk version (rebuilt on next use)\n", nil
+	}
 
-	status := fmt.Sprintf("Cache: %s\n", cacheFile)
-	status += fmt.Sprintf("Age: %s\n", age.Round(time.Second))
-	status += fmt.Sprintf("Valid: %v\n", valid)
-	status += fmt.Sprintf("Size: %d bytes\n", info.Size())
+	status := fmt.Sprintf("Cache: %s\n", info.File)
+	status += fmt.Sprintf("Backend: %s\n", info.Backend)
+	status += fmt.Sprintf("Version: %d\n", info.Version)
+	status += fmt.Sprintf("Age: %s\n", time.Since(*info.BuiltAt).Round(time.Second))
+	status += fmt.Sprintf("Valid: %v\n", info.Valid)
+	if info.Size > 0 {
+		status += fmt.Sprintf("Size: %d bytes\n", info.Size)
+	}
 
 	return status, nil
 }
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func TestStaleCacheIsRebuilt(t *testing.T) {
+	home := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	root := filepath.Join(home, "projects")
+	projectDir := filepath.Join(root, "dojo")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	toml := "[project]\nname = \"Dojo\"\nid = \"dojo\"\nstatus = \"active\"\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".project.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A cache from before versioning, listing a project that's gone
+	cacheFile, err := GetCacheFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `[{"Path": "/gone", "ProjectInfo": {"ID": "gone"}}]`
+	if err := os.WriteFile(cacheFile, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromCache(); !errors.Is(err, ErrStaleCache) {
+		t.Fatalf("LoadFromCache on a legacy cache = %v, want ErrStaleCache", err)
+	}
+
+	projects, err := FindProjectsCached(root)
+	if err != nil {
+		t.Fatalf("FindProjectsCached: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ProjectInfo.ID != "dojo" {
+		t.Errorf("FindProjectsCached = %v, want [dojo]", ids(projects))
+	}
+
+	// The rescan rewrites the cache in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cached, err := LoadFromCache()
+		if err == nil && len(cached) == 1 && cached[0].ProjectInfo.ID == "dojo" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("cache not rewritten: %v, %v", ids(cached), err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestIndexVersionMismatch(t *testing.T) {
+	ix := useIndex(t)
+
+	if err := ix.Replace([]*config.Project{indexProject("dojo", "active", "/p/dojo")}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if _, err := ix.BuiltAt(); err != nil {
+		t.Fatalf("BuiltAt: %v", err)
+	}
+
+	if _, err := ix.db.Exec(`UPDATE meta SET value = '0' WHERE key = 'version'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.BuiltAt(); !errors.Is(err, ErrStaleCache) {
+		t.Errorf("BuiltAt with an old version = %v, want ErrStaleCache", err)
+	}
+	if IsCacheValid() {
+		t.Error("index from another version reported as valid")
+	}
+}
+
+func TestScanRootsRescansOnlyChangedRoots(t *testing.T) {
+	home := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	projectsRoot := filepath.Join(home, "projects")
+	archiveRoot := filepath.Join(home, "archive")
+	write := func(root, id string) {
+		dir := filepath.Join(root, id)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, ".project.toml"), []byte("[project]\nid = \""+id+"\"\nname = \""+id+"\"\n"), 0644)
+	}
+	write(projectsRoot, "dojo")
+	write(archiveRoot, "old")
+
+	if _, err := RefreshRoots(projectsRoot, archiveRoot); err != nil {
+		t.Fatalf("RefreshRoots: %v", err)
+	}
+
+	// Mark the cached entries, so reused ones can be told from rescanned
+	cached, roots, err := loadCache()
+	if err != nil || len(roots) != 2 {
+		t.Fatalf("loadCache = %v, %d roots, %v", ids(cached), len(roots), err)
+	}
+	for _, p := range cached {
+		p.ProjectInfo.Name = "cached"
+	}
+	if err := saveProjects(cached, roots); err != nil {
+		t.Fatal(err)
+	}
+
+	write(projectsRoot, "kata")
+
+	projects, save, err := scanRoots([]string{projectsRoot, archiveRoot}, false)
+	if err != nil {
+		t.Fatalf("scanRoots: %v", err)
+	}
+	names := make(map[string]string)
+	for _, p := range projects {
+		names[p.ProjectInfo.ID] = p.ProjectInfo.Name
+	}
+	want := map[string]string{"dojo": "dojo", "kata": "kata", "old": "cached"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v (only the changed root rescanned)", names, want)
+	}
+	if err := save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refreshing one root keeps the other's cached projects
+	if _, err := RefreshRoots(archiveRoot); err != nil {
+		t.Fatal(err)
+	}
+	cached, _ = LoadFromCache()
+	if got := ids(cached); !reflect.DeepEqual(got, []string{"old", "dojo", "kata"}) {
+		t.Errorf("cache after refreshing one root = %v", got)
+	}
+
+	// SaveToCache replaces everything, so roots are rescanned next time
+	if err := SaveToCache(cached); err != nil {
+		t.Fatal(err)
+	}
+	if _, roots, _ := loadCache(); len(roots) != 0 {
+		t.Errorf("SaveToCache kept %d root states", len(roots))
+	}
+}
+
+func TestIndexKeepsRootStates(t *testing.T) {
+	ix := useIndex(t)
+
+	state := config.RootState{Options: "x", Paths: map[string]time.Time{"/p": time.Unix(1, 0).UTC()}}
+	if err := ix.replace([]*config.Project{indexProject("dojo", "active", "/p/dojo")}, map[string]config.RootState{"/p": state}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	roots, err := ix.Roots()
+	if err != nil || !reflect.DeepEqual(roots["/p"], state) {
+		t.Errorf("Roots = %v, %v", roots, err)
+	}
+
+	if err := ix.Replace(nil); err != nil {
+		t.Fatal(err)
+	}
+	if roots, _ := ix.Roots(); len(roots) != 0 {
+		t.Errorf("Replace kept root states: %v", roots)
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// Completion is one project as offered by shell tab-completion
+type Completion struct {
+	ID          string
+	Description string
+}
+
+// GetCompletionsFile returns the path to the completion file
+func GetCompletionsFile() (string, error) {
+	return statefile.Path("completions.tsv")
+}
+
+// SaveCompletions writes one "id<TAB>description" line per project. It is
+// written with the project cache so completion never has to decode (or
+// rebuild) projects.json while the user is typing.
+func SaveCompletions(projects []*config.Project) error {
+	completionsFile, err := GetCompletionsFile()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, 0, len(projects))
+	for _, p := range projects {
+		if p.ProjectInfo.ID == "" {
+			continue
+		}
+		lines = append(lines, completionField(p.ProjectInfo.ID)+"\t"+completionField(completionDescription(p)))
+	}
+	sort.Strings(lines)
+
+	return statefile.WriteFile(completionsFile, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// LoadCompletions reads the completion file. It is not checked for age:
+// slightly stale completions beat a scan in the middle of a command line.
+func LoadCompletions() ([]Completion, error) {
+	completionsFile, err := GetCompletionsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := statefile.ReadFile(completionsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var completions []Completion
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		id, description, _ := strings.Cut(line, "\t")
+		completions = append(completions, Completion{ID: id, Description: description})
+	}
+	return completions, nil
+}
+
+// completionDescription is the hint shells show next to a project ID
+func completionDescription(p *config.Project) string {
+	description := p.ProjectInfo.Name
+	if description == "" {
+		description = p.ProjectInfo.ID
+	}
+	if p.ProjectInfo.Status != "" {
+		description += " (" + p.ProjectInfo.Status + ")"
+	}
+	return description
+}
+
+// completionField keeps a value on one line and in one column
+func completionField(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func TestSaveToCacheWritesCompletions(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	dojo := &config.Project{Path: "/p/dojo"}
+	dojo.ProjectInfo.ID = "dojo"
+	dojo.ProjectInfo.Name = "Dojo\tPlatform"
+	dojo.ProjectInfo.Status = "active"
+	alpha := &config.Project{Path: "/p/alpha"}
+	alpha.ProjectInfo.ID = "alpha"
+
+	if err := SaveToCache([]*config.Project{dojo, alpha}); err != nil {
+		t.Fatalf("SaveToCache: %v", err)
+	}
+
+	got, err := LoadCompletions()
+	if err != nil {
+		t.Fatalf("LoadCompletions: %v", err)
+	}
+	want := []Completion{
+		{ID: "alpha", Description: "alpha"},
+		{ID: "dojo", Description: "Dojo Platform (active)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completions = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompletionsSurviveInvalidate(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	p := &config.Project{}
+	p.ProjectInfo.ID = "dojo"
+	if err := SaveToCache([]*config.Project{p}); err != nil {
+		t.Fatalf("SaveToCache: %v", err)
+	}
+
+	if err := InvalidateCache(); err != nil {
+		t.Fatalf("InvalidateCache: %v", err)
+	}
+	if got, err := LoadCompletions(); err != nil || len(got) != 1 {
+		t.Errorf("completions after invalidate = %v, %v; want the previous list", got, err)
+	}
+}
@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/datakaicr/pk/pkg/store"
+	_ "modernc.org/sqlite"
+)
+
+// Cache backends accepted in [cache] backend
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// ErrIndexNotBuilt is returned when the index has no scan recorded
+var ErrIndexNotBuilt = errors.New("project index not built")
+
+// Index is the optional SQLite project cache in ~/.cache/pk/pk.db. Each
+// project is a row with its filter columns indexed, so filtered and sorted
+// reads don't decode every project, and a rebuild is one transaction that
+// concurrent pk processes can't interleave with.
+type Index struct {
+	path string
+	db   *sql.DB
+}
+
+// Query selects projects from the index. Empty fields match everything.
+type Query struct {
+	Status string
+	Owner  string
+	Type   string
+	// Roots limits results to projects under these directories, returned
+	// root by root in this order and by path within a root
+	Roots []string
+}
+
+// OpenIndex opens (and creates if needed) the project index
+func OpenIndex() (*Index, error) {
+	path, err := store.IndexPath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS projects (
+			path   TEXT PRIMARY KEY,
+			id     TEXT NOT NULL,
+			name   TEXT NOT NULL,
+			status TEXT NOT NULL,
+			type   TEXT NOT NULL,
+			owner  TEXT NOT NULL,
+			data   BLOB NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS projects_id ON projects (id);
+		CREATE INDEX IF NOT EXISTS projects_status ON projects (status);
+		CREATE INDEX IF NOT EXISTS projects_owner ON projects (owner);
+		CREATE INDEX IF NOT EXISTS projects_type ON projects (type);
+		CREATE TABLE IF NOT EXISTS meta (
+			key   TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", path, err)
+	}
+
+	return &Index{path: path, db: db}, nil
+}
+
+// Path returns the database file
+func (ix *Index) Path() string {
+	return ix.path
+}
+
+// Close releases the database
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// Replace swaps the indexed projects for a fresh scan
+func (ix *Index) Replace(projects []*config.Project) error {
+	return ix.replace(projects, nil)
+}
+
+// replace swaps the indexed projects and records the root states they
+// were scanned with
+func (ix *Index) replace(projects []*config.Project, roots map[string]config.RootState) error {
+	rootsData, err := json.Marshal(roots)
+	if err != nil {
+		return err
+	}
+
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM projects`); err != nil {
+		return err
+	}
+
+	insert, err := tx.Prepare(`INSERT OR REPLACE INTO projects (path, id, name, status, type, owner, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, p := range projects {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = insert.Exec(p.Path, p.ProjectInfo.ID, p.ProjectInfo.Name, p.ProjectInfo.Status,
+			p.ProjectInfo.Type, p.GetOwner(), data)
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, value := range map[string]string{
+		"built_at": time.Now().UTC().Format(time.RFC3339Nano),
+		"version":  strconv.Itoa(CacheVersion),
+		"roots":    string(rootsData),
+	} {
+		_, err = tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// BuiltAt returns when the index was last replaced. An index built by a pk
+// with a different CacheVersion returns ErrStaleCache.
+func (ix *Index) BuiltAt() (time.Time, error) {
+	var builtAt string
+	var version sql.NullString
+	err := ix.db.QueryRow(`SELECT b.value, v.value FROM meta b
+		LEFT JOIN meta v ON v.key = 'version' WHERE b.key = 'built_at'`).Scan(&builtAt, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, ErrIndexNotBuilt
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if version.String != strconv.Itoa(CacheVersion) {
+		return time.Time{}, ErrStaleCache
+	}
+	return time.Parse(time.RFC3339Nano, builtAt)
+}
+
+// Roots returns the root states recorded by the last replace, or nil
+func (ix *Index) Roots() (map[string]config.RootState, error) {
+	var value string
+	err := ix.db.QueryRow(`SELECT value FROM meta WHERE key = 'roots'`).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var roots map[string]config.RootState
+	if err := json.Unmarshal([]byte(value), &roots); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// Projects returns the indexed projects matching q
+func (ix *Index) Projects(q Query) ([]*config.Project, error) {
+	if _, err := ix.BuiltAt(); err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var args []interface{}
+	for column, value := range map[string]string{"status": q.Status, "owner": q.Owner, "type": q.Type} {
+		if value != "" {
+			where = append(where, column+" = ?")
+			args = append(args, value)
+		}
+	}
+
+	if len(q.Roots) == 0 {
+		return ix.query(where, args)
+	}
+
+	var projects []*config.Project
+	for _, root := range q.Roots {
+		// Range scan on the primary key instead of LIKE, which would
+		// treat _ and % in directory names as wildcards
+		prefix := strings.TrimRight(root, string(os.PathSeparator)) + string(os.PathSeparator)
+		rootWhere := append(where[:len(where):len(where)], "(path = ? OR (path >= ? AND path < ?))")
+		rootArgs := append(args[:len(args):len(args)], root, prefix, prefix+"\xff")
+
+		found, err := ix.query(rootWhere, rootArgs)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, found...)
+	}
+	return projects, nil
+}
+
+func (ix *Index) query(where []string, args []interface{}) ([]*config.Project, error) {
+	query := `SELECT data FROM projects`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY path`
+
+	rows, err := ix.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*config.Project
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var p config.Project
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		projects = append(projects, &p)
+	}
+	return projects, rows.Err()
+}
+
+// Clear empties the index. Pins and access history in the same file are kept.
+func (ix *Index) Clear() error {
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM projects`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM meta WHERE key IN ('built_at', 'version', 'roots')`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+var (
+	indexOnce    sync.Once
+	defaultIndex *Index
+)
+
+// activeIndex returns the index when [cache] backend = "sqlite", or nil
+// for the JSON cache. An index that can't be opened falls back to JSON.
+func activeIndex() *Index {
+	indexOnce.Do(func() {
+		s, _ := settings.Load()
+		if s.Cache.Backend != BackendSQLite {
+			return
+		}
+
+		ix, err := OpenIndex()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: project index unavailable (%v); using projects.json\n", err)
+			return
+		}
+		defaultIndex = ix
+	})
+	return defaultIndex
+}
+
+// IndexEnabled reports whether the project cache is the SQLite index
+func IndexEnabled() bool {
+	return activeIndex() != nil
+}
+
+// QueryProjects returns cached projects matching q. With the SQLite index
+// the filtering and ordering happen in the database; with projects.json
+// the whole cache is loaded and filtered here.
+func QueryProjects(q Query) ([]*config.Project, error) {
+	if ix := activeIndex(); ix != nil {
+		return ix.Projects(q)
+	}
+
+	projects, err := LoadFromCache()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := q.Roots
+	if len(roots) == 0 {
+		roots = []string{""}
+	}
+	var matched []*config.Project
+	for _, root := range roots {
+		for _, p := range projects {
+			if root != "" && !underRoot(p.Path, root) {
+				continue
+			}
+			if (q.Status != "" && p.ProjectInfo.Status != q.Status) ||
+				(q.Owner != "" && p.GetOwner() != q.Owner) ||
+				(q.Type != "" && p.ProjectInfo.Type != q.Type) {
+				continue
+			}
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
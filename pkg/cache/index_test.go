@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// useIndex points the cache at a fresh SQLite index under a temp HOME
+func useIndex(t *testing.T) *Index {
+	t.Helper()
+
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+
+	ix, err := OpenIndex()
+	if err != nil {
+		t.Fatalf("OpenIndex: %v", err)
+	}
+	indexOnce.Do(func() {})
+	defaultIndex = ix
+
+	t.Cleanup(func() {
+		ix.Close()
+		defaultIndex = nil
+		indexOnce = sync.Once{}
+		os.Setenv("HOME", originalHome)
+	})
+	return ix
+}
+
+func indexProject(id, status, path string) *config.Project {
+	p := &config.Project{Path: path}
+	p.ProjectInfo.ID = id
+	p.ProjectInfo.Name = id
+	p.ProjectInfo.Status = status
+	p.ProjectInfo.Type = "product"
+	return p
+}
+
+func ids(projects []*config.Project) []string {
+	var out []string
+	for _, p := range projects {
+		out = append(out, p.ProjectInfo.ID)
+	}
+	return out
+}
+
+func TestIndexQuery(t *testing.T) {
+	ix := useIndex(t)
+
+	err := ix.Replace([]*config.Project{
+		indexProject("zeta", "active", "/home/u/projects/zeta"),
+		indexProject("old", "archived", "/home/u/archive/old"),
+		indexProject("alpha", "active", "/home/u/projects/alpha"),
+		indexProject("notes", "active", "/home/u/projects_old/notes"),
+	})
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"all by path", Query{}, "[old alpha zeta notes]"},
+		{"status", Query{Status: "active"}, "[alpha zeta notes]"},
+		{"roots in order", Query{Roots: []string{"/home/u/projects", "/home/u/archive"}}, "[alpha zeta old]"},
+		{"status within root", Query{Status: "archived", Roots: []string{"/home/u/projects"}}, "[]"},
+		{"no match", Query{Type: "client-project"}, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ix.Projects(tt.q)
+			if err != nil {
+				t.Fatalf("Projects: %v", err)
+			}
+			if s := fmt.Sprint(ids(got)); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheUsesIndex(t *testing.T) {
+	ix := useIndex(t)
+
+	if IsCacheValid() {
+		t.Fatal("empty index reported as valid")
+	}
+	if _, err := LoadFromCache(); err == nil {
+		t.Fatal("LoadFromCache on an unbuilt index should fail")
+	}
+
+	if err := SaveToCache([]*config.Project{indexProject("dojo", "active", "/p/dojo")}); err != nil {
+		t.Fatalf("SaveToCache: %v", err)
+	}
+	if !IsCacheValid() {
+		t.Error("index not valid after SaveToCache")
+	}
+	cached, err := LoadFromCache()
+	if err != nil || len(cached) != 1 || cached[0].ProjectInfo.ID != "dojo" {
+		t.Errorf("LoadFromCache = %v, %v", ids(cached), err)
+	}
+
+	// Nothing should land in projects.json
+	home, _ := os.UserHomeDir()
+	if _, err := os.Stat(filepath.Join(home, ".cache", "pk", "projects.json")); !os.IsNotExist(err) {
+		t.Errorf("projects.json written with the SQLite index enabled")
+	}
+
+	if err := InvalidateCache(); err != nil {
+		t.Fatalf("InvalidateCache: %v", err)
+	}
+	if IsCacheValid() {
+		t.Error("index still valid after InvalidateCache")
+	}
+	if _, err := os.Stat(ix.Path()); err != nil {
+		t.Errorf("InvalidateCache must keep pk.db (it holds pins): %v", err)
+	}
+}
+
+func TestQueryProjectsJSON(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	err := SaveToCache([]*config.Project{
+		indexProject("old", "archived", "/home/u/archive/old"),
+		indexProject("dojo", "active", "/home/u/projects/dojo"),
+	})
+	if err != nil {
+		t.Fatalf("SaveToCache: %v", err)
+	}
+
+	got, err := QueryProjects(Query{Status: "active", Roots: []string{"/home/u/projects"}})
+	if err != nil {
+		t.Fatalf("QueryProjects: %v", err)
+	}
+	if s := fmt.Sprint(ids(got)); s != "[dojo]" {
+		t.Errorf("got %s, want [dojo]", s)
+	}
+}
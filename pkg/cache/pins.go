@@ -1,13 +1,12 @@
 package cache
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 
 	"github.com/datakaicr/pk/pkg/paths"
+	"github.com/datakaicr/pk/pkg/statefile"
+	"github.com/datakaicr/pk/pkg/store"
 )
 
 // PinRecord represents a pinned project in a slot
@@ -17,39 +16,17 @@ type PinRecord struct {
 	ProjectPath string `json:"project_path"`
 }
 
-// GetPinsFile returns the path to the pins file
-func GetPinsFile() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	cacheDir := filepath.Join(homeDir, ".cache", "pk")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
-	}
-
-	return filepath.Join(cacheDir, "pins.json"), nil
-}
-
 // LoadPins reads all pinned projects and validates paths
 // Automatically heals stale paths by searching for projects
 func LoadPins() (map[int]PinRecord, error) {
-	pinsFile, err := GetPinsFile()
-	if err != nil {
-		return nil, err
-	}
-
-	data, err := os.ReadFile(pinsFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return make(map[int]PinRecord), nil
-		}
-		return nil, err
-	}
+	defer statefile.Lock("pins")()
+	return loadPins()
+}
 
-	var pins map[int]PinRecord
-	if err := json.Unmarshal(data, &pins); err != nil {
+// loadPins is LoadPins for callers already holding the pins lock
+func loadPins() (map[int]PinRecord, error) {
+	pins := make(map[int]PinRecord)
+	if err := store.LoadJSON(store.Default(), "pins", &pins); err != nil {
 		return nil, err
 	}
 
@@ -98,17 +75,7 @@ func validateAndHealPins(pins map[int]PinRecord) (bool, error) {
 
 // SavePins writes pinned projects to disk
 func SavePins(pins map[int]PinRecord) error {
-	pinsFile, err := GetPinsFile()
-	if err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(pins, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(pinsFile, data, 0644)
+	return store.SaveJSON(store.Default(), "pins", pins)
 }
 
 // AddPin pins a project to a specific slot (1-5)
@@ -117,7 +84,9 @@ func AddPin(slot int, projectID, projectPath string) error {
 		return fmt.Errorf("slot must be between 1 and 5")
 	}
 
-	pins, err := LoadPins()
+	defer statefile.Lock("pins")()
+
+	pins, err := loadPins()
 	if err != nil {
 		return err
 	}
@@ -133,7 +102,9 @@ func AddPin(slot int, projectID, projectPath string) error {
 
 // RemovePin removes a pin by slot number
 func RemovePin(slot int) error {
-	pins, err := LoadPins()
+	defer statefile.Lock("pins")()
+
+	pins, err := loadPins()
 	if err != nil {
 		return err
 	}
@@ -148,7 +119,9 @@ func RemovePin(slot int) error {
 
 // RemovePinByProject removes a pin by project ID
 func RemovePinByProject(projectID string) error {
-	pins, err := LoadPins()
+	defer statefile.Lock("pins")()
+
+	pins, err := loadPins()
 	if err != nil {
 		return err
 	}
@@ -205,6 +178,7 @@ func ListPins() ([]PinRecord, error) {
 
 // ClearPins removes all pins
 func ClearPins() error {
+	defer statefile.Lock("pins")()
 	return SavePins(make(map[int]PinRecord))
 }
 
@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/journal"
+	"github.com/datakaicr/pk/pkg/statefile"
+	"github.com/datakaicr/pk/pkg/store"
+)
+
+// Conflict kinds found by Reconcile
+const (
+	ConflictMoved   = "moved"   // Same ID, different path
+	ConflictRenamed = "renamed" // Same path, different ID in .project.toml
+	ConflictMissing = "missing" // Neither ID nor path found on disk
+)
+
+// Conflict is a project pk knows about (from the cache, pins, or access
+// history) that no longer matches what's on disk
+type Conflict struct {
+	Kind    string
+	OldID   string
+	OldPath string
+	NewID   string // Set for moved and renamed
+	NewPath string
+
+	// Candidates are unclaimed projects on disk that a missing project
+	// may have become, best guess first
+	Candidates []*config.Project
+}
+
+// KnownProjects collects ID -> path for every project pk has state for:
+// the cache, pins, and access history. The cache wins when they disagree.
+func KnownProjects() map[string]string {
+	known := make(map[string]string)
+
+	if records, err := LoadAccessRecords(); err == nil {
+		for id, r := range records {
+			known[id] = r.ProjectPath
+		}
+	}
+	if pins, err := LoadPins(); err == nil {
+		for _, pin := range pins {
+			known[pin.ProjectID] = pin.ProjectPath
+		}
+	}
+	if cached, err := LoadFromCache(); err == nil {
+		for _, p := range cached {
+			known[p.ProjectInfo.ID] = p.Path
+		}
+	}
+
+	return known
+}
+
+// Reconcile compares known projects (ID -> path) against a fresh scan
+func Reconcile(known map[string]string, current []*config.Project) []Conflict {
+	byID := make(map[string]*config.Project, len(current))
+	byPath := make(map[string]*config.Project, len(current))
+	for _, p := range current {
+		byID[p.ProjectInfo.ID] = p
+		byPath[filepath.Clean(p.Path)] = p
+	}
+
+	var conflicts []Conflict
+	claimed := make(map[string]bool)
+	for _, id := range sortedIDs(known) {
+		oldPath := known[id]
+		if p, exists := byID[id]; exists {
+			claimed[id] = true
+			if filepath.Clean(p.Path) != filepath.Clean(oldPath) {
+				conflicts = append(conflicts, Conflict{Kind: ConflictMoved, OldID: id, OldPath: oldPath, NewID: id, NewPath: p.Path})
+			}
+			continue
+		}
+		if p, exists := byPath[filepath.Clean(oldPath)]; exists {
+			claimed[p.ProjectInfo.ID] = true
+			conflicts = append(conflicts, Conflict{Kind: ConflictRenamed, OldID: id, OldPath: oldPath, NewID: p.ProjectInfo.ID, NewPath: p.Path})
+			continue
+		}
+		conflicts = append(conflicts, Conflict{Kind: ConflictMissing, OldID: id, OldPath: oldPath})
+	}
+
+	// Unambiguous fixes first, so prompts for missing projects come last
+	rank := map[string]int{ConflictMoved: 0, ConflictRenamed: 1, ConflictMissing: 2}
+	sort.SliceStable(conflicts, func(i, j int) bool { return rank[conflicts[i].Kind] < rank[conflicts[j].Kind] })
+
+	// Missing projects may have become any project pk has no state for yet
+	var unclaimed []*config.Project
+	for _, p := range current {
+		if _, isKnown := known[p.ProjectInfo.ID]; !isKnown && !claimed[p.ProjectInfo.ID] {
+			unclaimed = append(unclaimed, p)
+		}
+	}
+	for i := range conflicts {
+		if conflicts[i].Kind == ConflictMissing {
+			conflicts[i].Candidates = rankCandidates(conflicts[i], unclaimed)
+		}
+	}
+
+	return conflicts
+}
+
+// rankCandidates orders unclaimed projects by how likely they are to be the
+// missing one: same directory name first, then shared ID prefix
+func rankCandidates(c Conflict, unclaimed []*config.Project) []*config.Project {
+	score := func(p *config.Project) int {
+		s := 0
+		if filepath.Base(p.Path) == filepath.Base(c.OldPath) {
+			s += 4
+		}
+		if strings.Contains(p.ProjectInfo.ID, c.OldID) || strings.Contains(c.OldID, p.ProjectInfo.ID) {
+			s += 2
+		}
+		if filepath.Dir(p.Path) == filepath.Dir(c.OldPath) {
+			s++
+		}
+		return s
+	}
+
+	candidates := append([]*config.Project(nil), unclaimed...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		si, sj := score(candidates[i]), score(candidates[j])
+		if si != sj {
+			return si > sj
+		}
+		return candidates[i].ProjectInfo.ID < candidates[j].ProjectInfo.ID
+	})
+	return candidates
+}
+
+// RemapProject moves pk's per-project state from oldID at its old location
+// to newID at newPath (see MoveState) and records the remap
+func RemapProject(oldID, newID, newPath string) error {
+	if err := MoveState(oldID, newID, newPath); err != nil {
+		return err
+	}
+
+	events.Emit(events.ProjectRemapped, newID, newPath, map[string]string{"from": oldID})
+	return nil
+}
+
+// MoveState moves pk's per-project state (access history, pins, triage
+// snoozes and journal entries) from oldID to newID at newPath
+func MoveState(oldID, newID, newPath string) error {
+	if err := remapAccess(oldID, newID, newPath); err != nil {
+		return err
+	}
+	if err := remapPins(oldID, newID, newPath); err != nil {
+		return err
+	}
+	if err := remapSnoozes(oldID, newID, newPath); err != nil {
+		return err
+	}
+	return journal.Rename(oldID, newID)
+}
+
+func remapAccess(oldID, newID, newPath string) error {
+	defer statefile.Lock("access")()
+
+	records, err := loadAccessRecords()
+	if err != nil {
+		return err
+	}
+	if record, exists := records[oldID]; exists {
+		delete(records, oldID)
+		// Keep the most recent access if the new ID already has history,
+		// and the uses of both
+		if existing, ok := records[newID]; ok {
+			record.AccessCount = max(record.AccessCount, 1) + max(existing.AccessCount, 1)
+			for day, n := range existing.Days {
+				if record.Days == nil {
+					record.Days = make(map[string]int)
+				}
+				record.Days[day] += n
+			}
+			if existing.LastAccessed.After(record.LastAccessed) {
+				record.LastAccessed = existing.LastAccessed
+			}
+		}
+		record.ProjectID = newID
+		record.ProjectPath = newPath
+		records[newID] = record
+		return SaveAccessRecords(records)
+	}
+	return nil
+}
+
+func remapPins(oldID, newID, newPath string) error {
+	defer statefile.Lock("pins")()
+
+	pins, err := loadPins()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for slot, pin := range pins {
+		if pin.ProjectID == oldID {
+			pin.ProjectID = newID
+			pin.ProjectPath = newPath
+			pins[slot] = pin
+			changed = true
+		}
+	}
+	if changed {
+		return SavePins(pins)
+	}
+	return nil
+}
+
+func remapSnoozes(oldID, newID, newPath string) error {
+	defer statefile.Lock("snoozes")()
+
+	snoozes, err := loadSnoozes()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for path, s := range snoozes {
+		if s.ProjectID == oldID {
+			delete(snoozes, path)
+			s.ProjectID = newID
+			s.ProjectPath = newPath
+			snoozes[newPath] = s
+			changed = true
+		}
+	}
+	if changed {
+		return store.SaveJSON(store.Default(), "snoozes", snoozes)
+	}
+	return nil
+}
+
+// DropState removes pk's per-project state (access history, pins, triage
+// snoozes and journal entries) for a project pk should no longer know
+func DropState(id string) error {
+	if err := ForgetProject(id); err != nil {
+		return err
+	}
+	if err := dropSnoozes(id); err != nil {
+		return err
+	}
+	return journal.Remove(id)
+}
+
+func dropSnoozes(id string) error {
+	defer statefile.Lock("snoozes")()
+
+	snoozes, err := loadSnoozes()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for path, s := range snoozes {
+		if s.ProjectID == id {
+			delete(snoozes, path)
+			changed = true
+		}
+	}
+	if changed {
+		return store.SaveJSON(store.Default(), "snoozes", snoozes)
+	}
+	return nil
+}
+
+// ForgetProject drops access history and pins for a project that is gone
+func ForgetProject(id string) error {
+	if err := RemoveAccessRecord(id); err != nil {
+		return err
+	}
+	if IsPinned(id) < 0 {
+		return nil
+	}
+	return RemovePinByProject(id)
+}
+
+// LastAccessed returns when a project was last opened, if ever
+func LastAccessed(id string) (time.Time, bool) {
+	records, err := LoadAccessRecords()
+	if err != nil {
+		return time.Time{}, false
+	}
+	record, exists := records[id]
+	return record.LastAccessed, exists
+}
+
+func sortedIDs(m map[string]string) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/journal"
+)
+
+func testProject(id, path string) *config.Project {
+	p := &config.Project{Path: path}
+	p.ProjectInfo.ID = id
+	return p
+}
+
+func TestReconcile(t *testing.T) {
+	known := map[string]string{
+		"same":    "/p/same",
+		"moved":   "/p/moved",
+		"old-id":  "/p/renamed",
+		"missing": "/p/api",
+	}
+	current := []*config.Project{
+		testProject("same", "/p/same"),
+		testProject("moved", "/archive/moved"),
+		testProject("new-id", "/p/renamed"),
+		testProject("unrelated", "/q/unrelated"),
+		testProject("api-v2", "/work/api"),
+	}
+
+	conflicts := Reconcile(known, current)
+	if len(conflicts) != 3 {
+		t.Fatalf("Expected 3 conflicts, got %+v", conflicts)
+	}
+
+	if c := conflicts[0]; c.Kind != ConflictMoved || c.OldID != "moved" || c.NewPath != "/archive/moved" {
+		t.Errorf("Expected moved conflict first, got %+v", c)
+	}
+	if c := conflicts[1]; c.Kind != ConflictRenamed || c.OldID != "old-id" || c.NewID != "new-id" {
+		t.Errorf("Expected renamed conflict, got %+v", c)
+	}
+
+	c := conflicts[2]
+	if c.Kind != ConflictMissing || c.OldID != "missing" {
+		t.Fatalf("Expected missing conflict, got %+v", c)
+	}
+	// new-id is claimed by the rename; api-v2 shares the directory name
+	if len(c.Candidates) != 2 || c.Candidates[0].ProjectInfo.ID != "api-v2" {
+		t.Errorf("Unexpected candidates: %v", c.Candidates)
+	}
+}
+
+func TestRemapProject(t *testing.T) {
+	testHome := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", testHome)
+	defer os.Setenv("HOME", originalHome)
+
+	accessed := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	records := map[string]AccessRecord{
+		"old": {ProjectID: "old", ProjectPath: "/gone/old", LastAccessed: accessed},
+	}
+	if err := SaveAccessRecords(records); err != nil {
+		t.Fatalf("SaveAccessRecords failed: %v", err)
+	}
+	if err := SavePins(map[int]PinRecord{2: {Slot: 2, ProjectID: "old", ProjectPath: "/gone/old"}}); err != nil {
+		t.Fatalf("SavePins failed: %v", err)
+	}
+
+	newPath := t.TempDir()
+	if err := RemapProject("old", "new", newPath); err != nil {
+		t.Fatalf("RemapProject failed: %v", err)
+	}
+
+	records, _ = LoadAccessRecords()
+	if _, exists := records["old"]; exists {
+		t.Error("Old access record should be gone")
+	}
+	if r := records["new"]; r.ProjectPath != newPath || !r.LastAccessed.Equal(accessed) {
+		t.Errorf("Access history not carried over: %+v", r)
+	}
+
+	// Remapping onto an ID with its own history keeps the uses of both
+	later := accessed.Add(time.Hour)
+	records["other"] = AccessRecord{ProjectID: "other", ProjectPath: "/gone/other", LastAccessed: later, AccessCount: 3}
+	records["new"] = AccessRecord{ProjectID: "new", ProjectPath: newPath, LastAccessed: accessed, AccessCount: 4}
+	SaveAccessRecords(records)
+	if err := RemapProject("other", "new", newPath); err != nil {
+		t.Fatalf("RemapProject failed: %v", err)
+	}
+	records, _ = LoadAccessRecords()
+	if r := records["new"]; r.AccessCount != 7 || !r.LastAccessed.Equal(later) {
+		t.Errorf("Merged history = %+v, want 7 accesses at %v", r, later)
+	}
+
+	pin, err := GetPin(2)
+	if err != nil || pin.ProjectID != "new" || pin.ProjectPath != newPath {
+		t.Errorf("Pin not remapped: %+v (%v)", pin, err)
+	}
+
+	if err := ForgetProject("new"); err != nil {
+		t.Fatalf("ForgetProject failed: %v", err)
+	}
+	if _, ok := LastAccessed("new"); ok || IsPinned("new") >= 0 {
+		t.Error("Expected history and pin to be forgotten")
+	}
+}
+
+func TestMoveStateCarriesSnoozesAndJournal(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	until := time.Now().Add(24 * time.Hour)
+	if err := Snooze("prototype", "/projects/prototype", until, "snoozed"); err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+	if _, err := journal.Annotate("prototype", "waiting on review"); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+
+	if err := MoveState("prototype", "app", "/projects/app"); err != nil {
+		t.Fatalf("MoveState failed: %v", err)
+	}
+
+	snoozes, _ := LoadSnoozes()
+	if _, ok := snoozes["/projects/prototype"]; ok {
+		t.Error("Snooze still keyed by the old path")
+	}
+	if s := snoozes["/projects/app"]; s.ProjectID != "app" || !s.Until.Equal(until) {
+		t.Errorf("Snooze not carried over: %+v", s)
+	}
+	if latest, _ := journal.Latest("app"); latest == nil || latest.Text != "waiting on review" {
+		t.Errorf("Journal not carried over: %+v", latest)
+	}
+}
+
+func TestDropState(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	if err := RecordAccess("legacy", "/projects/legacy"); err != nil {
+		t.Fatalf("RecordAccess failed: %v", err)
+	}
+	if err := AddPin(1, "legacy", "/projects/legacy"); err != nil {
+		t.Fatalf("AddPin failed: %v", err)
+	}
+	if err := Snooze("legacy", "/projects/legacy", time.Now().Add(time.Hour), "snoozed"); err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+	if _, err := journal.Annotate("legacy", "superseded"); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+
+	if err := DropState("legacy"); err != nil {
+		t.Fatalf("DropState failed: %v", err)
+	}
+	if _, ok := LastAccessed("legacy"); ok || IsPinned("legacy") >= 0 {
+		t.Error("Expected history and pin to be dropped")
+	}
+	if snoozes, _ := LoadSnoozes(); len(snoozes) != 0 {
+		t.Errorf("Snoozes left: %v", snoozes)
+	}
+	if latest, _ := journal.Latest("legacy"); latest != nil {
+		t.Errorf("Journal left: %+v", latest)
+	}
+}
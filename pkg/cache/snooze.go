@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/datakaicr/pk/pkg/statefile"
+	"github.com/datakaicr/pk/pkg/store"
+)
+
+// SnoozeRecord hides a project from 'pk triage' until a date
+type SnoozeRecord struct {
+	ProjectID   string    `json:"project_id"`
+	ProjectPath string    `json:"project_path"`
+	Until       time.Time `json:"until"`
+	Reason      string    `json:"reason"` // snoozed | kept
+}
+
+// LoadSnoozes reads triage snoozes, keyed by project path. Expired
+// snoozes are left in place; Snoozed ignores them.
+func LoadSnoozes() (map[string]SnoozeRecord, error) {
+	defer statefile.Lock("snoozes")()
+	return loadSnoozes()
+}
+
+func loadSnoozes() (map[string]SnoozeRecord, error) {
+	snoozes := make(map[string]SnoozeRecord)
+	if err := store.LoadJSON(store.Default(), "snoozes", &snoozes); err != nil {
+		return nil, err
+	}
+	return snoozes, nil
+}
+
+// Snoozed reports whether the project at path is snoozed at now
+func Snoozed(snoozes map[string]SnoozeRecord, path string, now time.Time) bool {
+	s, ok := snoozes[path]
+	return ok && now.Before(s.Until)
+}
+
+// Snooze hides a project from triage until the given time, dropping
+// snoozes that have already expired
+func Snooze(projectID, projectPath string, until time.Time, reason string) error {
+	defer statefile.Lock("snoozes")()
+
+	snoozes, err := loadSnoozes()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for path, s := range snoozes {
+		if !now.Before(s.Until) {
+			delete(snoozes, path)
+		}
+	}
+
+	snoozes[projectPath] = SnoozeRecord{ProjectID: projectID, ProjectPath: projectPath, Until: until, Reason: reason}
+	return store.SaveJSON(store.Default(), "snoozes", snoozes)
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnooze(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	defer os.Setenv("HOME", originalHome)
+
+	now := time.Now()
+	if err := Snooze("old", "/p/old", now.Add(-time.Hour), "snoozed"); err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+	if err := Snooze("etl", "/p/etl", now.Add(30*24*time.Hour), "kept"); err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+
+	snoozes, err := LoadSnoozes()
+	if err != nil {
+		t.Fatalf("LoadSnoozes failed: %v", err)
+	}
+	if !Snoozed(snoozes, "/p/etl", now) || snoozes["/p/etl"].Reason != "kept" {
+		t.Errorf("Expected /p/etl to be kept, got %+v", snoozes["/p/etl"])
+	}
+	if Snoozed(snoozes, "/p/etl", now.Add(31*24*time.Hour)) {
+		t.Error("Snooze should expire")
+	}
+	if _, ok := snoozes["/p/old"]; ok {
+		t.Error("Expired snoozes should be dropped when another is saved")
+	}
+	if Snoozed(snoozes, "/p/missing", now) {
+		t.Error("Unknown projects aren't snoozed")
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+// AccessStats summarises one project's access history
+type AccessStats struct {
+	ProjectID     string
+	Opens         int // All-time opens
+	LastAccessed  time.Time
+	Streak        int   // Consecutive days with an open, ending today or yesterday
+	LongestStreak int   // Longest run of consecutive days within the kept history
+	Weekly        []int // Opens per week (Monday to Sunday), oldest first, this week last
+}
+
+// ComputeAccessStats builds per-project statistics over the last weeks
+// weeks, most opened in that window first
+func ComputeAccessStats(records map[string]AccessRecord, weeks int, now time.Time) []AccessStats {
+	var stats []AccessStats
+	for id, record := range records {
+		s := AccessStats{
+			ProjectID:    id,
+			Opens:        max(record.AccessCount, 1),
+			LastAccessed: record.LastAccessed,
+			Weekly:       weeklyOpens(record.Days, weeks, now),
+		}
+		s.Streak, s.LongestStreak = streaks(record.Days, now)
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		wi, wj := sum(stats[i].Weekly), sum(stats[j].Weekly)
+		if wi != wj {
+			return wi > wj
+		}
+		if stats[i].Opens != stats[j].Opens {
+			return stats[i].Opens > stats[j].Opens
+		}
+		return stats[i].ProjectID < stats[j].ProjectID
+	})
+	return stats
+}
+
+// WeeklyTotals sums Weekly across projects
+func WeeklyTotals(stats []AccessStats, weeks int) []int {
+	totals := make([]int, weeks)
+	for _, s := range stats {
+		for i, n := range s.Weekly {
+			totals[i] += n
+		}
+	}
+	return totals
+}
+
+// WeekStart is midnight on the Monday of t's week, in local time
+func WeekStart(t time.Time) time.Time {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+}
+
+func weeklyOpens(days map[string]int, weeks int, now time.Time) []int {
+	counts := make([]int, weeks)
+	first := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	for day, n := range days {
+		date, err := time.ParseInLocation("2006-01-02", day, time.Local)
+		if err != nil || date.Before(first) {
+			continue
+		}
+		// Count whole days, not hours, so DST changes don't shift buckets
+		week := int(date.Sub(first).Hours()+12) / 24 / 7
+		if week < weeks {
+			counts[week] += n
+		}
+	}
+	return counts
+}
+
+// streaks returns the current and longest runs of consecutive days
+func streaks(days map[string]int, now time.Time) (current, longest int) {
+	run := 0
+	start := now.AddDate(0, 0, -AccessHistoryDays)
+	for d := start; !d.After(now); d = d.AddDate(0, 0, 1) {
+		if days[DayKey(d)] > 0 {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	// A streak still counts until a whole day is missed
+	current = run
+	if current == 0 && days[DayKey(now.AddDate(0, 0, -1))] > 0 {
+		for d := now.AddDate(0, 0, -1); days[DayKey(d)] > 0; d = d.AddDate(0, 0, -1) {
+			current++
+		}
+	}
+	return current, longest
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
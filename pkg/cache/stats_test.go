@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeAccessStats(t *testing.T) {
+	// Wednesday, so this week holds Monday to Wednesday
+	now := time.Date(2026, 10, 14, 15, 0, 0, 0, time.Local)
+	day := func(offset int) string { return DayKey(now.AddDate(0, 0, offset)) }
+
+	records := map[string]AccessRecord{
+		"daily": {AccessCount: 30, LastAccessed: now, Days: map[string]int{
+			day(0): 2, day(-1): 1, day(-2): 1, // 3-day streak through today
+			day(-10): 1, day(-11): 1, day(-12): 1, day(-13): 1, // longer run earlier
+		}},
+		"lapsed": {AccessCount: 3, LastAccessed: now.AddDate(0, 0, -1), Days: map[string]int{
+			day(-1): 2, day(-2): 1, // streak alive until a whole day is missed
+		}},
+		"legacy": {LastAccessed: now.AddDate(0, -2, 0)}, // before day history
+	}
+
+	stats := ComputeAccessStats(records, 3, now)
+
+	var ids []string
+	for _, s := range stats {
+		ids = append(ids, s.ProjectID)
+	}
+	if want := []string{"daily", "lapsed", "legacy"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("order = %q, want %q", ids, want)
+	}
+
+	daily := stats[0]
+	if daily.Streak != 3 || daily.LongestStreak != 4 {
+		t.Errorf("daily streaks = %d/%d, want 3/4", daily.Streak, daily.LongestStreak)
+	}
+	// Weeks starting Sep 28, Oct 5, Oct 12
+	if want := []int{4, 0, 4}; !reflect.DeepEqual(daily.Weekly, want) {
+		t.Errorf("daily weekly = %v, want %v", daily.Weekly, want)
+	}
+
+	if lapsed := stats[1]; lapsed.Streak != 2 {
+		t.Errorf("lapsed streak = %d, want 2", lapsed.Streak)
+	}
+	if legacy := stats[2]; legacy.Opens != 1 || legacy.Streak != 0 {
+		t.Errorf("legacy = %+v, want 1 open and no streak", legacy)
+	}
+
+	if totals := WeeklyTotals(stats, 3); !reflect.DeepEqual(totals, []int{4, 0, 7}) {
+		t.Errorf("totals = %v, want [4 0 7]", totals)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, 23, 0, 0, 0, time.Local)
+	if got, want := WeekStart(sunday), time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("WeekStart(Sunday) = %v, want %v", got, want)
+	}
+}
+
+func TestVisitKeepsDailyCounts(t *testing.T) {
+	old := DayKey(time.Now().AddDate(0, 0, -AccessHistoryDays-1))
+	records := map[string]AccessRecord{
+		"p": {ProjectID: "p", AccessCount: 5, Days: map[string]int{old: 3}},
+	}
+
+	visit(records, "p", "/p")
+	visit(records, "p", "/p")
+
+	days := records["p"].Days
+	if days[DayKey(time.Now())] != 2 {
+		t.Errorf("today = %d, want 2", days[DayKey(time.Now())])
+	}
+	if _, kept := days[old]; kept {
+		t.Error("days older than AccessHistoryDays should be dropped")
+	}
+}
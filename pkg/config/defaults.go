@@ -0,0 +1,164 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultsFile holds metadata inherited by every project beneath its
+// directory, e.g. ~/projects/clients/acme/.project-defaults.toml setting
+// consultant.client_name and context.aws_profile for all Acme projects.
+// Nearer files override farther ones, and the project's own values win.
+const DefaultsFile = ".project-defaults.toml"
+
+// notInherited are identity fields that never come from defaults
+var notInherited = map[string]bool{"project.name": true, "project.id": true, "root": true}
+
+// Inherited is a field whose value came from a defaults file
+type Inherited struct {
+	File  string // Path of the .project-defaults.toml that set it
+	value interface{}
+}
+
+// defaultsLayer is one parsed .project-defaults.toml
+type defaultsLayer struct {
+	path    string
+	content string
+	keys    []string // Dotted leaf keys it sets
+}
+
+var (
+	defaultsMu    sync.Mutex
+	defaultsCache = make(map[string]*defaultsLayer) // dir -> layer (nil if none)
+)
+
+// applyDefaults decodes the defaults files above projectDir into p,
+// outermost first, and returns the leaf keys each one set
+func applyDefaults(p *Project, projectDir string) (map[string]Inherited, error) {
+	layers := defaultsLayers(projectDir)
+	if len(layers) == 0 {
+		return nil, nil
+	}
+
+	sources := make(map[string]string)
+	for _, layer := range layers {
+		if _, err := toml.Decode(layer.content, p); err != nil {
+			return nil, &os.PathError{Op: "parse", Path: layer.path, Err: err}
+		}
+		for _, key := range layer.keys {
+			sources[key] = layer.path
+		}
+	}
+	p.ProjectInfo.Name = ""
+	p.ProjectInfo.ID = ""
+
+	inherited := make(map[string]Inherited, len(sources))
+	for key, file := range sources {
+		v, ok := fieldByKey(p, key)
+		if !ok {
+			continue
+		}
+		inherited[key] = Inherited{File: file, value: cloneValue(v)}
+	}
+	return inherited, nil
+}
+
+// defaultsLayers finds defaults files in the directories above projectDir,
+// outermost first. A defaults file in the project directory itself is not used.
+func defaultsLayers(projectDir string) []*defaultsLayer {
+	dir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil
+	}
+
+	var layers []*defaultsLayer
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		if layer := readDefaults(dir); layer != nil {
+			layers = append([]*defaultsLayer{layer}, layers...)
+		}
+	}
+	return layers
+}
+
+// readDefaults parses dir's defaults file once per process
+func readDefaults(dir string) *defaultsLayer {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+
+	if layer, cached := defaultsCache[dir]; cached {
+		return layer
+	}
+
+	var layer *defaultsLayer
+	path := filepath.Join(dir, DefaultsFile)
+	if data, err := os.ReadFile(path); err == nil {
+		layer = &defaultsLayer{path: path, content: string(data)}
+		var probe Project
+		if md, err := toml.Decode(layer.content, &probe); err == nil {
+			for _, k := range md.Keys() {
+				key := k.String()
+				if v, ok := fieldByKey(&probe, key); ok && v.Kind() != reflect.Struct && !notInherited[key] {
+					layer.keys = append(layer.keys, key)
+				}
+			}
+		}
+	}
+
+	defaultsCache[dir] = layer
+	return layer
+}
+
+// ResetDefaultsCache forgets parsed defaults files, for long-running
+// processes (the daemon) and tests
+func ResetDefaultsCache() {
+	defaultsMu.Lock()
+	defaultsCache = make(map[string]*defaultsLayer)
+	defaultsMu.Unlock()
+}
+
+// InheritedFields returns the fields whose current value came from a
+// defaults file, keyed by dotted path
+func (p *Project) InheritedFields() map[string]Inherited {
+	fields := make(map[string]Inherited)
+	for key, inh := range p.inherited {
+		if v, ok := fieldByKey(p, key); ok && reflect.DeepEqual(v.Interface(), inh.value) {
+			fields[key] = inh
+		}
+	}
+	return fields
+}
+
+// stripInherited zeroes fields still holding their inherited value, so
+// saving a project doesn't copy its defaults into the file
+func (p *Project) stripInherited() {
+	for key := range p.InheritedFields() {
+		if v, ok := fieldByKey(p, key); ok && v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+}
+
+// cloneValue copies a field value so later decoding can't alias it
+func cloneValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c.Interface()
+	}
+	return v.Interface()
+}
+
+// isDefinedKey reports whether a dotted key was set in the decoded file
+func isDefinedKey(md toml.MetaData, key string) bool {
+	return md.IsDefined(strings.Split(key, ".")...)
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProjectInheritsDefaults(t *testing.T) {
+	root := t.TempDir()
+	clients := filepath.Join(root, "clients")
+	acme := filepath.Join(clients, "acme")
+	project := filepath.Join(acme, "api")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+	defer ResetDefaultsCache()
+
+	files := map[string]string{
+		filepath.Join(clients, DefaultsFile): `[project]
+name = "ignored"
+type = "client-project"
+
+[context]
+aws_profile = "clients"
+git_identity = "work"
+`,
+		filepath.Join(acme, DefaultsFile): `[context]
+aws_profile = "acme-dev"
+
+[consultant]
+client_name = "Acme Corp"
+`,
+		filepath.Join(project, ".project.toml"): `[project]
+name = "api"
+id = "api"
+status = "active"
+
+[context]
+git_identity = "acme"
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	p, err := LoadProject(filepath.Join(project, ".project.toml"))
+	if err != nil {
+		t.Fatalf("LoadProject failed: %v", err)
+	}
+
+	if p.ProjectInfo.Name != "api" || p.ProjectInfo.Type != "client-project" {
+		t.Errorf("Expected own name and inherited type, got %+v", p.ProjectInfo)
+	}
+	if p.Context.AWSProfile != "acme-dev" {
+		t.Errorf("Expected nearer defaults to win, got aws_profile %q", p.Context.AWSProfile)
+	}
+	if p.Context.GitIdentity != "acme" {
+		t.Errorf("Expected project value to win, got git_identity %q", p.Context.GitIdentity)
+	}
+	if p.Consultant.ClientName != "Acme Corp" {
+		t.Errorf("Expected inherited client_name, got %q", p.Consultant.ClientName)
+	}
+
+	inherited := p.InheritedFields()
+	if inherited["context.aws_profile"].File != filepath.Join(acme, DefaultsFile) {
+		t.Errorf("Wrong source for aws_profile: %+v", inherited)
+	}
+	if _, ok := inherited["context.git_identity"]; ok {
+		t.Error("Project's own git_identity should not count as inherited")
+	}
+
+	// Saving keeps inherited values out of the file, except ones changed since
+	p.Context.AWSProfile = "acme-prod"
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(project, ".project.toml"))
+	content := string(data)
+	for _, gone := range []string{"Acme Corp", "client-project", "[consultant]"} {
+		if strings.Contains(content, gone) {
+			t.Errorf("Saved file contains inherited %q:\n%s", gone, content)
+		}
+	}
+	for _, kept := range []string{`aws_profile = "acme-prod"`, `git_identity = "acme"`} {
+		if !strings.Contains(content, kept) {
+			t.Errorf("Saved file missing %q:\n%s", kept, content)
+		}
+	}
+}
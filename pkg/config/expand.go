@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateAction matches one {{...}} action in a command
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+var expandFuncs = template.FuncMap{
+	"raw": func(s string) string { return s },
+}
+
+// Expand fills metadata references in a session or kind command, such as
+// {{.Context.AWSProfile}} or {{.Consultant.ClientName}}, from the project,
+// so one shared layout can run client-specific commands. Each value is
+// quoted for sh where it needs to be; {{raw .Field}} inserts it as written.
+//
+// Only actions that refer to the project's fields are filled in. Other
+// tools' templates, like docker ps --format '{{.Names}}' or a kubectl
+// go-template, are left as written.
+func (p *Project) Expand(command string) (string, error) {
+	if !strings.Contains(command, "{{") {
+		return command, nil
+	}
+	var expandErr error
+	expanded := templateAction.ReplaceAllStringFunc(command, func(action string) string {
+		if expandErr != nil {
+			return action
+		}
+		value, err := p.expandAction(action)
+		if err != nil {
+			expandErr = err
+		}
+		return value
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
+
+// expandAction fills in a single action, or returns it unchanged if it
+// isn't a reference to the project
+func (p *Project) expandAction(action string) (string, error) {
+	tmpl, err := template.New("command").Funcs(expandFuncs).Option("missingkey=error").Parse(action)
+	if err != nil || !refersToProject(tmpl.Tree.Root) {
+		return action, nil
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, p); err != nil {
+		return "", err
+	}
+	if isRawAction(tmpl.Tree.Root) {
+		return b.String(), nil
+	}
+	return shellQuote(b.String()), nil
+}
+
+var projectType = reflect.TypeFor[*Project]()
+
+// refersToProject reports whether a parsed action uses a field or method
+// of Project
+func refersToProject(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			if refersToProject(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return refersToProject(n.Pipe)
+	case *parse.PipeNode:
+		for _, cmd := range n.Cmds {
+			if refersToProject(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if refersToProject(arg) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		_, isField := projectType.Elem().FieldByName(n.Ident[0])
+		_, isMethod := projectType.MethodByName(n.Ident[0])
+		return isField || isMethod
+	}
+	return false
+}
+
+// isRawAction reports whether an action ends in raw, {{raw .Field}} or
+// {{.Field | raw}}
+func isRawAction(root *parse.ListNode) bool {
+	if len(root.Nodes) != 1 {
+		return false
+	}
+	action, ok := root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Cmds) == 0 {
+		return false
+	}
+	last := action.Pipe.Cmds[len(action.Pipe.Cmds)-1]
+	ident, ok := last.Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == "raw"
+}
+
+// shellSafe matches values sh reads as one word without quoting
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s as a single sh word, unless it already is one
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,58 @@
+package config
+
+import "testing"
+
+func TestExpand(t *testing.T) {
+	var p Project
+	p.ProjectInfo.ID = "acme-etl"
+	p.Context.AWSProfile = "acme-prod"
+	p.Consultant.ClientName = "Acme Corp"
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"aws sso login --profile {{.Context.AWSProfile}}", "aws sso login --profile acme-prod"},
+		{"echo {{.Consultant.ClientName}} / {{.ProjectInfo.ID}}", "echo 'Acme Corp' / acme-etl"},
+		{`{{or .Context.GCloudProject "none"}}`, "none"},
+		{"echo {{raw .Consultant.ClientName}}", "echo Acme Corp"},
+		{"echo {{.Consultant.ClientName | raw}}", "echo Acme Corp"},
+		{"echo {{.GetClientName}}", "echo 'Acme Corp'"},
+		{"awk '{ print $1 }' notes.txt", "awk '{ print $1 }' notes.txt"},
+		// Other tools' templates pass through
+		{"watch docker ps --format '{{.Names}}'", "watch docker ps --format '{{.Names}}'"},
+		{"docker inspect --format '{{json .}}' db", "docker inspect --format '{{json .}}' db"},
+		{"kubectl get pods -n {{.ProjectInfo.ID}} -o go-template='{{range .items}}{{.metadata.name}}{{end}}'",
+			"kubectl get pods -n acme-etl -o go-template='{{range .items}}{{.metadata.name}}{{end}}'"},
+	}
+	for _, tt := range tests {
+		got, err := p.Expand(tt.command)
+		if err != nil {
+			t.Errorf("Expand(%q): %v", tt.command, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"{{.Context.Missing}}", "{{.ProjectInfo.ID.Nope}}"} {
+		if _, err := p.Expand(bad); err == nil {
+			t.Errorf("Expand(%q) should fail", bad)
+		}
+	}
+}
+
+func TestExpandQuotesForShell(t *testing.T) {
+	var p Project
+	p.Consultant.ClientName = "O'Brien & Sons; rm -rf ~"
+	p.Notes.Description = ""
+
+	got, err := p.Expand("echo {{.Consultant.ClientName}} {{.Notes.Description}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `echo 'O'\''Brien & Sons; rm -rf ~' ''`; got != want {
+		t.Errorf("Expand = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a dotted key of .project.toml and its value as text
+type Field struct {
+	Key   string
+	Value string
+}
+
+// FieldKeys lists the keys 'pk config' can read and write, in schema order.
+// Legacy keys, [detected], and lists of tables (tmux.windows) are left out.
+func FieldKeys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			name := tomlName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			key := name
+			if prefix != "" {
+				key = prefix + "." + name
+			}
+			if legacySections[key] || legacyKeys[key] {
+				continue
+			}
+
+			ft := t.Field(i).Type
+			switch {
+			case ft.Kind() == reflect.Struct:
+				walk(ft, key)
+			case isScalarField(ft):
+				keys = append(keys, key)
+			}
+		}
+	}
+	walk(reflect.TypeOf(Project{}), "")
+	return keys
+}
+
+// isScalarField reports whether a field type can be set from one string
+func isScalarField(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Pointer:
+		// Optional booleans whose default is true, e.g. tmux.attach
+		return t.Elem().Kind() == reflect.Bool
+	}
+	return false
+}
+
+// Fields returns every settable field with a value, in schema order
+func (p *Project) Fields() []Field {
+	var fields []Field
+	for _, key := range FieldKeys() {
+		v, _ := fieldByKey(p, key)
+		if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: FieldValue(p, key)})
+	}
+	return fields
+}
+
+// Document returns every settable field nested by section, keyed like
+// .project.toml, for JSON and YAML output. Unset lists are empty and unset
+// optional booleans left out.
+func (p *Project) Document() map[string]any {
+	doc := make(map[string]any)
+	for _, key := range FieldKeys() {
+		v, _ := fieldByKey(p, key)
+		var value any
+		switch {
+		case v.Kind() == reflect.Pointer:
+			if v.IsNil() {
+				continue
+			}
+			value = v.Elem().Interface()
+		case v.Kind() == reflect.Slice && v.Len() == 0:
+			value = []string{}
+		default:
+			value = v.Interface()
+		}
+
+		section := doc
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := section[part].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				section[part] = next
+			}
+			section = next
+		}
+		section[parts[len(parts)-1]] = value
+	}
+	return doc
+}
+
+// SetField parses value for the field at a dotted key and assigns it. Lists
+// are comma-separated; enum, date, and required fields are checked first.
+// Setting a field confirms any auto-detected value it replaces.
+func (p *Project) SetField(key, value string) error {
+	if !slices.Contains(FieldKeys(), key) {
+		return fmt.Errorf("unknown key %q (see 'pk config list --keys')", key)
+	}
+	v, _ := fieldByKey(p, key)
+	if v.Kind() == reflect.Pointer {
+		// Empty unsets an optional value, restoring its default
+		if value == "" {
+			v.SetZero()
+			p.Confirm(key)
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if value == "" && slices.Contains(RequiredKeys, key) {
+			return fmt.Errorf("%s is required and can't be empty", key)
+		}
+		if allowed, ok := EnumKeys[key]; ok && value != "" && !slices.Contains(allowed, value) {
+			return fmt.Errorf("invalid value %q for %s (want %s)", value, key, strings.Join(allowed, ", "))
+		}
+		if slices.Contains(DateKeys, key) && value != "" {
+			if _, err := time.Parse(DateLayout, value); err != nil {
+				return fmt.Errorf("malformed date %q for %s (want YYYY-MM-DD)", value, key)
+			}
+		}
+		v.SetString(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s (want true or false)", value, key)
+		}
+		v.SetBool(b)
+
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	}
+
+	p.Confirm(key)
+	return nil
+}
+
+// FieldChoices returns the values a field can take if they're a fixed set:
+// enums and booleans. Optional fields offer "" to unset them first.
+func FieldChoices(key string) []string {
+	v, ok := fieldByKey(&Project{}, key)
+	if !ok {
+		return nil
+	}
+	switch {
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Bool:
+		return []string{"", "true", "false"}
+	case v.Kind() == reflect.Bool:
+		return []string{"false", "true"}
+	}
+
+	allowed, ok := EnumKeys[key]
+	if !ok {
+		return nil
+	}
+	if slices.Contains(RequiredKeys, key) {
+		return slices.Clone(allowed)
+	}
+	return append([]string{""}, allowed...)
+}
+
+// FieldDescription returns what a field is for, as in the JSON Schema
+func FieldDescription(key string) string {
+	return schemaDescriptions[key]
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFieldKeys(t *testing.T) {
+	keys := FieldKeys()
+	for _, want := range []string{"project.status", "tech.stack", "consultant.billable", "tmux.layout"} {
+		if !slices.Contains(keys, want) {
+			t.Errorf("FieldKeys() missing %s", want)
+		}
+	}
+	for _, unwanted := range []string{"ownership.primary", "links.conduit_graph", "tmux.windows", "detected"} {
+		if slices.Contains(keys, unwanted) {
+			t.Errorf("FieldKeys() should not include %s", unwanted)
+		}
+	}
+}
+
+func TestSetField(t *testing.T) {
+	p := &Project{}
+	p.ProjectInfo.Status = "active"
+	p.MarkDetected("tech.stack", ConfidenceMedium, "package.json")
+
+	if err := p.SetField("tech.stack", " go, postgres ,,"); err != nil {
+		t.Fatalf("SetField: %v", err)
+	}
+	if want := []string{"go", "postgres"}; !reflect.DeepEqual(p.Tech.Stack, want) {
+		t.Errorf("stack = %v, want %v", p.Tech.Stack, want)
+	}
+	if _, detected := p.Detected["tech.stack"]; detected {
+		t.Error("setting a field should confirm its detected value")
+	}
+
+	if err := p.SetField("consultant.billable", "true"); err != nil || !p.Consultant.Billable {
+		t.Errorf("billable = %v, err %v", p.Consultant.Billable, err)
+	}
+
+	for _, tt := range []struct{ key, value string }{
+		{"project.status", "bogus"},
+		{"project.name", ""},
+		{"dates.started", "2024-13-01"},
+		{"consultant.billable", "yes"},
+		{"ownership.primary", "client"},
+		{"no.such", "x"},
+	} {
+		if err := p.SetField(tt.key, tt.value); err == nil {
+			t.Errorf("SetField(%q, %q) should fail", tt.key, tt.value)
+		}
+	}
+	if p.ProjectInfo.Status != "active" {
+		t.Errorf("rejected value was assigned: status = %q", p.ProjectInfo.Status)
+	}
+}
+
+func TestFieldsSkipsEmpty(t *testing.T) {
+	p := &Project{}
+	p.ProjectInfo.ID = "dojo"
+	p.Tech.Stack = []string{}
+	p.Consultant.Billable = true
+
+	want := []Field{{"project.id", "dojo"}, {"consultant.billable", "true"}}
+	if got := p.Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	p := &Project{}
+	p.ProjectInfo.ID = "dojo"
+	p.Consultant.Billable = true
+
+	doc := p.Document()
+	project := doc["project"].(map[string]any)
+	if project["id"] != "dojo" || project["status"] != "" {
+		t.Errorf("project = %v", project)
+	}
+	if stack := doc["tech"].(map[string]any)["stack"]; !reflect.DeepEqual(stack, []string{}) {
+		t.Errorf("unset stack = %#v, want an empty list", stack)
+	}
+	if doc["consultant"].(map[string]any)["billable"] != true {
+		t.Errorf("billable = %v", doc["consultant"])
+	}
+	if _, ok := doc["tmux"].(map[string]any)["attach"]; ok {
+		t.Error("unset tmux.attach should be left out")
+	}
+}
+
+func TestFieldChoices(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"project.status", StatusValues}, // Required, no unset
+		{"datakai.visibility", append([]string{""}, VisibilityValues...)},
+		{"consultant.billable", []string{"false", "true"}},
+		{"tmux.attach", []string{"", "true", "false"}},
+		{"project.name", nil},
+		{"nope", nil},
+	}
+	for _, tt := range tests {
+		if got := FieldChoices(tt.key); !slices.Equal(got, tt.want) {
+			t.Errorf("FieldChoices(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
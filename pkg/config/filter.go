@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// filterAliases are short names for common filter keys
+var filterAliases = map[string]string{
+	"id":         "project.id",
+	"name":       "project.name",
+	"status":     "project.status",
+	"type":       "project.type",
+	"kind":       "project.kind",
+	"mirror":     "project.mirror",
+	"client":     "consultant.client_name",
+	"owner":      "consultant.ownership",
+	"stack":      "tech.stack",
+	"domain":     "tech.domain",
+	"visibility": "datakai.visibility",
+}
+
+// Filter selects projects by field values, e.g. client=Acme,status=active.
+// Every term must match; values are case-insensitive globs, and a list
+// field matches if any item does.
+type Filter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	key   string // Dotted field key
+	value string // Lowercased glob
+}
+
+// FilterKeys lists the short keys a filter accepts besides dotted field keys
+func FilterKeys() []string {
+	keys := make([]string, 0, len(filterAliases))
+	for key := range filterAliases {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParseFilter reads key=value terms. Each argument may hold several,
+// comma-separated.
+func ParseFilter(exprs ...string) (Filter, error) {
+	var f Filter
+	for _, expr := range exprs {
+		for _, term := range strings.Split(expr, ",") {
+			if term = strings.TrimSpace(term); term == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(term, "=")
+			if !ok {
+				return Filter{}, fmt.Errorf("filter %q: want key=value", term)
+			}
+			key = strings.TrimSpace(key)
+			if alias, ok := filterAliases[key]; ok {
+				key = alias
+			} else if !slices.Contains(FieldKeys(), key) {
+				return Filter{}, fmt.Errorf("filter %q: unknown key %q (use %s, or a key from 'pk config list --keys')",
+					term, key, strings.Join(FilterKeys(), ", "))
+			}
+			value = strings.ToLower(strings.TrimSpace(value))
+			if _, err := path.Match(value, ""); err != nil {
+				return Filter{}, fmt.Errorf("filter %q: %w", term, err)
+			}
+			f.terms = append(f.terms, filterTerm{key: key, value: value})
+		}
+	}
+	return f, nil
+}
+
+// Empty reports whether the filter has no terms (and so matches everything)
+func (f Filter) Empty() bool {
+	return len(f.terms) == 0
+}
+
+// Has reports whether the filter constrains a field, by dotted key or alias
+func (f Filter) Has(key string) bool {
+	if alias, ok := filterAliases[key]; ok {
+		key = alias
+	}
+	return slices.ContainsFunc(f.terms, func(t filterTerm) bool { return t.key == key })
+}
+
+// Matches reports whether a project satisfies every term
+func (f Filter) Matches(p *Project) bool {
+	for _, t := range f.terms {
+		if !slices.ContainsFunc(filterValues(p, t.key), func(v string) bool {
+			ok, _ := path.Match(t.value, strings.ToLower(v))
+			return ok
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValues returns a field's values to match: each item of a list, or
+// the one value (which may be empty)
+func filterValues(p *Project, key string) []string {
+	switch key {
+	case "consultant.ownership":
+		return []string{p.GetOwner()}
+	case "consultant.client_name":
+		return []string{p.GetClientName()}
+	}
+	v, ok := fieldByKey(p, key)
+	if ok && v.Kind() == reflect.Slice {
+		return v.Interface().([]string)
+	}
+	return []string{FieldValue(p, key)}
+}
@@ -0,0 +1,47 @@
+package config
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	p := &Project{}
+	p.ProjectInfo.ID = "acme-etl"
+	p.ProjectInfo.Status = "active"
+	p.Consultant.ClientName = "Acme Corp"
+	p.Tech.Stack = []string{"python", "dbt"}
+
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{"", true},
+		{"client=acme corp", true},
+		{"client=Acme*", true},
+		{"client=Globex", false},
+		{"client=acme*,status=active", true},
+		{"client=acme*, status=archived", false},
+		{"stack=dbt", true}, // Any item of a list
+		{"tech.stack=go", false},
+		{"project.id=acme-*", true},
+		{"kind=", true}, // Unset
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tt.expr, err)
+		}
+		if got := f.Matches(p); got != tt.match {
+			t.Errorf("%q matches = %v, want %v", tt.expr, got, tt.match)
+		}
+	}
+
+	f, _ := ParseFilter("client=acme*", "status=active")
+	if !f.Has("status") || !f.Has("consultant.client_name") || f.Has("type") {
+		t.Error("Has should know the filtered keys by alias or dotted key")
+	}
+
+	for _, bad := range []string{"acme", "color=red", "client=[a"} {
+		if _, err := ParseFilter(bad); err == nil {
+			t.Errorf("ParseFilter(%q) should fail", bad)
+		}
+	}
+}
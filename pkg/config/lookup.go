@@ -0,0 +1,87 @@
+package config
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NormalizeLink reduces a URL or git remote to lowercase host/path, without
+// scheme, user, port, query or a .git suffix, so the https, ssh and
+// scp-like (git@host:path) forms of one repository compare equal. Anything
+// that isn't a URL is trimmed and lowercased.
+func NormalizeLink(raw string) string {
+	if link, ok := normalizeRemote(raw); ok {
+		return link
+	}
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(raw), "/"))
+}
+
+// normalizeRemote normalizes raw if it's a URL or git remote, reporting
+// whether it was one
+func normalizeRemote(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(raw, "@"); ok && !strings.Contains(at, "/") && strings.Contains(rest, ":") {
+		host, path, _ = strings.Cut(rest, ":")
+	} else {
+		return "", false
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return strings.ToLower(strings.TrimPrefix(host, "www.") + "/" + path), true
+}
+
+// LookupResult is a project one of whose links matches a lookup
+type LookupResult struct {
+	Project *Project
+	Key     string // The matching link, e.g. "links.repository"
+	Link    string
+}
+
+// lookupLinks are the project links a URL is matched against
+var lookupLinks = []string{"links.repository", "links.documentation"}
+
+// LookupURL returns the projects with a repository or documentation link
+// that is rawURL or a parent of it, e.g. the repository of a pull request
+// or file page. Longer (more specific) links come first.
+func LookupURL(projects []*Project, rawURL string) []LookupResult {
+	target := NormalizeLink(rawURL)
+	if target == "" {
+		return nil
+	}
+
+	var results []LookupResult
+	for _, p := range projects {
+		for _, key := range lookupLinks {
+			// Documentation can be a path in the project, which no URL matches
+			link := FieldValue(p, key)
+			normalized, ok := normalizeRemote(link)
+			if !ok {
+				continue
+			}
+			if target == normalized || strings.HasPrefix(target, normalized+"/") {
+				results = append(results, LookupResult{Project: p, Key: key, Link: link})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return len(NormalizeLink(results[i].Link)) > len(NormalizeLink(results[j].Link))
+	})
+	return results
+}
+
+// LookupField returns the projects whose field at a dotted key equals
+// value, ignoring case
+func LookupField(projects []*Project, key, value string) []LookupResult {
+	var results []LookupResult
+	for _, p := range projects {
+		if v := FieldValue(p, key); v != "" && strings.EqualFold(v, strings.TrimSpace(value)) {
+			results = append(results, LookupResult{Project: p, Key: key, Link: v})
+		}
+	}
+	return results
+}
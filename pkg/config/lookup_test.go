@@ -0,0 +1,60 @@
+package config
+
+import "testing"
+
+func TestNormalizeLink(t *testing.T) {
+	for _, raw := range []string{
+		"https://github.com/Acme/etl",
+		"https://www.github.com/acme/etl/",
+		"git@github.com:acme/etl.git",
+		"ssh://git@github.com:22/acme/etl.git",
+	} {
+		if got := NormalizeLink(raw); got != "github.com/acme/etl" {
+			t.Errorf("NormalizeLink(%q) = %q", raw, got)
+		}
+	}
+}
+
+func TestLookupURL(t *testing.T) {
+	link := func(id, repo, docs string) *Project {
+		p := &Project{}
+		p.ProjectInfo.ID = id
+		p.Links.Repository = repo
+		p.Links.Documentation = docs
+		return p
+	}
+	projects := []*Project{
+		link("etl", "git@github.com:acme/etl.git", "docs"),
+		link("etl-docs", "", "https://github.com/acme/etl/wiki"),
+		link("etl2", "https://github.com/acme/etl2", ""),
+	}
+
+	results := LookupURL(projects, "https://github.com/acme/etl/wiki/Setup")
+	if len(results) != 2 || results[0].Project.ProjectInfo.ID != "etl-docs" || results[1].Project.ProjectInfo.ID != "etl" {
+		t.Fatalf("results = %+v, want etl-docs then etl", results)
+	}
+	if results[1].Key != "links.repository" {
+		t.Errorf("key = %s", results[1].Key)
+	}
+
+	// A sibling with a longer name isn't a parent
+	if results := LookupURL(projects, "https://github.com/acme/etl2/pull/4"); len(results) != 1 || results[0].Project.ProjectInfo.ID != "etl2" {
+		t.Errorf("etl2 pull request = %+v", results)
+	}
+	if results := LookupURL(projects, "docs/index.md"); len(results) != 0 {
+		t.Errorf("a documentation path shouldn't match, got %+v", results)
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	p := &Project{}
+	p.ProjectInfo.ID = "etl"
+	p.DataKai.ScriptoriumProject = "SCR-42"
+
+	if results := LookupField([]*Project{p}, "datakai.scriptorium_project", "scr-42"); len(results) != 1 {
+		t.Errorf("results = %+v", results)
+	}
+	if results := LookupField([]*Project{p}, "datakai.conduit_graph", ""); len(results) != 0 {
+		t.Errorf("an empty value shouldn't match unset fields, got %+v", results)
+	}
+}
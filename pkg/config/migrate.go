@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Change is one field moved (or section dropped) by schema migration
+type Change struct {
+	From  string // Legacy dotted key or [section]
+	To    string // New dotted key; empty when the legacy value is simply dropped
+	Value string
+}
+
+// String renders the change for a diff summary
+func (c Change) String() string {
+	if c.To == "" {
+		return fmt.Sprintf("- %s", c.From)
+	}
+	return fmt.Sprintf("%s → %s = %q", c.From, c.To, c.Value)
+}
+
+// MigrationChanges lists what saving the project would change relative to
+// the legacy file it was loaded from. Empty if the file is current.
+func (p *Project) MigrationChanges() []Change {
+	if !p.migrated {
+		return nil
+	}
+
+	var changes []Change
+	add := func(from, to, value string) {
+		if value != "" {
+			changes = append(changes, Change{From: from, To: to, Value: value})
+		}
+	}
+
+	o := p.LegacyOwnership
+	add("ownership.primary", "consultant.ownership", o.Primary)
+	add("ownership.license_model", "consultant.license_model", o.LicenseModel)
+	if len(o.Partners) > 0 {
+		add("ownership.partners", "consultant.partner", o.Partners[0])
+		if len(o.Partners) > 1 {
+			changes = append(changes, Change{From: "ownership.partners[1:] (" + strings.Join(o.Partners[1:], ", ") + ")"})
+		}
+	}
+	add("ownership.visibility", "datakai.visibility", o.Visibility)
+
+	c := p.LegacyClient
+	add("client.end_client", "consultant.client_name", c.EndClient)
+	add("client.intermediary", "consultant.partner", c.Intermediary)
+	add("client.my_role", "consultant.my_role", c.MyRole)
+
+	add("links.scriptorium_project", "datakai.scriptorium_project", p.Links.ScriptoriumProject)
+	add("links.conduit_graph", "datakai.conduit_graph", p.Links.ConduitGraph)
+
+	for _, section := range p.legacyDefined {
+		changes = append(changes, Change{From: "[" + section + "]"})
+	}
+
+	return changes
+}
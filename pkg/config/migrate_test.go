@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".project.toml")
+
+	write := func(content string) *Project {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		p, err := LoadProject(path)
+		if err != nil {
+			t.Fatalf("LoadProject failed: %v", err)
+		}
+		return p
+	}
+
+	current := write("[project]\nname = \"x\"\n\n[consultant]\nownership = \"client\"\n")
+	if changes := current.MigrationChanges(); len(changes) != 0 {
+		t.Errorf("Expected no changes for current schema, got %v", changes)
+	}
+
+	// An empty legacy section still counts: saving drops it
+	empty := write("[project]\nname = \"x\"\n\n[ownership]\nprimary = \"\"\n")
+	changes := empty.MigrationChanges()
+	if len(changes) != 1 || changes[0].String() != "- [ownership]" {
+		t.Errorf("Expected empty section removal, got %v", changes)
+	}
+
+	legacy := write(`[project]
+name = "x"
+
+[client]
+intermediary = "West Monroe"
+
+[links]
+conduit_graph = "x-kg"
+`)
+	var got []string
+	for _, c := range legacy.MigrationChanges() {
+		got = append(got, c.String())
+	}
+	want := []string{
+		`client.intermediary → consultant.partner = "West Monroe"`,
+		`links.conduit_graph → datakai.conduit_graph = "x-kg"`,
+		"- [client]",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Change %d = %q, expected %q", i, got[i], want[i])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 
@@ -9,7 +10,8 @@ import (
 
 // Project represents a .project.toml file
 type Project struct {
-	Path string `toml:"-"` // Full path to project directory (internal, not serialized)
+	Path string `toml:"-"`              // Full path to project directory (internal, not serialized)
+	Root string `toml:"root,omitempty"` // Project directory, set only in sidecar metadata files
 
 	// ==========================================
 	// CORE SCHEMA (universal, always present)
@@ -21,6 +23,9 @@ type Project struct {
 		ID     string `toml:"id"`
 		Status string `toml:"status"`
 		Type   string `toml:"type"`
+		Kind   string `toml:"kind,omitempty"`   // Preset from 'pk kind list', e.g. "dbt"
+		Nested bool   `toml:"nested,omitempty"` // Holds other projects; discovery searches below it
+		Mirror bool   `toml:"mirror,omitempty"` // Read-only reference checkout, tracked but never modified
 	} `toml:"project"`
 
 	// [tech] section
@@ -50,9 +55,16 @@ type Project struct {
 
 	// [tmux] section (optional)
 	Tmux struct {
-		Layout  string       `toml:"layout"`
-		Windows []TmuxWindow `toml:"windows"`
-	} `toml:"tmux"`
+		Layout  string            `toml:"layout"`
+		Windows []TmuxWindow      `toml:"windows"`
+		Attach  *bool             `toml:"attach,omitempty"` // false: create the session in the background
+		Env     map[string]string `toml:"env,omitempty"`    // Exported into the session environment
+
+		// Shell commands run by pk in the project directory
+		OnCreate string `toml:"on_create,omitempty"` // After the session is created
+		OnAttach string `toml:"on_attach,omitempty"` // Each time pk opens the session
+		OnDetach string `toml:"on_detach,omitempty"` // When a client detaches from the session
+	} `toml:"tmux,omitempty"`
 
 	// [context] section (optional)
 	Context struct {
@@ -61,13 +73,29 @@ type Project struct {
 		GCloudProject     string `toml:"gcloud_project"`
 		DatabricksProfile string `toml:"databricks_profile"`
 		SnowflakeAccount  string `toml:"snowflake_account"`
+		KubeContext       string `toml:"kube_context"`
 		GitIdentity       string `toml:"git_identity"`
-	} `toml:"context"`
+		AutoSwitch        *bool  `toml:"auto_switch,omitempty"` // false: never switch CLI state on open
+	} `toml:"context,omitempty"`
+
+	// [editor] section (optional)
+	Editor struct {
+		VSCodeProfile string `toml:"vscode_profile"` // Overrides the client's profile from global config
+	} `toml:"editor,omitempty"`
 
 	// [dev] section (optional) - internal development planning
 	Dev struct {
 		Roadmap string `toml:"roadmap"` // Path to roadmap file (e.g., ".dev/ROADMAP.md")
-	} `toml:"dev"`
+	} `toml:"dev,omitempty"`
+
+	// [relations] section (optional) - links to other projects
+	Relations struct {
+		DependsOn []string `toml:"depends_on"` // IDs of projects this one builds on
+	} `toml:"relations,omitempty"`
+
+	// [detected] section (optional) - provenance of auto-detected fields,
+	// keyed by dotted path, e.g. "tech.stack"
+	Detected map[string]Detection `toml:"detected,omitempty"`
 
 	// ==========================================
 	// CONSULTANT EXTENSION (optional)
@@ -83,7 +111,7 @@ type Project struct {
 		LicenseModel    string `toml:"license_model"`    // proprietary | client-owned | open-source
 		Billable        bool   `toml:"billable"`
 		RateType        string `toml:"rate_type"` // fixed | hourly | retainer
-	} `toml:"consultant"`
+	} `toml:"consultant,omitempty"`
 
 	// ==========================================
 	// DATAKAI EXTENSION (optional)
@@ -98,7 +126,7 @@ type Project struct {
 		ProductCategory    string   `toml:"product_category"` // infrastructure | client-deliverable | internal-tool
 		RevenueModel       string   `toml:"revenue_model"`    // saas | consulting | open-source | internal
 		Maturity           string   `toml:"maturity"`         // experimental | mvp | production | deprecated
-	} `toml:"datakai"`
+	} `toml:"datakai,omitempty"`
 
 	// ==========================================
 	// LEGACY FIELDS (backward compatibility)
@@ -120,31 +148,153 @@ type Project struct {
 
 	// Track if migration occurred
 	migrated bool `toml:"-"`
+
+	// Legacy sections present in the file, even if empty
+	legacyDefined []string `toml:"-"`
+
+	// Fields filled from .project-defaults.toml files above the project
+	inherited map[string]Inherited `toml:"-"`
+
+	// Sidecar metadata file the project was loaded from
+	sidecarFile string `toml:"-"`
 }
 
 // TmuxWindow represents a window configuration
 type TmuxWindow struct {
-	Name    string `toml:"name"`
-	Command string `toml:"command"`
-	Path    string `toml:"path"`
+	Name    string     `toml:"name"`
+	Command string     `toml:"command"`
+	Path    string     `toml:"path"`
+	Panes   []TmuxPane `toml:"panes,omitempty"` // Split off after the window opens, in order
 }
 
-// LoadProject reads a .project.toml file
+// TmuxPane is a pane split off a window. Each pane splits the one before
+// it, starting with the window's own pane.
+type TmuxPane struct {
+	Split   string `toml:"split,omitempty"`   // vertical (stacked, default) | horizontal (side by side)
+	Size    int    `toml:"size,omitempty"`    // Percent of the split pane to take; 0 halves it
+	Command string `toml:"command,omitempty"` // Typed into the pane's shell
+	Path    string `toml:"path,omitempty"`    // Defaults to the window's path
+}
+
+// LoadProject reads a .project.toml file, or a sidecar metadata file
 func LoadProject(path string) (*Project, error) {
 	var project Project
 	project.Path = filepath.Dir(path)
+	if IsSidecar(path) {
+		root, err := sidecarRoot(path)
+		if err != nil {
+			return nil, err
+		}
+		project.Path = root
+		project.sidecarFile = path
+	}
+
+	// Inherit from .project-defaults.toml files, then let the project override
+	inherited, err := applyDefaults(&project, project.Path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Decode TOML file
-	if _, err := toml.DecodeFile(path, &project); err != nil {
+	md, err := toml.DecodeFile(path, &project)
+	if err != nil {
 		return nil, err
 	}
+	if project.sidecarFile != "" {
+		project.Root = project.Path // As expanded
+	}
+	for key := range inherited {
+		if !isDefinedKey(md, key) {
+			if project.inherited == nil {
+				project.inherited = make(map[string]Inherited)
+			}
+			project.inherited[key] = inherited[key]
+		}
+	}
 
 	// Auto-migrate legacy schema to new format
 	project.migrateSchema()
+	for _, section := range []string{"ownership", "client"} {
+		if md.IsDefined(section) {
+			project.legacyDefined = append(project.legacyDefined, section)
+			project.migrated = true // Saving drops the section, even if it was empty
+		}
+	}
 
 	return &project, nil
 }
 
+// FileHeader is written at the top of every .project.toml pk generates
+const FileHeader = "# Project Metadata\n\n"
+
+// Save writes the project to its metadata file (see File). The output is
+// canonical: sections follow schema order and legacy [ownership]/[client]
+// sections (and legacy links) are dropped once migrated.
+func (p *Project) Save() error {
+	if p.Sidecar() {
+		return p.saveSidecar()
+	}
+	return p.SaveAs(p.File())
+}
+
+// SaveAs is Save to an explicit path
+func (p *Project) SaveAs(path string) error {
+	data, err := p.Encode()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Encode renders the project as canonical .project.toml content
+func (p *Project) Encode() ([]byte, error) {
+	// Projects decoded directly (not via LoadProject) haven't been migrated yet
+	if !p.migrated {
+		p.migrateSchema()
+	}
+
+	clean := *p
+	clean.stripInherited()
+	if clean.Root != "" {
+		clean.Root = p.Path // Follows the project if it moved
+	}
+	if clean.DataKai.Visibility == "" {
+		// Visibility alone doesn't trigger migration, but must not be lost
+		clean.DataKai.Visibility = p.LegacyOwnership.Visibility
+	}
+
+	var zero Project
+	clean.LegacyOwnership = zero.LegacyOwnership
+	clean.LegacyClient = zero.LegacyClient
+	clean.Links.ScriptoriumProject = ""
+	clean.Links.ConduitGraph = ""
+
+	var buf bytes.Buffer
+	buf.WriteString(FileHeader)
+	if err := toml.NewEncoder(&buf).Encode(&clean); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Migrated reports whether the project was loaded from the legacy schema,
+// i.e. saving it would rewrite legacy sections
+func (p *Project) Migrated() bool {
+	return p.migrated
+}
+
+// TmuxAttach reports whether opening the session attaches to it
+// ([tmux] attach, default true)
+func (p *Project) TmuxAttach() bool {
+	return p.Tmux.Attach == nil || *p.Tmux.Attach
+}
+
+// ContextAutoSwitch reports whether opening the project switches cloud and
+// git context ([context] auto_switch, default true)
+func (p *Project) ContextAutoSwitch() bool {
+	return p.Context.AutoSwitch == nil || *p.Context.AutoSwitch
+}
+
 // GetOwner returns the project owner (backward compatibility)
 func (p *Project) GetOwner() string {
 	if p.Consultant.Ownership != "" {
@@ -252,39 +402,29 @@ func (p *Project) migrateSchema() {
 	}
 }
 
-// FindProjects recursively finds all .project.toml files
-func FindProjects(rootDirs ...string) ([]*Project, error) {
-	var projects []*Project
+// FindProjectFile walks up from dir looking for a .project.toml, or a
+// sidecar metadata file for the directory. Returns "" if dir is not inside
+// a project.
+func FindProjectFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
 
-	for _, root := range rootDirs {
-		// Check if directory exists
-		if _, err := os.Stat(root); os.IsNotExist(err) {
-			continue
+	sidecars := SidecarFiles()
+	for {
+		if sidecar, ok := sidecars[dir]; ok {
+			return sidecar
+		}
+		candidate := filepath.Join(dir, ".project.toml")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
 		}
 
-		// Walk directory tree
-		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-
-			// Found a .project.toml file
-			if info.Name() == ".project.toml" {
-				project, err := LoadProject(path)
-				if err != nil {
-					// Skip malformed files
-					return nil
-				}
-				projects = append(projects, project)
-			}
-
-			return nil
-		})
-
-		if err != nil {
-			return nil, err
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
 		}
+		dir = parent
 	}
-
-	return projects, nil
 }
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -48,8 +50,8 @@ started = "2025-01-15"
 		t.Errorf("Expected status 'active', got '%s'", project.ProjectInfo.Status)
 	}
 
-	if project.Ownership.Primary != "test-owner" {
-		t.Errorf("Expected owner 'test-owner', got '%s'", project.Ownership.Primary)
+	if project.GetOwner() != "test-owner" {
+		t.Errorf("Expected owner 'test-owner', got '%s'", project.GetOwner())
 	}
 
 	if project.Path != tmpDir {
@@ -145,3 +147,161 @@ func TestFindProjectsNonexistent(t *testing.T) {
 		t.Errorf("Expected 0 projects from nonexistent dir, got %d", len(projects))
 	}
 }
+
+func TestFindProjectFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	projectDir := filepath.Join(tmpDir, "acme")
+	nested := filepath.Join(projectDir, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+	projectToml := filepath.Join(projectDir, ".project.toml")
+	if err := os.WriteFile(projectToml, []byte("[project]\nid = \"acme\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .project.toml: %v", err)
+	}
+
+	if got := FindProjectFile(nested); got != projectToml {
+		t.Errorf("FindProjectFile(nested) = %q, want %q", got, projectToml)
+	}
+	if got := FindProjectFile(projectDir); got != projectToml {
+		t.Errorf("FindProjectFile(root) = %q, want %q", got, projectToml)
+	}
+	if got := FindProjectFile(tmpDir); got != "" {
+		t.Errorf("FindProjectFile(outside) = %q, want empty", got)
+	}
+}
+
+func TestSaveStripsLegacy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".project.toml")
+	legacy := `[project]
+name = "Legacy"
+id = "legacy"
+status = "active"
+type = "client-project"
+
+[ownership]
+primary = "client"
+license_model = "client-owned"
+visibility = "client-confidential"
+
+[client]
+end_client = "Acme"
+intermediary = "West Monroe"
+my_role = "lead"
+
+[links]
+scriptorium_project = "legacy-notes"
+`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	project, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject failed: %v", err)
+	}
+	if !project.Migrated() {
+		t.Fatal("Expected legacy project to be migrated")
+	}
+	if err := project.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, gone := range []string{"[ownership]", "[client]", "primary", "end_client"} {
+		if strings.Contains(content, gone) {
+			t.Errorf("Saved file still contains %q:\n%s", gone, content)
+		}
+	}
+	if !strings.HasPrefix(content, FileHeader+"[project]") {
+		t.Errorf("Expected header followed by [project], got:\n%s", content)
+	}
+	if strings.Index(content, "[project]") > strings.Index(content, "[consultant]") {
+		t.Error("Expected sections in schema order")
+	}
+
+	// Everything migrated survives a reload, and the file is now current schema
+	reloaded, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reloaded.Migrated() || reloaded.Links.ScriptoriumProject != "" {
+		t.Error("Saved file should not need migration")
+	}
+	if reloaded.Consultant.ClientName != "Acme" || reloaded.Consultant.Partner != "West Monroe" ||
+		reloaded.DataKai.Visibility != "client-confidential" || reloaded.DataKai.ScriptoriumProject != "legacy-notes" {
+		t.Errorf("Migrated values lost: consultant=%+v datakai=%+v", reloaded.Consultant, reloaded.DataKai)
+	}
+	if diags := Validate(data); len(diags) != 0 {
+		t.Errorf("Saved file should validate cleanly, got %v", diags)
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+func TestSaveGolden(t *testing.T) {
+	fresh := &Project{}
+	fresh.ProjectInfo.Name = "Fresh"
+	fresh.ProjectInfo.ID = "fresh"
+	fresh.ProjectInfo.Status = "active"
+	fresh.ProjectInfo.Type = "product"
+	fresh.Consultant.Ownership = "datakai"
+	fresh.Tech.Stack = []string{}
+	fresh.Tech.Domain = []string{}
+	fresh.Dates.Started = "2025-03-01"
+	fresh.MarkDetected("tech.stack", ConfidenceHigh, "go.mod")
+
+	tests := []struct {
+		name    string
+		project func(t *testing.T) *Project
+	}{
+		{"fresh", func(t *testing.T) *Project { return fresh }},
+		{"legacy", func(t *testing.T) *Project {
+			data, err := os.ReadFile(filepath.Join("testdata", "legacy.toml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), ".project.toml")
+			os.WriteFile(path, data, 0644)
+			p, err := LoadProject(path)
+			if err != nil {
+				t.Fatalf("LoadProject failed: %v", err)
+			}
+			return p
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.project(t).Encode()
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", tt.name+".golden"), string(data))
+		})
+	}
+}
+
+// checkGolden compares got with a golden file, rewriting it under -update
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run 'go test ./pkg/config -update' to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch (run with -update if the change is intended)\n--- got ---\n%s\n--- want ---\n%s",
+			path, got, want)
+	}
+}
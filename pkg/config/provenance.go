@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Confidence levels for auto-detected metadata
+const (
+	ConfidenceHigh   = "high"   // Read from a manifest or git, e.g. go.mod, remote origin
+	ConfidenceMedium = "medium" // Heuristic match, e.g. a dependency name or file pattern
+	ConfidenceLow    = "low"    // Inferred from other detected values
+)
+
+// Detection records that a field was filled in automatically. Fields without
+// a detection entry are user-set.
+type Detection struct {
+	Value      string `toml:"value"`      // Detected value, so later edits can be noticed
+	Confidence string `toml:"confidence"` // high | medium | low
+	Source     string `toml:"source"`     // What it was detected from
+}
+
+// AuditEntry is a detected field as it stands now
+type AuditEntry struct {
+	Key     string // Dotted key, e.g. "tech.stack"
+	Current string
+	Detection
+	Edited bool // Value changed since detection, so it's effectively user-set
+}
+
+// MarkDetected records provenance for a field that was just auto-filled
+func (p *Project) MarkDetected(key, confidence, source string) {
+	if p.Detected == nil {
+		p.Detected = make(map[string]Detection)
+	}
+	p.Detected[key] = Detection{Value: FieldValue(p, key), Confidence: confidence, Source: source}
+}
+
+// Audit lists detected fields, least confident first
+func (p *Project) Audit() []AuditEntry {
+	var entries []AuditEntry
+	for key, d := range p.Detected {
+		current := FieldValue(p, key)
+		entries = append(entries, AuditEntry{Key: key, Current: current, Detection: d, Edited: current != d.Value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		ri, rj := confidenceRank(entries[i].Confidence), confidenceRank(entries[j].Confidence)
+		if ri != rj {
+			return ri < rj
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Confirm marks detected fields as user-set. With no keys, confirms all of
+// them. Returns the keys that were confirmed.
+func (p *Project) Confirm(keys ...string) []string {
+	if len(keys) == 0 {
+		keys = sortedKeys(p.Detected)
+	}
+
+	var confirmed []string
+	for _, key := range keys {
+		if _, exists := p.Detected[key]; exists {
+			delete(p.Detected, key)
+			confirmed = append(confirmed, key)
+		}
+	}
+	if len(p.Detected) == 0 {
+		p.Detected = nil
+	}
+	return confirmed
+}
+
+// FieldValue returns the value at a dotted TOML path of p as a string,
+// joining lists with ", "
+func FieldValue(p *Project, key string) string {
+	v, ok := fieldByKey(p, key)
+	if !ok {
+		return ""
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if v.Index(i).Kind() == reflect.String {
+				items = append(items, v.Index(i).String())
+			}
+		}
+		return strings.Join(items, ", ")
+	}
+	return ""
+}
+
+func confidenceRank(confidence string) int {
+	switch confidence {
+	case ConfidenceLow:
+		return 0
+	case ConfidenceMedium:
+		return 1
+	case ConfidenceHigh:
+		return 2
+	}
+	return -1 // Unknown levels are the least trustworthy
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestAuditAndConfirm(t *testing.T) {
+	var p Project
+	p.Tech.Stack = []string{"go", "docker"}
+	p.MarkDetected("tech.stack", ConfidenceMedium, "go.mod, Dockerfile")
+	p.Tech.Domain = []string{"api"}
+	p.MarkDetected("tech.domain", ConfidenceLow, "inferred from stack")
+	p.Links.Repository = "git@example.com:x.git"
+	p.MarkDetected("links.repository", ConfidenceHigh, "git remote origin")
+
+	// The user fixes the domain by hand
+	p.Tech.Domain = []string{"cli"}
+
+	entries := p.Audit()
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"tech.domain", "tech.stack", "links.repository"}) {
+		t.Errorf("Expected least confident first, got %v", keys)
+	}
+	if !entries[0].Edited || entries[0].Current != "cli" || entries[1].Edited {
+		t.Errorf("Edited detection wrong: %+v", entries[:2])
+	}
+
+	if confirmed := p.Confirm("tech.domain", "nope"); !reflect.DeepEqual(confirmed, []string{"tech.domain"}) {
+		t.Errorf("Confirm returned %v", confirmed)
+	}
+	if confirmed := p.Confirm(); len(confirmed) != 2 || p.Detected != nil {
+		t.Errorf("Confirm all left %v (confirmed %v)", p.Detected, confirmed)
+	}
+}
+
+func TestDetectedRoundTrip(t *testing.T) {
+	data := `[project]
+name = "x"
+
+[detected]
+"tech.stack" = {value = "go", confidence = "high", source = "go.mod"}
+`
+	var p Project
+	if _, err := toml.Decode(data, &p); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if p.Detected["tech.stack"].Source != "go.mod" {
+		t.Errorf("Detected not decoded: %v", p.Detected)
+	}
+	if diags := Validate([]byte(data + "\n[tech]\nstack = [\"go\"]\n")); len(diags) != 3 {
+		// Only the missing required fields, not [detected]
+		t.Errorf("Unexpected diagnostics: %v", diags)
+	}
+}
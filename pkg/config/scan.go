@@ -0,0 +1,323 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ScanOptions tune project discovery. Set from [scan] in config.toml.
+type ScanOptions struct {
+	// Parallelism bounds how many directories are read, and how many
+	// .project.toml files parsed, at once. 0 uses DefaultScanParallelism.
+	Parallelism int
+
+	// Ignore lists extra globs for directories never descended into, on
+	// top of DefaultIgnore. A glob with a slash matches the path relative
+	// to the root, otherwise the directory name.
+	Ignore []string
+
+	// Nested keeps descending into every project directory. By default a
+	// directory with a .project.toml is a leaf unless it sets
+	// [project] nested = true.
+	Nested bool
+
+	// MaxDepth is how many directory levels below a root are searched:
+	// 1 finds ~/projects/x, 3 finds ~/projects/clients/acme/x. 0 is
+	// unlimited.
+	MaxDepth int
+}
+
+// Scan holds the discovery options for this process
+var Scan ScanOptions
+
+// DefaultIgnore are directories that never hold projects worth finding:
+// VCS internals, dependency trees, and build output
+var DefaultIgnore = []string{
+	".git", ".hg", ".svn",
+	"node_modules", "vendor", "bower_components",
+	".venv", "venv", "__pycache__", ".tox", ".mypy_cache",
+	"target", "dist", "build", ".next", ".terraform",
+}
+
+// DefaultScanParallelism favours I/O over CPU: on network filesystems
+// most of a scan is waiting on round trips, not parsing
+func DefaultScanParallelism() int {
+	return max(8, 2*runtime.NumCPU())
+}
+
+func scanWorkers() int {
+	if Scan.Parallelism > 0 {
+		return Scan.Parallelism
+	}
+	return DefaultScanParallelism()
+}
+
+// Ignored reports whether discovery skips a directory. rel is its path
+// relative to the root being scanned.
+func Ignored(rel string) bool {
+	name := filepath.Base(rel)
+	for _, patterns := range [][]string{DefaultIgnore, Scan.Ignore} {
+		for _, pattern := range patterns {
+			target := name
+			if strings.Contains(pattern, "/") {
+				target = filepath.ToSlash(rel)
+			}
+			if ok, _ := filepath.Match(strings.Trim(pattern, "/"), target); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// holdsNestedProjects reports whether discovery should look for projects
+// below the project whose .project.toml is at path
+func holdsNestedProjects(path string) bool {
+	if Scan.Nested {
+		return true
+	}
+	var probe struct {
+		Project struct {
+			Nested bool `toml:"nested"`
+		} `toml:"project"`
+	}
+	// A file that doesn't parse isn't a project anyway; don't descend
+	if _, err := toml.DecodeFile(path, &probe); err != nil {
+		return false
+	}
+	return probe.Project.Nested
+}
+
+// FindProjects recursively finds all .project.toml files
+func FindProjects(rootDirs ...string) ([]*Project, error) {
+	paths, err := FindProjectFiles(rootDirs...)
+	if err != nil {
+		return nil, err
+	}
+	return loadProjects(paths), nil
+}
+
+// loadProjects parses project files in parallel, keeping their order and
+// skipping malformed files
+func loadProjects(paths []string) []*Project {
+	loaded := make([]*Project, len(paths))
+	sem := make(chan struct{}, scanWorkers())
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if project, err := LoadProject(path); err == nil {
+				loaded[i] = project
+			}
+		}()
+	}
+	wg.Wait()
+
+	var projects []*Project
+	for _, p := range loaded {
+		if p != nil {
+			projects = append(projects, p)
+		}
+	}
+	return projects
+}
+
+// RootState fingerprints one root as a scan saw it: the modification time
+// of each directory it searched and each project or defaults file it read.
+// While none of them changes, rescanning the root finds the same projects.
+type RootState struct {
+	Options string               `json:"options"` // Scan options in effect
+	Paths   map[string]time.Time `json:"paths"`   // Zero time: didn't exist
+}
+
+// Changed reports whether anything the scan saw is different now, or the
+// scan options are. It costs one stat per recorded path and reads nothing.
+func (s RootState) Changed() bool {
+	if s.Options != scanOptionsKey() {
+		return true
+	}
+	for path, modTime := range s.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if !modTime.IsZero() {
+				return true
+			}
+			continue
+		}
+		if !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanOptionsKey identifies the options that change what a scan finds
+func scanOptionsKey() string {
+	return fmt.Sprintf("ignore=%q nested=%v depth=%d", Scan.Ignore, Scan.Nested, Scan.MaxDepth)
+}
+
+// ScanRoot finds the projects under root and fingerprints what it saw, so
+// a cache can tell later whether the root needs scanning again
+func ScanRoot(root string) ([]*Project, RootState, error) {
+	state := RootState{Options: scanOptionsKey(), Paths: make(map[string]time.Time)}
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		state.Paths[root] = time.Time{}
+		return nil, state, nil
+	}
+
+	paths, err := walkProjectFiles(root, scanWorkers(), state.Paths)
+	if err != nil {
+		return nil, state, err
+	}
+	paths = withSidecars(root, paths, state.Paths)
+	return loadProjects(paths), state, nil
+}
+
+// FindProjectFiles returns the path of every .project.toml under rootDirs,
+// and every sidecar metadata file for a directory under them, including
+// ones that fail to parse. Directories are read concurrently; the
+// result is in the order a depth-first walk would produce, root by root.
+// Ignored directories are skipped, and project directories are not searched
+// further unless they hold nested projects (see ScanOptions), nor is
+// anything below Scan.MaxDepth.
+func FindProjectFiles(rootDirs ...string) ([]string, error) {
+	var paths []string
+
+	for _, root := range rootDirs {
+		// Check if directory exists
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		found, err := walkProjectFiles(root, scanWorkers(), nil)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, withSidecars(root, found, nil)...)
+	}
+
+	return paths, nil
+}
+
+// walkProjectFiles reads every directory under root with at most workers
+// reads in flight. Symlinks below root are not followed. If seen is not
+// nil, it records the modification time of each directory searched and each
+// project and defaults file found, taken before reading them.
+func walkProjectFiles(root string, workers int, seen map[string]time.Time) ([]string, error) {
+	var (
+		mu       sync.Mutex
+		found    []string
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, workers)
+
+	record := func(path string, info os.FileInfo, err error) {
+		if seen != nil && err == nil {
+			mu.Lock()
+			seen[path] = info.ModTime()
+			mu.Unlock()
+		}
+	}
+
+	var visit func(dir string, depth int)
+	visit = func(dir string, depth int) {
+		defer wg.Done()
+
+		sem <- struct{}{}
+		if seen != nil {
+			info, err := os.Stat(dir)
+			record(dir, info, err)
+		}
+		entries, err := os.ReadDir(dir)
+		<-sem
+
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			mu.Unlock()
+			return
+		}
+
+		// A project is a leaf unless it says otherwise
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if seen != nil && (entry.Name() == ".project.toml" || entry.Name() == DefaultsFile) {
+				info, err := os.Stat(path)
+				record(path, info, err)
+			}
+			if entry.Name() != ".project.toml" {
+				continue
+			}
+			mu.Lock()
+			found = append(found, path)
+			mu.Unlock()
+			if dir == root {
+				continue
+			}
+			sem <- struct{}{}
+			nested := holdsNestedProjects(path)
+			<-sem
+			if !nested {
+				// Files coming and going in a project don't change what
+				// a scan finds; its .project.toml is what counts
+				if seen != nil {
+					mu.Lock()
+					delete(seen, dir)
+					mu.Unlock()
+				}
+				return
+			}
+		}
+
+		if Scan.MaxDepth > 0 && depth >= Scan.MaxDepth {
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if rel, err := filepath.Rel(root, path); err == nil && Ignored(rel) {
+				continue
+			}
+			wg.Add(1)
+			go visit(path, depth+1)
+		}
+	}
+
+	wg.Add(1)
+	visit(root, 0)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	slices.SortFunc(found, compareWalkOrder)
+	return found, nil
+}
+
+// compareWalkOrder orders paths component by component, as a depth-first
+// walk of sorted directory entries visits them ("a/x" before "a-b/x")
+func compareWalkOrder(a, b string) int {
+	return slices.Compare(
+		strings.Split(a, string(filepath.Separator)),
+		strings.Split(b, string(filepath.Separator)),
+	)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// walkOrder is what the serial filepath.Walk scan used to return
+func walkOrder(t *testing.T, root string) []string {
+	t.Helper()
+	var paths []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Name() == ".project.toml" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths
+}
+
+func TestFindProjectFilesMatchesWalkOrder(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"a", "a/b", "a/b/c", "a-b", "a.z", "b", "clients/acme/api", "clients/acme/web", "Z",
+	} {
+		path := filepath.Join(root, dir)
+		os.MkdirAll(path, 0755)
+		os.WriteFile(filepath.Join(path, ".project.toml"), []byte("[project]\nid = \"x\"\n"), 0644)
+	}
+	os.MkdirAll(filepath.Join(root, "empty", "deep", "er"), 0755)
+
+	// Search every project so the result is comparable to a full walk
+	Scan.Nested = true
+	defer func() { Scan = ScanOptions{} }()
+
+	for _, workers := range []int{1, 3, 64} {
+		Scan.Parallelism = workers
+		got, err := FindProjectFiles(root)
+		if err != nil {
+			t.Fatalf("FindProjectFiles: %v", err)
+		}
+		if want := walkOrder(t, root); !reflect.DeepEqual(got, want) {
+			t.Errorf("parallelism %d:\n got %q\nwant %q", workers, got, want)
+		}
+	}
+}
+
+func TestFindProjectFilesPrunesAndIgnores(t *testing.T) {
+	root := t.TempDir()
+	write := func(dir, content string) {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, ".project.toml"), []byte(content), 0644)
+	}
+	write("app", "[project]\nid = \"app\"\n")
+	write("app/examples/demo", "[project]\nid = \"demo\"\n")   // pruned: app is a leaf
+	write("web/node_modules/pkg", "[project]\nid = \"pkg\"\n") // default ignore
+	write("mono", "[project]\nid = \"mono\"\nnested = true\n") // opted in
+	write("mono/apps/api", "[project]\nid = \"api\"\n")
+	write("mono/apps/api/.git/x", "[project]\nid = \"x\"\n") // default ignore
+	write("clients/acme/tmp", "[project]\nid = \"tmp\"\n")   // ignored by path
+	write("clients/globex", "[project]\nid = \"globex\"\n")
+
+	Scan.Ignore = []string{"clients/*/tmp"}
+	defer func() { Scan = ScanOptions{} }()
+
+	got, err := FindProjectFiles(root)
+	if err != nil {
+		t.Fatalf("FindProjectFiles: %v", err)
+	}
+	var rels []string
+	for _, path := range got {
+		rel, _ := filepath.Rel(root, filepath.Dir(path))
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	want := []string{"app", "clients/globex", "mono", "mono/apps/api"}
+	if !reflect.DeepEqual(rels, want) {
+		t.Errorf("found %q, want %q", rels, want)
+	}
+
+	// [scan] nested = true searches inside every project
+	Scan.Nested = true
+	got, _ = FindProjectFiles(root)
+	if len(got) != 5 {
+		t.Errorf("with Nested, found %d projects, want 5 (app/examples/demo included)", len(got))
+	}
+}
+
+func TestFindProjectsSkipsMalformed(t *testing.T) {
+	root := t.TempDir()
+	for i, content := range []string{
+		"[project]\nid = \"one\"\n",
+		"not = [valid toml",
+		"[project]\nid = \"three\"\n",
+	} {
+		dir := filepath.Join(root, string(rune('a'+i)))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, ".project.toml"), []byte(content), 0644)
+	}
+
+	projects, err := FindProjects(root, filepath.Join(root, "missing"))
+	if err != nil {
+		t.Fatalf("FindProjects: %v", err)
+	}
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.ProjectInfo.ID)
+	}
+	if want := []string{"one", "three"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+}
+
+func TestScanRootState(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		os.MkdirAll(filepath.Dir(filepath.Join(root, rel)), 0755)
+		os.WriteFile(filepath.Join(root, rel), []byte(content), 0644)
+	}
+	touch := func(rel string) {
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(filepath.Join(root, rel), later, later)
+	}
+	write("app/.project.toml", "[project]\nid = \"app\"\n")
+	write("clients/acme/.project-defaults.toml", "[consultant]\nclient_name = \"Acme\"\n")
+	write("clients/acme/etl/.project.toml", "[project]\nid = \"etl\"\n")
+
+	projects, state, err := ScanRoot(root)
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("ScanRoot = %d projects, %v", len(projects), err)
+	}
+	if state.Changed() {
+		t.Fatal("state changed with nothing touched")
+	}
+
+	// Work inside a project doesn't count
+	write("app/main.go", "package main\n")
+	if state.Changed() {
+		t.Error("a new file inside a project changed the root")
+	}
+
+	for _, rel := range []string{"app/.project.toml", "clients/acme/.project-defaults.toml", "clients"} {
+		_, state, _ := ScanRoot(root)
+		touch(rel)
+		if !state.Changed() {
+			t.Errorf("touching %s didn't change the root", rel)
+		}
+	}
+
+	_, state, _ = ScanRoot(root)
+	Scan.Ignore = []string{"clients"}
+	defer func() { Scan = ScanOptions{} }()
+	if !state.Changed() {
+		t.Error("new scan options didn't change the root")
+	}
+
+	// A missing root changes when it appears
+	missing := filepath.Join(root, "later")
+	_, state, _ = ScanRoot(missing)
+	if state.Changed() {
+		t.Error("missing root changed while still missing")
+	}
+	os.MkdirAll(missing, 0755)
+	if !state.Changed() {
+		t.Error("missing root didn't change when created")
+	}
+}
+
+func TestFindProjectFilesMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "clients/acme/etl", "deep/er/still/x"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, ".project.toml"), []byte("[project]\nid = \"x\"\n"), 0644)
+	}
+	defer func() { Scan = ScanOptions{} }()
+
+	for depth, want := range map[int]int{0: 3, 1: 1, 3: 2, 4: 3} {
+		Scan.MaxDepth = depth
+		got, err := FindProjectFiles(root)
+		if err != nil {
+			t.Fatalf("FindProjectFiles: %v", err)
+		}
+		if len(got) != want {
+			t.Errorf("max depth %d: found %d projects, want %d", depth, len(got), want)
+		}
+	}
+}
+
+func TestFindUntracked(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write("tracked/.project.toml", "[project]\nid = \"tracked\"\n")
+	write("tracked/sub/go.mod", "module sub") // Inside a project
+	write("monorepo/.project.toml", "[project]\nnested = true\n")
+	write("monorepo/svc/package.json", "{}") // Nested holders are searched
+	write("loose/go.mod", "module loose")
+	write("loose/inner/Cargo.toml", "") // Below an untracked repo
+	os.MkdirAll(filepath.Join(root, "clients", "acme", "etl", ".git"), 0755)
+	write("notes/todo.md", "")                       // No markers
+	write("app/node_modules/dep/package.json", "{}") // Ignored
+
+	got, err := FindUntracked(root)
+	if err != nil {
+		t.Fatalf("FindUntracked: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "clients", "acme", "etl"),
+		filepath.Join(root, "loose"),
+		filepath.Join(root, "monorepo", "svc"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+
+	Scan.MaxDepth = 2
+	defer func() { Scan = ScanOptions{} }()
+	got, _ = FindUntracked(root)
+	if len(got) != 2 {
+		t.Errorf("MaxDepth 2: got %q, expected clients/acme/etl to be out of reach", got)
+	}
+}
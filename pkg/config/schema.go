@@ -0,0 +1,223 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SchemaID is the $id of the generated JSON Schema
+const SchemaID = "https://github.com/datakaicr/pk/schema/project.schema.json"
+
+// Schema rules shared by Validate and JSONSchema, keyed by dotted TOML path
+var (
+	// RequiredKeys must be set in every .project.toml
+	RequiredKeys = []string{"project.name", "project.id", "project.status", "project.type"}
+
+	// SectionRequiredKeys become required once their section is present
+	SectionRequiredKeys = map[string]string{
+		"consultant": "ownership",
+		"datakai":    "visibility",
+	}
+
+	// EnumKeys restrict a field to a fixed set of values
+	EnumKeys = map[string][]string{
+		"project.status":       StatusValues,
+		"consultant.ownership": OwnershipValues,
+		"datakai.visibility":   VisibilityValues,
+		"ownership.visibility": VisibilityValues,
+
+		// Inside a list of tables, so Validate checks it separately
+		"tmux.windows.panes.split": SplitValues,
+	}
+
+	// DateKeys hold YYYY-MM-DD dates (or are empty)
+	DateKeys = []string{"dates.started", "dates.completed"}
+
+	// legacySections are still read (and migrated) but shouldn't be written
+	legacySections = map[string]bool{"ownership": true, "client": true}
+	legacyKeys     = map[string]bool{"links.scriptorium_project": true, "links.conduit_graph": true}
+)
+
+// schemaDescriptions documents sections and fields in the generated schema
+var schemaDescriptions = map[string]string{
+	"root":                      "Project directory; only in sidecar metadata files (~/.local/share/pk/meta)",
+	"project":                   "Core project identity",
+	"project.name":              "Human-readable project name",
+	"project.id":                "Machine-friendly identifier (lowercase, hyphens)",
+	"project.type":              "Project type, e.g. product, client-project, internal, tool, library",
+	"project.kind":              "Project kind bundling layout, env, and commands, e.g. dbt, terraform-module, go-cli",
+	"project.nested":            "Holds other projects (a monorepo); discovery keeps searching inside it",
+	"project.mirror":            "Read-only reference checkout (vendor SDK, client reference code): no dirty reports or time tracking, its own alias prefix, fetched by the daemon",
+	"tech":                      "Technology and domain tags",
+	"tech.stack":                "Technology stack, e.g. [\"python\", \"fastapi\"]",
+	"tech.domain":               "Domain categories, e.g. [\"web\", \"api\"]",
+	"dates.started":             "Start date (YYYY-MM-DD)",
+	"dates.completed":           "Completion date (YYYY-MM-DD), empty if ongoing",
+	"notes.description":         "Brief project description",
+	"tmux":                      "Custom tmux session layout",
+	"tmux.layout":               "tmux layout name, e.g. main-vertical",
+	"tmux.windows":              "Windows to create when the session opens",
+	"tmux.windows.panes":        "Panes split off the window in order, each from the pane before it",
+	"tmux.windows.panes.split":  "vertical stacks the new pane below, horizontal puts it to the right",
+	"tmux.windows.panes.size":   "Percent of the split pane the new pane takes (default half)",
+	"tmux.attach":               "Attach when the session opens (false: create it in the background)",
+	"tmux.env":                  "Environment variables exported into the session, overriding [context]",
+	"tmux.on_create":            "Shell command run in the project directory after the session is created",
+	"tmux.on_attach":            "Shell command run in the project directory each time pk opens the session",
+	"tmux.on_detach":            "Shell command run in the project directory when a client detaches",
+	"detected":                  "Provenance of auto-detected fields, keyed by dotted path; cleared by 'pk confirm'",
+	"context":                   "Cloud and git context applied when the project opens",
+	"context.kube_context":      "kubectl context switched to when the project opens",
+	"context.auto_switch":       "Switch context when the project opens (false: leave CLI state alone)",
+	"editor.vscode_profile":     "VS Code profile, overriding the client's profile from global config",
+	"dev.roadmap":               "Path to roadmap file, e.g. .dev/ROADMAP.md",
+	"relations":                 "Links to other projects, drawn by 'pk export graph'",
+	"relations.depends_on":      "IDs of projects this one builds on, e.g. [\"dk\", \"acme-sdk\"]",
+	"consultant":                "Consultant extension: client and delivery metadata",
+	"consultant.ownership":      "Who owns the intellectual property",
+	"consultant.client_type":    "direct | partner | internal",
+	"consultant.my_role":        "Your role, e.g. lead | contributor | advisor",
+	"consultant.license_model":  "proprietary | client-owned | open-source",
+	"consultant.rate_type":      "fixed | hourly | retainer",
+	"datakai":                   "DataKai extension: DKOS ecosystem metadata",
+	"datakai.visibility":        "Protocol variant used by dkproto",
+	"datakai.product_category":  "infrastructure | client-deliverable | internal-tool",
+	"datakai.revenue_model":     "saas | consulting | open-source | internal",
+	"datakai.maturity":          "experimental | mvp | production | deprecated",
+	"ownership":                 "Legacy: migrated to [consultant] and [datakai]",
+	"client":                    "Legacy: migrated to [consultant]",
+	"links.scriptorium_project": "Legacy: moved to datakai.scriptorium_project",
+	"links.conduit_graph":       "Legacy: moved to datakai.conduit_graph",
+}
+
+// JSONSchema describes .project.toml as a JSON Schema, generated from the
+// Project struct so it can't drift from what pk actually reads
+func JSONSchema() map[string]interface{} {
+	schema := objectSchema(reflect.TypeOf(Project{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = ".project.toml"
+	schema["required"] = []string{"project"}
+	return schema
+}
+
+// objectSchema builds the schema for a struct, prefix being its dotted path
+func objectSchema(t reflect.Type, prefix string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := tomlName(field)
+		if name == "" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		prop := typeSchema(field.Type, key)
+		if desc, ok := schemaDescriptions[key]; ok {
+			prop["description"] = desc
+		}
+		if values, ok := EnumKeys[key]; ok {
+			prop["enum"] = values
+		}
+		if slices.Contains(DateKeys, key) {
+			prop["pattern"] = `^(\d{4}-\d{2}-\d{2})?$`
+		}
+		if legacySections[key] || legacyKeys[key] {
+			prop["deprecated"] = true
+		}
+		if sectionKey, ok := SectionRequiredKeys[key]; ok {
+			prop["required"] = []string{sectionKey}
+		}
+		if prefix != "" && slices.Contains(RequiredKeys, key) {
+			required = append(required, name)
+		}
+
+		properties[name] = prop
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// typeSchema maps a Go field type to its JSON Schema
+func typeSchema(t reflect.Type, key string) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), key)}
+	case reflect.Struct:
+		return objectSchema(t, key)
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), key)
+	}
+	return map[string]interface{}{}
+}
+
+// tomlName returns the TOML key for a struct field, or "" if it isn't serialized
+func tomlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// stringField returns the string value at a dotted TOML path of p
+func stringField(p *Project, key string) string {
+	v, ok := fieldByKey(p, key)
+	if !ok || v.Kind() != reflect.String {
+		return ""
+	}
+	return v.String()
+}
+
+// fieldByKey walks p's struct fields along a dotted TOML path
+func fieldByKey(p *Project, key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(p).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if tomlName(v.Type().Field(i)) == part {
+				v = v.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
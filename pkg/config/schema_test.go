@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	// Round-trip through JSON so assertions see what 'pk schema' prints
+	data, err := json.Marshal(JSONSchema())
+	if err != nil {
+		t.Fatalf("Failed to marshal schema: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+
+	props := schema["properties"].(map[string]interface{})
+	for _, section := range []string{"project", "tech", "dates", "tmux", "context", "editor", "consultant", "datakai"} {
+		if _, ok := props[section]; !ok {
+			t.Errorf("Expected section %q in schema", section)
+		}
+	}
+	if _, ok := props["Path"]; ok {
+		t.Error("Internal Path field should not be in the schema")
+	}
+
+	project := props["project"].(map[string]interface{})
+	if project["additionalProperties"] != false {
+		t.Error("Expected unknown keys to be disallowed")
+	}
+	if required := project["required"].([]interface{}); len(required) != 4 {
+		t.Errorf("Expected 4 required project fields, got %v", required)
+	}
+
+	status := project["properties"].(map[string]interface{})["status"].(map[string]interface{})
+	if enum := status["enum"].([]interface{}); len(enum) != len(StatusValues) {
+		t.Errorf("Expected status enum %v, got %v", StatusValues, enum)
+	}
+
+	consultant := props["consultant"].(map[string]interface{})
+	if required := consultant["required"].([]interface{}); len(required) != 1 || required[0] != "ownership" {
+		t.Errorf("Expected consultant to require ownership, got %v", required)
+	}
+
+	windows := props["tmux"].(map[string]interface{})["properties"].(map[string]interface{})["windows"].(map[string]interface{})
+	items := windows["items"].(map[string]interface{})
+	if _, ok := items["properties"].(map[string]interface{})["command"]; !ok {
+		t.Error("Expected tmux window items to describe command")
+	}
+
+	if props["ownership"].(map[string]interface{})["deprecated"] != true {
+		t.Error("Expected legacy [ownership] to be marked deprecated")
+	}
+}
+
+func TestStringField(t *testing.T) {
+	var p Project
+	p.ProjectInfo.Status = "active"
+	p.DataKai.Visibility = "private"
+
+	if got := stringField(&p, "project.status"); got != "active" {
+		t.Errorf("Expected active, got %q", got)
+	}
+	if got := stringField(&p, "datakai.visibility"); got != "private" {
+		t.Errorf("Expected private, got %q", got)
+	}
+	if got := stringField(&p, "project.status.extra"); got != "" {
+		t.Errorf("Expected empty for path through a string, got %q", got)
+	}
+	if got := stringField(&p, "nope.key"); got != "" {
+		t.Errorf("Expected empty for unknown section, got %q", got)
+	}
+}
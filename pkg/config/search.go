@@ -0,0 +1,156 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// searchFields are the fields 'pk search' looks in, most telling first,
+// with how much a match in each counts
+var searchFields = []struct {
+	key    string
+	weight int
+}{
+	{"project.id", 10},
+	{"project.name", 10},
+	{"consultant.client_name", 6},
+	{"tech.stack", 5},
+	{"tech.domain", 5},
+	{"project.kind", 3},
+	{"project.type", 3},
+	{"consultant.partner", 3},
+	{"notes.description", 2},
+}
+
+// SearchMatch is a field where a search term was found
+type SearchMatch struct {
+	Key   string
+	Value string
+}
+
+// SearchResult is a project matching every term of a search
+type SearchResult struct {
+	Project *Project
+	Score   int
+	Matches []SearchMatch // In searchFields order
+}
+
+// SearchTerms splits a query into lowercased terms
+func SearchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// Search ranks the projects matching every term of query in their name,
+// ID, client, stack, domain, kind, type, partner or description. A term
+// matching a whole value scores above one starting a word, which scores
+// above one inside a word; ties go by name.
+func Search(projects []*Project, query string) []SearchResult {
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []SearchResult
+	for _, p := range projects {
+		if r, ok := searchProject(p, terms); ok {
+			results = append(results, r)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Project.ProjectInfo.Name) < strings.ToLower(results[j].Project.ProjectInfo.Name)
+	})
+	return results
+}
+
+// searchProject scores a project, or reports false if a term is missing
+func searchProject(p *Project, terms []string) (SearchResult, bool) {
+	result := SearchResult{Project: p}
+	matched := make(map[string]bool)
+	for _, term := range terms {
+		best := 0
+		for _, f := range searchFields {
+			for _, value := range filterValues(p, f.key) {
+				quality := matchQuality(strings.ToLower(value), term)
+				if quality == 0 {
+					continue
+				}
+				best = max(best, quality*f.weight)
+				matched[f.key] = true
+			}
+		}
+		if best == 0 {
+			return SearchResult{}, false
+		}
+		result.Score += best
+	}
+
+	for _, f := range searchFields {
+		if matched[f.key] {
+			result.Matches = append(result.Matches, SearchMatch{Key: f.key, Value: FieldValue(p, f.key)})
+		}
+	}
+	return result, true
+}
+
+// matchQuality rates how well term matches a lowercased value: 3 for the
+// whole value, 2 at the start of a word, 1 anywhere, 0 not at all
+func matchQuality(value, term string) int {
+	switch {
+	case value == term:
+		return 3
+	case !strings.Contains(value, term):
+		return 0
+	}
+	for i := strings.Index(value, term); i >= 0; {
+		if i == 0 || !isWordByte(value[i-1]) {
+			return 2
+		}
+		next := strings.Index(value[i+1:], term)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return 1
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+}
+
+// Highlight wraps each case-insensitive occurrence of the terms in value
+// with before and after
+func Highlight(value string, terms []string, before, after string) string {
+	lower := strings.ToLower(value)
+	marked := make([]bool, len(value))
+	for _, term := range terms {
+		if term == "" || len(lower) != len(value) {
+			continue // Case folding changed byte offsets; leave it plain
+		}
+		for i := 0; ; {
+			j := strings.Index(lower[i:], term)
+			if j < 0 {
+				break
+			}
+			for k := i + j; k < i+j+len(term); k++ {
+				marked[k] = true
+			}
+			i += j + len(term)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(before)
+		}
+		b.WriteByte(value[i])
+		if marked[i] && (i == len(value)-1 || !marked[i+1]) {
+			b.WriteString(after)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func searchProjects() []*Project {
+	conduit := &Project{}
+	conduit.ProjectInfo.ID = "conduit"
+	conduit.ProjectInfo.Name = "Conduit"
+	conduit.Tech.Stack = []string{"go", "duckdb"}
+	conduit.Notes.Description = "Graph engine for lineage"
+
+	etl := &Project{}
+	etl.ProjectInfo.ID = "acme-etl"
+	etl.ProjectInfo.Name = "Acme ETL"
+	etl.Tech.Stack = []string{"python", "dbt"}
+	etl.Tech.Domain = []string{"data-engineering"}
+	etl.Consultant.ClientName = "Acme Corp"
+	etl.Notes.Description = "Nightly loads into the conduit graph"
+
+	lineage := &Project{}
+	lineage.ProjectInfo.ID = "lineage-ui"
+	lineage.ProjectInfo.Name = "Lineage UI"
+	lineage.Tech.Stack = []string{"typescript"}
+
+	return []*Project{etl, lineage, conduit}
+}
+
+func TestSearchRanks(t *testing.T) {
+	ids := func(results []SearchResult) string {
+		var s []string
+		for _, r := range results {
+			s = append(s, r.Project.ProjectInfo.ID)
+		}
+		return strings.Join(s, " ")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"conduit", "conduit acme-etl"}, // Name beats description
+		{"lineage", "lineage-ui conduit"},
+		{"acme", "acme-etl"},
+		{"DBT", "acme-etl"},
+		{"graph go", "conduit"}, // Every term must match
+		{"engineering", "acme-etl"},
+		{"rust", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := ids(Search(searchProjects(), tt.query)); got != tt.want {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchMatches(t *testing.T) {
+	results := Search(searchProjects(), "acme loads")
+	if len(results) != 1 {
+		t.Fatalf("got %d results", len(results))
+	}
+	var keys []string
+	for _, m := range results[0].Matches {
+		keys = append(keys, m.Key)
+	}
+	want := "project.id project.name consultant.client_name notes.description"
+	if got := strings.Join(keys, " "); got != want {
+		t.Errorf("matched fields = %q, want %q", got, want)
+	}
+}
+
+func TestMatchQuality(t *testing.T) {
+	tests := []struct {
+		value, term string
+		want        int
+	}{
+		{"dbt", "dbt", 3},
+		{"acme corp", "corp", 2},
+		{"data-engineering", "eng", 2},
+		{"mydbt", "dbt", 1},
+		{"go", "rust", 0},
+	}
+	for _, tt := range tests {
+		if got := matchQuality(tt.value, tt.term); got != tt.want {
+			t.Errorf("matchQuality(%q, %q) = %d, want %d", tt.value, tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		value string
+		terms []string
+		want  string
+	}{
+		{"Acme ETL", []string{"acme"}, "[Acme] ETL"},
+		{"go, duckdb", []string{"d", "duck"}, "go, [duckd]b"},
+		{"Graph engine", []string{"graph", "engine"}, "[Graph] [engine]"},
+		{"plain", []string{"x"}, "plain"},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.value, tt.terms, "[", "]"); got != tt.want {
+			t.Errorf("Highlight(%q, %v) = %q, want %q", tt.value, tt.terms, got, tt.want)
+		}
+	}
+}
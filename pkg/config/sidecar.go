@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Sidecar metadata lives outside the project, for repositories that mustn't
+// hold pk's files: ~/.local/share/pk/meta/<id>.toml is a .project.toml plus
+// a top-level root key naming the project directory. Discovery finds a
+// sidecar project under whichever root holds its directory, and a sidecar
+// takes precedence over a .project.toml in the same directory.
+
+// SidecarDir returns the directory holding sidecar metadata files
+func SidecarDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "pk", "meta"), nil
+}
+
+// SidecarFile returns the sidecar metadata file for a project ID
+func SidecarFile(id string) (string, error) {
+	dir, err := SidecarDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".toml"), nil
+}
+
+// IsSidecar reports whether path is a sidecar metadata file
+func IsSidecar(path string) bool {
+	dir, err := SidecarDir()
+	return err == nil && filepath.Dir(path) == dir && strings.HasSuffix(path, ".toml")
+}
+
+// sidecarRoot reads the project directory a sidecar file names
+func sidecarRoot(path string) (string, error) {
+	var probe struct {
+		Root string `toml:"root"`
+	}
+	if _, err := toml.DecodeFile(path, &probe); err != nil {
+		return "", err
+	}
+	if probe.Root == "" {
+		return "", fmt.Errorf("%s: missing root (the project directory)", path)
+	}
+	root := probe.Root
+	if strings.HasPrefix(root, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			root = filepath.Join(homeDir, root[2:])
+		}
+	}
+	return filepath.Clean(root), nil
+}
+
+// SidecarFiles maps each sidecar project's directory to its metadata file.
+// Files that don't parse or name no root are skipped.
+func SidecarFiles() map[string]string {
+	files := make(map[string]string)
+	dir, err := SidecarDir()
+	if err != nil {
+		return files
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".toml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if root, err := sidecarRoot(path); err == nil {
+			files[root] = path
+		}
+	}
+	return files
+}
+
+// withSidecars adds the sidecar files for projects under root to paths, the
+// .project.toml files a walk of root found, replacing any .project.toml in
+// a sidecar project's own directory. If seen is not nil, the sidecar
+// directory and files are recorded in it as walkProjectFiles records its own.
+func withSidecars(root string, paths []string, seen map[string]time.Time) []string {
+	sidecars := SidecarFiles()
+	if seen != nil {
+		if dir, err := SidecarDir(); err == nil {
+			info, err := os.Stat(dir)
+			if err != nil {
+				seen[dir] = time.Time{}
+			} else {
+				seen[dir] = info.ModTime()
+			}
+		}
+	}
+
+	var merged []string
+	for _, path := range paths {
+		if _, ok := sidecars[filepath.Dir(path)]; !ok {
+			merged = append(merged, path)
+		}
+	}
+	root = filepath.Clean(root)
+	var files []string
+	for dir, file := range sidecars {
+		if dir != root && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			if seen != nil {
+				seen[dir] = time.Time{}
+			}
+			continue
+		}
+		files = append(files, file)
+		if seen != nil {
+			if info, err := os.Stat(file); err == nil {
+				seen[file] = info.ModTime()
+			}
+		}
+	}
+	sort.Strings(files)
+	return append(merged, files...)
+}
+
+// MetadataFile returns the metadata file for a project directory: its
+// sidecar if it has one, otherwise .project.toml in it (which may not exist)
+func MetadataFile(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		if file, ok := SidecarFiles()[abs]; ok {
+			return file
+		}
+	}
+	return filepath.Join(dir, ".project.toml")
+}
+
+// Sidecar reports whether the project's metadata lives in a sidecar file
+func (p *Project) Sidecar() bool {
+	return p.Root != ""
+}
+
+// File returns the path of the project's metadata file: its sidecar, or
+// .project.toml in the project directory
+func (p *Project) File() string {
+	return p.FileIn(p.Path)
+}
+
+// FileIn returns where the project's metadata file is once its directory
+// is dir: a sidecar stays put, a .project.toml moves with the directory
+func (p *Project) FileIn(dir string) string {
+	if !p.Sidecar() {
+		return filepath.Join(dir, ".project.toml")
+	}
+	if p.sidecarFile != "" {
+		return p.sidecarFile
+	}
+	// Projects from the cache don't know which file they came from
+	if file, ok := SidecarFiles()[p.Path]; ok {
+		p.sidecarFile = file
+		return file
+	}
+	file, _ := SidecarFile(p.ProjectInfo.ID)
+	return file
+}
+
+// saveSidecar writes a sidecar project. A changed ID renames the file to
+// match, unless a file by the new name already exists.
+func (p *Project) saveSidecar() error {
+	old := p.File()
+	path := old
+	if file, err := SidecarFile(p.ProjectInfo.ID); err == nil && file != old {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			path = file
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := p.SaveAs(path); err != nil {
+		return err
+	}
+	if path != old {
+		p.sidecarFile = path
+		if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveToSidecar moves the project's metadata out of its directory into a
+// sidecar file, or back into .project.toml when toSidecar is false.
+// Returns the new metadata file.
+func (p *Project) MoveToSidecar(toSidecar bool) (string, error) {
+	if p.Sidecar() == toSidecar {
+		return p.File(), nil
+	}
+	old := p.File()
+
+	if toSidecar {
+		file, err := SidecarFile(p.ProjectInfo.ID)
+		if err != nil {
+			return "", err
+		}
+		if existing, err := sidecarRoot(file); err == nil && existing != p.Path {
+			return "", fmt.Errorf("sidecar %s already belongs to %s", file, existing)
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return "", err
+		}
+		p.Root, p.sidecarFile = p.Path, file
+	} else {
+		if _, err := os.Stat(filepath.Join(p.Path, ".project.toml")); err == nil {
+			return "", fmt.Errorf("%s already has a .project.toml", p.Path)
+		}
+		p.Root, p.sidecarFile = "", ""
+	}
+
+	if err := p.Save(); err != nil {
+		return "", err
+	}
+	if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return p.File(), nil
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sidecarHome points HOME at a temporary directory with a projects root
+func sidecarHome(t *testing.T) (home, root string) {
+	t.Helper()
+	home = t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	t.Cleanup(func() { os.Setenv("HOME", oldHome) })
+
+	root = filepath.Join(home, "projects")
+	os.MkdirAll(root, 0755)
+	return home, root
+}
+
+func TestMoveToSidecarAndBack(t *testing.T) {
+	home, root := sidecarHome(t)
+	dir := filepath.Join(root, "acme-etl")
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, ".project.toml"), []byte("[project]\nname = \"Acme ETL\"\nid = \"acme-etl\"\n"), 0644)
+
+	project, err := LoadProject(filepath.Join(dir, ".project.toml"))
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	file, err := project.MoveToSidecar(true)
+	if err != nil {
+		t.Fatalf("MoveToSidecar: %v", err)
+	}
+
+	want := filepath.Join(home, ".local", "share", "pk", "meta", "acme-etl.toml")
+	if file != want {
+		t.Errorf("sidecar = %s, want %s", file, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".project.toml")); !os.IsNotExist(err) {
+		t.Errorf(".project.toml still in the project")
+	}
+	data, _ := os.ReadFile(file)
+	if !strings.Contains(string(data), "root = \""+dir+"\"") {
+		t.Errorf("sidecar doesn't name the project directory:\n%s", data)
+	}
+
+	// Discovery and lookups find the sidecar
+	files, err := FindProjectFiles(root)
+	if err != nil || !reflect.DeepEqual(files, []string{file}) {
+		t.Errorf("FindProjectFiles = %q, %v; want [%s]", files, err, file)
+	}
+	if got := FindProjectFile(filepath.Join(dir, "src")); got != file {
+		t.Errorf("FindProjectFile = %q, want %s", got, file)
+	}
+	if got := MetadataFile(dir); got != file {
+		t.Errorf("MetadataFile = %q, want %s", got, file)
+	}
+
+	loaded, err := LoadProject(file)
+	if err != nil {
+		t.Fatalf("LoadProject(sidecar): %v", err)
+	}
+	if loaded.Path != dir || !loaded.Sidecar() || loaded.File() != file {
+		t.Errorf("loaded Path %q, Sidecar %v, File %q", loaded.Path, loaded.Sidecar(), loaded.File())
+	}
+
+	// Saving writes the sidecar, not the project
+	loaded.ProjectInfo.Status = "active"
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".project.toml")); !os.IsNotExist(err) {
+		t.Errorf("Save wrote .project.toml into the project")
+	}
+
+	back, err := loaded.MoveToSidecar(false)
+	if err != nil {
+		t.Fatalf("MoveToSidecar(false): %v", err)
+	}
+	if back != filepath.Join(dir, ".project.toml") {
+		t.Errorf("restored to %s", back)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("sidecar left behind")
+	}
+	data, _ = os.ReadFile(back)
+	if strings.Contains(string(data), "root =") || !strings.Contains(string(data), `status = "active"`) {
+		t.Errorf("restored file:\n%s", data)
+	}
+}
+
+func TestSidecarTakesPrecedenceAndStaysInItsRoot(t *testing.T) {
+	home, root := sidecarHome(t)
+	dir := filepath.Join(root, "client")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, ".project.toml"), []byte("[project]\nid = \"repo\"\n"), 0644)
+	other := filepath.Join(home, "archive", "old")
+	os.MkdirAll(other, 0755)
+
+	meta := filepath.Join(home, ".local", "share", "pk", "meta")
+	os.MkdirAll(meta, 0755)
+	sidecar := filepath.Join(meta, "client.toml")
+	os.WriteFile(sidecar, []byte("root = \"~/projects/client\"\n\n[project]\nid = \"client\"\n"), 0644)
+	os.WriteFile(filepath.Join(meta, "old.toml"), []byte("root = \""+other+"\"\n\n[project]\nid = \"old\"\n"), 0644)
+	os.WriteFile(filepath.Join(meta, "gone.toml"), []byte("root = \""+filepath.Join(root, "gone")+"\"\n"), 0644)
+	os.WriteFile(filepath.Join(meta, "broken.toml"), []byte("[project\n"), 0644)
+
+	projects, state, err := ScanRoot(root)
+	if err != nil {
+		t.Fatalf("ScanRoot: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ProjectInfo.ID != "client" || projects[0].Path != dir {
+		t.Fatalf("projects = %+v, want the sidecar's", projects)
+	}
+
+	// Editing the sidecar invalidates the scan
+	if state.Changed() {
+		t.Fatalf("state changed before any edit")
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(sidecar, later, later)
+	if !state.Changed() {
+		t.Errorf("state unchanged after editing the sidecar")
+	}
+}
+
+func TestSidecarFollowsIDChange(t *testing.T) {
+	home, root := sidecarHome(t)
+	dir := filepath.Join(root, "prototype")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, ".project.toml"), []byte("[project]\nid = \"prototype\"\n"), 0644)
+
+	project, _ := LoadProject(filepath.Join(dir, ".project.toml"))
+	old, err := project.MoveToSidecar(true)
+	if err != nil {
+		t.Fatalf("MoveToSidecar: %v", err)
+	}
+
+	project.ProjectInfo.ID = "app"
+	if err := project.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	want := filepath.Join(home, ".local", "share", "pk", "meta", "app.toml")
+	if project.File() != want {
+		t.Errorf("File = %s, want %s", project.File(), want)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old sidecar %s left behind", old)
+	}
+	if got := MetadataFile(dir); got != want {
+		t.Errorf("MetadataFile = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// RepoMarkers are entries that make a directory look like a project of its
+// own: version control or a language manifest
+var RepoMarkers = []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+
+// FindUntracked returns directories under rootDirs that look like projects
+// (see RepoMarkers) but have no .project.toml. It follows discovery's
+// rules: ignored directories and anything below Scan.MaxDepth are skipped,
+// and tracked projects are only searched if they hold nested projects. An
+// untracked directory isn't searched further, and roots themselves are
+// never reported.
+func FindUntracked(rootDirs ...string) ([]string, error) {
+	var found []string
+
+	var visit func(root, dir string, depth int) error
+	visit = func(root, dir string, depth int) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+
+		if dir != root {
+			if slices.Contains(names, ".project.toml") {
+				if !holdsNestedProjects(filepath.Join(dir, ".project.toml")) {
+					return nil
+				}
+			} else if slices.ContainsFunc(RepoMarkers, func(m string) bool { return slices.Contains(names, m) }) {
+				found = append(found, dir)
+				return nil
+			}
+		}
+
+		if Scan.MaxDepth > 0 && depth >= Scan.MaxDepth {
+			return nil
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if rel, err := filepath.Rel(root, path); err == nil && Ignored(rel) {
+				continue
+			}
+			if err := visit(root, path, depth+1); err != nil && !os.IsPermission(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range rootDirs {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		if err := visit(root, root, 0); err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
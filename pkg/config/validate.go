@@ -0,0 +1,258 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Allowed values for enum fields (see docs/schema-design.md)
+var (
+	StatusValues     = []string{"active", "archived", "completed", "experimental", "paused"}
+	OwnershipValues  = []string{"datakai", "client", "shared", "open-source"}
+	VisibilityValues = []string{"private", "public", "client-confidential"}
+	SplitValues      = []string{"vertical", "horizontal"}
+)
+
+// envNamePattern matches names a shell can export
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DateLayout is the format for [dates] fields
+const DateLayout = "2006-01-02"
+
+// Diagnostic is a single schema problem in a .project.toml file
+type Diagnostic struct {
+	Line    int    // 1-based; 0 when the position is unknown
+	Key     string // Dotted key path, e.g. "project.status"
+	Message string
+}
+
+// Format renders the diagnostic as "file:line: key: message"
+func (d Diagnostic) Format(file string) string {
+	pos := file
+	if d.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", file, d.Line)
+	}
+	if d.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", pos, d.Key, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", pos, d.Message)
+}
+
+// decodeErrPattern picks apart "toml: line N (last key "k"): msg" decode errors
+var decodeErrPattern = regexp.MustCompile(`^toml: (?:line (\d+) )?\(last key "([^"]*)"\): (.*)$`)
+
+// ValidateFile checks a .project.toml against the schema. The error is only
+// set when the file can't be read; syntax problems are reported as diagnostics.
+func ValidateFile(path string) ([]Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(data), nil
+}
+
+// Validate checks raw .project.toml content against the schema
+func Validate(data []byte) []Diagnostic {
+	var project Project
+	md, err := toml.Decode(string(data), &project)
+	if err != nil {
+		return []Diagnostic{decodeDiagnostic(err)}
+	}
+
+	lines := keyLines(data)
+	var diags []Diagnostic
+	add := func(key, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{Line: lines.find(key), Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Unknown keys (report only the outermost unknown table)
+	undecoded := make(map[string]bool)
+	for _, k := range md.Undecoded() {
+		undecoded[k.String()] = true
+	}
+	for _, k := range md.Undecoded() {
+		if len(k) > 1 && undecoded[k[:len(k)-1].String()] {
+			continue
+		}
+		add(k.String(), "unknown key")
+	}
+
+	// Required core fields
+	for _, key := range RequiredKeys {
+		if stringField(&project, key) == "" {
+			add(key, "required field is missing")
+		}
+	}
+
+	// Extension sections make their key field required
+	for _, section := range sortedKeys(SectionRequiredKeys) {
+		key := section + "." + SectionRequiredKeys[section]
+		if md.IsDefined(section) && stringField(&project, key) == "" {
+			add(key, "required when [%s] is present", section)
+		}
+	}
+
+	// Enums
+	for _, key := range sortedKeys(EnumKeys) {
+		allowed := EnumKeys[key]
+		if value := stringField(&project, key); value != "" && !slices.Contains(allowed, value) {
+			add(key, "invalid value %q (want %s)", value, strings.Join(allowed, ", "))
+		}
+	}
+
+	// Dates
+	for _, key := range DateKeys {
+		value := stringField(&project, key)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, value); err != nil {
+			add(key, "malformed date %q (want YYYY-MM-DD)", value)
+		}
+	}
+
+	// Tmux panes, and metadata references in window and pane commands
+	for i, window := range project.Tmux.Windows {
+		if _, err := project.Expand(window.Command); err != nil {
+			add("tmux.windows.command", "window %d: %v", i+1, err)
+		}
+		for j, pane := range window.Panes {
+			where := fmt.Sprintf("window %d, pane %d", i+1, j+1)
+			if _, err := project.Expand(pane.Command); err != nil {
+				add("tmux.windows.panes.command", "%s: %v", where, err)
+			}
+			if pane.Split != "" && !slices.Contains(SplitValues, pane.Split) {
+				add("tmux.windows.panes.split", "%s: invalid value %q (want %s)", where, pane.Split, strings.Join(SplitValues, ", "))
+			}
+			if pane.Size < 0 || pane.Size > 99 {
+				add("tmux.windows.panes.size", "%s: size %d is not a percentage (1-99)", where, pane.Size)
+			}
+		}
+	}
+
+	// Session environment
+	for _, name := range sortedKeys(project.Tmux.Env) {
+		switch {
+		case !envNamePattern.MatchString(name):
+			add("tmux.env", "%q is not a valid environment variable name", name)
+		case strings.HasPrefix(name, "PK_"):
+			add("tmux.env", "%s is set by pk and can't be overridden", name)
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	return diags
+}
+
+// decodeDiagnostic turns a TOML syntax or type error into a diagnostic
+func decodeDiagnostic(err error) Diagnostic {
+	var perr toml.ParseError
+	if errors.As(err, &perr) {
+		return Diagnostic{Line: perr.Position.Line, Key: perr.LastKey, Message: perr.Message}
+	}
+
+	if m := decodeErrPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return Diagnostic{Line: line, Key: m[2], Message: m[3]}
+	}
+
+	return Diagnostic{Message: err.Error()}
+}
+
+// keyLineMap maps dotted key paths to the line they are defined on
+type keyLineMap map[string]int
+
+var (
+	tableHeader = regexp.MustCompile(`^\[\[?\s*([^\[\]]+?)\s*\]\]?`)
+	keyAssign   = regexp.MustCompile(`^([A-Za-z0-9_\-."' ]+?)\s*=`)
+)
+
+// keyLines does a light line scan of TOML source to locate keys. It only
+// needs to be good enough to point a human at the right line.
+func keyLines(data []byte) keyLineMap {
+	lines := make(keyLineMap)
+	table := ""
+	inMultiline := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip the bodies of multi-line strings
+		if inMultiline != "" {
+			if strings.Count(line, inMultiline)%2 == 1 {
+				inMultiline = ""
+			}
+			continue
+		}
+
+		if m := tableHeader.FindStringSubmatch(line); m != nil {
+			table = unquoteKey(m[1])
+			if _, ok := lines[table]; !ok {
+				lines[table] = n
+			}
+			continue
+		}
+
+		if m := keyAssign.FindStringSubmatch(line); m != nil {
+			key := unquoteKey(m[1])
+			if table != "" {
+				key = table + "." + key
+			}
+			if _, ok := lines[key]; !ok {
+				lines[key] = n
+			}
+		}
+
+		for _, delim := range []string{`"""`, `'''`} {
+			if strings.Count(line, delim)%2 == 1 {
+				inMultiline = delim
+			}
+		}
+	}
+
+	return lines
+}
+
+// find returns the line for key, falling back to its closest parent
+func (l keyLineMap) find(key string) int {
+	for key != "" {
+		if n, ok := l[key]; ok {
+			return n
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return 0
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unquoteKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
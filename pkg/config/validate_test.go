@@ -0,0 +1,191 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateClean(t *testing.T) {
+	content := `# Project Metadata
+
+[project]
+name = "Test"
+id = "test"
+status = "active"
+type = "product"
+
+[dates]
+started = "2025-01-15"
+completed = ""
+
+[tmux]
+windows = [{name = "editor", command = "nvim"}]
+
+[consultant]
+ownership = "datakai"
+`
+	if diags := Validate([]byte(content)); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	content := `[project]
+name = "Test"
+status = "done"
+type = "product"
+colour = "blue"
+
+[dates]
+started = "15/01/2025"
+
+[datakai]
+visibility = "secret"
+
+[extras]
+foo = "bar"
+`
+	diags := Validate([]byte(content))
+
+	want := []Diagnostic{
+		{Line: 1, Key: "project.id"},
+		{Line: 3, Key: "project.status"},
+		{Line: 5, Key: "project.colour"},
+		{Line: 8, Key: "dates.started"},
+		{Line: 11, Key: "datakai.visibility"},
+		{Line: 13, Key: "extras"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(want), len(diags), diags)
+	}
+	for i, w := range want {
+		if diags[i].Line != w.Line || diags[i].Key != w.Key {
+			t.Errorf("Diagnostic %d: expected %d %s, got %d %s", i, w.Line, w.Key, diags[i].Line, diags[i].Key)
+		}
+	}
+
+	if !strings.Contains(diags[1].Message, `"done"`) {
+		t.Errorf("Expected status message to quote the bad value, got %q", diags[1].Message)
+	}
+}
+
+func TestValidateTmuxPanes(t *testing.T) {
+	content := `[project]
+name = "Test"
+id = "test"
+status = "active"
+type = "tool"
+
+[[tmux.windows]]
+name = "dev"
+
+[[tmux.windows.panes]]
+split = "horizontal"
+size = 40
+
+[[tmux.windows.panes]]
+split = "diagonal"
+size = 150
+`
+	diags := Validate([]byte(content))
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", diags)
+	}
+	if diags[0].Key != "tmux.windows.panes.split" || !strings.Contains(diags[0].Message, "pane 2") {
+		t.Errorf("Expected the second pane's split to be reported, got %v", diags[0])
+	}
+	if diags[1].Key != "tmux.windows.panes.size" {
+		t.Errorf("Expected the size to be reported, got %v", diags[1])
+	}
+}
+
+func TestValidateTmuxCommands(t *testing.T) {
+	content := `[project]
+name = "Test"
+id = "test"
+status = "active"
+type = "tool"
+
+[context]
+aws_profile = "acme"
+
+[[tmux.windows]]
+name = "cloud"
+command = "aws sso login --profile {{.Context.AWSProfile}}"
+
+[[tmux.windows]]
+name = "db"
+command = "psql {{.Context.Database}}"
+`
+	diags := Validate([]byte(content))
+	if len(diags) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", diags)
+	}
+	if diags[0].Key != "tmux.windows.command" || !strings.Contains(diags[0].Message, "window 2") {
+		t.Errorf("Expected the second window's command to be reported, got %v", diags[0])
+	}
+}
+
+func TestValidateTmuxEnv(t *testing.T) {
+	content := `[project]
+name = "Test"
+id = "test"
+status = "active"
+type = "tool"
+
+[tmux.env]
+AWS_PROFILE = "acme"
+"DBT-TARGET" = "prod"
+PK_CLIENT = "Acme"
+`
+	diags := Validate([]byte(content))
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Message, "DBT-TARGET") || !strings.Contains(diags[1].Message, "PK_CLIENT") {
+		t.Errorf("Expected the invalid and reserved names to be reported, got %v", diags)
+	}
+}
+
+func TestValidateRequiredSections(t *testing.T) {
+	content := `[project]
+name = "Test"
+id = "test"
+status = "active"
+type = "product"
+
+[consultant]
+client_name = "Acme"
+`
+	diags := Validate([]byte(content))
+	if len(diags) != 1 || diags[0].Key != "consultant.ownership" || diags[0].Line != 7 {
+		t.Errorf("Expected missing consultant.ownership at line 7, got %v", diags)
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	content := `[project]
+name = "Test"
+id = "test
+`
+	diags := Validate([]byte(content))
+	if len(diags) != 1 || diags[0].Line != 3 {
+		t.Errorf("Expected one syntax diagnostic on line 3, got %v", diags)
+	}
+}
+
+func TestValidateTypeError(t *testing.T) {
+	content := `[project]
+name = "Test"
+id = "test"
+status = "active"
+type = "product"
+
+[dates]
+started = 2025-01-15
+`
+	diags := Validate([]byte(content))
+	if len(diags) != 1 || diags[0].Line != 8 || diags[0].Key != "dates.started" {
+		t.Errorf("Expected type diagnostic for dates.started on line 8, got %v", diags)
+	}
+}
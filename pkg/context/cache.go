@@ -0,0 +1,74 @@
+package context
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// SwitchCacheTTL is how long a global CLI switch (az account set, gcloud
+// config set) is trusted before it is re-applied. Opening many sessions for
+// the same subscription in a row then costs one CLI call, not one per session.
+const SwitchCacheTTL = 5 * time.Minute
+
+// switchCacheEntry is the last value applied for a provider
+type switchCacheEntry struct {
+	Value      string    `json:"value"`
+	SwitchedAt time.Time `json:"switched_at"`
+}
+
+// switchCache maps provider name to its last applied value
+type switchCache map[string]switchCacheEntry
+
+// GetSwitchCacheFile returns the path to the context switch cache
+func GetSwitchCacheFile() (string, error) {
+	return statefile.Path("context.json")
+}
+
+func loadSwitchCache() switchCache {
+	state := make(switchCache)
+
+	cacheFile, err := GetSwitchCacheFile()
+	if err != nil {
+		return state
+	}
+
+	if data, err := statefile.ReadFile(cacheFile); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func saveSwitchCache(state switchCache) {
+	cacheFile, err := GetSwitchCacheFile()
+	if err != nil {
+		return
+	}
+
+	if data, err := json.MarshalIndent(state, "", "  "); err == nil {
+		statefile.WriteFile(cacheFile, data, 0644)
+	}
+}
+
+// fresh reports whether provider was switched to value within the TTL
+func (c switchCache) fresh(provider, value string) bool {
+	entry, exists := c[provider]
+	return exists && entry.Value == value && time.Since(entry.SwitchedAt) < SwitchCacheTTL
+}
+
+// record remembers a successful switch
+func (c switchCache) record(provider, value string) {
+	c[provider] = switchCacheEntry{Value: value, SwitchedAt: time.Now()}
+}
+
+// InvalidateSwitchCache forgets all cached switches, forcing the next
+// session open to re-apply every context
+func InvalidateSwitchCache() error {
+	cacheFile, err := GetSwitchCacheFile()
+	if err != nil {
+		return err
+	}
+
+	return statefile.Remove(cacheFile)
+}
@@ -0,0 +1,43 @@
+package context
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSwitchCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	state := loadSwitchCache()
+	if state.fresh("Azure", "prod") {
+		t.Error("Empty cache should not be fresh")
+	}
+
+	state.record("Azure", "prod")
+	saveSwitchCache(state)
+
+	reloaded := loadSwitchCache()
+	if !reloaded.fresh("Azure", "prod") {
+		t.Error("Expected recorded switch to be fresh after reload")
+	}
+	if reloaded.fresh("Azure", "dev") {
+		t.Error("A different subscription must not be considered fresh")
+	}
+
+	// Expired entries are re-applied
+	reloaded["GCloud"] = switchCacheEntry{Value: "proj", SwitchedAt: time.Now().Add(-SwitchCacheTTL - time.Second)}
+	if reloaded.fresh("GCloud", "proj") {
+		t.Error("Expected expired entry to be stale")
+	}
+
+	if err := InvalidateSwitchCache(); err != nil {
+		t.Fatalf("InvalidateSwitchCache failed: %v", err)
+	}
+	if loadSwitchCache().fresh("Azure", "prod") {
+		t.Error("Expected cache to be empty after invalidation")
+	}
+}
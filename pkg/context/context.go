@@ -2,80 +2,133 @@ package context
 
 import (
 	"fmt"
-	"os/exec"
+	"strings"
+	"sync"
 
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
 )
 
-// Switch switches cloud and git contexts based on project configuration
+// switcher is one independent context switch (e.g. Azure subscription)
+type switcher struct {
+	provider string // Cache key and summary label
+	value    string
+	run      func(string) error
+	cache    bool // Only cache switches that change global CLI state
+}
+
+// switchResult is the outcome of one switcher
+type switchResult struct {
+	switcher
+	cached  bool
+	skipped string // Why the policy kept it from running
+	err     error
+}
+
+// Switch switches cloud and git contexts based on project configuration.
+// Independent switches run in parallel; global CLI switches (az, gcloud)
+// that were already applied within SwitchCacheTTL are skipped. Projects
+// with [context] auto_switch = false are left alone, and global switches
+// follow the Policy in config.toml.
 func Switch(project *config.Project) error {
-	if project.Context.GitIdentity == "" &&
-		project.Context.AWSProfile == "" &&
-		project.Context.AzureSubscription == "" &&
-		project.Context.GCloudProject == "" &&
-		project.Context.DatabricksProfile == "" &&
-		project.Context.SnowflakeAccount == "" {
-		// No context configured
-		return nil
+	switchers := []switcher{
+		{"Git", project.Context.GitIdentity, switchGitIdentity, false},
+		{"AWS", project.Context.AWSProfile, switchAWSProfile, false},
+		{"Azure", project.Context.AzureSubscription, switchAzureSubscription, true},
+		{"GCloud", project.Context.GCloudProject, switchGCloudProject, true},
+		{"Kube", project.Context.KubeContext, switchKubeContext, true},
+		// Databricks and Snowflake use env vars, set in session
+		{"Databricks", project.Context.DatabricksProfile, nil, false},
+		{"Snowflake", project.Context.SnowflakeAccount, nil, false},
 	}
 
-	fmt.Printf("☁️  Switching context for: %s\n", project.ProjectInfo.Name)
-
-	// Switch git identity
-	if project.Context.GitIdentity != "" {
-		if err := switchGitIdentity(project.Context.GitIdentity); err != nil {
-			fmt.Printf("Warning: Failed to switch git identity: %v\n", err)
-		} else {
-			fmt.Printf("   Git: %s\n", project.Context.GitIdentity)
+	var active []switcher
+	for _, s := range switchers {
+		if s.value != "" {
+			active = append(active, s)
 		}
 	}
 
-	// Switch AWS profile
-	if project.Context.AWSProfile != "" {
-		if err := switchAWSProfile(project.Context.AWSProfile); err != nil {
-			fmt.Printf("Warning: Failed to switch AWS profile: %v\n", err)
-		} else {
-			fmt.Printf("   AWS: %s\n", project.Context.AWSProfile)
-		}
+	if len(active) == 0 {
+		// No context configured
+		return nil
 	}
 
-	// Switch Azure subscription
-	if project.Context.AzureSubscription != "" {
-		if err := switchAzureSubscription(project.Context.AzureSubscription); err != nil {
-			fmt.Printf("Warning: Failed to switch Azure subscription: %v\n", err)
-		} else {
-			fmt.Printf("   Azure: %s\n", project.Context.AzureSubscription)
-		}
+	if !project.ContextAutoSwitch() {
+		fmt.Printf("☁️  Context for %s: not switched ([context] auto_switch = false)\n", project.ProjectInfo.Name)
+		return nil
 	}
 
-	// Switch GCloud project
-	if project.Context.GCloudProject != "" {
-		if err := switchGCloudProject(project.Context.GCloudProject); err != nil {
-			fmt.Printf("Warning: Failed to switch GCloud project: %v\n", err)
-		} else {
-			fmt.Printf("   GCloud: %s\n", project.Context.GCloudProject)
+	policy := LoadPolicy()
+	blocked := policy.Blocks(project)
+	state := loadSwitchCache()
+	results := make([]switchResult, len(active))
+
+	var wg sync.WaitGroup
+	for i, s := range active {
+		results[i].switcher = s
+
+		if s.run == nil {
+			continue
+		}
+		if s.cache && state.fresh(s.provider, s.value) {
+			results[i].cached = true
+			continue
+		}
+		if s.cache && blocked != "" {
+			results[i].skipped = blocked
+			continue
+		}
+		// Prompts run before any switch starts, so they don't interleave
+		if s.cache && policy.NeedsConfirm(s.value) &&
+			!Confirm(fmt.Sprintf("Switch %s to %s? (y/N): ", s.provider, s.value)) {
+			results[i].skipped = "declined"
+			continue
 		}
-	}
 
-	// Switch Databricks profile
-	if project.Context.DatabricksProfile != "" {
-		fmt.Printf("   Databricks: %s\n", project.Context.DatabricksProfile)
-		// Databricks uses env var, set in session
+		wg.Add(1)
+		go func(r *switchResult) {
+			defer wg.Done()
+			r.err = r.run(r.value)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	// Combined summary line, warnings after
+	var parts []string
+	var warnings []string
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			parts = append(parts, fmt.Sprintf("%s %s (failed)", r.provider, r.value))
+			warnings = append(warnings, fmt.Sprintf("Warning: Failed to switch %s: %v", r.provider, r.err))
+		case r.cached:
+			parts = append(parts, fmt.Sprintf("%s %s (cached)", r.provider, r.value))
+		case r.skipped != "":
+			parts = append(parts, fmt.Sprintf("%s %s (skipped: %s)", r.provider, r.value, r.skipped))
+		case r.run == nil:
+			parts = append(parts, fmt.Sprintf("%s %s (session env)", r.provider, r.value))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %s", r.provider, r.value))
+			if r.cache && r.run != nil {
+				state.record(r.provider, r.value)
+			}
+		}
 	}
 
-	// Switch Snowflake account
-	if project.Context.SnowflakeAccount != "" {
-		fmt.Printf("   Snowflake: %s\n", project.Context.SnowflakeAccount)
-		// Snowflake uses env var, set in session
+	fmt.Printf("☁️  Context for %s: %s\n", project.ProjectInfo.Name, strings.Join(parts, " · "))
+	for _, w := range warnings {
+		fmt.Println(w)
 	}
 
+	saveSwitchCache(state)
 	return nil
 }
 
 func switchGitIdentity(identity string) error {
-	// Check if git is installed
-	if _, err := exec.LookPath("git"); err != nil {
-		return fmt.Errorf("git not installed")
+	if err := deps.Require("Git context", "git"); err != nil {
+		return err
 	}
 
 	// This sets local git config for the project directory
@@ -84,9 +137,8 @@ func switchGitIdentity(identity string) error {
 }
 
 func switchAWSProfile(profile string) error {
-	// Check if aws CLI is installed
-	if _, err := exec.LookPath("aws"); err != nil {
-		return fmt.Errorf("aws CLI not installed")
+	if err := deps.Require("AWS context", "aws"); err != nil {
+		return err
 	}
 
 	// Export AWS_PROFILE environment variable (done in session)
@@ -94,23 +146,31 @@ func switchAWSProfile(profile string) error {
 }
 
 func switchAzureSubscription(subscription string) error {
-	// Check if az CLI is installed
-	if _, err := exec.LookPath("az"); err != nil {
-		return fmt.Errorf("azure CLI not installed")
+	if err := deps.Require("Azure context", "az"); err != nil {
+		return err
 	}
 
 	// Set default subscription
-	cmd := exec.Command("az", "account", "set", "--subscription", subscription)
-	return cmd.Run()
+	cmd := runner.Command("az", "account", "set", "--subscription", subscription)
+	return runner.Run(cmd)
 }
 
 func switchGCloudProject(project string) error {
-	// Check if gcloud is installed
-	if _, err := exec.LookPath("gcloud"); err != nil {
-		return fmt.Errorf("gcloud CLI not installed")
+	if err := deps.Require("GCloud context", "gcloud"); err != nil {
+		return err
 	}
 
 	// Set default project
-	cmd := exec.Command("gcloud", "config", "set", "project", project)
-	return cmd.Run()
+	cmd := runner.Command("gcloud", "config", "set", "project", project)
+	return runner.Run(cmd)
+}
+
+func switchKubeContext(kubeContext string) error {
+	if err := deps.Require("Kubernetes context", "kubectl"); err != nil {
+		return err
+	}
+
+	// Set current context in kubeconfig
+	cmd := runner.Command("kubectl", "config", "use-context", kubeContext)
+	return runner.Run(cmd)
 }
@@ -0,0 +1,142 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestSwitchRunsCloudCLIs(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	project := &config.Project{}
+	project.ProjectInfo.Name = "Dojo"
+	project.Context.AzureSubscription = "prod-sub"
+	project.Context.GCloudProject = "dojo-prod"
+
+	Switch(project)
+
+	// Switches run in parallel, so order is not fixed
+	got := fake.Commands()
+	sort.Strings(got)
+	want := []string{
+		"az account set --subscription prod-sub",
+		"gcloud config set project dojo-prod",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+
+	// A second switch within the TTL is served from the cache
+	Switch(project)
+	if n := len(fake.Calls()); n != 2 {
+		t.Errorf("ran %d commands after cached switch, want 2", n)
+	}
+}
+
+func TestSwitchSkipsMissingCLI(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	fake.Missing("az")
+	defer runner.Swap(fake)()
+
+	project := &config.Project{}
+	project.Context.AzureSubscription = "prod-sub"
+
+	Switch(project)
+
+	if calls := fake.Commands(); len(calls) != 0 {
+		t.Errorf("expected no commands without az installed, got %q", calls)
+	}
+	if loadSwitchCache().fresh("Azure", "prod-sub") {
+		t.Error("a failed switch must not be cached")
+	}
+}
+
+func TestSwitchHonoursAutoSwitch(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	off := false
+	project := &config.Project{}
+	project.Context.AzureSubscription = "prod-sub"
+	project.Context.AutoSwitch = &off
+
+	Switch(project)
+
+	if calls := fake.Commands(); len(calls) != 0 {
+		t.Errorf("auto_switch = false should run nothing, got %q", calls)
+	}
+}
+
+func TestSwitchPolicy(t *testing.T) {
+	home := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	configDir := filepath.Join(home, ".config", "pk")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(`
+[context]
+skip_visibility = ["public"]
+confirm_profiles = ["*-prod"]
+`), 0644)
+
+	var asked []string
+	answer := false
+	originalConfirm := Confirm
+	Confirm = func(prompt string) bool {
+		asked = append(asked, prompt)
+		return answer
+	}
+	defer func() { Confirm = originalConfirm }()
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	// Public projects never touch global CLI state
+	public := &config.Project{}
+	public.DataKai.Visibility = "public"
+	public.Context.GCloudProject = "demo-dev"
+	Switch(public)
+	if calls := fake.Commands(); len(calls) != 0 {
+		t.Errorf("public project switched global state: %q", calls)
+	}
+
+	// Production values ask first; declining skips only that switch
+	project := &config.Project{}
+	project.Context.AzureSubscription = "acme-prod"
+	project.Context.GCloudProject = "acme-dev"
+	Switch(project)
+	if want := []string{"gcloud config set project acme-dev"}; !reflect.DeepEqual(fake.Commands(), want) {
+		t.Errorf("after declining, commands = %q, want %q", fake.Commands(), want)
+	}
+	if len(asked) != 1 {
+		t.Fatalf("asked %d times, want once: %q", len(asked), asked)
+	}
+
+	answer = true
+	Switch(project)
+	got := fake.Commands()
+	if len(got) != 2 || got[1] != "az account set --subscription acme-prod" {
+		t.Errorf("after confirming, commands = %q", got)
+	}
+}
@@ -0,0 +1,217 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// CredState is whether a login can be used right now
+type CredState int
+
+const (
+	CredUnknown CredState = iota // CLI missing or status unreadable
+	CredValid
+	CredInvalid // Expired or never logged in
+)
+
+// Credential is one cloud login a project's [context] depends on
+type Credential struct {
+	Provider string // AWS, Azure, GCloud, Kube
+	Profile  string // Profile, subscription, or kube context
+	State    CredState
+	Expires  time.Time // Zero when the provider doesn't say
+	Detail   string    // Why the state isn't valid
+}
+
+// Key identifies the login; projects sharing a profile share a Key
+func (c Credential) Key() string {
+	return c.Provider + "\x00" + c.Profile
+}
+
+// credProvider knows how to check and renew one kind of login
+type credProvider struct {
+	tool  string
+	check func(profile string) (time.Time, error)
+	login func(profile string) []string
+}
+
+var credProviders = map[string]credProvider{
+	"AWS":    {"aws", checkAWS, func(p string) []string { return []string{"aws", "sso", "login", "--profile", p} }},
+	"Azure":  {"az", checkAzure, func(string) []string { return []string{"az", "login", "--output", "none"} }},
+	"GCloud": {"gcloud", checkGCloudADC, func(string) []string { return []string{"gcloud", "auth", "application-default", "login"} }},
+	"Kube":   {"kubectl", checkKube, func(p string) []string { return []string{"kubectl", "--context", p, "auth", "whoami"} }},
+}
+
+// gcloudADC labels gcloud's Application Default Credentials, which are
+// shared by every project whatever its gcloud_project
+const gcloudADC = "application-default"
+
+// Credentials lists the logins a project needs, unchecked
+func Credentials(project *config.Project) []Credential {
+	var creds []Credential
+	add := func(provider, profile string) {
+		if profile != "" {
+			creds = append(creds, Credential{Provider: provider, Profile: profile})
+		}
+	}
+	add("AWS", project.Context.AWSProfile)
+	add("Azure", project.Context.AzureSubscription)
+	if project.Context.GCloudProject != "" {
+		add("GCloud", gcloudADC)
+	}
+	add("Kube", project.Context.KubeContext)
+	return creds
+}
+
+// CheckCredentials checks each login in parallel and returns them with
+// State, Expires and Detail filled in
+func CheckCredentials(creds []Credential) []Credential {
+	checked := make([]Credential, len(creds))
+	var wg sync.WaitGroup
+	for i, c := range creds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checked[i] = checkCredential(c)
+		}()
+	}
+	wg.Wait()
+	return checked
+}
+
+func checkCredential(c Credential) Credential {
+	provider, ok := credProviders[c.Provider]
+	if !ok {
+		c.State, c.Detail = CredUnknown, "unsupported provider"
+		return c
+	}
+	if _, err := runner.LookPath(provider.tool); err != nil {
+		c.State, c.Detail = CredUnknown, provider.tool+" not installed"
+		return c
+	}
+
+	expires, err := provider.check(c.Profile)
+	switch {
+	case err != nil:
+		c.State, c.Detail = CredInvalid, errorDetail(err)
+	case !expires.IsZero() && time.Now().After(expires):
+		c.State, c.Expires, c.Detail = CredInvalid, expires, "expired"
+	default:
+		c.State, c.Expires = CredValid, expires
+	}
+	return c
+}
+
+// Refresh re-authenticates c interactively, attached to the terminal
+func Refresh(c Credential) error {
+	provider, ok := credProviders[c.Provider]
+	if !ok {
+		return errors.New("unsupported provider " + c.Provider)
+	}
+	if err := deps.Require("'pk creds refresh'", provider.tool); err != nil {
+		return err
+	}
+
+	args := provider.login(c.Profile)
+	cmd := runner.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := runner.Run(cmd); err != nil {
+		return err
+	}
+
+	// Logging in can reset CLI defaults (az login picks its own default
+	// subscription), so re-apply switches on the next session open
+	InvalidateSwitchCache()
+	return nil
+}
+
+// RefreshCommand is the command Refresh runs, for display
+func RefreshCommand(c Credential) string {
+	if provider, ok := credProviders[c.Provider]; ok {
+		return strings.Join(provider.login(c.Profile), " ")
+	}
+	return ""
+}
+
+// checkAWS resolves the profile's credentials the way the SDKs would,
+// which fails once an SSO session has expired
+func checkAWS(profile string) (time.Time, error) {
+	out, err := runner.Output(runner.Command("aws", "configure", "export-credentials",
+		"--profile", profile, "--format", "process"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	var creds struct {
+		Expiration string `json:"Expiration"`
+	}
+	json.Unmarshal(out, &creds)
+	// Static keys have no expiry
+	expires, _ := time.Parse(time.RFC3339, creds.Expiration)
+	return expires, nil
+}
+
+func checkAzure(subscription string) (time.Time, error) {
+	out, err := runner.Output(runner.Command("az", "account", "get-access-token",
+		"--subscription", subscription, "--output", "json"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseAzureExpiry(out), nil
+}
+
+// parseAzureExpiry prefers expires_on (Unix seconds, az 2.54+) over the
+// older expiresOn, which is local time without a zone
+func parseAzureExpiry(out []byte) time.Time {
+	var token struct {
+		ExpiresOnUnix json.RawMessage `json:"expires_on"`
+		ExpiresOn     string          `json:"expiresOn"`
+	}
+	if json.Unmarshal(out, &token) != nil {
+		return time.Time{}
+	}
+	if secs, err := strconv.ParseInt(strings.Trim(string(token.ExpiresOnUnix), `"`), 10, 64); err == nil {
+		return time.Unix(secs, 0)
+	}
+	expires, _ := time.ParseInLocation("2006-01-02 15:04:05.999999", token.ExpiresOn, time.Local)
+	return expires
+}
+
+// checkGCloudADC mints a token from Application Default Credentials. The
+// refresh token behind ADC doesn't report an expiry.
+func checkGCloudADC(string) (time.Time, error) {
+	_, err := runner.Output(runner.Command("gcloud", "auth", "application-default", "print-access-token"))
+	return time.Time{}, err
+}
+
+// checkKube asks the cluster who we are, which runs any exec credential
+// plugin and fails on an expired token
+func checkKube(kubeContext string) (time.Time, error) {
+	_, err := runner.Output(runner.Command("kubectl", "--context", kubeContext,
+		"--request-timeout", "10s", "auth", "whoami"))
+	return time.Time{}, err
+}
+
+// errorDetail is the first line a failed CLI printed, or the error itself
+func errorDetail(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		for _, line := range strings.Split(string(exitErr.Stderr), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return line
+			}
+		}
+	}
+	return err.Error()
+}
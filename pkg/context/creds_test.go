@@ -0,0 +1,105 @@
+package context
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestCredentialsFromContext(t *testing.T) {
+	project := &config.Project{}
+	project.Context.AWSProfile = "acme-dev"
+	project.Context.GCloudProject = "acme-data"
+	project.Context.KubeContext = "acme-prod"
+	project.Context.GitIdentity = "work" // not a cloud login
+
+	var got []string
+	for _, c := range Credentials(project) {
+		got = append(got, c.Provider+":"+c.Profile)
+	}
+	want := []string{"AWS:acme-dev", "GCloud:application-default", "Kube:acme-prod"}
+	if len(got) != len(want) {
+		t.Fatalf("Credentials = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Credentials[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	fake := runner.NewFake()
+	fake.Missing("kubectl")
+	expires := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	fake.On("aws configure export-credentials --profile live",
+		`{"Version": 1, "Expiration": "`+expires.Format(time.RFC3339)+`"}`, nil)
+	fake.On("aws configure export-credentials --profile stale", "", errors.New("token has expired"))
+	fake.On("az account get-access-token", `{"expires_on": 1}`, nil)
+	defer runner.Swap(fake)()
+
+	got := CheckCredentials([]Credential{
+		{Provider: "AWS", Profile: "live"},
+		{Provider: "AWS", Profile: "stale"},
+		{Provider: "Azure", Profile: "sub"},
+		{Provider: "GCloud", Profile: gcloudADC},
+		{Provider: "Kube", Profile: "prod"},
+	})
+
+	cases := []struct {
+		state  CredState
+		detail string
+	}{
+		{CredValid, ""},
+		{CredInvalid, "token has expired"},
+		{CredInvalid, "expired"},
+		{CredValid, ""},
+		{CredUnknown, "kubectl not installed"},
+	}
+	for i, tc := range cases {
+		if got[i].State != tc.state || got[i].Detail != tc.detail {
+			t.Errorf("%s %s: state %d %q, want %d %q",
+				got[i].Provider, got[i].Profile, got[i].State, got[i].Detail, tc.state, tc.detail)
+		}
+	}
+	if !got[0].Expires.Equal(expires) {
+		t.Errorf("AWS expiry = %v, want %v", got[0].Expires, expires)
+	}
+}
+
+func TestParseAzureExpiry(t *testing.T) {
+	if got := parseAzureExpiry([]byte(`{"expires_on": 1760000000}`)); got.Unix() != 1760000000 {
+		t.Errorf("expires_on: got %v", got)
+	}
+	legacy := parseAzureExpiry([]byte(`{"expiresOn": "2026-10-15 12:30:00.000000"}`))
+	if want := time.Date(2026, 10, 15, 12, 30, 0, 0, time.Local); !legacy.Equal(want) {
+		t.Errorf("expiresOn: got %v, want %v", legacy, want)
+	}
+}
+
+func TestRefreshInvalidatesSwitchCache(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	state := loadSwitchCache()
+	state.record("Azure", "prod-sub")
+	saveSwitchCache(state)
+
+	if err := Refresh(Credential{Provider: "Azure", Profile: "prod-sub"}); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := fake.Commands(); len(got) != 1 || got[0] != "az login --output none" {
+		t.Errorf("commands = %q", got)
+	}
+	if loadSwitchCache().fresh("Azure", "prod-sub") {
+		t.Error("switch cache should be cleared after logging in")
+	}
+}
@@ -0,0 +1,88 @@
+package context
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// ManagedEnvVars lists the environment variables pk sets from [context].
+// Any of these present in a session but absent from Env() is stale.
+var ManagedEnvVars = []string{
+	"AWS_PROFILE",
+	"AZURE_SUBSCRIPTION_ID",
+	"CLOUDSDK_CORE_PROJECT",
+	"DATABRICKS_CONFIG_PROFILE",
+	"SNOWFLAKE_ACCOUNT",
+	"PK_PROJECT_ID",
+	"PK_CLIENT",
+	"PK_VISIBILITY",
+	"PK_BILLABLE",
+}
+
+// Env returns the environment variables a project's context should set:
+// its kind's env, overridden by [context], then by [tmux.env], plus PK_*
+// variables describing the project so prompts, scripts and direnv can react
+// without calling pk
+func Env(project *config.Project) map[string]string {
+	env := make(map[string]string)
+	for key, value := range settings.KindEnv(project) {
+		env[key] = value
+	}
+
+	if project.Context.AWSProfile != "" {
+		env["AWS_PROFILE"] = project.Context.AWSProfile
+	}
+	if project.Context.AzureSubscription != "" {
+		env["AZURE_SUBSCRIPTION_ID"] = project.Context.AzureSubscription
+	}
+	if project.Context.GCloudProject != "" {
+		env["CLOUDSDK_CORE_PROJECT"] = project.Context.GCloudProject
+	}
+	if project.Context.DatabricksProfile != "" {
+		env["DATABRICKS_CONFIG_PROFILE"] = project.Context.DatabricksProfile
+	}
+	if project.Context.SnowflakeAccount != "" {
+		env["SNOWFLAKE_ACCOUNT"] = project.Context.SnowflakeAccount
+	}
+	for key, value := range project.Tmux.Env {
+		env[key] = value
+	}
+
+	for key, value := range ProjectEnv(project) {
+		env[key] = value
+	}
+	return env
+}
+
+// ManagedVars returns the variables pk manages in a project's session:
+// ManagedEnvVars plus those its kind and [tmux.env] set
+func ManagedVars(project *config.Project) []string {
+	managed := slices.Clone(ManagedEnvVars)
+	for key := range settings.KindEnv(project) {
+		managed = append(managed, key)
+	}
+	for key := range project.Tmux.Env {
+		managed = append(managed, key)
+	}
+	slices.Sort(managed)
+	return slices.Compact(managed)
+}
+
+// ProjectEnv returns the PK_* variables for a project. Empty fields are left
+// out; PK_BILLABLE is always "true" or "false".
+func ProjectEnv(project *config.Project) map[string]string {
+	env := map[string]string{
+		"PK_PROJECT_ID": project.ProjectInfo.ID,
+		"PK_BILLABLE":   strconv.FormatBool(project.Consultant.Billable),
+	}
+	if client := project.GetClientName(); client != "" {
+		env["PK_CLIENT"] = client
+	}
+	if project.DataKai.Visibility != "" {
+		env["PK_VISIBILITY"] = project.DataKai.Visibility
+	}
+	return env
+}
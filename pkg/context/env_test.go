@@ -0,0 +1,67 @@
+package context
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func TestEnvExportsProjectVars(t *testing.T) {
+	project := &config.Project{Path: "/work/acme-etl"}
+	project.ProjectInfo.ID = "acme-etl"
+	project.Consultant.ClientName = "Acme Corp"
+	project.Consultant.Billable = true
+	project.DataKai.Visibility = "client-confidential"
+	project.Context.AWSProfile = "acme-dev"
+
+	want := map[string]string{
+		"AWS_PROFILE":   "acme-dev",
+		"PK_PROJECT_ID": "acme-etl",
+		"PK_CLIENT":     "Acme Corp",
+		"PK_VISIBILITY": "client-confidential",
+		"PK_BILLABLE":   "true",
+	}
+	if got := Env(project); !reflect.DeepEqual(got, want) {
+		t.Errorf("Env = %v, want %v", got, want)
+	}
+
+	internal := &config.Project{}
+	internal.ProjectInfo.ID = "notes"
+	want = map[string]string{"PK_PROJECT_ID": "notes", "PK_BILLABLE": "false"}
+	if got := ProjectEnv(internal); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProjectEnv = %v, want %v", got, want)
+	}
+}
+
+func TestEnvTmuxEnvOverridesContext(t *testing.T) {
+	project := &config.Project{}
+	project.ProjectInfo.ID = "acme-etl"
+	project.Context.AWSProfile = "acme-dev"
+	project.Context.DatabricksProfile = "acme"
+	project.Tmux.Env = map[string]string{
+		"AWS_PROFILE":   "acme-prod",
+		"DBT_TARGET":    "prod",
+		"PK_PROJECT_ID": "spoofed",
+	}
+
+	env := Env(project)
+	if env["AWS_PROFILE"] != "acme-prod" {
+		t.Errorf("AWS_PROFILE = %q, want [tmux.env] to win", env["AWS_PROFILE"])
+	}
+	if env["DATABRICKS_CONFIG_PROFILE"] != "acme" || env["DBT_TARGET"] != "prod" {
+		t.Errorf("Env = %v, want [context] and custom variables", env)
+	}
+	if env["PK_PROJECT_ID"] != "acme-etl" {
+		t.Errorf("PK_PROJECT_ID = %q, PK_* variables can't be overridden", env["PK_PROJECT_ID"])
+	}
+
+	managed := ManagedVars(project)
+	if !slices.Contains(managed, "DBT_TARGET") || !slices.Contains(managed, "AWS_PROFILE") {
+		t.Errorf("ManagedVars = %v, want [tmux.env] keys included", managed)
+	}
+	if !slices.IsSorted(managed) || len(slices.Compact(slices.Clone(managed))) != len(managed) {
+		t.Errorf("ManagedVars = %v, want sorted and unique", managed)
+	}
+}
@@ -0,0 +1,109 @@
+package context
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Mismatch is a context the project expects that isn't the one active in
+// this shell
+type Mismatch struct {
+	Provider string
+	Want     string
+	Active   string // "" when nothing is active
+}
+
+// activeReader reports the value a CLI would use right now, and whether
+// want names it (an Azure subscription can be given by name or ID).
+// ok is false when the active value can't be read.
+type activeReader func(want string) (active string, matches, ok bool)
+
+// CheckActive compares the project's [context] with what the shell and
+// CLIs are using now. Providers whose state can't be read (CLI missing,
+// not logged in) are not reported.
+func CheckActive(project *config.Project) []Mismatch {
+	checks := []struct {
+		provider string
+		want     string
+		read     activeReader
+	}{
+		{"AWS", project.Context.AWSProfile, envReader("default", "AWS_PROFILE", "AWS_DEFAULT_PROFILE")},
+		{"Azure", project.Context.AzureSubscription, activeAzure},
+		{"GCloud", project.Context.GCloudProject, activeGCloud},
+		{"Kube", project.Context.KubeContext, cliReader("kubectl", "config", "current-context")},
+		{"Databricks", project.Context.DatabricksProfile, envReader("DEFAULT", "DATABRICKS_CONFIG_PROFILE")},
+		{"Snowflake", project.Context.SnowflakeAccount, envReader("", "SNOWFLAKE_ACCOUNT")},
+	}
+
+	var mismatches []Mismatch
+	for _, c := range checks {
+		if c.want == "" {
+			continue
+		}
+		active, matches, ok := c.read(c.want)
+		if ok && !matches {
+			mismatches = append(mismatches, Mismatch{c.provider, c.want, active})
+		}
+	}
+	return mismatches
+}
+
+// envReader reads the first set variable, falling back to the tool's default
+func envReader(fallback string, vars ...string) activeReader {
+	return func(want string) (string, bool, bool) {
+		active := fallback
+		for _, v := range vars {
+			if value := os.Getenv(v); value != "" {
+				active = value
+				break
+			}
+		}
+		return active, active == want, true
+	}
+}
+
+// cliReader runs a command that prints the active value
+func cliReader(name string, args ...string) activeReader {
+	return func(want string) (string, bool, bool) {
+		if _, err := runner.LookPath(name); err != nil {
+			return "", false, false
+		}
+		out, err := runner.Output(runner.Command(name, args...))
+		if err != nil {
+			return "", false, false
+		}
+		active := strings.TrimSpace(string(out))
+		return active, active == want, true
+	}
+}
+
+// activeGCloud honours CLOUDSDK_CORE_PROJECT, which overrides gcloud config
+func activeGCloud(want string) (string, bool, bool) {
+	if project := os.Getenv("CLOUDSDK_CORE_PROJECT"); project != "" {
+		return project, project == want, true
+	}
+	return cliReader("gcloud", "config", "get-value", "project")(want)
+}
+
+func activeAzure(want string) (string, bool, bool) {
+	if _, err := runner.LookPath("az"); err != nil {
+		return "", false, false
+	}
+	out, err := runner.Output(runner.Command("az", "account", "show", "--output", "json"))
+	if err != nil {
+		return "", false, false
+	}
+	var account struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(out, &account) != nil {
+		return "", false, false
+	}
+	matches := strings.EqualFold(want, account.ID) || want == account.Name
+	return account.Name, matches, true
+}
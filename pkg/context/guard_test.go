@@ -0,0 +1,55 @@
+package context
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestCheckActive(t *testing.T) {
+	t.Setenv("AWS_PROFILE", "globex-prod")
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+	t.Setenv("CLOUDSDK_CORE_PROJECT", "")
+	t.Setenv("DATABRICKS_CONFIG_PROFILE", "")
+
+	fake := runner.NewFake()
+	fake.On("az account show", `{"id": "0000-1111", "name": "Acme Prod"}`, nil)
+	fake.On("gcloud config get-value project", "acme-data\n", nil)
+	fake.On("kubectl config current-context", "globex-aks\n", nil)
+	defer runner.Swap(fake)()
+
+	project := &config.Project{}
+	project.Context.AWSProfile = "acme-prod"
+	project.Context.AzureSubscription = "0000-1111" // by ID; active reports the name
+	project.Context.GCloudProject = "acme-data"
+	project.Context.KubeContext = "acme-aks"
+	project.Context.DatabricksProfile = "acme"
+
+	got := CheckActive(project)
+	want := []Mismatch{
+		{"AWS", "acme-prod", "globex-prod"},
+		{"Kube", "acme-aks", "globex-aks"},
+		{"Databricks", "acme", "DEFAULT"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckActive = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckActiveSkipsUnreadable(t *testing.T) {
+	fake := runner.NewFake()
+	fake.Missing("kubectl")
+	fake.On("az account show", "", errors.New("not logged in"))
+	defer runner.Swap(fake)()
+
+	project := &config.Project{}
+	project.Context.AzureSubscription = "acme"
+	project.Context.KubeContext = "acme-aks"
+
+	if got := CheckActive(project); len(got) != 0 {
+		t.Errorf("expected no mismatches when state can't be read, got %+v", got)
+	}
+}
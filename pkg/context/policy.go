@@ -0,0 +1,66 @@
+package context
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// Policy limits when opening a project may change global CLI state
+// (az account set, gcloud config set, kubectl use-context). Session env
+// vars are per-session and always set. Configured under [context] in
+// config.toml.
+type Policy struct {
+	SkipVisibility  []string // Projects with these datakai.visibility values never switch
+	ConfirmProfiles []string // Globs; switching to a matching value asks first
+}
+
+// Confirm asks the user a yes/no question, defaulting to no. Replaced in
+// tests; with no terminal to answer, Scanln fails and the answer is no.
+var Confirm = func(prompt string) bool {
+	fmt.Print(prompt)
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
+// LoadPolicy reads the switching policy from global config
+func LoadPolicy() Policy {
+	s, err := settings.Load()
+	if err != nil {
+		return Policy{}
+	}
+	return Policy{
+		SkipVisibility:  s.Context.SkipVisibility,
+		ConfirmProfiles: s.Context.ConfirmProfiles,
+	}
+}
+
+// Blocks reports why the policy keeps a project from switching global
+// state, or "" if it doesn't
+func (p Policy) Blocks(project *config.Project) string {
+	visibility := strings.ToLower(project.DataKai.Visibility)
+	if visibility != "" && slices.ContainsFunc(p.SkipVisibility, func(v string) bool {
+		return strings.EqualFold(v, visibility)
+	}) {
+		return visibility + " project"
+	}
+	return ""
+}
+
+// NeedsConfirm reports whether switching to value (a profile,
+// subscription, project or kube context) needs the user's go-ahead
+func (p Policy) NeedsConfirm(value string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range p.ConfirmProfiles {
+		if ok, _ := path.Match(strings.ToLower(pattern), value); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/fsnotify/fsnotify"
+)
+
+// RefreshInterval keeps the cache fresh well within its TTL
+const RefreshInterval = cache.CacheMaxAge / 2
+
+// Warm scans the roots and writes the project cache synchronously
+func Warm(rootDirs ...string) (int, error) {
+	projects, err := scan(rootDirs)
+	return len(projects), err
+}
+
+// scan finds projects and writes them to the cache
+func scan(rootDirs []string) ([]*config.Project, error) {
+	// Pick up edits to .project-defaults.toml since the last scan
+	config.ResetDefaultsCache()
+
+	projects, err := config.FindProjects(rootDirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.SaveToCache(projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// projectPaths indexes projects by path
+func projectPaths(projects []*config.Project) map[string]*config.Project {
+	byPath := make(map[string]*config.Project, len(projects))
+	for _, p := range projects {
+		byPath[p.Path] = p
+	}
+	return byPath
+}
+
+// emitChanges publishes projects that appeared or disappeared between scans,
+// catching changes made outside pk (git clone, rm -rf, Finder moves)
+func emitChanges(before, after map[string]*config.Project) {
+	for path, p := range after {
+		if _, existed := before[path]; !existed {
+			events.Emit(events.ProjectDetected, p.ProjectInfo.ID, path, nil)
+		}
+	}
+	for path, p := range before {
+		if _, exists := after[path]; !exists {
+			events.Emit(events.ProjectGone, p.ProjectInfo.ID, path, nil)
+		}
+	}
+}
+
+// Run warms the cache, then keeps it current until stop is closed:
+// immediately when the watcher sees projects change, and on a timer as a
+// backstop for filesystems that don't deliver events (NFS, SMB). Mirror
+// projects are fetched every MirrorFetchInterval.
+func Run(rootDirs []string, stop <-chan struct{}) error {
+	projects, err := scan(rootDirs)
+	if err != nil {
+		return err
+	}
+	log.Printf("cache warmed: %d projects", len(projects))
+	known := projectPaths(projects)
+
+	refresh := func(reason string) {
+		projects, err := scan(rootDirs)
+		if err != nil {
+			// Keep running; roots may be temporarily unavailable
+			log.Printf("cache refresh failed: %v", err)
+			return
+		}
+		log.Printf("cache refreshed (%s): %d projects", reason, len(projects))
+
+		current := projectPaths(projects)
+		emitChanges(known, current)
+		known = current
+	}
+
+	// Without a watcher the nil channels below never fire
+	var fsEvents <-chan fsnotify.Event
+	var fsErrors <-chan error
+	w, err := newWatcher(rootDirs)
+	if err != nil {
+		log.Printf("not watching project roots (%v); refreshing every %s", err, RefreshInterval)
+	} else {
+		defer w.close()
+		fsEvents, fsErrors = w.fs.Events, w.fs.Errors
+		log.Printf("watching %d directories", len(w.dirs))
+	}
+
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+
+	// Without mirror fetching the nil channel never fires
+	var mirrorTick <-chan time.Time
+	var fetching atomic.Bool
+	if MirrorFetchInterval > 0 {
+		mirrors := time.NewTicker(MirrorFetchInterval)
+		defer mirrors.Stop()
+		mirrorTick = mirrors.C
+		go fetchMirrors(mirrorDirs(known), &fetching)
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			log.Printf("daemon stopped")
+			return nil
+		case <-ticker.C:
+			refresh("timer")
+		case <-mirrorTick:
+			go fetchMirrors(mirrorDirs(known), &fetching)
+		case event := <-fsEvents:
+			if w.handle(event) && debounce == nil {
+				debounce = time.After(WatchDebounce)
+			}
+		case err := <-fsErrors:
+			log.Printf("watch error: %v", err)
+		case <-debounce:
+			debounce = nil
+			refresh("change detected")
+		}
+	}
+}
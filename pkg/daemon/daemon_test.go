@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"os"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+)
+
+func newProject(id, path string) *config.Project {
+	p := &config.Project{Path: path}
+	p.ProjectInfo.ID = id
+	return p
+}
+
+func TestEmitChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	before := projectPaths([]*config.Project{newProject("kept", "/p/kept"), newProject("removed", "/p/removed")})
+	after := projectPaths([]*config.Project{newProject("kept", "/p/kept"), newProject("added", "/p/added")})
+
+	emitChanges(before, after)
+
+	emitted, err := events.Read(0, "project")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", emitted)
+	}
+
+	types := map[string]string{}
+	for _, e := range emitted {
+		types[e.ProjectID] = e.Type
+	}
+	if types["added"] != events.ProjectDetected || types["removed"] != events.ProjectGone {
+		t.Errorf("Unexpected events: %v", types)
+	}
+}
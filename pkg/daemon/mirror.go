@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"log"
+	"sort"
+	"sync/atomic"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/git"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// MirrorFetchInterval is how often Run fetches mirror projects
+// ([project] mirror = true); zero disables fetching
+var MirrorFetchInterval = settings.DefaultMirrorFetchInterval
+
+// mirrorDirs returns the git repositories of active mirror projects, sorted
+func mirrorDirs(projects map[string]*config.Project) []string {
+	var dirs []string
+	for path, p := range projects {
+		if p.ProjectInfo.Mirror && p.ProjectInfo.Status != "archived" && git.IsRepo(path) {
+			dirs = append(dirs, path)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// fetchMirrors fetches each mirror in turn, logging failures. A fetch that
+// is still running when the next one is due is not overlapped.
+func fetchMirrors(dirs []string, running *atomic.Bool) {
+	if len(dirs) == 0 || !running.CompareAndSwap(false, true) {
+		return
+	}
+	defer running.Store(false)
+
+	failed := 0
+	for _, dir := range dirs {
+		if err := git.Fetch(dir); err != nil {
+			log.Printf("mirror fetch failed: %s: %v", dir, err)
+			failed++
+		}
+	}
+	log.Printf("mirrors fetched: %d of %d", len(dirs)-failed, len(dirs))
+}
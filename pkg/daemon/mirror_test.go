@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestMirrorDirs(t *testing.T) {
+	root := t.TempDir()
+	repo := func(id string, mirror bool, status string) *config.Project {
+		p := newProject(id, filepath.Join(root, id))
+		p.ProjectInfo.Mirror = mirror
+		p.ProjectInfo.Status = status
+		os.MkdirAll(filepath.Join(p.Path, ".git"), 0755)
+		return p
+	}
+
+	notRepo := newProject("plain", filepath.Join(root, "plain"))
+	notRepo.ProjectInfo.Mirror = true
+	projects := projectPaths([]*config.Project{
+		repo("sdk", true, "active"),
+		repo("app", false, "active"),
+		repo("old-sdk", true, "archived"),
+		repo("docs", true, "active"),
+		notRepo,
+	})
+
+	want := []string{filepath.Join(root, "docs"), filepath.Join(root, "sdk")}
+	if got := mirrorDirs(projects); !reflect.DeepEqual(got, want) {
+		t.Errorf("mirrorDirs = %v, want %v", got, want)
+	}
+}
+
+func TestFetchMirrors(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("git -C /m/b fetch", "", errors.New("could not resolve host"))
+	defer runner.Swap(fake)()
+
+	var running atomic.Bool
+	fetchMirrors([]string{"/m/a", "/m/b", "/m/c"}, &running)
+
+	want := []string{
+		"git -C /m/a fetch --quiet --all --prune",
+		"git -C /m/b fetch --quiet --all --prune",
+		"git -C /m/c fetch --quiet --all --prune",
+	}
+	if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+	if running.Load() {
+		t.Error("running should be cleared after fetching")
+	}
+
+	// A fetch already in progress isn't overlapped
+	running.Store(true)
+	fetchMirrors([]string{"/m/a"}, &running)
+	if len(fake.Commands()) != len(want) {
+		t.Errorf("fetched while a fetch was running: %v", fake.Commands())
+	}
+}
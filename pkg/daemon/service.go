@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// ServiceName is the systemd user unit name
+	ServiceName = "pk-daemon.service"
+	// LaunchdLabel is the launchd agent label
+	LaunchdLabel = "com.datakai.pk.daemon"
+)
+
+// ServiceFile returns where the login service definition lives for this OS
+func ServiceFile() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "LaunchAgents", LaunchdLabel+".plist"), nil
+	case "linux":
+		return filepath.Join(homeDir, ".config", "systemd", "user", ServiceName), nil
+	default:
+		return "", fmt.Errorf("login services are not supported on %s", runtime.GOOS)
+	}
+}
+
+// ServiceOptions adjust what the login service runs
+type ServiceOptions struct {
+	// WarmPinned runs 'pk daemon run --warm-pinned'. The tmux server it
+	// starts must survive the daemon being restarted or stopped.
+	WarmPinned bool
+}
+
+// runArgs are the arguments after the pk binary
+func (o ServiceOptions) runArgs() []string {
+	args := []string{"daemon", "run"}
+	if o.WarmPinned {
+		args = append(args, "--warm-pinned")
+	}
+	return args
+}
+
+// RenderService returns the service definition for this OS
+func RenderService(binary string, opts ServiceOptions) (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return RenderLaunchdPlist(binary, filepath.Join(homeDir, ".cache", "pk", "daemon.log"), opts), nil
+	case "linux":
+		return RenderSystemdUnit(binary, opts), nil
+	default:
+		return "", fmt.Errorf("login services are not supported on %s", runtime.GOOS)
+	}
+}
+
+// RenderSystemdUnit returns a systemd user unit running 'pk daemon run'
+func RenderSystemdUnit(binary string, opts ServiceOptions) string {
+	killMode := ""
+	if opts.WarmPinned {
+		// Only stop pk itself, not the tmux server holding the warmed sessions
+		killMode = "KillMode=process\n"
+	}
+
+	return fmt.Sprintf(`# Generated by 'pk daemon install'
+[Unit]
+Description=PK (Project Kit) daemon - keeps the project cache warm
+
+[Service]
+Type=simple
+ExecStart=%s %s
+%sRestart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=default.target
+`, binary, strings.Join(opts.runArgs(), " "), killMode)
+}
+
+// RenderLaunchdPlist returns a launchd agent running 'pk daemon run' at login
+func RenderLaunchdPlist(binary, logPath string, opts ServiceOptions) string {
+	var args strings.Builder
+	for _, arg := range opts.runArgs() {
+		fmt.Fprintf(&args, "\n\t\t<string>%s</string>", arg)
+	}
+
+	abandon := ""
+	if opts.WarmPinned {
+		// Keep the tmux server holding the warmed sessions when pk exits
+		abandon = "\n\t<key>AbandonProcessGroup</key>\n\t<true/>"
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- Generated by 'pk daemon install' -->
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>%s
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>%s
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, LaunchdLabel, binary, args.String(), abandon, logPath, logPath)
+}
+
+// EnableCommand returns the command that loads and starts the service
+func EnableCommand(serviceFile string) []string {
+	if runtime.GOOS == "darwin" {
+		return []string{"launchctl", "load", "-w", serviceFile}
+	}
+	return []string{"systemctl", "--user", "enable", "--now", ServiceName}
+}
+
+// DisableCommand returns the command that stops and unloads the service
+func DisableCommand(serviceFile string) []string {
+	if runtime.GOOS == "darwin" {
+		return []string{"launchctl", "unload", "-w", serviceFile}
+	}
+	return []string{"systemctl", "--user", "disable", "--now", ServiceName}
+}
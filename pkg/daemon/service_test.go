@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit := RenderSystemdUnit("/usr/local/bin/pk", ServiceOptions{})
+
+	if !strings.Contains(unit, "ExecStart=/usr/local/bin/pk daemon run") {
+		t.Errorf("Unit should run 'pk daemon run', got:\n%s", unit)
+	}
+
+	if !strings.Contains(unit, "WantedBy=default.target") {
+		t.Error("Unit should be wanted by default.target to start at login")
+	}
+	if strings.Contains(unit, "KillMode") {
+		t.Error("Unit without warming should use the default KillMode")
+	}
+
+	unit = RenderSystemdUnit("/usr/local/bin/pk", ServiceOptions{WarmPinned: true})
+	if !strings.Contains(unit, "ExecStart=/usr/local/bin/pk daemon run --warm-pinned\nKillMode=process\n") {
+		t.Errorf("Warming unit should keep tmux alive across restarts, got:\n%s", unit)
+	}
+}
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	plist := RenderLaunchdPlist("/opt/homebrew/bin/pk", "/tmp/pk.log", ServiceOptions{})
+
+	for _, expected := range []string{
+		"<string>" + LaunchdLabel + "</string>",
+		"<string>/opt/homebrew/bin/pk</string>\n\t\t<string>daemon</string>\n\t\t<string>run</string>\n\t</array>",
+		"<key>RunAtLoad</key>",
+		"<string>/tmp/pk.log</string>",
+	} {
+		if !strings.Contains(plist, expected) {
+			t.Errorf("Plist missing %q", expected)
+		}
+	}
+
+	plist = RenderLaunchdPlist("/opt/homebrew/bin/pk", "/tmp/pk.log", ServiceOptions{WarmPinned: true})
+	for _, expected := range []string{"<string>--warm-pinned</string>", "<key>AbandonProcessGroup</key>"} {
+		if !strings.Contains(plist, expected) {
+			t.Errorf("Warming plist missing %q", expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDebounce groups bursts of filesystem events (git clone, rm -rf,
+// an editor's write-and-rename) into one rescan
+const WatchDebounce = 250 * time.Millisecond
+
+// skipWatch reports whether a directory is never worth watching for
+// projects: anything discovery ignores (see config.Ignored), and hidden
+// directories, which the periodic refresh still covers
+func skipWatch(name string) bool {
+	return strings.HasPrefix(name, ".") || config.Ignored(name)
+}
+
+// watcher reports changes under the project roots that can add, change,
+// or remove a project
+type watcher struct {
+	fs   *fsnotify.Watcher
+	dirs map[string]bool
+}
+
+// newWatcher watches every directory under the roots that exist, and the
+// sidecar metadata directory
+func newWatcher(rootDirs []string) (*watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watcher{fs: fsw, dirs: make(map[string]bool)}
+	for _, root := range rootDirs {
+		if err := w.addTree(root); err != nil {
+			fsw.Close()
+			return nil, err
+		}
+	}
+	if dir, err := config.SidecarDir(); err == nil && w.fs.Add(dir) == nil {
+		w.dirs[dir] = true
+	}
+	return w, nil
+}
+
+// addTree watches dir and its subdirectories. A missing dir is not an error.
+func (w *watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return nil
+			}
+			// Unreadable subdirectory: skip it, keep watching the rest
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && skipWatch(d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return err
+		}
+		w.dirs[path] = true
+		return nil
+	})
+}
+
+// handle watches new directories and reports whether the event calls for
+// a rescan: any change to a .project.toml or sidecar metadata file, or a
+// directory appearing or disappearing (which can carry projects with it)
+func (w *watcher) handle(event fsnotify.Event) bool {
+	name := filepath.Base(event.Name)
+	if name == ".project.toml" || config.IsSidecar(event.Name) {
+		return true
+	}
+	if skipWatch(name) {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.addTree(event.Name)
+			return true
+		}
+		return false
+	}
+
+	// A watched directory removed or moved away; fsnotify drops its
+	// watches on its own. Other files coming and going don't matter.
+	if (event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)) && w.dirs[event.Name] {
+		prefix := event.Name + string(filepath.Separator)
+		for dir := range w.dirs {
+			if dir == event.Name || strings.HasPrefix(dir, prefix) {
+				delete(w.dirs, dir)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (w *watcher) close() error {
+	return w.fs.Close()
+}
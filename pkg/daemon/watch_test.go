@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatcherHandle(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "dojo", "node_modules"), 0755)
+
+	w, err := newWatcher([]string{root, filepath.Join(root, "missing")})
+	if err != nil {
+		t.Fatalf("newWatcher: %v", err)
+	}
+	defer w.close()
+
+	if !w.dirs[filepath.Join(root, "dojo")] || w.dirs[filepath.Join(root, "dojo", "node_modules")] {
+		t.Errorf("unexpected watched dirs: %v", w.dirs)
+	}
+
+	newDir := filepath.Join(root, "fresh")
+	os.Mkdir(newDir, 0755)
+
+	tests := []struct {
+		name  string
+		event fsnotify.Event
+		want  bool
+	}{
+		{"project file written", fsnotify.Event{Name: filepath.Join(root, "dojo", ".project.toml"), Op: fsnotify.Write}, true},
+		{"project file removed", fsnotify.Event{Name: filepath.Join(root, "dojo", ".project.toml"), Op: fsnotify.Remove}, true},
+		{"other file written", fsnotify.Event{Name: filepath.Join(root, "dojo", "main.go"), Op: fsnotify.Write}, false},
+		{"other file removed", fsnotify.Event{Name: filepath.Join(root, "dojo", "main.go"), Op: fsnotify.Remove}, false},
+		{"directory created", fsnotify.Event{Name: newDir, Op: fsnotify.Create}, true},
+		{"watched directory removed", fsnotify.Event{Name: filepath.Join(root, "dojo"), Op: fsnotify.Remove}, true},
+		{"hidden directory", fsnotify.Event{Name: filepath.Join(root, ".git"), Op: fsnotify.Create}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.handle(tt.event); got != tt.want {
+				t.Errorf("handle(%v) = %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+
+	if !w.dirs[newDir] {
+		t.Error("new directory was not watched")
+	}
+	if w.dirs[filepath.Join(root, "dojo")] {
+		t.Error("removed directory still tracked")
+	}
+}
+
+func TestRunPicksUpNewProject(t *testing.T) {
+	home := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	root := filepath.Join(home, "projects")
+	os.MkdirAll(root, 0755)
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- Run([]string{root}, stop) }()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	// Wait for the initial scan, then add a project in a new directory
+	waitFor(t, func() bool { return cache.IsCacheValid() })
+	dir := filepath.Join(root, "clients", "dojo")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, ".project.toml"), []byte("[project]\nid = \"dojo\"\nname = \"Dojo\"\n"), 0644)
+
+	waitFor(t, func() bool {
+		projects, err := cache.LoadFromCache()
+		return err == nil && len(projects) == 1 && projects[0].ProjectInfo.ID == "dojo"
+	})
+
+	os.RemoveAll(filepath.Join(root, "clients"))
+	waitFor(t, func() bool {
+		projects, err := cache.LoadFromCache()
+		return err == nil && len(projects) == 0
+	})
+}
+
+// waitFor polls cond well within RefreshInterval, so only the watcher can satisfy it
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the cache to update")
+}
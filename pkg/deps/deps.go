@@ -0,0 +1,133 @@
+package deps
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Tool is an external program some pk features depend on
+type Tool struct {
+	Name    string // Binary looked up on PATH
+	Purpose string // What pk uses it for
+	Brew    string // Homebrew formula or cask (macOS)
+	Apt     string // apt package (Debian/Ubuntu)
+	URL     string // Install docs when no package applies
+}
+
+// catalog lists every external tool pk knows about
+var catalog = map[string]Tool{
+	"tmux":    {Name: "tmux", Purpose: "sessions and keybindings", Brew: "tmux", Apt: "tmux"},
+	"zellij":  {Name: "zellij", Purpose: "sessions with multiplexer = \"zellij\"", Brew: "zellij", URL: "https://zellij.dev/documentation/installation"},
+	"fzf":     {Name: "fzf", Purpose: "pickers with previews", Brew: "fzf", Apt: "fzf"},
+	"sk":      {Name: "sk", Purpose: "pickers with [picker] backend = \"sk\"", Brew: "sk", Apt: "skim"},
+	"git":     {Name: "git", Purpose: "clone, worktrees, repository links", Brew: "git", Apt: "git"},
+	"gh":      {Name: "gh", Purpose: "GitHub integration", Brew: "gh", Apt: "gh", URL: "https://cli.github.com"},
+	"aws":     {Name: "aws", Purpose: "AWS context", Brew: "awscli", Apt: "awscli"},
+	"az":      {Name: "az", Purpose: "Azure context", Brew: "azure-cli", URL: "https://learn.microsoft.com/cli/azure/install-azure-cli"},
+	"gcloud":  {Name: "gcloud", Purpose: "GCloud context", Brew: "--cask google-cloud-sdk", URL: "https://cloud.google.com/sdk/docs/install"},
+	"kubectl": {Name: "kubectl", Purpose: "Kubernetes context", Brew: "kubectl", URL: "https://kubernetes.io/docs/tasks/tools/"},
+	"code":    {Name: "code", Purpose: "pk code", Brew: "--cask visual-studio-code", URL: "https://code.visualstudio.com/docs/setup/linux"},
+	"nvim":    {Name: "nvim", Purpose: "pk nvim", Brew: "neovim", Apt: "neovim"},
+	"vim":     {Name: "vim", Purpose: "fallback editor for pk edit", Brew: "vim", Apt: "vim"},
+	"zstd":    {Name: "zstd", Purpose: "pk archive --compress", Brew: "zstd", Apt: "zstd"},
+}
+
+// MissingError reports a tool a feature needs but PATH doesn't have
+type MissingError struct {
+	Feature string
+	Tool    Tool
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%s requires %s (%s)", e.Feature, e.Tool.Name, e.Tool.InstallHint())
+}
+
+// Lookup returns the catalog entry for a tool, or a bare entry if unknown
+func Lookup(name string) Tool {
+	if tool, exists := catalog[name]; exists {
+		return tool
+	}
+	return Tool{Name: name}
+}
+
+// Tools returns all known tools sorted by name
+func Tools() []Tool {
+	tools := make([]Tool, 0, len(catalog))
+	for _, tool := range catalog {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// Available reports whether a tool is on PATH
+func Available(name string) bool {
+	_, err := runner.LookPath(name)
+	return err == nil
+}
+
+// Require returns a *MissingError for the first tool not on PATH
+func Require(feature string, names ...string) error {
+	for _, name := range names {
+		if !Available(name) {
+			return &MissingError{Feature: feature, Tool: Lookup(name)}
+		}
+	}
+	return nil
+}
+
+// versionFlags is how each tool is asked for its version, when not --version
+var versionFlags = map[string]string{"tmux": "-V"}
+
+// Version asks a tool on PATH for its version, e.g. "3.4" for tmux
+func Version(name string) (string, error) {
+	flag := versionFlags[name]
+	if flag == "" {
+		flag = "--version"
+	}
+	output, err := runner.Output(runner.Command(name, flag))
+	if err != nil {
+		return "", err
+	}
+	return parseVersion(string(output)), nil
+}
+
+// parseVersion picks the version out of a tool's version banner: the first
+// word of its first line that starts with a digit ("git version 2.43.0",
+// "tmux 3.4", "0.44.1 (brew)"), else the whole line
+func parseVersion(output string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	for _, field := range strings.Fields(line) {
+		if field[0] >= '0' && field[0] <= '9' {
+			return field
+		}
+		if v, ok := strings.CutPrefix(field, "v"); ok && v != "" && v[0] >= '0' && v[0] <= '9' {
+			return v
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// InstallHint returns an install suggestion for the current OS
+func (t Tool) InstallHint() string {
+	return t.installHintFor(runtime.GOOS)
+}
+
+func (t Tool) installHintFor(goos string) string {
+	switch {
+	case goos == "darwin" && t.Brew != "":
+		return "install: brew install " + t.Brew
+	case goos == "linux" && t.Apt != "":
+		return "install: apt install " + t.Apt
+	case t.URL != "":
+		return "see " + t.URL
+	case t.Brew != "":
+		return "install: brew install " + t.Brew
+	default:
+		return "install " + t.Name + " and make sure it is on PATH"
+	}
+}
@@ -0,0 +1,63 @@
+package deps
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInstallHintFor(t *testing.T) {
+	tmux := Lookup("tmux")
+	if got := tmux.installHintFor("darwin"); got != "install: brew install tmux" {
+		t.Errorf("darwin hint = %q", got)
+	}
+	if got := tmux.installHintFor("linux"); got != "install: apt install tmux" {
+		t.Errorf("linux hint = %q", got)
+	}
+
+	// No apt package: fall back to docs URL on Linux
+	az := Lookup("az")
+	if got := az.installHintFor("linux"); !strings.HasPrefix(got, "see https://") {
+		t.Errorf("az linux hint = %q", got)
+	}
+
+	unknown := Lookup("frobnicate")
+	if got := unknown.installHintFor("linux"); !strings.Contains(got, "frobnicate") {
+		t.Errorf("unknown hint = %q", got)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	err := Require("pk test", "definitely-not-installed-pk-tool")
+	var missing *MissingError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected *MissingError, got %v", err)
+	}
+	if missing.Tool.Name != "definitely-not-installed-pk-tool" || missing.Feature != "pk test" {
+		t.Errorf("Unexpected error contents: %+v", missing)
+	}
+	if !strings.HasPrefix(err.Error(), "pk test requires definitely-not-installed-pk-tool") {
+		t.Errorf("Unexpected message: %s", err)
+	}
+
+	if err := Require("nothing"); err != nil {
+		t.Errorf("Expected nil for no requirements, got %v", err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]string{
+		"git version 2.43.0\n":        "2.43.0",
+		"tmux 3.4\n":                  "3.4",
+		"tmux next-3.5\n":             "tmux next-3.5",
+		"0.44.1 (brew)\n":             "0.44.1",
+		"zellij v0.40.1\nmore text\n": "0.40.1",
+		"sk 0.10.4\n":                 "0.10.4",
+		"":                            "",
+	}
+	for output, want := range tests {
+		if got := parseVersion(output); got != want {
+			t.Errorf("parseVersion(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
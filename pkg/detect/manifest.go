@@ -0,0 +1,123 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// Manifest is the name and description a language manifest declares
+type Manifest struct {
+	File        string // e.g. "package.json"
+	Name        string
+	Description string
+}
+
+// majorVersion is the /vN suffix of a Go module path
+var majorVersion = regexp.MustCompile(`/v\d+$`)
+
+// ReadManifest reads the first of package.json, Cargo.toml, pyproject.toml
+// and go.mod in dir that names the project. go.mod has no description;
+// its name is the last element of the module path.
+func ReadManifest(dir string) (Manifest, bool) {
+	readers := []struct {
+		file string
+		read func(data []byte) (name, description string)
+	}{
+		{"package.json", readPackageJSON},
+		{"Cargo.toml", readCargoToml},
+		{"pyproject.toml", readPyproject},
+		{"go.mod", readGoMod},
+	}
+
+	for _, r := range readers {
+		data, err := os.ReadFile(filepath.Join(dir, r.file))
+		if err != nil {
+			continue
+		}
+		if name, description := r.read(data); name != "" || description != "" {
+			return Manifest{File: r.file, Name: name, Description: strings.TrimSpace(description)}, true
+		}
+	}
+	return Manifest{}, false
+}
+
+// Provisional derives a project entry for a directory without a
+// .project.toml, as promote would create it: the directory name as ID, the
+// manifest's name and description, and detected stack, domain, repository
+// and kind. Every derived field is marked as detected.
+func Provisional(dir string) *config.Project {
+	p := &config.Project{Path: dir}
+	p.ProjectInfo.ID = filepath.Base(dir)
+	p.ProjectInfo.Name = p.ProjectInfo.ID
+	if m, ok := ReadManifest(dir); ok && m.Name != "" {
+		p.ProjectInfo.Name = m.Name
+		p.MarkDetected("project.name", config.ConfidenceHigh, m.File)
+	}
+	Apply(p, dir)
+	return p
+}
+
+func readPackageJSON(data []byte) (string, string) {
+	var pkg struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return "", ""
+	}
+	// @scope/name -> name
+	return pkg.Name[strings.LastIndex(pkg.Name, "/")+1:], pkg.Description
+}
+
+func readCargoToml(data []byte) (string, string) {
+	var cargo struct {
+		Package struct {
+			Name        string `toml:"name"`
+			Description string `toml:"description"`
+		} `toml:"package"`
+	}
+	if _, err := toml.Decode(string(data), &cargo); err != nil {
+		return "", ""
+	}
+	return cargo.Package.Name, cargo.Package.Description
+}
+
+func readPyproject(data []byte) (string, string) {
+	var py struct {
+		Project struct {
+			Name        string `toml:"name"`
+			Description string `toml:"description"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Name        string `toml:"name"`
+				Description string `toml:"description"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if _, err := toml.Decode(string(data), &py); err != nil {
+		return "", ""
+	}
+	if py.Project.Name != "" {
+		return py.Project.Name, py.Project.Description
+	}
+	return py.Tool.Poetry.Name, py.Tool.Poetry.Description
+}
+
+func readGoMod(data []byte) (string, string) {
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			module = majorVersion.ReplaceAllString(strings.Trim(strings.TrimSpace(module), `"`), "")
+			return module[strings.LastIndex(module, "/")+1:], ""
+		}
+	}
+	return "", ""
+}
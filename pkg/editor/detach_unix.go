@@ -0,0 +1,14 @@
+//go:build unix
+
+package editor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs cmd in its own session, so it outlives the terminal that
+// started it
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
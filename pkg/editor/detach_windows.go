@@ -0,0 +1,14 @@
+//go:build windows
+
+package editor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs cmd in its own process group, so closing the console that
+// started it doesn't stop it
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
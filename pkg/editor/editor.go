@@ -0,0 +1,123 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// CheckVSCode verifies the VS Code CLI is installed
+func CheckVSCode() error {
+	return deps.Require("'pk code'", "code")
+}
+
+// CheckNvim verifies Neovim is installed
+func CheckNvim() error {
+	return deps.Require("'pk nvim'", "nvim")
+}
+
+// VSCodeProfile returns the VS Code profile for a project: the project's own
+// [editor] vscode_profile, else the profile mapped to its client in global config
+func VSCodeProfile(project *config.Project, s *settings.Settings) string {
+	if project.Editor.VSCodeProfile != "" {
+		return project.Editor.VSCodeProfile
+	}
+
+	client := project.GetClientName()
+	if client == "" || s == nil {
+		return ""
+	}
+
+	for name, profile := range s.Editor.VSCodeProfiles {
+		if strings.EqualFold(name, client) {
+			return profile
+		}
+	}
+
+	return ""
+}
+
+// OpenVSCode opens a project directory in VS Code, optionally with a profile
+// and an isolated client profile
+func OpenVSCode(path, profile string, isolated *IsolatedProfile) error {
+	var args []string
+	if isolated != nil {
+		args = append(args, isolated.Args()...)
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	args = append(args, path)
+
+	cmd := runner.Command("code", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return runner.Run(cmd)
+}
+
+// NvimSocket returns the server socket path for a project's Neovim instance
+func NvimSocket(projectID string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	socketDir := filepath.Join(homeDir, ".cache", "pk", "nvim")
+	if err := os.MkdirAll(socketDir, 0700); err != nil {
+		return "", err
+	}
+
+	// Same sanitization as tmux session names
+	name := strings.ReplaceAll(projectID, ".", "_")
+	return filepath.Join(socketDir, name+".sock"), nil
+}
+
+// NvimServerRunning checks if a Neovim server is listening on the socket
+func NvimServerRunning(socket string) bool {
+	if _, err := os.Stat(socket); err != nil {
+		return false
+	}
+
+	cmd := runner.Command("nvim", "--server", socket, "--remote-expr", "1")
+	return runner.Run(cmd) == nil
+}
+
+// StartNvimServer starts a headless Neovim server rooted at the project path.
+// The server runs in its own session so it outlives the terminal that started it.
+func StartNvimServer(path, socket string) error {
+	// A stale socket from a crashed server blocks --listen
+	os.Remove(socket)
+
+	cmd := runner.Command("nvim", "--headless", "--listen", socket)
+	cmd.Dir = path
+	detach(cmd)
+
+	return runner.Start(cmd)
+}
+
+// WaitForNvimServer polls until the server responds or the timeout passes
+func WaitForNvimServer(socket string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if NvimServerRunning(socket) {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+// AttachNvim attaches a UI to the Neovim server on the socket
+func AttachNvim(socket string) error {
+	cmd := runner.Command("nvim", "--server", socket, "--remote-ui")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return runner.Run(cmd)
+}
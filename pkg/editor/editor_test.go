@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+func TestVSCodeProfile(t *testing.T) {
+	s := &settings.Settings{}
+	s.Editor.VSCodeProfiles = map[string]string{"Acme Corp": "acme"}
+
+	client := &config.Project{}
+	client.Consultant.ClientName = "acme corp"
+	if got := VSCodeProfile(client, s); got != "acme" {
+		t.Errorf("Expected client profile 'acme', got %q", got)
+	}
+
+	// Project-level profile wins over the client mapping
+	client.Editor.VSCodeProfile = "acme-data"
+	if got := VSCodeProfile(client, s); got != "acme-data" {
+		t.Errorf("Expected project profile 'acme-data', got %q", got)
+	}
+
+	internal := &config.Project{}
+	if got := VSCodeProfile(internal, s); got != "" {
+		t.Errorf("Expected no profile for project without client, got %q", got)
+	}
+}
+
+func TestManagedSettings(t *testing.T) {
+	s := &settings.Settings{}
+	s.Editor.ClientProfile.Settings = map[string]interface{}{
+		"editor.formatOnSave":      true,
+		"telemetry.telemetryLevel": "all", // Never allowed to re-enable telemetry
+	}
+	s.Editor.Clients = map[string]settings.ClientProfile{
+		"Acme Corp": {Settings: map[string]interface{}{"editor.formatOnSave": false}},
+	}
+
+	managed := ManagedSettings("acme corp", s)
+	if managed["editor.formatOnSave"] != false {
+		t.Errorf("Expected per-client setting to override default, got %v", managed["editor.formatOnSave"])
+	}
+	if managed["telemetry.telemetryLevel"] != "off" {
+		t.Errorf("Expected telemetry off, got %v", managed["telemetry.telemetryLevel"])
+	}
+}
+
+func TestEnsureClientProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	s := &settings.Settings{}
+	s.Editor.ClientProfile.Extensions = []string{"ms-python.python"}
+	s.Editor.Clients = map[string]settings.ClientProfile{
+		"Acme Corp": {Extensions: []string{"MS-Python.python", "dbt-labs.dbt"}},
+	}
+
+	profile, err := EnsureClientProfile("Acme Corp", s)
+	if err != nil {
+		t.Fatalf("EnsureClientProfile failed: %v", err)
+	}
+
+	if len(profile.Extensions) != 2 {
+		t.Errorf("Expected 2 deduplicated extensions, got %v", profile.Extensions)
+	}
+	if !strings.Contains(profile.UserDataDir, filepath.Join("vscode", "acme-corp")) {
+		t.Errorf("Unexpected profile dir: %s", profile.UserDataDir)
+	}
+
+	// User edits survive, managed keys are enforced
+	settingsPath := filepath.Join(profile.UserDataDir, "User", "settings.json")
+	os.WriteFile(settingsPath, []byte(`{"editor.fontSize": 14, "telemetry.telemetryLevel": "all"}`), 0600)
+
+	if _, err := EnsureClientProfile("Acme Corp", s); err != nil {
+		t.Fatalf("EnsureClientProfile (update) failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(settingsPath)
+	var current map[string]interface{}
+	if err := json.Unmarshal(data, &current); err != nil {
+		t.Fatalf("settings.json is not valid JSON: %v", err)
+	}
+	if current["editor.fontSize"] != float64(14) {
+		t.Errorf("User setting lost: %v", current)
+	}
+	if current["telemetry.telemetryLevel"] != "off" {
+		t.Errorf("Telemetry not enforced: %v", current)
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	tests := map[string]string{
+		"https://github.com/acme/etl":       "https://github.com/acme/etl",
+		"git@github.com:acme/etl.git":       "https://github.com/acme/etl",
+		"ssh://git@gitlab.acme.io/data/etl": "https://gitlab.acme.io/data/etl",
+		"docs/index.html":                   "/work/etl/docs/index.html",
+		"/srv/docs/etl":                     "/srv/docs/etl",
+		"~/notes/etl.md":                    "/home/dev/notes/etl.md",
+	}
+	for link, want := range tests {
+		if got := WebURL(link, "/work/etl"); got != want {
+			t.Errorf("WebURL(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+func TestOpenInBrowser(t *testing.T) {
+	t.Setenv("BROWSER", "firefox --new-tab")
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	if err := OpenInBrowser("https://github.com/acme/etl"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "firefox --new-tab https://github.com/acme/etl"; fake.Commands()[0] != want {
+		t.Errorf("command = %q, want %q", fake.Commands()[0], want)
+	}
+}
+
+func TestMissingExtensions(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("code --user-data-dir /p/data --extensions-dir /p/ext --list-extensions", "ms-python.python\nRedHat.vscode-yaml\n", nil)
+	defer runner.Swap(fake)()
+
+	p := &IsolatedProfile{
+		UserDataDir:   "/p/data",
+		ExtensionsDir: "/p/ext",
+		Extensions:    []string{"ms-python.python", "redhat.vscode-yaml", "databricks.databricks"},
+	}
+	missing, err := p.MissingExtensions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "databricks.databricks" {
+		t.Errorf("MissingExtensions() = %v, want [databricks.databricks]", missing)
+	}
+}
+
+func TestStartNvimServer(t *testing.T) {
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	socket := filepath.Join(t.TempDir(), "acme.sock")
+	if err := StartNvimServer("/work/acme", socket); err != nil {
+		t.Fatal(err)
+	}
+	call := fake.Calls()[0]
+	if want := "nvim --headless --listen " + socket; call.String() != want || call.Dir != "/work/acme" {
+		t.Errorf("started %q in %q, want %q in /work/acme", call.String(), call.Dir, want)
+	}
+}
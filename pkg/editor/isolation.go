@@ -0,0 +1,197 @@
+package editor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// telemetrySettings are always enforced in isolated client profiles
+var telemetrySettings = map[string]interface{}{
+	"telemetry.telemetryLevel":                       "off",
+	"workbench.enableExperiments":                    false,
+	"workbench.settings.enableNaturalLanguageSearch": false,
+	"redhat.telemetry.enabled":                       false,
+}
+
+// IsolatedProfile is a per-client VS Code user data and extensions directory
+type IsolatedProfile struct {
+	Client        string
+	UserDataDir   string
+	ExtensionsDir string
+	Extensions    []string
+}
+
+// NeedsIsolation reports whether a project should open in an isolated client profile
+func NeedsIsolation(project *config.Project) bool {
+	return project.DataKai.Visibility == "client-confidential" && project.GetClientName() != ""
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ClientSlug converts a client name to a directory-safe name
+func ClientSlug(client string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(client), "-"), "-")
+}
+
+// ClientProfileDir returns the isolated VS Code directory for a client
+func ClientProfileDir(client string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".local", "share", "pk", "vscode", ClientSlug(client)), nil
+}
+
+// clientOverrides returns the per-client profile, matching names case-insensitively
+func clientOverrides(client string, s *settings.Settings) settings.ClientProfile {
+	for name, profile := range s.Editor.Clients {
+		if strings.EqualFold(name, client) {
+			return profile
+		}
+	}
+	return settings.ClientProfile{}
+}
+
+// ManagedSettings returns the settings pk enforces for a client profile:
+// global client_profile settings, then per-client settings, then telemetry off
+func ManagedSettings(client string, s *settings.Settings) map[string]interface{} {
+	managed := make(map[string]interface{})
+
+	if s != nil {
+		for k, v := range s.Editor.ClientProfile.Settings {
+			managed[k] = v
+		}
+		for k, v := range clientOverrides(client, s).Settings {
+			managed[k] = v
+		}
+	}
+
+	for k, v := range telemetrySettings {
+		managed[k] = v
+	}
+
+	return managed
+}
+
+// ManagedExtensions returns the extensions every profile for this client should have
+func ManagedExtensions(client string, s *settings.Settings) []string {
+	if s == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var extensions []string
+	all := append(append([]string{}, s.Editor.ClientProfile.Extensions...), clientOverrides(client, s).Extensions...)
+	for _, ext := range all {
+		key := strings.ToLower(ext)
+		if !seen[key] {
+			seen[key] = true
+			extensions = append(extensions, ext)
+		}
+	}
+	return extensions
+}
+
+// EnsureClientProfile creates or updates a client's isolated profile.
+// Managed settings are merged into settings.json, keeping any other user edits.
+func EnsureClientProfile(client string, s *settings.Settings) (*IsolatedProfile, error) {
+	dir, err := ClientProfileDir(client)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &IsolatedProfile{
+		Client:        client,
+		UserDataDir:   filepath.Join(dir, "user-data"),
+		ExtensionsDir: filepath.Join(dir, "extensions"),
+		Extensions:    ManagedExtensions(client, s),
+	}
+
+	userDir := filepath.Join(profile.UserDataDir, "User")
+	if err := os.MkdirAll(userDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(profile.ExtensionsDir, 0700); err != nil {
+		return nil, err
+	}
+
+	settingsPath := filepath.Join(userDir, "settings.json")
+	if err := mergeSettingsFile(settingsPath, ManagedSettings(client, s)); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", settingsPath, err)
+	}
+
+	return profile, nil
+}
+
+// mergeSettingsFile writes managed keys into a VS Code settings.json
+func mergeSettingsFile(path string, managed map[string]interface{}) error {
+	current := make(map[string]interface{})
+
+	if data, err := os.ReadFile(path); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &current); err != nil {
+			// settings.json allows comments; don't clobber a file we can't parse
+			return fmt.Errorf("cannot parse existing settings (comments?): %w", err)
+		}
+	}
+
+	for k, v := range managed {
+		current[k] = v
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// MissingExtensions returns managed extensions not yet installed in the profile
+func (p *IsolatedProfile) MissingExtensions() ([]string, error) {
+	if len(p.Extensions) == 0 {
+		return nil, nil
+	}
+
+	cmd := runner.Command("code", "--user-data-dir", p.UserDataDir,
+		"--extensions-dir", p.ExtensionsDir, "--list-extensions")
+	output, err := runner.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			installed[strings.ToLower(line)] = true
+		}
+	}
+
+	var missing []string
+	for _, ext := range p.Extensions {
+		if !installed[strings.ToLower(ext)] {
+			missing = append(missing, ext)
+		}
+	}
+	return missing, nil
+}
+
+// InstallExtension installs an extension into the isolated profile
+func (p *IsolatedProfile) InstallExtension(ext string) error {
+	cmd := runner.Command("code", "--user-data-dir", p.UserDataDir,
+		"--extensions-dir", p.ExtensionsDir, "--install-extension", ext)
+	return runner.Run(cmd)
+}
+
+// Args returns the VS Code CLI arguments that select this profile
+func (p *IsolatedProfile) Args() []string {
+	return []string{"--user-data-dir", p.UserDataDir, "--extensions-dir", p.ExtensionsDir}
+}
@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Terminal returns the editor to open files in: $EDITOR, else vim, else nano
+func Terminal() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	if deps.Available("vim") {
+		return "vim"
+	}
+	return "nano"
+}
+
+// Browser returns the command that opens URLs and files: $BROWSER, else
+// open on macOS and xdg-open elsewhere
+func Browser() string {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		return browser
+	}
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+	return "xdg-open"
+}
+
+// OpenInBrowser opens a URL or file with Browser
+func OpenInBrowser(target string) error {
+	fields := strings.Fields(Browser())
+	return runner.Run(runner.Command(fields[0], append(fields[1:], target)...))
+}
+
+// WebURL returns what a browser should open for a project link: URLs as
+// they are, ssh and scp-like (git@host:path) repository remotes as https
+// pages, and anything else as a path, relative to dir unless absolute
+func WebURL(link, dir string) string {
+	link = strings.TrimSpace(link)
+	if u, err := url.Parse(link); err == nil && u.Host != "" {
+		if u.Scheme == "http" || u.Scheme == "https" {
+			return link
+		}
+		return "https://" + u.Hostname() + "/" + strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	}
+	if at, rest, ok := strings.Cut(link, "@"); ok && !strings.Contains(at, "/") {
+		if host, path, ok := strings.Cut(rest, ":"); ok {
+			return "https://" + host + "/" + strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+		}
+	}
+	if strings.HasPrefix(link, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, link[2:])
+		}
+	}
+	if filepath.IsAbs(link) {
+		return link
+	}
+	return filepath.Join(dir, link)
+}
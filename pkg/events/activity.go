@@ -0,0 +1,153 @@
+package events
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is a stretch of time a tmux client spent attached to a
+// project's session
+type Interval struct {
+	ProjectID string
+	Path      string
+	Session   string
+	Start     time.Time
+	End       time.Time
+}
+
+// Duration is the length of the interval
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Intervals pairs each session.attached event with the next attach or
+// detach from the same tmux client. Intervals still open at the end of the
+// log end at until; all are clipped to [since, until]. Sessions that aren't
+// pk projects are skipped.
+func Intervals(log []Event, since, until time.Time) []Interval {
+	open := make(map[string]*Interval) // By client
+	var result []Interval
+
+	closeInterval := func(client string, at time.Time) {
+		if i := open[client]; i != nil {
+			i.End = at
+			result = append(result, *i)
+			delete(open, client)
+		}
+	}
+
+	for _, e := range log {
+		if e.Type != SessionAttached && e.Type != SessionDetached {
+			continue
+		}
+		client := e.Data["client"]
+
+		// Re-attaching to the same session continues the interval
+		if i := open[client]; i != nil && e.Type == SessionAttached && i.Session == e.Data["session"] {
+			continue
+		}
+		closeInterval(client, e.Time)
+
+		if e.Type == SessionAttached && e.ProjectID != "" {
+			open[client] = &Interval{
+				ProjectID: e.ProjectID,
+				Path:      e.Path,
+				Session:   e.Data["session"],
+				Start:     e.Time,
+			}
+		}
+	}
+	for client := range open {
+		closeInterval(client, until)
+	}
+
+	// Clip to the window, dropping what falls outside it
+	var clipped []Interval
+	for _, i := range result {
+		if i.Start.Before(since) {
+			i.Start = since
+		}
+		if i.End.After(until) {
+			i.End = until
+		}
+		if i.End.After(i.Start) {
+			clipped = append(clipped, i)
+		}
+	}
+	sort.SliceStable(clipped, func(a, b int) bool { return clipped[a].Start.Before(clipped[b].Start) })
+	return clipped
+}
+
+// AWBucketType is the ActivityWatch bucket type for exported sessions
+const AWBucketType = "app.pk.session"
+
+// awEvent is one event in an ActivityWatch bucket
+type awEvent struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Duration  float64           `json:"duration"` // Seconds
+	Data      map[string]string `json:"data"`
+}
+
+type awBucket struct {
+	ID       string    `json:"id"`
+	Created  time.Time `json:"created"`
+	Type     string    `json:"type"`
+	Client   string    `json:"client"`
+	Hostname string    `json:"hostname"`
+	Events   []awEvent `json:"events"`
+}
+
+// ActivityWatch renders intervals as an aw-server bucket export, the format
+// its import accepts. Events carry app and title so category rules match.
+func ActivityWatch(intervals []Interval, hostname string, title func(Interval) string) interface{} {
+	id := "aw-watcher-pk_" + hostname
+	bucket := awBucket{
+		ID:       id,
+		Created:  time.Now().UTC(),
+		Type:     AWBucketType,
+		Client:   "pk",
+		Hostname: hostname,
+		Events:   []awEvent{},
+	}
+	for _, i := range intervals {
+		bucket.Events = append(bucket.Events, awEvent{
+			Timestamp: i.Start.UTC(),
+			Duration:  i.Duration().Seconds(),
+			Data: map[string]string{
+				"app":     "pk",
+				"title":   title(i),
+				"project": i.ProjectID,
+				"session": i.Session,
+				"path":    i.Path,
+			},
+		})
+	}
+	return map[string]interface{}{"buckets": map[string]awBucket{id: bucket}}
+}
+
+// timingEntry mirrors the fields of Timing's JSON export and time entry API
+type timingEntry struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Duration  float64   `json:"duration"` // Seconds
+	Project   string    `json:"project"`
+	Title     string    `json:"title"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+// Timing renders intervals as Timing time entries. project names the
+// Timing project, e.g. "Acme Corp ▸ etl".
+func Timing(intervals []Interval, project func(Interval) string) interface{} {
+	entries := []timingEntry{}
+	for _, i := range intervals {
+		entries = append(entries, timingEntry{
+			StartDate: i.Start.UTC(),
+			EndDate:   i.End.UTC(),
+			Duration:  i.Duration().Seconds(),
+			Project:   project(i),
+			Title:     i.Session,
+			Notes:     i.Path,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntervals(t *testing.T) {
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	attach := func(minutes int, client, session, project string) Event {
+		return Event{Time: at(minutes), Type: SessionAttached, ProjectID: project,
+			Data: map[string]string{"client": client, "session": session}}
+	}
+	detach := func(minutes int, client string) Event {
+		return Event{Time: at(minutes), Type: SessionDetached, Data: map[string]string{"client": client}}
+	}
+
+	log := []Event{
+		attach(-30, "/dev/pts/1", "etl", "etl"), // began before the window
+		attach(10, "/dev/pts/1", "api", "api"),  // switch closes etl
+		attach(15, "/dev/pts/1", "api", "api"),  // same session: continues
+		{Time: at(20), Type: SessionOpened, ProjectID: "docs"},
+		attach(25, "/dev/pts/2", "scratch", ""), // not a project
+		detach(40, "/dev/pts/1"),
+		attach(50, "/dev/pts/2", "etl", "etl"), // still attached at the end
+	}
+
+	got := Intervals(log, base, at(60))
+
+	want := []struct {
+		project    string
+		start, end int
+	}{
+		{"etl", 0, 10},
+		{"api", 10, 40},
+		{"etl", 50, 60},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d intervals, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].ProjectID != w.project || !got[i].Start.Equal(at(w.start)) || !got[i].End.Equal(at(w.end)) {
+			t.Errorf("interval %d = %s %v-%v, want %s %d-%d min",
+				i, got[i].ProjectID, got[i].Start.Sub(base), got[i].End.Sub(base), w.project, w.start, w.end)
+		}
+	}
+}
+
+func TestActivityWatchExport(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	intervals := []Interval{{ProjectID: "etl", Session: "etl", Path: "/p/etl", Start: start, End: start.Add(90 * time.Second)}}
+
+	data, _ := json.Marshal(ActivityWatch(intervals, "laptop", func(i Interval) string { return "Acme ETL" }))
+	for _, want := range []string{
+		`"aw-watcher-pk_laptop"`, `"type":"app.pk.session"`, `"duration":90`, `"title":"Acme ETL"`,
+		`"timestamp":"2026-10-14T09:00:00Z"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("export missing %s:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Event types
+const (
+	ProjectCreated    = "project.created"
+	ProjectArchived   = "project.archived"
+	ProjectUnarchived = "project.unarchived" // Brought back from the archive with 'pk unarchive'
+	ProjectDeleted    = "project.deleted"
+	ProjectRestored   = "project.restored"  // Brought back from the trash with 'pk restore'
+	ProjectRenamed    = "project.renamed"   // Renamed with 'pk rename'; data has the old ID and path
+	ProjectDetected   = "project.detected"  // Appeared on disk outside pk (daemon)
+	ProjectGone       = "project.gone"      // Disappeared from disk outside pk (daemon)
+	ProjectAnnotated  = "project.annotated" // Note added with 'pk annotate'
+	SessionOpened     = "session.opened"
+	SessionAttached   = "session.attached" // A tmux client attached or switched to a session (tmux hook)
+	SessionDetached   = "session.detached" // A tmux client detached (tmux hook)
+	AccessRecorded    = "access.recorded"
+	CacheRebuilt      = "cache.rebuilt"
+	ProjectRemapped   = "project.remapped" // State moved to a new ID/path by 'pk cache reconcile'
+)
+
+// maxLogSize rotates the event log to events.jsonl.1 once exceeded
+const maxLogSize = 1 << 20
+
+// Event is one line in the event log
+type Event struct {
+	Time      time.Time         `json:"time"`
+	Type      string            `json:"type"`
+	ProjectID string            `json:"project_id,omitempty"`
+	Path      string            `json:"path,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+}
+
+// GetEventsFile returns the path to the event log
+func GetEventsFile() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir := filepath.Join(homeDir, ".cache", "pk")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "events.jsonl"), nil
+}
+
+// Emit appends an event to the log. Failures are ignored: events are
+// best-effort and must never break the command that emits them.
+func Emit(eventType, projectID, path string, data map[string]string) {
+	eventsFile, err := GetEventsFile()
+	if err != nil {
+		return
+	}
+
+	if info, err := os.Stat(eventsFile); err == nil && info.Size() > maxLogSize {
+		os.Rename(eventsFile, eventsFile+".1")
+	}
+
+	line, err := json.Marshal(Event{
+		Time:      time.Now(),
+		Type:      eventType,
+		ProjectID: projectID,
+		Path:      path,
+		Data:      data,
+	})
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(eventsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	// Single write keeps concurrent appends line-atomic
+	f.Write(append(line, '\n'))
+}
+
+// Matches reports whether an event passes a type filter.
+// "session" matches "session.opened"; an empty filter matches everything.
+func (e Event) Matches(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	return e.Type == filter || strings.HasPrefix(e.Type, filter+".")
+}
+
+// Read returns the last n events (all if n <= 0) that match the filter
+func Read(n int, filter string) ([]Event, error) {
+	eventsFile, err := GetEventsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := readLog(eventsFile, filter, time.Time{})
+	if n > 0 && len(result) > n {
+		result = result[len(result)-n:]
+	}
+	return result, err
+}
+
+// ReadSince returns every event at or after since that matches the filter,
+// including those already rotated into events.jsonl.1
+func ReadSince(since time.Time, filter string) ([]Event, error) {
+	eventsFile, err := GetEventsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := readLog(eventsFile+".1", filter, since)
+	if err != nil {
+		return nil, err
+	}
+	current, err := readLog(eventsFile, filter, since)
+	return append(rotated, current...), err
+}
+
+// readLog reads matching events from one log file; a missing file is empty
+func readLog(path, filter string, since time.Time) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Event{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Skip partial or corrupt lines
+		}
+		if e.Matches(filter) && !e.Time.Before(since) {
+			result = append(result, e)
+		}
+	}
+	return result, scanner.Err()
+}
+
+// Follow streams raw JSON lines appended to the log after the call starts,
+// handling rotation, until stop is closed
+func Follow(w io.Writer, filter string, pollInterval time.Duration, stop <-chan struct{}) error {
+	eventsFile, err := GetEventsFile()
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	if info, err := os.Stat(eventsFile); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var partial string
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(eventsFile)
+		if err != nil {
+			continue
+		}
+
+		// Rotated or truncated: start from the top of the new file
+		if info.Size() < offset {
+			offset = 0
+			partial = ""
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		f, err := os.Open(eventsFile)
+		if err != nil {
+			continue
+		}
+		f.Seek(offset, io.SeekStart)
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		offset += int64(len(data))
+
+		chunk := partial + string(data)
+		lines := strings.Split(chunk, "\n")
+		partial = lines[len(lines)-1] // Incomplete trailing line, if any
+
+		for _, line := range lines[:len(lines)-1] {
+			var e Event
+			if err := json.Unmarshal([]byte(line), &e); err != nil || !e.Matches(filter) {
+				continue
+			}
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmitAndRead(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	Emit(ProjectCreated, "acme", "/p/acme", nil)
+	Emit(SessionOpened, "acme", "/p/acme", nil)
+	Emit(CacheRebuilt, "", "", map[string]string{"projects": "3"})
+
+	all, err := Read(0, "")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(all))
+	}
+	if all[2].Data["projects"] != "3" {
+		t.Errorf("Expected data to round-trip, got %v", all[2].Data)
+	}
+
+	sessions, _ := Read(0, "session")
+	if len(sessions) != 1 || sessions[0].Type != SessionOpened {
+		t.Errorf("Expected 1 session event, got %+v", sessions)
+	}
+
+	last, _ := Read(1, "")
+	if len(last) != 1 || last[0].Type != CacheRebuilt {
+		t.Errorf("Expected last event to be cache.rebuilt, got %+v", last)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	e := Event{Type: "session.opened"}
+	for filter, want := range map[string]bool{
+		"":               true,
+		"session":        true,
+		"session.opened": true,
+		"sess":           false,
+		"project":        false,
+	} {
+		if got := e.Matches(filter); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", filter, got, want)
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	// Existing events are not replayed
+	Emit(ProjectCreated, "old", "", nil)
+
+	var buf bytes.Buffer
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- Follow(&buf, "project", 10*time.Millisecond, stop) }()
+
+	time.Sleep(30 * time.Millisecond)
+	Emit(SessionOpened, "acme", "", nil)
+	Emit(ProjectCreated, "new", "", nil)
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Follow failed: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, `"old"`) || strings.Contains(out, "session.opened") {
+		t.Errorf("Unexpected events in output: %s", out)
+	}
+	if !strings.Contains(out, `"project_id":"new"`) {
+		t.Errorf("Expected new project event, got: %s", out)
+	}
+}
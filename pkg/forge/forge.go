@@ -0,0 +1,205 @@
+// Package forge reads repository metadata (description, topics, language,
+// license) from the GitHub and GitLab APIs so it can be merged into a
+// project's .project.toml, and creates GitHub repositories for new projects.
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTPTimeout bounds each API request
+const HTTPTimeout = 10 * time.Second
+
+// Hosting providers
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// API base URLs, swapped out in tests. gitlabAPI is empty to use the
+// repository's own host.
+var (
+	githubAPI = "https://api.github.com"
+	gitlabAPI = ""
+)
+
+// Repo is what a provider reports about a repository
+type Repo struct {
+	Provider    string
+	Path        string // owner/name, or group/subgroup/name on GitLab
+	Description string
+	Topics      []string
+	Language    string // Primary language, e.g. "Go"
+	License     string // SPDX identifier, e.g. "MIT"; empty when none
+	Archived    bool
+}
+
+// Remote is a repository URL split into host and path
+type Remote struct {
+	Provider string
+	Host     string
+	Path     string
+}
+
+// ParseRemote recognises GitHub and GitLab repository URLs in https, ssh
+// and scp-like (git@host:path) form
+func ParseRemote(repository string) (Remote, error) {
+	raw := strings.TrimSpace(repository)
+	var host, path string
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(raw, "@"); ok && !strings.Contains(at, "/") {
+		host, path, _ = strings.Cut(rest, ":")
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	if host == "" || strings.Count(path, "/") < 1 {
+		return Remote{}, fmt.Errorf("not a repository URL: %q", repository)
+	}
+
+	remote := Remote{Host: host, Path: path}
+	switch {
+	case host == "github.com":
+		remote.Provider = GitHub
+	case strings.Contains(host, "gitlab"):
+		remote.Provider = GitLab
+	default:
+		return Remote{}, fmt.Errorf("unsupported host %s (GitHub and GitLab are supported)", host)
+	}
+	return remote, nil
+}
+
+// Fetch reads a repository's metadata from its provider's API. GITHUB_TOKEN
+// (or GH_TOKEN) and GITLAB_TOKEN are sent when set, for private
+// repositories and higher rate limits.
+func Fetch(repository string) (*Repo, error) {
+	remote, err := ParseRemote(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: HTTPTimeout}
+	if remote.Provider == GitHub {
+		return fetchGitHub(client, remote)
+	}
+	return fetchGitLab(client, remote)
+}
+
+func fetchGitHub(client *http.Client, remote Remote) (*Repo, error) {
+	var body struct {
+		Description string   `json:"description"`
+		Topics      []string `json:"topics"`
+		Language    string   `json:"language"`
+		Archived    bool     `json:"archived"`
+		License     *struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+
+	token := githubEnvToken()
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	if err := getJSON(client, githubAPI+"/repos/"+remote.Path, headers, &body); err != nil {
+		return nil, err
+	}
+
+	repo := &Repo{
+		Provider:    GitHub,
+		Path:        remote.Path,
+		Description: body.Description,
+		Topics:      body.Topics,
+		Language:    body.Language,
+		Archived:    body.Archived,
+	}
+	if body.License != nil && body.License.SPDXID != "NOASSERTION" {
+		repo.License = body.License.SPDXID
+	}
+	return repo, nil
+}
+
+func fetchGitLab(client *http.Client, remote Remote) (*Repo, error) {
+	var body struct {
+		Description string   `json:"description"`
+		Topics      []string `json:"topics"`
+		TagList     []string `json:"tag_list"` // Before GitLab 14.5
+		Archived    bool     `json:"archived"`
+		License     *struct {
+			Key string `json:"key"`
+		} `json:"license"`
+	}
+
+	base := gitlabAPI
+	if base == "" {
+		base = "https://" + remote.Host + "/api/v4"
+	}
+	project := base + "/projects/" + url.PathEscape(remote.Path)
+
+	headers := map[string]string{}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+	if err := getJSON(client, project+"?license=true", headers, &body); err != nil {
+		return nil, err
+	}
+
+	repo := &Repo{
+		Provider:    GitLab,
+		Path:        remote.Path,
+		Description: body.Description,
+		Topics:      body.Topics,
+		Archived:    body.Archived,
+	}
+	if len(repo.Topics) == 0 {
+		repo.Topics = body.TagList
+	}
+	if body.License != nil {
+		repo.License = strings.ToUpper(body.License.Key)
+	}
+
+	// Languages are a separate call: name -> percentage of the code
+	var languages map[string]float64
+	if err := getJSON(client, project+"/languages", headers, &languages); err == nil {
+		share := 0.0
+		for name, percent := range languages {
+			if percent > share || (percent == share && name < repo.Language) {
+				repo.Language, share = name, percent
+			}
+		}
+	}
+	return repo, nil
+}
+
+// getJSON decodes the JSON response of a GET request into v
+func getJSON(client *http.Client, endpoint string, headers map[string]string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("repository not found (private? set GITHUB_TOKEN or GITLAB_TOKEN)")
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%s (rate limited? set GITHUB_TOKEN or GITLAB_TOKEN)", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("GET %s: %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
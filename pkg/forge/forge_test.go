@@ -0,0 +1,252 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url      string
+		provider string
+		path     string
+	}{
+		{"https://github.com/acme/etl", GitHub, "acme/etl"},
+		{"https://github.com/acme/etl.git", GitHub, "acme/etl"},
+		{"git@github.com:acme/etl.git", GitHub, "acme/etl"},
+		{"ssh://git@gitlab.com/acme/data/etl.git", GitLab, "acme/data/etl"},
+		{"https://gitlab.example.com/acme/etl/", GitLab, "acme/etl"},
+	}
+	for _, tt := range tests {
+		remote, err := ParseRemote(tt.url)
+		if err != nil {
+			t.Errorf("ParseRemote(%q): %v", tt.url, err)
+			continue
+		}
+		if remote.Provider != tt.provider || remote.Path != tt.path {
+			t.Errorf("ParseRemote(%q) = %s %s, want %s %s", tt.url, remote.Provider, remote.Path, tt.provider, tt.path)
+		}
+	}
+
+	for _, bad := range []string{"", "not a url", "https://github.com/acme", "https://bitbucket.org/acme/etl"} {
+		if _, err := ParseRemote(bad); err == nil {
+			t.Errorf("ParseRemote(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFetchGitHub(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/repos/acme/etl" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"description":"Nightly loads","topics":["etl","dbt"],"language":"Python",
+			"archived":true,"license":{"spdx_id":"MIT"}}`))
+	}))
+	defer server.Close()
+	defer swap(&githubAPI, server.URL)()
+	defer setenv("GITHUB_TOKEN", "secret")()
+
+	repo, err := Fetch("git@github.com:acme/etl.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Description != "Nightly loads" || strings.Join(repo.Topics, ",") != "etl,dbt" ||
+		repo.Language != "Python" || repo.License != "MIT" || !repo.Archived {
+		t.Errorf("unexpected repo: %+v", repo)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the token", auth)
+	}
+
+	if _, err := Fetch("https://github.com/acme/missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing repository: got %v", err)
+	}
+}
+
+func TestFetchGitLab(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/projects/acme%2Fdata%2Fetl":
+			w.Write([]byte(`{"description":"Loads","tag_list":["ETL"],"license":{"key":"apache-2.0"}}`))
+		case "/projects/acme%2Fdata%2Fetl/languages":
+			w.Write([]byte(`{"Shell":10.5,"Go":89.5}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer swap(&gitlabAPI, server.URL)()
+
+	repo, err := Fetch("https://gitlab.com/acme/data/etl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Description != "Loads" || strings.Join(repo.Topics, ",") != "ETL" ||
+		repo.Language != "Go" || repo.License != "APACHE-2.0" {
+		t.Errorf("unexpected repo: %+v", repo)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	var p config.Project
+	p.Notes.Description = "Mine"
+	p.Tech.Stack = []string{"python"}
+	repo := &Repo{
+		Provider:    GitHub,
+		Description: "Theirs",
+		Topics:      []string{"ETL", "Data"},
+		Language:    "Jupyter Notebook",
+		License:     "MIT",
+	}
+
+	changes := Changes(&p, repo, false)
+	got := map[string]string{}
+	for _, c := range changes {
+		got[c.Key] = c.New
+	}
+	want := map[string]string{
+		"tech.domain":              "etl, data",
+		"tech.stack":               "python, jupyter",
+		"consultant.license_model": "open-source",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+
+	if err := Apply(&p, repo, changes); err != nil {
+		t.Fatal(err)
+	}
+	if p.Detected["tech.stack"].Source != "github api" {
+		t.Errorf("tech.stack provenance = %+v", p.Detected["tech.stack"])
+	}
+	if again := Changes(&p, repo, false); len(again) != 0 {
+		t.Errorf("applied changes should be idempotent, got %v", again)
+	}
+
+	overwrite := Changes(&p, repo, true)
+	if len(overwrite) != 1 || overwrite[0].Key != "notes.description" || overwrite[0].Old != "Mine" {
+		t.Errorf("overwrite changes = %v, want only the description", overwrite)
+	}
+}
+
+func swap(target *string, value string) func() {
+	original := *target
+	*target = value
+	return func() { *target = original }
+}
+
+func setenv(key, value string) func() {
+	original, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if had {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestCreateGitHubRepo(t *testing.T) {
+	var path string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["name"] == "taken" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"Repository creation failed.","errors":[{"message":"name already exists on this account"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"full_name":"acme/etl","html_url":"https://github.com/acme/etl",
+			"clone_url":"https://github.com/acme/etl.git","ssh_url":"git@github.com:acme/etl.git","private":true}`))
+	}))
+	defer server.Close()
+	defer swap(&githubAPI, server.URL)()
+	defer setenv("GITHUB_TOKEN", "secret")()
+
+	repo, err := CreateGitHubRepo("etl", "Nightly loads", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/user/repos" || payload["private"] != true || payload["description"] != "Nightly loads" {
+		t.Errorf("request = %s %v", path, payload)
+	}
+	if repo.HTMLURL != "https://github.com/acme/etl" || repo.SSHURL != "git@github.com:acme/etl.git" || !repo.Private {
+		t.Errorf("unexpected repo: %+v", repo)
+	}
+
+	if _, err := CreateGitHubRepo("taken", "", false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("existing repository: got %v", err)
+	}
+}
+
+func TestListGitHubRepos(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		if r.URL.Path != "/orgs/acme/repos" && r.URL.Path != "/users/jo/repos" {
+			http.NotFound(w, r)
+			return
+		}
+		// A full first page, then the rest
+		var repos []map[string]interface{}
+		if r.URL.Query().Get("page") == "1" {
+			for i := range 100 {
+				repos = append(repos, map[string]interface{}{"name": fmt.Sprintf("repo-%d", i), "full_name": fmt.Sprintf("acme/repo-%d", i)})
+			}
+		} else {
+			repos = append(repos, map[string]interface{}{
+				"name": "etl", "full_name": "acme/etl", "description": "Loads", "language": "Python",
+				"topics": []string{"etl"}, "fork": true, "license": map[string]string{"spdx_id": "MIT"},
+				"html_url": "https://github.com/acme/etl", "ssh_url": "git@github.com:acme/etl.git",
+			})
+		}
+		json.NewEncoder(w).Encode(repos)
+	}))
+	defer server.Close()
+	defer swap(&githubAPI, server.URL)()
+	defer setenv("GITHUB_TOKEN", "secret")()
+
+	repos, err := ListGitHubRepos("acme", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 101 || len(queries) != 2 {
+		t.Fatalf("got %d repos in %d requests, want 101 in 2", len(repos), len(queries))
+	}
+	etl := repos[100]
+	if etl.Name != "etl" || etl.Path != "acme/etl" || etl.Language != "Python" || etl.License != "MIT" ||
+		!etl.Fork || etl.SSHURL != "git@github.com:acme/etl.git" {
+		t.Errorf("unexpected repo: %+v", etl)
+	}
+
+	queries = nil
+	if _, err := ListGitHubRepos("jo", true); err != nil || !strings.HasPrefix(queries[0], "/users/jo/repos?type=owner") {
+		t.Errorf("user listing: %v %v", queries, err)
+	}
+	if _, err := ListGitHubRepos("missing", false); err == nil {
+		t.Error("a missing organization should fail")
+	}
+}
@@ -0,0 +1,189 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// CreatedRepo is a repository created on GitHub
+type CreatedRepo struct {
+	FullName string `json:"full_name"` // owner/name
+	HTMLURL  string `json:"html_url"`
+	CloneURL string `json:"clone_url"` // https
+	SSHURL   string `json:"ssh_url"`
+	Private  bool   `json:"private"`
+}
+
+// githubEnvToken returns GITHUB_TOKEN, or GH_TOKEN
+func githubEnvToken() string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("GH_TOKEN")
+}
+
+// GitHubToken returns a token for the GitHub API: GITHUB_TOKEN or GH_TOKEN,
+// else the one the gh CLI is logged in with. Empty when there's none.
+func GitHubToken() string {
+	if token := githubEnvToken(); token != "" {
+		return token
+	}
+	if !deps.Available("gh") {
+		return ""
+	}
+	output, err := runner.Output(runner.Command("gh", "auth", "token", "--hostname", "github.com"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// CreateGitHubRepo creates an empty repository owned by the authenticated
+// user
+func CreateGitHubRepo(name, description string, private bool) (*CreatedRepo, error) {
+	token := GitHubToken()
+	if token == "" {
+		return nil, errors.New("no GitHub token (set GITHUB_TOKEN or run 'gh auth login')")
+	}
+
+	endpoint := githubAPI + "/user/repos"
+	payload, err := json.Marshal(map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"private":     private,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: HTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		// GitHub explains refusals, e.g. "name already exists on this account"
+		var body struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		reason := body.Message
+		if len(body.Errors) > 0 && body.Errors[0].Message != "" {
+			reason = body.Errors[0].Message
+		}
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized:
+			return nil, errors.New("GitHub rejected the token (set GITHUB_TOKEN or run 'gh auth login')")
+		case reason != "":
+			return nil, fmt.Errorf("%s: %s", resp.Status, reason)
+		}
+		return nil, fmt.Errorf("POST %s: %s", endpoint, resp.Status)
+	}
+
+	var repo CreatedRepo
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// OwnedRepo is a repository in an organization's or user's listing
+type OwnedRepo struct {
+	Repo
+	Name     string
+	HTMLURL  string
+	CloneURL string // https
+	SSHURL   string
+	Fork     bool
+	Private  bool
+}
+
+// listPageSize is the most repositories GitHub returns per page
+const listPageSize = 100
+
+// ListGitHubRepos lists the repositories of an organization, or of a user
+// when user is set, following pagination. The token from GitHubToken is
+// sent when there is one, so private repositories the token can see are
+// included.
+func ListGitHubRepos(owner string, user bool) ([]OwnedRepo, error) {
+	endpoint := githubAPI + "/orgs/" + url.PathEscape(owner) + "/repos?type=all"
+	if user {
+		endpoint = githubAPI + "/users/" + url.PathEscape(owner) + "/repos?type=owner"
+	}
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if token := GitHubToken(); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	client := &http.Client{Timeout: HTTPTimeout}
+	var repos []OwnedRepo
+	for page := 1; ; page++ {
+		var body []struct {
+			Name        string   `json:"name"`
+			FullName    string   `json:"full_name"`
+			Description string   `json:"description"`
+			Topics      []string `json:"topics"`
+			Language    string   `json:"language"`
+			Archived    bool     `json:"archived"`
+			Fork        bool     `json:"fork"`
+			Private     bool     `json:"private"`
+			HTMLURL     string   `json:"html_url"`
+			CloneURL    string   `json:"clone_url"`
+			SSHURL      string   `json:"ssh_url"`
+			License     *struct {
+				SPDXID string `json:"spdx_id"`
+			} `json:"license"`
+		}
+		pageURL := fmt.Sprintf("%s&per_page=%d&page=%d", endpoint, listPageSize, page)
+		if err := getJSON(client, pageURL, headers, &body); err != nil {
+			return nil, err
+		}
+
+		for _, r := range body {
+			repo := OwnedRepo{
+				Repo: Repo{
+					Provider:    GitHub,
+					Path:        r.FullName,
+					Description: r.Description,
+					Topics:      r.Topics,
+					Language:    r.Language,
+					Archived:    r.Archived,
+				},
+				Name:     r.Name,
+				HTMLURL:  r.HTMLURL,
+				CloneURL: r.CloneURL,
+				SSHURL:   r.SSHURL,
+				Fork:     r.Fork,
+				Private:  r.Private,
+			}
+			if r.License != nil && r.License.SPDXID != "NOASSERTION" {
+				repo.License = r.License.SPDXID
+			}
+			repos = append(repos, repo)
+		}
+		if len(body) < listPageSize {
+			return repos, nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package forge
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// Change is a proposed edit to one field of a project
+type Change struct {
+	Key        string // Dotted key, e.g. "tech.stack"
+	Old        string
+	New        string
+	Confidence string
+}
+
+// languages maps provider language names to pk stack entries where they
+// differ from the lowercased name
+var languages = map[string]string{
+	"Jupyter Notebook": "jupyter",
+	"HCL":              "terraform",
+	"C++":              "cpp",
+	"C#":               "csharp",
+}
+
+// Changes lists what repo would add to p. Topics are added to tech.domain
+// and the primary language to tech.stack; a license makes license_model
+// open-source. Description and license_model are only filled when empty
+// unless overwrite is set. Lists are merged, never shortened.
+func Changes(p *config.Project, repo *Repo, overwrite bool) []Change {
+	var changes []Change
+	propose := func(key, value, confidence string) {
+		if old := config.FieldValue(p, key); value != old {
+			changes = append(changes, Change{Key: key, Old: old, New: value, Confidence: confidence})
+		}
+	}
+
+	if description := strings.TrimSpace(repo.Description); description != "" {
+		if p.Notes.Description == "" || overwrite {
+			propose("notes.description", description, config.ConfidenceHigh)
+		}
+	}
+
+	var topics []string
+	for _, topic := range repo.Topics {
+		topics = append(topics, strings.ToLower(topic))
+	}
+	propose("tech.domain", strings.Join(merge(p.Tech.Domain, topics), ", "), config.ConfidenceMedium)
+
+	if repo.Language != "" {
+		propose("tech.stack", strings.Join(merge(p.Tech.Stack, []string{StackName(repo.Language)}), ", "), config.ConfidenceMedium)
+	}
+
+	if repo.License != "" && (p.GetLicenseModel() == "" || overwrite) {
+		propose("consultant.license_model", "open-source", config.ConfidenceMedium)
+	}
+	return changes
+}
+
+// Apply sets each change on p and records it as detected from the
+// provider, so 'pk show --audit' lists it until confirmed
+func Apply(p *config.Project, repo *Repo, changes []Change) error {
+	for _, c := range changes {
+		if err := p.SetField(c.Key, c.New); err != nil {
+			return err
+		}
+		p.MarkDetected(c.Key, c.Confidence, repo.Provider+" api")
+	}
+	return nil
+}
+
+// StackName converts a provider language name to a stack entry
+func StackName(language string) string {
+	if name, ok := languages[language]; ok {
+		return name
+	}
+	return strings.ReplaceAll(strings.ToLower(language), " ", "-")
+}
+
+// merge appends the items of add that current lacks, ignoring case
+func merge(current, add []string) []string {
+	merged := slices.Clone(current)
+	for _, item := range add {
+		if !slices.ContainsFunc(merged, func(s string) bool { return strings.EqualFold(s, item) }) {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
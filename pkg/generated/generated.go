@@ -0,0 +1,237 @@
+package generated
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of files pk generates outside project directories
+const (
+	KindAliases      = "aliases"
+	KindCompletion   = "completion"
+	KindTmuxBindings = "tmux-bindings"
+	KindService      = "service"
+	KindBinDir       = "bin-dir"
+	KindBadges       = "badges"
+)
+
+// Ownership modes for generated files
+const (
+	ModeFile  = "file"  // pk owns the whole file
+	ModeBlock = "block" // pk owns a managed block inside a user file
+	ModeDir   = "dir"   // pk owns the whole directory
+)
+
+// Markers delimiting a managed block inside a user-owned file
+const (
+	BlockStart = "# >>> pk managed block >>>"
+	BlockEnd   = "# <<< pk managed block <<<"
+)
+
+// Markers the user places in a project README for pk sync badges to fill.
+// pk owns only what lies between them.
+const (
+	BadgesStart = "<!-- pk:badges:start -->"
+	BadgesEnd   = "<!-- pk:badges:end -->"
+)
+
+// Entry records a file written by pk
+type Entry struct {
+	Path      string    `json:"path"`
+	Kind      string    `json:"kind"`
+	Mode      string    `json:"mode"`
+	Generator string    `json:"generator"` // Command that regenerates the file
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetManifestFile returns the path to the generated files manifest
+func GetManifestFile() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dataDir := filepath.Join(homeDir, ".local", "share", "pk")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "generated.json"), nil
+}
+
+// Load reads the manifest, keyed by file path
+func Load() (map[string]Entry, error) {
+	manifestFile, err := GetManifestFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]Entry), nil
+		}
+		return nil, err
+	}
+
+	var entries map[string]Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Save writes the manifest
+func Save(entries map[string]Entry) error {
+	manifestFile, err := GetManifestFile()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(manifestFile, data, 0644)
+}
+
+// Record marks a file as generated by pk
+func Record(path, kind, mode, generator string) error {
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+
+	entries[path] = Entry{
+		Path:      path,
+		Kind:      kind,
+		Mode:      mode,
+		Generator: generator,
+		UpdatedAt: time.Now(),
+	}
+
+	return Save(entries)
+}
+
+// Forget removes a file from the manifest without touching the file
+func Forget(path string) error {
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+
+	delete(entries, path)
+	return Save(entries)
+}
+
+// List returns manifest entries sorted by kind then path, optionally filtered by kind
+func List(kind string) ([]Entry, error) {
+	entries, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Entry
+	for _, e := range entries {
+		if kind == "" || e.Kind == kind {
+			list = append(list, e)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Path < list[j].Path
+	})
+
+	return list, nil
+}
+
+// Clean removes what pk wrote for an entry: the whole file for ModeFile,
+// only the managed block for ModeBlock. The entry is dropped from the manifest.
+func Clean(entry Entry) error {
+	switch entry.Mode {
+	case ModeBlock:
+		data, err := os.ReadFile(entry.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			cleaned := RemoveBlock(string(data))
+			if entry.Kind == KindBadges {
+				cleaned = ClearBadges(string(data))
+			}
+			if err := os.WriteFile(entry.Path, []byte(cleaned), 0644); err != nil {
+				return err
+			}
+		}
+	case ModeFile:
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	case ModeDir:
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown mode '%s' for %s", entry.Mode, entry.Path)
+	}
+
+	return Forget(entry.Path)
+}
+
+// ReplaceBlock swaps the managed block in content for block,
+// appending it when no managed block exists yet
+func ReplaceBlock(content, block string) string {
+	managed := BlockStart + "\n" + block + "\n" + BlockEnd + "\n"
+
+	start := strings.Index(content, BlockStart)
+	end := strings.Index(content, BlockEnd)
+	if start >= 0 && end > start {
+		rest := strings.TrimPrefix(content[end+len(BlockEnd):], "\n")
+		return content[:start] + managed + rest
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + managed
+}
+
+// RemoveBlock strips the managed block (and the blank line before it) from content
+func RemoveBlock(content string) string {
+	start := strings.Index(content, BlockStart)
+	end := strings.Index(content, BlockEnd)
+	if start < 0 || end < start {
+		return content
+	}
+
+	before := strings.TrimRight(content[:start], "\n")
+	rest := strings.TrimPrefix(content[end+len(BlockEnd):], "\n")
+	if before == "" {
+		return rest
+	}
+	return before + "\n" + rest
+}
+
+// ClearBadges empties the badge block in a README, keeping the markers so a
+// later pk sync badges fills it again
+func ClearBadges(content string) string {
+	start := strings.Index(content, BadgesStart)
+	end := strings.Index(content, BadgesEnd)
+	if start < 0 || end < start {
+		return content
+	}
+	return content[:start] + BadgesStart + "\n" + content[end:]
+}
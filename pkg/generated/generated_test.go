@@ -0,0 +1,101 @@
+package generated
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceBlock(t *testing.T) {
+	content := "set -g mouse on"
+
+	// Append when no block exists
+	first := ReplaceBlock(content, "bind f one")
+	expected := "set -g mouse on\n\n" + BlockStart + "\nbind f one\n" + BlockEnd + "\n"
+	if first != expected {
+		t.Errorf("ReplaceBlock append = %q, want %q", first, expected)
+	}
+
+	// Replace in place on subsequent runs
+	second := ReplaceBlock(first+"set -g status on\n", "bind f two")
+	expected = "set -g mouse on\n\n" + BlockStart + "\nbind f two\n" + BlockEnd + "\nset -g status on\n"
+	if second != expected {
+		t.Errorf("ReplaceBlock replace = %q, want %q", second, expected)
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	content := ReplaceBlock("set -g mouse on\n", "bind f one") + "set -g status on\n"
+
+	result := RemoveBlock(content)
+	expected := "set -g mouse on\nset -g status on\n"
+	if result != expected {
+		t.Errorf("RemoveBlock = %q, want %q", result, expected)
+	}
+
+	// No block is a no-op
+	if RemoveBlock("plain\n") != "plain\n" {
+		t.Error("RemoveBlock should leave content without a block untouched")
+	}
+}
+
+func TestRecordAndClean(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	aliasFile := filepath.Join(tmpDir, "aliases.zsh")
+	if err := os.WriteFile(aliasFile, []byte("alias x=y\n"), 0644); err != nil {
+		t.Fatalf("Failed to write alias file: %v", err)
+	}
+
+	if err := Record(aliasFile, KindAliases, ModeFile, "pk sync"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	entries, err := List(KindAliases)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != aliasFile {
+		t.Fatalf("Expected one alias entry, got %+v", entries)
+	}
+
+	if err := Clean(entries[0]); err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+
+	if _, err := os.Stat(aliasFile); !os.IsNotExist(err) {
+		t.Error("Clean should remove file-mode entries from disk")
+	}
+
+	entries, _ = List("")
+	if len(entries) != 0 {
+		t.Errorf("Expected empty manifest after clean, got %d entries", len(entries))
+	}
+}
+
+func TestCleanBadges(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	readme := filepath.Join(tmpDir, "README.md")
+	content := "# ETL\n\n" + BadgesStart + "\n![status](https://img.shields.io/badge/status-active-green)\n" + BadgesEnd + "\n\nNotes\n"
+	if err := os.WriteFile(readme, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Record(readme, KindBadges, ModeBlock, "pk sync badges"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := List(KindBadges)
+	if err := Clean(entries[0]); err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(readme)
+	if want := "# ETL\n\n" + BadgesStart + "\n" + BadgesEnd + "\n\nNotes\n"; string(data) != want {
+		t.Errorf("README after clean = %q, want %q", data, want)
+	}
+}
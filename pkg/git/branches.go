@@ -0,0 +1,105 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// BranchCacheMaxAge is how long ls-remote results are reused for completion
+const BranchCacheMaxAge = 10 * time.Minute
+
+// lsRemoteTimeout bounds network calls made during shell completion
+const lsRemoteTimeout = 5 * time.Second
+
+// branchCacheEntry is the cached branch list for one remote
+type branchCacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Branches  []string  `json:"branches"`
+}
+
+// GetBranchCacheFile returns the path to the remote branch cache
+func GetBranchCacheFile() (string, error) {
+	return statefile.Path("branches.json")
+}
+
+// RemoteBranches lists branch names on a remote via 'git ls-remote --heads'.
+// remote is a URL, or a remote name (e.g. "origin") resolved inside dir.
+func RemoteBranches(dir, remote string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lsRemoteTimeout)
+	defer cancel()
+
+	cmd := runner.CommandContext(ctx, "git", "ls-remote", "--heads", remote)
+	cmd.Dir = dir
+	// Never block completion on a credential prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := runner.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLsRemote(string(output)), nil
+}
+
+// parseLsRemote extracts sorted branch names from ls-remote output
+func parseLsRemote(output string) []string {
+	var branches []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if name, ok := strings.CutPrefix(fields[1], "refs/heads/"); ok {
+			branches = append(branches, name)
+		}
+	}
+	sort.Strings(branches)
+	return branches
+}
+
+// CachedRemoteBranches returns branches for a remote, reusing results younger
+// than BranchCacheMaxAge. Stale cache is returned if the remote is unreachable.
+func CachedRemoteBranches(dir, remote string) ([]string, error) {
+	return cachedBranches(dir+"|"+remote, func() ([]string, error) {
+		return RemoteBranches(dir, remote)
+	})
+}
+
+func cachedBranches(key string, fetch func() ([]string, error)) ([]string, error) {
+	cacheFile, err := GetBranchCacheFile()
+	if err != nil {
+		return fetch()
+	}
+
+	entries := make(map[string]branchCacheEntry)
+	if data, err := statefile.ReadFile(cacheFile); err == nil {
+		json.Unmarshal(data, &entries)
+	}
+
+	entry, cached := entries[key]
+	if cached && time.Since(entry.FetchedAt) < BranchCacheMaxAge {
+		return entry.Branches, nil
+	}
+
+	branches, err := fetch()
+	if err != nil {
+		if cached {
+			return entry.Branches, nil
+		}
+		return nil, err
+	}
+
+	entries[key] = branchCacheEntry{FetchedAt: time.Now(), Branches: branches}
+	if data, err := json.MarshalIndent(entries, "", "  "); err == nil {
+		statefile.WriteFile(cacheFile, data, 0644)
+	}
+
+	return branches, nil
+}
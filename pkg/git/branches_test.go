@@ -0,0 +1,50 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseLsRemote(t *testing.T) {
+	output := "abc123\trefs/heads/main\n" +
+		"def456\trefs/heads/feature/login\n" +
+		"789abc\trefs/tags/v1.0\n" +
+		"\n"
+
+	branches := parseLsRemote(output)
+	expected := []string{"feature/login", "main"}
+	if !reflect.DeepEqual(branches, expected) {
+		t.Errorf("Expected %v, got %v", expected, branches)
+	}
+}
+
+func TestCachedBranches(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	calls := 0
+	fetch := func() ([]string, error) {
+		calls++
+		return []string{"main"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		branches, err := cachedBranches("repo", fetch)
+		if err != nil || len(branches) != 1 {
+			t.Fatalf("Unexpected result: %v, %v", branches, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected one fetch thanks to cache, got %d", calls)
+	}
+
+	// Unreachable remote without cache is an error
+	failing := func() ([]string, error) { return nil, errors.New("offline") }
+	if _, err := cachedBranches("other", failing); err == nil {
+		t.Error("Expected error for uncached unreachable remote")
+	}
+}
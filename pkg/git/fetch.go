@@ -0,0 +1,116 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Fetch updates a repository's remote-tracking branches from all its
+// remotes, pruning deleted ones, without touching the working tree
+func Fetch(dir string) error {
+	_, err := remoteCommand(dir, "fetch", "--quiet", "--all", "--prune")
+	return err
+}
+
+// ErrNoUpstream is returned by Pull when the current branch has no upstream
+// or HEAD is detached, so there is nothing to pull
+var ErrNoUpstream = errors.New("no upstream")
+
+// Pull fast-forwards the current branch from its upstream, or rebases onto
+// it with rebase, reporting whether HEAD moved
+func Pull(dir string, rebase bool) (bool, error) {
+	if _, err := runner.Output(runner.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "@{upstream}")); err != nil {
+		return false, ErrNoUpstream
+	}
+	before := head(dir)
+	mode := "--ff-only"
+	if rebase {
+		mode = "--rebase"
+	}
+	if _, err := remoteCommand(dir, "pull", "--quiet", mode); err != nil {
+		return false, err
+	}
+	return head(dir) != before, nil
+}
+
+// Clone clones url into dir, which must not exist yet, quietly and
+// without prompting for credentials
+func Clone(url, dir string) error {
+	_, err := remoteCommand(filepath.Dir(dir), "clone", "--quiet", url, dir)
+	return err
+}
+
+// head returns the commit HEAD points at, or "" if it can't be read
+func head(dir string) string {
+	output, err := runner.Output(runner.Command("git", "-C", dir, "rev-parse", "HEAD"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// remoteCommand runs a git command that talks to remotes. Credential
+// prompts are turned off so a batch never waits on a terminal, and a
+// failure is reported with git's own reason.
+func remoteCommand(dir string, args ...string) ([]byte, error) {
+	cmd := runner.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := runner.Output(cmd)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if reason := failureReason(string(exitErr.Stderr)); reason != "" {
+			return output, errors.New(reason)
+		}
+	}
+	return output, err
+}
+
+// failureReason picks the line explaining a git failure out of its stderr:
+// the first fatal: or error: message, else the last line
+func failureReason(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for _, line := range lines {
+		for _, prefix := range []string{"fatal: ", "error: "} {
+			if reason, ok := strings.CutPrefix(line, prefix); ok {
+				return strings.TrimSpace(reason)
+			}
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// ForEach runs fn on every directory, at most workers at a time, and
+// returns the errors in the order given. done, if set, is called as each
+// directory finishes (from several goroutines, one at a time).
+func ForEach(dirs []string, workers int, fn func(dir string) error, done func(dir string, err error)) []error {
+	errs := make([]error, len(dirs))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range max(1, min(workers, len(dirs))) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(dirs[i])
+				if done != nil {
+					mu.Lock()
+					done(dirs[i], errs[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range dirs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return errs
+}
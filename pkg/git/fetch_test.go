@@ -0,0 +1,104 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestForEach(t *testing.T) {
+	dirs := []string{"/a", "/b", "/c", "/d", "/e"}
+	var running, peak atomic.Int32
+	var finished []string
+	errs := ForEach(dirs, 2, func(dir string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if dir == "/c" {
+			return fmt.Errorf("failed %s", dir)
+		}
+		return nil
+	}, func(dir string, err error) {
+		finished = append(finished, dir)
+	})
+
+	if len(errs) != len(dirs) {
+		t.Fatalf("got %d errors for %d dirs", len(errs), len(dirs))
+	}
+	for i, err := range errs {
+		if (err != nil) != (dirs[i] == "/c") {
+			t.Errorf("%s: unexpected error %v", dirs[i], err)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("%d ran at once, want at most 2", peak.Load())
+	}
+	if len(finished) != len(dirs) {
+		t.Errorf("done called for %v", finished)
+	}
+}
+
+func TestPull(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("git -C /a rev-parse HEAD", "abc\n", nil)
+	defer runner.Swap(fake)()
+
+	updated, err := Pull("/a", false)
+	if err != nil || updated {
+		t.Errorf("Pull = %v, %v; want not updated", updated, err)
+	}
+	want := []string{
+		"git -C /a rev-parse --abbrev-ref @{upstream}",
+		"git -C /a rev-parse HEAD",
+		"git -C /a pull --quiet --ff-only",
+		"git -C /a rev-parse HEAD",
+	}
+	if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+
+	fake = runner.NewFake()
+	fake.On("git -C /a pull", "", errors.New("exit status 1"))
+	defer runner.Swap(fake)()
+	if _, err := Pull("/a", true); err == nil {
+		t.Error("Pull should fail")
+	}
+	if got := fake.Commands()[2]; got != "git -C /a pull --quiet --rebase" {
+		t.Errorf("pull command = %q", got)
+	}
+
+	// A branch without an upstream is left alone
+	fake = runner.NewFake()
+	fake.On("git -C /a rev-parse --abbrev-ref @{upstream}", "", errors.New("exit status 128"))
+	defer runner.Swap(fake)()
+	if _, err := Pull("/a", false); !errors.Is(err, ErrNoUpstream) {
+		t.Errorf("Pull without upstream = %v, want ErrNoUpstream", err)
+	}
+	if n := len(fake.Calls()); n != 1 {
+		t.Errorf("ran %d commands for a branch without upstream", n)
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct{ stderr, want string }{
+		{"fatal: '/x' does not appear to be a git repository\nfatal: Could not read from remote repository.\n\nPlease make sure you have the correct access rights\nand the repository exists.\n",
+			"'/x' does not appear to be a git repository"},
+		{"hint: something\nerror: cannot pull with rebase: You have unstaged changes.\n", "cannot pull with rebase: You have unstaged changes."},
+		{"Not possible to fast-forward, aborting.\n", "Not possible to fast-forward, aborting."},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := failureReason(tt.stderr); got != tt.want {
+			t.Errorf("failureReason(%q) = %q, want %q", tt.stderr, got, tt.want)
+		}
+	}
+}
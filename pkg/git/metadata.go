@@ -0,0 +1,100 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Ways of keeping pk's files out of what a repository ships ([metadata] in
+// config.toml)
+const (
+	MetadataNone         = "none"          // Leave the repository alone
+	MetadataExportIgnore = "export-ignore" // .gitattributes: left out of git archive and forge downloads
+	MetadataExclude      = "exclude"       // .git/info/exclude: never committed, nothing in the repository
+	MetadataSidecar      = "sidecar"       // ~/.local/share/pk/meta: moved out by the caller, nothing to hide
+)
+
+// MetadataPaths are pk's files in a project, as root-anchored patterns
+var MetadataPaths = []string{"/.project.toml", "/.pk"}
+
+// HideMetadata applies mode to the repository at dir, adding only the
+// patterns not already there. Returns the file it changed, or "" if none.
+func HideMetadata(dir, mode string) (string, error) {
+	switch mode {
+	case "", MetadataNone, MetadataSidecar:
+		return "", nil
+	case MetadataExportIgnore:
+		path := filepath.Join(dir, ".gitattributes")
+		return appendMissing(path, func(line string) (string, bool) {
+			fields := strings.Fields(line)
+			for _, attr := range fields[1:] {
+				if attr == "export-ignore" {
+					return fields[0], true
+				}
+			}
+			return "", false
+		}, " export-ignore")
+	case MetadataExclude:
+		cmd := runner.Command("git", "rev-parse", "--git-path", "info/exclude")
+		cmd.Dir = dir
+		output, err := runner.Output(cmd)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a git repository", dir)
+		}
+		path := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		return appendMissing(path, func(line string) (string, bool) {
+			return strings.TrimSuffix(line, "/"), true
+		}, "")
+	}
+	return "", fmt.Errorf("unknown metadata mode %q (want %s, %s, %s or %s)",
+		mode, MetadataExportIgnore, MetadataExclude, MetadataSidecar, MetadataNone)
+}
+
+// appendMissing adds a line for each of MetadataPaths that parse doesn't
+// find in the file already. parse returns the pattern a line covers.
+func appendMissing(path string, parse func(line string) (string, bool), suffix string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if pattern, ok := parse(line); ok {
+			present[pattern] = true
+		}
+	}
+
+	var add strings.Builder
+	for _, pattern := range MetadataPaths {
+		if !present[pattern] {
+			add.WriteString(pattern + suffix + "\n")
+		}
+	}
+	if add.Len() == 0 {
+		return "", nil
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content+"# pk project metadata\n"+add.String()), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
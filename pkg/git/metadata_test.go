@@ -0,0 +1,65 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestHideMetadataExportIgnore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".gitattributes")
+	os.WriteFile(path, []byte("*.sh text eol=lf\n/.project.toml export-ignore"), 0644)
+
+	changed, err := HideMetadata(dir, MetadataExportIgnore)
+	if err != nil || changed != path {
+		t.Fatalf("HideMetadata = %q, %v", changed, err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "*.sh text eol=lf\n/.project.toml export-ignore\n# pk project metadata\n/.pk export-ignore\n"
+	if string(data) != want {
+		t.Errorf(".gitattributes =\n%s\nwant\n%s", data, want)
+	}
+
+	// Already marked: nothing to do
+	if changed, err := HideMetadata(dir, MetadataExportIgnore); err != nil || changed != "" {
+		t.Errorf("second run should change nothing, got %q, %v", changed, err)
+	}
+}
+
+func TestHideMetadataExclude(t *testing.T) {
+	dir := t.TempDir()
+	fake := runner.NewFake()
+	fake.On("git rev-parse --git-path info/exclude", ".git/info/exclude\n", nil)
+	defer runner.Swap(fake)()
+
+	changed, err := HideMetadata(dir, MetadataExclude)
+	want := filepath.Join(dir, ".git", "info", "exclude")
+	if err != nil || changed != want {
+		t.Fatalf("HideMetadata = %q, %v", changed, err)
+	}
+	data, _ := os.ReadFile(want)
+	if string(data) != "# pk project metadata\n/.project.toml\n/.pk\n" {
+		t.Errorf("exclude = %q", data)
+	}
+	if calls := fake.Calls(); calls[0].Dir != dir {
+		t.Errorf("git should run in the project, got %q", calls[0].Dir)
+	}
+}
+
+func TestHideMetadataModes(t *testing.T) {
+	dir := t.TempDir()
+	for _, mode := range []string{"", MetadataNone} {
+		if changed, err := HideMetadata(dir, mode); err != nil || changed != "" {
+			t.Errorf("mode %q should change nothing, got %q, %v", mode, changed, err)
+		}
+	}
+	if _, err := HideMetadata(dir, "hide"); err == nil {
+		t.Error("unknown mode should fail")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("no files should be written, got %d", len(entries))
+	}
+}
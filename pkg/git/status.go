@@ -0,0 +1,115 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Status is a repository's working tree and branch state
+type Status struct {
+	Branch     string // Empty when HEAD is detached
+	Upstream   string // Empty without a tracking branch
+	Ahead      int    // Commits not on the upstream
+	Behind     int    // Upstream commits not merged
+	Staged     int
+	Modified   int
+	Untracked  int
+	Conflicted int
+}
+
+// Dirty reports whether there are uncommitted or untracked changes
+func (s Status) Dirty() bool {
+	return s.Staged+s.Modified+s.Untracked+s.Conflicted > 0
+}
+
+// IsRepo reports whether dir is the top of a git working tree
+func IsRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// ReadStatus reads a repository's state with 'git status --porcelain=v2'
+func ReadStatus(dir string) (Status, error) {
+	output, err := runner.Output(runner.Command("git", "-C", dir,
+		"status", "--porcelain=v2", "--branch", "--untracked-files=normal"))
+	if err != nil {
+		return Status{}, err
+	}
+	return parseStatus(string(output)), nil
+}
+
+// parseStatus reads 'git status --porcelain=v2 --branch' output
+func parseStatus(output string) Status {
+	var s Status
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "#":
+			switch fields[1] {
+			case "branch.head":
+				if len(fields) > 2 && fields[2] != "(detached)" {
+					s.Branch = fields[2]
+				}
+			case "branch.upstream":
+				if len(fields) > 2 {
+					s.Upstream = fields[2]
+				}
+			case "branch.ab":
+				if len(fields) > 3 {
+					s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					s.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			}
+		case "1", "2":
+			// XY: staged and worktree state, "." when unchanged
+			if xy := fields[1]; len(xy) == 2 {
+				if xy[0] != '.' {
+					s.Staged++
+				}
+				if xy[1] != '.' {
+					s.Modified++
+				}
+			}
+		case "u":
+			s.Conflicted++
+		case "?":
+			s.Untracked++
+		}
+	}
+	return s
+}
+
+// StatusResult is one repository's status from ReadStatuses
+type StatusResult struct {
+	Status
+	Err error
+}
+
+// ReadStatuses reads the status of many repositories concurrently, a few
+// at a time, in the order given
+func ReadStatuses(dirs []string) []StatusResult {
+	results := make([]StatusResult, len(dirs))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i, dir := range dirs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s, err := ReadStatus(dir)
+			results[i] = StatusResult{Status: s, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
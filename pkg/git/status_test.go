@@ -0,0 +1,62 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestParseStatus(t *testing.T) {
+	output := `# branch.oid 1234567890abcdef
+# branch.head main
+# branch.upstream origin/main
+# branch.ab +2 -1
+1 M. N... 100644 100644 100644 abc abc staged.go
+1 .M N... 100644 100644 100644 abc abc modified.go
+1 MM N... 100644 100644 100644 abc abc both.go
+2 R. N... 100644 100644 100644 abc abc R100 new.go	old.go
+u UU N... 100644 100644 100644 100644 abc abc abc conflict.go
+? notes.txt
+? scratch/
+! ignored.log
+`
+	got := parseStatus(output)
+	want := Status{Branch: "main", Upstream: "origin/main", Ahead: 2, Behind: 1,
+		Staged: 3, Modified: 2, Untracked: 2, Conflicted: 1}
+	if got != want {
+		t.Errorf("parseStatus = %+v, want %+v", got, want)
+	}
+	if !got.Dirty() {
+		t.Error("should be dirty")
+	}
+}
+
+func TestParseStatusClean(t *testing.T) {
+	got := parseStatus("# branch.oid abc\n# branch.head (detached)\n")
+	if got != (Status{}) || got.Dirty() {
+		t.Errorf("parseStatus = %+v, want a clean detached HEAD", got)
+	}
+}
+
+func TestReadStatuses(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("git -C /a status", "# branch.head main\n? x\n", nil)
+	fake.On("git -C /b status", "", errors.New("not a git repository"))
+	fake.On("git -C /c status", "# branch.head dev\n", nil)
+	defer runner.Swap(fake)()
+
+	results := ReadStatuses([]string{"/a", "/b", "/c"})
+	if len(results) != 3 {
+		t.Fatalf("got %d results", len(results))
+	}
+	if results[0].Branch != "main" || results[0].Untracked != 1 || results[0].Err != nil {
+		t.Errorf("/a: %+v", results[0])
+	}
+	if results[1].Err == nil {
+		t.Errorf("/b should fail")
+	}
+	if results[2].Branch != "dev" {
+		t.Errorf("/c: %+v", results[2])
+	}
+}
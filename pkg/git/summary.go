@@ -0,0 +1,96 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// SummaryMaxAge is how long a repository's branch and dirty state is reused
+// for picker markers, unless git has touched its HEAD or index since
+const SummaryMaxAge = 2 * time.Minute
+
+// summaryPruneAge drops cached repositories no picker has shown in a while
+const summaryPruneAge = 7 * 24 * time.Hour
+
+// Summary is the branch and dirty state of a repository, for markers
+type Summary struct {
+	Branch string    `json:"branch"` // Empty when HEAD is detached
+	Dirty  bool      `json:"dirty"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+// GetSummaryCacheFile returns the path to the repository summary cache
+func GetSummaryCacheFile() (string, error) {
+	return statefile.Path("git-summaries.json")
+}
+
+// CachedSummaries returns summaries of the repositories among dirs, keyed by
+// directory. Only those whose cached summary is missing or stale are read,
+// concurrently; directories that aren't repositories are left out.
+func CachedSummaries(dirs []string) map[string]Summary {
+	cacheFile, cacheErr := GetSummaryCacheFile()
+	entries := make(map[string]Summary)
+	if cacheErr == nil {
+		if data, err := statefile.ReadFile(cacheFile); err == nil {
+			json.Unmarshal(data, &entries)
+		}
+	}
+
+	summaries := make(map[string]Summary)
+	var stale []string
+	for _, dir := range dirs {
+		if !IsRepo(dir) {
+			continue
+		}
+		if entry, ok := entries[dir]; ok && fresh(dir, entry) {
+			summaries[dir] = entry
+			continue
+		}
+		stale = append(stale, dir)
+	}
+	if len(stale) == 0 {
+		return summaries
+	}
+
+	// After reading: 'git status' may rewrite the index as it goes
+	results := ReadStatuses(stale)
+	now := time.Now()
+	for i, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		summary := Summary{Branch: r.Branch, Dirty: r.Dirty(), ReadAt: now}
+		summaries[stale[i]] = summary
+		entries[stale[i]] = summary
+	}
+
+	if cacheErr == nil {
+		for dir, entry := range entries {
+			if now.Sub(entry.ReadAt) > summaryPruneAge {
+				delete(entries, dir)
+			}
+		}
+		if data, err := json.MarshalIndent(entries, "", "  "); err == nil {
+			statefile.WriteFile(cacheFile, data, 0644)
+		}
+	}
+	return summaries
+}
+
+// fresh reports whether a cached summary can be reused: it's younger than
+// SummaryMaxAge and no checkout, commit or staging happened after it
+func fresh(dir string, s Summary) bool {
+	if time.Since(s.ReadAt) >= SummaryMaxAge {
+		return false
+	}
+	for _, name := range []string{"HEAD", "index"} {
+		if info, err := os.Stat(filepath.Join(dir, ".git", name)); err == nil && info.ModTime().After(s.ReadAt) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestCachedSummaries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	plain := filepath.Join(home, "plain")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.MkdirAll(plain, 0755)
+	past := time.Now().Add(-time.Hour)
+	os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+	os.Chtimes(filepath.Join(repo, ".git", "HEAD"), past, past)
+
+	fake := runner.NewFake()
+	fake.On("git -C "+repo+" status", "# branch.head main\n1 .M N... 100644 100644 100644 a b f\n", nil)
+	defer runner.Swap(fake)()
+
+	summaries := CachedSummaries([]string{repo, plain})
+	if len(summaries) != 1 {
+		t.Fatalf("summaries = %+v, want only the repository", summaries)
+	}
+	if s := summaries[repo]; s.Branch != "main" || !s.Dirty {
+		t.Errorf("summary = %+v", s)
+	}
+
+	// Reused while fresh
+	CachedSummaries([]string{repo})
+	if n := len(fake.Commands()); n != 1 {
+		t.Errorf("status read %d times, want 1: %v", n, fake.Commands())
+	}
+
+	// A checkout since invalidates it
+	now := time.Now().Add(time.Second)
+	os.Chtimes(filepath.Join(repo, ".git", "HEAD"), now, now)
+	CachedSummaries([]string{repo})
+	if n := len(fake.Commands()); n != 2 {
+		t.Errorf("status read %d times after checkout, want 2", n)
+	}
+}
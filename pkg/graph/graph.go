@@ -0,0 +1,222 @@
+// Package graph draws the portfolio as a graph: clients, the partners work
+// comes through, projects and the projects they depend on, for Graphviz
+// (DOT) or tools that import GraphML, such as Conduit
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// Node kinds
+const (
+	KindClient     = "client"
+	KindPartner    = "partner"
+	KindProject    = "project"
+	KindDependency = "dependency" // Depended on, but not among the projects
+)
+
+// Edge relations
+const (
+	RelationClient    = "client"     // project → client it's for
+	RelationPartner   = "partner"    // project → partner it came through
+	RelationServes    = "serves"     // partner → client
+	RelationDependsOn = "depends_on" // project → project ([relations] depends_on)
+)
+
+// Node is a client, partner or project
+type Node struct {
+	ID    string // Unique across kinds, e.g. "client:Acme Corp"
+	Label string
+	Kind  string
+}
+
+// Edge joins two nodes by ID
+type Edge struct {
+	From, To string
+	Relation string
+}
+
+// Graph is a set of nodes and edges, both sorted
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Build links projects to their clients and partners, partners to the
+// clients they serve, and projects to their dependencies
+func Build(projects []*config.Project) *Graph {
+	nodes := make(map[string]Node)
+	edges := make(map[Edge]bool)
+	add := func(kind, label string) string {
+		id := kind + ":" + label
+		nodes[id] = Node{ID: id, Label: label, Kind: kind}
+		return id
+	}
+
+	ids := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		ids[p.ProjectInfo.ID] = true
+	}
+
+	for _, p := range projects {
+		project := add(KindProject, p.ProjectInfo.ID)
+
+		var client, partner string
+		if name := p.GetClientName(); name != "" {
+			client = add(KindClient, name)
+			edges[Edge{project, client, RelationClient}] = true
+		}
+		if name := p.GetPartner(); name != "" {
+			partner = add(KindPartner, name)
+			edges[Edge{project, partner, RelationPartner}] = true
+		}
+		if client != "" && partner != "" {
+			edges[Edge{partner, client, RelationServes}] = true
+		}
+
+		for _, dep := range p.Relations.DependsOn {
+			dep = strings.TrimSpace(dep)
+			if dep == "" || dep == p.ProjectInfo.ID {
+				continue
+			}
+			// Dependencies share the project namespace, so one that is a
+			// project is the same node
+			target := KindProject + ":" + dep
+			if !ids[dep] {
+				nodes[target] = Node{ID: target, Label: dep, Kind: KindDependency}
+			}
+			edges[Edge{project, target, RelationDependsOn}] = true
+		}
+	}
+
+	g := &Graph{}
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	for e := range edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Relation < b.Relation
+	})
+	return g
+}
+
+// dotShapes styles each kind of node in DOT output
+var dotShapes = map[string]string{
+	KindClient:     `shape=box, style="rounded,filled", fillcolor="#dbeafe"`,
+	KindPartner:    `shape=box, style="rounded,filled", fillcolor="#fef3c7"`,
+	KindProject:    `shape=ellipse`,
+	KindDependency: `shape=ellipse, style=dashed`,
+}
+
+// WriteDOT writes the graph in Graphviz DOT, e.g. for 'dot -Tsvg'
+func WriteDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph portfolio {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, %s];\n", strconv.Quote(n.ID), strconv.Quote(n.Label), dotShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Relation == RelationDependsOn {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Relation), style)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// graphML is the GraphML document WriteGraphML encodes
+type graphML struct {
+	XMLName xml.Name    `xml:"graphml"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Keys    []graphKey  `xml:"key"`
+	Graph   graphMLBody `xml:"graph"`
+}
+
+type graphKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLBody struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string      `xml:"id,attr"`
+	Data []graphData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Data   []graphData `xml:"data"`
+}
+
+// WriteGraphML writes the graph as GraphML, with each node's label and kind
+// and each edge's relation as data attributes
+func WriteGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+		},
+		Graph: graphMLBody{ID: "portfolio", EdgeDefault: "directed"},
+	}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID:   n.ID,
+			Data: []graphData{{"label", n.Label}, {"kind", n.Kind}},
+		})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.From,
+			Target: e.To,
+			Data:   []graphData{{"relation", e.Relation}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
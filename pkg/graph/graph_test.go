@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func graphProject(id, client, partner string, dependsOn ...string) *config.Project {
+	p := &config.Project{}
+	p.ProjectInfo.ID = id
+	p.Consultant.ClientName = client
+	p.Consultant.Partner = partner
+	p.Relations.DependsOn = dependsOn
+	return p
+}
+
+func TestBuild(t *testing.T) {
+	g := Build([]*config.Project{
+		graphProject("etl", "Acme Corp", "West Monroe", "sdk", "vendor-lib", "etl"),
+		graphProject("dash", "Acme Corp", "West Monroe", "etl"),
+		graphProject("sdk", "", ""),
+	})
+
+	kinds := map[string]string{}
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+	want := map[string]string{
+		"client:Acme Corp":    KindClient,
+		"partner:West Monroe": KindPartner,
+		"project:etl":         KindProject,
+		"project:dash":        KindProject,
+		"project:sdk":         KindProject,
+		"project:vendor-lib":  KindDependency,
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("nodes = %v, want %v", kinds, want)
+	}
+	for id, kind := range want {
+		if kinds[id] != kind {
+			t.Errorf("node %s kind = %q, want %q", id, kinds[id], kind)
+		}
+	}
+
+	edges := map[Edge]bool{}
+	for _, e := range g.Edges {
+		edges[e] = true
+	}
+	for _, e := range []Edge{
+		{"project:etl", "client:Acme Corp", RelationClient},
+		{"project:etl", "partner:West Monroe", RelationPartner},
+		{"partner:West Monroe", "client:Acme Corp", RelationServes},
+		{"project:etl", "project:sdk", RelationDependsOn},
+		{"project:etl", "project:vendor-lib", RelationDependsOn},
+		{"project:dash", "project:etl", RelationDependsOn},
+	} {
+		if !edges[e] {
+			t.Errorf("missing edge %+v", e)
+		}
+	}
+	// Two projects through one partner serve the client once; no self loops
+	if len(g.Edges) != 8 {
+		t.Errorf("got %d edges, want 8: %+v", len(g.Edges), g.Edges)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := Build([]*config.Project{graphProject("etl", `Acme "Corp"`, "", "sdk")})
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph portfolio {",
+		`"client:Acme \"Corp\"" [label="Acme \"Corp\"", shape=box`,
+		`"project:etl" -> "project:sdk" [label="depends_on", style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	g := Build([]*config.Project{graphProject("etl", "R&D <Labs>", "")})
+	var buf bytes.Buffer
+	if err := WriteGraphML(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc graphML
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output isn't valid XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 {
+		t.Fatalf("got %d nodes, %d edges", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if doc.Graph.Nodes[0].ID != "client:R&D <Labs>" || doc.Graph.Nodes[0].Data[0].Value != "R&D <Labs>" {
+		t.Errorf("client node = %+v", doc.Graph.Nodes[0])
+	}
+	if e := doc.Graph.Edges[0]; e.Source != "project:etl" || e.Data[0].Value != RelationClient {
+		t.Errorf("edge = %+v", e)
+	}
+}
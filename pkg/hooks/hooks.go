@@ -1,23 +1,17 @@
 package hooks
 
 import (
-	"os"
-	"path/filepath"
-
 	"github.com/datakaicr/pk/pkg/cache"
+	"github.com/datakaicr/pk/pkg/paths"
 )
 
 // InvalidateCache triggers a cache rebuild after project modifications
 func InvalidateCache() {
-	homeDir, err := os.UserHomeDir()
+	resolver, err := paths.NewResolver()
 	if err != nil {
 		return
 	}
 
-	projectsDir := filepath.Join(homeDir, "projects")
-	archiveDir := filepath.Join(homeDir, "archive")
-	scriptoriumDir := filepath.Join(homeDir, "scriptorium")
-
 	// Rebuild cache in background
-	cache.RebuildCacheAsync(projectsDir, archiveDir, scriptoriumDir)
+	cache.RebuildCacheAsync(resolver.AllRoots()...)
 }
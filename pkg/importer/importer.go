@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// Candidate is a project found in another tool's registry
+type Candidate struct {
+	Name    string
+	Path    string
+	Windows []config.TmuxWindow
+	Source  string
+}
+
+// Source describes a supported registry format
+type Source struct {
+	Name        string
+	DefaultPath string // Relative to home directory
+	Description string
+	load        func(path string) ([]Candidate, error)
+}
+
+// Sources lists the supported registries in display order
+var Sources = []Source{
+	{
+		Name:        "projectile",
+		DefaultPath: ".emacs.d/projectile-bookmarks.eld",
+		Description: "Emacs projectile bookmarks file",
+		load:        loadProjectile,
+	},
+	{
+		Name:        "sesh",
+		DefaultPath: ".config/sesh/sesh.toml",
+		Description: "sesh session config",
+		load:        loadSesh,
+	},
+	{
+		Name:        "tmuxifier",
+		DefaultPath: ".tmuxifier/layouts",
+		Description: "tmuxifier layouts directory (*.session.sh)",
+		load:        loadTmuxifier,
+	},
+	{
+		Name:        "tmuxinator",
+		DefaultPath: ".config/tmuxinator",
+		Description: "tmuxinator configs directory (*.yml)",
+		load:        loadTmuxinator,
+	},
+}
+
+// FindSource returns the source with the given name
+func FindSource(name string) (*Source, error) {
+	for i := range Sources {
+		if Sources[i].Name == name {
+			return &Sources[i], nil
+		}
+	}
+
+	var names []string
+	for _, s := range Sources {
+		names = append(names, s.Name)
+	}
+	return nil, fmt.Errorf("unknown source '%s' (supported: %s)", name, strings.Join(names, ", "))
+}
+
+// Load reads candidates from path, or the source's default location if empty
+func (s *Source) Load(path string) ([]Candidate, error) {
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(homeDir, s.DefaultPath)
+	}
+
+	candidates, err := s.load(ExpandHome(path))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		candidates[i].Source = s.Name
+		candidates[i].Path = filepath.Clean(ExpandHome(candidates[i].Path))
+		if candidates[i].Name == "" {
+			candidates[i].Name = filepath.Base(candidates[i].Path)
+		}
+	}
+	return candidates, nil
+}
+
+// ExpandHome expands a leading ~ to the home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
+
+// Slug converts a name to a pk project ID (lowercase, dashes)
+func Slug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+	return strings.Trim(slug, "-")
+}
+
+// Conflicts returns, by candidate path, why a candidate can't be imported
+// under the ID derived from its name: a known project already has the ID,
+// or another candidate in the batch derives the same one. known maps the
+// IDs in use to their project paths.
+func Conflicts(candidates []Candidate, known map[string]string) map[string]string {
+	byID := make(map[string][]string)
+	for _, c := range candidates {
+		id := Slug(c.Name)
+		byID[id] = append(byID[id], c.Path)
+	}
+
+	conflicts := make(map[string]string)
+	for id, candidatePaths := range byID {
+		if path, taken := known[id]; taken {
+			for _, p := range candidatePaths {
+				conflicts[p] = fmt.Sprintf("ID '%s' already used by %s", id, path)
+			}
+			continue
+		}
+		if len(candidatePaths) > 1 {
+			for i, p := range candidatePaths {
+				other := candidatePaths[(i+1)%len(candidatePaths)]
+				conflicts[p] = fmt.Sprintf("ID '%s' also derived for %s", id, other)
+			}
+		}
+	}
+	return conflicts
+}
+
+// Project converts the candidate into pk metadata rooted at path
+func (c Candidate) Project(path string) *config.Project {
+	var project config.Project
+	project.Path = path
+
+	project.ProjectInfo.Name = c.Name
+	project.ProjectInfo.ID = Slug(c.Name)
+	project.ProjectInfo.Status = "active"
+	project.ProjectInfo.Type = "product"
+	project.Tech.Stack = []string{}
+	project.Tech.Domain = []string{}
+	project.Dates.Started = time.Now().Format("2006-01-02")
+	project.Notes.Description = fmt.Sprintf("Imported from %s", c.Source)
+	project.Tmux.Windows = c.Windows
+
+	return &project
+}
@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseProjectile(t *testing.T) {
+	content := `("~/projects/acme-etl/" "/opt/src/tool/" "")`
+
+	candidates := parseProjectile(content)
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(candidates))
+	}
+	if candidates[0].Path != "~/projects/acme-etl/" || candidates[1].Path != "/opt/src/tool/" {
+		t.Errorf("Unexpected paths: %+v", candidates)
+	}
+}
+
+func TestParseTmuxifier(t *testing.T) {
+	content := `# Set a custom session root path.
+session_root "~/Projects/dojo"
+
+if initialize_session "dojo"; then
+  new_window "editor"
+  run_cmd "nvim"
+  new_window "server"
+  run_cmd "cd web"
+  run_cmd "npm run dev"
+fi
+finalize_and_go_to_session
+`
+	c, ok := parseTmuxifier("dojo", content)
+	if !ok {
+		t.Fatal("Expected layout with session_root to be parsed")
+	}
+	if c.Path != "~/Projects/dojo" {
+		t.Errorf("Expected path ~/Projects/dojo, got %q", c.Path)
+	}
+	if len(c.Windows) != 2 {
+		t.Fatalf("Expected 2 windows, got %+v", c.Windows)
+	}
+	if c.Windows[1].Command != "cd web && npm run dev" {
+		t.Errorf("Expected joined commands, got %q", c.Windows[1].Command)
+	}
+
+	if _, ok := parseTmuxifier("bare", "new_window \"x\"\n"); ok {
+		t.Error("Expected layout without session_root to be skipped")
+	}
+}
+
+func TestLoadSesh(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	content := `[[session]]
+name = "Acme ETL"
+path = "~/work/acme"
+startup_command = "nvim"
+
+[[session]]
+name = "no path"
+`
+	file := filepath.Join(tmpDir, "sesh.toml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write sesh.toml: %v", err)
+	}
+
+	source, err := FindSource("sesh")
+	if err != nil {
+		t.Fatalf("FindSource failed: %v", err)
+	}
+
+	candidates, err := source.Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("Expected 1 candidate, got %+v", candidates)
+	}
+
+	c := candidates[0]
+	if c.Path != filepath.Join(tmpDir, "work", "acme") {
+		t.Errorf("Expected ~ expanded, got %q", c.Path)
+	}
+	if c.Source != "sesh" || len(c.Windows) != 1 || c.Windows[0].Command != "nvim" {
+		t.Errorf("Unexpected candidate: %+v", c)
+	}
+
+	project := c.Project(c.Path)
+	if project.ProjectInfo.ID != "acme-etl" {
+		t.Errorf("Expected slug acme-etl, got %q", project.ProjectInfo.ID)
+	}
+}
+
+func TestParseTmuxinator(t *testing.T) {
+	content := `# ~/.config/tmuxinator/dojo.yml
+name: dojo
+root: ~/code/dojo
+pre_window: nvm use
+
+windows:
+  - editor:
+      layout: main-vertical
+      panes:
+        - vim
+        - guard
+  - server: "bundle exec rails s"
+  - logs:
+    - cd log
+    - tail -f development.log
+  - docs:
+      root: ~/code/dojo/docs
+      panes:
+        - notes: [ls, make watch]
+`
+	c, ok, err := parseTmuxinator("file-name", content)
+	if !ok || err != nil {
+		t.Fatalf("Expected config with root to be parsed, got %v", err)
+	}
+	if c.Name != "dojo" || c.Path != "~/code/dojo" {
+		t.Errorf("Expected dojo at ~/code/dojo, got %q at %q", c.Name, c.Path)
+	}
+	if len(c.Windows) != 4 {
+		t.Fatalf("Expected 4 windows, got %+v", c.Windows)
+	}
+
+	editor := c.Windows[0]
+	if editor.Name != "editor" || editor.Command != "nvm use && vim" {
+		t.Errorf("Unexpected editor window: %+v", editor)
+	}
+	if len(editor.Panes) != 1 || editor.Panes[0].Split != "horizontal" || editor.Panes[0].Command != "nvm use && guard" {
+		t.Errorf("Expected one side-by-side guard pane, got %+v", editor.Panes)
+	}
+	if c.Windows[1].Command != "nvm use && bundle exec rails s" {
+		t.Errorf("Unexpected server command: %q", c.Windows[1].Command)
+	}
+	if c.Windows[2].Command != "nvm use && cd log && tail -f development.log" {
+		t.Errorf("Expected joined commands, got %q", c.Windows[2].Command)
+	}
+	if docs := c.Windows[3]; docs.Path != "~/code/dojo/docs" || docs.Command != "nvm use && ls && make watch" {
+		t.Errorf("Unexpected docs window: %+v", docs)
+	}
+
+	if _, ok, _ := parseTmuxinator("bare", "windows:\n  - shell:\n"); ok {
+		t.Error("Expected config without root to be skipped")
+	}
+}
+
+func TestParseTmuxinatorBlockScalars(t *testing.T) {
+	content := `name: dojo
+on_project_start: |
+  docker compose up -d  # not a comment
+
+  bin/setup
+pre_window: >-
+  source .env &&
+  nvm use
+root: ~/code/dojo
+windows:
+  - server: |
+      bundle install
+      bin/rails s
+  - notes:
+      panes:
+        - |+
+          vim notes.md
+
+`
+	c, ok, err := parseTmuxinator("file-name", content)
+	if !ok || err != nil {
+		t.Fatalf("Expected keys after block scalars to be read, got %+v, %v", c, err)
+	}
+	if c.Path != "~/code/dojo" || len(c.Windows) != 2 {
+		t.Fatalf("Unexpected config: %+v", c)
+	}
+	if want := "source .env && nvm use && bundle install && bin/rails s"; c.Windows[0].Command != want {
+		t.Errorf("server command = %q, want %q", c.Windows[0].Command, want)
+	}
+	if want := "source .env && nvm use && vim notes.md"; c.Windows[1].Command != want {
+		t.Errorf("notes command = %q, want %q", c.Windows[1].Command, want)
+	}
+
+	doc, err := parseYAML(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := doc.(map[string]any)
+	if got, want := m["on_project_start"], "docker compose up -d  # not a comment\n\nbin/setup\n"; got != want {
+		t.Errorf("literal block = %q, want %q", got, want)
+	}
+	if got, want := m["pre_window"], "source .env && nvm use"; got != want {
+		t.Errorf("folded block = %q, want %q", got, want)
+	}
+}
+
+func TestParseTmuxinatorReportsWhereItStopped(t *testing.T) {
+	content := "name: dojo\nwindows:\n  - shell: zsh\n bad: indent\nroot: ~/code/dojo\n"
+	_, ok, err := parseTmuxinator("dojo", content)
+	if ok {
+		t.Error("Expected the root after the unreadable line to be missed")
+	}
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("Expected an error naming line 4, got %v", err)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	candidates := []Candidate{
+		{Name: "acme-etl", Path: "/src/acme-etl"},
+		{Name: "Dojo", Path: "/src/dojo"},
+		{Name: "dojo", Path: "/old/dojo"},
+		{Name: "tool", Path: "/src/tool"},
+	}
+	known := map[string]string{"acme-etl": "/home/me/projects/acme-etl"}
+
+	conflicts := Conflicts(candidates, known)
+	if len(conflicts) != 3 {
+		t.Fatalf("Expected 3 conflicts, got %v", conflicts)
+	}
+	if !strings.Contains(conflicts["/src/acme-etl"], "/home/me/projects/acme-etl") {
+		t.Errorf("acme-etl conflict = %q, want the known project's path", conflicts["/src/acme-etl"])
+	}
+	if !strings.Contains(conflicts["/src/dojo"], "/old/dojo") || !strings.Contains(conflicts["/old/dojo"], "/src/dojo") {
+		t.Errorf("dojo conflicts should name each other: %v", conflicts)
+	}
+	if _, ok := conflicts["/src/tool"]; ok {
+		t.Error("tool has a free ID and should not conflict")
+	}
+}
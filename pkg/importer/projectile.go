@@ -0,0 +1,30 @@
+package importer
+
+import (
+	"os"
+	"regexp"
+)
+
+// Bookmarks are an elisp list of quoted strings: ("~/src/foo/" "~/src/bar/")
+var elispString = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+
+func loadProjectile(path string) ([]Candidate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProjectile(string(data)), nil
+}
+
+// parseProjectile extracts project directories from projectile-bookmarks.eld
+func parseProjectile(content string) []Candidate {
+	var candidates []Candidate
+	for _, match := range elispString.FindAllStringSubmatch(content, -1) {
+		if match[1] == "" {
+			continue
+		}
+		candidates = append(candidates, Candidate{Path: match[1]})
+	}
+	return candidates
+}
@@ -0,0 +1,44 @@
+package importer
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// seshConfig mirrors the parts of sesh.toml pk understands
+type seshConfig struct {
+	Sessions []struct {
+		Name           string `toml:"name"`
+		Path           string `toml:"path"`
+		StartupCommand string `toml:"startup_command"`
+	} `toml:"session"`
+}
+
+func loadSesh(path string) ([]Candidate, error) {
+	var cfg seshConfig
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return nil, err
+	}
+
+	return seshCandidates(cfg), nil
+}
+
+// seshCandidates converts [[session]] entries; a startup command becomes
+// the command of a single editor window
+func seshCandidates(cfg seshConfig) []Candidate {
+	var candidates []Candidate
+	for _, s := range cfg.Sessions {
+		if s.Path == "" {
+			continue
+		}
+
+		c := Candidate{Name: s.Name, Path: s.Path}
+		if s.StartupCommand != "" {
+			c.Windows = []config.TmuxWindow{
+				{Name: "main", Command: s.StartupCommand},
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
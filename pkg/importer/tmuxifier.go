@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+var (
+	tmuxifierRoot   = regexp.MustCompile(`^\s*session_root\s+["']?([^"']+)["']?`)
+	tmuxifierWindow = regexp.MustCompile(`^\s*new_window\s+["']?([^"']*)["']?`)
+	tmuxifierCmd    = regexp.MustCompile(`^\s*run_cmd\s+["'](.*)["']`)
+)
+
+func loadTmuxifier(path string) ([]Candidate, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.session.sh"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []Candidate
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".session.sh")
+		if c, ok := parseTmuxifier(name, string(data)); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, nil
+}
+
+// parseTmuxifier reads session_root, new_window and run_cmd from a
+// *.session.sh layout. Layouts without a session_root are skipped.
+func parseTmuxifier(name, content string) (Candidate, bool) {
+	c := Candidate{Name: name}
+
+	for _, line := range strings.Split(content, "\n") {
+		if m := tmuxifierRoot.FindStringSubmatch(line); m != nil {
+			c.Path = strings.TrimSpace(m[1])
+			continue
+		}
+		if m := tmuxifierWindow.FindStringSubmatch(line); m != nil {
+			c.Windows = append(c.Windows, config.TmuxWindow{Name: m[1]})
+			continue
+		}
+		// run_cmd applies to the most recent window
+		if m := tmuxifierCmd.FindStringSubmatch(line); m != nil && len(c.Windows) > 0 {
+			w := &c.Windows[len(c.Windows)-1]
+			if w.Command == "" {
+				w.Command = m[1]
+			} else {
+				w.Command += " && " + m[1]
+			}
+		}
+	}
+
+	return c, c.Path != ""
+}
@@ -0,0 +1,444 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func loadTmuxinator(path string) ([]Candidate, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yml", "*.yaml"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	}
+
+	var candidates []Candidate
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		c, ok, err := parseTmuxinator(name, string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", file, err)
+		}
+		if ok {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, nil
+}
+
+// parseTmuxinator reads name, root, pre_window and windows from a
+// tmuxinator config. Each window's first pane becomes the window command
+// and the rest become [[tmux.windows.panes]], split the way its layout
+// arranges them. Configs without a root are skipped. The error reports YAML
+// that couldn't be read; what came before it is still used.
+func parseTmuxinator(name, content string) (Candidate, bool, error) {
+	value, err := parseYAML(content)
+	doc, _ := value.(map[string]any)
+	c := Candidate{Name: name}
+	if doc == nil {
+		return c, false, err
+	}
+
+	if s := yamlString(doc["name"], doc["project_name"]); s != "" {
+		c.Name = s
+	}
+	c.Path = yamlString(doc["root"], doc["project_root"])
+	pre := yamlCommand(doc["pre_window"])
+
+	windows, _ := doc["windows"].([]any)
+	if windows == nil {
+		windows, _ = doc["tabs"].([]any) // Before tmuxinator 0.6
+	}
+	for _, item := range windows {
+		entry, ok := item.(map[string]any)
+		if !ok || len(entry) != 1 {
+			continue
+		}
+		for windowName, value := range entry {
+			c.Windows = append(c.Windows, tmuxinatorWindow(windowName, value, c.Path, pre))
+		}
+	}
+
+	return c, c.Path != "", err
+}
+
+// tmuxinatorWindow converts one entry of windows: a command, a list of
+// commands, or a map with root, layout and panes
+func tmuxinatorWindow(name string, value any, root, pre string) config.TmuxWindow {
+	w := config.TmuxWindow{Name: name}
+	settings, ok := value.(map[string]any)
+	if !ok {
+		w.Command = withPre(pre, yamlCommand(value))
+		return w
+	}
+
+	if dir := yamlString(settings["root"]); dir != "" && dir != root {
+		w.Path = dir
+	}
+	split := tmuxinatorSplit(yamlString(settings["layout"]))
+
+	panes, _ := settings["panes"].([]any)
+	for i, pane := range panes {
+		// A pane may be named: - logs: tail -f log/dev.log
+		if named, ok := pane.(map[string]any); ok && len(named) == 1 {
+			for _, commands := range named {
+				pane = commands
+			}
+		}
+		command := withPre(pre, yamlCommand(pane))
+		if i == 0 {
+			w.Command = command
+			continue
+		}
+		w.Panes = append(w.Panes, config.TmuxPane{Split: split, Command: command})
+	}
+	if len(panes) == 0 {
+		w.Command = pre
+	}
+	return w
+}
+
+// tmuxinatorSplit maps a tmux layout to the split that best approximates it
+func tmuxinatorSplit(layout string) string {
+	switch layout {
+	case "even-horizontal", "main-vertical":
+		return "horizontal"
+	case "even-vertical", "main-horizontal":
+		return "vertical"
+	}
+	return ""
+}
+
+// withPre runs pre_window before a command
+func withPre(pre, command string) string {
+	switch {
+	case pre == "":
+		return command
+	case command == "":
+		return pre
+	}
+	return pre + " && " + command
+}
+
+// yamlCommand flattens a command or list of commands into one command line
+func yamlCommand(value any) string {
+	list, ok := value.([]any)
+	if !ok {
+		list = []any{value}
+	}
+	var commands []string
+	for _, item := range list {
+		// A | block holds one command per line
+		for _, line := range strings.Split(yamlString(item), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commands = append(commands, line)
+			}
+		}
+	}
+	return strings.Join(commands, " && ")
+}
+
+// yamlString returns the first of values that is a non-empty scalar
+func yamlString(values ...any) string {
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// yamlLine is a significant line of a YAML document
+type yamlLine struct {
+	num    int // 1-based, for errors
+	indent int
+	text   string
+	block  *string // Content of the | or > block scalar the line ends with
+}
+
+// parseYAML parses the block-style YAML subset tmuxinator configs use:
+// nested maps and lists of scalars, with quoted scalars, | and > block
+// scalars and single-line [flow, lists]. Values are map[string]any, []any,
+// string, or nil. ERB tags are left as text. If it can't read the whole
+// document, it returns what it read up to there and an error naming the
+// line it stopped at.
+func parseYAML(content string) (any, error) {
+	raws := strings.Split(content, "\n")
+	var lines []yamlLine
+	for n := 0; n < len(raws); n++ {
+		text := stripYAMLComment(strings.TrimRight(raws[n], " \t\r"))
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		line := yamlLine{num: n + 1, indent: len(text) - len(trimmed), text: trimmed}
+		if header := yamlValue(trimmed); isYAMLBlockHeader(header) {
+			var block string
+			block, n = readYAMLBlockScalar(raws, n+1, line.indent, header)
+			line.block = &block
+			n--
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	value, next := parseYAMLBlock(lines, 0, lines[0].indent)
+	if next < len(lines) {
+		return value, fmt.Errorf("line %d: can't read %q, ignoring the rest of the file", lines[next].num, lines[next].text)
+	}
+	return value, nil
+}
+
+// yamlValue returns the inline value of a line: what follows its list
+// dashes and key
+func yamlValue(text string) string {
+	for {
+		switch {
+		case isYAMLItem(text):
+			text = strings.TrimLeft(strings.TrimPrefix(text, "-"), " ")
+		case yamlKey(text) != "":
+			return strings.TrimSpace(text[len(yamlKey(text))+1:])
+		default:
+			return text
+		}
+	}
+}
+
+// isYAMLBlockHeader reports whether a value opens a block scalar: | or >,
+// optionally with an indentation digit and a - or + chomping indicator
+func isYAMLBlockHeader(value string) bool {
+	if value == "" || (value[0] != '|' && value[0] != '>') || len(value) > 3 {
+		return false
+	}
+	for _, ch := range value[1:] {
+		if !strings.ContainsRune("-+123456789", ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// readYAMLBlockScalar reads the content of a block scalar from raws[i],
+// indented deeper than its parent, and returns it with the index of the
+// first line after it. Comments and blank lines in it are content.
+func readYAMLBlockScalar(raws []string, i, parent int, header string) (string, int) {
+	indent, chomp := 0, byte(0)
+	for _, ch := range header[1:] {
+		if ch == '-' || ch == '+' {
+			chomp = byte(ch)
+		} else {
+			indent = parent + int(ch-'0')
+		}
+	}
+
+	var body []string
+	for ; i < len(raws); i++ {
+		raw := strings.TrimRight(raws[i], " \t\r")
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" {
+			body = append(body, "")
+			continue
+		}
+		lineIndent := len(raw) - len(trimmed)
+		if indent == 0 {
+			if lineIndent <= parent {
+				break
+			}
+			indent = lineIndent
+		}
+		if lineIndent < indent {
+			break
+		}
+		body = append(body, raw[indent:])
+	}
+
+	trailing := 0
+	for len(body) > 0 && body[len(body)-1] == "" {
+		body = body[:len(body)-1]
+		trailing++
+	}
+	var b strings.Builder
+	for j, line := range body {
+		switch {
+		case j == 0:
+		case header[0] == '|' || line == "":
+			b.WriteByte('\n')
+		case body[j-1] != "":
+			b.WriteByte(' ') // > folds lines into one
+		}
+		b.WriteString(line)
+	}
+	value := b.String()
+	switch {
+	case value == "" || chomp == '-':
+	case chomp == '+':
+		value += strings.Repeat("\n", trailing+1)
+	default:
+		value += "\n"
+	}
+	return value, i
+}
+
+// parseYAMLBlock parses the map or list starting at lines[i], whose lines
+// are at indent, and returns it with the index of the first line after it
+func parseYAMLBlock(lines []yamlLine, i, indent int) (any, int) {
+	if isYAMLItem(lines[i].text) {
+		var list []any
+		for i < len(lines) && lines[i].indent == indent && isYAMLItem(lines[i].text) {
+			rest := strings.TrimLeft(strings.TrimPrefix(lines[i].text, "-"), " ")
+			switch {
+			case rest == "":
+				var item any
+				i++
+				if i < len(lines) && lines[i].indent > indent {
+					item, i = parseYAMLBlock(lines, i, lines[i].indent)
+				}
+				list = append(list, item)
+			case isYAMLItem(rest) || yamlKey(rest) != "":
+				// The item is a map or list starting on the dash's line
+				inner := indent + len(lines[i].text) - len(rest)
+				lines[i] = yamlLine{lines[i].num, inner, rest, lines[i].block}
+				var item any
+				item, i = parseYAMLBlock(lines, i, inner)
+				list = append(list, item)
+			case lines[i].block != nil:
+				list = append(list, *lines[i].block)
+				i++
+			default:
+				list = append(list, yamlScalar(rest))
+				i++
+			}
+		}
+		return list, i
+	}
+
+	m := make(map[string]any)
+	for i < len(lines) && lines[i].indent == indent && !isYAMLItem(lines[i].text) {
+		key := yamlKey(lines[i].text)
+		if key == "" {
+			i++ // Not a key; skip what we can't read
+			continue
+		}
+		rest := strings.TrimSpace(lines[i].text[len(key)+1:])
+		if lines[i].block != nil {
+			m[unquoteYAML(key)] = *lines[i].block
+			i++
+			continue
+		}
+		if rest == "" {
+			m[unquoteYAML(key)], i = parseYAMLChild(lines, i+1, indent)
+			continue
+		}
+		m[unquoteYAML(key)] = yamlScalar(rest)
+		i++
+	}
+	return m, i
+}
+
+// parseYAMLChild parses the value nested under a key at parent indent, or
+// returns nil if there is none. A list may sit at the same indent as its key.
+func parseYAMLChild(lines []yamlLine, i, parent int) (any, int) {
+	if i >= len(lines) {
+		return nil, i
+	}
+	next := lines[i]
+	if next.indent > parent || (next.indent == parent && isYAMLItem(next.text)) {
+		return parseYAMLBlock(lines, i, next.indent)
+	}
+	return nil, i
+}
+
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKey returns the key of a "key: value" or "key:" line, or ""
+func yamlKey(text string) string {
+	start := 0
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return ""
+		}
+		start = end + 2
+	}
+	for i := start; i < len(text); i++ {
+		switch {
+		case text[i] == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return text[:i]
+		case i == 0 && (text[i] == '[' || text[i] == '{'):
+			return ""
+		}
+	}
+	return ""
+}
+
+// yamlScalar parses an inline value: a quoted or plain string, or a
+// [flow, list] of them
+func yamlScalar(text string) any {
+	if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+		var list []any
+		for _, item := range strings.Split(text[1:len(text)-1], ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, unquoteYAML(item))
+			}
+		}
+		return list
+	}
+	if text == "~" || text == "null" {
+		return nil
+	}
+	return unquoteYAML(text)
+}
+
+func unquoteYAML(text string) string {
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		inner := text[1 : len(text)-1]
+		if text[0] == '\'' {
+			return strings.ReplaceAll(inner, "''", "'")
+		}
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(inner)
+	}
+	return text
+}
+
+// stripYAMLComment drops a # comment that isn't inside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '-' || line[i-1] == '[' {
+				quote = ch
+			}
+		case ch == '#' && (i == 0 || line[i-1] == ' '):
+			return strings.TrimRight(line[:i], " ")
+		}
+	}
+	return line
+}
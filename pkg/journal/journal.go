@@ -0,0 +1,149 @@
+// Package journal keeps short, timestamped notes per project: what was
+// going on the last time you looked ("blocked on client VPN"). Entries live
+// in the "journal" state document, so they follow the [state] backend like
+// pins and access history.
+package journal
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/statefile"
+	"github.com/datakaicr/pk/pkg/store"
+)
+
+// Entry kinds
+const (
+	KindAnnotation = "annotation"
+)
+
+// MaxEntries is how many entries are kept per project, oldest dropped first
+const MaxEntries = 500
+
+// Entry is one journal line
+type Entry struct {
+	ProjectID string    `json:"project_id"`
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Text      string    `json:"text"`
+}
+
+// Version is the layout of the journal document
+const Version = 1
+
+type journalFile struct {
+	Version int                `json:"version"`
+	Entries map[string][]Entry `json:"entries"` // By project ID, oldest first
+}
+
+func load() (journalFile, error) {
+	doc := journalFile{Entries: make(map[string][]Entry)}
+	if err := store.LoadJSON(store.Default(), "journal", &doc); err != nil {
+		return doc, err
+	}
+	if doc.Entries == nil {
+		doc.Entries = make(map[string][]Entry)
+	}
+	return doc, nil
+}
+
+// Annotate appends a note to a project's journal
+func Annotate(projectID, text string) (Entry, error) {
+	entry := Entry{
+		ProjectID: projectID,
+		Time:      time.Now(),
+		Kind:      KindAnnotation,
+		Text:      strings.Join(strings.Fields(text), " "),
+	}
+
+	defer statefile.Lock("journal")()
+
+	doc, err := load()
+	if err != nil {
+		return entry, err
+	}
+	entries := append(doc.Entries[projectID], entry)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	doc.Entries[projectID] = entries
+	doc.Version = Version
+
+	return entry, store.SaveJSON(store.Default(), "journal", doc)
+}
+
+// Rename moves a project's entries to a new ID, merging them by time with
+// any the new ID already has
+func Rename(oldID, newID string) error {
+	if oldID == newID {
+		return nil
+	}
+
+	defer statefile.Lock("journal")()
+
+	doc, err := load()
+	if err != nil {
+		return err
+	}
+	moved, ok := doc.Entries[oldID]
+	if !ok {
+		return nil
+	}
+	delete(doc.Entries, oldID)
+
+	entries := append(doc.Entries[newID], moved...)
+	for i := range entries {
+		entries[i].ProjectID = newID
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	doc.Entries[newID] = entries
+	doc.Version = Version
+
+	return store.SaveJSON(store.Default(), "journal", doc)
+}
+
+// Remove drops all of a project's entries
+func Remove(projectID string) error {
+	defer statefile.Lock("journal")()
+
+	doc, err := load()
+	if err != nil {
+		return err
+	}
+	if _, ok := doc.Entries[projectID]; !ok {
+		return nil
+	}
+	delete(doc.Entries, projectID)
+	doc.Version = Version
+
+	return store.SaveJSON(store.Default(), "journal", doc)
+}
+
+// Entries returns up to limit of a project's entries, newest first. A limit
+// of 0 returns all of them.
+func Entries(projectID string, limit int) ([]Entry, error) {
+	doc, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	all := doc.Entries[projectID]
+	var entries []Entry
+	for i := len(all) - 1; i >= 0 && (limit <= 0 || len(entries) < limit); i-- {
+		entries = append(entries, all[i])
+	}
+	return entries, nil
+}
+
+// Latest returns a project's newest entry, or nil if it has none
+func Latest(projectID string) (*Entry, error) {
+	entries, err := Entries(projectID, 1)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
@@ -0,0 +1,119 @@
+package journal
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnnotate(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	if latest, err := Latest("acme"); err != nil || latest != nil {
+		t.Fatalf("Latest on an empty journal = %v, %v", latest, err)
+	}
+
+	for _, note := range []string{"kickoff done", "blocked on\n client   VPN"} {
+		if _, err := Annotate("acme", note); err != nil {
+			t.Fatalf("Annotate: %v", err)
+		}
+	}
+	if _, err := Annotate("dojo", "unrelated"); err != nil {
+		t.Fatalf("Annotate: %v", err)
+	}
+
+	latest, err := Latest("acme")
+	if err != nil || latest == nil {
+		t.Fatalf("Latest = %v, %v", latest, err)
+	}
+	if latest.Text != "blocked on client VPN" || latest.Kind != KindAnnotation {
+		t.Errorf("Latest = %+v, want the newest note on one line", latest)
+	}
+
+	entries, err := Entries("acme", 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Text != "kickoff done" {
+		t.Errorf("Entries = %+v, want both notes newest first", entries)
+	}
+	if entries, _ := Entries("acme", 1); len(entries) != 1 {
+		t.Errorf("Entries with limit 1 returned %d", len(entries))
+	}
+}
+
+func TestAnnotateKeepsMaxEntries(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	for i := 0; i < MaxEntries+5; i++ {
+		if _, err := Annotate("acme", "note"); err != nil {
+			t.Fatalf("Annotate: %v", err)
+		}
+	}
+	entries, _ := Entries("acme", 0)
+	if len(entries) != MaxEntries {
+		t.Errorf("kept %d entries, want %d", len(entries), MaxEntries)
+	}
+}
+
+func TestRename(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	Annotate("prototype", "first")
+	Annotate("app", "already here")
+	Annotate("prototype", "second")
+
+	if err := Rename("prototype", "app"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if entries, _ := Entries("prototype", 0); len(entries) != 0 {
+		t.Errorf("old ID still has %d entries", len(entries))
+	}
+
+	entries, err := Entries("app", 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	var texts []string
+	for _, e := range entries {
+		if e.ProjectID != "app" {
+			t.Errorf("entry %q still has ID %q", e.Text, e.ProjectID)
+		}
+		texts = append(texts, e.Text)
+	}
+	if want := "second,already here,first"; strings.Join(texts, ",") != want {
+		t.Errorf("entries = %s, want %s (merged newest first)", strings.Join(texts, ","), want)
+	}
+
+	if err := Rename("missing", "other"); err != nil {
+		t.Errorf("Rename of an ID without entries: %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	Annotate("prototype", "first")
+	Annotate("app", "kept")
+
+	if err := Remove("prototype"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if entries, _ := Entries("prototype", 0); len(entries) != 0 {
+		t.Errorf("removed ID still has %d entries", len(entries))
+	}
+	if entries, _ := Entries("app", 0); len(entries) != 1 {
+		t.Errorf("other projects lost entries: %v", entries)
+	}
+	if err := Remove("missing"); err != nil {
+		t.Errorf("Remove of an unknown ID: %v", err)
+	}
+}
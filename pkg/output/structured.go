@@ -0,0 +1,155 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Output formats for the global --format flag
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// Formats lists the values the global --format flag accepts
+var Formats = []string{FormatTable, FormatJSON, FormatYAML}
+
+// Structured reports whether format is a machine-readable one (json or yaml)
+func Structured(format string) bool {
+	return format == FormatJSON || format == FormatYAML
+}
+
+// Encode writes v as indented JSON or as YAML. YAML is converted from the
+// JSON encoding, so json tags name the keys in both and keep their order.
+func Encode(w io.Writer, format string, v any) error {
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		_, err := w.Write(data.Bytes())
+		return err
+	case FormatYAML:
+		dec := json.NewDecoder(&data)
+		dec.UseNumber()
+		n, err := decodeNode(dec)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, strings.Join(n.yaml(), "\n")+"\n")
+		return err
+	}
+	return fmt.Errorf("unknown format '%s' (use %s)", format, strings.Join(Formats, ", "))
+}
+
+// node is a decoded JSON value with object keys in their encoded order
+type node struct {
+	scalar string // Rendered YAML scalar, when neither keys nor items are set
+	keys   []string
+	values []node
+	object bool
+	array  bool
+}
+
+func decodeNode(dec *json.Decoder) (node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return node{}, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		n := node{object: t == '{', array: t == '['}
+		for dec.More() {
+			if n.object {
+				key, err := dec.Token()
+				if err != nil {
+					return node{}, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			value, err := decodeNode(dec)
+			if err != nil {
+				return node{}, err
+			}
+			n.values = append(n.values, value)
+		}
+		_, err := dec.Token() // Closing delimiter
+		return n, err
+	case string:
+		return node{scalar: yamlString(t)}, nil
+	case json.Number:
+		return node{scalar: t.String()}, nil
+	case bool:
+		return node{scalar: strconv.FormatBool(t)}, nil
+	}
+	return node{scalar: "null"}, nil
+}
+
+// inline reports whether a node fits on its key's or dash's line
+func (n node) inline() bool {
+	return (!n.object && !n.array) || len(n.values) == 0
+}
+
+// yaml renders a node as lines of block-style YAML
+func (n node) yaml() []string {
+	switch {
+	case n.object && len(n.values) == 0:
+		return []string{"{}"}
+	case n.array && len(n.values) == 0:
+		return []string{"[]"}
+	case !n.object && !n.array:
+		return []string{n.scalar}
+	}
+
+	var lines []string
+	for i, value := range n.values {
+		child := value.yaml()
+		if n.object {
+			key := yamlString(n.keys[i])
+			if value.inline() {
+				lines = append(lines, key+": "+child[0])
+				continue
+			}
+			lines = append(lines, key+":")
+			for _, line := range child {
+				lines = append(lines, "  "+line)
+			}
+			continue
+		}
+
+		lines = append(lines, "- "+child[0])
+		for _, line := range child[1:] {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// plainScalar matches strings YAML reads back unchanged without quotes
+var plainScalar = regexp.MustCompile(`^[A-Za-z_/~.][A-Za-z0-9_ ./~@+()-]*$`)
+
+// yamlString renders s plain when that's unambiguous, else double-quoted
+// (a JSON string is a valid YAML double-quoted scalar)
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n", "~", ".inf", ".nan":
+		return strconv.Quote(s)
+	}
+	if plainScalar.MatchString(s) && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
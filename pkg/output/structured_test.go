@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	type session struct {
+		Name    string   `json:"name"`
+		Windows int      `json:"windows"`
+		Tags    []string `json:"tags"`
+		Path    string   `json:"path"`
+	}
+	v := map[string]any{
+		"sessions": []session{
+			{Name: "acme-etl", Windows: 2, Tags: []string{"dbt", "on"}, Path: "/p/acme etl"},
+			{Name: "true", Tags: []string{}, Path: "C: drive # 1"},
+		},
+		"empty": map[string]any{},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, FormatYAML, v); err != nil {
+		t.Fatal(err)
+	}
+	want := `empty: {}
+sessions:
+  - name: acme-etl
+    windows: 2
+    tags:
+      - dbt
+      - "on"
+    path: /p/acme etl
+  - name: "true"
+    windows: 0
+    tags: []
+    path: "C: drive # 1"
+`
+	if buf.String() != want {
+		t.Errorf("yaml =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Encode(&buf, FormatJSON, []string{"<a&b>"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[\n  \"<a&b>\"\n]\n"; buf.String() != want {
+		t.Errorf("json = %q, want %q", buf.String(), want)
+	}
+
+	if err := Encode(&buf, "xml", nil); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"acme-etl", "acme-etl"},
+		{"~/projects/dojo", "~/projects/dojo"},
+		{"~", `"~"`},
+		{"null", `"null"`},
+		{".inf", `".inf"`},
+		{".Inf", `".Inf"`},
+		{".NaN", `".NaN"`},
+		{"-.inf", `"-.inf"`},
+		{".gitignore", ".gitignore"},
+	}
+	for _, tt := range tests {
+		if got := yamlString(tt.in); got != tt.want {
+			t.Errorf("yamlString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
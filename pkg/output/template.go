@@ -0,0 +1,61 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available in user-provided --format templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// Funcs returns the helpers available to user templates
+func Funcs() template.FuncMap {
+	return templateFuncs
+}
+
+// ResolveFormat returns the template text for a --format value: a saved
+// format name from config, or the value itself as an inline template
+func ResolveFormat(format string, saved map[string]string) string {
+	if tmpl, exists := saved[format]; exists {
+		return tmpl
+	}
+	return format
+}
+
+// ParseTemplate parses a Go template, adding a trailing newline so each
+// rendered item ends up on its own line
+func ParseTemplate(text string) (*template.Template, error) {
+	// Allow "\t" and "\n" escapes, since shells make real tabs awkward
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderEach executes the template once per item
+func RenderEach[T any](w io.Writer, tmpl *template.Template, items []T) error {
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("format template: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func TestResolveFormat(t *testing.T) {
+	saved := map[string]string{"csv": "{{.ProjectInfo.ID}},{{.Path}}"}
+
+	if got := ResolveFormat("csv", saved); got != saved["csv"] {
+		t.Errorf("Expected saved format, got %q", got)
+	}
+	if got := ResolveFormat("{{.Path}}", saved); got != "{{.Path}}" {
+		t.Errorf("Expected inline template, got %q", got)
+	}
+}
+
+func TestRenderEach(t *testing.T) {
+	acme := &config.Project{Path: "/p/acme"}
+	acme.ProjectInfo.ID = "acme"
+	acme.Consultant.ClientName = "Acme Corp"
+	acme.Tech.Stack = []string{"python", "dbt"}
+
+	internal := &config.Project{Path: "/p/tool"}
+	internal.ProjectInfo.ID = "tool"
+
+	tmpl, err := ParseTemplate(`{{.ProjectInfo.ID}}\t{{default "-" .GetClientName}}\t{{join .Tech.Stack ","}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderEach(&buf, tmpl, []*config.Project{acme, internal}); err != nil {
+		t.Fatalf("RenderEach failed: %v", err)
+	}
+
+	expected := "acme\tAcme Corp\tpython,dbt\ntool\t-\t\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	if _, err := ParseTemplate("{{.Unclosed"); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
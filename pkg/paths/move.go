@@ -0,0 +1,81 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Move renames src to dst. Across filesystems (e.g. to an archive on an
+// encrypted volume) it copies the tree and then removes src.
+func Move(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies a directory tree, keeping modes, modification times and
+// symlinks. Special files (sockets, FIFOs) are skipped.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			// Writable while copying into it, whatever the original mode
+			return os.Mkdir(target, info.Mode().Perm()|0700)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+		return copyFile(path, target, info)
+	})
+}
+
+// copyFile copies one regular file, refusing to overwrite
+func copyFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
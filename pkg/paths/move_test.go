@@ -0,0 +1,87 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "acme")
+	if err := os.MkdirAll(filepath.Join(src, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(src, "src", "lib.go"), []byte("package src\n"), 0644)
+	os.Symlink("src/lib.go", filepath.Join(src, "link.go"))
+	old := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	os.Chtimes(filepath.Join(src, "run.sh"), old, old)
+
+	dst := filepath.Join(t.TempDir(), "acme")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dst, "src", "lib.go")); err != nil || string(data) != "package src\n" {
+		t.Errorf("src/lib.go = %q, %v", data, err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 || !info.ModTime().Equal(old) {
+		t.Errorf("run.sh mode %v, modified %v: want 0755 and %v", info.Mode().Perm(), info.ModTime(), old)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "link.go")); err != nil || link != "src/lib.go" {
+		t.Errorf("link.go -> %q, %v", link, err)
+	}
+}
+
+func TestMove(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a")
+	os.Mkdir(src, 0755)
+	os.WriteFile(filepath.Join(src, "f"), []byte("x"), 0644)
+
+	dst := filepath.Join(dir, "b")
+	if err := Move(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("src should be gone")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "f")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestArchives(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".config", "pk"), 0755)
+	os.WriteFile(filepath.Join(home, ".config", "pk", "config.toml"), []byte(`
+[[paths.archive_rules]]
+match = "owner=client"
+path = "/Volumes/Vault/archive"
+
+[[paths.archive_rules]]
+match = "visibility=private"
+path = "~/archive"
+`), 0644)
+
+	r, err := NewResolver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(home, "archive"), "/Volumes/Vault/archive"}
+	if got := r.Archives(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Archives() = %v, want %v", got, want)
+	}
+	if rules := r.ArchiveRules(); len(rules) != 2 || rules[1].Path != want[0] {
+		t.Errorf("ArchiveRules() = %+v", rules)
+	}
+	if roots := r.AllRoots(); len(roots) != 4 {
+		t.Errorf("AllRoots() = %v", roots)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/BurntSushi/toml"
 )
@@ -15,9 +16,20 @@ type Config struct {
 		Archive     string `toml:"archive"`
 		Scratch     string `toml:"scratch"`
 		Scriptorium string `toml:"scriptorium"`
+
+		// Where 'pk archive' moves projects matching each rule, first match
+		// wins; the rest go to archive
+		ArchiveRules []ArchiveRule `toml:"archive_rules"`
 	} `toml:"paths"`
 }
 
+// ArchiveRule sends projects matching a filter to their own archive
+// directory, e.g. client work to an encrypted volume
+type ArchiveRule struct {
+	Match string `toml:"match"` // Filter terms as in 'pk session --filter', e.g. "owner=client"
+	Path  string `toml:"path"`
+}
+
 // Resolver handles path resolution with config and defaults
 type Resolver struct {
 	homeDir     string
@@ -26,6 +38,8 @@ type Resolver struct {
 	archive     string
 	scratch     string
 	scriptorium string
+
+	archiveRules []ArchiveRule
 }
 
 // NewResolver creates a new path resolver
@@ -58,6 +72,14 @@ func NewResolver() (*Resolver, error) {
 	r.archive = r.resolvePath("archive", filepath.Join(homeDir, "archive"))
 	r.scratch = r.resolvePath("scratch", filepath.Join(homeDir, "scratch"))
 	r.scriptorium = r.resolvePath("scriptorium", filepath.Join(homeDir, "scriptorium"))
+	if r.config != nil {
+		for _, rule := range r.config.Paths.ArchiveRules {
+			if rule.Path != "" {
+				rule.Path = r.expand(rule.Path)
+				r.archiveRules = append(r.archiveRules, rule)
+			}
+		}
+	}
 
 	return r, nil
 }
@@ -84,13 +106,15 @@ func (r *Resolver) resolvePath(name, defaultPath string) string {
 	if configured == "" {
 		return defaultPath
 	}
+	return r.expand(configured)
+}
 
-	// Expand ~ to home directory
-	if configured[0] == '~' {
-		configured = filepath.Join(r.homeDir, configured[1:])
+// expand expands a leading ~ to the home directory
+func (r *Resolver) expand(path string) string {
+	if path[0] == '~' {
+		return filepath.Join(r.homeDir, path[1:])
 	}
-
-	return configured
+	return path
 }
 
 // Projects returns the projects directory path
@@ -103,6 +127,23 @@ func (r *Resolver) Archive() string {
 	return r.archive
 }
 
+// ArchiveRules returns the configured archive rules, paths expanded
+func (r *Resolver) ArchiveRules() []ArchiveRule {
+	return r.archiveRules
+}
+
+// Archives returns the archive directory and every archive rule's
+// directory, without duplicates
+func (r *Resolver) Archives() []string {
+	dirs := []string{r.archive}
+	for _, rule := range r.archiveRules {
+		if !slices.Contains(dirs, rule.Path) {
+			dirs = append(dirs, rule.Path)
+		}
+	}
+	return dirs
+}
+
 // Scratch returns the scratch directory path
 func (r *Resolver) Scratch() string {
 	return r.scratch
@@ -115,11 +156,8 @@ func (r *Resolver) Scriptorium() string {
 
 // AllRoots returns all root directories
 func (r *Resolver) AllRoots() []string {
-	return []string{
-		r.projects,
-		r.archive,
-		r.scriptorium,
-	}
+	roots := append([]string{r.projects}, r.Archives()...)
+	return append(roots, r.scriptorium)
 }
 
 // FindProject searches for a project by ID across all root directories
@@ -0,0 +1,422 @@
+package picker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// maxColumn caps the width of a column, as fzf's --tabstop=40 does
+const maxColumn = 40
+
+// Keys the built-in picker handles
+const (
+	keyNone = iota
+	keyRune
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyClear
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyLeft
+	keyRight
+	keySave
+)
+
+// key is one keypress: a kind, and the rune typed for keyRune
+type key struct {
+	kind int
+	r    rune
+}
+
+// match is a line that passes the query, with the positions (runes in its
+// display text) to highlight
+type match struct {
+	index     int
+	score     int
+	positions []int
+}
+
+// model is the built-in picker's state, kept apart from the terminal
+type model struct {
+	lines   []string // As given, returned on selection
+	display []string // Columns aligned
+	query   []rune
+	matches []match // Best first
+	cursor  int     // Index into matches
+	offset  int     // First match on screen
+}
+
+func newModel(lines []string) *model {
+	m := &model{lines: lines, display: alignColumns(lines)}
+	m.filter()
+	return m
+}
+
+// alignColumns pads tab-separated columns to a common width
+func alignColumns(lines []string) []string {
+	var widths []int
+	rows := make([][]string, len(lines))
+	for i, line := range lines {
+		rows[i] = strings.Split(line, "\t")
+		for j, cell := range rows[i][:len(rows[i])-1] {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], min(len([]rune(cell)), maxColumn-2))
+		}
+	}
+
+	display := make([]string, len(lines))
+	for i, cells := range rows {
+		var b strings.Builder
+		for j, cell := range cells {
+			if j == len(cells)-1 {
+				b.WriteString(cell)
+				break
+			}
+			runes := []rune(cell)
+			if len(runes) > widths[j] {
+				runes = append(runes[:widths[j]-1], '…')
+			}
+			b.WriteString(string(runes))
+			b.WriteString(strings.Repeat(" ", widths[j]-len(runes)+2))
+		}
+		display[i] = strings.TrimRight(b.String(), " ")
+	}
+	return display
+}
+
+// filter recomputes the matches for the query: lines containing every
+// space-separated term as a subsequence, tightest and earliest matches
+// first, ties in input order. Terms with an uppercase letter match case.
+func (m *model) filter() {
+	terms := strings.Fields(string(m.query))
+	m.matches = m.matches[:0]
+	for i, text := range m.display {
+		if mt, ok := fuzzyMatch([]rune(text), terms); ok {
+			mt.index = i
+			m.matches = append(m.matches, mt)
+		}
+	}
+	sort.SliceStable(m.matches, func(i, j int) bool { return m.matches[i].score < m.matches[j].score })
+	m.cursor, m.offset = 0, 0
+}
+
+// fuzzyMatch matches every term against text, scoring each by the gaps
+// inside its tightest occurrence and how far in it starts (lower is better)
+func fuzzyMatch(text []rune, terms []string) (match, bool) {
+	var mt match
+	for _, term := range terms {
+		pattern := []rune(term)
+		caseSensitive := strings.ToLower(term) != term
+
+		best := []int(nil)
+		for start := range text {
+			if !runeEqual(text[start], pattern[0], caseSensitive) {
+				continue
+			}
+			positions := []int{start}
+			for i, p := start+1, 1; i < len(text) && p < len(pattern); i++ {
+				if runeEqual(text[i], pattern[p], caseSensitive) {
+					positions = append(positions, i)
+					p++
+				}
+			}
+			if len(positions) < len(pattern) {
+				break // Later starts can't match either
+			}
+			if best == nil || span(positions) < span(best) {
+				best = positions
+			}
+		}
+		if best == nil {
+			return match{}, false
+		}
+		mt.score += (span(best)-len(pattern))*10 + best[0]
+		mt.positions = append(mt.positions, best...)
+	}
+	return mt, true
+}
+
+func span(positions []int) int {
+	return positions[len(positions)-1] - positions[0] + 1
+}
+
+func runeEqual(a, b rune, caseSensitive bool) bool {
+	if caseSensitive {
+		return a == b
+	}
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// handle applies a keypress; done is true once the user chose or cancelled
+func (m *model) handle(k key, pageSize int) (done, chosen bool) {
+	switch k.kind {
+	case keyEnter:
+		return true, len(m.matches) > 0
+	case keyCancel:
+		return true, false
+	case keyRune:
+		m.query = append(m.query, k.r)
+		m.filter()
+	case keyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.filter()
+		}
+	case keyClear:
+		m.query = m.query[:0]
+		m.filter()
+	case keyUp:
+		m.move(-1, pageSize)
+	case keyDown:
+		m.move(1, pageSize)
+	case keyPageUp:
+		m.move(-pageSize, pageSize)
+	case keyPageDown:
+		m.move(pageSize, pageSize)
+	}
+	return false, false
+}
+
+// move shifts the cursor by delta, scrolling to keep it on screen
+func (m *model) move(delta, pageSize int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.matches)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if pageSize > 0 && m.cursor >= m.offset+pageSize {
+		m.offset = m.cursor - pageSize + 1
+	}
+}
+
+// selected returns the line under the cursor
+func (m *model) selected() string {
+	return m.lines[m.matches[m.cursor].index]
+}
+
+// render draws the picker top-down from the home position, fzf --reverse
+// style: prompt, match count, header, then the list
+func (m *model) render(w io.Writer, opts Options, rows, cols int) {
+	var b strings.Builder
+	b.WriteString("\033[H")
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteString("\033[K\r\n")
+	}
+
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = "> "
+	}
+	// Save the cursor after the query (DECSC) to put it back there at the end
+	line(fmt.Sprintf("\033[36m%s\033[0m%s\0337", prompt, string(m.query)))
+	line(fmt.Sprintf("  \033[2m%d/%d\033[0m", len(m.matches), len(m.lines)))
+	if opts.Header != "" {
+		line("  \033[2m" + opts.Header + "\033[0m")
+	}
+
+	for i := m.offset; i < len(m.matches) && i < m.offset+m.pageSize(opts, rows); i++ {
+		mt := m.matches[i]
+		text := highlight([]rune(m.display[mt.index]), mt.positions, cols-2)
+		if i == m.cursor {
+			line("\033[1;31m>\033[0m \033[1m" + text + "\033[0m")
+		} else {
+			line("  " + text)
+		}
+	}
+	b.WriteString("\033[J\0338")
+	io.WriteString(w, b.String())
+}
+
+// pageSize is how many matches fit below the prompt, count and header,
+// leaving the last row free so the final newline doesn't scroll
+func (m *model) pageSize(opts Options, rows int) int {
+	used := 3
+	if opts.Header != "" {
+		used++
+	}
+	return max(1, rows-used)
+}
+
+// highlight colors the runes at positions, cut to width
+func highlight(text []rune, positions []int, width int) string {
+	if width > 0 && len(text) > width {
+		text = text[:width]
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+
+	var b strings.Builder
+	for i, r := range text {
+		if marked[i] {
+			b.WriteString("\033[32m")
+			b.WriteRune(r)
+			b.WriteString("\033[39m")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// readPickerKey reads one keypress. The terminal returns no input after a
+// tenth of a second, which tells a lone Esc from an arrow key's sequence.
+func readPickerKey(r *bufio.Reader) (key, error) {
+	c, _, err := r.ReadRune()
+	if err == io.EOF {
+		return key{}, nil // Timed out
+	}
+	if err != nil {
+		return key{}, err
+	}
+
+	switch c {
+	case '\r':
+		return key{kind: keyEnter}, nil
+	case 3, 7: // Ctrl-C, Ctrl-G
+		return key{kind: keyCancel}, nil
+	case 127, 8: // Backspace, Ctrl-H
+		return key{kind: keyBackspace}, nil
+	case 21: // Ctrl-U
+		return key{kind: keyClear}, nil
+	case 16, 11: // Ctrl-P, Ctrl-K
+		return key{kind: keyUp}, nil
+	case 14, '\n', '\t': // Ctrl-N, Ctrl-J, Tab
+		return key{kind: keyDown}, nil
+	case 19: // Ctrl-S
+		return key{kind: keySave}, nil
+	case 27: // Esc, or the start of an escape sequence
+		next, _, err := r.ReadRune()
+		if err != nil || (next != '[' && next != 'O') {
+			return key{kind: keyCancel}, nil
+		}
+		code, _, _ := r.ReadRune()
+		switch code {
+		case 'A':
+			return key{kind: keyUp}, nil
+		case 'B':
+			return key{kind: keyDown}, nil
+		case 'C':
+			return key{kind: keyRight}, nil
+		case 'D':
+			return key{kind: keyLeft}, nil
+		case 'Z': // Shift-Tab
+			return key{kind: keyUp}, nil
+		case '5', '6':
+			r.ReadRune() // Trailing ~
+			if code == '5' {
+				return key{kind: keyPageUp}, nil
+			}
+			return key{kind: keyPageDown}, nil
+		}
+		return key{}, nil
+	}
+	if unicode.IsPrint(c) {
+		return key{kind: keyRune, r: c}, nil
+	}
+	return key{}, nil
+}
+
+// terminal is the controlling terminal, unbuffered and without echo, showing
+// the alternate screen so it's left as it was
+type terminal struct {
+	tty        *os.File
+	input      *bufio.Reader
+	rows, cols int
+	restore    func()
+}
+
+// openTerminal takes over the terminal; Close gives it back
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("interactive use needs a terminal: %w", err)
+	}
+
+	stty := func(args ...string) ([]byte, error) {
+		cmd := runner.Command("stty", args...)
+		cmd.Stdin = tty
+		return runner.Output(cmd)
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("failed to read terminal settings: %w", err)
+	}
+	// -ixon lets Ctrl-S through instead of pausing output
+	if _, err := stty("-icanon", "-echo", "-isig", "-icrnl", "-ixon", "min", "0", "time", "1"); err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+
+	t := &terminal{tty: tty, input: bufio.NewReader(tty), rows: 24, cols: 80}
+	if size, err := stty("size"); err == nil {
+		if f := strings.Fields(string(size)); len(f) == 2 {
+			if n, err := strconv.Atoi(f[0]); err == nil && n > 0 {
+				t.rows = n
+			}
+			if n, err := strconv.Atoi(f[1]); err == nil && n > 0 {
+				t.cols = n
+			}
+		}
+	}
+
+	io.WriteString(tty, "\033[?1049h")
+	t.restore = func() {
+		io.WriteString(tty, "\033[?1049l")
+		stty(strings.TrimSpace(string(saved)))
+		tty.Close()
+	}
+	return t, nil
+}
+
+// Close restores the terminal
+func (t *terminal) Close() {
+	t.restore()
+}
+
+// pickBuiltin runs the built-in picker full screen on the terminal
+func pickBuiltin(lines []string, opts Options) (string, error) {
+	t, err := openTerminal()
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+
+	m := newModel(lines)
+	if opts.Query != "" {
+		m.query = []rune(opts.Query)
+		m.filter()
+	}
+	m.render(t.tty, opts, t.rows, t.cols)
+	for {
+		k, err := readPickerKey(t.input)
+		if err != nil {
+			return "", err
+		}
+		if k.kind == keyNone {
+			continue
+		}
+		if done, chosen := m.handle(k, m.pageSize(opts, t.rows)); done {
+			if !chosen {
+				return "", ErrCancelled
+			}
+			return m.selected(), nil
+		}
+		m.render(t.tty, opts, t.rows, t.cols)
+	}
+}
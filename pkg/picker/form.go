@@ -0,0 +1,266 @@
+package picker
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// FormField is one row of a form
+type FormField struct {
+	Key      string
+	Value    string
+	Choices  []string // Fixed values, chosen with ←/→ instead of typed ("" shows as unset)
+	Help     string   // Shown while the field is selected
+	ReadOnly bool
+}
+
+// FormOptions configure a form
+type FormOptions struct {
+	Title string
+
+	// Validate checks a new value before the field takes it; the error is
+	// shown and the field stays open. May be nil.
+	Validate func(key, value string) error
+}
+
+// formModel is the form's state, kept apart from the terminal
+type formModel struct {
+	fields   []FormField
+	validate func(key, value string) error
+	cursor   int
+	offset   int
+	editing  bool
+	input    []rune
+	message  string // Last error, shown until the next key
+	dirty    bool
+	quitting bool // Esc pressed once with unsaved changes
+}
+
+func newFormModel(fields []FormField, opts FormOptions) *formModel {
+	return &formModel{fields: slices.Clone(fields), validate: opts.Validate}
+}
+
+// handle applies a keypress; done is true once the user saved or cancelled
+func (m *formModel) handle(k key, pageSize int) (done, saved bool) {
+	m.message = ""
+	if m.editing {
+		m.handleInput(k)
+		return false, false
+	}
+
+	if k.kind != keyCancel {
+		m.quitting = false
+	}
+	field := &m.fields[m.cursor]
+	switch k.kind {
+	case keySave:
+		return true, true
+	case keyCancel:
+		if m.dirty && !m.quitting {
+			m.quitting = true
+			m.message = "Unsaved changes: Esc again to discard them, Ctrl-S to save"
+			return false, false
+		}
+		return true, false
+	case keyUp:
+		m.move(-1, pageSize)
+	case keyDown:
+		m.move(1, pageSize)
+	case keyPageUp:
+		m.move(-pageSize, pageSize)
+	case keyPageDown:
+		m.move(pageSize, pageSize)
+	case keyLeft, keyRight:
+		if len(field.Choices) > 0 && !field.ReadOnly {
+			delta := 1
+			if k.kind == keyLeft {
+				delta = -1
+			}
+			i := slices.Index(field.Choices, field.Value)
+			m.set(field.Choices[(i+delta+len(field.Choices))%len(field.Choices)])
+		}
+	case keyEnter:
+		switch {
+		case field.ReadOnly:
+			m.message = field.Key + " can't be changed here"
+		case len(field.Choices) > 0:
+			i := slices.Index(field.Choices, field.Value)
+			m.set(field.Choices[(i+1)%len(field.Choices)])
+		default:
+			m.editing = true
+			m.input = []rune(field.Value)
+		}
+	}
+	return false, false
+}
+
+// handleInput edits the value of the text field under the cursor
+func (m *formModel) handleInput(k key) {
+	switch k.kind {
+	case keyRune:
+		m.input = append(m.input, k.r)
+	case keyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case keyClear:
+		m.input = m.input[:0]
+	case keyCancel:
+		m.editing = false
+	case keyEnter, keySave:
+		if m.set(strings.TrimSpace(string(m.input))) {
+			m.editing = false
+		}
+	}
+}
+
+// set gives the field under the cursor a new value if it validates, and
+// reports whether it did
+func (m *formModel) set(value string) bool {
+	field := &m.fields[m.cursor]
+	if value == field.Value {
+		return true
+	}
+	if m.validate != nil {
+		if err := m.validate(field.Key, value); err != nil {
+			m.message = err.Error()
+			return false
+		}
+	}
+	field.Value = value
+	m.dirty = true
+	return true
+}
+
+// move shifts the cursor by delta, scrolling to keep it on screen
+func (m *formModel) move(delta, pageSize int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.fields)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if pageSize > 0 && m.cursor >= m.offset+pageSize {
+		m.offset = m.cursor - pageSize + 1
+	}
+}
+
+// pageSize is how many fields fit between the title and the help lines
+func (m *formModel) pageSize(rows int) int {
+	return max(1, rows-6)
+}
+
+// render draws the form: title, fields, then the selected field's help or
+// the last error, and the keys
+func (m *formModel) render(w io.Writer, opts FormOptions, rows, cols int) {
+	var b strings.Builder
+	b.WriteString("\033[H")
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteString("\033[K\r\n")
+	}
+
+	title := opts.Title
+	if m.dirty {
+		title += " \033[33m(modified)\033[0m"
+	}
+	line("\033[1m" + title + "\033[0m")
+	line("")
+
+	width := 0
+	for _, f := range m.fields {
+		width = max(width, len(f.Key))
+	}
+	for i := m.offset; i < len(m.fields) && i < m.offset+m.pageSize(rows); i++ {
+		f := m.fields[i]
+		value := f.Value
+		switch {
+		case i == m.cursor && m.editing:
+			value = "\033[4m" + string(m.input) + " \033[24m"
+		case value == "":
+			value = "\033[2m(unset)\033[22m"
+		}
+		if len(f.Choices) > 0 && i == m.cursor && !f.ReadOnly {
+			value = "‹ " + value + " ›"
+		}
+		if f.ReadOnly {
+			value = "\033[2m" + value + "\033[22m"
+		}
+
+		label := fmt.Sprintf("%-*s  ", width, f.Key)
+		if i == m.cursor {
+			line("\033[1;31m>\033[0m \033[1m" + label + "\033[0m" + value)
+		} else {
+			line("  " + label + value)
+		}
+	}
+	b.WriteString("\033[J")
+
+	// Help and keys stay at the bottom of the screen
+	var help string
+	switch {
+	case m.message != "":
+		help = "\033[31m" + m.message + "\033[0m"
+	case len(m.fields) > 0:
+		help = "\033[2m" + m.fields[m.cursor].Help + "\033[0m"
+	}
+	keys := "↑↓ move  enter edit  ←→ choose  ctrl-s save  esc cancel"
+	if m.editing {
+		keys = "enter keep  esc undo  ctrl-u clear"
+	}
+	fmt.Fprintf(&b, "\033[%d;1H%s\033[K\r\n\033[2m%s\033[0m\033[K", rows-1, truncate(help, cols), keys)
+	io.WriteString(w, b.String())
+}
+
+// truncate cuts text to width runes, ignoring escape sequences' length
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	visible := 0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\033' {
+			for i < len(runes) && runes[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		visible++
+		if visible > width {
+			return string(runes[:i]) + "\033[0m"
+		}
+	}
+	return text
+}
+
+// EditForm shows fields on the terminal for the user to change. It returns
+// the fields with their new values once saved, or ErrCancelled.
+func EditForm(fields []FormField, opts FormOptions) ([]FormField, error) {
+	if len(fields) == 0 {
+		return nil, ErrCancelled
+	}
+	t, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	m := newFormModel(fields, opts)
+	io.WriteString(t.tty, "\033[?25l") // Hide the cursor; the form marks its own
+	defer io.WriteString(t.tty, "\033[?25h")
+	m.render(t.tty, opts, t.rows, t.cols)
+	for {
+		k, err := readPickerKey(t.input)
+		if err != nil {
+			return nil, err
+		}
+		if k.kind == keyNone {
+			continue
+		}
+		if done, saved := m.handle(k, m.pageSize(t.rows)); done {
+			if !saved {
+				return nil, ErrCancelled
+			}
+			return m.fields, nil
+		}
+		m.render(t.tty, opts, t.rows, t.cols)
+	}
+}
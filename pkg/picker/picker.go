@@ -0,0 +1,91 @@
+// Package picker lets the user choose one line from a list: with fzf (or
+// skim) when it's installed, otherwise with a built-in fuzzy selector.
+// EditForm shows fields for the user to fill in on the same terminal.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// ErrCancelled is returned when the user leaves the picker without choosing
+var ErrCancelled = errors.New("selection cancelled")
+
+// Options configure a picker. Lines are tab-separated columns, aligned in
+// the built-in picker as fzf's --tabstop would.
+type Options struct {
+	Prompt  string   // e.g. "⚡ Project: "
+	Header  string   // Shown above the list
+	Query   string   // Typed in to start with
+	Backend string   // fzf, sk or builtin; empty for fzf when installed
+	FzfArgs []string // Extra fzf/sk flags (layout, preview); the built-in picker ignores them
+	Bind    []string // fzf/sk key bindings, e.g. "ctrl-o:execute(code {1})"
+}
+
+// Backends a picker can run with
+const (
+	BackendFzf     = "fzf"
+	BackendSkim    = "sk"
+	BackendBuiltin = "builtin"
+)
+
+// Backends lists the accepted Options.Backend values
+var Backends = []string{BackendFzf, BackendSkim, BackendBuiltin}
+
+// Pick shows lines and returns the one chosen, or ErrCancelled. A backend
+// that isn't installed falls back to the built-in picker.
+func Pick(lines []string, opts Options) (string, error) {
+	if len(lines) == 0 {
+		return "", ErrCancelled
+	}
+	switch backend := opts.Backend; backend {
+	case BackendBuiltin:
+	case "", BackendFzf, BackendSkim:
+		if backend == "" {
+			backend = BackendFzf
+		}
+		if deps.Available(backend) {
+			return pickFzf(backend, lines, opts)
+		}
+	default:
+		return "", fmt.Errorf("unknown picker backend %q (want %s)", backend, strings.Join(Backends, ", "))
+	}
+	return pickBuiltin(lines, opts)
+}
+
+// pickFzf runs fzf, or skim with the same flags, over lines
+func pickFzf(program string, lines []string, opts Options) (string, error) {
+	args := append([]string{}, opts.FzfArgs...)
+	if opts.Prompt != "" {
+		args = append(args, "--prompt", opts.Prompt)
+	}
+	if opts.Header != "" {
+		args = append(args, "--header", opts.Header)
+	}
+	if opts.Query != "" {
+		args = append(args, "--query", opts.Query)
+	}
+	for _, bind := range opts.Bind {
+		args = append(args, "--bind", bind)
+	}
+
+	cmd := runner.Command(program, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	cmd.Stderr = os.Stderr
+	output, err := runner.Output(cmd)
+	if err != nil {
+		// Esc, Ctrl-C or no match
+		return "", ErrCancelled
+	}
+
+	selection := strings.TrimRight(string(output), "\n")
+	if selection == "" {
+		return "", ErrCancelled
+	}
+	return selection, nil
+}
@@ -0,0 +1,213 @@
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestAlignColumns(t *testing.T) {
+	got := alignColumns([]string{
+		"api\t[acme]\tactive\t●",
+		"data-platform\t[datakai]\tpaused\t",
+	})
+	want := []string{
+		"api            [acme]     active  ●",
+		"data-platform  [datakai]  paused",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("alignColumns =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFilterRanksTightMatchesFirst(t *testing.T) {
+	m := newModel([]string{"pipeline-keeper", "dbt-warehouse", "pk", "ops-kit"})
+
+	for _, r := range "pk" {
+		m.handle(key{kind: keyRune, r: r}, 10)
+	}
+	var got []string
+	for _, mt := range m.matches {
+		got = append(got, m.lines[mt.index])
+	}
+	if want := []string{"pk", "ops-kit", "pipeline-keeper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matches = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(m.matches[0].positions, []int{0, 1}) {
+		t.Errorf("positions = %v", m.matches[0].positions)
+	}
+
+	// Every term must match; uppercase matches case
+	m = newModel([]string{"acme-etl\t[acme]", "acme-api\t[acme]", "globex-etl"})
+	m.query = []rune("etl acme")
+	m.filter()
+	if len(m.matches) != 1 || m.lines[m.matches[0].index] != "acme-etl\t[acme]" {
+		t.Errorf("'etl acme' should only match acme-etl, got %v", m.matches)
+	}
+	m.query = []rune("ETL")
+	m.filter()
+	if len(m.matches) != 0 {
+		t.Errorf("uppercase should match case, got %v", m.matches)
+	}
+}
+
+func TestHandleKeys(t *testing.T) {
+	m := newModel([]string{"a", "b", "c", "d"})
+
+	m.handle(key{kind: keyDown}, 2)
+	m.handle(key{kind: keyDown}, 2)
+	if m.cursor != 2 || m.offset != 1 {
+		t.Errorf("cursor %d offset %d, want 2 1", m.cursor, m.offset)
+	}
+	m.handle(key{kind: keyPageDown}, 2)
+	if m.cursor != 3 {
+		t.Errorf("page down should stop at the last match, got %d", m.cursor)
+	}
+	if done, chosen := m.handle(key{kind: keyEnter}, 2); !done || !chosen || m.selected() != "d" {
+		t.Errorf("enter = %v %v %q", done, chosen, m.selected())
+	}
+
+	m.handle(key{kind: keyRune, r: 'z'}, 2)
+	if done, chosen := m.handle(key{kind: keyEnter}, 2); !done || chosen {
+		t.Error("enter with no matches should cancel")
+	}
+	m.handle(key{kind: keyBackspace}, 2)
+	if len(m.matches) != 4 || m.cursor != 0 {
+		t.Errorf("backspace should restore every line, got %d", len(m.matches))
+	}
+	if done, chosen := m.handle(key{kind: keyCancel}, 2); !done || chosen {
+		t.Error("cancel should end without a selection")
+	}
+}
+
+func TestReadPickerKey(t *testing.T) {
+	input := bufio.NewReader(strings.NewReader("x\033[A\033[B\033[6~\r\x7f\x03é\t\x13\033[C\033[D\033[Z\033"))
+	want := []key{
+		{kind: keyRune, r: 'x'},
+		{kind: keyUp},
+		{kind: keyDown},
+		{kind: keyPageDown},
+		{kind: keyEnter},
+		{kind: keyBackspace},
+		{kind: keyCancel},
+		{kind: keyRune, r: 'é'},
+		{kind: keyDown}, // Tab
+		{kind: keySave},
+		{kind: keyRight},
+		{kind: keyLeft},
+		{kind: keyUp},     // Shift-Tab
+		{kind: keyCancel}, // Lone Esc
+		{},                // Timed out
+	}
+	for i, w := range want {
+		if got, err := readPickerKey(input); err != nil || got != w {
+			t.Errorf("key %d = %+v (%v), want %+v", i, got, err, w)
+		}
+	}
+}
+
+func TestFormKeys(t *testing.T) {
+	fields := []FormField{
+		{Key: "project.id", Value: "etl", ReadOnly: true},
+		{Key: "project.name", Value: "ETL"},
+		{Key: "project.status", Value: "active", Choices: []string{"active", "paused", "archived"}},
+	}
+	validate := func(key, value string) error {
+		if value == "" {
+			return errors.New(key + " is required")
+		}
+		return nil
+	}
+	m := newFormModel(fields, FormOptions{Validate: validate})
+
+	m.handle(key{kind: keyEnter}, 10)
+	if m.editing || m.message == "" {
+		t.Error("read-only fields shouldn't open for editing")
+	}
+
+	// Clearing a required field fails validation and keeps it open
+	m.handle(key{kind: keyDown}, 10)
+	m.handle(key{kind: keyEnter}, 10)
+	m.handle(key{kind: keyClear}, 10)
+	m.handle(key{kind: keyEnter}, 10)
+	if !m.editing || m.message != "project.name is required" {
+		t.Errorf("editing %v message %q, want the validation error", m.editing, m.message)
+	}
+	for _, r := range "Pipeline" {
+		m.handle(key{kind: keyRune, r: r}, 10)
+	}
+	m.handle(key{kind: keyEnter}, 10)
+	if m.editing || m.fields[1].Value != "Pipeline" {
+		t.Errorf("name = %q (editing %v), want Pipeline", m.fields[1].Value, m.editing)
+	}
+
+	m.handle(key{kind: keyDown}, 10)
+	m.handle(key{kind: keyLeft}, 10)
+	if got := m.fields[2].Value; got != "archived" {
+		t.Errorf("← should wrap to the last choice, got %q", got)
+	}
+	if fields[1].Value != "ETL" {
+		t.Error("the form shouldn't change the caller's fields")
+	}
+
+	// Unsaved changes take a second Esc to discard
+	if done, _ := m.handle(key{kind: keyCancel}, 10); done {
+		t.Error("first Esc with changes should only warn")
+	}
+	if done, saved := m.handle(key{kind: keyCancel}, 10); !done || saved {
+		t.Errorf("second Esc = %v %v, want cancelled", done, saved)
+	}
+	if done, saved := m.handle(key{kind: keySave}, 10); !done || !saved {
+		t.Errorf("ctrl-s = %v %v, want saved", done, saved)
+	}
+}
+
+func TestPickUsesFzf(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("fzf", "acme-etl\t[acme]\n", nil)
+	defer runner.Swap(fake)()
+
+	got, err := Pick([]string{"api\t[none]", "acme-etl\t[acme]"}, Options{
+		Prompt:  "Project: ",
+		FzfArgs: []string{"--reverse"},
+	})
+	if err != nil || got != "acme-etl\t[acme]" {
+		t.Errorf("Pick = %q, %v", got, err)
+	}
+	if want := "fzf --reverse --prompt Project: "; fake.Commands()[0] != want {
+		t.Errorf("command = %q, want %q", fake.Commands()[0], want)
+	}
+
+	fake = runner.NewFake()
+	fake.On("fzf", "", errors.New("exit status 130"))
+	runner.Swap(fake)
+	if _, err := Pick([]string{"api"}, Options{}); err != ErrCancelled {
+		t.Errorf("fzf exiting should cancel, got %v", err)
+	}
+}
+
+func TestPickBackends(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("sk", "api\n", nil)
+	defer runner.Swap(fake)()
+
+	got, err := Pick([]string{"api"}, Options{
+		Backend: BackendSkim,
+		Query:   "ap",
+		Bind:    []string{"ctrl-o:execute(code {1})"},
+	})
+	if err != nil || got != "api" {
+		t.Errorf("Pick = %q, %v", got, err)
+	}
+	if want := "sk --query ap --bind ctrl-o:execute(code {1})"; fake.Commands()[0] != want {
+		t.Errorf("command = %q, want %q", fake.Commands()[0], want)
+	}
+
+	if _, err := Pick([]string{"api"}, Options{Backend: "peco"}); err == nil || !strings.Contains(err.Error(), "peco") {
+		t.Errorf("unknown backend: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package progress shows that a long operation is still going: a spinner
+// for work of unknown length, or a counter with an ETA for a known number of
+// items. It draws a single line on stderr, only on a terminal, and only
+// once the work has run longer than Delay, so quick commands, pipes and
+// scripts see nothing. Quiet (--quiet) turns it off.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// Quiet suppresses all progress output
+	Quiet bool
+
+	// Delay is how long work runs before progress is drawn
+	Delay = 300 * time.Millisecond
+
+	// ReportAfter is how long work must take for its time to be reported
+	// when it finishes
+	ReportAfter = 2 * time.Second
+)
+
+// Overridable in tests
+var (
+	output     io.Writer = os.Stderr
+	isTerminal           = stderrIsTerminal
+)
+
+var frames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// Task is one operation in progress. The zero value and tasks started
+// while Quiet draw nothing, so callers never need to check.
+type Task struct {
+	label string
+	total int // 0 for a spinner
+	start time.Time
+	live  bool // Animated on a terminal
+	log   bool // Log each item when not live
+
+	mu      sync.Mutex
+	current int // 1-based index of the item being worked on
+	item    string
+	visible bool
+	frame   int
+
+	stop     chan struct{}
+	finished chan struct{}
+}
+
+// Spinner starts a task of unknown length
+func Spinner(label string) *Task {
+	return start(label, 0)
+}
+
+// Counter starts a task of total items; call Next before each
+func Counter(label string, total int) *Task {
+	return start(label, total)
+}
+
+func start(label string, total int) *Task {
+	t := &Task{label: label, total: total, start: time.Now()}
+	if Quiet {
+		return t
+	}
+	t.live = isTerminal()
+	if t.live {
+		t.stop = make(chan struct{})
+		t.finished = make(chan struct{})
+		go t.run()
+	}
+	return t
+}
+
+func (t *Task) run() {
+	defer close(t.finished)
+
+	delay := time.NewTimer(Delay)
+	select {
+	case <-t.stop:
+		delay.Stop()
+		return
+	case <-delay.C:
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		t.mu.Lock()
+		t.draw()
+		t.mu.Unlock()
+
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Logged makes a counter log each item on its own line when stderr isn't a
+// terminal, for commands whose output ends up in logs
+func (t *Task) Logged() *Task {
+	t.log = true
+	return t
+}
+
+// Next starts the next item, named item
+func (t *Task) Next(item string) {
+	if t == nil || Quiet {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.current++
+	t.item = item
+	switch {
+	case t.live && t.visible:
+		t.draw()
+	case !t.live && t.log && t.total > 0:
+		fmt.Fprintf(output, "\033[2m[%d/%d] %s %s...\033[0m\n", t.current, t.total, t.label, item)
+	}
+}
+
+// Printf prints to stdout without the progress line getting in the way
+func (t *Task) Printf(format string, args ...interface{}) {
+	if t == nil {
+		fmt.Printf(format, args...)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clear()
+	fmt.Printf(format, args...)
+}
+
+// Stop ends the task, erasing its line. Work that took longer than
+// ReportAfter reports its time.
+func (t *Task) Stop() {
+	if t == nil || t.stop == nil {
+		return
+	}
+	select {
+	case <-t.stop:
+		return // Already stopped
+	default:
+		close(t.stop)
+	}
+	<-t.finished
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	shown := t.visible
+	t.clear()
+	if elapsed := time.Since(t.start); shown && elapsed >= ReportAfter {
+		fmt.Fprintf(output, "\033[2m%s: %s\033[0m\n", t.label, formatDuration(elapsed))
+	}
+}
+
+// draw renders the progress line; the caller holds mu
+func (t *Task) draw() {
+	elapsed := time.Since(t.start)
+	line := fmt.Sprintf("%c %s", frames[t.frame%len(frames)], t.label)
+	t.frame++
+
+	if t.total > 0 {
+		line += fmt.Sprintf(" [%d/%d]", max(t.current, 1), t.total)
+		if t.item != "" {
+			line += " " + t.item
+		}
+	}
+	line += " · " + formatDuration(elapsed)
+	if done := t.current - 1; t.total > 0 && done > 0 {
+		remaining := elapsed / time.Duration(done) * time.Duration(t.total-done)
+		line += " · ETA " + formatDuration(remaining)
+	}
+
+	fmt.Fprintf(output, "\r\033[K\033[2m%s\033[0m", line)
+	t.visible = true
+}
+
+// clear erases the progress line; the caller holds mu
+func (t *Task) clear() {
+	if t.visible {
+		fmt.Fprint(output, "\r\033[K")
+		t.visible = false
+	}
+}
+
+// formatDuration rounds to tenths of a second below a minute, seconds above
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// stderrIsTerminal reports whether stderr is an interactive terminal
+func stderrIsTerminal() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
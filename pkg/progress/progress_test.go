@@ -0,0 +1,98 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture sends progress output to a buffer, as a terminal or not
+func capture(terminal bool) (*bytes.Buffer, func()) {
+	var buf bytes.Buffer
+	origOutput, origTerminal, origDelay := output, isTerminal, Delay
+	output = &buf
+	isTerminal = func() bool { return terminal }
+	Delay = 0
+	return &buf, func() {
+		output, isTerminal, Delay = origOutput, origTerminal, origDelay
+	}
+}
+
+func TestLoggedCounterOffTerminal(t *testing.T) {
+	buf, restore := capture(false)
+	defer restore()
+
+	task := Counter("Warming", 2).Logged()
+	task.Next("dojo")
+	task.Next("api")
+	task.Stop()
+
+	want := "\033[2m[1/2] Warming dojo...\033[0m\n\033[2m[2/2] Warming api...\033[0m\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	task = Counter("Importing", 2)
+	task.Next("dojo")
+	Spinner("Scanning").Stop()
+	task.Stop()
+	if buf.Len() != 0 {
+		t.Errorf("unlogged tasks off a terminal should print nothing, got %q", buf.String())
+	}
+}
+
+func TestCounterOnTerminal(t *testing.T) {
+	buf, restore := capture(true)
+	defer restore()
+
+	task := Counter("Opening", 4)
+	task.Next("dojo")
+	time.Sleep(20 * time.Millisecond)
+	task.Next("api")
+	time.Sleep(150 * time.Millisecond)
+	task.Stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "Opening [2/4] api") || !strings.Contains(out, "ETA") {
+		t.Errorf("progress line missing count or ETA: %q", out)
+	}
+	if !strings.HasSuffix(out, "\r\033[K") {
+		t.Errorf("Stop should erase the progress line: %q", out)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	buf, restore := capture(true)
+	defer restore()
+	Quiet = true
+	defer func() { Quiet = false }()
+
+	task := Counter("Warming", 1).Logged()
+	task.Next("dojo")
+	time.Sleep(150 * time.Millisecond)
+	task.Stop()
+	if buf.Len() != 0 {
+		t.Errorf("Quiet should suppress progress, got %q", buf.String())
+	}
+}
+
+func TestNilTask(t *testing.T) {
+	var task *Task
+	task.Next("dojo")
+	task.Stop()
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		1500 * time.Millisecond: "1.5s",
+		59 * time.Second:        "59.0s",
+		90 * time.Second:        "1m30s",
+	}
+	for d, want := range tests {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Package registry shares project metadata with teammates. The registry is
+// one document on a team server ([team] url, the same REST protocol as the
+// http state backend) holding every member's projects; syncing pushes your
+// own edits and reports what others changed since you last pulled.
+package registry
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/datakaicr/pk/pkg/store"
+)
+
+// document is the registry's name on the team server
+const document = "registry"
+
+// maxHistory bounds the changes kept locally for 'pk changes'
+const maxHistory = 1000
+
+// Record is one project's metadata as last pushed
+type Record struct {
+	Fields map[string]string `json:"fields"` // Dotted key -> value, as 'pk config get' shows it
+	By     string            `json:"by"`
+	At     time.Time         `json:"at"`
+}
+
+// Registry is the shared document: project ID -> record
+type Registry struct {
+	Projects map[string]Record `json:"projects"`
+}
+
+// Change is one edit a teammate made, as seen on a pull
+type Change struct {
+	ProjectID string    `json:"project_id"`
+	By        string    `json:"by"`
+	At        time.Time `json:"at"`
+	Key       string    `json:"key,omitempty"` // Empty when the project is new to the registry
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	PulledAt  time.Time `json:"pulled_at"`
+}
+
+// String describes the change, e.g. "alice archived acme-etl"
+func (c Change) String() string {
+	switch {
+	case c.Key == "":
+		return fmt.Sprintf("%s added %s", c.By, c.ProjectID)
+	case c.Key == "project.status" && c.To == "archived":
+		return fmt.Sprintf("%s archived %s", c.By, c.ProjectID)
+	case c.To == "":
+		return fmt.Sprintf("%s cleared %s of %s", c.By, label(c.Key), c.ProjectID)
+	}
+	return fmt.Sprintf("%s changed %s of %s to %s", c.By, label(c.Key), c.ProjectID, c.To)
+}
+
+// label names a field in a sentence: datakai.client_name -> client name
+func label(key string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	return strings.ReplaceAll(key, "_", " ")
+}
+
+// Fields returns the metadata of a project the registry shares
+func Fields(p *config.Project) map[string]string {
+	fields := make(map[string]string)
+	for _, f := range p.Fields() {
+		fields[f.Key] = f.Value
+	}
+	return fields
+}
+
+// Diff returns the changes between two pulls of the registry made by
+// anyone but self, ordered by project and key
+func Diff(before, after Registry, self string) []Change {
+	var changes []Change
+	for _, id := range slices.Sorted(maps.Keys(after.Projects)) {
+		rec := after.Projects[id]
+		if rec.By == self {
+			continue
+		}
+		prev, existed := before.Projects[id]
+		if !existed {
+			changes = append(changes, Change{ProjectID: id, By: rec.By, At: rec.At})
+			continue
+		}
+		keys := slices.Collect(maps.Keys(rec.Fields))
+		for key := range prev.Fields {
+			if _, ok := rec.Fields[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prev.Fields[key] != rec.Fields[key] {
+				changes = append(changes, Change{ProjectID: id, By: rec.By, At: rec.At,
+					Key: key, From: prev.Fields[key], To: rec.Fields[key]})
+			}
+		}
+	}
+	return changes
+}
+
+// state is what this machine remembers between syncs, kept in local JSON
+// state (never on the team server)
+type state struct {
+	PulledAt time.Time                    `json:"pulled_at"`
+	Seen     Registry                     `json:"seen"`   // The registry as last pulled
+	Pushed   map[string]map[string]string `json:"pushed"` // Fields last pushed, per project ID
+	Changes  []Change                     `json:"changes"`
+}
+
+const stateDocument = "team-registry"
+
+func loadState() (state, error) {
+	var s state
+	err := store.LoadJSON(store.NewJSONStore(), stateDocument, &s)
+	return s, err
+}
+
+func saveState(s state) error {
+	return store.SaveJSON(store.NewJSONStore(), stateDocument, s)
+}
+
+// Open connects to the team server configured in [team]
+func Open(s *settings.Settings) (store.Store, error) {
+	if s.Team.URL == "" {
+		return nil, fmt.Errorf("no team registry configured (set [team] url in ~/.config/pk/config.toml)")
+	}
+	token := ""
+	if s.Team.TokenEnv != "" {
+		token = os.Getenv(s.Team.TokenEnv)
+	}
+	return store.NewHTTPStore(s.Team.URL, token)
+}
+
+// User returns your name in the registry: [team] user, or $USER
+func User(s *settings.Settings) string {
+	if s.Team.User != "" {
+		return s.Team.User
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "unknown"
+}
+
+// Result is what a sync did
+type Result struct {
+	Changes   []Change // Teammates' edits since the last pull
+	Pushed    []string // IDs of your projects pushed
+	Withdrawn []string // IDs of your projects taken off the registry, no longer public
+}
+
+// Shared reports whether a project's metadata may go to the team server:
+// only projects marked public. Private, client-confidential and projects
+// without a visibility never leave this machine.
+func Shared(p *config.Project) bool {
+	return p.DataKai.Visibility == "public"
+}
+
+// Sync pulls the registry, records teammates' changes since the last pull,
+// and pushes the projects you've edited since you last pushed them. A
+// project someone else changed isn't overwritten unless you edit it too.
+// Projects that aren't Shared are never pushed, and if you pushed one
+// before it stopped being public, your record of it is withdrawn.
+func Sync(remote store.Store, projects []*config.Project, user string, now time.Time) (Result, error) {
+	local, err := loadState()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read local registry state: %w", err)
+	}
+
+	var reg Registry
+	if err := store.LoadJSON(remote, document, &reg); err != nil {
+		return Result{}, fmt.Errorf("failed to pull the registry: %w", err)
+	}
+	if reg.Projects == nil {
+		reg.Projects = make(map[string]Record)
+	}
+
+	var result Result
+	if !local.PulledAt.IsZero() {
+		result.Changes = Diff(local.Seen, reg, user)
+	}
+	for i := range result.Changes {
+		result.Changes[i].PulledAt = now
+	}
+
+	if local.Pushed == nil {
+		local.Pushed = make(map[string]map[string]string)
+	}
+	for _, p := range projects {
+		id := p.ProjectInfo.ID
+		if !Shared(p) {
+			if _, pushed := local.Pushed[id]; pushed {
+				if reg.Projects[id].By == user {
+					delete(reg.Projects, id)
+					result.Withdrawn = append(result.Withdrawn, id)
+				}
+				delete(local.Pushed, id)
+			}
+			continue
+		}
+		fields := Fields(p)
+		if maps.Equal(fields, local.Pushed[id]) {
+			continue
+		}
+		if rec, ok := reg.Projects[id]; ok && maps.Equal(fields, rec.Fields) {
+			local.Pushed[id] = fields
+			continue
+		}
+		reg.Projects[id] = Record{Fields: fields, By: user, At: now}
+		local.Pushed[id] = fields
+		result.Pushed = append(result.Pushed, id)
+	}
+
+	if len(result.Pushed) > 0 || len(result.Withdrawn) > 0 {
+		if err := store.SaveJSON(remote, document, reg); err != nil {
+			return result, fmt.Errorf("failed to push the registry: %w", err)
+		}
+	}
+
+	local.PulledAt = now
+	local.Seen = reg
+	local.Changes = append(local.Changes, result.Changes...)
+	if len(local.Changes) > maxHistory {
+		local.Changes = local.Changes[len(local.Changes)-maxHistory:]
+	}
+	return result, saveState(local)
+}
+
+// LastPull returns when the registry was last pulled (zero if never)
+func LastPull() (time.Time, error) {
+	local, err := loadState()
+	return local.PulledAt, err
+}
+
+// Changes returns the teammates' changes pulled at or after since, oldest
+// first
+func Changes(since time.Time) ([]Change, error) {
+	local, err := loadState()
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, c := range local.Changes {
+		if !c.PulledAt.Before(since) {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
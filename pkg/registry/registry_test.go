@@ -0,0 +1,209 @@
+package registry
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/store"
+)
+
+// memStore is a team server in memory
+type memStore struct {
+	mu   sync.Mutex
+	docs map[string][]byte
+}
+
+func (s *memStore) Get(name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.docs[name]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *memStore) Put(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[name] = data
+	return nil
+}
+
+func (s *memStore) Name() string { return "memory" }
+
+func project(id, status, maturity string) *config.Project {
+	p := &config.Project{}
+	p.ProjectInfo.ID = id
+	p.ProjectInfo.Name = id
+	p.ProjectInfo.Status = status
+	p.DataKai.Maturity = maturity
+	p.DataKai.Visibility = "public"
+	return p
+}
+
+// as switches to a teammate's machine (their local state)
+func as(t *testing.T, homes map[string]string, user string) {
+	t.Helper()
+	if homes[user] == "" {
+		homes[user] = t.TempDir()
+	}
+	t.Setenv("HOME", homes[user])
+}
+
+func TestSyncDigest(t *testing.T) {
+	remote := &memStore{docs: make(map[string][]byte)}
+	homes := make(map[string]string)
+	now := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	as(t, homes, "carol")
+	if _, err := Sync(remote, nil, "carol", now); err != nil {
+		t.Fatal(err)
+	}
+
+	as(t, homes, "alice")
+	etl := project("acme-etl", "active", "mvp")
+	if r, err := Sync(remote, []*config.Project{etl}, "alice", now); err != nil || len(r.Pushed) != 1 {
+		t.Fatalf("alice's first sync: %+v, %v", r, err)
+	}
+
+	as(t, homes, "bob")
+	conduit := project("conduit", "active", "mvp")
+	if _, err := Sync(remote, []*config.Project{conduit}, "bob", now); err != nil {
+		t.Fatal(err)
+	}
+
+	as(t, homes, "carol")
+	r, err := Sync(remote, nil, "carol", now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Changes) != 2 || r.Changes[0].String() != "alice added acme-etl" {
+		t.Fatalf("carol's digest: %v", r.Changes)
+	}
+
+	as(t, homes, "alice")
+	etl.ProjectInfo.Status = "archived"
+	if _, err := Sync(remote, []*config.Project{etl}, "alice", now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	as(t, homes, "bob")
+	conduit.DataKai.Maturity = "production"
+	r, err = Sync(remote, []*config.Project{conduit}, "bob", now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bob joined after alice pushed, so he only sees her archive it, and not
+	// his own edit
+	var seen []string
+	for _, c := range r.Changes {
+		seen = append(seen, c.String())
+	}
+	if got := strings.Join(seen, "; "); got != "alice archived acme-etl" {
+		t.Errorf("bob's digest = %q", got)
+	}
+
+	as(t, homes, "carol")
+	pulled := now.Add(3 * time.Hour)
+	r, err = Sync(remote, nil, "carol", pulled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen = nil
+	for _, c := range r.Changes {
+		seen = append(seen, c.String())
+	}
+	want := "alice archived acme-etl; bob changed maturity of conduit to production"
+	if got := strings.Join(seen, "; "); got != want {
+		t.Errorf("carol's digest = %q, want %q", got, want)
+	}
+
+	last, err := LastPull()
+	if err != nil || !last.Equal(pulled) {
+		t.Errorf("LastPull = %v, %v", last, err)
+	}
+	changes, err := Changes(last)
+	if err != nil || len(changes) != 2 {
+		t.Errorf("Changes(last pull) = %v, %v", changes, err)
+	}
+	if changes, _ := Changes(now); len(changes) != 4 {
+		t.Errorf("Changes(all) = %d changes, want 4", len(changes))
+	}
+}
+
+func TestSyncKeepsOthersEdits(t *testing.T) {
+	remote := &memStore{docs: make(map[string][]byte)}
+	homes := make(map[string]string)
+	now := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	// Both have shared a checkout of the same project
+	as(t, homes, "alice")
+	mine := project("conduit", "active", "mvp")
+	Sync(remote, []*config.Project{mine}, "alice", now)
+	as(t, homes, "bob")
+	theirs := project("conduit", "active", "mvp")
+	Sync(remote, []*config.Project{theirs}, "bob", now)
+
+	theirs.DataKai.Maturity = "production"
+	Sync(remote, []*config.Project{theirs}, "bob", now.Add(time.Hour))
+
+	// Alice hasn't touched hers, so her stale copy doesn't undo bob's edit
+	as(t, homes, "alice")
+	r, err := Sync(remote, []*config.Project{mine}, "alice", now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Pushed) != 0 || len(r.Changes) != 1 {
+		t.Errorf("alice's sync: %+v", r)
+	}
+	var reg Registry
+	store.LoadJSON(remote, document, &reg)
+	if rec := reg.Projects["conduit"]; rec.By != "bob" || rec.Fields["datakai.maturity"] != "production" {
+		t.Errorf("registry record = %+v", rec)
+	}
+}
+
+func TestSyncNeverPushesPrivate(t *testing.T) {
+	remote := &memStore{docs: make(map[string][]byte)}
+	homes := make(map[string]string)
+	now := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	as(t, homes, "alice")
+
+	public := project("conduit", "active", "mvp")
+	private := project("acme-etl", "active", "mvp")
+	private.DataKai.Visibility = "private"
+	private.Consultant.ClientName = "Acme"
+	confidential := project("globex", "active", "mvp")
+	confidential.DataKai.Visibility = "client-confidential"
+	unset := project("initech", "active", "mvp")
+	unset.DataKai.Visibility = ""
+	unset.Notes.Description = "Initech billing rewrite"
+
+	r, err := Sync(remote, []*config.Project{public, private, confidential, unset}, "alice", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(r.Pushed, ",") != "conduit" {
+		t.Errorf("pushed %v, want [conduit]", r.Pushed)
+	}
+	if doc := string(remote.docs[document]); strings.Contains(doc, "acme-etl") || strings.Contains(doc, "Acme") || strings.Contains(doc, "globex") ||
+		strings.Contains(doc, "initech") || strings.Contains(doc, "Initech") {
+		t.Errorf("private projects on the team server:\n%s", doc)
+	}
+
+	// A project made private after it was shared is withdrawn
+	public.DataKai.Visibility = "private"
+	r, err = Sync(remote, []*config.Project{public, private, confidential}, "alice", now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Pushed) != 0 || strings.Join(r.Withdrawn, ",") != "conduit" {
+		t.Errorf("second sync: %+v", r)
+	}
+	if doc := string(remote.docs[document]); strings.Contains(doc, "conduit") {
+		t.Errorf("withdrawn project still on the team server:\n%s", doc)
+	}
+}
@@ -0,0 +1,196 @@
+// Package report builds status reports from what pk records about projects:
+// git history, roadmap progress, session time and journal notes
+package report
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/journal"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// Status is a report on a group of projects (a client's) over a period
+type Status struct {
+	Client   string
+	From, To time.Time
+	Projects []ProjectStatus
+	Time     time.Duration // Tracked across every project
+}
+
+// ProjectStatus is what happened in one project over the period
+type ProjectStatus struct {
+	Project    *config.Project
+	Milestones []string        // Roadmap items ticked off
+	Commits    []string        // Subjects, newest first
+	Time       time.Duration   // Attached to the project's session
+	Notes      []journal.Entry // Oldest first
+}
+
+// Active reports whether anything happened in the project
+func (s ProjectStatus) Active() bool {
+	return len(s.Milestones) > 0 || len(s.Commits) > 0 || s.Time > 0 || len(s.Notes) > 0
+}
+
+// Collect gathers each project's activity between from and to. Intervals
+// are session time (see events.Intervals); projects with no activity are
+// kept unless archived.
+func Collect(client string, projects []*config.Project, intervals []events.Interval, from, to time.Time) Status {
+	status := Status{Client: client, From: from, To: to}
+
+	tracked := make(map[string]time.Duration)
+	for _, i := range intervals {
+		tracked[i.ProjectID] += i.Duration()
+	}
+
+	for _, p := range projects {
+		ps := ProjectStatus{
+			Project:    p,
+			Milestones: milestones(p, from, to),
+			Commits:    commits(p.Path, from, to),
+			Time:       tracked[p.ProjectInfo.ID],
+			Notes:      notes(p.ProjectInfo.ID, from, to),
+		}
+		if !ps.Active() && p.ProjectInfo.Status == "archived" {
+			continue
+		}
+		status.Projects = append(status.Projects, ps)
+		status.Time += ps.Time
+	}
+
+	// Busiest first
+	sort.SliceStable(status.Projects, func(i, j int) bool {
+		a, b := status.Projects[i], status.Projects[j]
+		if a.Time != b.Time {
+			return a.Time > b.Time
+		}
+		return len(a.Commits) > len(b.Commits)
+	})
+	return status
+}
+
+// gitLog runs git log in dir over the period, or returns "" if dir isn't a
+// repository
+func gitLog(dir string, from, to time.Time, args ...string) string {
+	args = append([]string{"-C", dir, "log",
+		"--since=" + from.Format(time.RFC3339), "--until=" + to.Format(time.RFC3339)}, args...)
+	output, err := runner.Output(runner.Command("git", args...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commits returns the subjects of non-merge commits in the period
+func commits(dir string, from, to time.Time) []string {
+	output := gitLog(dir, from, to, "--no-merges", "--format=%s")
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// milestones returns the items of the project's roadmap (dev.roadmap)
+// ticked off in the period, per its git history
+func milestones(p *config.Project, from, to time.Time) []string {
+	if p.Dev.Roadmap == "" {
+		return nil
+	}
+	return tickedItems(gitLog(p.Path, from, to, "--reverse", "-p", "--format=", "--", p.Dev.Roadmap))
+}
+
+// checkedItem is a ticked Markdown task list item
+var checkedItem = regexp.MustCompile(`^\s*[-*] \[[xX]\]\s+(.+)$`)
+
+// tickedItems reads a diff for task list items added as checked, in order,
+// once each
+func tickedItems(diff string) []string {
+	var items []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(diff, "\n") {
+		added, ok := strings.CutPrefix(line, "+")
+		if !ok || strings.HasPrefix(added, "++") {
+			continue
+		}
+		if m := checkedItem.FindStringSubmatch(added); m != nil && !seen[m[1]] {
+			seen[m[1]] = true
+			items = append(items, strings.TrimSpace(m[1]))
+		}
+	}
+	return items
+}
+
+// notes returns the project's journal entries in the period, oldest first
+func notes(projectID string, from, to time.Time) []journal.Entry {
+	entries, err := journal.Entries(projectID, 0)
+	if err != nil {
+		return nil
+	}
+	var inPeriod []journal.Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if t := entries[i].Time; !t.Before(from) && !t.After(to) {
+			inPeriod = append(inPeriod, entries[i])
+		}
+	}
+	return inPeriod
+}
+
+// DefaultTemplate is the report layout unless the user has their own
+const DefaultTemplate = `# {{.Client}}: status for {{date .From}} to {{date .To}}
+{{range .Projects}}
+## {{.Project.ProjectInfo.Name}}
+{{if .Milestones}}
+Milestones:
+{{range .Milestones}}- ✅ {{.}}
+{{end}}{{end}}
+{{- if .Commits}}
+Work ({{len .Commits}} commit{{if ne (len .Commits) 1}}s{{end}}):
+{{range first 8 .Commits}}- {{.}}
+{{end}}{{if gt (len .Commits) 8}}- …and {{sub (len .Commits) 8}} more
+{{end}}{{end}}
+{{- if .Notes}}
+Notes:
+{{range .Notes}}- {{date .Time}}: {{.Text}}
+{{end}}{{end}}
+{{- if .Time}}
+Time: {{hours .Time}}
+{{end}}
+{{- if not .Active}}
+No activity this period.
+{{end}}{{end}}
+{{- if .Time}}
+Total time: {{hours .Time}}
+{{end}}`
+
+// templateFuncs are available in report templates
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("Mon Jan 2")
+	},
+	"hours": func(d time.Duration) string {
+		return fmt.Sprintf("%.1fh", d.Hours())
+	},
+	"first": func(n int, items []string) []string {
+		return items[:min(n, len(items))]
+	},
+	"sub": func(a, b int) int {
+		return a - b
+	},
+	"join": strings.Join,
+}
+
+// Render writes a status report using a text/template layout
+func Render(w io.Writer, layout string, status Status) error {
+	tmpl, err := template.New("report").Funcs(templateFuncs).Parse(layout)
+	if err != nil {
+		return fmt.Errorf("report template: %w", err)
+	}
+	return tmpl.Execute(w, status)
+}
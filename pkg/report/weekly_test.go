@@ -0,0 +1,82 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/journal"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestTickedItems(t *testing.T) {
+	diff := `diff --git a/.dev/ROADMAP.md b/.dev/ROADMAP.md
+--- a/.dev/ROADMAP.md
++++ b/.dev/ROADMAP.md
+-- [ ] Load customers
++- [x] Load customers
++- [ ] Load orders
++  * [X] Nested check
+ - [x] Already done
++- [x] Load customers`
+
+	want := []string{"Load customers", "Nested check"}
+	if got := tickedItems(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("tickedItems = %q, want %q", got, want)
+	}
+}
+
+func TestCollectAndRender(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	fake := runner.NewFake()
+	fake.On("git -C /p/etl log", "Add orders load\nFix dedupe", nil)
+	fake.On("git -C /p/etl log --since=2025-01-06T00:00:00Z --until=2025-01-11T00:00:00Z --reverse -p", "+- [x] Orders in the warehouse", nil)
+	fake.On("git -C /p/old", "", nil)
+	defer runner.Swap(fake)()
+
+	etl := &config.Project{Path: "/p/etl"}
+	etl.ProjectInfo.ID, etl.ProjectInfo.Name, etl.ProjectInfo.Status = "acme-etl", "Acme ETL", "active"
+	etl.Dev.Roadmap = ".dev/ROADMAP.md"
+	old := &config.Project{Path: "/p/old"}
+	old.ProjectInfo.ID, old.ProjectInfo.Name, old.ProjectInfo.Status = "acme-old", "Acme Old", "archived"
+
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 5)
+	if _, err := journal.Annotate("acme-etl", "Demo moved to Friday"); err != nil {
+		t.Fatal(err)
+	}
+	intervals := []events.Interval{
+		{ProjectID: "acme-etl", Start: from, End: from.Add(90 * time.Minute)},
+	}
+
+	// The note was written now, outside the period
+	status := Collect("Acme", []*config.Project{etl, old}, intervals, from, to)
+	if len(status.Projects) != 1 {
+		t.Fatalf("got %d projects, want 1 (idle archived projects are left out)", len(status.Projects))
+	}
+	ps := status.Projects[0]
+	if len(ps.Commits) != 2 || len(ps.Milestones) != 1 || ps.Time != 90*time.Minute || len(ps.Notes) != 0 {
+		t.Errorf("status = %+v", ps)
+	}
+
+	var out strings.Builder
+	if err := Render(&out, DefaultTemplate, status); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{
+		"# Acme: status for Mon Jan 6 to Sat Jan 11",
+		"## Acme ETL",
+		"- ✅ Orders in the warehouse",
+		"Work (2 commits):\n- Add orders load\n- Fix dedupe",
+		"Time: 1.5h",
+		"Total time: 1.5h",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
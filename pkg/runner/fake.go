@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Fake records commands instead of running them. Responses are matched by
+// command-line prefix; unmatched commands succeed with no output.
+//
+//	fake := runner.NewFake()
+//	fake.On("tmux has-session", "", errors.New("no session"))
+//	defer runner.Swap(fake)()
+type Fake struct {
+	mu        sync.Mutex
+	calls     []Call
+	responses []response
+	missing   map[string]bool
+}
+
+// Call is one command the fake was asked to run
+type Call struct {
+	Args []string // Including the program name
+	Dir  string
+	Env  []string
+}
+
+// String returns the command line, space separated
+func (c Call) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+type response struct {
+	prefix string
+	output string
+	err    error
+}
+
+// NewFake returns a fake on which every tool is installed
+func NewFake() *Fake {
+	return &Fake{missing: make(map[string]bool)}
+}
+
+// On sets the output and error for commands starting with prefix. Later
+// rules win over earlier ones.
+func (f *Fake) On(prefix, output string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, response{prefix, output, err})
+}
+
+// Missing makes LookPath fail for the named tools
+func (f *Fake) Missing(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		f.missing[name] = true
+	}
+}
+
+// Calls returns the commands run so far, in order
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Commands returns the command lines run so far, in order
+func (f *Fake) Commands() []string {
+	var lines []string
+	for _, c := range f.Calls() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+// Run implements Runner, writing canned output to cmd.Stdout if set
+func (f *Fake) Run(cmd *exec.Cmd) error {
+	output, err := f.record(cmd)
+	if cmd.Stdout != nil && output != "" {
+		fmt.Fprint(cmd.Stdout, output)
+	}
+	return err
+}
+
+// Output implements Runner
+func (f *Fake) Output(cmd *exec.Cmd) ([]byte, error) {
+	output, err := f.record(cmd)
+	return []byte(output), err
+}
+
+// Start implements Runner, recording cmd as if it had run
+func (f *Fake) Start(cmd *exec.Cmd) error {
+	_, err := f.record(cmd)
+	return err
+}
+
+// LookPath implements Runner
+func (f *Fake) LookPath(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.missing[name] {
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	return filepath.Join("/usr/bin", name), nil
+}
+
+func (f *Fake) record(cmd *exec.Cmd) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// cmd.Path is resolved; report the name the caller asked for
+	args := append([]string{filepath.Base(cmd.Args[0])}, cmd.Args[1:]...)
+	call := Call{Args: args, Dir: cmd.Dir, Env: cmd.Env}
+	f.calls = append(f.calls, call)
+
+	line := call.String()
+	for i := len(f.responses) - 1; i >= 0; i-- {
+		r := f.responses[i]
+		if line == r.prefix || strings.HasPrefix(line, r.prefix+" ") {
+			return r.output, r.err
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFakeRecordsAndResponds(t *testing.T) {
+	fake := NewFake()
+	fake.On("git remote", "origin\n", nil)
+	fake.On("tmux has-session", "", errors.New("no session"))
+	defer Swap(fake)()
+
+	out, err := Output(Command("git", "remote"))
+	if err != nil || string(out) != "origin\n" {
+		t.Errorf("git remote = %q, %v", out, err)
+	}
+
+	if err := Run(Command("tmux", "has-session", "-t=dojo")); err == nil {
+		t.Error("expected has-session to fail")
+	}
+
+	// Prefixes match whole words only
+	if err := Run(Command("tmux", "has-sessions")); err != nil {
+		t.Errorf("unexpected match on partial word: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cmd := Command("git", "remote", "-v")
+	cmd.Stdout = &buf
+	cmd.Dir = "/tmp/repo"
+	Run(cmd)
+	if buf.String() != "origin\n" {
+		t.Errorf("Run stdout = %q, want canned output", buf.String())
+	}
+
+	want := []string{"git remote", "tmux has-session -t=dojo", "tmux has-sessions", "git remote -v"}
+	if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Commands() = %v, want %v", got, want)
+	}
+	if dir := fake.Calls()[3].Dir; dir != "/tmp/repo" {
+		t.Errorf("Dir = %q", dir)
+	}
+}
+
+func TestFakeLaterRulesWin(t *testing.T) {
+	fake := NewFake()
+	fake.On("git", "any", nil)
+	fake.On("git status", "status", nil)
+
+	out, _ := fake.Output(Command("git", "status", "--short"))
+	if string(out) != "status" {
+		t.Errorf("got %q, want the more recent rule", out)
+	}
+}
+
+func TestFakeMissingTools(t *testing.T) {
+	fake := NewFake()
+	fake.Missing("az")
+	defer Swap(fake)()
+
+	if _, err := LookPath("az"); err == nil {
+		t.Error("az should be missing")
+	}
+	if _, err := LookPath("tmux"); err != nil {
+		t.Errorf("tmux should be found: %v", err)
+	}
+}
+
+func TestSwapRestores(t *testing.T) {
+	before := Default
+	restore := Swap(NewFake())
+	restore()
+	if Default != before {
+		t.Error("Swap did not restore the previous runner")
+	}
+}
@@ -0,0 +1,77 @@
+// Package runner is the single place pk starts external programs (tmux, git,
+// cloud CLIs). Commands are built with Command and started through Default,
+// which tests replace with a Fake to assert on what would have run.
+package runner
+
+import (
+	"context"
+	"os/exec"
+
+	"golang.org/x/sys/execabs"
+)
+
+// Runner starts external commands
+type Runner interface {
+	// Run starts cmd and waits for it, using the stdio already set on cmd
+	Run(cmd *exec.Cmd) error
+	// Output runs cmd and returns its standard output
+	Output(cmd *exec.Cmd) ([]byte, error)
+	// Start starts cmd in the background and lets it outlive pk
+	Start(cmd *exec.Cmd) error
+	// LookPath finds an executable on PATH
+	LookPath(name string) (string, error)
+}
+
+// Default is the runner every package uses
+var Default Runner = system{}
+
+// Swap replaces Default and returns a function that restores it
+func Swap(r Runner) (restore func()) {
+	previous := Default
+	Default = r
+	return func() { Default = previous }
+}
+
+// Command builds a command. Lookup never resolves to a binary in the
+// current directory.
+func Command(name string, args ...string) *exec.Cmd {
+	return execabs.Command(name, args...)
+}
+
+// CommandContext is Command with a context that kills the process
+func CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return execabs.CommandContext(ctx, name, args...)
+}
+
+// Run runs cmd with Default
+func Run(cmd *exec.Cmd) error {
+	return Default.Run(cmd)
+}
+
+// Output runs cmd with Default and returns its standard output
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	return Default.Output(cmd)
+}
+
+// Start starts cmd in the background with Default, without waiting for it
+func Start(cmd *exec.Cmd) error {
+	return Default.Start(cmd)
+}
+
+// LookPath finds an executable on PATH with Default
+func LookPath(name string) (string, error) {
+	return Default.LookPath(name)
+}
+
+// system runs commands for real
+type system struct{}
+
+func (system) Run(cmd *exec.Cmd) error              { return cmd.Run() }
+func (system) Output(cmd *exec.Cmd) ([]byte, error) { return cmd.Output() }
+func (system) Start(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+func (system) LookPath(name string) (string, error) { return execabs.LookPath(name) }
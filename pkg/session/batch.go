@@ -0,0 +1,91 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/events"
+)
+
+// tmuxMu serializes session creation. Several new-session calls racing a
+// tmux server that is still starting up fail with "server exited unexpectedly"
+// or leave half-built layouts, so batch opens go one session at a time.
+var tmuxMu sync.Mutex
+
+// Result is the outcome of opening one project in a batch
+type Result struct {
+	Project *config.Project
+	Session string
+	Created bool // false when the session was already running
+	Err     error
+}
+
+// Progress is called before each project in a batch is opened
+type Progress func(done, total int, project *config.Project)
+
+// OpenAll creates detached sessions for projects, continuing past failures.
+// Sessions that are already running are left untouched. progress may be nil.
+func OpenAll(projects []*config.Project, progress Progress) []Result {
+	m := Active()
+	return openAll(projects, progress, m.Exists, func(p *config.Project) error {
+		if err := m.CreateDetached(p); err != nil {
+			return err
+		}
+		created(m, p)
+		return nil
+	})
+}
+
+// openAll implements OpenAll with the multiplexer calls swappable for tests
+func openAll(projects []*config.Project, progress Progress,
+	exists func(string) bool, create func(*config.Project) error) []Result {
+
+	results := make([]Result, 0, len(projects))
+	claimed := make(map[string]string)
+
+	for i, p := range projects {
+		if progress != nil {
+			progress(i, len(projects), p)
+		}
+
+		name := SanitizeSessionName(p.ProjectInfo.ID)
+		result := Result{Project: p, Session: name}
+
+		if other, taken := claimed[name]; taken {
+			result.Err = fmt.Errorf("session name '%s' already used by %s", name, other)
+			results = append(results, result)
+			continue
+		}
+		claimed[name] = p.ProjectInfo.ID
+
+		tmuxMu.Lock()
+		if !exists(name) {
+			result.Err = create(p)
+			result.Created = result.Err == nil
+		}
+		tmuxMu.Unlock()
+
+		if result.Err == nil {
+			meta := map[string]string{"session": name}
+			if result.Created {
+				meta["created"] = "true"
+			}
+			events.Emit(events.SessionOpened, p.ProjectInfo.ID, p.Path, meta)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Failed returns the results that ended in an error
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
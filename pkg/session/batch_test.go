@@ -0,0 +1,96 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func batchProject(id string) *config.Project {
+	p := &config.Project{Path: "/tmp/" + id}
+	p.ProjectInfo.ID = id
+	return p
+}
+
+func TestOpenAllContinuesPastFailures(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	projects := []*config.Project{
+		batchProject("alpha"),
+		batchProject("broken"),
+		batchProject("running"),
+		batchProject("omega"),
+	}
+
+	var created []string
+	exists := func(name string) bool { return name == "running" }
+	create := func(p *config.Project) error {
+		if p.ProjectInfo.ID == "broken" {
+			return errors.New("boom")
+		}
+		created = append(created, p.ProjectInfo.ID)
+		return nil
+	}
+
+	var ticks []int
+	progress := func(done, total int, p *config.Project) {
+		if total != len(projects) {
+			t.Errorf("progress total = %d, want %d", total, len(projects))
+		}
+		ticks = append(ticks, done)
+	}
+
+	results := openAll(projects, progress, exists, create)
+
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	if len(created) != 2 || created[0] != "alpha" || created[1] != "omega" {
+		t.Errorf("created = %v, want [alpha omega]", created)
+	}
+	if len(ticks) != 4 || ticks[3] != 3 {
+		t.Errorf("progress ticks = %v", ticks)
+	}
+
+	if !results[0].Created || results[0].Err != nil {
+		t.Errorf("alpha: %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Created {
+		t.Errorf("broken should fail: %+v", results[1])
+	}
+	if results[2].Created || results[2].Err != nil {
+		t.Errorf("running should be reused: %+v", results[2])
+	}
+
+	failed := Failed(results)
+	if len(failed) != 1 || failed[0].Project.ProjectInfo.ID != "broken" {
+		t.Errorf("Failed() = %+v", failed)
+	}
+}
+
+func TestOpenAllRejectsSessionNameCollision(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	projects := []*config.Project{batchProject("my.app"), batchProject("my_app")}
+
+	calls := 0
+	create := func(p *config.Project) error {
+		calls++
+		return nil
+	}
+
+	results := openAll(projects, nil, func(string) bool { return false }, create)
+
+	if calls != 1 {
+		t.Errorf("create called %d times, want 1", calls)
+	}
+	if results[1].Err == nil {
+		t.Error("expected collision error for second project")
+	}
+}
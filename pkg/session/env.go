@@ -0,0 +1,115 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+// EnvChange describes one variable that differs between a live session and metadata
+type EnvChange struct {
+	Key      string
+	Current  string // Empty if unset in the session
+	Expected string // Empty if metadata no longer sets it
+	InLive   bool
+}
+
+// Kind returns a short label for the change: missing, changed, or stale
+func (c EnvChange) Kind() string {
+	switch {
+	case !c.InLive:
+		return "missing"
+	case c.Expected == "":
+		return "stale"
+	default:
+		return "changed"
+	}
+}
+
+// envArgs converts an environment map to sorted 'tmux new-session -e' arguments
+func envArgs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	return args
+}
+
+// SessionEnvironment returns the session-level environment of a tmux session
+func SessionEnvironment(sessionName string) (map[string]string, error) {
+	cmd := runner.Command("tmux", "show-environment", "-t", sessionName)
+	output, err := runner.Output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environment of session '%s': %w", sessionName, err)
+	}
+
+	return parseEnvironment(string(output)), nil
+}
+
+// parseEnvironment parses 'tmux show-environment' output.
+// Lines starting with '-' mark variables removed from the session.
+func parseEnvironment(output string) map[string]string {
+	env := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		env[key] = value
+	}
+	return env
+}
+
+// DiffEnv compares a live session environment against expected values for the
+// managed variables, returning changes sorted by key
+func DiffEnv(live, expected map[string]string, managed []string) []EnvChange {
+	var changes []EnvChange
+
+	for _, key := range managed {
+		current, inLive := live[key]
+		want := expected[key]
+
+		if !inLive && want == "" {
+			continue // Not set anywhere
+		}
+		if inLive && current == want {
+			continue // Up to date
+		}
+
+		changes = append(changes, EnvChange{Key: key, Current: current, Expected: want, InLive: inLive})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// ApplyEnvChanges updates the session environment so new windows and panes
+// pick up the expected values. Running shells keep their old environment.
+func ApplyEnvChanges(sessionName string, changes []EnvChange) error {
+	for _, c := range changes {
+		var cmd *exec.Cmd
+		if c.Expected == "" {
+			cmd = runner.Command("tmux", "set-environment", "-t", sessionName, "-u", c.Key)
+		} else {
+			cmd = runner.Command("tmux", "set-environment", "-t", sessionName, c.Key, c.Expected)
+		}
+
+		if err := runner.Run(cmd); err != nil {
+			return fmt.Errorf("failed to update %s: %w", c.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package session
+
+import "testing"
+
+func TestParseEnvironment(t *testing.T) {
+	output := "AWS_PROFILE=acme-dev\n-SNOWFLAKE_ACCOUNT\nEMPTY=\nWITH_EQUALS=a=b\n"
+	env := parseEnvironment(output)
+
+	if env["AWS_PROFILE"] != "acme-dev" {
+		t.Errorf("Expected AWS_PROFILE=acme-dev, got %q", env["AWS_PROFILE"])
+	}
+	if _, ok := env["SNOWFLAKE_ACCOUNT"]; ok {
+		t.Error("Removed variable should not be present")
+	}
+	if v, ok := env["EMPTY"]; !ok || v != "" {
+		t.Error("Expected EMPTY to be set to empty string")
+	}
+	if env["WITH_EQUALS"] != "a=b" {
+		t.Errorf("Expected value with '=' preserved, got %q", env["WITH_EQUALS"])
+	}
+}
+
+func TestDiffEnv(t *testing.T) {
+	managed := []string{"AWS_PROFILE", "CLOUDSDK_CORE_PROJECT", "DATABRICKS_CONFIG_PROFILE", "SNOWFLAKE_ACCOUNT"}
+	live := map[string]string{
+		"AWS_PROFILE":               "old",
+		"SNOWFLAKE_ACCOUNT":         "xy123",
+		"DATABRICKS_CONFIG_PROFILE": "same",
+		"UNMANAGED":                 "ignored",
+	}
+	expected := map[string]string{
+		"AWS_PROFILE":               "new",
+		"CLOUDSDK_CORE_PROJECT":     "acme-prod",
+		"DATABRICKS_CONFIG_PROFILE": "same",
+	}
+
+	changes := DiffEnv(live, expected, managed)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+
+	kinds := map[string]string{}
+	for _, c := range changes {
+		kinds[c.Key] = c.Kind()
+	}
+
+	if kinds["AWS_PROFILE"] != "changed" {
+		t.Errorf("AWS_PROFILE: expected changed, got %q", kinds["AWS_PROFILE"])
+	}
+	if kinds["CLOUDSDK_CORE_PROJECT"] != "missing" {
+		t.Errorf("CLOUDSDK_CORE_PROJECT: expected missing, got %q", kinds["CLOUDSDK_CORE_PROJECT"])
+	}
+	if kinds["SNOWFLAKE_ACCOUNT"] != "stale" {
+		t.Errorf("SNOWFLAKE_ACCOUNT: expected stale, got %q", kinds["SNOWFLAKE_ACCOUNT"])
+	}
+}
@@ -0,0 +1,136 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// Session lifecycle hooks, set globally in [hooks] in config.toml and per
+// project in [tmux]
+const (
+	OnCreate = "on_create"
+	OnAttach = "on_attach"
+	OnDetach = "on_detach"
+)
+
+// hookCommands returns the commands for a hook: the global one first, then
+// the project's
+func hookCommands(project *config.Project, hook string) []string {
+	var global settings.SessionHooks
+	if s, err := settings.Load(); err == nil {
+		global = s.Hooks
+	}
+
+	var commands []string
+	switch hook {
+	case OnCreate:
+		commands = []string{global.OnCreate, project.Tmux.OnCreate}
+	case OnAttach:
+		commands = []string{global.OnAttach, project.Tmux.OnAttach}
+	case OnDetach:
+		commands = []string{global.OnDetach, project.Tmux.OnDetach}
+	}
+
+	var set []string
+	for _, c := range commands {
+		if strings.TrimSpace(c) != "" {
+			set = append(set, c)
+		}
+	}
+	return set
+}
+
+// RunHook runs a lifecycle hook's commands with sh in the project directory,
+// with the session's environment plus PK_SESSION and PK_HOOK, after filling
+// in metadata references. A failing
+// command is reported and the rest still run; hooks never stop a session
+// from opening.
+func RunHook(project *config.Project, hook string) {
+	commands := hookCommands(project, hook)
+	if len(commands) == 0 {
+		return
+	}
+
+	env := os.Environ()
+	for k, v := range pkcontext.Env(project) {
+		env = append(env, k+"="+v)
+	}
+	env = append(env,
+		"PK_SESSION="+SanitizeSessionName(project.ProjectInfo.ID),
+		"PK_HOOK="+hook)
+
+	for _, command := range commands {
+		command, err := project.Expand(command)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s hook skipped: %v\n", hook, err)
+			continue
+		}
+		cmd := runner.Command("sh", "-c", command)
+		cmd.Dir = project.Path
+		cmd.Env = env
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := runner.Run(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s hook failed (%s): %v\n", hook, command, err)
+		}
+	}
+}
+
+// created runs on_create for a session pk just started and arranges for
+// on_detach to run when a client leaves it. tmux runs on_detach from a
+// session hook; other multiplexers only when pk's own attach returns.
+func created(m Multiplexer, project *config.Project) {
+	RunHook(project, OnCreate)
+
+	if _, ok := m.(Tmux); !ok || len(hookCommands(project, OnDetach)) == 0 {
+		return
+	}
+	pk, err := os.Executable()
+	if err != nil {
+		pk = "pk"
+	}
+	// A session's hook shadows the global client-detached hooks, so this one
+	// also records the detach event that 'pk install --tmux-bindings' would
+	path := strings.ReplaceAll(project.Path, "#", "##")
+	command := fmt.Sprintf("%s __hook session %s %s \"#{client_name}\"", shellQuote(pk), OnDetach, shellQuote(path))
+	cmd := runner.Command("tmux", "set-hook", "-t", SanitizeSessionName(project.ProjectInfo.ID),
+		"client-detached", "run-shell -b "+tmuxQuote(command))
+	if err := runner.Run(cmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to set tmux on_detach hook: %v\n", err)
+	}
+}
+
+// attach runs on_attach and attaches to a running session. Where attaching
+// blocks until the client detaches and the multiplexer has no hook of its
+// own for it, on_detach runs once it returns.
+func attach(m Multiplexer, project *config.Project, sessionName string) error {
+	RunHook(project, OnAttach)
+	err := m.Attach(sessionName)
+	if _, ok := m.(Zellij); ok && !IsInZellij() && err == nil {
+		RunHook(project, OnDetach)
+	}
+	return err
+}
+
+// hasHooks reports whether the project has on_create or on_detach commands,
+// which need the session to exist before pk attaches to it
+func hasHooks(project *config.Project) bool {
+	return len(hookCommands(project, OnCreate)) > 0 || len(hookCommands(project, OnDetach)) > 0
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tmuxQuote quotes s as a tmux command argument
+func tmuxQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
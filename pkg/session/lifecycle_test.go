@@ -0,0 +1,72 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestCreatedRunsHooks(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	home := t.TempDir()
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	configDir := filepath.Join(home, ".config", "pk")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.toml"), []byte("[hooks]\non_create = \"timetrack start\"\n"), 0644)
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/my api"}
+	project.ProjectInfo.ID = "api.v2"
+	project.Tmux.OnCreate = "docker compose up -d"
+	project.Tmux.OnDetach = "docker compose stop"
+
+	created(Tmux{}, project)
+
+	calls := fake.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("commands = %q", fake.Commands())
+	}
+	if calls[0].String() != "sh -c timetrack start" || calls[1].String() != "sh -c docker compose up -d" {
+		t.Errorf("global on_create should run before the project's, got %q", fake.Commands())
+	}
+	if calls[1].Dir != "/work/my api" || !slices.Contains(calls[1].Env, "PK_SESSION=api_v2") ||
+		!slices.Contains(calls[1].Env, "PK_HOOK=on_create") || !slices.Contains(calls[1].Env, "PK_PROJECT_ID=api.v2") {
+		t.Errorf("hook should run in the project with its env, got dir %q", calls[1].Dir)
+	}
+
+	hook := calls[2].Args
+	if len(hook) != 6 || hook[1] != "set-hook" || hook[3] != "api_v2" || hook[4] != "client-detached" {
+		t.Fatalf("set-hook = %q", hook)
+	}
+	if !strings.HasSuffix(hook[5], ` __hook session on_detach '/work/my api' \"#{client_name}\""`) {
+		t.Errorf("detach hook command = %q", hook[5])
+	}
+}
+
+func TestHookCommandsNone(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/api"}
+	project.Tmux.OnAttach = "  "
+	if hasHooks(project) {
+		t.Error("project without on_create or on_detach should have no hooks")
+	}
+	RunHook(project, OnAttach)
+	if len(fake.Calls()) != 0 {
+		t.Errorf("blank hook should run nothing, got %q", fake.Commands())
+	}
+}
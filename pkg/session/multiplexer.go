@@ -0,0 +1,155 @@
+package session
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// Multiplexer is a terminal multiplexer hosting project sessions. Session
+// names come from SanitizeSessionName; layouts from the project's [tmux]
+// section (or the default layout), translated as each backend allows.
+type Multiplexer interface {
+	// Name is the value of multiplexer in config.toml
+	Name() string
+	// Check reports whether the multiplexer is installed
+	Check() error
+	// Exists reports whether a session is running
+	Exists(name string) bool
+	// List returns the names of the running sessions
+	List() ([]string, error)
+	// Create starts the project's session and attaches to it
+	Create(project *config.Project) error
+	// CreateDetached starts the project's session in the background
+	CreateDetached(project *config.Project) error
+	// Attach attaches to a running session, or switches to it from inside
+	// the multiplexer
+	Attach(name string) error
+	// Kill ends a session
+	Kill(name string) error
+	// Rename renames a running session
+	Rename(name, newName string) error
+	// Current returns the session pk is running in
+	Current() (string, error)
+}
+
+// Multiplexers by their config.toml name
+var multiplexers = map[string]Multiplexer{
+	"tmux":   Tmux{},
+	"zellij": Zellij{},
+}
+
+var (
+	active     Multiplexer
+	activeOnce sync.Once
+)
+
+// Active returns the multiplexer chosen by multiplexer in config.toml,
+// tmux unless set. An unknown name warns once and falls back to tmux.
+func Active() Multiplexer {
+	activeOnce.Do(func() {
+		if active != nil {
+			return // Set by a test
+		}
+		active = Tmux{}
+		s, err := settings.Load()
+		if err != nil || s.Multiplexer == "" {
+			return
+		}
+		if m, ok := multiplexers[s.Multiplexer]; ok {
+			active = m
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Warning: Unknown multiplexer '%s' in config.toml (supported: %s); using tmux\n",
+			s.Multiplexer, strings.Join(slices.Sorted(maps.Keys(multiplexers)), ", "))
+	})
+	return active
+}
+
+// Check verifies the configured multiplexer is installed
+func Check() error {
+	return Active().Check()
+}
+
+// SessionExists checks if a session is running
+func SessionExists(name string) bool {
+	return Active().Exists(name)
+}
+
+// ListSessions returns all running sessions
+func ListSessions() ([]string, error) {
+	return Active().List()
+}
+
+// SwitchSession attaches or switches to a running session
+func SwitchSession(sessionName string) error {
+	return Active().Attach(sessionName)
+}
+
+// KillSession ends a session by name
+func KillSession(name string) error {
+	return Active().Kill(name)
+}
+
+// RenameSession renames a running session
+func RenameSession(name, newName string) error {
+	return Active().Rename(name, newName)
+}
+
+// CurrentSession returns the name of the session pk is running in
+func CurrentSession() (string, error) {
+	return Active().Current()
+}
+
+// CreateSession opens the project's session, creating it if it isn't
+// running. With [tmux] attach = false it is left in the background.
+// Lifecycle hooks run along the way (see RunHook).
+func CreateSession(project *config.Project) error {
+	m := Active()
+	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
+
+	// Check if session already exists
+	if m.Exists(sessionName) {
+		events.Emit(events.SessionOpened, project.ProjectInfo.ID, project.Path, map[string]string{"session": sessionName})
+		if !project.TmuxAttach() {
+			return nil
+		}
+		return attach(m, project, sessionName)
+	}
+
+	events.Emit(events.SessionOpened, project.ProjectInfo.ID, project.Path,
+		map[string]string{"session": sessionName, "created": "true"})
+
+	// [tmux] attach = false leaves the session running in the background;
+	// on_create and on_detach need it running before pk attaches
+	if !project.TmuxAttach() || hasHooks(project) {
+		if err := m.CreateDetached(project); err != nil {
+			return err
+		}
+		created(m, project)
+		if !project.TmuxAttach() {
+			return nil
+		}
+		return attach(m, project, sessionName)
+	}
+
+	RunHook(project, OnAttach)
+	return m.Create(project)
+}
+
+// RequireTmux checks that a tmux-only feature can run: tmux is the
+// configured multiplexer and it is installed
+func RequireTmux(feature string) error {
+	if name := Active().Name(); name != "tmux" {
+		return fmt.Errorf("%s only works with tmux (multiplexer = %q in config.toml)", feature, name)
+	}
+	return deps.Require(feature, "tmux")
+}
@@ -0,0 +1,186 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datakaicr/pk/pkg/events"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// SnapshotVersion is the layout of the snapshot file
+const SnapshotVersion = 1
+
+// SnapshotFile is the default snapshot in the pk cache directory
+const SnapshotFile = "sessions.json"
+
+// Snapshot records which project sessions were running and how their
+// windows were laid out
+type Snapshot struct {
+	Version  int            `json:"version"`
+	SavedAt  time.Time      `json:"saved_at"`
+	Sessions []SavedSession `json:"sessions"`
+}
+
+// SavedSession is one session as it was running
+type SavedSession struct {
+	Name      string        `json:"name"`
+	ProjectID string        `json:"project_id,omitempty"`
+	Path      string        `json:"path,omitempty"` // Project directory
+	Windows   []SavedWindow `json:"windows"`
+}
+
+// SavedWindow is a window's name, pane layout and panes
+type SavedWindow struct {
+	Name   string      `json:"name"`
+	Layout string      `json:"layout"` // #{window_layout}, for select-layout
+	Active bool        `json:"active,omitempty"`
+	Panes  []SavedPane `json:"panes"`
+}
+
+// SavedPane is a pane's working directory
+type SavedPane struct {
+	Path   string `json:"path"`
+	Active bool   `json:"active,omitempty"`
+}
+
+// paneFieldSep separates 'tmux list-panes' fields. tmux 3.3 prints tabs in
+// formats as "_", so it is a string no name or path will contain instead.
+const paneFieldSep = "<pk>"
+
+// Capture reads the windows and panes of every running session, in tmux's
+// order. Programs running in panes aren't recorded.
+func Capture() ([]SavedSession, error) {
+	format := strings.Join([]string{"#{session_name}", "#{window_index}", "#{window_name}", "#{window_layout}",
+		"#{window_active}", "#{pane_current_path}", "#{pane_active}"}, paneFieldSep)
+	output, err := runner.Output(runner.Command("tmux", "list-panes", "-a", "-F", format))
+	if err != nil {
+		// No server, no sessions
+		return []SavedSession{}, nil
+	}
+	return parsePaneList(string(output)), nil
+}
+
+// parsePaneList groups 'tmux list-panes -a' output into sessions and
+// windows
+func parsePaneList(output string) []SavedSession {
+	var sessions []SavedSession
+	lastWindow := -1
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, paneFieldSep)
+		if len(fields) < 7 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		if len(sessions) == 0 || sessions[len(sessions)-1].Name != fields[0] {
+			sessions = append(sessions, SavedSession{Name: fields[0]})
+			lastWindow = -1
+		}
+		s := &sessions[len(sessions)-1]
+		if index != lastWindow {
+			s.Windows = append(s.Windows, SavedWindow{Name: fields[2], Layout: fields[3], Active: fields[4] == "1"})
+			lastWindow = index
+		}
+		w := &s.Windows[len(s.Windows)-1]
+		w.Panes = append(w.Panes, SavedPane{Path: fields[5], Active: fields[6] == "1"})
+	}
+	return sessions
+}
+
+// SaveSnapshot writes sessions to path
+func SaveSnapshot(path string, sessions []SavedSession) error {
+	data, err := json.MarshalIndent(Snapshot{Version: SnapshotVersion, SavedAt: time.Now(), Sessions: sessions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return statefile.WriteFile(path, data, 0644)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot
+func LoadSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	data, err := statefile.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if snapshot.Version > SnapshotVersion {
+		return snapshot, fmt.Errorf("snapshot %s is version %d; upgrade pk to restore it", path, snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+// Restore recreates a saved session in the background with the given
+// session environment: its windows, their panes and working directories,
+// and the saved pane layout. Panes whose directory is gone open in the
+// project directory. Programs aren't restarted.
+func Restore(s SavedSession, env map[string]string) error {
+	if len(s.Windows) == 0 {
+		return fmt.Errorf("session %s has no windows", s.Name)
+	}
+	dir := func(panes []SavedPane, j int) string {
+		if j >= len(panes) {
+			return s.Path
+		}
+		if _, err := os.Stat(panes[j].Path); err != nil && s.Path != "" {
+			return s.Path
+		}
+		return panes[j].Path
+	}
+
+	first := s.Windows[0]
+	args := []string{"new-session", "-ds", s.Name, "-n", first.Name, "-c", dir(first.Panes, 0)}
+	args = append(args, envArgs(env)...)
+	if err := runner.Run(runner.Command("tmux", args...)); err != nil {
+		return fmt.Errorf("failed to create session %s: %w", s.Name, err)
+	}
+
+	opts := readOptions(s.Name)
+	active := opts.BaseIndex
+	for i, window := range s.Windows {
+		target := fmt.Sprintf("%s:%d", s.Name, opts.BaseIndex+i)
+		if i > 0 {
+			cmd := runner.Command("tmux", "new-window", "-d", "-t", target, "-n", window.Name, "-c", dir(window.Panes, 0))
+			if err := runner.Run(cmd); err != nil {
+				return fmt.Errorf("failed to create window %s: %w", window.Name, err)
+			}
+		}
+
+		activePane := opts.PaneBaseIndex
+		for j, pane := range window.Panes {
+			if j > 0 {
+				// Split the last pane so panes keep their saved order
+				last := fmt.Sprintf("%s.%d", target, opts.PaneBaseIndex+j-1)
+				runner.Run(runner.Command("tmux", "split-window", "-d", "-t", last, "-c", dir(window.Panes, j)))
+			}
+			if pane.Active {
+				activePane = opts.PaneBaseIndex + j
+			}
+		}
+		if len(window.Panes) > 1 && window.Layout != "" {
+			runner.Run(runner.Command("tmux", "select-layout", "-t", target, window.Layout))
+		}
+		if len(window.Panes) > 1 {
+			runner.Run(runner.Command("tmux", "select-pane", "-t", fmt.Sprintf("%s.%d", target, activePane)))
+		}
+		if window.Active {
+			active = opts.BaseIndex + i
+		}
+	}
+	runner.Run(runner.Command("tmux", "select-window", "-t", fmt.Sprintf("%s:%d", s.Name, active)))
+
+	events.Emit(events.SessionOpened, s.ProjectID, s.Path,
+		map[string]string{"session": s.Name, "created": "true", "restored": "true"})
+	return nil
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestParsePaneList(t *testing.T) {
+	line := func(fields ...string) string { return strings.Join(fields, paneFieldSep) }
+	output := strings.Join([]string{
+		line("api", "1", "edit", "L1", "1", "/work/api", "0"),
+		line("api", "1", "edit", "L1", "1", "/work/api/src", "1"),
+		line("api", "3", "logs", "L2", "0", "/var/log", "1"),
+		line("web", "0", "zsh", "L3", "1", "/work/web", "1"),
+	}, "\n")
+
+	want := []SavedSession{
+		{Name: "api", Windows: []SavedWindow{
+			{Name: "edit", Layout: "L1", Active: true, Panes: []SavedPane{{Path: "/work/api"}, {Path: "/work/api/src", Active: true}}},
+			{Name: "logs", Layout: "L2", Panes: []SavedPane{{Path: "/var/log", Active: true}}},
+		}},
+		{Name: "web", Windows: []SavedWindow{
+			{Name: "zsh", Layout: "L3", Active: true, Panes: []SavedPane{{Path: "/work/web", Active: true}}},
+		}},
+	}
+	if got := parsePaneList(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePaneList:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	saved := []SavedSession{{Name: "api", ProjectID: "api", Path: "/work/api",
+		Windows: []SavedWindow{{Name: "edit", Panes: []SavedPane{{Path: "/work/api"}}}}}}
+
+	if err := SaveSnapshot(path, saved); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Version != SnapshotVersion || !reflect.DeepEqual(snapshot.Sessions, saved) {
+		t.Errorf("round trip: got %+v", snapshot)
+	}
+
+	os.WriteFile(path, []byte(`{"version": 99, "sessions": []}`), 0644)
+	if _, err := LoadSnapshot(path); err == nil || !strings.Contains(err.Error(), "upgrade pk") {
+		t.Errorf("expected a newer snapshot to be refused, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	project := t.TempDir()
+	src := filepath.Join(project, "src")
+	os.Mkdir(src, 0755)
+
+	fake := runner.NewFake()
+	fake.On("tmux display-message", "1 1\n", nil)
+	defer runner.Swap(fake)()
+
+	s := SavedSession{Name: "api", ProjectID: "api", Path: project, Windows: []SavedWindow{
+		{Name: "edit", Layout: "L1", Panes: []SavedPane{{Path: project}, {Path: src, Active: true}}},
+		{Name: "logs", Layout: "L2", Active: true, Panes: []SavedPane{{Path: "/gone"}}},
+	}}
+	if err := Restore(s, map[string]string{"PK_PROJECT_ID": "api"}); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	want := []string{
+		"tmux new-session -ds api -n edit -c " + project + " -e PK_PROJECT_ID=api",
+		"tmux display-message -p -t api: #{window_index} #{pane_index}",
+		"tmux split-window -d -t api:1.1 -c " + src,
+		"tmux select-layout -t api:1 L1",
+		"tmux select-pane -t api:1.2",
+		"tmux new-window -d -t api:2 -n logs -c " + project, // Missing directory
+		"tmux select-window -t api:2",
+	}
+	if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands:\n got %q\nwant %q", got, want)
+	}
+}
@@ -4,29 +4,50 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
 )
 
+// Tmux runs project sessions in tmux, the default multiplexer
+type Tmux struct{}
+
+// Name implements Multiplexer
+func (Tmux) Name() string { return "tmux" }
+
+// Check implements Multiplexer
+func (Tmux) Check() error { return CheckTmux() }
+
 // CheckTmux verifies if tmux is installed
 func CheckTmux() error {
-	if _, err := exec.LookPath("tmux"); err != nil {
-		return fmt.Errorf("'pk session' requires tmux to be installed\n" +
-			"Install: brew install tmux (macOS) or apt install tmux (Linux)")
-	}
-	return nil
+	return deps.Require("'pk session'", "tmux")
 }
 
+// PopupMode forces switch-client behavior for pickers running inside
+// 'tmux display-popup', where attaching would nest tmux inside the popup
+var PopupMode bool
+
 // IsInTmux checks if currently inside a tmux session
 func IsInTmux() bool {
 	return os.Getenv("TMUX") != ""
 }
 
-// SessionExists checks if a tmux session exists
-func SessionExists(name string) bool {
-	cmd := exec.Command("tmux", "has-session", "-t="+name)
-	return cmd.Run() == nil
+// useSwitchClient reports whether to switch the current client rather than attach
+func useSwitchClient() bool {
+	return PopupMode || IsInTmux()
+}
+
+// Exists checks if a tmux session exists
+func (Tmux) Exists(name string) bool {
+	cmd := runner.Command("tmux", "has-session", "-t="+name)
+	return runner.Run(cmd) == nil
 }
 
 // SanitizeSessionName converts a project name to a valid tmux session name
@@ -35,14 +56,11 @@ func SanitizeSessionName(name string) string {
 	return strings.ReplaceAll(name, ".", "_")
 }
 
-// CreateSession creates a new tmux session
-func CreateSession(project *config.Project) error {
-	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
-
-	// Check if session already exists
-	if SessionExists(sessionName) {
-		return SwitchSession(sessionName)
-	}
+// Create builds the project's session from its windows (or the default
+// layout) and attaches to it
+func (Tmux) Create(project *config.Project) error {
+	// Projects without a [tmux] section get a default layout from global config
+	settings.ApplyDefaultLayout(project)
 
 	// Create new session based on configuration
 	if len(project.Tmux.Windows) > 0 {
@@ -50,99 +68,248 @@ func CreateSession(project *config.Project) error {
 	}
 
 	// Create basic session
-	return CreateBasicSession(sessionName, project.Path)
+	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
+	return CreateBasicSession(sessionName, project.Path, pkcontext.Env(project))
 }
 
-// CreateBasicSession creates a simple single-window session
-func CreateBasicSession(sessionName, path string) error {
+// CreateDetached implements Multiplexer
+func (Tmux) CreateDetached(project *config.Project) error {
+	return createDetached(project)
+}
+
+// CreateBasicSession creates a simple single-window session with the given
+// session environment
+func CreateBasicSession(sessionName, path string, env map[string]string) error {
 	var cmd *exec.Cmd
 
-	if IsInTmux() {
+	if useSwitchClient() {
 		// Inside tmux: create detached and switch
-		cmd = exec.Command("tmux", "new-session", "-ds", sessionName, "-c", path)
-		if err := cmd.Run(); err != nil {
+		args := append([]string{"new-session", "-ds", sessionName, "-c", path}, envArgs(env)...)
+		cmd = runner.Command("tmux", args...)
+		if err := runner.Run(cmd); err != nil {
 			return fmt.Errorf("failed to create tmux session: %w", err)
 		}
-		return SwitchSession(sessionName)
+		return switchTmuxSession(sessionName)
 	}
 
 	// Outside tmux: attach directly
-	cmd = exec.Command("tmux", "new-session", "-s", sessionName, "-c", path)
+	args := append([]string{"new-session", "-s", sessionName, "-c", path}, envArgs(env)...)
+	cmd = runner.Command("tmux", args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return runner.Run(cmd)
+}
+
+// Attach switches to an existing session, or to a session:window target
+func (Tmux) Attach(sessionName string) error {
+	return switchTmuxSession(sessionName)
 }
 
-// SwitchSession switches to an existing session
-func SwitchSession(sessionName string) error {
+func switchTmuxSession(sessionName string) error {
 	var cmd *exec.Cmd
 
-	if IsInTmux() {
-		cmd = exec.Command("tmux", "switch-client", "-t", sessionName)
+	if useSwitchClient() {
+		cmd = runner.Command("tmux", "switch-client", "-t", sessionName)
 	} else {
-		cmd = exec.Command("tmux", "attach-session", "-t", sessionName)
+		cmd = runner.Command("tmux", "attach-session", "-t", sessionName)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 	}
 
-	return cmd.Run()
+	return runner.Run(cmd)
 }
 
 // CreateWithLayout creates a session with custom window layout
 func CreateWithLayout(project *config.Project) error {
+	if err := buildLayout(project); err != nil {
+		return err
+	}
+
+	// Switch to session
+	return switchTmuxSession(SanitizeSessionName(project.ProjectInfo.ID))
+}
+
+// createDetached creates the project's session without attaching to it
+func createDetached(project *config.Project) error {
+	settings.ApplyDefaultLayout(project)
+
+	if len(project.Tmux.Windows) > 0 {
+		return buildLayout(project)
+	}
+
 	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
+	args := append([]string{"new-session", "-ds", sessionName, "-c", project.Path}, envArgs(pkcontext.Env(project))...)
+	if err := runner.Run(runner.Command("tmux", args...)); err != nil {
+		return fmt.Errorf("failed to create tmux session: %w", err)
+	}
+	return nil
+}
+
+// buildLayout creates a detached session with the project's configured
+// windows. The first window is the session's initial window, and later ones
+// are numbered from wherever tmux put it, so base-index and pane-base-index
+// from the user's tmux.conf are honoured. pk never passes a shell or
+// command to tmux, so default-shell and default-command apply as usual.
+func buildLayout(project *config.Project) error {
+	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
+	windows, err := expandedWindows(project)
+	if err != nil {
+		return err
+	}
 
 	// Create base session (detached)
-	cmd := exec.Command("tmux", "new-session", "-ds", sessionName, "-c", project.Path)
-	if err := cmd.Run(); err != nil {
+	args := []string{"new-session", "-ds", sessionName,
+		"-n", windowName(windows[0], 0), "-c", windowPath(project, windows[0])}
+	args = append(args, envArgs(pkcontext.Env(project))...)
+	cmd := runner.Command("tmux", args...)
+	if err := runner.Run(cmd); err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
 	}
 
-	// Kill the default window
-	exec.Command("tmux", "kill-window", "-t", sessionName+":1").Run()
+	opts := readOptions(sessionName)
 
 	// Create windows from configuration
-	for i, window := range project.Tmux.Windows {
-		windowPath := project.Path
-		if window.Path != "" {
-			windowPath = window.Path
-		}
+	for i, window := range windows {
+		windowTarget := fmt.Sprintf("%s:%d", sessionName, opts.BaseIndex+i)
 
-		windowName := window.Name
-		if windowName == "" {
-			windowName = fmt.Sprintf("window-%d", i+1)
-		}
-
-		// Create window
-		windowTarget := fmt.Sprintf("%s:%d", sessionName, i+1)
-		createCmd := exec.Command("tmux", "new-window", "-t", windowTarget, "-n", windowName, "-c", windowPath)
-		if err := createCmd.Run(); err != nil {
-			return fmt.Errorf("failed to create window %s: %w", windowName, err)
+		if i > 0 {
+			name := windowName(window, i)
+			createCmd := runner.Command("tmux", "new-window", "-t", windowTarget, "-n", name, "-c", windowPath(project, window))
+			if err := runner.Run(createCmd); err != nil {
+				return fmt.Errorf("failed to create window %s: %w", name, err)
+			}
 		}
 
 		// Send command if specified
 		if window.Command != "" {
-			sendCmd := exec.Command("tmux", "send-keys", "-t", windowTarget, window.Command, "Enter")
-			sendCmd.Run()
+			paneTarget := fmt.Sprintf("%s.%d", windowTarget, opts.PaneBaseIndex)
+			sendCmd := runner.Command("tmux", "send-keys", "-t", paneTarget, window.Command, "Enter")
+			runner.Run(sendCmd)
+		}
+
+		if err := splitPanes(project, window, windowName(window, i), windowTarget, opts); err != nil {
+			return err
 		}
 	}
 
 	// Set layout if specified
 	if project.Tmux.Layout != "" {
-		layoutCmd := exec.Command("tmux", "select-layout", "-t", sessionName, project.Tmux.Layout)
-		layoutCmd.Run()
+		layoutCmd := runner.Command("tmux", "select-layout", "-t", sessionName, project.Tmux.Layout)
+		runner.Run(layoutCmd)
 	}
 
-	// Switch to session
-	return SwitchSession(sessionName)
+	return nil
+}
+
+// splitPanes adds a window's configured panes. Each splits the pane before
+// it, and tmux numbers a new pane right after the one it split, so the
+// panes' indices follow their order. Focus goes back to the first pane.
+func splitPanes(project *config.Project, window config.TmuxWindow, name, windowTarget string, opts tmuxOptions) error {
+	if len(window.Panes) == 0 {
+		return nil
+	}
+
+	for j, pane := range window.Panes {
+		direction := "-v"
+		if pane.Split == "horizontal" {
+			direction = "-h"
+		}
+		path := windowPath(project, window)
+		if pane.Path != "" {
+			path = projectPath(project, pane.Path)
+		}
+
+		args := []string{"split-window", "-t", fmt.Sprintf("%s.%d", windowTarget, opts.PaneBaseIndex+j), direction}
+		if pane.Size > 0 {
+			args = append(args, "-l", fmt.Sprintf("%d%%", pane.Size))
+		}
+		args = append(args, "-c", path)
+		if err := runner.Run(runner.Command("tmux", args...)); err != nil {
+			return fmt.Errorf("failed to split pane %d of window %s: %w", j+1, name, err)
+		}
+
+		if pane.Command != "" {
+			paneTarget := fmt.Sprintf("%s.%d", windowTarget, opts.PaneBaseIndex+j+1)
+			runner.Run(runner.Command("tmux", "send-keys", "-t", paneTarget, pane.Command, "Enter"))
+		}
+	}
+
+	return runner.Run(runner.Command("tmux", "select-pane", "-t", fmt.Sprintf("%s.%d", windowTarget, opts.PaneBaseIndex)))
 }
 
-// ListSessions returns all active tmux sessions
-func ListSessions() ([]string, error) {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-	output, err := cmd.Output()
+// tmuxOptions are the user's tmux settings that decide window and pane targets
+type tmuxOptions struct {
+	BaseIndex     int // base-index
+	PaneBaseIndex int // pane-base-index
+}
+
+// readOptions reads the indices tmux gave a new session's first window and
+// pane. Asking the session rather than the global options picks up
+// tmux.conf, per-session overrides, and hooks alike. Unreadable values
+// fall back to tmux's defaults.
+func readOptions(sessionName string) tmuxOptions {
+	var opts tmuxOptions
+	out, err := runner.Output(runner.Command("tmux", "display-message", "-p", "-t", sessionName+":",
+		"#{window_index} #{pane_index}"))
+	if err != nil {
+		return opts
+	}
+	fmt.Sscan(string(out), &opts.BaseIndex, &opts.PaneBaseIndex)
+	return opts
+}
+
+// windowName is the configured name, or window-N counting from 1
+func windowName(window config.TmuxWindow, i int) string {
+	if window.Name != "" {
+		return window.Name
+	}
+	return fmt.Sprintf("window-%d", i+1)
+}
+
+// expandedWindows returns the project's windows with metadata references
+// in their commands filled in (see config.Project.Expand). They're copies:
+// windows may come from a layout shared with other projects.
+func expandedWindows(project *config.Project) ([]config.TmuxWindow, error) {
+	windows := make([]config.TmuxWindow, len(project.Tmux.Windows))
+	for i, window := range project.Tmux.Windows {
+		command, err := project.Expand(window.Command)
+		if err != nil {
+			return nil, fmt.Errorf("window %s: %w", windowName(window, i), err)
+		}
+		window.Command = command
+		window.Panes = slices.Clone(window.Panes)
+		for j := range window.Panes {
+			if window.Panes[j].Command, err = project.Expand(window.Panes[j].Command); err != nil {
+				return nil, fmt.Errorf("window %s, pane %d: %w", windowName(window, i), j+1, err)
+			}
+		}
+		windows[i] = window
+	}
+	return windows, nil
+}
+
+// windowPath resolves a window's path; relative paths are inside the project
+func windowPath(project *config.Project, window config.TmuxWindow) string {
+	return projectPath(project, window.Path)
+}
+
+// projectPath resolves a configured path against the project directory
+func projectPath(project *config.Project, path string) string {
+	switch {
+	case path == "":
+		return project.Path
+	case filepath.IsAbs(path):
+		return path
+	}
+	return filepath.Join(project.Path, path)
+}
+
+// List returns all active tmux sessions
+func (Tmux) List() ([]string, error) {
+	cmd := runner.Command("tmux", "list-sessions", "-F", "#{session_name}")
+	output, err := runner.Output(cmd)
 	if err != nil {
 		// No sessions is not an error
 		return []string{}, nil
@@ -152,8 +319,76 @@ func ListSessions() ([]string, error) {
 	return sessions, nil
 }
 
-// KillSession kills a tmux session by name
-func KillSession(name string) error {
-	cmd := exec.Command("tmux", "kill-session", "-t", name)
-	return cmd.Run()
+// Window represents a window inside an active tmux session
+type Window struct {
+	Session string
+	Index   int
+	Name    string
+	Active  bool
+}
+
+// Target returns the tmux target for the window (session:index)
+func (w Window) Target() string {
+	return fmt.Sprintf("%s:%d", w.Session, w.Index)
+}
+
+// ListWindows returns all windows across all active tmux sessions
+func ListWindows() ([]Window, error) {
+	cmd := runner.Command("tmux", "list-windows", "-a", "-F",
+		"#{session_name}\t#{window_index}\t#{window_name}\t#{window_active}")
+	output, err := runner.Output(cmd)
+	if err != nil {
+		// No sessions is not an error
+		return []Window{}, nil
+	}
+
+	return parseWindowList(string(output)), nil
+}
+
+// parseWindowList parses tab-separated output of 'tmux list-windows -a'
+func parseWindowList(output string) []Window {
+	var windows []Window
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 {
+			continue
+		}
+
+		index, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		windows = append(windows, Window{
+			Session: fields[0],
+			Index:   index,
+			Name:    fields[2],
+			Active:  fields[3] == "1",
+		})
+	}
+	return windows
+}
+
+// Kill kills a tmux session by name
+func (Tmux) Kill(name string) error {
+	cmd := runner.Command("tmux", "kill-session", "-t", name)
+	return runner.Run(cmd)
+}
+
+// Rename renames a tmux session
+func (Tmux) Rename(name, newName string) error {
+	return runner.Run(runner.Command("tmux", "rename-session", "-t="+name, newName))
+}
+
+// Current returns the name of the tmux session pk is running in
+func (Tmux) Current() (string, error) {
+	if !IsInTmux() {
+		return "", fmt.Errorf("not inside a tmux session")
+	}
+
+	output, err := runner.Output(runner.Command("tmux", "display-message", "-p", "#S"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
 }
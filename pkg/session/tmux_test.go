@@ -1,8 +1,14 @@
 package session
 
 import (
+	"errors"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
 )
 
 func TestSanitizeSessionName(t *testing.T) {
@@ -54,3 +60,217 @@ func TestIsInTmux(t *testing.T) {
 		t.Error("IsInTmux() should return false when TMUX is empty string")
 	}
 }
+
+func TestParseWindowList(t *testing.T) {
+	output := "pk\t1\teditor\t1\npk\t2\tserver\t0\ndojo\t1\tmy shell\t1\nbroken line\n"
+
+	windows := parseWindowList(output)
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(windows))
+	}
+
+	if windows[1].Session != "pk" || windows[1].Index != 2 || windows[1].Name != "server" {
+		t.Errorf("Unexpected window: %+v", windows[1])
+	}
+
+	if windows[1].Active {
+		t.Error("Window pk:2 should not be active")
+	}
+
+	if windows[2].Name != "my shell" {
+		t.Errorf("Expected window name with spaces to be preserved, got %q", windows[2].Name)
+	}
+
+	if windows[1].Target() != "pk:2" {
+		t.Errorf("Expected target 'pk:2', got %q", windows[1].Target())
+	}
+}
+
+func TestCreateDetachedWithLayout(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	// tmux.conf sets base-index 1 and pane-base-index 1
+	fake.On("tmux display-message", "1 1\n", nil)
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/my.app"}
+	project.ProjectInfo.ID = "my.app"
+	project.Context.AWSProfile = "dev"
+	project.Tmux.Layout = "main-vertical"
+	project.Tmux.Windows = []config.TmuxWindow{
+		{Name: "editor", Command: "nvim"},
+		{Path: "/work/my.app/api", Command: "go test ./..."},
+		{Name: "docs", Path: "docs"},
+	}
+
+	if err := createDetached(project); err != nil {
+		t.Fatalf("createDetached: %v", err)
+	}
+
+	want := []string{
+		"tmux new-session -ds my_app -n editor -c /work/my.app -e AWS_PROFILE=dev -e PK_BILLABLE=false -e PK_PROJECT_ID=my.app",
+		"tmux display-message -p -t my_app: #{window_index} #{pane_index}",
+		"tmux send-keys -t my_app:1.1 nvim Enter",
+		"tmux new-window -t my_app:2 -n window-2 -c /work/my.app/api",
+		"tmux send-keys -t my_app:2.1 go test ./... Enter",
+		"tmux new-window -t my_app:3 -n docs -c /work/my.app/docs",
+		"tmux select-layout -t my_app main-vertical",
+	}
+	if got := fake.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildLayoutDefaultIndices(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("tmux display-message", "0 0\n", nil)
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/api"}
+	project.ProjectInfo.ID = "api"
+	project.Tmux.Windows = []config.TmuxWindow{{Name: "shell"}, {Name: "logs", Command: "tail -f log"}}
+
+	if err := buildLayout(project); err != nil {
+		t.Fatalf("buildLayout: %v", err)
+	}
+
+	got := fake.Commands()
+	want := []string{
+		"tmux new-session -ds api -n shell -c /work/api -e PK_BILLABLE=false -e PK_PROJECT_ID=api",
+		"tmux display-message -p -t api: #{window_index} #{pane_index}",
+		"tmux new-window -t api:1 -n logs -c /work/api",
+		"tmux send-keys -t api:1.0 tail -f log Enter",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commands:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestCreateSessionWithoutAttach(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	fake.On("tmux has-session", "", errors.New("can't find session"))
+	defer runner.Swap(fake)()
+
+	attach := false
+	project := &config.Project{Path: "/work/api"}
+	project.ProjectInfo.ID = "api"
+	project.Tmux.Attach = &attach
+
+	if err := CreateSession(project); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	for _, c := range fake.Commands() {
+		if strings.Contains(c, "attach-session") || strings.Contains(c, "switch-client") {
+			t.Errorf("attach = false still ran %q", c)
+		}
+	}
+	if got := fake.Commands(); len(got) != 2 || got[1] != "tmux new-session -ds api -c /work/api -e PK_BILLABLE=false -e PK_PROJECT_ID=api" {
+		t.Errorf("commands = %q", got)
+	}
+}
+
+func TestCreateDetachedReportsTmuxFailure(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	fake.On("tmux new-session", "", errors.New("server exited unexpectedly"))
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/api"}
+	project.ProjectInfo.ID = "api"
+
+	if err := createDetached(project); err == nil {
+		t.Fatal("expected an error when new-session fails")
+	}
+	if n := len(fake.Calls()); n != 1 {
+		t.Errorf("ran %d commands after new-session failed, want 1", n)
+	}
+}
+
+func TestBuildLayoutPanes(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("tmux display-message", "1 1\n", nil)
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/api"}
+	project.ProjectInfo.ID = "api"
+	project.Tmux.Windows = []config.TmuxWindow{{
+		Name:    "dev",
+		Command: "nvim",
+		Panes: []config.TmuxPane{
+			{Split: "horizontal", Size: 40, Command: "make serve"},
+			{Path: "logs", Command: "tail -f app.log"},
+		},
+	}}
+
+	if err := buildLayout(project); err != nil {
+		t.Fatalf("buildLayout: %v", err)
+	}
+
+	got := fake.Commands()
+	want := []string{
+		"tmux new-session -ds api -n dev -c /work/api -e PK_BILLABLE=false -e PK_PROJECT_ID=api",
+		"tmux display-message -p -t api: #{window_index} #{pane_index}",
+		"tmux send-keys -t api:1.1 nvim Enter",
+		"tmux split-window -t api:1.1 -h -l 40% -c /work/api",
+		"tmux send-keys -t api:1.2 make serve Enter",
+		"tmux split-window -t api:1.2 -v -c /work/api/logs",
+		"tmux send-keys -t api:1.3 tail -f app.log Enter",
+		"tmux select-pane -t api:1.1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commands:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildLayoutExpandsMetadata(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("tmux display-message", "1 1\n", nil)
+	defer runner.Swap(fake)()
+
+	// A layout shared with other projects
+	shared := []config.TmuxWindow{{
+		Name:    "cloud",
+		Command: "aws sso login --profile {{.Context.AWSProfile}}",
+		Panes:   []config.TmuxPane{{Command: "echo {{.Consultant.ClientName}}"}},
+	}}
+	project := &config.Project{Path: "/work/api"}
+	project.ProjectInfo.ID = "api"
+	project.Context.AWSProfile = "acme"
+	project.Consultant.ClientName = "Acme"
+	project.Tmux.Windows = shared
+
+	if err := buildLayout(project); err != nil {
+		t.Fatalf("buildLayout: %v", err)
+	}
+	got := strings.Join(fake.Commands(), "\n")
+	for _, want := range []string{"send-keys -t api:1.1 aws sso login --profile acme Enter", "send-keys -t api:1.2 echo Acme Enter"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if shared[0].Command != "aws sso login --profile {{.Context.AWSProfile}}" || shared[0].Panes[0].Command != "echo {{.Consultant.ClientName}}" {
+		t.Errorf("the shared layout was modified: %+v", shared[0])
+	}
+
+	// Bad references fail before tmux is touched
+	fake = runner.NewFake()
+	defer runner.Swap(fake)()
+	project.Tmux.Windows = []config.TmuxWindow{{Command: "psql {{.Context.Database}}"}}
+	if err := buildLayout(project); err == nil || !strings.Contains(err.Error(), "window-1") {
+		t.Errorf("expected an error naming the window, got %v", err)
+	}
+	if n := len(fake.Calls()); n != 0 {
+		t.Errorf("ran %d commands for a layout that can't expand", n)
+	}
+}
@@ -0,0 +1,297 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+	pkcontext "github.com/datakaicr/pk/pkg/context"
+	"github.com/datakaicr/pk/pkg/deps"
+	"github.com/datakaicr/pk/pkg/runner"
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// Zellij runs project sessions in zellij. [tmux] windows become tabs and
+// their panes nested splits, written to a KDL layout under
+// ~/.cache/pk/zellij. Window commands run in the user's shell, which stays
+// open when they exit, as with tmux send-keys. tmux's named layouts
+// (layout = "main-vertical") have no zellij equivalent and are ignored.
+type Zellij struct{}
+
+// Name implements Multiplexer
+func (Zellij) Name() string { return "zellij" }
+
+// Check implements Multiplexer
+func (Zellij) Check() error {
+	return deps.Require("'pk session'", "zellij")
+}
+
+// IsInZellij checks if currently inside a zellij session
+func IsInZellij() bool {
+	return os.Getenv("ZELLIJ") != ""
+}
+
+// Exists reports whether a zellij session is running. Exited sessions
+// zellij keeps for resurrection don't count.
+func (z Zellij) Exists(name string) bool {
+	sessions, _ := z.List()
+	for _, s := range sessions {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the running zellij sessions
+func (Zellij) List() ([]string, error) {
+	output, err := runner.Output(runner.Command("zellij", "list-sessions", "--no-formatting"))
+	if err != nil {
+		// No sessions is not an error
+		return []string{}, nil
+	}
+	return parseZellijSessions(string(output)), nil
+}
+
+// parseZellijSessions parses 'zellij list-sessions --no-formatting' output,
+// "name [Created 2h ago] (current)" per line, skipping exited sessions
+func parseZellijSessions(output string) []string {
+	sessions := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.Contains(line, "(EXITED") {
+			continue
+		}
+		sessions = append(sessions, fields[0])
+	}
+	return sessions
+}
+
+// Create starts the project's session with its layout and attaches to it.
+// Inside zellij, where sessions can't nest, it starts in the background.
+func (z Zellij) Create(project *config.Project) error {
+	if IsInZellij() {
+		if err := z.CreateDetached(project); err != nil {
+			return err
+		}
+		return z.Attach(SanitizeSessionName(project.ProjectInfo.ID))
+	}
+
+	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
+	layout, err := writeZellijLayout(project)
+	if err != nil {
+		return err
+	}
+
+	// An exited session of the same name would be resurrected instead
+	runner.Run(runner.Command("zellij", "delete-session", sessionName))
+
+	var cmd *exec.Cmd
+	if layout == "" {
+		cmd = runner.Command("zellij", "attach", "--create", sessionName)
+	} else {
+		cmd = runner.Command("zellij", "--session", sessionName, "--new-session-with-layout", layout)
+	}
+	cmd.Dir = project.Path
+	cmd.Env = zellijEnv(project)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return runner.Run(cmd)
+}
+
+// CreateDetached starts the project's session in the background
+func (Zellij) CreateDetached(project *config.Project) error {
+	sessionName := SanitizeSessionName(project.ProjectInfo.ID)
+	layout, err := writeZellijLayout(project)
+	if err != nil {
+		return err
+	}
+
+	runner.Run(runner.Command("zellij", "delete-session", sessionName))
+
+	args := []string{"attach", "--create-background", sessionName}
+	if layout != "" {
+		args = append(args, "options", "--default-layout", layout)
+	}
+	cmd := runner.Command("zellij", args...)
+	cmd.Dir = project.Path
+	cmd.Env = zellijEnv(project)
+	if err := runner.Run(cmd); err != nil {
+		return fmt.Errorf("failed to create zellij session: %w", err)
+	}
+	return nil
+}
+
+// Attach attaches to a running session. zellij can't switch sessions from
+// the command line, so inside zellij it says how to get there instead.
+func (Zellij) Attach(name string) error {
+	if IsInZellij() {
+		fmt.Fprintf(os.Stderr, "Session '%s' is running; switch to it with the session manager (Ctrl o w)\n", name)
+		return nil
+	}
+
+	cmd := runner.Command("zellij", "attach", name)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return runner.Run(cmd)
+}
+
+// Kill ends a session and drops it from zellij's resurrection list
+func (Zellij) Kill(name string) error {
+	return runner.Run(runner.Command("zellij", "delete-session", "--force", name))
+}
+
+// Rename renames a zellij session, running the action in that session
+func (Zellij) Rename(name, newName string) error {
+	return runner.Run(runner.Command("zellij", "--session", name, "action", "rename-session", newName))
+}
+
+// Current returns the name of the zellij session pk is running in
+func (Zellij) Current() (string, error) {
+	name := os.Getenv("ZELLIJ_SESSION_NAME")
+	if name == "" {
+		return "", fmt.Errorf("not inside a zellij session")
+	}
+	return name, nil
+}
+
+// zellijEnv is pk's environment plus the project's session variables.
+// zellij has no session environment; the server inherits it on start.
+func zellijEnv(project *config.Project) []string {
+	env := pkcontext.Env(project)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	vars := os.Environ()
+	for _, k := range keys {
+		vars = append(vars, k+"="+env[k])
+	}
+	return vars
+}
+
+// writeZellijLayout writes the project's layout for zellij and returns its
+// path, or "" when there are no windows and zellij's default layout applies
+func writeZellijLayout(project *config.Project) (string, error) {
+	settings.ApplyDefaultLayout(project)
+	if len(project.Tmux.Windows) == 0 {
+		return "", nil
+	}
+	windows, err := expandedWindows(project)
+	if err != nil {
+		return "", err
+	}
+	expanded := *project
+	expanded.Tmux.Windows = windows
+
+	dir, err := statefile.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "zellij")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	path := filepath.Join(dir, SanitizeSessionName(project.ProjectInfo.ID)+".kdl")
+	if err := os.WriteFile(path, []byte(zellijLayout(&expanded, shell)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write zellij layout: %w", err)
+	}
+	return path, nil
+}
+
+// zellijLayout translates the project's windows into a KDL layout: a tab
+// per window, with zellij's tab and status bars. A window's panes nest so
+// each splits the one before it, as they do in tmux.
+func zellijLayout(project *config.Project, shell string) string {
+	var b strings.Builder
+	b.WriteString("layout {\n")
+	b.WriteString("    default_tab_template {\n")
+	b.WriteString("        pane size=1 borderless=true {\n")
+	b.WriteString("            plugin location=\"zellij:tab-bar\"\n")
+	b.WriteString("        }\n")
+	b.WriteString("        children\n")
+	b.WriteString("        pane size=2 borderless=true {\n")
+	b.WriteString("            plugin location=\"zellij:status-bar\"\n")
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+
+	for i, window := range project.Tmux.Windows {
+		focus := ""
+		if i == 0 {
+			focus = " focus=true"
+		}
+		fmt.Fprintf(&b, "    tab name=%s%s {\n", kdlString(windowName(window, i)), focus)
+		writeZellijPane(&b, project, window, 0, "", shell, 2)
+		b.WriteString("    }\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writeZellijPane writes pane j of a window (0 is the window's own pane)
+// and, nested beside it, the panes split off after it
+func writeZellijPane(b *strings.Builder, project *config.Project, window config.TmuxWindow, j int, size, shell string, depth int) {
+	indent := strings.Repeat("    ", depth)
+	if j == len(window.Panes) {
+		writeZellijLeaf(b, project, window, j, size, shell, indent)
+		return
+	}
+
+	next := window.Panes[j]
+	// tmux splits vertically by stacking panes; zellij calls that horizontal
+	direction := "horizontal"
+	if next.Split == "horizontal" {
+		direction = "vertical"
+	}
+	nextSize := ""
+	if next.Size > 0 {
+		nextSize = fmt.Sprintf(" size=\"%d%%\"", next.Size)
+	}
+
+	fmt.Fprintf(b, "%spane split_direction=%q%s {\n", indent, direction, size)
+	writeZellijLeaf(b, project, window, j, "", shell, indent+"    ")
+	writeZellijPane(b, project, window, j+1, nextSize, shell, depth+1)
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// writeZellijLeaf writes pane j itself: its directory and command
+func writeZellijLeaf(b *strings.Builder, project *config.Project, window config.TmuxWindow, j int, size, shell, indent string) {
+	path, command := windowPath(project, window), window.Command
+	if j > 0 {
+		pane := window.Panes[j-1]
+		command = pane.Command
+		if pane.Path != "" {
+			path = projectPath(project, pane.Path)
+		}
+	}
+
+	if command == "" {
+		fmt.Fprintf(b, "%spane cwd=%s%s\n", indent, kdlString(path), size)
+		return
+	}
+	// Run the command in an interactive shell that stays open afterwards
+	fmt.Fprintf(b, "%spane cwd=%s command=%s%s {\n", indent, kdlString(path), kdlString(shell), size)
+	fmt.Fprintf(b, "%s    args \"-ic\" %s\n", indent, kdlString(command+"; exec "+shell))
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// kdlString quotes s as a KDL string
+func kdlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
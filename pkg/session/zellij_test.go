@@ -0,0 +1,112 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/runner"
+)
+
+func TestParseZellijSessions(t *testing.T) {
+	output := `pk [Created 3m 2s ago] (current)
+acme-etl [Created 1h ago]
+old [Created 2days ago] (EXITED - attach to resurrect)
+`
+	want := []string{"pk", "acme-etl"}
+	if got := parseZellijSessions(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseZellijSessions = %q, want %q", got, want)
+	}
+	if got := parseZellijSessions(""); len(got) != 0 {
+		t.Errorf("empty output should have no sessions, got %q", got)
+	}
+}
+
+func TestZellijLayout(t *testing.T) {
+	project := &config.Project{Path: "/work/api"}
+	project.Tmux.Windows = []config.TmuxWindow{
+		{
+			Name:    "dev",
+			Command: "nvim",
+			Panes: []config.TmuxPane{
+				{Split: "horizontal", Size: 40, Command: "make serve"},
+				{Path: "logs"},
+			},
+		},
+		{Command: `echo "hi"`},
+	}
+
+	want := `layout {
+    default_tab_template {
+        pane size=1 borderless=true {
+            plugin location="zellij:tab-bar"
+        }
+        children
+        pane size=2 borderless=true {
+            plugin location="zellij:status-bar"
+        }
+    }
+    tab name="dev" focus=true {
+        pane split_direction="vertical" {
+            pane cwd="/work/api" command="/bin/zsh" {
+                args "-ic" "nvim; exec /bin/zsh"
+            }
+            pane split_direction="horizontal" size="40%" {
+                pane cwd="/work/api" command="/bin/zsh" {
+                    args "-ic" "make serve; exec /bin/zsh"
+                }
+                pane cwd="/work/api/logs"
+            }
+        }
+    }
+    tab name="window-2" {
+        pane cwd="/work/api" command="/bin/zsh" {
+            args "-ic" "echo \"hi\"; exec /bin/zsh"
+        }
+    }
+}
+`
+	if got := zellijLayout(project, "/bin/zsh"); got != want {
+		t.Errorf("zellijLayout:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestZellijCreateDetached(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	home := t.TempDir()
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	fake := runner.NewFake()
+	defer runner.Swap(fake)()
+
+	project := &config.Project{Path: "/work/api"}
+	project.ProjectInfo.ID = "api"
+	project.Tmux.Env = map[string]string{"DBT_TARGET": "dev"}
+	project.Tmux.Windows = []config.TmuxWindow{{Name: "dev"}}
+
+	if err := (Zellij{}).CreateDetached(project); err != nil {
+		t.Fatalf("CreateDetached: %v", err)
+	}
+
+	layout := filepath.Join(home, ".cache", "pk", "zellij", "api.kdl")
+	if data, err := os.ReadFile(layout); err != nil || !strings.Contains(string(data), `tab name="dev"`) {
+		t.Errorf("layout file %s: %v %q", layout, err, data)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("commands = %q", fake.Commands())
+	}
+	if want := "zellij attach --create-background api options --default-layout " + layout; calls[1].String() != want {
+		t.Errorf("command = %q, want %q", calls[1].String(), want)
+	}
+	if calls[1].Dir != "/work/api" || !slices.Contains(calls[1].Env, "DBT_TARGET=dev") ||
+		!slices.Contains(calls[1].Env, "PK_PROJECT_ID=api") {
+		t.Errorf("session should start in the project with its env, got dir %q", calls[1].Dir)
+	}
+}
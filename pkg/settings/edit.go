@@ -0,0 +1,414 @@
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/paths"
+)
+
+// settingTypes are decoded from config.toml; a key is valid if one of them
+// has a field (or map entry) at that path
+var settingTypes = []reflect.Type{
+	reflect.TypeOf(Settings{}),
+	reflect.TypeOf(paths.Config{}),
+}
+
+// settingEnums restrict string settings to fixed values
+var settingEnums = map[string][]string{
+	"multiplexer":   {"tmux", "zellij"},
+	"ids.separator": {"-", "_"},
+	"cache.backend": {"json", "sqlite"},
+	"state.backend": {"json", "sqlite", "http"},
+}
+
+// Keys lists the settings 'pk config set' accepts. Map entries appear as
+// <name>, e.g. "formats.<name>".
+func Keys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch {
+		case t.Kind() == reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				if name := tomlKey(t.Field(i)); name != "" {
+					walk(t.Field(i).Type, joinKey(prefix, name))
+				}
+			}
+		case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			walk(t.Elem(), joinKey(prefix, "<name>"))
+		case isSettingLeaf(t):
+			keys = append(keys, prefix)
+		}
+	}
+	for _, t := range settingTypes {
+		walk(t, "")
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value set for key in config.toml, and whether it is set
+func Get(key string) (string, bool, error) {
+	doc, err := readConfigDoc()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := lookupKey(doc, key)
+	if !ok {
+		return "", false, nil
+	}
+	return formatSetting(value), true, nil
+}
+
+// List returns every value set in config.toml as flattened dotted keys
+func List() ([]config.Field, error) {
+	doc, err := readConfigDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []config.Field
+	var walk func(table map[string]interface{}, prefix string)
+	walk = func(table map[string]interface{}, prefix string) {
+		for _, name := range SortedKeys(table) {
+			key := joinKey(prefix, name)
+			if sub, ok := table[name].(map[string]interface{}); ok {
+				walk(sub, key)
+				continue
+			}
+			fields = append(fields, config.Field{Key: key, Value: formatSetting(table[name])})
+		}
+	}
+	walk(doc, "")
+	return fields, nil
+}
+
+// Set checks value against the setting's type and writes it into
+// config.toml in place, leaving the rest of the file (comments included)
+// untouched. The file is only written if it still parses afterwards.
+func Set(key, raw string) error {
+	t, err := settingType(key)
+	if err != nil {
+		return err
+	}
+	value, err := parseSetting(t, key, raw)
+	if err != nil {
+		return err
+	}
+
+	configPath, err := ConfigFile()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	updated, err := setKey(data, key, value)
+	if err != nil {
+		return err
+	}
+	if err := verifySet(updated, key, value); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, updated, 0644)
+}
+
+// settingType returns the Go type of the setting at a dotted key
+func settingType(key string) (reflect.Type, error) {
+	parts := strings.Split(key, ".")
+	for _, root := range settingTypes {
+		t := root
+		found := true
+		for _, part := range parts {
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.Struct:
+				field, ok := fieldByTOMLKey(t, part)
+				if !ok {
+					found = false
+					break
+				}
+				t = field.Type
+			case reflect.Map:
+				t = t.Elem()
+			default:
+				found = false
+			}
+			if !found {
+				break
+			}
+		}
+		if found && isSettingLeaf(t) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown setting %q (see 'pk config list --keys')", key)
+}
+
+// isSettingLeaf reports whether a setting can be written from one string
+func isSettingLeaf(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// parseSetting converts raw to the setting's type. Lists are comma-separated.
+func parseSetting(t reflect.Type, key, raw string) (interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s (want true or false)", raw, key)
+		}
+		return b, nil
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s (want a number)", raw, key)
+		}
+		return n, nil
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+
+	if allowed, ok := settingEnums[key]; ok && !slices.Contains(allowed, raw) {
+		return nil, fmt.Errorf("invalid value %q for %s (want %s)", raw, key, strings.Join(allowed, ", "))
+	}
+	return raw, nil
+}
+
+// tableHeader matches a [table] line (not [[array]] tables)
+var tableHeader = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+
+// headerDots matches a dot between table name segments, with any spacing
+var headerDots = regexp.MustCompile(`\s*\.\s*`)
+
+// setKey rewrites the key's line in its table, or adds it (and the table)
+func setKey(data []byte, key string, value interface{}) ([]byte, error) {
+	table, leaf := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, leaf = key[:i], key[i+1:]
+	}
+
+	if table != "" {
+		segments := strings.Split(table, ".")
+		for i, segment := range segments {
+			segments[i] = tomlBareOrQuoted(segment)
+		}
+		table = strings.Join(segments, ".")
+	}
+
+	line, err := encodeLine(leaf, value)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	keyLine := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(tomlBareOrQuoted(leaf)) + `\s*=`)
+
+	current := ""
+	inTable := table == ""
+	lastInTable := -1
+	for i, l := range lines {
+		if m := tableHeader.FindStringSubmatch(l); m != nil {
+			current = headerDots.ReplaceAllString(m[1], ".")
+			inTable = current == table
+			if inTable {
+				lastInTable = i
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(l), "[[") {
+			inTable = false
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		if keyLine.MatchString(l) {
+			indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+			lines[i] = indent + line
+			return []byte(strings.Join(lines, "\n") + "\n"), nil
+		}
+		if strings.TrimSpace(l) != "" {
+			lastInTable = i
+		}
+	}
+
+	switch {
+	case lastInTable >= 0:
+		lines = slices.Insert(lines, lastInTable+1, line)
+	case table == "":
+		lines = append([]string{line}, lines...)
+	default:
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+table+"]", line)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// encodeLine renders "key = value" as TOML
+func encodeLine(leaf string, value interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]interface{}{leaf: value}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// tomlBareOrQuoted returns a key as it would be written in TOML
+func tomlBareOrQuoted(key string) string {
+	line, err := encodeLine(key, "")
+	if err != nil {
+		return key
+	}
+	k, _, _ := strings.Cut(line, " =")
+	return k
+}
+
+// verifySet re-reads the edited file and checks the key now holds value.
+// Files that define the table inline or with dotted keys can't be edited
+// line by line; this catches that before anything is written.
+func verifySet(data []byte, key string, value interface{}) error {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return fmt.Errorf("can't update %s in place (%v); edit config.toml by hand", key, err)
+	}
+	got, ok := lookupKey(doc, key)
+	if !ok || formatSetting(got) != formatSetting(value) {
+		return fmt.Errorf("can't update %s in place; edit config.toml by hand", key)
+	}
+
+	var s Settings
+	if _, err := toml.Decode(string(data), &s); err != nil {
+		return fmt.Errorf("config.toml would no longer load: %v", err)
+	}
+	var p paths.Config
+	if _, err := toml.Decode(string(data), &p); err != nil {
+		return fmt.Errorf("config.toml would no longer load: %v", err)
+	}
+	return nil
+}
+
+// readConfigDoc decodes config.toml without a schema. Missing is empty.
+func readConfigDoc() (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	configPath, err := ConfigFile()
+	if err != nil {
+		return doc, err
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return doc, nil
+	}
+	if _, err := toml.DecodeFile(configPath, &doc); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
+
+// lookupKey finds a dotted key in a decoded TOML document
+func lookupKey(doc map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(key, ".") {
+		table, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = table[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// formatSetting renders a setting value as 'pk config get' prints it
+func formatSetting(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ", ")
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatSetting(item))
+		}
+		return strings.Join(items, ", ")
+	case []map[string]interface{}:
+		return fmt.Sprintf("(%d tables)", len(v))
+	}
+	return fmt.Sprint(value)
+}
+
+// fieldByTOMLKey finds the struct field serialized under name
+func fieldByTOMLKey(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if tomlKey(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// tomlKey returns the TOML key of a struct field, or "" if not serialized
+func tomlKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
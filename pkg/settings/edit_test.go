@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetKeyInPlace(t *testing.T) {
+	original := `# Global pk config
+[shell]
+# Record access on cd
+cd_hook = true
+
+[formats]
+csv = "x"
+`
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{"replace keeps comments", "shell.cd_hook", false,
+			"# Global pk config\n[shell]\n# Record access on cd\ncd_hook = false\n\n[formats]\ncsv = \"x\"\n"},
+		{"append to existing table", "formats.ids", "{{.ProjectInfo.ID}}",
+			"# Global pk config\n[shell]\n# Record access on cd\ncd_hook = true\n\n[formats]\ncsv = \"x\"\nids = \"{{.ProjectInfo.ID}}\"\n"},
+		{"new table at the end", "state.backend", "sqlite",
+			original + "\n[state]\nbackend = \"sqlite\"\n"},
+		{"quoted key and table", "editor.vscode_profiles.Acme Corp", "acme",
+			original + "\n[editor.vscode_profiles]\n\"Acme Corp\" = \"acme\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setKey([]byte(original), tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("setKey: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if err := verifySet(got, tt.key, tt.value); err != nil {
+				t.Errorf("verifySet: %v", err)
+			}
+		})
+	}
+}
+
+func TestSetValidatesBeforeWriting(t *testing.T) {
+	home := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	configPath := filepath.Join(home, ".config", "pk", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	inline := "[editor]\nvscode_profiles = { acme = \"acme\" }\n"
+	os.WriteFile(configPath, []byte(inline), 0644)
+
+	for _, tt := range []struct{ key, value string }{
+		{"nope.key", "x"},
+		{"state.backend", "mysql"},
+		{"shell.cd_hook", "maybe"},
+		{"layout_rules", "x"},
+		// Inline table: a new [editor.vscode_profiles] header would redefine it
+		{"editor.vscode_profiles.other", "other"},
+	} {
+		if err := Set(tt.key, tt.value); err == nil {
+			t.Errorf("Set(%q, %q) should fail", tt.key, tt.value)
+		}
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if string(data) != inline {
+		t.Errorf("config.toml changed after rejected sets:\n%s", data)
+	}
+
+	if err := Set("shell.cd_hook", "false"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.CDHookEnabled() {
+		t.Error("cd_hook should be disabled after Set")
+	}
+	if value, set, _ := Get("shell.cd_hook"); !set || value != "false" {
+		t.Errorf("Get = %q, %v", value, set)
+	}
+}
+
+func TestKeysCoverSettingsAndPaths(t *testing.T) {
+	keys := strings.Join(Keys(), "\n")
+	for _, want := range []string{"shell.cd_hook", "state.backend", "formats.<name>", "paths.projects"} {
+		if !strings.Contains(keys, want) {
+			t.Errorf("Keys() missing %s", want)
+		}
+	}
+}
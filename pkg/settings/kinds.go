@@ -0,0 +1,247 @@
+package settings
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// Kind bundles defaults for a family of projects, selected by
+// [project] kind = "<name>". Defined in [kinds.<name>]; a kind with the same
+// name as a built-in extends it field by field.
+type Kind struct {
+	Description string            `toml:"description"`
+	Layout      string            `toml:"layout"`   // Named layout from [layouts]
+	Tmux        Layout            `toml:"tmux"`     // Inline layout, used when layout is unset
+	Env         map[string]string `toml:"env"`      // Session environment
+	Commands    map[string]string `toml:"commands"` // Named commands for 'pk run'
+	Detect      []string          `toml:"detect"`   // Globs relative to the project root; all must match
+	Report      map[string]string `toml:"report"`   // Label -> Go template shown by 'pk show'
+}
+
+// builtinKindOrder is also detection priority: more specific kinds first
+var builtinKindOrder = []string{"dbt", "terraform-module", "go-cli", "node-web", "python-package"}
+
+// BuiltinKinds ship with pk
+var BuiltinKinds = map[string]Kind{
+	"dbt": {
+		Description: "dbt analytics project",
+		Tmux: Layout{Windows: []config.TmuxWindow{
+			{Name: "editor", Command: "nvim"},
+			{Name: "dbt"},
+		}},
+		Commands: map[string]string{
+			"build": "dbt build",
+			"test":  "dbt test",
+			"deps":  "dbt deps",
+			"docs":  "dbt docs generate && dbt docs serve",
+		},
+		Detect: []string{"dbt_project.yml"},
+		Report: map[string]string{
+			"Snowflake":  `{{default "-" .Context.SnowflakeAccount}}`,
+			"Databricks": `{{default "-" .Context.DatabricksProfile}}`,
+		},
+	},
+	"terraform-module": {
+		Description: "Terraform module",
+		Tmux: Layout{Windows: []config.TmuxWindow{
+			{Name: "editor", Command: "nvim"},
+			{Name: "terraform"},
+		}},
+		Commands: map[string]string{
+			"fmt":      "terraform fmt -recursive",
+			"validate": "terraform init -backend=false && terraform validate",
+			"plan":     "terraform plan",
+		},
+		Detect: []string{"*.tf"},
+		Report: map[string]string{
+			"AWS profile": `{{default "-" .Context.AWSProfile}}`,
+			"Azure":       `{{default "-" .Context.AzureSubscription}}`,
+		},
+	},
+	"go-cli": {
+		Description: "Go command-line tool",
+		Tmux: Layout{Windows: []config.TmuxWindow{
+			{Name: "editor", Command: "nvim"},
+			{Name: "shell"},
+		}},
+		Commands: map[string]string{
+			"build": "go build ./...",
+			"test":  "go test ./...",
+			"vet":   "go vet ./...",
+		},
+		Detect: []string{"go.mod", "main.go"},
+	},
+	"node-web": {
+		Description: "Node.js web app",
+		Tmux: Layout{Windows: []config.TmuxWindow{
+			{Name: "editor", Command: "nvim"},
+			{Name: "server", Command: "npm run dev"},
+			{Name: "shell"},
+		}},
+		Commands: map[string]string{
+			"dev":   "npm run dev",
+			"build": "npm run build",
+			"test":  "npm test",
+		},
+		Detect: []string{"package.json"},
+	},
+	"python-package": {
+		Description: "Python package",
+		Tmux: Layout{Windows: []config.TmuxWindow{
+			{Name: "editor", Command: "nvim"},
+			{Name: "shell"},
+		}},
+		Commands: map[string]string{
+			"test": "pytest",
+		},
+		Detect: []string{"pyproject.toml"},
+	},
+}
+
+// AllKinds returns built-in kinds merged with [kinds] from config
+func (s *Settings) AllKinds() map[string]Kind {
+	kinds := make(map[string]Kind, len(BuiltinKinds)+len(s.Kinds))
+	for name, k := range BuiltinKinds {
+		kinds[name] = k
+	}
+	for name, k := range s.Kinds {
+		kinds[name] = mergeKind(kinds[name], k)
+	}
+	return kinds
+}
+
+// KindNames lists kinds in detection order: config-only kinds (sorted),
+// then built-ins from most to least specific
+func (s *Settings) KindNames() []string {
+	var custom []string
+	for name := range s.Kinds {
+		if _, builtin := BuiltinKinds[name]; !builtin {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(custom, builtinKindOrder...)
+}
+
+// KindFor returns the kind named by the project's [project] kind
+func (s *Settings) KindFor(p *config.Project) (Kind, bool) {
+	if p.ProjectInfo.Kind == "" {
+		return Kind{}, false
+	}
+	k, exists := s.AllKinds()[p.ProjectInfo.Kind]
+	return k, exists
+}
+
+// DetectKind returns the first kind whose detection globs all match in dir,
+// or "" if none do
+func (s *Settings) DetectKind(dir string) string {
+	kinds := s.AllKinds()
+	for _, name := range s.KindNames() {
+		if kinds[name].Matches(dir) {
+			return name
+		}
+	}
+	return ""
+}
+
+// DetectKind loads settings and detects the kind of dir
+func DetectKind(dir string) string {
+	s, err := Load()
+	if err != nil {
+		return ""
+	}
+	return s.DetectKind(dir)
+}
+
+// Matches reports whether every detection glob matches a file in dir.
+// Kinds without detection rules never match.
+func (k Kind) Matches(dir string) bool {
+	if len(k.Detect) == 0 {
+		return false
+	}
+	for _, pattern := range k.Detect {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil || len(matches) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LayoutIn resolves the kind's layout: the named layout if it exists in s,
+// otherwise the inline [tmux] one. Returns nil if the kind has neither.
+func (k Kind) LayoutIn(s *Settings) *Layout {
+	if k.Layout != "" {
+		if layout, exists := s.Layouts[k.Layout]; exists {
+			return &layout
+		}
+	}
+	if k.Tmux.Layout != "" || len(k.Tmux.Windows) > 0 {
+		layout := k.Tmux
+		return &layout
+	}
+	return nil
+}
+
+// KindEnv returns the session environment from the project's kind
+func KindEnv(p *config.Project) map[string]string {
+	s, err := Load()
+	if err != nil {
+		return nil
+	}
+	k, _ := s.KindFor(p)
+	return k.Env
+}
+
+// mergeKind overlays override onto base: set fields replace, maps merge by key
+func mergeKind(base, override Kind) Kind {
+	merged := base
+	if override.Description != "" {
+		merged.Description = override.Description
+	}
+	if override.Layout != "" {
+		merged.Layout = override.Layout
+	}
+	if override.Tmux.Layout != "" || len(override.Tmux.Windows) > 0 {
+		merged.Tmux = override.Tmux
+	}
+	if len(override.Detect) > 0 {
+		merged.Detect = override.Detect
+	}
+	merged.Env = mergeMap(base.Env, override.Env)
+	merged.Commands = mergeMap(base.Commands, override.Commands)
+	merged.Report = mergeMap(base.Report, override.Report)
+	return merged
+}
+
+func mergeMap(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// SortedKeys returns the keys of a kind's map field in order
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsBuiltinKind reports whether name ships with pk (possibly extended in config)
+func IsBuiltinKind(name string) bool {
+	_, exists := BuiltinKinds[name]
+	return exists
+}
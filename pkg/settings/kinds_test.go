@@ -0,0 +1,117 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func touch(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	s := &Settings{
+		Kinds: map[string]Kind{
+			"airflow": {Detect: []string{"dags"}},
+		},
+	}
+
+	tests := []struct {
+		files    []string
+		expected string
+	}{
+		{[]string{"dbt_project.yml", "pyproject.toml"}, "dbt"}, // dbt wins over python
+		{[]string{"main.tf", "variables.tf"}, "terraform-module"},
+		{[]string{"go.mod", "main.go"}, "go-cli"},
+		{[]string{"go.mod"}, ""}, // Library, not a CLI
+		{[]string{"package.json"}, "node-web"},
+		{[]string{"dags", "pyproject.toml"}, "airflow"}, // Config kinds checked first
+		{[]string{"README.md"}, ""},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		touch(t, dir, tt.files...)
+		if got := s.DetectKind(dir); got != tt.expected {
+			t.Errorf("DetectKind(%v) = %q, expected %q", tt.files, got, tt.expected)
+		}
+	}
+}
+
+func TestAllKindsMergesConfig(t *testing.T) {
+	s := &Settings{
+		Kinds: map[string]Kind{
+			"dbt": {
+				Env:      map[string]string{"DBT_TARGET": "dev"},
+				Commands: map[string]string{"test": "dbt test --fail-fast", "lint": "sqlfluff lint"},
+			},
+		},
+	}
+
+	dbt := s.AllKinds()["dbt"]
+	if dbt.Description != BuiltinKinds["dbt"].Description {
+		t.Errorf("Expected built-in description to survive, got %q", dbt.Description)
+	}
+	if dbt.Commands["test"] != "dbt test --fail-fast" {
+		t.Errorf("Expected config to override test command, got %q", dbt.Commands["test"])
+	}
+	if dbt.Commands["build"] != "dbt build" || dbt.Commands["lint"] != "sqlfluff lint" {
+		t.Errorf("Expected built-in and added commands, got %v", dbt.Commands)
+	}
+	if dbt.Env["DBT_TARGET"] != "dev" {
+		t.Errorf("Expected env from config, got %v", dbt.Env)
+	}
+
+	// Merging must not leak into the built-in definition
+	if _, leaked := BuiltinKinds["dbt"].Commands["lint"]; leaked {
+		t.Error("Config commands leaked into BuiltinKinds")
+	}
+}
+
+func TestApplyDefaultLayoutFromKind(t *testing.T) {
+	tmpHome := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpHome)
+	defer os.Setenv("HOME", originalHome)
+
+	configDir := filepath.Join(tmpHome, ".config", "pk")
+	os.MkdirAll(configDir, 0755)
+	content := `[layouts.wide]
+windows = [{name = "wide"}]
+
+[[layout_rules]]
+layout = "wide"
+
+[kinds.infra]
+layout = "missing"
+tmux = {windows = [{name = "plan", command = "terraform plan"}]}
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Kind beats layout rules; a missing named layout falls back to inline
+	p := &config.Project{}
+	p.ProjectInfo.Kind = "infra"
+	if name := ApplyDefaultLayout(p); name != "infra" {
+		t.Errorf("Expected kind layout, got %q", name)
+	}
+	if len(p.Tmux.Windows) != 1 || p.Tmux.Windows[0].Command != "terraform plan" {
+		t.Errorf("Expected inline kind windows, got %v", p.Tmux.Windows)
+	}
+
+	// Unknown kinds fall through to layout rules
+	other := &config.Project{}
+	other.ProjectInfo.Kind = "nope"
+	if name := ApplyDefaultLayout(other); name != "wide" {
+		t.Errorf("Expected layout rule for unknown kind, got %q", name)
+	}
+}
@@ -0,0 +1,92 @@
+package settings
+
+import (
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// Layout is a reusable tmux layout, same shape as a project's [tmux] section
+type Layout struct {
+	Layout  string              `toml:"layout"`
+	Windows []config.TmuxWindow `toml:"windows"`
+}
+
+// LayoutRule selects a named layout for projects matching type and/or stack.
+// Empty criteria match everything, so a rule with only a layout acts as default.
+type LayoutRule struct {
+	Type   string `toml:"type"`
+	Stack  string `toml:"stack"` // Matches if the project's stack contains this entry
+	Layout string `toml:"layout"`
+}
+
+// Matches reports whether the rule applies to a project
+func (r LayoutRule) Matches(p *config.Project) bool {
+	if r.Type != "" && !strings.EqualFold(r.Type, p.ProjectInfo.Type) {
+		return false
+	}
+
+	if r.Stack != "" {
+		found := false
+		for _, tech := range p.Tech.Stack {
+			if strings.EqualFold(tech, r.Stack) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DefaultLayoutFor returns the first layout whose rule matches the project,
+// along with its name. Returns nil if no rule matches.
+func (s *Settings) DefaultLayoutFor(p *config.Project) (*Layout, string) {
+	for _, rule := range s.LayoutRules {
+		if !rule.Matches(p) {
+			continue
+		}
+
+		layout, exists := s.Layouts[rule.Layout]
+		if !exists {
+			continue
+		}
+		return &layout, rule.Layout
+	}
+
+	return nil, ""
+}
+
+// ApplyDefaultLayout fills an empty [tmux] section from the project's kind,
+// or else the matching default layout. Returns the applied layout (or kind)
+// name, or "" if nothing was applied.
+func ApplyDefaultLayout(p *config.Project) string {
+	if p.Tmux.Layout != "" || len(p.Tmux.Windows) > 0 {
+		return ""
+	}
+
+	s, err := Load()
+	if err != nil {
+		return ""
+	}
+
+	if kind, exists := s.KindFor(p); exists {
+		if layout := kind.LayoutIn(s); layout != nil {
+			p.Tmux.Layout = layout.Layout
+			p.Tmux.Windows = layout.Windows
+			return p.ProjectInfo.Kind
+		}
+	}
+
+	layout, name := s.DefaultLayoutFor(p)
+	if layout == nil {
+		return ""
+	}
+
+	p.Tmux.Layout = layout.Layout
+	p.Tmux.Windows = layout.Windows
+	return name
+}
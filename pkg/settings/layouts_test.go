@@ -0,0 +1,87 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func newProject(projectType string, stack ...string) *config.Project {
+	p := &config.Project{}
+	p.ProjectInfo.Type = projectType
+	p.Tech.Stack = stack
+	return p
+}
+
+func TestDefaultLayoutFor(t *testing.T) {
+	s := &Settings{
+		Layouts: map[string]Layout{
+			"data-eng": {Layout: "main-vertical", Windows: []config.TmuxWindow{{Name: "notebook"}}},
+			"basic":    {Windows: []config.TmuxWindow{{Name: "shell"}}},
+		},
+		LayoutRules: []LayoutRule{
+			{Type: "client-project", Stack: "python", Layout: "data-eng"},
+			{Type: "product", Layout: "missing"},
+			{Layout: "basic"},
+		},
+	}
+
+	tests := []struct {
+		project  *config.Project
+		expected string
+	}{
+		{newProject("client-project", "Python", "dbt"), "data-eng"},
+		{newProject("client-project", "go"), "basic"},
+		{newProject("product"), "basic"}, // Rule points at unknown layout, falls through
+		{newProject(""), "basic"},
+	}
+
+	for _, tt := range tests {
+		_, name := s.DefaultLayoutFor(tt.project)
+		if name != tt.expected {
+			t.Errorf("DefaultLayoutFor(type=%q, stack=%v) = %q, want %q",
+				tt.project.ProjectInfo.Type, tt.project.Tech.Stack, name, tt.expected)
+		}
+	}
+}
+
+func TestApplyDefaultLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	configDir := filepath.Join(tmpDir, ".config", "pk")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+
+	content := `[layouts.data-eng]
+layout = "tiled"
+windows = [{name = "editor", command = "nvim"}, {name = "notebook"}]
+
+[[layout_rules]]
+stack = "python"
+layout = "data-eng"
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	p := newProject("product", "python")
+	if name := ApplyDefaultLayout(p); name != "data-eng" {
+		t.Fatalf("Expected data-eng layout to be applied, got %q", name)
+	}
+	if p.Tmux.Layout != "tiled" || len(p.Tmux.Windows) != 2 {
+		t.Errorf("Layout not applied correctly: %+v", p.Tmux)
+	}
+
+	// Projects with their own [tmux] section are left alone
+	custom := newProject("product", "python")
+	custom.Tmux.Windows = []config.TmuxWindow{{Name: "mine"}}
+	if name := ApplyDefaultLayout(custom); name != "" {
+		t.Errorf("Expected no default layout for project with [tmux], got %q", name)
+	}
+}
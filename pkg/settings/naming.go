@@ -0,0 +1,119 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/slug"
+)
+
+// NamingRule is a naming convention for projects matching type, owner
+// and/or client. Empty criteria match everything.
+type NamingRule struct {
+	Type   string `toml:"type"`
+	Owner  string `toml:"owner"`
+	Client string `toml:"client"` // consultant.client_name; "*" for any client
+
+	// Field checked: id (default) | name
+	Field string `toml:"field"`
+
+	// Regular expression the whole field must match. It is a Go template
+	// over the project first, e.g. "{{slug .Consultant.ClientName}}-[a-z-]+"
+	Pattern string `toml:"pattern"`
+
+	// Shown with a violation, e.g. "client projects start with the client"
+	Message string `toml:"message"`
+}
+
+// namingFuncs are available in naming rule patterns
+var namingFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"quote": regexp.QuoteMeta,
+	"slug": func(name string) string {
+		return slug.Make(name, "", slug.Rules{})
+	},
+}
+
+// Matches reports whether the rule applies to a project
+func (r NamingRule) Matches(p *config.Project) bool {
+	if r.Type != "" && !strings.EqualFold(r.Type, p.ProjectInfo.Type) {
+		return false
+	}
+	if r.Owner != "" && !strings.EqualFold(r.Owner, p.GetOwner()) {
+		return false
+	}
+	switch client := p.GetClientName(); {
+	case r.Client == "":
+	case r.Client == "*":
+		return client != ""
+	default:
+		return strings.EqualFold(r.Client, client)
+	}
+	return true
+}
+
+// Expand renders the rule's pattern template for a project
+func (r NamingRule) Expand(p *config.Project) (string, error) {
+	tmpl, err := template.New("pattern").Funcs(namingFuncs).Option("missingkey=error").Parse(r.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("naming pattern %q: %w", r.Pattern, err)
+	}
+	var pattern strings.Builder
+	if err := tmpl.Execute(&pattern, p); err != nil {
+		return "", fmt.Errorf("naming pattern %q: %w", r.Pattern, err)
+	}
+	return pattern.String(), nil
+}
+
+// NamingViolation is a project field that breaks a naming rule
+type NamingViolation struct {
+	Rule    NamingRule
+	Field   string // id | name
+	Value   string
+	Pattern string // As expanded for the project
+}
+
+func (v NamingViolation) Error() string {
+	msg := fmt.Sprintf("project %s '%s' doesn't match %s", v.Field, v.Value, v.Pattern)
+	if v.Rule.Message != "" {
+		msg += " (" + v.Rule.Message + ")"
+	}
+	return msg
+}
+
+// CheckNaming returns the naming rules the project breaks. An error means
+// a rule that applies to it is invalid.
+func (s *Settings) CheckNaming(p *config.Project) ([]NamingViolation, error) {
+	var violations []NamingViolation
+	for _, rule := range s.Naming {
+		if rule.Pattern == "" || !rule.Matches(p) {
+			continue
+		}
+
+		field, value := "id", p.ProjectInfo.ID
+		switch rule.Field {
+		case "", "id":
+		case "name":
+			field, value = "name", p.ProjectInfo.Name
+		default:
+			return nil, fmt.Errorf("naming rule field %q: use id or name", rule.Field)
+		}
+
+		pattern, err := rule.Expand(p)
+		if err != nil {
+			return nil, err
+		}
+		// The whole value must match, not just part of it
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("naming pattern %q: %w", rule.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			violations = append(violations, NamingViolation{Rule: rule, Field: field, Value: value, Pattern: pattern})
+		}
+	}
+	return violations, nil
+}
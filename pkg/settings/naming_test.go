@@ -0,0 +1,64 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func namedProject(id, projectType, client string) *config.Project {
+	p := newProject(projectType)
+	p.ProjectInfo.ID = id
+	p.ProjectInfo.Name = id
+	p.Consultant.ClientName = client
+	return p
+}
+
+func TestCheckNaming(t *testing.T) {
+	s := &Settings{Naming: []NamingRule{
+		{Type: "client-project", Client: "*", Pattern: "{{slug .Consultant.ClientName}}-[a-z-]+", Message: "start with the client"},
+		{Client: "Globex", Field: "name", Pattern: "[A-Z].*"},
+	}}
+
+	tests := []struct {
+		project    *config.Project
+		violations int
+	}{
+		{namedProject("acme-corp-etl", "client-project", "Acme Corp"), 0},
+		{namedProject("etl", "client-project", "Acme Corp"), 1},
+		{namedProject("xacme-corp-etl", "client-project", "Acme Corp"), 1}, // Anchored
+		{namedProject("etl", "client-project", ""), 0},                     // No client, rule doesn't apply
+		{namedProject("etl", "product", "Acme Corp"), 0},
+		{namedProject("globex-etl", "client-project", "Globex"), 1}, // Lowercase name
+	}
+
+	for _, tt := range tests {
+		violations, err := s.CheckNaming(tt.project)
+		if err != nil {
+			t.Fatalf("CheckNaming(%s): %v", tt.project.ProjectInfo.ID, err)
+		}
+		if len(violations) != tt.violations {
+			t.Errorf("%s (%s, %q): %d violation(s) %v, want %d", tt.project.ProjectInfo.ID,
+				tt.project.ProjectInfo.Type, tt.project.Consultant.ClientName, len(violations), violations, tt.violations)
+		}
+	}
+
+	violations, _ := s.CheckNaming(namedProject("etl", "client-project", "Acme Corp"))
+	want := "project id 'etl' doesn't match acme-corp-[a-z-]+ (start with the client)"
+	if got := violations[0].Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckNamingInvalidRule(t *testing.T) {
+	for _, rule := range []NamingRule{
+		{Pattern: "[a-z"},
+		{Pattern: "{{.Missing}}"},
+		{Pattern: "x", Field: "path"},
+	} {
+		s := &Settings{Naming: []NamingRule{rule}}
+		if _, err := s.CheckNaming(namedProject("x", "", "")); err == nil {
+			t.Errorf("Expected an error for %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/slug"
+)
+
+// Settings holds global behavior configured in ~/.config/pk/config.toml.
+// Paths are handled separately by pkg/paths.
+type Settings struct {
+	// Terminal multiplexer for project sessions: tmux (default) | zellij
+	Multiplexer string `toml:"multiplexer"`
+
+	// Named tmux layouts, e.g. [layouts.data-eng]
+	Layouts map[string]Layout `toml:"layouts"`
+
+	// Rules mapping project type/stack to a named layout, first match wins
+	LayoutRules []LayoutRule `toml:"layout_rules"`
+
+	// Project kinds, e.g. [kinds.dbt]; extends the built-ins
+	Kinds map[string]Kind `toml:"kinds"`
+
+	// Editor integration for 'pk code' and 'pk nvim'
+	Editor struct {
+		VSCodeProfiles map[string]string `toml:"vscode_profiles"` // Client name -> VS Code profile
+
+		// Isolated VS Code setup for client-confidential projects
+		ClientProfile ClientProfile            `toml:"client_profile"` // Applied to every client
+		Clients       map[string]ClientProfile `toml:"clients"`        // Per-client additions
+	} `toml:"editor"`
+
+	// How 'pk new' derives project IDs from names
+	IDs slug.Rules `toml:"ids"`
+
+	// Naming conventions enforced by new, rename, promote and 'pk lint',
+	// e.g. [[naming]] type = "client-project", pattern = "acme-[a-z-]+"
+	Naming []NamingRule `toml:"naming"`
+
+	// Session lifecycle commands for every project, run before the
+	// project's own [tmux] on_create/on_attach/on_detach
+	Hooks SessionHooks `toml:"hooks"`
+
+	// Keeping pk's files out of project repositories on create
+	Metadata struct {
+		// datakai.visibility -> export-ignore | exclude | sidecar | none; "default" for the rest
+		Visibility map[string]string `toml:"visibility"`
+	} `toml:"metadata"`
+
+	// Interactive pickers ('pk session', 'pk sessions', ...)
+	Picker struct {
+		Backend string   `toml:"backend"` // fzf | sk | builtin (default: fzf if installed, else built-in)
+		Args    []string `toml:"args"`    // Extra fzf/sk flags, after pk's own
+		Preview string   `toml:"preview"` // Preview command for project pickers, {1} is the ID; "none" hides it
+		Bind    []string `toml:"bind"`    // Key bindings as for fzf --bind, e.g. "ctrl-o:execute(code {1})"
+		Query   string   `toml:"query"`   // Initial query
+	} `toml:"picker"`
+
+	// Saved --format templates for list/show, e.g. [formats] csv = "..."
+	Formats map[string]string `toml:"formats"`
+
+	// Shell integration written into the generated alias file
+	Shell struct {
+		CDHook *bool `toml:"cd_hook"` // Record access on cd into a project (default true)
+	} `toml:"shell"`
+
+	// Project discovery
+	Scan struct {
+		Parallelism int      `toml:"parallelism"` // Concurrent directory reads and parses (0: automatic)
+		Ignore      []string `toml:"ignore"`      // Extra directory globs to skip
+		Nested      bool     `toml:"nested"`      // Search inside every project, not just nested = true ones
+		MaxDepth    int      `toml:"max_depth"`   // Directory levels below a root to search (0: unlimited)
+	} `toml:"scan"`
+
+	// Guardrails for switching global CLI state (see pkg/context)
+	Context struct {
+		SkipVisibility  []string `toml:"skip_visibility"`  // datakai.visibility values that never switch, e.g. ["public"]
+		ConfirmProfiles []string `toml:"confirm_profiles"` // Globs; ask before switching to a matching value
+	} `toml:"context"`
+
+	// Where the project cache lives (see pkg/cache)
+	Cache struct {
+		Backend string `toml:"backend"` // json (default) | sqlite (~/.cache/pk/pk.db)
+	} `toml:"cache"`
+
+	// Where pins and access history live (see pkg/store)
+	State struct {
+		Backend  string `toml:"backend"`   // json (default) | sqlite | http
+		Path     string `toml:"path"`      // SQLite database file
+		URL      string `toml:"url"`       // Base URL for the http backend
+		TokenEnv string `toml:"token_env"` // Env var holding a bearer token for http
+	} `toml:"state"`
+
+	// Read-only reference checkouts ([project] mirror = true)
+	Mirror struct {
+		AliasPrefix   string `toml:"alias_prefix"`   // Prepended to their aliases (default "ref-")
+		FetchInterval string `toml:"fetch_interval"` // How often the daemon fetches them, e.g. "6h" (default); "off" never
+	} `toml:"mirror"`
+
+	// Registry of project metadata shared with teammates (see pkg/registry)
+	Team struct {
+		URL      string `toml:"url"`       // Server holding the registry, same protocol as the http state backend
+		TokenEnv string `toml:"token_env"` // Env var holding a bearer token
+		User     string `toml:"user"`      // Your name in teammates' digests (default $USER)
+	} `toml:"team"`
+}
+
+// CDHookEnabled reports whether the shell cd hook should be installed
+func (s *Settings) CDHookEnabled() bool {
+	return s.Shell.CDHook == nil || *s.Shell.CDHook
+}
+
+// DefaultMirrorFetchInterval is how often the daemon fetches mirrors
+// unless [mirror] fetch_interval says otherwise
+const DefaultMirrorFetchInterval = 6 * time.Hour
+
+// MirrorAliasPrefix returns the prefix of mirror projects' aliases
+func (s *Settings) MirrorAliasPrefix() string {
+	if s.Mirror.AliasPrefix == "" {
+		return "ref-"
+	}
+	return s.Mirror.AliasPrefix
+}
+
+// MirrorFetchInterval returns how often the daemon fetches mirrors, or 0
+// if it shouldn't
+func (s *Settings) MirrorFetchInterval() (time.Duration, error) {
+	switch s.Mirror.FetchInterval {
+	case "":
+		return DefaultMirrorFetchInterval, nil
+	case "off", "0":
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s.Mirror.FetchInterval)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid [mirror] fetch_interval %q (use a duration like \"6h\", or \"off\")", s.Mirror.FetchInterval)
+	}
+	return d, nil
+}
+
+// MetadataMode returns how to keep pk's files out of the repository of a
+// project with the given datakai.visibility ("none" unless configured)
+func (s *Settings) MetadataMode(visibility string) string {
+	if mode, ok := s.Metadata.Visibility[visibility]; ok && visibility != "" {
+		return mode
+	}
+	if mode, ok := s.Metadata.Visibility["default"]; ok {
+		return mode
+	}
+	return "none"
+}
+
+// SessionHooks are shell commands run at points in a session's life
+type SessionHooks struct {
+	OnCreate string `toml:"on_create"` // After the session is created
+	OnAttach string `toml:"on_attach"` // Each time pk opens the session
+	OnDetach string `toml:"on_detach"` // When a client detaches from the session
+}
+
+// ClientProfile lists extensions and settings for an isolated client editor
+type ClientProfile struct {
+	Extensions []string               `toml:"extensions"`
+	Settings   map[string]interface{} `toml:"settings"`
+}
+
+// ConfigFile returns the path to the global config file
+func ConfigFile() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".config", "pk", "config.toml"), nil
+}
+
+// Load reads global settings. A missing config file yields empty settings.
+func Load() (*Settings, error) {
+	var s Settings
+
+	configPath, err := ConfigFile()
+	if err != nil {
+		return &s, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return &s, nil
+	}
+
+	if _, err := toml.DecodeFile(configPath, &s); err != nil {
+		return &s, err
+	}
+
+	return &s, nil
+}
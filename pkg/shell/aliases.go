@@ -5,9 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
 )
 
 // GenerateAliases creates shell alias file for all projects
@@ -28,37 +30,41 @@ func GenerateAliases(shell Shell, projects []*config.Project) error {
 	}
 	defer f.Close()
 
+	s, _ := settings.Load()
+
 	// Write header
 	writeHeader(f, shell)
 
 	// Separate projects by category
 	datakai := []*config.Project{}
 	active := []*config.Project{}
+	mirrors := []*config.Project{}
 	archived := []*config.Project{}
 
 	for _, p := range projects {
-		// DataKai ecosystem (special handling)
-		if p.ProjectInfo.ID == "conduit" || p.ProjectInfo.ID == "dk" || p.ProjectInfo.ID == "dkos" {
+		switch AliasSection(p) {
+		case SectionDataKai:
 			datakai = append(datakai, p)
-			continue
-		}
-
-		// Categorize others
-		if p.ProjectInfo.Status == "archived" {
+		case SectionMirrors:
+			mirrors = append(mirrors, p)
+		case SectionArchived:
 			archived = append(archived, p)
-		} else {
+		default:
 			active = append(active, p)
 		}
 	}
 
 	// Write DataKai ecosystem
-	writeSection(f, shell, "DataKai Ecosystem", datakai)
+	writeSection(f, shell, SectionDataKai, datakai, "")
 
 	// Special DataKai aliases
 	writeDataKaiSpecial(f, shell)
 
 	// Write active projects
-	writeSection(f, shell, "Active Projects", active)
+	writeSection(f, shell, SectionActive, active, "")
+
+	// Write mirrors under their own prefix
+	writeSection(f, shell, SectionMirrors, mirrors, s.MirrorAliasPrefix())
 
 	// Write archived projects
 	writeArchivedSection(f, shell, archived)
@@ -66,6 +72,11 @@ func GenerateAliases(shell Shell, projects []*config.Project) error {
 	// Write special aliases
 	writeSpecialAliases(f, shell)
 
+	// Write cd hook for access tracking
+	if s.CDHookEnabled() {
+		writeCDHook(f, shell)
+	}
+
 	// Move temp to final location
 	if err := os.Rename(tempFile, aliasFile); err != nil {
 		return fmt.Errorf("failed to move alias file: %w", err)
@@ -74,6 +85,71 @@ func GenerateAliases(shell Shell, projects []*config.Project) error {
 	return nil
 }
 
+// Alias file sections
+const (
+	SectionDataKai  = "DataKai Ecosystem"
+	SectionActive   = "Active Projects"
+	SectionMirrors  = "Mirrors"
+	SectionArchived = "Archived Projects"
+)
+
+// AliasSection returns the section of the alias file a project is written to
+func AliasSection(p *config.Project) string {
+	// DataKai ecosystem (special handling)
+	switch p.ProjectInfo.ID {
+	case "conduit", "dk", "dkos":
+		return SectionDataKai
+	}
+	if p.ProjectInfo.Status == "archived" {
+		return SectionArchived
+	}
+	if p.ProjectInfo.Mirror {
+		return SectionMirrors
+	}
+	return SectionActive
+}
+
+// AliasName returns a project's alias: its ID, with the [mirror]
+// alias_prefix for mirrors
+func AliasName(p *config.Project, s *settings.Settings) string {
+	if AliasSection(p) == SectionMirrors {
+		return s.MirrorAliasPrefix() + p.ProjectInfo.ID
+	}
+	return p.ProjectInfo.ID
+}
+
+// AliasSkipReason explains why a project gets no alias, or "" if it gets one
+func AliasSkipReason(p *config.Project) string {
+	// Skip 'pk' to avoid conflict with pk command
+	if p.ProjectInfo.ID == "pk" {
+		return "id 'pk' would shadow the pk command"
+	}
+	return ""
+}
+
+// HasAlias reports whether the generated alias file for shell defines name
+func HasAlias(shell Shell, name string) bool {
+	data, err := os.ReadFile(ConfigPath(shell))
+	if err != nil {
+		return false
+	}
+
+	var prefix string
+	switch shell {
+	case Fish:
+		prefix = "abbr -a " + name + " "
+	default:
+		prefix = "alias " + name + "="
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func writeHeader(f *os.File, shell Shell) {
 	switch shell {
 	case Zsh, Bash:
@@ -90,7 +166,7 @@ func writeHeader(f *os.File, shell Shell) {
 	}
 }
 
-func writeSection(f *os.File, shell Shell, title string, projects []*config.Project) {
+func writeSection(f *os.File, shell Shell, title string, projects []*config.Project, prefix string) {
 	if len(projects) == 0 {
 		return
 	}
@@ -108,11 +184,10 @@ func writeSection(f *os.File, shell Shell, title string, projects []*config.Proj
 	}
 
 	for _, p := range projects {
-		// Skip 'pk' to avoid conflict with pk command
-		if p.ProjectInfo.ID == "pk" {
+		if AliasSkipReason(p) != "" {
 			continue
 		}
-		writeAlias(f, shell, p.ProjectInfo.ID, p.Path, "")
+		writeAlias(f, shell, prefix+p.ProjectInfo.ID, p.Path, "")
 	}
 
 	fmt.Fprintf(f, "\n")
@@ -135,8 +210,7 @@ func writeArchivedSection(f *os.File, shell Shell, projects []*config.Project) {
 	}
 
 	for _, p := range projects {
-		// Skip 'pk' to avoid conflict with pk command
-		if p.ProjectInfo.ID == "pk" {
+		if AliasSkipReason(p) != "" {
 			continue
 		}
 		comment := fmt.Sprintf("archived %s", p.Dates.Completed)
@@ -186,6 +260,32 @@ func writeSpecialAliases(f *os.File, shell Shell) {
 	}
 }
 
+// writeCDHook records project access when the user cd's into a project.
+// Runs in the background so it never slows down the prompt.
+func writeCDHook(f *os.File, shell Shell) {
+	switch shell {
+	case Zsh:
+		fmt.Fprintf(f, "\n# ---------- Access Tracking ----------\n")
+		fmt.Fprintf(f, "autoload -Uz add-zsh-hook\n")
+		fmt.Fprintf(f, "_pk_chpwd() { command pk __hook chpwd \"$PWD\" >/dev/null 2>&1 &! }\n")
+		fmt.Fprintf(f, "add-zsh-hook chpwd _pk_chpwd\n")
+	case Bash:
+		fmt.Fprintf(f, "\n# ---------- Access Tracking ----------\n")
+		fmt.Fprintf(f, "_pk_chpwd() {\n")
+		fmt.Fprintf(f, "    [ \"$PWD\" = \"$_PK_LAST_PWD\" ] && return\n")
+		fmt.Fprintf(f, "    _PK_LAST_PWD=\"$PWD\"\n")
+		fmt.Fprintf(f, "    (command pk __hook chpwd \"$PWD\" >/dev/null 2>&1 &)\n")
+		fmt.Fprintf(f, "}\n")
+		fmt.Fprintf(f, "case \"$PROMPT_COMMAND\" in *_pk_chpwd*) ;; *) PROMPT_COMMAND=\"_pk_chpwd${PROMPT_COMMAND:+;$PROMPT_COMMAND}\" ;; esac\n")
+	case Fish:
+		fmt.Fprintf(f, "\n# Access Tracking\n")
+		fmt.Fprintf(f, "function __pk_chpwd --on-variable PWD\n")
+		fmt.Fprintf(f, "    command pk __hook chpwd $PWD >/dev/null 2>&1 &\n")
+		fmt.Fprintf(f, "    disown\n")
+		fmt.Fprintf(f, "end\n")
+	}
+}
+
 func writeAlias(f *os.File, shell Shell, name, path, comment string) {
 	switch shell {
 	case Zsh, Bash:
@@ -0,0 +1,117 @@
+package shell
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+// lastUpdated matches the timestamp written into every alias file header
+var lastUpdated = regexp.MustCompile(`(?m)^# Last updated: .*$`)
+
+func aliasProject(id, status, path, completed string) *config.Project {
+	p := &config.Project{Path: path}
+	p.ProjectInfo.ID = id
+	p.ProjectInfo.Status = status
+	p.Dates.Completed = completed
+	return p
+}
+
+func TestGenerateAliasesGolden(t *testing.T) {
+	for _, sh := range []Shell{Zsh, Bash, Fish} {
+		t.Run(sh.String(), func(t *testing.T) {
+			home := t.TempDir()
+			originalHome := os.Getenv("HOME")
+			os.Setenv("HOME", home)
+			defer os.Setenv("HOME", originalHome)
+
+			// dojo lives inside the dk monorepo and gets its own aliases
+			os.MkdirAll(filepath.Join(home, "projects", "dk", "apps", "dojo"), 0755)
+
+			sdk := aliasProject("sdk", "active", filepath.Join(home, "projects", "sdk"), "")
+			sdk.ProjectInfo.Mirror = true
+
+			projects := []*config.Project{
+				sdk,
+				aliasProject("zeta", "active", filepath.Join(home, "projects", "zeta"), ""),
+				aliasProject("dk", "active", filepath.Join(home, "projects", "dk"), ""),
+				aliasProject("pk", "active", filepath.Join(home, "projects", "pk"), ""),
+				aliasProject("oldapp", "archived", filepath.Join(home, "archive", "oldapp"), "2024-06-30"),
+				aliasProject("alpha", "paused", filepath.Join(home, "projects", "alpha"), ""),
+			}
+
+			if err := GenerateAliases(sh, projects); err != nil {
+				t.Fatalf("GenerateAliases: %v", err)
+			}
+			data, err := os.ReadFile(ConfigPath(sh))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := strings.ReplaceAll(string(data), home, "$HOME")
+			got = lastUpdated.ReplaceAllString(got, "# Last updated: <time>")
+			checkGolden(t, filepath.Join("testdata", "aliases."+sh.String()+".golden"), got)
+		})
+	}
+}
+
+func TestAliasSkipReason(t *testing.T) {
+	if reason := AliasSkipReason(aliasProject("pk", "active", "/p/pk", "")); reason == "" {
+		t.Error("project 'pk' must not get an alias")
+	}
+	if reason := AliasSkipReason(aliasProject("dojo", "active", "/p/dojo", "")); reason != "" {
+		t.Errorf("unexpected skip reason %q", reason)
+	}
+}
+
+func TestAliasName(t *testing.T) {
+	mirror := aliasProject("sdk", "active", "/p/sdk", "")
+	mirror.ProjectInfo.Mirror = true
+	archived := aliasProject("old-sdk", "archived", "/a/old-sdk", "")
+	archived.ProjectInfo.Mirror = true
+
+	var s settings.Settings
+	if name := AliasName(mirror, &s); name != "ref-sdk" {
+		t.Errorf("mirror alias = %q, want ref-sdk", name)
+	}
+	if name := AliasName(archived, &s); name != "old-sdk" {
+		t.Errorf("archived mirror alias = %q, want old-sdk", name)
+	}
+	s.Mirror.AliasPrefix = "vendor-"
+	if name := AliasName(mirror, &s); name != "vendor-sdk" {
+		t.Errorf("mirror alias = %q, want vendor-sdk", name)
+	}
+	if name := AliasName(aliasProject("dojo", "active", "/p/dojo", ""), &s); name != "dojo" {
+		t.Errorf("alias = %q, want dojo", name)
+	}
+}
+
+// checkGolden compares got with a golden file, rewriting it under -update
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+
+	if *update {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run 'go test ./pkg/shell -update' to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch (run with -update if the change is intended)\n--- got ---\n%s\n--- want ---\n%s",
+			path, got, want)
+	}
+}
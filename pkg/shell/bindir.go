@@ -0,0 +1,89 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+// BinRoot returns ~/.local/share/pk/bin, which holds a directory of command
+// wrappers per project
+func BinRoot() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "pk", "bin"), nil
+}
+
+// BinDir returns the wrapper directory for a project
+func BinDir(projectID string) (string, error) {
+	root, err := BinRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, projectID), nil
+}
+
+// WriteBinDir replaces a project's wrapper directory with one executable
+// script per named command, running it from the project root with the
+// script's arguments appended and metadata references filled in. Names
+// that aren't plain file names are skipped. It returns the directory and the wrappers written.
+func WriteBinDir(p *config.Project, commands map[string]string) (string, []string, error) {
+	dir, err := BinDir(p.ProjectInfo.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Build the new directory beside the old one and swap them, so a shell
+	// with it on PATH never sees it half written
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+p.ProjectInfo.ID+"-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return "", nil, err
+	}
+
+	var names []string
+	for name := range commands {
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		command, err := p.Expand(commands[name])
+		if err != nil {
+			return "", nil, fmt.Errorf("command %s: %w", name, err)
+		}
+		script := fmt.Sprintf("#!/bin/sh\n# %s command '%s', generated by 'pk alias export --direnv-style'\ncd %s || exit 1\nexec sh -c %s %s \"$@\"\n",
+			p.ProjectInfo.ID, name, quote(p.Path), quote(command+` "$@"`), quote(name))
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(script), 0755); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return "", nil, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", nil, err
+	}
+	return dir, names, nil
+}
+
+// quote quotes s for sh
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,59 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteBinDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, "projects", "it's-etl")
+	os.MkdirAll(projectDir, 0755)
+	p := aliasProject("etl", "active", projectDir, "")
+
+	dir, names, err := WriteBinDir(p, map[string]string{
+		"where":   "pwd; echo",
+		"old":     "true",
+		"../evil": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".local", "share", "pk", "bin", "etl"); dir != want {
+		t.Errorf("dir = %s, want %s", dir, want)
+	}
+	if !reflect.DeepEqual(names, []string{"old", "where"}) {
+		t.Errorf("names = %v, want the plain ones", names)
+	}
+
+	// Wrappers run from the project root with their arguments
+	out, err := exec.Command(filepath.Join(dir, "where"), "a b", "c").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(out), projectDir+"\na b c\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	// Rewriting drops commands the project no longer has
+	if _, _, err := WriteBinDir(p, map[string]string{"where": "pwd"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	if len(entries) != 1 || entries[0].Name() != "etl" {
+		t.Errorf("bin root should only hold etl, got %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Error("old should be removed")
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "where"))
+	if !strings.HasPrefix(string(data), "#!/bin/sh\n") {
+		t.Errorf("wrapper = %q", data)
+	}
+}
@@ -0,0 +1,112 @@
+// Package slug derives project IDs from human-readable names, following
+// the [ids] rules in config.toml
+package slug
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rules configure how IDs are derived ([ids] in config.toml)
+type Rules struct {
+	Separator    string            `toml:"separator"`     // Between words: - (default) | _
+	MaxLength    int               `toml:"max_length"`    // Longest ID, cut at a word boundary (0: unlimited)
+	ClientPrefix bool              `toml:"client_prefix"` // Start IDs with the client's prefix
+	Clients      map[string]string `toml:"clients"`       // Client name -> prefix; default is the name's first word
+}
+
+// separator returns the configured separator, "-" by default
+func (r Rules) separator() string {
+	if r.Separator == "" {
+		return "-"
+	}
+	return r.Separator
+}
+
+// accents folds common accented letters to ASCII
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c", "ß", "ss",
+)
+
+// Words splits a name into lowercase ASCII words, folding accents and
+// treating everything but letters and digits as a break
+func Words(name string) []string {
+	name = accents.Replace(strings.ToLower(name))
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+}
+
+// Make derives an ID from a name: lowercase words joined by the separator,
+// prefixed with the client's prefix if the rules ask for it, and cut to
+// MaxLength. Returns "" if the name has no letters or digits.
+func Make(name, client string, rules Rules) string {
+	sep := rules.separator()
+	words := Words(name)
+
+	if prefix := rules.prefix(client); prefix != "" && len(words) > 0 {
+		if !strings.HasPrefix(strings.Join(words, sep)+sep, prefix+sep) {
+			words = append(Words(prefix), words...)
+		}
+	}
+	return cut(strings.Join(words, sep), rules.MaxLength, sep)
+}
+
+// prefix returns the ID prefix for a client, or "" when not prefixing
+func (r Rules) prefix(client string) string {
+	if !r.ClientPrefix || client == "" {
+		return ""
+	}
+	for name, prefix := range r.Clients {
+		if strings.EqualFold(name, client) {
+			return strings.Join(Words(prefix), r.separator())
+		}
+	}
+	if words := Words(client); len(words) > 0 {
+		return words[0]
+	}
+	return ""
+}
+
+// Unique returns id, or id with the lowest -2, -3, ... suffix that isn't
+// taken, keeping within MaxLength
+func Unique(id string, rules Rules, taken func(string) bool) string {
+	if !taken(id) {
+		return id
+	}
+	sep := rules.separator()
+	for n := 2; ; n++ {
+		suffix := sep + strconv.Itoa(n)
+		base := id
+		if rules.MaxLength > 0 {
+			base = cut(id, rules.MaxLength-len(suffix), sep)
+		}
+		if candidate := base + suffix; !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// Valid reports whether id is already in the form Make produces: lowercase
+// letters and digits, single separators between them
+func Valid(id string, rules Rules) bool {
+	return id != "" && strings.Join(Words(id), rules.separator()) == id
+}
+
+// cut shortens id to max bytes, backing up to the last whole word if that
+// leaves one
+func cut(id string, max int, sep string) string {
+	if max <= 0 || len(id) <= max {
+		return id
+	}
+	id = id[:max]
+	if i := strings.LastIndex(id, sep); i > 0 {
+		id = id[:i]
+	}
+	return strings.TrimSuffix(id, sep)
+}
@@ -0,0 +1,63 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name   string
+		client string
+		rules  Rules
+		want   string
+	}{
+		{"Acme Data Platform POC", "", Rules{}, "acme-data-platform-poc"},
+		{"  Q3 -- Report (v2)! ", "", Rules{}, "q3-report-v2"},
+		{"Migración Año Fiscal", "", Rules{}, "migracion-ano-fiscal"},
+		{"Data Platform", "", Rules{Separator: "_"}, "data_platform"},
+		{"Data Platform POC", "Acme Corp", Rules{ClientPrefix: true}, "acme-data-platform-poc"},
+		{"Acme Data Platform", "Acme Corp", Rules{ClientPrefix: true}, "acme-data-platform"},
+		{"Data Platform", "Acme Corp", Rules{}, "data-platform"},
+		{"Data Platform", "ACME corp", Rules{ClientPrefix: true, Clients: map[string]string{"Acme Corp": "AC"}}, "ac-data-platform"},
+		{"Acme Data Platform POC", "", Rules{MaxLength: 20}, "acme-data-platform"},
+		{"Supercalifragilistic", "", Rules{MaxLength: 10}, "supercalif"},
+		{"???", "", Rules{}, ""},
+		{"???", "Acme", Rules{ClientPrefix: true}, ""},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.name, tt.client, tt.rules); got != tt.want {
+			t.Errorf("Make(%q, %q) = %q, want %q", tt.name, tt.client, got, tt.want)
+		}
+	}
+}
+
+func TestUnique(t *testing.T) {
+	taken := map[string]bool{"api": true, "api-2": true, "data-platform": true}
+	isTaken := func(id string) bool { return taken[id] }
+
+	if got := Unique("web", Rules{}, isTaken); got != "web" {
+		t.Errorf("free ID should be kept, got %q", got)
+	}
+	if got := Unique("api", Rules{}, isTaken); got != "api-3" {
+		t.Errorf("Unique(api) = %q, want api-3", got)
+	}
+	if got := Unique("data-platform", Rules{MaxLength: 14}, isTaken); got != "data-2" {
+		t.Errorf("suffix should fit max_length, got %q", got)
+	}
+}
+
+func TestValid(t *testing.T) {
+	for id, want := range map[string]bool{
+		"acme-poc":  true,
+		"acme_poc":  false,
+		"Acme-POC":  false,
+		"acme--poc": false,
+		"-acme":     false,
+		"":          false,
+	} {
+		if got := Valid(id, Rules{}); got != want {
+			t.Errorf("Valid(%q) = %v, want %v", id, got, want)
+		}
+	}
+	if !Valid("acme_poc", Rules{Separator: "_"}) {
+		t.Error("acme_poc should be valid with separator _")
+	}
+}
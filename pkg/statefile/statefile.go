@@ -0,0 +1,248 @@
+// Package statefile reads and writes pk's cache and state files (projects,
+// access, pins, ...) so that a locked-down or network-mounted home directory
+// slows pk down instead of breaking it.
+//
+// Transient errors (NFS hiccups) are retried with backoff. If a file can't be
+// written at all (read-only HOME, unwritable ~/.cache), its contents are kept
+// in memory for the rest of the run and a single warning is printed.
+//
+// Writes replace files atomically, so a reader never sees half a file.
+// Read-modify-write cycles shared between processes take a Lock.
+package statefile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryDelays is the backoff between attempts for transient errors
+var RetryDelays = []time.Duration{50 * time.Millisecond, 200 * time.Millisecond, 800 * time.Millisecond}
+
+// LockTimeout bounds how long Lock waits for another process to let go
+var LockTimeout = 5 * time.Second
+
+// Overridable in tests
+var (
+	writeFile = writeAtomic
+	mkdirAll  = os.MkdirAll
+)
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+var (
+	mu     sync.Mutex
+	memory = make(map[string]memFile)
+	warned sync.Once
+)
+
+// Dir returns ~/.cache/pk, creating it if possible. A directory that can't
+// be created is not an error: writes into it fall back to memory.
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(homeDir, ".cache", "pk")
+	if err := retry(func() error { return mkdirAll(dir, 0755) }); err != nil && !unwritable(err) {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns the path of a named file in the pk cache directory
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// ReadFile reads a state file, preferring the in-memory copy when writes
+// to it have been failing. A file in an unreadable location reads as
+// missing, so callers start from empty state.
+func ReadFile(path string) ([]byte, error) {
+	mu.Lock()
+	f, ok := memory[path]
+	mu.Unlock()
+	if ok {
+		return append([]byte(nil), f.data...), nil
+	}
+
+	var data []byte
+	err := retry(func() error {
+		var err error
+		data, err = os.ReadFile(path)
+		return err
+	})
+	if err != nil && unwritable(err) {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return data, err
+}
+
+// WriteFile writes a state file. Unwritable locations degrade to memory-only
+// with a one-time warning rather than returning an error.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	err := retry(func() error { return writeFile(path, data, perm) })
+	if err == nil {
+		mu.Lock()
+		delete(memory, path)
+		mu.Unlock()
+		return nil
+	}
+
+	// A missing parent (e.g. Dir couldn't create it) is the same problem
+	if !unwritable(err) && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	warned.Do(func() {
+		fmt.Fprintf(os.Stderr, "Warning: %s is not writable (%v); pk state will not persist this run\n",
+			filepath.Dir(path), err)
+	})
+
+	mu.Lock()
+	memory[path] = memFile{data: append([]byte(nil), data...), modTime: time.Now()}
+	mu.Unlock()
+	return nil
+}
+
+// writeAtomic writes data to a temporary file beside path and renames it
+// into place
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Gone after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Lock takes an exclusive advisory lock called name in the cache directory
+// and returns the function that releases it. Hold it across a read and the
+// write that follows, so concurrent pk processes (two tmux popups opening
+// projects at once) don't lose each other's updates.
+//
+// A lock that can't be taken doesn't stop the caller: in an unwritable
+// directory, or after LockTimeout, it proceeds unlocked.
+func Lock(name string) (unlock func()) {
+	path, err := Path(name + ".lock")
+	if err != nil {
+		return func() {}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return func() {}
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		busy, err := tryLock(f)
+		if err == nil {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}
+		}
+		if !busy {
+			f.Close()
+			return func() {}
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintf(os.Stderr, "Warning: %s is still held after %s; continuing without it\n", path, LockTimeout)
+			f.Close()
+			return func() {}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Remove deletes a state file and any in-memory copy. Missing files are not an error.
+func Remove(path string) error {
+	mu.Lock()
+	delete(memory, path)
+	mu.Unlock()
+
+	err := retry(func() error { return os.Remove(path) })
+	if err != nil && !errors.Is(err, fs.ErrNotExist) && !unwritable(err) {
+		return err
+	}
+	return nil
+}
+
+// ModTime returns when a state file was last written
+func ModTime(path string) (time.Time, error) {
+	mu.Lock()
+	f, ok := memory[path]
+	mu.Unlock()
+	if ok {
+		return f.modTime, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// MemoryOnly reports whether any state file has fallen back to memory
+func MemoryOnly() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(memory) > 0
+}
+
+// retry runs fn, retrying transient errors with backoff
+func retry(fn func() error) error {
+	err := fn()
+	for _, delay := range RetryDelays {
+		if err == nil || !transient(err) {
+			return err
+		}
+		time.Sleep(delay)
+		err = fn()
+	}
+	return err
+}
+
+// transient reports errors worth retrying, typical of network filesystems
+func transient(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.EBUSY) ||
+		errors.Is(err, syscall.EINTR) ||
+		errors.Is(err, syscall.ESTALE) ||
+		errors.Is(err, syscall.ETIMEDOUT)
+}
+
+// unwritable reports errors that retrying won't fix
+func unwritable(err error) bool {
+	return errors.Is(err, syscall.EROFS) ||
+		errors.Is(err, fs.ErrPermission) ||
+		errors.Is(err, syscall.ENOTDIR) ||
+		errors.Is(err, syscall.EDQUOT) ||
+		errors.Is(err, syscall.ENOSPC)
+}
@@ -0,0 +1,156 @@
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func stubWrite(t *testing.T, fn func(string, []byte, os.FileMode) error) {
+	t.Helper()
+	original := writeFile
+	writeFile = fn
+	t.Cleanup(func() {
+		writeFile = original
+		mu.Lock()
+		memory = make(map[string]memFile)
+		mu.Unlock()
+	})
+}
+
+func TestWriteFileReadOnlyFallsBackToMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.json")
+	stubWrite(t, func(string, []byte, os.FileMode) error {
+		return &os.PathError{Op: "open", Path: path, Err: syscall.EROFS}
+	})
+
+	if err := WriteFile(path, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatalf("Expected read-only write to degrade, got %v", err)
+	}
+	if !MemoryOnly() {
+		t.Error("Expected MemoryOnly after a failed write")
+	}
+
+	data, err := ReadFile(path)
+	if err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Expected in-memory contents, got %q (%v)", data, err)
+	}
+	if _, err := ModTime(path); err != nil {
+		t.Errorf("Expected ModTime for in-memory file, got %v", err)
+	}
+
+	if err := Remove(path); err != nil {
+		t.Errorf("Remove failed: %v", err)
+	}
+	if _, err := ReadFile(path); !os.IsNotExist(err) {
+		t.Errorf("Expected file to be gone after Remove, got %v", err)
+	}
+}
+
+func TestWriteFileRetriesTransientErrors(t *testing.T) {
+	originalDelays := RetryDelays
+	RetryDelays = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	defer func() { RetryDelays = originalDelays }()
+
+	attempts := 0
+	stubWrite(t, func(name string, data []byte, perm os.FileMode) error {
+		attempts++
+		if attempts < 3 {
+			return &os.PathError{Op: "write", Path: name, Err: syscall.ESTALE}
+		}
+		return os.WriteFile(name, data, perm)
+	})
+
+	path := filepath.Join(t.TempDir(), "pins.json")
+	if err := WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if MemoryOnly() {
+		t.Error("Expected successful write to land on disk")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected file on disk: %v", err)
+	}
+}
+
+func TestWriteFileOtherErrorsReturned(t *testing.T) {
+	stubWrite(t, func(name string, data []byte, perm os.FileMode) error {
+		return &os.PathError{Op: "write", Path: name, Err: syscall.EISDIR}
+	})
+
+	if err := WriteFile(filepath.Join(t.TempDir(), "x.json"), nil, 0644); err == nil {
+		t.Error("Expected non-permission errors to be returned")
+	}
+}
+
+func TestWriteFileReplacesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "projects.json")
+	for _, data := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("Expected second write, got %q", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only projects.json, temporary files left: %v", entries)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644, got %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestLockExcludes(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	unlock := Lock("access")
+
+	acquired := make(chan struct{})
+	go func() {
+		Lock("access")()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected second Lock to wait for the first")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected second Lock once the first was released")
+	}
+}
+
+func TestLockTimesOut(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	originalTimeout := LockTimeout
+	LockTimeout = 50 * time.Millisecond
+	defer func() { LockTimeout = originalTimeout }()
+
+	unlock := Lock("pins")
+	defer unlock()
+
+	start := time.Now()
+	Lock("pins")()
+	if time.Since(start) > time.Second {
+		t.Error("Expected Lock to give up after LockTimeout")
+	}
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPTimeout bounds each request to a remote state server
+const HTTPTimeout = 5 * time.Second
+
+// HTTPStore keeps documents on a remote server for team-shared or
+// multi-machine state. The protocol is plain REST on {url}/{name}:
+// GET returns the document (404 if missing) and PUT replaces it.
+type HTTPStore struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewHTTPStore creates a store for baseURL, sending token as a bearer
+// token when set
+func NewHTTPStore(baseURL, token string) (*HTTPStore, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("[state] url is required for the http backend")
+	}
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid [state] url: %w", err)
+	}
+
+	return &HTTPStore{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: HTTPTimeout},
+	}, nil
+}
+
+// Name identifies the backend
+func (s *HTTPStore) Name() string {
+	return fmt.Sprintf("%s (%s)", BackendHTTP, s.baseURL)
+}
+
+// Get fetches a document
+func (s *HTTPStore) Get(name string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", name, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Put uploads a document
+func (s *HTTPStore) Put(name string, data []byte) error {
+	resp, err := s.do(http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: %s", name, resp.Status)
+	}
+	return nil
+}
+
+func (s *HTTPStore) do(method, name string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.baseURL+"/"+url.PathEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return s.client.Do(req)
+}
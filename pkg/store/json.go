@@ -0,0 +1,44 @@
+package store
+
+import (
+	"os"
+
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// JSONStore keeps each document in ~/.cache/pk/<name>.json (the original
+// pins.json / access.json layout)
+type JSONStore struct{}
+
+// NewJSONStore creates the local JSON file store
+func NewJSONStore() *JSONStore {
+	return &JSONStore{}
+}
+
+// Name identifies the backend
+func (s *JSONStore) Name() string {
+	return BackendJSON
+}
+
+// Get reads a document file
+func (s *JSONStore) Get(name string) ([]byte, error) {
+	path, err := statefile.Path(name + ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := statefile.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put writes a document file
+func (s *JSONStore) Put(name string, data []byte) error {
+	path, err := statefile.Path(name + ".json")
+	if err != nil {
+		return err
+	}
+	return statefile.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps documents in one table of a SQLite database, which
+// is safe to share between concurrent pk processes and syncs as one file
+type SQLiteStore struct {
+	path string
+	db   *sql.DB
+}
+
+// DefaultSQLitePath is ~/.local/share/pk/state.db
+func DefaultSQLitePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "pk", "state.db"), nil
+}
+
+// NewSQLiteStore opens (and creates if needed) the database at path.
+// An empty path uses DefaultSQLitePath; ~ is expanded.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultSQLitePath(); err != nil {
+			return nil, err
+		}
+	} else if rest, ok := strings.CutPrefix(path, "~"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(homeDir, rest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS documents (
+		name       TEXT PRIMARY KEY,
+		data       BLOB NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", path, err)
+	}
+
+	return &SQLiteStore{path: path, db: db}, nil
+}
+
+// Name identifies the backend
+func (s *SQLiteStore) Name() string {
+	return fmt.Sprintf("%s (%s)", BackendSQLite, s.path)
+}
+
+// Get reads a document row
+func (s *SQLiteStore) Get(name string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM documents WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put upserts a document row
+func (s *SQLiteStore) Put(name string, data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO documents (name, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		name, data, time.Now().UTC())
+	return err
+}
+
+// Close releases the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
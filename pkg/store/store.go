@@ -0,0 +1,173 @@
+// Package store persists pk's small state documents (pins, access history,
+// and future time-tracking or journal data) behind a pluggable backend.
+//
+// Each document is a JSON blob addressed by name. The backend is chosen in
+// ~/.config/pk/config.toml:
+//
+//	[state]
+//	backend = "sqlite"   # json (default) | sqlite | http
+//	path = "~/.local/share/pk/state.db"
+//	url = "https://pk.example.com/state/alice"
+//	token_env = "PK_STATE_TOKEN"
+//
+// Without a [state] backend, [cache] backend = "sqlite" keeps these
+// documents in the project index (~/.cache/pk/pk.db) alongside projects.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/datakaicr/pk/pkg/settings"
+	"github.com/datakaicr/pk/pkg/statefile"
+)
+
+// ErrNotFound is returned by Get when a document doesn't exist
+var ErrNotFound = errors.New("document not found")
+
+// Store reads and writes named state documents
+type Store interface {
+	Get(name string) ([]byte, error)
+	Put(name string, data []byte) error
+	// Name identifies the backend in messages, e.g. "sqlite (~/.local/share/pk/state.db)"
+	Name() string
+}
+
+// Backend names accepted in [state] backend
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+	BackendHTTP   = "http"
+)
+
+// Open creates the store configured in s
+func Open(s *settings.Settings) (Store, error) {
+	switch s.State.Backend {
+	case "":
+		if s.Cache.Backend == BackendSQLite {
+			path, err := IndexPath()
+			if err != nil {
+				return nil, err
+			}
+			return NewSQLiteStore(path)
+		}
+		return NewJSONStore(), nil
+	case BackendJSON:
+		return NewJSONStore(), nil
+	case BackendSQLite:
+		return NewSQLiteStore(s.State.Path)
+	case BackendHTTP:
+		token := ""
+		if s.State.TokenEnv != "" {
+			token = os.Getenv(s.State.TokenEnv)
+		}
+		return NewHTTPStore(s.State.URL, token)
+	default:
+		return nil, fmt.Errorf("unknown state backend %q (want json, sqlite, or http)", s.State.Backend)
+	}
+}
+
+// IndexPath is ~/.cache/pk/pk.db, the SQLite project index
+func IndexPath() (string, error) {
+	return statefile.Path("pk.db")
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore Store
+)
+
+// Default returns the configured store for this process. Non-JSON backends
+// fall back to the local JSON files when they are unreachable, and read
+// existing JSON state the first time so switching backends keeps your pins.
+func Default() Store {
+	defaultOnce.Do(func() {
+		s, err := settings.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to load settings: %v\n", err)
+		}
+
+		st, err := Open(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using local JSON state\n", err)
+			st = NewJSONStore()
+		}
+
+		if _, isJSON := st.(*JSONStore); !isJSON {
+			st = &fallbackStore{primary: st, local: NewJSONStore()}
+		}
+		defaultStore = st
+	})
+	return defaultStore
+}
+
+// LoadJSON decodes document name into v. A missing document leaves v unchanged.
+func LoadJSON(s Store, name string, v interface{}) error {
+	data, err := s.Get(name)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SaveJSON encodes v as document name
+func SaveJSON(s Store, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.Put(name, data)
+}
+
+// fallbackStore uses primary, degrading to local when primary fails, and
+// seeds primary from local documents it hasn't seen yet
+type fallbackStore struct {
+	primary Store
+	local   Store
+	warned  sync.Once
+}
+
+func (f *fallbackStore) Name() string {
+	return f.primary.Name()
+}
+
+func (f *fallbackStore) Get(name string) ([]byte, error) {
+	data, err := f.primary.Get(name)
+	if err == nil {
+		return data, nil
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		f.warn(err)
+		return f.local.Get(name)
+	}
+
+	// First use of this backend: carry over existing local state
+	data, err = f.local.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	f.primary.Put(name, data)
+	return data, nil
+}
+
+func (f *fallbackStore) Put(name string, data []byte) error {
+	if err := f.primary.Put(name, data); err != nil {
+		f.warn(err)
+		return f.local.Put(name, data)
+	}
+	return nil
+}
+
+func (f *fallbackStore) warn(err error) {
+	f.warned.Do(func() {
+		fmt.Fprintf(os.Stderr, "Warning: %s state backend unavailable (%v); using local JSON state\n",
+			f.primary.Name(), err)
+	})
+}
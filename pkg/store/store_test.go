@@ -0,0 +1,180 @@
+package store
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/settings"
+)
+
+// exercise runs the behavior every backend must share
+func exercise(t *testing.T, s Store) {
+	t.Helper()
+
+	if _, err := s.Get("pins"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for missing document, got %v", err)
+	}
+
+	pins := map[string]string{"1": "dojo"}
+	if err := SaveJSON(s, "pins", pins); err != nil {
+		t.Fatalf("SaveJSON failed: %v", err)
+	}
+	pins["1"] = "conduit"
+	if err := SaveJSON(s, "pins", pins); err != nil {
+		t.Fatalf("SaveJSON overwrite failed: %v", err)
+	}
+
+	loaded := make(map[string]string)
+	if err := LoadJSON(s, "pins", &loaded); err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+	if loaded["1"] != "conduit" {
+		t.Errorf("Expected latest value 'conduit', got %v", loaded)
+	}
+
+	untouched := map[string]string{"keep": "me"}
+	if err := LoadJSON(s, "journal", &untouched); err != nil || untouched["keep"] != "me" {
+		t.Errorf("Expected missing document to leave value alone, got %v (%v)", untouched, err)
+	}
+}
+
+func TestJSONStore(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	s := NewJSONStore()
+	exercise(t, s)
+
+	// Keeps the original file layout
+	if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".cache", "pk", "pins.json")); err != nil {
+		t.Errorf("Expected ~/.cache/pk/pins.json: %v", err)
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "nested", "state.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer s.Close()
+
+	exercise(t, s)
+}
+
+// memoryServer is a minimal state server for the http backend
+func memoryServer(t *testing.T, token string) *httptest.Server {
+	var mu sync.Mutex
+	docs := make(map[string][]byte)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := docs[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			docs[name] = data
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestHTTPStore(t *testing.T) {
+	server := memoryServer(t, "secret")
+	defer server.Close()
+
+	s, err := NewHTTPStore(server.URL+"/", "secret")
+	if err != nil {
+		t.Fatalf("NewHTTPStore failed: %v", err)
+	}
+	exercise(t, s)
+
+	unauthorized, _ := NewHTTPStore(server.URL, "wrong")
+	if _, err := unauthorized.Get("pins"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected auth failure to be an error, got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	var s settings.Settings
+	if st, err := Open(&s); err != nil || st.Name() != BackendJSON {
+		t.Errorf("Expected json default, got %v (%v)", st, err)
+	}
+
+	// The SQLite project index doubles as the state store
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+	s.Cache.Backend = "sqlite"
+	st, err := Open(&s)
+	if err != nil {
+		t.Fatalf("Open with [cache] sqlite: %v", err)
+	}
+	if path, _ := IndexPath(); st.Name() != BackendSQLite+" ("+path+")" {
+		t.Errorf("Expected state in %s, got %s", path, st.Name())
+	}
+	st.(*SQLiteStore).Close()
+
+	s.State.Backend = "http"
+	if _, err := Open(&s); err == nil {
+		t.Error("Expected http backend without url to fail")
+	}
+
+	s.State.Backend = "redis"
+	if _, err := Open(&s); err == nil {
+		t.Error("Expected unknown backend to fail")
+	}
+}
+
+func TestFallbackStoreSeedsFromLocal(t *testing.T) {
+	local, _ := NewSQLiteStore(filepath.Join(t.TempDir(), "local.db"))
+	primary, _ := NewSQLiteStore(filepath.Join(t.TempDir(), "primary.db"))
+	local.Put("pins", []byte(`{"1":"dojo"}`))
+
+	f := &fallbackStore{primary: primary, local: local}
+	data, err := f.Get("pins")
+	if err != nil || string(data) != `{"1":"dojo"}` {
+		t.Fatalf("Expected local pins, got %q (%v)", data, err)
+	}
+
+	if data, err := primary.Get("pins"); err != nil || string(data) != `{"1":"dojo"}` {
+		t.Errorf("Expected pins copied to primary, got %q (%v)", data, err)
+	}
+}
+
+func TestFallbackStoreDegradesWhenUnreachable(t *testing.T) {
+	server := memoryServer(t, "")
+	server.Close() // Unreachable from here on
+
+	primary, _ := NewHTTPStore(server.URL, "")
+	local, _ := NewSQLiteStore(filepath.Join(t.TempDir(), "local.db"))
+	f := &fallbackStore{primary: primary, local: local}
+
+	if err := f.Put("access", []byte(`{}`)); err != nil {
+		t.Fatalf("Expected Put to fall back to local, got %v", err)
+	}
+	if data, err := f.Get("access"); err != nil || string(data) != `{}` {
+		t.Errorf("Expected Get to read local copy, got %q (%v)", data, err)
+	}
+}
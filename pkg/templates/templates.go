@@ -0,0 +1,186 @@
+// Package templates scaffolds new projects from user-defined templates in
+// ~/.config/pk/templates/<name>/:
+//
+//	template.toml   [template] description and dirs, plus any .project.toml
+//	                sections ([project], [tech], [tmux], ...) used as defaults
+//	files/          copied into the new project; *.tmpl files are rendered
+//	                as Go templates and lose the suffix
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/output"
+)
+
+// Template is a project template loaded from disk
+type Template struct {
+	Name string `toml:"-"`
+	Dir  string `toml:"-"`
+
+	Template struct {
+		Description string   `toml:"description"`
+		Dirs        []string `toml:"dirs"` // Empty directories to create
+	} `toml:"template"`
+
+	// Defaults for the generated .project.toml
+	config.Project
+}
+
+// Data is available to rendered files and paths, e.g. {{.Name}} or {{.Date}}
+type Data struct {
+	Name  string
+	ID    string
+	Owner string
+	Type  string
+	Kind  string
+	Date  string // YYYY-MM-DD
+	Year  string
+}
+
+// Dir returns ~/.config/pk/templates
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "pk", "templates"), nil
+}
+
+// Names lists available templates. A missing templates directory yields none.
+func Names() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load reads a template by name. template.toml is optional, so a bare files/
+// directory is a valid template.
+func Load(name string) (*Template, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return LoadDir(name, filepath.Join(dir, name))
+}
+
+// LoadDir reads a template from an explicit directory
+func LoadDir(name, dir string) (*Template, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("template '%s' not found in %s", name, filepath.Dir(dir))
+	}
+
+	t := &Template{Name: name, Dir: dir}
+	tomlPath := filepath.Join(dir, "template.toml")
+	if _, err := os.Stat(tomlPath); err == nil {
+		if _, err := toml.DecodeFile(tomlPath, t); err != nil {
+			return nil, fmt.Errorf("template '%s': %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// Scaffold creates the template's directories and files under projectPath.
+// .project.toml in files/ is skipped: pk writes it from the defaults.
+func (t *Template) Scaffold(projectPath string, data Data) error {
+	for _, dir := range t.Template.Dirs {
+		rel, err := renderString(dir, data)
+		if err != nil {
+			return fmt.Errorf("dir %s: %w", dir, err)
+		}
+		if err := os.MkdirAll(filepath.Join(projectPath, rel), 0755); err != nil {
+			return err
+		}
+	}
+
+	filesDir := filepath.Join(t.Dir, "files")
+	if _, err := os.Stat(filesDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.WalkDir(filesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(filesDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if rel, err = renderString(rel, data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		dest := filepath.Join(projectPath, strings.TrimSuffix(rel, ".tmpl"))
+
+		if d.IsDir() {
+			return os.MkdirAll(dest, 0755)
+		}
+		if filepath.Base(dest) == ".project.toml" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(rel, ".tmpl") {
+			if content, err = render(path, string(content), data); err != nil {
+				return err
+			}
+		}
+		return os.WriteFile(dest, content, info.Mode().Perm())
+	})
+}
+
+// renderString renders s if it contains template actions
+func renderString(s string, data Data) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	out, err := render(s, s, data)
+	return string(out), err
+}
+
+func render(name, text string, data Data) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(output.Funcs()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
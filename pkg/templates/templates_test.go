@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadAndScaffold(t *testing.T) {
+	tmpHome := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpHome)
+	defer os.Setenv("HOME", originalHome)
+
+	dir := filepath.Join(tmpHome, ".config", "pk", "templates", "api")
+	writeFile(t, filepath.Join(dir, "template.toml"), `[template]
+description = "API service"
+dirs = ["docs", "cmd/{{.ID}}"]
+
+[project]
+type = "client-project"
+
+[tech]
+stack = ["go"]
+
+[tmux]
+windows = [{name = "editor", command = "nvim"}]
+`)
+	writeFile(t, filepath.Join(dir, "files", "README.md.tmpl"), "# {{.Name}} ({{.Owner}}, {{.Year}})\n")
+	writeFile(t, filepath.Join(dir, "files", "{{.ID}}.txt"), "{{.Name}} stays literal\n")
+	writeFile(t, filepath.Join(dir, "files", ".project.toml"), "ignored")
+
+	names, err := Names()
+	if err != nil || len(names) != 1 || names[0] != "api" {
+		t.Fatalf("Names() = %v, %v", names, err)
+	}
+
+	tpl, err := Load("api")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if tpl.Template.Description != "API service" || tpl.ProjectInfo.Type != "client-project" {
+		t.Errorf("Template not decoded: %+v", tpl.Template)
+	}
+	if len(tpl.Tech.Stack) != 1 || len(tpl.Tmux.Windows) != 1 {
+		t.Errorf("Project defaults not decoded: stack=%v tmux=%v", tpl.Tech.Stack, tpl.Tmux)
+	}
+
+	project := t.TempDir()
+	data := Data{Name: "Acme", ID: "acme", Owner: "client", Year: "2025"}
+	if err := tpl.Scaffold(project, data); err != nil {
+		t.Fatalf("Scaffold failed: %v", err)
+	}
+
+	readme, err := os.ReadFile(filepath.Join(project, "README.md"))
+	if err != nil || string(readme) != "# Acme (client, 2025)\n" {
+		t.Errorf("README.md not rendered: %q (%v)", readme, err)
+	}
+	plain, err := os.ReadFile(filepath.Join(project, "acme.txt"))
+	if err != nil || string(plain) != "{{.Name}} stays literal\n" {
+		t.Errorf("Non-.tmpl file should be copied verbatim: %q (%v)", plain, err)
+	}
+	if info, err := os.Stat(filepath.Join(project, "cmd", "acme")); err != nil || !info.IsDir() {
+		t.Errorf("Expected rendered dir cmd/acme: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".project.toml")); !os.IsNotExist(err) {
+		t.Error("Template .project.toml should not be copied")
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	tmpHome := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpHome)
+	defer os.Setenv("HOME", originalHome)
+
+	if names, err := Names(); err != nil || len(names) != 0 {
+		t.Errorf("Expected no templates without a directory, got %v, %v", names, err)
+	}
+	if _, err := Load("nope"); err == nil {
+		t.Error("Expected error for missing template")
+	}
+}
@@ -0,0 +1,179 @@
+// Package trash keeps deleted projects in ~/.local/share/pk/trash until
+// they're restored or purged. Each entry is a directory holding the
+// project's files, its sidecar metadata if it had one, and info.toml
+// recording where it came from.
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/datakaicr/pk/pkg/config"
+	"github.com/datakaicr/pk/pkg/paths"
+)
+
+// Entry is a project in the trash
+type Entry struct {
+	Dir       string    `toml:"-"`                 // The entry's directory in the trash
+	ID        string    `toml:"id"`                // Project ID when deleted
+	Name      string    `toml:"name"`              // Project name when deleted
+	Path      string    `toml:"path"`              // Where the project was
+	Sidecar   string    `toml:"sidecar,omitempty"` // Its sidecar metadata file, if any
+	DeletedAt time.Time `toml:"deleted_at"`
+}
+
+// Files returns where the entry keeps the project directory
+func (e Entry) Files() string {
+	return filepath.Join(e.Dir, "files")
+}
+
+// sidecarCopy returns where the entry keeps the sidecar metadata file
+func (e Entry) sidecarCopy() string {
+	return filepath.Join(e.Dir, "sidecar.toml")
+}
+
+// Dir returns the trash directory
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "pk", "trash"), nil
+}
+
+// Put moves a project's directory, and its sidecar file if any, into the
+// trash. A project on another filesystem is copied in and then removed.
+func Put(p *config.Project) (Entry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Entry{}, err
+	}
+
+	now := time.Now()
+	entryDir, err := os.MkdirTemp(dir, p.ProjectInfo.ID+"-"+now.Format("20060102-150405")+"-")
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{
+		Dir:       entryDir,
+		ID:        p.ProjectInfo.ID,
+		Name:      p.ProjectInfo.Name,
+		Path:      p.Path,
+		DeletedAt: now,
+	}
+	if p.Sidecar() {
+		e.Sidecar = p.File()
+	}
+
+	if err := writeInfo(e); err != nil {
+		os.RemoveAll(entryDir)
+		return Entry{}, err
+	}
+	if err := paths.Move(p.Path, e.Files()); err != nil {
+		os.RemoveAll(entryDir)
+		return Entry{}, fmt.Errorf("failed to move %s to the trash: %w", p.Path, err)
+	}
+	if e.Sidecar != "" {
+		if err := paths.Move(e.Sidecar, e.sidecarCopy()); err != nil && !os.IsNotExist(err) {
+			return e, fmt.Errorf("failed to move sidecar %s to the trash: %w", e.Sidecar, err)
+		}
+	}
+	return e, nil
+}
+
+// writeInfo records an entry's origin in its directory
+func writeInfo(e Entry) error {
+	f, err := os.Create(filepath.Join(e.Dir, "info.toml"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(e)
+}
+
+// List returns the entries in the trash, most recently deleted first.
+// Directories without a readable info.toml are skipped.
+func List() ([]Entry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, d := range dirEntries {
+		if !d.IsDir() {
+			continue
+		}
+		e := Entry{Dir: filepath.Join(dir, d.Name())}
+		if _, err := toml.DecodeFile(filepath.Join(e.Dir, "info.toml"), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+// Find returns the most recently deleted entry whose ID or name matches,
+// case-insensitively
+func Find(name string) (Entry, bool, error) {
+	entries, err := List()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.ID, name) || strings.EqualFold(e.Name, name) {
+			return e, true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// Restore moves an entry's project back where it was, with its sidecar
+// file, and removes the entry
+func Restore(e Entry) error {
+	if _, err := os.Stat(e.Path); err == nil {
+		return fmt.Errorf("%s already exists", e.Path)
+	}
+	if e.Sidecar != "" {
+		if _, err := os.Stat(e.Sidecar); err == nil {
+			return fmt.Errorf("%s already exists", e.Sidecar)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+		return err
+	}
+	if err := paths.Move(e.Files(), e.Path); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", e.Path, err)
+	}
+	if e.Sidecar != "" {
+		if err := os.MkdirAll(filepath.Dir(e.Sidecar), 0755); err != nil {
+			return err
+		}
+		if err := paths.Move(e.sidecarCopy(), e.Sidecar); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to restore sidecar %s: %w", e.Sidecar, err)
+		}
+	}
+	return os.RemoveAll(e.Dir)
+}
+
+// Purge deletes an entry for good
+func Purge(e Entry) error {
+	return os.RemoveAll(e.Dir)
+}
@@ -0,0 +1,104 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datakaicr/pk/pkg/config"
+)
+
+func trashProject(t *testing.T, dir, id string) *config.Project {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := &config.Project{Path: dir}
+	p.ProjectInfo.ID = id
+	p.ProjectInfo.Name = id
+	return p
+}
+
+func TestPutAndRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, "projects", "acme")
+	e, err := Put(trashProject(t, dir, "acme"))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("the project should be gone from its directory")
+	}
+	if _, err := os.Stat(filepath.Join(e.Files(), "main.go")); err != nil {
+		t.Errorf("the project's files should be in the trash: %v", err)
+	}
+
+	found, ok, err := Find("ACME")
+	if err != nil || !ok || found.Path != dir || found.Dir != e.Dir {
+		t.Fatalf("Find = %+v %v %v, want the entry for %s", found, ok, err, dir)
+	}
+
+	if err := Restore(found); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("the project should be back: %v", err)
+	}
+	if entries, _ := List(); len(entries) != 0 {
+		t.Errorf("the entry should be gone after restoring, got %d", len(entries))
+	}
+}
+
+func TestRestoreRefusesToOverwrite(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, "projects", "acme")
+	e, err := Put(trashProject(t, dir, "acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashProject(t, dir, "acme") // A new project took its place
+
+	if err := Restore(e); err == nil {
+		t.Error("Restore should refuse to replace an existing directory")
+	}
+	if _, err := os.Stat(e.Files()); err != nil {
+		t.Error("a failed restore should leave the entry in the trash")
+	}
+}
+
+func TestListNewestFirst(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, id := range []string{"first", "second"} {
+		e, err := Put(trashProject(t, filepath.Join(home, "projects", id), id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id == "first" {
+			// Deleted earlier
+			e.DeletedAt = e.DeletedAt.Add(-1e9)
+			if err := writeInfo(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	entries, err := List()
+	if err != nil || len(entries) != 2 || entries[0].ID != "second" {
+		t.Fatalf("List = %+v %v, want second first", entries, err)
+	}
+	if err := Purge(entries[1]); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := List(); len(entries) != 1 {
+		t.Errorf("Purge should remove the entry, %d left", len(entries))
+	}
+}